@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"github.com/alexsibrin/runbot-auth/internal/accountfile"
+	"github.com/alexsibrin/runbot-auth/internal/api/controllers"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/app"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"io"
+	"os"
+	"strconv"
+)
+
+const (
+	accountsCommand = "accounts"
+
+	accountsImport = "import"
+	accountsExport = "export"
+
+	accountsUsage = "usage: accounts import [-format csv|jsonl] [-batch N] [-report FILE] FILE|- | export [-format csv|jsonl] FILE"
+
+	defaultAccountsImportBatch = 1000
+	// accountsStdin is the FILE of the import read from stdin
+	accountsStdin = "-"
+)
+
+var (
+	ErrAccountsWrongArgs      = errors.New(accountsUsage)
+	ErrAccountsRowsRejected   = errors.New("some rows are rejected, see the report")
+	ErrAccountsFormatIsNeeded = errors.New("-format is needed for stdin")
+)
+
+// accountsArgs are the parsed arguments of the accounts subcommand
+type accountsArgs struct {
+	path   string
+	format string
+	batch  int
+	report string
+}
+
+// runAccounts handles the accounts subcommand, the migrations are expected to be applied.
+// The logger writes to stdout, so the files are never written there
+func runAccounts(ctx context.Context, logger logapp.ILogger, store *app.Storage, args []string) error {
+	if len(args) == 0 || (args[0] != accountsImport && args[0] != accountsExport) {
+		return ErrAccountsWrongArgs
+	}
+	parsed, err := parseAccountsArgs(args[0], args[1:])
+	if err != nil {
+		return err
+	}
+
+	usecase, err := usecases.NewAccountImport(&usecases.AccountImportDependencies{
+		AccountRepo: store.Account,
+		ImportRepo:  store.AccountImport,
+	})
+	if err != nil {
+		return err
+	}
+	controller, err := controllers.NewAccountImport(&controllers.AccountImportDependencies{
+		Usecase: usecase,
+	})
+	if err != nil {
+		return err
+	}
+
+	if args[0] == accountsImport {
+		return importAccounts(ctx, logger, controller, parsed)
+	}
+	return exportAccounts(ctx, logger, controller, parsed)
+}
+
+func parseAccountsArgs(command string, args []string) (*accountsArgs, error) {
+	parsed := &accountsArgs{}
+
+	flags := flag.NewFlagSet(accountsCommand+" "+command, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.StringVar(&parsed.format, "format", "", "csv or jsonl, by the file extension if it's empty")
+	if command == accountsImport {
+		flags.IntVar(&parsed.batch, "batch", defaultAccountsImportBatch, "rows imported at once")
+		flags.StringVar(&parsed.report, "report", "", "CSV file of the rejected rows, stderr if it's empty")
+	}
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		return nil, ErrAccountsWrongArgs
+	}
+	parsed.path = flags.Arg(0)
+	if command == accountsImport && parsed.batch < 1 {
+		return nil, ErrAccountsWrongArgs
+	}
+	if command == accountsExport && parsed.path == accountsStdin {
+		return nil, ErrAccountsWrongArgs
+	}
+
+	if parsed.format == "" {
+		if parsed.path == accountsStdin {
+			return nil, ErrAccountsFormatIsNeeded
+		}
+		format, err := accountfile.FormatOf(parsed.path)
+		if err != nil {
+			return nil, err
+		}
+		parsed.format = format
+	}
+	return parsed, nil
+}
+
+// importAccounts streams the file in batches, the rejected rows are reported as CSV with the reasons
+func importAccounts(ctx context.Context, logger logapp.ILogger, controller *controllers.AccountImport, args *accountsArgs) error {
+	in := io.Reader(os.Stdin)
+	if args.path != accountsStdin {
+		f, err := os.Open(args.path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	reader, err := accountfile.NewReader(in, args.format)
+	if err != nil {
+		return err
+	}
+
+	out := io.Writer(os.Stderr)
+	if args.report != "" {
+		f, err := os.Create(args.report)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	report := csv.NewWriter(out)
+	if err = report.Write([]string{"row", "email", "error"}); err != nil {
+		return err
+	}
+
+	var rows, rejected int
+	reject := func(r *models.AccountImportError) error {
+		rejected++
+		return report.Write([]string{strconv.Itoa(r.Row), r.Email, r.Err.Error()})
+	}
+
+	batch := make([]*models.AccountImportRow, 0, args.batch)
+	importBatch := func() error {
+		rejectedrows, err := controller.Import(ctx, batch)
+		if err != nil {
+			return err
+		}
+		for _, r := range rejectedrows {
+			if err = reject(r); err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var recorderr *accountfile.RecordError
+		if errors.As(err, &recorderr) {
+			rows++
+			if err = reject(&models.AccountImportError{Row: recorderr.Row, Err: recorderr.Err}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		rows++
+		batch = append(batch, row)
+		if len(batch) == args.batch {
+			if err = importBatch(); err != nil {
+				return err
+			}
+			logger.Infof("%d rows are processed", rows)
+		}
+	}
+	if err = importBatch(); err != nil {
+		return err
+	}
+
+	report.Flush()
+	if err = report.Error(); err != nil {
+		return err
+	}
+
+	logger.Infof("%d accounts are imported of %d rows, %d rows are rejected", rows-rejected, rows, rejected)
+	if rejected > 0 {
+		return ErrAccountsRowsRejected
+	}
+	return nil
+}
+
+// exportAccounts writes the accounts which aren't deleted with their password hashes,
+// so the file is created readable by the owner only and an existing one isn't overwritten
+func exportAccounts(ctx context.Context, logger logapp.ILogger, controller *controllers.AccountImport, args *accountsArgs) error {
+	f, err := os.OpenFile(args.path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+
+	exported, err := writeAccounts(ctx, controller, f, args.format)
+	if closeerr := f.Close(); err == nil {
+		err = closeerr
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("%d accounts are exported", exported)
+	return nil
+}
+
+func writeAccounts(ctx context.Context, controller *controllers.AccountImport, out io.Writer, format string) (int, error) {
+	writer, err := accountfile.NewWriter(out, format)
+	if err != nil {
+		return 0, err
+	}
+
+	var exported int
+	err = controller.Export(ctx, func(record *models.AccountRecord) error {
+		exported++
+		return writer.Write(record)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return exported, writer.Flush()
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/alexsibrin/runbot-auth/internal/app"
+	"github.com/alexsibrin/runbot-auth/internal/config"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"os"
+	"strings"
+)
+
+const (
+	createAdminCommand = "create-admin"
+
+	createAdminUsage = "usage: create-admin NAME"
+
+	// secretSize is the number of the random bytes of the generated tokens and salts
+	secretSize = 32
+)
+
+var (
+	ErrCreateAdminWrongArgs = errors.New(createAdminUsage)
+)
+
+// runCreateAdmin prints a new NAME:TOKEN entry of Admin.Tokens, the NAME is the actor of the admin's changes
+func runCreateAdmin(_ context.Context, conf *config.Config, logger logapp.ILogger, _ *app.Storage, args []string) error {
+	if len(args) != 1 || args[0] == "" || strings.ContainsAny(args[0], ": \t") {
+		return ErrCreateAdminWrongArgs
+	}
+
+	token, err := newSecret()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stdout, args[0]+":"+token)
+
+	logger.Infof("Admin token is generated, add it to Admin.Tokens (%d configured now) and restart the app", len(conf.Admin.Tokens))
+	return nil
+}
+
+// newSecret is a random URL-safe string of secretSize bytes
+func newSecret() (string, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+)
+
+const (
+	auditCommand = "audit"
+
+	auditVerify = "verify"
+
+	auditUsage = "usage: audit verify"
+)
+
+var (
+	ErrAuditWrongArgs = errors.New(auditUsage)
+)
+
+// runAudit handles the audit subcommand, it fails if the chain of the audit log is broken
+func runAudit(ctx context.Context, logger logapp.ILogger, repo usecases.IAuditLogRepo, args []string) error {
+	if len(args) == 0 || args[0] != auditVerify {
+		return ErrAuditWrongArgs
+	}
+
+	auditlog, err := usecases.NewAuditLog(&usecases.AuditLogDependencies{
+		Repo: repo,
+	})
+	if err != nil {
+		return err
+	}
+
+	result, err := auditlog.Verify(ctx)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Audit log is intact: %d records, the head hash is %q", result.Records, result.Head)
+	return nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/app"
+	"github.com/alexsibrin/runbot-auth/internal/config"
+	"github.com/alexsibrin/runbot-auth/internal/geoip"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/alexsibrin/runbot-auth/internal/mailer"
+	"github.com/alexsibrin/runbot-auth/internal/repositories/dbpostgres"
+	"io"
+	"net/url"
+)
+
+const (
+	checkConfigCommand = "check-config"
+
+	checkConfigUsage = "usage: check-config"
+
+	// minSecretLength is the length of the secrets below which they're reported as weak
+	minSecretLength = 32
+)
+
+var (
+	ErrCheckConfigWrongArgs = errors.New(checkConfigUsage)
+	ErrConfigIsNotValid     = errors.New("config is not valid")
+
+	ErrJwtExpiresInIsNotValid = errors.New("Jwt.ExpiresIn must be positive")
+	ErrRestPortIsEmpty        = errors.New("RestServer.Port is empty")
+	ErrGRPCPortIsNotValid     = errors.New("GRPCServer.Port must be positive")
+	ErrPublicURLIsNotValid    = errors.New("Export.PublicURL is not an absolute URL")
+)
+
+// runCheckConfig reports the config problems and checks the storage, the outbox publisher and the files
+// the app loads at the startup. The storage is already connected when the command runs
+func runCheckConfig(ctx context.Context, conf *config.Config, logger logapp.ILogger, store *app.Storage, args []string) error {
+	if len(args) > 0 {
+		return ErrCheckConfigWrongArgs
+	}
+
+	errs := checkConfig(conf, logger)
+	if store.Postgres != nil {
+		errs = append(errs, checkMigrations(ctx, logger, store.Postgres))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("%w: %w", ErrConfigIsNotValid, err)
+	}
+
+	logger.Info("Config is valid")
+	return nil
+}
+
+// checkConfig returns the problems which stop the app, the doubtful settings are only logged
+func checkConfig(conf *config.Config, logger logapp.ILogger) []error {
+	var errs []error
+
+	if conf.Jwt.Salt == "" {
+		errs = append(errs, ErrJwtSaltIsEmpty)
+	} else if len(conf.Jwt.Salt) < minSecretLength {
+		logger.Warnf("Jwt.Salt is shorter than %d bytes", minSecretLength)
+	}
+	if conf.Jwt.ExpiresIn <= 0 {
+		errs = append(errs, ErrJwtExpiresInIsNotValid)
+	}
+	if conf.RestServer.Port == "" {
+		errs = append(errs, ErrRestPortIsEmpty)
+	}
+	if conf.GRPCServer.Port <= 0 {
+		errs = append(errs, ErrGRPCPortIsNotValid)
+	}
+
+	if conf.Export.PublicURL == "" {
+		logger.Warn("Export.PublicURL is empty, the emailed links and the download URLs are relative")
+	} else if u, err := url.Parse(conf.Export.PublicURL); err != nil || !u.IsAbs() {
+		errs = append(errs, ErrPublicURLIsNotValid)
+	}
+
+	if conf.Mail.Host != "" {
+		if _, err := mailer.NewSMTP(&mailer.Config{Host: conf.Mail.Host, From: conf.Mail.From}); err != nil {
+			errs = append(errs, fmt.Errorf("Mail: %w", err))
+		}
+	} else {
+		logger.Warn("Mail.Host is empty, the emails are only logged")
+	}
+
+	if conf.GeoIP.File != "" {
+		if _, err := geoip.Load(conf.GeoIP.File); err != nil {
+			errs = append(errs, fmt.Errorf("GeoIP.File: %w", err))
+		}
+	}
+	if conf.Email.DisposableDomainsFile != "" {
+		if _, err := validators.LoadEmailDomainBlocklist(conf.Email.DisposableDomainsFile, conf.Email.DisposableDomains); err != nil {
+			errs = append(errs, fmt.Errorf("Email.DisposableDomainsFile: %w", err))
+		}
+	}
+
+	eventpublisher, err := app.NewPublisher(conf)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("Outbox: %w", err))
+	} else if closer, ok := eventpublisher.(io.Closer); ok {
+		if err = closer.Close(); err != nil {
+			logger.Error(err)
+		}
+	}
+
+	if len(conf.Admin.Tokens) == 0 {
+		logger.Warn("Admin.Tokens is empty, the admin REST API is disabled")
+	}
+	for i, token := range conf.Admin.Tokens {
+		if len(token) < minSecretLength {
+			logger.Warnf("Admin.Tokens[%d] is shorter than %d bytes", i, minSecretLength)
+		}
+	}
+
+	return errs
+}
+
+// checkMigrations logs the pending migrations, the app applies them at the startup only with PostgreSQL.AutoMigrate
+func checkMigrations(ctx context.Context, logger logapp.ILogger, db *dbpostgres.PostgreSQL) error {
+	migrator, err := dbpostgres.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	var pending int
+	for _, s := range statuses {
+		if !s.Applied {
+			pending++
+		}
+	}
+	if pending > 0 {
+		logger.Warnf("%d migrations are pending", pending)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/alexsibrin/runbot-auth/internal/app"
+	"github.com/alexsibrin/runbot-auth/internal/config"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	helpCommand = "help"
+
+	usagePrefix = "usage: "
+)
+
+var (
+	ErrCommandIsUnknown = errors.New("command is unknown")
+)
+
+// command is a subcommand of the app, all of them share the config and the logger
+type command struct {
+	usage string
+	// storage is opened before the command runs and closed after it, store is nil otherwise
+	storage bool
+	run     func(ctx context.Context, conf *config.Config, logger logapp.ILogger, store *app.Storage, args []string) error
+}
+
+// commands are the subcommands by their names, serve runs when the name is omitted
+var commands = map[string]*command{
+	serveCommand: {
+		usage: serveUsage,
+		run:   runServe,
+	},
+	migrateCommand: {
+		usage:   migrateUsage,
+		storage: true,
+		run: func(ctx context.Context, _ *config.Config, logger logapp.ILogger, store *app.Storage, args []string) error {
+			return runMigrate(ctx, logger, store.Postgres, args)
+		},
+	},
+	auditCommand: {
+		usage:   auditUsage,
+		storage: true,
+		run: func(ctx context.Context, _ *config.Config, logger logapp.ILogger, store *app.Storage, args []string) error {
+			return runAudit(ctx, logger, store.AuditLog, args)
+		},
+	},
+	accountsCommand: {
+		usage:   accountsUsage,
+		storage: true,
+		run: func(ctx context.Context, _ *config.Config, logger logapp.ILogger, store *app.Storage, args []string) error {
+			return runAccounts(ctx, logger, store, args)
+		},
+	},
+	createAdminCommand: {
+		usage: createAdminUsage,
+		run:   runCreateAdmin,
+	},
+	setStatusCommand: {
+		usage:   setStatusUsage,
+		storage: true,
+		run:     runSetStatus,
+	},
+	revokeSessionsCommand: {
+		usage:   revokeSessionsUsage,
+		storage: true,
+		run:     runRevokeSessions,
+	},
+	rotateKeysCommand: {
+		usage: rotateKeysUsage,
+		run:   runRotateKeys,
+	},
+	checkConfigCommand: {
+		usage:   checkConfigUsage,
+		storage: true,
+		run:     runCheckConfig,
+	},
+}
+
+func lookupCommand(name string) (*command, error) {
+	cmd, ok := commands[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCommandIsUnknown, name)
+	}
+	return cmd, nil
+}
+
+func isHelp(name string) bool {
+	return name == helpCommand || name == "-h" || name == "--help"
+}
+
+// runCommand opens the storage for the command, it's closed even if the command fails
+func runCommand(ctx context.Context, cmd *command, conf *config.Config, logger logapp.ILogger, args []string) error {
+	if !cmd.storage {
+		return cmd.run(ctx, conf, logger, nil, args)
+	}
+
+	store, err := app.NewStorage(conf)
+	if err != nil {
+		return err
+	}
+	err = cmd.run(ctx, conf, logger, store, args)
+	if closeerr := store.Close(); closeerr != nil {
+		logger.Error(closeerr)
+	}
+	return err
+}
+
+func printUsage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "commands, serve runs by default:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\n", strings.TrimPrefix(commands[name].usage, usagePrefix))
+	}
+}
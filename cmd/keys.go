@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/alexsibrin/runbot-auth/internal/app"
+	"github.com/alexsibrin/runbot-auth/internal/config"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"os"
+)
+
+const (
+	rotateKeysCommand = "rotate-keys"
+
+	rotateKeysUsage = "usage: rotate-keys"
+)
+
+var (
+	ErrRotateKeysWrongArgs = errors.New(rotateKeysUsage)
+	ErrJwtSaltIsEmpty      = errors.New("Jwt.Salt is empty")
+)
+
+// runRotateKeys prints the Jwt section with a new salt, the current one becomes the previous one,
+// so the issued tokens stay valid until they expire
+func runRotateKeys(_ context.Context, conf *config.Config, logger logapp.ILogger, _ *app.Storage, args []string) error {
+	if len(args) > 0 {
+		return ErrRotateKeysWrongArgs
+	}
+	if conf.Jwt.Salt == "" {
+		return ErrJwtSaltIsEmpty
+	}
+
+	salt, err := newSecret()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Jwt:\n  Salt: %s\n  PreviousSalts:\n    - %s\n", salt, conf.Jwt.Salt)
+
+	if len(conf.Jwt.PreviousSalts) > 0 {
+		logger.Warnf("%d previous salts are dropped, the tokens signed with them are rejected", len(conf.Jwt.PreviousSalts))
+	}
+	if conf.Export.URLSecret == "" {
+		logger.Warn("Export.URLSecret is empty, the download URLs signed with a secret derived from Jwt.Salt are rejected after the rotation")
+	}
+	if conf.LoginAlert.LinkSecret == "" {
+		logger.Warn("LoginAlert.LinkSecret is empty, the links signed with a secret derived from Jwt.Salt are rejected after the rotation")
+	}
+	logger.Info("Jwt.Salt is generated, replace the Jwt section and restart all the instances")
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/alexsibrin/runbot-auth/internal/repositories/dbpostgres"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	migrateCommand = "migrate"
+
+	migrateUp     = "up"
+	migrateDown   = "down"
+	migrateStatus = "status"
+
+	migrateUsage = "usage: migrate up | down [steps|all] | status"
+)
+
+var (
+	ErrMigrateWrongArgs     = errors.New(migrateUsage)
+	ErrMigrateNeedsPostgres = errors.New("migrations are supported by the postgres storage driver only")
+)
+
+// runMigrate handles the migrate subcommand
+func runMigrate(ctx context.Context, logger logapp.ILogger, db *dbpostgres.PostgreSQL, args []string) error {
+	if len(args) == 0 {
+		return ErrMigrateWrongArgs
+	}
+	if db == nil {
+		return ErrMigrateNeedsPostgres
+	}
+
+	migrator, err := dbpostgres.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case migrateUp:
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		for _, m := range applied {
+			logger.Infof("Migration %d_%s is applied", m.Version, m.Name)
+		}
+		logger.Infof("%d migrations are applied", len(applied))
+
+	case migrateDown:
+		steps := 1
+		if len(args) > 1 {
+			if args[1] == "all" {
+				steps = -1
+			} else if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return ErrMigrateWrongArgs
+			}
+		}
+		rolledback, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		for _, m := range rolledback {
+			logger.Infof("Migration %d_%s is rolled back", m.Version, m.Name)
+		}
+		logger.Infof("%d migrations are rolled back", len(rolledback))
+
+	case migrateStatus:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedat := "pending"
+			if s.Applied {
+				appliedat = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, appliedat)
+		}
+		return w.Flush()
+
+	default:
+		return ErrMigrateWrongArgs
+	}
+
+	return nil
+}
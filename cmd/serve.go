@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/app"
+	"github.com/alexsibrin/runbot-auth/internal/config"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+const (
+	serveCommand = "serve"
+
+	serveUsage = "usage: serve"
+)
+
+var (
+	ErrServeWrongArgs = errors.New(serveUsage)
+)
+
+// runServe runs the servers and the workers until the app is interrupted, the app opens the storage itself
+func runServe(ctx context.Context, conf *config.Config, logger logapp.ILogger, _ *app.Storage, args []string) error {
+	if len(args) > 0 {
+		return ErrServeWrongArgs
+	}
+
+	service, err := app.New(ctx, &app.Dependencies{
+		Config: conf,
+		Logger: logger,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Init graceful shutdown
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	logger.Info("App is running...")
+	err = service.Run(ctx)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("App is stopped.")
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"github.com/alexsibrin/runbot-auth/internal/api/controllers"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/app"
+	"github.com/alexsibrin/runbot-auth/internal/config"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"io"
+	"os"
+)
+
+const (
+	revokeSessionsCommand = "revoke-sessions"
+
+	revokeSessionsUsage = "usage: revoke-sessions [-actor NAME] UUID"
+)
+
+var (
+	ErrRevokeSessionsWrongArgs = errors.New(revokeSessionsUsage)
+)
+
+// runRevokeSessions signs all the devices out of the account, the revocation is recorded in the security log
+// and the audit log like set-status
+func runRevokeSessions(ctx context.Context, conf *config.Config, logger logapp.ILogger, store *app.Storage, args []string) error {
+	var actor string
+	flags := flag.NewFlagSet(revokeSessionsCommand, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.StringVar(&actor, "actor", os.Getenv("USER"), "who revokes the sessions")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		return ErrRevokeSessionsWrongArgs
+	}
+
+	usecase, err := app.NewSessionUsecase(conf, store, nil)
+	if err != nil {
+		return err
+	}
+	controller, err := controllers.NewSession(&controllers.SessionDependencies{
+		Usecase: usecase,
+	})
+	if err != nil {
+		return err
+	}
+
+	revoked, err := controller.RevokeAll(ctx, &models.RevokeSessions{
+		UUID:  flags.Arg(0),
+		Actor: actor,
+		Audit: &models.AuditMetadata{
+			Method: cliAuditMethod + " " + revokeSessionsCommand,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("%d sessions of the account %s are revoked", revoked, flags.Arg(0))
+	return nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/alexsibrin/runbot-auth/internal/api/controllers"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/app"
+	"github.com/alexsibrin/runbot-auth/internal/config"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/hasher"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	setStatusCommand = "set-status"
+
+	setStatusUsage = "usage: set-status [-reason TEXT] [-until RFC3339] [-actor NAME] UUID active|suspended|blocked"
+
+	// cliAuditMethod is the method of the audit records of the changes made with the commands
+	cliAuditMethod = "cli"
+)
+
+var (
+	ErrSetStatusWrongArgs = errors.New(setStatusUsage)
+)
+
+// runSetStatus changes the account status as the admin API does, the change is recorded in the audit log
+func runSetStatus(ctx context.Context, conf *config.Config, logger logapp.ILogger, store *app.Storage, args []string) error {
+	var reason, until, actor string
+	flags := flag.NewFlagSet(setStatusCommand, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.StringVar(&reason, "reason", "", "reason of the change")
+	flags.StringVar(&until, "until", "", "end of a temporary suspension or block")
+	flags.StringVar(&actor, "actor", os.Getenv("USER"), "who makes the change")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 2 {
+		return ErrSetStatusWrongArgs
+	}
+
+	status, ok := entities.StatusByName(flags.Arg(1))
+	if !ok {
+		return validators.ErrStatusIsNotValid
+	}
+	model := &models.ChangeAccountStatus{
+		UUID:   flags.Arg(0),
+		Status: status,
+		Reason: reason,
+		Actor:  actor,
+		Audit: &models.AuditMetadata{
+			Method: cliAuditMethod + " " + setStatusCommand,
+		},
+	}
+	if until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			return fmt.Errorf("-until: %w", err)
+		}
+		model.Until = t.Unix()
+	}
+
+	controller, err := newAccountController(conf, store)
+	if err != nil {
+		return err
+	}
+	changed, err := controller.ChangeAccountStatus(ctx, model)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Account %s is %s", changed.UUID, entities.StatusName(changed.Status))
+	return nil
+}
+
+// newAccountController builds the account controller for the admin commands, nobody signs in with them,
+// so the login alerts are left out
+func newAccountController(conf *config.Config, store *app.Storage) (*controllers.Account, error) {
+	usecase, err := usecases.NewAccount(&usecases.AccountDependencies{
+		Repo:                store.Account,
+		StatusHistory:       store.StatusHistory,
+		SecurityEvents:      store.SecurityEvent,
+		Transactor:          store.Transactor,
+		PasswordHasher:      hasher.NewStringHasher(),
+		DeletionGracePeriod: conf.Account.DeletionGracePeriod,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := app.NewSessionUsecase(conf, store, nil)
+	if err != nil {
+		return nil, err
+	}
+	return controllers.NewAccount(&controllers.AccountDependencies{
+		Usecase:  usecase,
+		Securer:  app.NewJwt(conf),
+		Sessions: sessions,
+	})
+}
@@ -0,0 +1,256 @@
+// Package accountfile reads and writes the account files of the bulk import and export in CSV and JSON Lines
+package accountfile
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+
+	columnUUID         = "uuid"
+	columnEmail        = "email"
+	columnName         = "name"
+	columnPasswordHash = "password_hash"
+	columnStatus       = "status"
+	columnStatusUntil  = "status_until"
+	columnCreatedAt    = "created_at"
+)
+
+var (
+	ErrFormatIsUnknown   = errors.New("account file format is unknown, it's csv or jsonl")
+	ErrHeaderIsNotValid  = errors.New("CSV header is not valid")
+	ErrRecordIsNotValid  = errors.New("record is not valid")
+	ErrColumnIsMissing   = errors.New("email and name columns are required")
+	ErrColumnIsDuplicate = errors.New("column is duplicate")
+)
+
+// columns are the CSV columns in the export order, the import accepts them in any order
+var columns = []string{columnUUID, columnEmail, columnName, columnPasswordHash, columnStatus, columnStatusUntil, columnCreatedAt}
+
+// RecordError is a row which can't be decoded, the reading goes on with the next row
+type RecordError struct {
+	Row int
+	Err error
+}
+
+func (err *RecordError) Error() string {
+	return fmt.Sprintf("row %d: %s", err.Row, err.Err)
+}
+
+func (err *RecordError) Unwrap() error {
+	return err.Err
+}
+
+// FormatOf is the format of the file by its extension, e.g. accounts.jsonl
+func FormatOf(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	default:
+		return "", ErrFormatIsUnknown
+	}
+}
+
+// Reader streams the records of an account file
+type Reader struct {
+	csv   *csv.Reader
+	lines *bufio.Scanner
+	// index of the CSV columns by their names
+	index map[string]int
+	row   int
+}
+
+func NewReader(r io.Reader, format string) (*Reader, error) {
+	switch format {
+	case FormatCSV:
+		reader := &Reader{csv: csv.NewReader(r)}
+		// the rows with a wrong number of fields are reported as RecordError
+		reader.csv.FieldsPerRecord = -1
+		reader.csv.ReuseRecord = true
+		if err := reader.readHeader(); err != nil {
+			return nil, err
+		}
+		return reader, nil
+	case FormatJSONL:
+		lines := bufio.NewScanner(r)
+		// a line holds a single account, 1MB is far more than needed
+		lines.Buffer(make([]byte, 64*1024), 1024*1024)
+		return &Reader{lines: lines}, nil
+	default:
+		return nil, ErrFormatIsUnknown
+	}
+}
+
+// Read returns the next row, io.EOF at the end of the file.
+// *RecordError is returned for a row which can't be decoded, any other error stops the reading
+func (r *Reader) Read() (*models.AccountImportRow, error) {
+	if r.csv != nil {
+		return r.readCSV()
+	}
+	return r.readJSONL()
+}
+
+func (r *Reader) readHeader() error {
+	header, err := r.csv.Read()
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: file is empty", ErrHeaderIsNotValid)
+	}
+	if err != nil {
+		return err
+	}
+
+	r.index = make(map[string]int, len(header))
+	for i, name := range header {
+		// the files saved by spreadsheets may start with a BOM
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !slices.Contains(columns, name) {
+			return fmt.Errorf("%w: unknown column %q", ErrHeaderIsNotValid, name)
+		}
+		if _, ok := r.index[name]; ok {
+			return fmt.Errorf("%w: %w %q", ErrHeaderIsNotValid, ErrColumnIsDuplicate, name)
+		}
+		r.index[name] = i
+	}
+	if _, ok := r.index[columnEmail]; !ok {
+		return fmt.Errorf("%w: %w", ErrHeaderIsNotValid, ErrColumnIsMissing)
+	}
+	if _, ok := r.index[columnName]; !ok {
+		return fmt.Errorf("%w: %w", ErrHeaderIsNotValid, ErrColumnIsMissing)
+	}
+	return nil
+}
+
+func (r *Reader) readCSV() (*models.AccountImportRow, error) {
+	fields, err := r.csv.Read()
+	if err != nil {
+		var parseerr *csv.ParseError
+		if errors.As(err, &parseerr) {
+			r.row++
+			return nil, &RecordError{Row: r.row, Err: fmt.Errorf("%w: %w", ErrRecordIsNotValid, parseerr.Err)}
+		}
+		return nil, err
+	}
+	r.row++
+
+	if len(fields) != len(r.index) {
+		return nil, &RecordError{Row: r.row, Err: fmt.Errorf("%w: %d fields instead of %d", ErrRecordIsNotValid, len(fields), len(r.index))}
+	}
+
+	field := func(name string) string {
+		i, ok := r.index[name]
+		if !ok {
+			return ""
+		}
+		return strings.TrimSpace(fields[i])
+	}
+	record := &models.AccountRecord{
+		UUID:         field(columnUUID),
+		Email:        field(columnEmail),
+		Name:         field(columnName),
+		PasswordHash: field(columnPasswordHash),
+		Status:       field(columnStatus),
+	}
+	if record.StatusUntil, err = parseTime(field(columnStatusUntil)); err != nil {
+		return nil, &RecordError{Row: r.row, Err: fmt.Errorf("%w: %s: %w", ErrRecordIsNotValid, columnStatusUntil, err)}
+	}
+	if record.CreatedAt, err = parseTime(field(columnCreatedAt)); err != nil {
+		return nil, &RecordError{Row: r.row, Err: fmt.Errorf("%w: %s: %w", ErrRecordIsNotValid, columnCreatedAt, err)}
+	}
+	return &models.AccountImportRow{Row: r.row, Record: record}, nil
+}
+
+func (r *Reader) readJSONL() (*models.AccountImportRow, error) {
+	for r.lines.Scan() {
+		line := r.lines.Bytes()
+		r.row++
+		// blank lines aren't rows, so the row number is the line number
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+
+		var record models.AccountRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, &RecordError{Row: r.row, Err: fmt.Errorf("%w: %w", ErrRecordIsNotValid, err)}
+		}
+		return &models.AccountImportRow{Row: r.row, Record: &record}, nil
+	}
+	if err := r.lines.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// Writer writes the records of an account file, Flush must be called after the last one
+type Writer struct {
+	csv  *csv.Writer
+	json *json.Encoder
+	buf  *bufio.Writer
+}
+
+func NewWriter(w io.Writer, format string) (*Writer, error) {
+	switch format {
+	case FormatCSV:
+		writer := &Writer{csv: csv.NewWriter(w)}
+		if err := writer.csv.Write(columns); err != nil {
+			return nil, err
+		}
+		return writer, nil
+	case FormatJSONL:
+		buf := bufio.NewWriter(w)
+		return &Writer{json: json.NewEncoder(buf), buf: buf}, nil
+	default:
+		return nil, ErrFormatIsUnknown
+	}
+}
+
+func (w *Writer) Write(record *models.AccountRecord) error {
+	if w.csv != nil {
+		return w.csv.Write([]string{
+			record.UUID,
+			record.Email,
+			record.Name,
+			record.PasswordHash,
+			record.Status,
+			formatTime(record.StatusUntil),
+			formatTime(record.CreatedAt),
+		})
+	}
+	return w.json.Encode(record)
+}
+
+func (w *Writer) Flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		return w.csv.Error()
+	}
+	return w.buf.Flush()
+}
+
+// parseTime parses a Unix time, the empty one is zero
+func parseTime(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+func formatTime(t int64) string {
+	if t == 0 {
+		return ""
+	}
+	return strconv.FormatInt(t, 10)
+}
@@ -0,0 +1,130 @@
+package accountfile
+
+import (
+	"bytes"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"strings"
+	"testing"
+)
+
+// readAll returns the decoded rows and the rows of the record errors
+func readAll(t *testing.T, r *Reader) ([]*models.AccountImportRow, []int) {
+	var (
+		rows    []*models.AccountImportRow
+		invalid []int
+	)
+	for {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, invalid
+		}
+		var recorderr *RecordError
+		if errors.As(err, &recorderr) {
+			assert.ErrorIs(t, err, ErrRecordIsNotValid)
+			invalid = append(invalid, recorderr.Row)
+			continue
+		}
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	format, err := FormatOf("accounts.CSV")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatCSV, format)
+
+	format, err = FormatOf("/tmp/accounts.jsonl")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatJSONL, format)
+
+	_, err = FormatOf("accounts.xlsx")
+	assert.ErrorIs(t, err, ErrFormatIsUnknown)
+}
+
+func TestReader_CSV(t *testing.T) {
+	file := "\ufeffEmail,name,password_hash,created_at\n" +
+		"bob@example.com,Bobby,$2a$10$hash,100\n" +
+		"alice@example.com,Alice\n" +
+		"carol@example.com,Carol,,yesterday\n" +
+		"\"dave@example.com\",\"Dave\",\"\",\"\"\n"
+
+	r, err := NewReader(strings.NewReader(file), FormatCSV)
+	require.NoError(t, err)
+
+	rows, invalid := readAll(t, r)
+	assert.Equal(t, []int{2, 3}, invalid)
+	assert.Equal(t, []*models.AccountImportRow{
+		{Row: 1, Record: &models.AccountRecord{Email: "bob@example.com", Name: "Bobby", PasswordHash: "$2a$10$hash", CreatedAt: 100}},
+		{Row: 4, Record: &models.AccountRecord{Email: "dave@example.com", Name: "Dave"}},
+	}, rows)
+}
+
+func TestReader_CSVHeader(t *testing.T) {
+	testCases := []struct {
+		name   string
+		header string
+	}{
+		{name: "Empty file", header: ""},
+		{name: "Unknown column", header: "email,name,password\n"},
+		{name: "Duplicate column", header: "email,name,email\n"},
+		{name: "Email is missing", header: "uuid,name\n"},
+		{name: "Name is missing", header: "uuid,email\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewReader(strings.NewReader(tc.header), FormatCSV)
+			assert.ErrorIs(t, err, ErrHeaderIsNotValid)
+		})
+	}
+}
+
+func TestReader_JSONL(t *testing.T) {
+	file := `{"email":"bob@example.com","name":"Bobby","status":"suspended","status_until":200}` + "\n" +
+		"\n" +
+		`{"email":"alice@example.com",` + "\n" +
+		`{"uuid":"someuuid","email":"carol@example.com","name":"Carol","created_at":100}`
+
+	r, err := NewReader(strings.NewReader(file), FormatJSONL)
+	require.NoError(t, err)
+
+	rows, invalid := readAll(t, r)
+	assert.Equal(t, []int{3}, invalid)
+	assert.Equal(t, []*models.AccountImportRow{
+		{Row: 1, Record: &models.AccountRecord{Email: "bob@example.com", Name: "Bobby", Status: "suspended", StatusUntil: 200}},
+		{Row: 4, Record: &models.AccountRecord{UUID: "someuuid", Email: "carol@example.com", Name: "Carol", CreatedAt: 100}},
+	}, rows)
+}
+
+func TestWriter_RoundTrip(t *testing.T) {
+	records := []*models.AccountRecord{
+		{UUID: "bobuuid", Email: "bob@example.com", Name: "Bobby", PasswordHash: "$2a$10$hash", Status: "suspended", StatusUntil: 200, CreatedAt: 100},
+		{UUID: "aliceuuid", Email: "alice@example.com", Name: "Alice, Jr.", Status: "active", CreatedAt: 150},
+	}
+
+	for _, format := range []string{FormatCSV, FormatJSONL} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, format)
+			require.NoError(t, err)
+			for _, record := range records {
+				require.NoError(t, w.Write(record))
+			}
+			require.NoError(t, w.Flush())
+
+			r, err := NewReader(&buf, format)
+			require.NoError(t, err)
+			rows, invalid := readAll(t, r)
+			assert.Empty(t, invalid)
+			require.Len(t, rows, len(records))
+			for i, row := range rows {
+				assert.Equal(t, records[i], row.Record)
+			}
+		})
+	}
+}
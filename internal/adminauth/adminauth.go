@@ -0,0 +1,82 @@
+package adminauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+const (
+	// fingerprintLength is the number of the hex digits of the token hash naming an unnamed admin
+	fingerprintLength = 8
+)
+
+var (
+	ErrTokensAreEmpty = errors.New("admin tokens are empty")
+	ErrTokenIsWrong   = errors.New("admin token is wrong")
+)
+
+type actorKey struct{}
+
+// Admins authenticates the admins by their bearer tokens. A token is configured as NAME:TOKEN,
+// the NAME is the actor of the admin changes. An unnamed token is the actor admin-<hash prefix>,
+// so the changes made with it are still told apart
+type Admins struct {
+	// hashes are compared instead of the tokens, so the comparison time doesn't depend on the token lengths
+	hashes [][sha256.Size]byte
+	actors []string
+}
+
+func New(tokens []string) (*Admins, error) {
+	a := &Admins{}
+	for _, entry := range tokens {
+		actor, token, named := strings.Cut(entry, ":")
+		if !named {
+			actor, token = "", entry
+		}
+		if token == "" {
+			continue
+		}
+
+		hash := sha256.Sum256([]byte(token))
+		if actor == "" {
+			actor = "admin-" + hex.EncodeToString(hash[:])[:fingerprintLength]
+		}
+		a.hashes = append(a.hashes, hash)
+		a.actors = append(a.actors, actor)
+	}
+	if len(a.hashes) == 0 {
+		return nil, ErrTokensAreEmpty
+	}
+	return a, nil
+}
+
+// Authenticate returns the actor of the token
+func (a *Admins) Authenticate(token string) (string, error) {
+	hash := sha256.Sum256([]byte(token))
+	found := -1
+	// all the tokens are compared, so the time doesn't tell which one is close
+	for i := range a.hashes {
+		if subtle.ConstantTimeCompare(hash[:], a.hashes[i][:]) == 1 {
+			found = i
+		}
+	}
+	if found < 0 {
+		return "", ErrTokenIsWrong
+	}
+	return a.actors[found], nil
+}
+
+// WithActor keeps the authenticated actor in the context
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the authenticated actor, it's empty if the caller isn't authenticated
+func ActorFrom(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
@@ -0,0 +1,51 @@
+package adminauth
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New(nil)
+	assert.ErrorIs(t, err, ErrTokensAreEmpty)
+
+	_, err = New([]string{"", "alice:"})
+	assert.ErrorIs(t, err, ErrTokensAreEmpty)
+}
+
+func TestAdmins_Authenticate(t *testing.T) {
+	admins, err := New([]string{"alice:alicetoken", "bob:bobtoken", "unnamedtoken", ":emptyname"})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		token         string
+		expectedActor string
+		expectedErr   error
+	}{
+		{name: "Named token", token: "alicetoken", expectedActor: "alice"},
+		{name: "Another named token", token: "bobtoken", expectedActor: "bob"},
+		// sha256("unnamedtoken") starts with c276c66d
+		{name: "Unnamed token", token: "unnamedtoken", expectedActor: "admin-c276c66d"},
+		{name: "Empty name", token: "emptyname", expectedActor: "admin-56e68b21"},
+		{name: "Name isn't a token", token: "alice", expectedErr: ErrTokenIsWrong},
+		{name: "Whole entry isn't a token", token: "alice:alicetoken", expectedErr: ErrTokenIsWrong},
+		{name: "Empty token", token: "", expectedErr: ErrTokenIsWrong},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actor, err := admins.Authenticate(tc.token)
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expectedActor, actor)
+		})
+	}
+}
+
+func TestActor(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, ActorFrom(ctx))
+	assert.Equal(t, "alice", ActorFrom(WithActor(ctx, "alice")))
+}
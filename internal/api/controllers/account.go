@@ -2,10 +2,16 @@ package controllers
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"github.com/alexsibrin/runbot-auth/internal/api/models"
 	"github.com/alexsibrin/runbot-auth/internal/api/validators"
 	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"github.com/alexsibrin/runbot-auth/internal/tracing"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
 	"github.com/google/uuid"
+	"strings"
 	"time"
 )
 
@@ -13,29 +19,71 @@ const (
 	accountControllerKey = "Account"
 )
 
-//go:generate mockgen -destination ./mocks/mocks_controllers.go -package controllers_test github.com/alexsibrin/runbot-auth/internal/api/controllers IAccountUsecase,ISecurer
+// accountSorts are the sorts of the admin search by their API names
+var accountSorts = map[string]string{
+	"":           repositories.AccountSortCreatedAt,
+	"created_at": repositories.AccountSortCreatedAt,
+	"email":      repositories.AccountSortEmail,
+}
+
+// accountCursor is the position after the last account of a page with its sort,
+// it's encoded as base64url JSON so the clients treat it as opaque
+type accountCursor struct {
+	Sort       string `json:"s"`
+	Descending bool   `json:"d,omitempty"`
+	CreatedAt  int64  `json:"c,omitempty"`
+	Email      string `json:"e,omitempty"`
+	UUID       string `json:"u"`
+}
+
+//go:generate mockgen -destination ./mocks/mocks_controllers.go -package controllers_test github.com/alexsibrin/runbot-auth/internal/api/controllers IAccountUsecase,ISecurer,ISessionTracker
 type IAccountUsecase interface {
-	SignIn(ctx context.Context, email, pswd string) (*entities.Account, error)
+	SignIn(ctx context.Context, r *usecases.SignInRequest) (*entities.Account, error)
 	SignUp(ctx context.Context, r *entities.Account) (*entities.Account, error)
 	GetOneByEmail(ctx context.Context, uuid string) (*entities.Account, error)
 	GetOneByUUID(ctx context.Context, uuid string) (*entities.Account, error)
-	ChangeAccountStatus(ctx context.Context, uuid string, status uint8) error
+	GetManyByUUIDs(ctx context.Context, uuids []string) ([]*entities.Account, []string, error)
+	Search(ctx context.Context, filter *repositories.AccountFilter) ([]*entities.Account, error)
+	ChangeAccountStatus(ctx context.Context, r *usecases.AccountStatusChangeRequest) (*entities.StatusChange, error)
+	GetStatusHistory(ctx context.Context, uuid string, limit int) ([]*entities.StatusChange, error)
+	DeleteAccount(ctx context.Context, r *usecases.AccountDeleteRequest) (*entities.Account, error)
+	PurgeTime(account *entities.Account) time.Time
+}
+
+type IEmailBlocklist interface {
+	Check(email string) error
 }
 
 type ISecurer interface {
-	AccessToken(account *entities.Account) (string, error)
-	RefreshToken(account *entities.Account) (string, error)
-	Decrypt(token string) (*entities.Account, error)
+	AccessToken(account *entities.Account, sessionuuid string) (string, error)
+	RefreshToken(account *entities.Account, sessionuuid string) (string, error)
+	// DecryptAccess accepts only the access tokens
+	DecryptAccess(token string) (*entities.TokenClaims, error)
+	// DecryptRefresh accepts only the refresh tokens
+	DecryptRefresh(token string) (*entities.TokenClaims, error)
+}
+
+// ISessionTracker records the devices the tokens are issued to
+type ISessionTracker interface {
+	Start(ctx context.Context, r *usecases.SessionStartRequest) (*entities.Session, error)
+	Refresh(ctx context.Context, r *usecases.SessionRefreshRequest) (*entities.Session, error)
+	// Check rejects the revoked and the expired sessions of the account
+	Check(ctx context.Context, accountuuid, sessionuuid string) error
 }
 
 type AccountDependencies struct {
-	Usecase IAccountUsecase
-	Securer ISecurer
+	Usecase  IAccountUsecase
+	Securer  ISecurer
+	Sessions ISessionTracker
+	// EmailBlocklist is optional, sign up isn't restricted by email domains if it's nil
+	EmailBlocklist IEmailBlocklist
 }
 
 type Account struct {
-	usecase IAccountUsecase
-	securer ISecurer
+	usecase        IAccountUsecase
+	securer        ISecurer
+	sessions       ISessionTracker
+	emailblocklist IEmailBlocklist
 }
 
 func NewAccount(d *AccountDependencies) (*Account, error) {
@@ -48,16 +96,31 @@ func NewAccount(d *AccountDependencies) (*Account, error) {
 	if d.Securer == nil {
 		return nil, NewErrUnitIsNil(accountControllerKey, "Securer")
 	}
+	if d.Sessions == nil {
+		return nil, NewErrUnitIsNil(accountControllerKey, "Sessions")
+	}
 	return &Account{
-		usecase: d.Usecase,
-		securer: d.Securer,
+		usecase:        d.Usecase,
+		securer:        d.Securer,
+		sessions:       d.Sessions,
+		emailblocklist: d.EmailBlocklist,
 	}, nil
 }
 
 func (c *Account) SignUp(ctx context.Context, model *models.SignUp) (*models.SignUpResponse, error) {
-	if err := validators.Email(model.Email); err != nil {
+	ctx, span := tracing.Start(ctx, tracer, "Account.SignUp")
+	defer span.End()
+
+	email, err := validators.NormalizeEmail(model.Email)
+	if err != nil {
 		return nil, err
 	}
+	if c.emailblocklist != nil {
+		if err = c.emailblocklist.Check(email); err != nil {
+			return nil, err
+		}
+	}
+	model.Email = email
 	if err := validators.Password(model.Password); err != nil {
 		return nil, err
 	}
@@ -72,7 +135,7 @@ func (c *Account) SignUp(ctx context.Context, model *models.SignUp) (*models.Sig
 		return nil, err
 	}
 
-	token, err := c.createToken(usecaseresult)
+	token, err := c.createToken(ctx, usecaseresult, model.Client)
 	if err != nil {
 		return nil, err
 	}
@@ -83,19 +146,23 @@ func (c *Account) SignUp(ctx context.Context, model *models.SignUp) (*models.Sig
 }
 
 func (c *Account) SignIn(ctx context.Context, model *models.SignIn) (*models.SignInResponse, error) {
-	if err := validators.Email(model.Email); err != nil {
+	ctx, span := tracing.Start(ctx, tracer, "Account.SignIn")
+	defer span.End()
+
+	email, err := validators.NormalizeEmail(model.Email)
+	if err != nil {
 		return nil, err
 	}
 	if err := validators.Password(model.Password); err != nil {
 		return nil, err
 	}
 
-	account, err := c.usecase.SignIn(ctx, model.Email, model.Password)
+	account, err := c.usecase.SignIn(ctx, c.signIn2Request(email, model))
 	if err != nil {
 		return nil, err
 	}
 
-	token, err := c.createToken(account)
+	token, err := c.createToken(ctx, account, model.Client)
 	if err != nil {
 		return nil, err
 	}
@@ -106,7 +173,11 @@ func (c *Account) SignIn(ctx context.Context, model *models.SignIn) (*models.Sig
 }
 
 func (c *Account) GetOneByEmail(ctx context.Context, email string) (*models.AccountGetModel, error) {
-	if err := validators.Email(email); err != nil {
+	ctx, span := tracing.Start(ctx, tracer, "Account.GetOneByEmail")
+	defer span.End()
+
+	email, err := validators.NormalizeEmail(email)
+	if err != nil {
 		return nil, err
 	}
 	acc, err := c.usecase.GetOneByEmail(ctx, email)
@@ -118,6 +189,9 @@ func (c *Account) GetOneByEmail(ctx context.Context, email string) (*models.Acco
 }
 
 func (c *Account) GetOneByUUID(ctx context.Context, uuid string) (*models.AccountGetModel, error) {
+	ctx, span := tracing.Start(ctx, tracer, "Account.GetOneByUUID")
+	defer span.End()
+
 	acc, err := c.usecase.GetOneByUUID(ctx, uuid)
 	if err != nil {
 		return nil, err
@@ -126,20 +200,142 @@ func (c *Account) GetOneByUUID(ctx context.Context, uuid string) (*models.Accoun
 	return result, nil
 }
 
-func (c *Account) RefreshToken(_ context.Context, token string) (string, error) {
-	account, err := c.securer.Decrypt(token)
+// GetManyByUUIDs returns the found accounts in the order of the uuids and the missing uuids
+func (c *Account) GetManyByUUIDs(ctx context.Context, uuids []string) (*models.AccountBatchGetResponse, error) {
+	ctx, span := tracing.Start(ctx, tracer, "Account.GetManyByUUIDs")
+	defer span.End()
+
+	for _, accountuuid := range uuids {
+		if err := validators.AccountUUID(accountuuid); err != nil {
+			return nil, err
+		}
+	}
+
+	accounts, missing, err := c.usecase.GetManyByUUIDs(ctx, uuids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.AccountBatchGetResponse{
+		Accounts: make([]*models.AccountGetModel, 0, len(accounts)),
+		Missing:  missing,
+	}
+	for _, acc := range accounts {
+		result.Accounts = append(result.Accounts, c.accountEntity2AccountGetModel(acc))
+	}
+	return result, nil
+}
+
+// Search returns a page of the accounts matching the filters for admins, the empty cursor is the first page
+func (c *Account) Search(ctx context.Context, model *models.AccountsSearch) (*models.AccountsResponse, error) {
+	ctx, span := tracing.Start(ctx, tracer, "Account.Search")
+	defer span.End()
+
+	filter, err := c.accountsSearch2Filter(model)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := c.usecase.Search(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.AccountsResponse{
+		Accounts: make([]*models.AccountSummary, 0, len(accounts)),
+	}
+	for _, acc := range accounts {
+		result.Accounts = append(result.Accounts, c.accountEntity2AccountSummary(acc))
+	}
+	if len(accounts) > 0 && len(accounts) >= usecases.AccountsLimit(model.Limit) {
+		result.NextCursor = encodeAccountCursor(filter, accounts[len(accounts)-1])
+	}
+	return result, nil
+}
+
+// RefreshToken issues a new refresh token of the same session, the tokens of revoked sessions are rejected
+func (c *Account) RefreshToken(ctx context.Context, token string, client *models.Client) (string, error) {
+	ctx, span := tracing.Start(ctx, tracer, "Account.RefreshToken")
+	defer span.End()
+
+	claims, err := c.securer.DecryptRefresh(token)
 	if err != nil {
 		return "", err
 	}
+	account, err := c.activeAccount(ctx, claims)
+	if err != nil {
+		return "", err
+	}
+	// the tokens issued before the sessions are tracked can't be revoked, so they aren't refreshed
+	if claims.SessionUUID == "" {
+		return "", usecases.ErrSessionIsNotExist
+	}
 
-	rtoken, err := c.securer.RefreshToken(account)
+	request := c.client2SessionRefreshRequest(claims, client)
+	session, err := c.sessions.Refresh(ctx, request)
+	if err != nil {
+		return "", err
+	}
+
+	rtoken, err := c.securer.RefreshToken(account, session.UUID)
 	if err != nil {
 		return "", err
 	}
 	return rtoken, nil
 }
 
+// Authenticate returns the UUID of the token owner, the tokens of inactive accounts
+// and of revoked or expired sessions are rejected
+func (c *Account) Authenticate(ctx context.Context, token string) (string, error) {
+	ctx, span := tracing.Start(ctx, tracer, "Account.Authenticate")
+	defer span.End()
+
+	claims, err := c.securer.DecryptAccess(token)
+	if err != nil {
+		return "", err
+	}
+	account, err := c.activeAccount(ctx, claims)
+	if err != nil {
+		return "", err
+	}
+	// the tokens without a session can't be revoked, so they aren't accepted
+	if claims.SessionUUID == "" {
+		return "", usecases.ErrSessionIsNotExist
+	}
+	if err = c.sessions.Check(ctx, account.UUID, claims.SessionUUID); err != nil {
+		return "", err
+	}
+	return account.UUID, nil
+}
+
+// DeleteAccount deletes the account on behalf of the actor, it's the account itself for the user-initiated deletion
+func (c *Account) DeleteAccount(ctx context.Context, model *models.DeleteAccount) (*models.DeleteAccountResponse, error) {
+	ctx, span := tracing.Start(ctx, tracer, "Account.DeleteAccount")
+	defer span.End()
+
+	if err := validators.AccountUUID(model.UUID); err != nil {
+		return nil, err
+	}
+	if err := validators.Actor(model.Actor); err != nil {
+		return nil, err
+	}
+
+	account, err := c.usecase.DeleteAccount(ctx, &usecases.AccountDeleteRequest{
+		UUID:  model.UUID,
+		Actor: model.Actor,
+		Audit: auditMetadata2Request(model.Audit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.accountEntity2DeleteAccountResponse(account), nil
+}
+
 func (c *Account) ChangeAccountStatus(ctx context.Context, model *models.ChangeAccountStatus) (*models.ChangeAccountStatusResponse, error) {
+	ctx, span := tracing.Start(ctx, tracer, "Account.ChangeAccountStatus")
+	defer span.End()
+
 	err := validators.AccountUUID(model.UUID)
 	if err != nil {
 		return nil, err
@@ -148,15 +344,57 @@ func (c *Account) ChangeAccountStatus(ctx context.Context, model *models.ChangeA
 	if err != nil {
 		return nil, err
 	}
+	err = validators.StatusUntil(model.Status, model.Until, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	err = validators.StatusReason(model.Reason)
+	if err != nil {
+		return nil, err
+	}
+	err = validators.Actor(model.Actor)
+	if err != nil {
+		return nil, err
+	}
 
-	err = c.usecase.ChangeAccountStatus(ctx, model.UUID, model.Status)
+	change, err := c.usecase.ChangeAccountStatus(ctx, c.changeAccountStatus2Request(model))
 	if err != nil {
 		return nil, err
 	}
-	response := c.changeAccountStatus2Response(model)
+	response := c.statusChange2ChangeAccountStatusResponse(change)
 	return response, nil
 }
 
+// GetStatusHistory returns up to limit latest status changes, limit is chosen by the usecase if it isn't positive
+func (c *Account) GetStatusHistory(ctx context.Context, uuid string, limit int) (*models.StatusHistoryResponse, error) {
+	ctx, span := tracing.Start(ctx, tracer, "Account.GetStatusHistory")
+	defer span.End()
+
+	if err := validators.AccountUUID(uuid); err != nil {
+		return nil, err
+	}
+
+	changes, err := c.usecase.GetStatusHistory(ctx, uuid, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.statusChanges2StatusHistoryResponse(uuid, changes), nil
+}
+
+// activeAccount is the current state of the token owner, so tokens are revoked as soon as the account is deactivated
+func (c *Account) activeAccount(ctx context.Context, claims *entities.TokenClaims) (*entities.Account, error) {
+	account, err := c.usecase.GetOneByUUID(ctx, claims.AccountUUID)
+	if err != nil {
+		return nil, err
+	}
+	if !account.IsActive() {
+		return nil, usecases.ErrAccountIsNotActive
+	}
+
+	return account, nil
+}
+
 func (c *Account) accountCreateModel2Entity(acc *models.SignUp) *entities.Account {
 	return &entities.Account{
 		UUID:      uuid.NewString(),
@@ -202,13 +440,76 @@ func (c *Account) accountEntity2AccountGetModel(acc *entities.Account) *models.A
 	}
 }
 
-func (c *Account) createToken(a *entities.Account) (*models.Token, error) {
-	atoken, err := c.securer.AccessToken(a)
+func (c *Account) accountEntity2AccountSummary(acc *entities.Account) *models.AccountSummary {
+	return &models.AccountSummary{
+		UUID:        acc.UUID,
+		Email:       acc.Email,
+		Name:        acc.Name,
+		Status:      entities.StatusName(acc.Status),
+		StatusUntil: acc.StatusUntil,
+		CreatedAt:   acc.CreatedAt,
+		UpdatedAt:   acc.UpdatedAt,
+		DeletedAt:   acc.DeletedAt,
+	}
+}
+
+func (c *Account) accountsSearch2Filter(model *models.AccountsSearch) (*repositories.AccountFilter, error) {
+	sort, ok := accountSorts[model.Sort]
+	if !ok {
+		return nil, validators.ErrAccountSortIsNotValid
+	}
+	if err := validators.TimeRange(model.CreatedSince, model.CreatedUntil); err != nil {
+		return nil, err
+	}
+
+	filter := &repositories.AccountFilter{
+		EmailPrefix:  strings.ToLower(model.EmailPrefix),
+		NameContains: model.NameContains,
+		CreatedSince: model.CreatedSince,
+		CreatedUntil: model.CreatedUntil,
+		Sort:         sort,
+		Descending:   model.Descending,
+		Limit:        model.Limit,
+	}
+	for _, name := range model.Statuses {
+		status, ok := entities.StatusByName(name)
+		if !ok {
+			return nil, validators.ErrStatusIsNotValid
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+	if model.Cursor != "" {
+		after, err := decodeAccountCursor(model.Cursor, sort, model.Descending)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+	return filter, nil
+}
+
+func (c *Account) accountEntity2DeleteAccountResponse(acc *entities.Account) *models.DeleteAccountResponse {
+	return &models.DeleteAccountResponse{
+		UUID:      acc.UUID,
+		Status:    acc.Status,
+		DeletedAt: acc.DeletedAt,
+		PurgeAt:   c.usecase.PurgeTime(acc).Unix(),
+	}
+}
+
+// createToken starts a new session of the client, the tokens are bound to it
+func (c *Account) createToken(ctx context.Context, a *entities.Account, client *models.Client) (*models.Token, error) {
+	session, err := c.sessions.Start(ctx, c.client2SessionStartRequest(a, client))
+	if err != nil {
+		return nil, err
+	}
+
+	atoken, err := c.securer.AccessToken(a, session.UUID)
 	if err != nil {
 		return nil, err
 	}
 
-	rtoken, err := c.securer.RefreshToken(a)
+	rtoken, err := c.securer.RefreshToken(a, session.UUID)
 	if err != nil {
 		return nil, err
 	}
@@ -219,10 +520,114 @@ func (c *Account) createToken(a *entities.Account) (*models.Token, error) {
 	}, nil
 }
 
-func (c *Account) changeAccountStatus2Response(model *models.ChangeAccountStatus) *models.ChangeAccountStatusResponse {
+func (c *Account) signIn2Request(email string, model *models.SignIn) *usecases.SignInRequest {
+	request := &usecases.SignInRequest{
+		Email:    email,
+		Password: model.Password,
+	}
+	if model.Client != nil {
+		request.IP = model.Client.IP
+		request.UserAgent = model.Client.UserAgent
+	}
+	return request
+}
+
+func (c *Account) client2SessionStartRequest(a *entities.Account, client *models.Client) *usecases.SessionStartRequest {
+	request := &usecases.SessionStartRequest{
+		AccountUUID: a.UUID,
+	}
+	if client != nil {
+		request.IP = client.IP
+		request.UserAgent = client.UserAgent
+	}
+	return request
+}
+
+func (c *Account) client2SessionRefreshRequest(claims *entities.TokenClaims, client *models.Client) *usecases.SessionRefreshRequest {
+	request := &usecases.SessionRefreshRequest{
+		UUID:        claims.SessionUUID,
+		AccountUUID: claims.AccountUUID,
+	}
+	if client != nil {
+		request.IP = client.IP
+		request.UserAgent = client.UserAgent
+	}
+	return request
+}
+
+func (c *Account) changeAccountStatus2Request(model *models.ChangeAccountStatus) *usecases.AccountStatusChangeRequest {
+	return &usecases.AccountStatusChangeRequest{
+		UUID:   model.UUID,
+		Status: model.Status,
+		Reason: model.Reason,
+		Actor:  model.Actor,
+		Until:  model.Until,
+		Audit:  auditMetadata2Request(model.Audit),
+	}
+}
+
+func (c *Account) statusChange2ChangeAccountStatusResponse(change *entities.StatusChange) *models.ChangeAccountStatusResponse {
 	return &models.ChangeAccountStatusResponse{
-		UUID:      model.UUID,
-		Status:    model.Status,
-		UpdatedAt: time.Now().Unix(),
+		UUID:      change.AccountUUID,
+		Status:    change.ToStatus,
+		Until:     change.Until,
+		UpdatedAt: change.CreatedAt,
+	}
+}
+
+func (c *Account) statusChanges2StatusHistoryResponse(uuid string, changes []*entities.StatusChange) *models.StatusHistoryResponse {
+	result := &models.StatusHistoryResponse{
+		UUID:    uuid,
+		Changes: make([]*models.StatusChange, 0, len(changes)),
+	}
+	for _, change := range changes {
+		result.Changes = append(result.Changes, &models.StatusChange{
+			ID:         change.ID,
+			FromStatus: change.FromStatus,
+			ToStatus:   change.ToStatus,
+			Reason:     change.Reason,
+			Actor:      change.Actor,
+			Until:      change.Until,
+			CreatedAt:  change.CreatedAt,
+		})
 	}
+	return result
+}
+
+// encodeAccountCursor keeps only the key of the sort, so the cursor doesn't carry more of the account than needed
+func encodeAccountCursor(filter *repositories.AccountFilter, last *entities.Account) string {
+	cursor := accountCursor{
+		Sort:       filter.Sort,
+		Descending: filter.Descending,
+		UUID:       last.UUID,
+	}
+	switch filter.Sort {
+	case repositories.AccountSortEmail:
+		cursor.Email = strings.ToLower(last.Email)
+	default:
+		cursor.CreatedAt = last.CreatedAt
+	}
+	// the marshaling of the plain struct doesn't fail
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeAccountCursor rejects the cursors of another sort, they point to a position in a different order
+func decodeAccountCursor(s, sort string, descending bool) (*repositories.AccountKey, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, validators.ErrCursorIsNotValid
+	}
+	var cursor accountCursor
+	if err = json.Unmarshal(data, &cursor); err != nil {
+		return nil, validators.ErrCursorIsNotValid
+	}
+	if cursor.Sort != sort || cursor.Descending != descending || validators.AccountUUID(cursor.UUID) != nil {
+		return nil, validators.ErrCursorIsNotValid
+	}
+	return &repositories.AccountKey{
+		CreatedAt: cursor.CreatedAt,
+		Email:     cursor.Email,
+		UUID:      cursor.UUID,
+	}, nil
 }
@@ -8,13 +8,16 @@ import (
 	"github.com/alexsibrin/runbot-auth/internal/api/models"
 	"github.com/alexsibrin/runbot-auth/internal/api/validators"
 	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
 	"github.com/alexsibrin/runbot-auth/internal/usecases"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 	"golang.org/x/crypto/bcrypt"
 	"testing"
+	"time"
 )
 
 func TestSignUp(t *testing.T) {
@@ -25,6 +28,7 @@ func TestSignUp(t *testing.T) {
 
 	usecase := controllers_test.NewMockIAccountUsecase(ctrl)
 	securer := controllers_test.NewMockISecurer(ctrl)
+	sessions := controllers_test.NewMockISessionTracker(ctrl)
 
 	testcases := []struct {
 		name        string
@@ -41,8 +45,9 @@ func TestSignUp(t *testing.T) {
 			},
 			setupMocks: func() {
 				usecase.EXPECT().SignUp(gomock.Any(), gomock.Any()).Return(&entities.Account{}, nil)
-				securer.EXPECT().AccessToken(gomock.Any()).Return("atoken", nil)
-				securer.EXPECT().RefreshToken(gomock.Any()).Return("rtoken", nil)
+				sessions.EXPECT().Start(gomock.Any(), gomock.Any()).Return(&entities.Session{UUID: "sessionuuid"}, nil)
+				securer.EXPECT().AccessToken(gomock.Any(), "sessionuuid").Return("atoken", nil)
+				securer.EXPECT().RefreshToken(gomock.Any(), "sessionuuid").Return("rtoken", nil)
 			},
 			expectedErr: nil,
 		},
@@ -108,6 +113,33 @@ func TestSignUp(t *testing.T) {
 			},
 			expectedErr: usecases.ErrAccountAlreadyExist,
 		},
+		{
+			name: "Disposable email domain",
+			in: &models.SignUp{
+				Email:    "test@mailinator.com",
+				Password: "strongpswd",
+				Name:     "SomeName",
+			},
+			setupMocks:  func() {},
+			expectedErr: validators.ErrEmailDomainIsNotAllowed,
+		},
+		{
+			name: "Email is normalized",
+			in: &models.SignUp{
+				Email:    "Test+Runbot@Test.Technology",
+				Password: "strongpswd",
+				Name:     "SomeName",
+			},
+			setupMocks: func() {
+				usecase.EXPECT().SignUp(gomock.Any(), gomock.Cond(func(x any) bool {
+					return x.(*entities.Account).Email == "test+runbot@test.technology"
+				})).Return(&entities.Account{}, nil)
+				sessions.EXPECT().Start(gomock.Any(), gomock.Any()).Return(&entities.Session{UUID: "sessionuuid"}, nil)
+				securer.EXPECT().AccessToken(gomock.Any(), "sessionuuid").Return("atoken", nil)
+				securer.EXPECT().RefreshToken(gomock.Any(), "sessionuuid").Return("rtoken", nil)
+			},
+			expectedErr: nil,
+		},
 		{
 			name: "Other account usecase error",
 			in: &models.SignUp{
@@ -122,12 +154,17 @@ func TestSignUp(t *testing.T) {
 		},
 	}
 
+	blocklist, err := validators.NewEmailDomainBlocklist([]string{"mailinator.com"})
+	assert.NoError(t, err)
+
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.setupMocks()
 			account := &Account{
-				usecase: usecase,
-				securer: securer,
+				usecase:        usecase,
+				securer:        securer,
+				sessions:       sessions,
+				emailblocklist: blocklist,
 			}
 
 			acc, err := account.SignUp(ctx, tc.in)
@@ -149,6 +186,7 @@ func TestSignIn(t *testing.T) {
 
 	usecase := controllers_test.NewMockIAccountUsecase(ctrl)
 	securer := controllers_test.NewMockISecurer(ctrl)
+	sessions := controllers_test.NewMockISessionTracker(ctrl)
 
 	ctx := context.TODO()
 
@@ -165,12 +203,63 @@ func TestSignIn(t *testing.T) {
 				Password: "strongpswd",
 			},
 			setupMocks: func() {
-				usecase.EXPECT().SignIn(ctx, gomock.Any(), gomock.Any()).Return(&entities.Account{}, nil)
-				securer.EXPECT().AccessToken(gomock.Any()).Return("atoken", nil)
-				securer.EXPECT().RefreshToken(gomock.Any()).Return("rtoken", nil)
+				usecase.EXPECT().SignIn(ctx, gomock.Any()).Return(&entities.Account{}, nil)
+				sessions.EXPECT().Start(gomock.Any(), gomock.Any()).Return(&entities.Session{UUID: "sessionuuid"}, nil)
+				securer.EXPECT().AccessToken(gomock.Any(), "sessionuuid").Return("atoken", nil)
+				securer.EXPECT().RefreshToken(gomock.Any(), "sessionuuid").Return("rtoken", nil)
 			},
 			expectedErr: nil,
 		},
+		{
+			name: "Email is normalized",
+			in: &models.SignIn{
+				Email:    "Test@Test.RU",
+				Password: "strongpswd",
+			},
+			setupMocks: func() {
+				usecase.EXPECT().SignIn(ctx, &usecases.SignInRequest{Email: "test@test.ru", Password: "strongpswd"}).Return(&entities.Account{}, nil)
+				sessions.EXPECT().Start(gomock.Any(), gomock.Any()).Return(&entities.Session{UUID: "sessionuuid"}, nil)
+				securer.EXPECT().AccessToken(gomock.Any(), "sessionuuid").Return("atoken", nil)
+				securer.EXPECT().RefreshToken(gomock.Any(), "sessionuuid").Return("rtoken", nil)
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "Session is started for the client",
+			in: &models.SignIn{
+				Email:    "test@test.ru",
+				Password: "strongpswd",
+				Client:   &models.Client{IP: "10.0.0.1", UserAgent: "someagent"},
+			},
+			setupMocks: func() {
+				usecase.EXPECT().SignIn(ctx, &usecases.SignInRequest{
+					Email:     "test@test.ru",
+					Password:  "strongpswd",
+					IP:        "10.0.0.1",
+					UserAgent: "someagent",
+				}).Return(&entities.Account{UUID: "someuuid"}, nil)
+				sessions.EXPECT().Start(ctx, &usecases.SessionStartRequest{
+					AccountUUID: "someuuid",
+					IP:          "10.0.0.1",
+					UserAgent:   "someagent",
+				}).Return(&entities.Session{UUID: "sessionuuid"}, nil)
+				securer.EXPECT().AccessToken(gomock.Any(), "sessionuuid").Return("atoken", nil)
+				securer.EXPECT().RefreshToken(gomock.Any(), "sessionuuid").Return("rtoken", nil)
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "Session is not started",
+			in: &models.SignIn{
+				Email:    "test@test.ru",
+				Password: "strongpswd",
+			},
+			setupMocks: func() {
+				usecase.EXPECT().SignIn(ctx, gomock.Any()).Return(&entities.Account{}, nil)
+				sessions.EXPECT().Start(ctx, gomock.Any()).Return(nil, sql.ErrConnDone)
+			},
+			expectedErr: sql.ErrConnDone,
+		},
 		{
 			name: "Wrong password",
 			in: &models.SignIn{
@@ -178,7 +267,7 @@ func TestSignIn(t *testing.T) {
 				Password: "somewrongpassword",
 			},
 			setupMocks: func() {
-				usecase.EXPECT().SignIn(ctx, gomock.Any(), gomock.Any()).Return(nil, bcrypt.ErrMismatchedHashAndPassword)
+				usecase.EXPECT().SignIn(ctx, gomock.Any()).Return(nil, bcrypt.ErrMismatchedHashAndPassword)
 			},
 			expectedErr: bcrypt.ErrMismatchedHashAndPassword,
 		},
@@ -189,7 +278,7 @@ func TestSignIn(t *testing.T) {
 				Password: "strongpswd",
 			},
 			setupMocks: func() {
-				usecase.EXPECT().SignIn(ctx, gomock.Any(), gomock.Any()).Return(nil, sql.ErrNoRows)
+				usecase.EXPECT().SignIn(ctx, gomock.Any()).Return(nil, sql.ErrNoRows)
 			},
 			expectedErr: sql.ErrNoRows,
 		},
@@ -199,8 +288,9 @@ func TestSignIn(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.setupMocks()
 			account := &Account{
-				usecase: usecase,
-				securer: securer,
+				usecase:  usecase,
+				securer:  securer,
+				sessions: sessions,
 			}
 
 			result, err := account.SignIn(ctx, tc.in)
@@ -334,6 +424,11 @@ func TestRefreshToken(t *testing.T) {
 	defer ctrl.Finish()
 
 	securer := controllers_test.NewMockISecurer(ctrl)
+	mockedUsecase := controllers_test.NewMockIAccountUsecase(ctrl)
+	sessions := controllers_test.NewMockISessionTracker(ctrl)
+
+	client := &models.Client{IP: "10.0.0.2", UserAgent: "someagent"}
+	claims := &entities.TokenClaims{AccountUUID: "someuuid", SessionUUID: "sessionuuid"}
 
 	ctx := context.TODO()
 
@@ -347,16 +442,51 @@ func TestRefreshToken(t *testing.T) {
 			name: "Valid case",
 			in:   "sometoken",
 			setupMocks: func() {
-				securer.EXPECT().Decrypt(gomock.Any()).Return(&entities.Account{}, nil)
-				securer.EXPECT().RefreshToken(gomock.Any()).Return("newtoken", nil)
+				securer.EXPECT().DecryptRefresh(gomock.Any()).Return(claims, nil)
+				mockedUsecase.EXPECT().GetOneByUUID(ctx, "someuuid").Return(&entities.Account{UUID: "someuuid", Status: entities.Active}, nil)
+				sessions.EXPECT().Refresh(ctx, &usecases.SessionRefreshRequest{
+					UUID:        "sessionuuid",
+					AccountUUID: "someuuid",
+					IP:          "10.0.0.2",
+					UserAgent:   "someagent",
+				}).Return(&entities.Session{UUID: "sessionuuid"}, nil)
+				securer.EXPECT().RefreshToken(gomock.Any(), "sessionuuid").Return("newtoken", nil)
 			},
 			expectedErr: nil,
 		},
+		{
+			name: "Session is revoked",
+			in:   "sometoken",
+			setupMocks: func() {
+				securer.EXPECT().DecryptRefresh(gomock.Any()).Return(claims, nil)
+				mockedUsecase.EXPECT().GetOneByUUID(ctx, "someuuid").Return(&entities.Account{UUID: "someuuid", Status: entities.Active}, nil)
+				sessions.EXPECT().Refresh(ctx, gomock.Any()).Return(nil, usecases.ErrSessionIsRevoked)
+			},
+			expectedErr: usecases.ErrSessionIsRevoked,
+		},
+		{
+			name: "Token has no session",
+			in:   "sometoken",
+			setupMocks: func() {
+				securer.EXPECT().DecryptRefresh(gomock.Any()).Return(&entities.TokenClaims{AccountUUID: "someuuid"}, nil)
+				mockedUsecase.EXPECT().GetOneByUUID(ctx, "someuuid").Return(&entities.Account{UUID: "someuuid", Status: entities.Active}, nil)
+			},
+			expectedErr: usecases.ErrSessionIsNotExist,
+		},
+		{
+			name: "Account is deleted",
+			in:   "sometoken",
+			setupMocks: func() {
+				securer.EXPECT().DecryptRefresh(gomock.Any()).Return(claims, nil)
+				mockedUsecase.EXPECT().GetOneByUUID(ctx, "someuuid").Return(&entities.Account{UUID: "someuuid", Status: entities.Deleted}, nil)
+			},
+			expectedErr: usecases.ErrAccountIsNotActive,
+		},
 		{
 			name: "Wrong token signature",
 			in:   "somewrongtoken",
 			setupMocks: func() {
-				securer.EXPECT().Decrypt(gomock.Any()).Return(nil, jwt.ErrTokenSignatureInvalid)
+				securer.EXPECT().DecryptRefresh(gomock.Any()).Return(nil, jwt.ErrTokenSignatureInvalid)
 			},
 			expectedErr: jwt.ErrTokenSignatureInvalid,
 		},
@@ -364,7 +494,7 @@ func TestRefreshToken(t *testing.T) {
 			name: "Wrong token hash",
 			in:   "somewrongtoken",
 			setupMocks: func() {
-				securer.EXPECT().Decrypt(gomock.Any()).Return(nil, jwt.ErrHashUnavailable)
+				securer.EXPECT().DecryptRefresh(gomock.Any()).Return(nil, jwt.ErrHashUnavailable)
 			},
 			expectedErr: jwt.ErrHashUnavailable,
 		},
@@ -374,9 +504,11 @@ func TestRefreshToken(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.setupMocks()
 			account := &Account{
-				securer: securer,
+				usecase:  mockedUsecase,
+				securer:  securer,
+				sessions: sessions,
 			}
-			token, err := account.RefreshToken(ctx, tc.in)
+			token, err := account.RefreshToken(ctx, tc.in, client)
 			if tc.expectedErr != nil {
 				assert.Error(t, err)
 				assert.ErrorIs(t, err, tc.expectedErr)
@@ -398,6 +530,7 @@ func TestAccount_ChangeAccountStatus(t *testing.T) {
 	mockedUsecase := controllers_test.NewMockIAccountUsecase(ctrl)
 
 	validuuid := uuid.NewString()
+	until := time.Now().Add(time.Hour).Unix()
 
 	testCases := []struct {
 		name          string
@@ -410,14 +543,32 @@ func TestAccount_ChangeAccountStatus(t *testing.T) {
 			name: "Valid case",
 			in: &models.ChangeAccountStatus{
 				UUID:   validuuid,
-				Status: 1,
+				Status: entities.Suspended,
+				Reason: "spam",
+				Actor:  "admin",
+				Until:  until,
 			},
 			setupMocks: func() {
-				mockedUsecase.EXPECT().ChangeAccountStatus(ctx, gomock.Any(), gomock.Any()).Return(nil)
+				mockedUsecase.EXPECT().ChangeAccountStatus(ctx, &usecases.AccountStatusChangeRequest{
+					UUID:   validuuid,
+					Status: entities.Suspended,
+					Reason: "spam",
+					Actor:  "admin",
+					Until:  until,
+				}).Return(&entities.StatusChange{
+					ID:          1,
+					AccountUUID: validuuid,
+					FromStatus:  entities.Active,
+					ToStatus:    entities.Suspended,
+					Until:       until,
+					CreatedAt:   100,
+				}, nil)
 			},
 			out: &models.ChangeAccountStatusResponse{
-				UUID:   validuuid,
-				Status: 1,
+				UUID:      validuuid,
+				Status:    entities.Suspended,
+				Until:     until,
+				UpdatedAt: 100,
 			},
 			expectedError: nil,
 		},
@@ -443,6 +594,30 @@ func TestAccount_ChangeAccountStatus(t *testing.T) {
 			out:           nil,
 			expectedError: validators.ErrStatusIsNotValid,
 		},
+		{
+			name: "Until is in the past",
+			in: &models.ChangeAccountStatus{
+				UUID:   validuuid,
+				Status: entities.Suspended,
+				Until:  time.Now().Add(-time.Hour).Unix(),
+			},
+			setupMocks: func() {
+			},
+			out:           nil,
+			expectedError: validators.ErrStatusUntilIsNotValid,
+		},
+		{
+			name: "Account is not exist",
+			in: &models.ChangeAccountStatus{
+				UUID:   validuuid,
+				Status: entities.Blocked,
+			},
+			setupMocks: func() {
+				mockedUsecase.EXPECT().ChangeAccountStatus(ctx, gomock.Any()).Return(nil, usecases.ErrAccountIsNotExist)
+			},
+			out:           nil,
+			expectedError: usecases.ErrAccountIsNotExist,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -454,16 +629,382 @@ func TestAccount_ChangeAccountStatus(t *testing.T) {
 
 			result, err := account.ChangeAccountStatus(ctx, tc.in)
 
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.out, result)
+		})
+	}
+}
+
+func TestAccount_GetStatusHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+
+	mockedUsecase := controllers_test.NewMockIAccountUsecase(ctrl)
+
+	validuuid := uuid.NewString()
+
+	testCases := []struct {
+		name          string
+		in            string
+		setupMocks    func()
+		out           *models.StatusHistoryResponse
+		expectedError error
+	}{
+		{
+			name: "Valid case",
+			in:   validuuid,
+			setupMocks: func() {
+				mockedUsecase.EXPECT().GetStatusHistory(ctx, validuuid, 10).Return([]*entities.StatusChange{
+					{ID: 2, AccountUUID: validuuid, FromStatus: entities.Suspended, ToStatus: entities.Active, Actor: entities.SystemActor, CreatedAt: 200},
+					{ID: 1, AccountUUID: validuuid, FromStatus: entities.Active, ToStatus: entities.Suspended, Reason: "spam", Actor: "admin", Until: 200, CreatedAt: 100},
+				}, nil)
+			},
+			out: &models.StatusHistoryResponse{
+				UUID: validuuid,
+				Changes: []*models.StatusChange{
+					{ID: 2, FromStatus: entities.Suspended, ToStatus: entities.Active, Actor: entities.SystemActor, CreatedAt: 200},
+					{ID: 1, FromStatus: entities.Active, ToStatus: entities.Suspended, Reason: "spam", Actor: "admin", Until: 200, CreatedAt: 100},
+				},
+			},
+		},
+		{
+			name:          "Invalid UUID",
+			in:            "invaliduuid",
+			setupMocks:    func() {},
+			expectedError: validators.ErrUUIDIsNotValid,
+		},
+		{
+			name: "Account is not exist",
+			in:   validuuid,
+			setupMocks: func() {
+				mockedUsecase.EXPECT().GetStatusHistory(ctx, validuuid, 10).Return(nil, usecases.ErrAccountIsNotExist)
+			},
+			expectedError: usecases.ErrAccountIsNotExist,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			account := &Account{
+				usecase: mockedUsecase,
+			}
+
+			result, err := account.GetStatusHistory(ctx, tc.in, 10)
+
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.out, result)
+		})
+	}
+}
+
+func TestAccount_GetManyByUUIDs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+
+	mockedUsecase := controllers_test.NewMockIAccountUsecase(ctrl)
+
+	founduuid, missinguuid := uuid.NewString(), uuid.NewString()
+
+	testCases := []struct {
+		name          string
+		in            []string
+		setupMocks    func()
+		out           *models.AccountBatchGetResponse
+		expectedError error
+	}{
+		{
+			name: "Valid case",
+			in:   []string{founduuid, missinguuid},
+			setupMocks: func() {
+				mockedUsecase.EXPECT().GetManyByUUIDs(ctx, []string{founduuid, missinguuid}).Return([]*entities.Account{
+					{UUID: founduuid, Email: "bob@example.com", Name: "Bob", Password: "hash", CreatedAt: 100},
+				}, []string{missinguuid}, nil)
+			},
+			out: &models.AccountBatchGetResponse{
+				Accounts: []*models.AccountGetModel{{UUID: founduuid, Email: "bob@example.com", Name: "Bob", CreatedAt: 100}},
+				Missing:  []string{missinguuid},
+			},
+		},
+		{
+			name:          "Invalid UUID",
+			in:            []string{founduuid, "invaliduuid"},
+			setupMocks:    func() {},
+			expectedError: validators.ErrUUIDIsNotValid,
+		},
+		{
+			name: "Too many UUIDs",
+			in:   []string{founduuid},
+			setupMocks: func() {
+				mockedUsecase.EXPECT().GetManyByUUIDs(ctx, []string{founduuid}).Return(nil, nil, usecases.ErrTooManyUUIDs)
+			},
+			expectedError: usecases.ErrTooManyUUIDs,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			account := &Account{
+				usecase: mockedUsecase,
+			}
+
+			result, err := account.GetManyByUUIDs(ctx, tc.in)
+
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.out, result)
+		})
+	}
+}
+
+func TestAccount_Search(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+
+	mockedUsecase := controllers_test.NewMockIAccountUsecase(ctrl)
+	account := &Account{
+		usecase: mockedUsecase,
+	}
+
+	found := &entities.Account{UUID: uuid.NewString(), Email: "Bob@example.com", Name: "Bob", Password: "hash", Status: entities.Suspended, CreatedAt: 100}
+
+	t.Run("Pages are continued with the cursor", func(t *testing.T) {
+		mockedUsecase.EXPECT().Search(ctx, &repositories.AccountFilter{
+			Statuses:    []uint8{entities.Suspended, entities.Deleted},
+			EmailPrefix: "bob",
+			Sort:        repositories.AccountSortEmail,
+			Descending:  true,
+			Limit:       1,
+		}).Return([]*entities.Account{found}, nil)
+
+		search := &models.AccountsSearch{Statuses: []string{"suspended", "deleted"}, EmailPrefix: "Bob", Sort: "email", Descending: true, Limit: 1}
+		result, err := account.Search(ctx, search)
+		require.NoError(t, err)
+		assert.Equal(t, []*models.AccountSummary{{UUID: found.UUID, Email: found.Email, Name: "Bob", Status: "suspended", CreatedAt: 100}}, result.Accounts)
+		require.NotEmpty(t, result.NextCursor)
+
+		mockedUsecase.EXPECT().Search(ctx, &repositories.AccountFilter{
+			Statuses:    []uint8{entities.Suspended, entities.Deleted},
+			EmailPrefix: "bob",
+			Sort:        repositories.AccountSortEmail,
+			Descending:  true,
+			After:       &repositories.AccountKey{Email: "bob@example.com", UUID: found.UUID},
+			Limit:       1,
+		}).Return(nil, nil)
+
+		search.Cursor = result.NextCursor
+		result, err = account.Search(ctx, search)
+		require.NoError(t, err)
+		assert.Empty(t, result.Accounts)
+		assert.Empty(t, result.NextCursor)
+	})
+
+	t.Run("Cursor of another sort is not valid", func(t *testing.T) {
+		mockedUsecase.EXPECT().Search(ctx, gomock.Any()).Return([]*entities.Account{found}, nil)
+
+		result, err := account.Search(ctx, &models.AccountsSearch{Limit: 1})
+		require.NoError(t, err)
+		require.NotEmpty(t, result.NextCursor)
+
+		_, err = account.Search(ctx, &models.AccountsSearch{Sort: "email", Cursor: result.NextCursor})
+		assert.ErrorIs(t, err, validators.ErrCursorIsNotValid)
+		_, err = account.Search(ctx, &models.AccountsSearch{Descending: true, Cursor: result.NextCursor})
+		assert.ErrorIs(t, err, validators.ErrCursorIsNotValid)
+	})
+
+	testCases := []struct {
+		name          string
+		in            *models.AccountsSearch
+		expectedError error
+	}{
+		{name: "Invalid sort", in: &models.AccountsSearch{Sort: "name"}, expectedError: validators.ErrAccountSortIsNotValid},
+		{name: "Invalid status", in: &models.AccountsSearch{Statuses: []string{"unknown"}}, expectedError: validators.ErrStatusIsNotValid},
+		{name: "Invalid time range", in: &models.AccountsSearch{CreatedSince: 200, CreatedUntil: 100}, expectedError: validators.ErrTimeRangeIsNotValid},
+		{name: "Invalid cursor", in: &models.AccountsSearch{Cursor: "not a cursor"}, expectedError: validators.ErrCursorIsNotValid},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := account.Search(ctx, tc.in)
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Nil(t, result)
+		})
+	}
+}
+
+func TestAccount_Authenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+
+	securer := controllers_test.NewMockISecurer(ctrl)
+	mockedUsecase := controllers_test.NewMockIAccountUsecase(ctrl)
+	sessions := controllers_test.NewMockISessionTracker(ctrl)
+
+	validuuid := uuid.NewString()
+
+	testCases := []struct {
+		name          string
+		setupMocks    func()
+		expectedError error
+	}{
+		{
+			name: "Valid case",
+			setupMocks: func() {
+				securer.EXPECT().DecryptAccess("sometoken").Return(&entities.TokenClaims{AccountUUID: validuuid, SessionUUID: "sessionuuid"}, nil)
+				mockedUsecase.EXPECT().GetOneByUUID(ctx, validuuid).Return(&entities.Account{UUID: validuuid, Status: entities.Active}, nil)
+				sessions.EXPECT().Check(ctx, validuuid, "sessionuuid").Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Session is revoked",
+			setupMocks: func() {
+				securer.EXPECT().DecryptAccess("sometoken").Return(&entities.TokenClaims{AccountUUID: validuuid, SessionUUID: "sessionuuid"}, nil)
+				mockedUsecase.EXPECT().GetOneByUUID(ctx, validuuid).Return(&entities.Account{UUID: validuuid, Status: entities.Active}, nil)
+				sessions.EXPECT().Check(ctx, validuuid, "sessionuuid").Return(usecases.ErrSessionIsRevoked)
+			},
+			expectedError: usecases.ErrSessionIsRevoked,
+		},
+		{
+			name: "Token without a session",
+			setupMocks: func() {
+				securer.EXPECT().DecryptAccess("sometoken").Return(&entities.TokenClaims{AccountUUID: validuuid}, nil)
+				mockedUsecase.EXPECT().GetOneByUUID(ctx, validuuid).Return(&entities.Account{UUID: validuuid, Status: entities.Active}, nil)
+			},
+			expectedError: usecases.ErrSessionIsNotExist,
+		},
+		{
+			name: "Wrong token signature",
+			setupMocks: func() {
+				securer.EXPECT().DecryptAccess("sometoken").Return(nil, jwt.ErrTokenSignatureInvalid)
+			},
+			expectedError: jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name: "Account is blocked",
+			setupMocks: func() {
+				securer.EXPECT().DecryptAccess("sometoken").Return(&entities.TokenClaims{AccountUUID: validuuid, SessionUUID: "sessionuuid"}, nil)
+				mockedUsecase.EXPECT().GetOneByUUID(ctx, validuuid).Return(&entities.Account{UUID: validuuid, Status: entities.Blocked}, nil)
+			},
+			expectedError: usecases.ErrAccountIsNotActive,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			account := &Account{
+				usecase:  mockedUsecase,
+				securer:  securer,
+				sessions: sessions,
+			}
+
+			accountuuid, err := account.Authenticate(ctx, "sometoken")
+
 			if tc.expectedError != nil {
-				assert.Error(t, err)
 				assert.ErrorIs(t, err, tc.expectedError)
 			} else {
 				assert.NoError(t, err)
-				assert.IsType(t, &models.ChangeAccountStatusResponse{}, result)
-				assert.Equal(t, tc.out.UUID, result.UUID)
-				assert.Equal(t, tc.out.Status, result.Status)
-				assert.NotEqual(t, 0, result.UpdatedAt)
+				assert.Equal(t, validuuid, accountuuid)
+			}
+		})
+	}
+}
+
+func TestAccount_DeleteAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+
+	mockedUsecase := controllers_test.NewMockIAccountUsecase(ctrl)
+
+	validuuid := uuid.NewString()
+	deletedat := time.Now().Unix()
+	purgeat := time.Unix(deletedat, 0).Add(time.Hour)
+
+	testCases := []struct {
+		name          string
+		in            *models.DeleteAccount
+		setupMocks    func()
+		out           *models.DeleteAccountResponse
+		expectedError error
+	}{
+		{
+			name: "Valid case",
+			in:   &models.DeleteAccount{UUID: validuuid, Actor: validuuid},
+			setupMocks: func() {
+				deleted := &entities.Account{UUID: validuuid, Status: entities.Deleted, DeletedAt: deletedat}
+				mockedUsecase.EXPECT().DeleteAccount(ctx, &usecases.AccountDeleteRequest{UUID: validuuid, Actor: validuuid}).Return(deleted, nil)
+				mockedUsecase.EXPECT().PurgeTime(deleted).Return(purgeat)
+			},
+			out: &models.DeleteAccountResponse{
+				UUID:      validuuid,
+				Status:    entities.Deleted,
+				DeletedAt: deletedat,
+				PurgeAt:   purgeat.Unix(),
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Admin deletion is audited",
+			in: &models.DeleteAccount{
+				UUID:  validuuid,
+				Actor: "admin",
+				Audit: &models.AuditMetadata{Method: "/Account/DeleteAccount", IP: "10.0.0.1", UserAgent: "grpc-go/1.61.0"},
+			},
+			setupMocks: func() {
+				deleted := &entities.Account{UUID: validuuid, Status: entities.Deleted, DeletedAt: deletedat}
+				mockedUsecase.EXPECT().DeleteAccount(ctx, &usecases.AccountDeleteRequest{
+					UUID:  validuuid,
+					Actor: "admin",
+					Audit: &usecases.AuditMetadata{Method: "/Account/DeleteAccount", IP: "10.0.0.1", UserAgent: "grpc-go/1.61.0"},
+				}).Return(deleted, nil)
+				mockedUsecase.EXPECT().PurgeTime(deleted).Return(purgeat)
+			},
+			out: &models.DeleteAccountResponse{
+				UUID:      validuuid,
+				Status:    entities.Deleted,
+				DeletedAt: deletedat,
+				PurgeAt:   purgeat.Unix(),
+			},
+		},
+		{
+			name:          "Invalid UUID",
+			in:            &models.DeleteAccount{UUID: "invaliduuid", Actor: "invaliduuid"},
+			setupMocks:    func() {},
+			out:           nil,
+			expectedError: validators.ErrUUIDIsNotValid,
+		},
+		{
+			name: "Account is already deleted",
+			in:   &models.DeleteAccount{UUID: validuuid, Actor: validuuid},
+			setupMocks: func() {
+				mockedUsecase.EXPECT().DeleteAccount(ctx, &usecases.AccountDeleteRequest{UUID: validuuid, Actor: validuuid}).Return(nil, usecases.ErrAccountIsDeleted)
+			},
+			out:           nil,
+			expectedError: usecases.ErrAccountIsDeleted,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			account := &Account{
+				usecase: mockedUsecase,
 			}
+
+			result, err := account.DeleteAccount(ctx, tc.in)
+
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.out, result)
 		})
 	}
 }
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	accountImportControllerKey = "AccountImport"
+)
+
+//go:generate mockgen -destination ./mocks/mocks_accountimport.go -package controllers_test github.com/alexsibrin/runbot-auth/internal/api/controllers IAccountImportUsecase
+type IAccountImportUsecase interface {
+	Import(ctx context.Context, accounts []*entities.Account) ([]string, error)
+	Export(ctx context.Context, send func(account *entities.Account) error) error
+}
+
+type AccountImportDependencies struct {
+	Usecase IAccountImportUsecase
+}
+
+// AccountImport validates the account files of the bulk import and builds the ones of the export
+type AccountImport struct {
+	usecase IAccountImportUsecase
+}
+
+func NewAccountImport(d *AccountImportDependencies) (*AccountImport, error) {
+	if d == nil {
+		return nil, NewErrUnitIsNil(accountImportControllerKey, "whole struct")
+	}
+	if d.Usecase == nil {
+		return nil, NewErrUnitIsNil(accountImportControllerKey, "Usecase")
+	}
+	return &AccountImport{
+		usecase: d.Usecase,
+	}, nil
+}
+
+// Import imports a batch of the rows and returns the rejected ones: the invalid rows
+// and the accounts whose UUID or email exists. The valid rows are imported even if some are rejected
+func (c *AccountImport) Import(ctx context.Context, rows []*models.AccountImportRow) ([]*models.AccountImportError, error) {
+	var rejected []*models.AccountImportError
+
+	now := time.Now().Unix()
+	accounts := make([]*entities.Account, 0, len(rows))
+	byuuid := make(map[string]*models.AccountImportRow, len(rows))
+	for _, row := range rows {
+		account, err := c.accountRecord2Entity(row.Record, now)
+		if err != nil {
+			rejected = append(rejected, &models.AccountImportError{Row: row.Row, Email: row.Record.Email, Err: err})
+			continue
+		}
+		// the skipped accounts are told apart by the UUIDs, so a repeated one is rejected before the import
+		if _, ok := byuuid[account.UUID]; ok {
+			rejected = append(rejected, &models.AccountImportError{Row: row.Row, Email: row.Record.Email, Err: usecases.ErrAccountAlreadyExist})
+			continue
+		}
+		accounts = append(accounts, account)
+		byuuid[account.UUID] = row
+	}
+
+	skipped, err := c.usecase.Import(ctx, accounts)
+	if err != nil {
+		return nil, err
+	}
+	for _, accountuuid := range skipped {
+		row := byuuid[accountuuid]
+		rejected = append(rejected, &models.AccountImportError{Row: row.Row, Email: row.Record.Email, Err: usecases.ErrAccountAlreadyExist})
+	}
+	return rejected, nil
+}
+
+// Export calls send with the record of every account which isn't deleted, the records have the password hashes
+func (c *AccountImport) Export(ctx context.Context, send func(record *models.AccountRecord) error) error {
+	return c.usecase.Export(ctx, func(account *entities.Account) error {
+		return send(&models.AccountRecord{
+			UUID:         account.UUID,
+			Email:        account.Email,
+			Name:         account.Name,
+			PasswordHash: account.Password,
+			Status:       entities.StatusName(account.Status),
+			StatusUntil:  account.StatusUntil,
+			CreatedAt:    account.CreatedAt,
+		})
+	})
+}
+
+func (c *AccountImport) accountRecord2Entity(record *models.AccountRecord, now int64) (*entities.Account, error) {
+	email, err := validators.NormalizeEmail(record.Email)
+	if err != nil {
+		return nil, err
+	}
+	if err = validators.Name(record.Name); err != nil {
+		return nil, err
+	}
+	if err = validators.PasswordHash(record.PasswordHash); err != nil {
+		return nil, err
+	}
+
+	account := &entities.Account{
+		UUID:        record.UUID,
+		Email:       email,
+		Password:    record.PasswordHash,
+		Name:        record.Name,
+		Status:      entities.Active,
+		StatusUntil: record.StatusUntil,
+		CreatedAt:   record.CreatedAt,
+	}
+	if account.UUID == "" {
+		account.UUID = uuid.NewString()
+	} else if err = validators.AccountUUID(account.UUID); err != nil {
+		return nil, err
+	}
+	if record.Status != "" {
+		status, ok := entities.StatusByName(record.Status)
+		// the deleted accounts are purged on schedule, so they aren't imported
+		if !ok || status == entities.Deleted {
+			return nil, validators.ErrStatusIsNotValid
+		}
+		account.Status = status
+	}
+	if err = validators.StatusUntil(account.Status, account.StatusUntil, now); err != nil {
+		return nil, err
+	}
+	if account.CreatedAt < 0 || account.CreatedAt > now {
+		return nil, validators.ErrTimeRangeIsNotValid
+	}
+	if account.CreatedAt == 0 {
+		account.CreatedAt = now
+	}
+	return account, nil
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"context"
+	controllers_test "github.com/alexsibrin/runbot-auth/internal/api/controllers/mocks"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+const testBcryptHash = "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"
+
+func TestAccountImport_Import(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+
+	mockedUsecase := controllers_test.NewMockIAccountImportUsecase(ctrl)
+	controller, err := NewAccountImport(&AccountImportDependencies{Usecase: mockedUsecase})
+	require.NoError(t, err)
+
+	t.Run("Valid rows are imported and invalid ones are rejected", func(t *testing.T) {
+		bobuuid, aliceuuid := uuid.NewString(), uuid.NewString()
+		until := time.Now().Add(time.Hour).Unix()
+		rows := []*models.AccountImportRow{
+			{Row: 1, Record: &models.AccountRecord{UUID: bobuuid, Email: "Bob@Example.com", Name: "Bobby", PasswordHash: testBcryptHash, Status: "suspended", StatusUntil: until, CreatedAt: 100}},
+			{Row: 2, Record: &models.AccountRecord{UUID: aliceuuid, Email: "alice@example.com", Name: "Alice", CreatedAt: 200}},
+			{Row: 3, Record: &models.AccountRecord{Email: "carol@example.com", Name: "Carol", PasswordHash: "plainpassword"}},
+			{Row: 4, Record: &models.AccountRecord{Email: "dave@example.com", Name: "Dave", Status: "deleted"}},
+			{Row: 5, Record: &models.AccountRecord{Email: "erin", Name: "Erin"}},
+			{Row: 6, Record: &models.AccountRecord{UUID: bobuuid, Email: "robert@example.com", Name: "Robert"}},
+		}
+
+		mockedUsecase.EXPECT().Import(ctx, []*entities.Account{
+			{UUID: bobuuid, Email: "bob@example.com", Name: "Bobby", Password: testBcryptHash, Status: entities.Suspended, StatusUntil: until, CreatedAt: 100},
+			{UUID: aliceuuid, Email: "alice@example.com", Name: "Alice", Status: entities.Active, CreatedAt: 200},
+		}).Return([]string{aliceuuid}, nil)
+
+		rejected, err := controller.Import(ctx, rows)
+		require.NoError(t, err)
+		require.Len(t, rejected, 5)
+		assert.Equal(t, &models.AccountImportError{Row: 3, Email: "carol@example.com", Err: validators.ErrPasswordHashIsNotValid}, rejected[0])
+		assert.Equal(t, &models.AccountImportError{Row: 4, Email: "dave@example.com", Err: validators.ErrStatusIsNotValid}, rejected[1])
+		assert.Equal(t, 5, rejected[2].Row)
+		assert.Error(t, rejected[2].Err)
+		assert.Equal(t, &models.AccountImportError{Row: 6, Email: "robert@example.com", Err: usecases.ErrAccountAlreadyExist}, rejected[3])
+		assert.Equal(t, &models.AccountImportError{Row: 2, Email: "alice@example.com", Err: usecases.ErrAccountAlreadyExist}, rejected[4])
+	})
+
+	t.Run("Service chooses the missing fields", func(t *testing.T) {
+		mockedUsecase.EXPECT().Import(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, accounts []*entities.Account) ([]string, error) {
+			require.Len(t, accounts, 1)
+			assert.NoError(t, validators.AccountUUID(accounts[0].UUID))
+			assert.Equal(t, entities.Active, accounts[0].Status)
+			assert.NotZero(t, accounts[0].CreatedAt)
+			assert.True(t, accounts[0].IsPasswordResetRequired())
+			return nil, nil
+		})
+
+		rejected, err := controller.Import(ctx, []*models.AccountImportRow{
+			{Row: 1, Record: &models.AccountRecord{Email: "bob@example.com", Name: "Bobby"}},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, rejected)
+	})
+
+	t.Run("Temporary active status is rejected", func(t *testing.T) {
+		mockedUsecase.EXPECT().Import(ctx, []*entities.Account{}).Return(nil, nil)
+
+		rejected, err := controller.Import(ctx, []*models.AccountImportRow{
+			{Row: 1, Record: &models.AccountRecord{Email: "bob@example.com", Name: "Bobby", StatusUntil: time.Now().Add(time.Hour).Unix()}},
+		})
+		require.NoError(t, err)
+		require.Len(t, rejected, 1)
+		assert.ErrorIs(t, rejected[0].Err, validators.ErrStatusUntilIsNotValid)
+	})
+}
+
+func TestAccountImport_Export(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+
+	mockedUsecase := controllers_test.NewMockIAccountImportUsecase(ctrl)
+	controller, err := NewAccountImport(&AccountImportDependencies{Usecase: mockedUsecase})
+	require.NoError(t, err)
+
+	mockedUsecase.EXPECT().Export(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, send func(account *entities.Account) error) error {
+		return send(&entities.Account{UUID: "accountuuid", Email: "bob@example.com", Name: "Bobby", Password: testBcryptHash, Status: entities.Blocked, CreatedAt: 100, UpdatedAt: 200})
+	})
+
+	var records []*models.AccountRecord
+	err = controller.Export(ctx, func(record *models.AccountRecord) error {
+		records = append(records, record)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*models.AccountRecord{
+		{UUID: "accountuuid", Email: "bob@example.com", Name: "Bobby", PasswordHash: testBcryptHash, Status: "blocked", CreatedAt: 100},
+	}, records)
+}
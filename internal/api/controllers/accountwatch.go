@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"strconv"
+)
+
+const (
+	accountWatchControllerKey = "AccountWatch"
+)
+
+//go:generate mockgen -destination ./mocks/mocks_accountwatch.go -package controllers_test github.com/alexsibrin/runbot-auth/internal/api/controllers IAccountWatchUsecase
+type IAccountWatchUsecase interface {
+	Watch(ctx context.Context, cursor int64, send func(event *entities.DomainEvent) error) error
+}
+
+type AccountWatchDependencies struct {
+	Usecase IAccountWatchUsecase
+}
+
+type AccountWatch struct {
+	usecase IAccountWatchUsecase
+}
+
+func NewAccountWatch(d *AccountWatchDependencies) (*AccountWatch, error) {
+	if d == nil {
+		return nil, NewErrUnitIsNil(accountWatchControllerKey, "whole struct")
+	}
+	if d.Usecase == nil {
+		return nil, NewErrUnitIsNil(accountWatchControllerKey, "Usecase")
+	}
+	return &AccountWatch{
+		usecase: d.Usecase,
+	}, nil
+}
+
+// Watch calls send with the account changes after the cursor until ctx is done or send fails,
+// the empty cursor starts with the next change
+func (c *AccountWatch) Watch(ctx context.Context, cursor string, send func(change *models.AccountChange) error) error {
+	afterseq, err := validators.Cursor(cursor)
+	if err != nil {
+		return err
+	}
+
+	return c.usecase.Watch(ctx, afterseq, func(event *entities.DomainEvent) error {
+		return send(c.event2Model(event))
+	})
+}
+
+func (c *AccountWatch) event2Model(event *entities.DomainEvent) *models.AccountChange {
+	data := event.Payload
+	if data == "" {
+		data = "{}"
+	}
+	return &models.AccountChange{
+		Cursor:      strconv.FormatInt(event.Seq, 10),
+		ID:          event.UUID,
+		Type:        event.Type,
+		AccountUUID: event.AccountUUID,
+		CreatedAt:   event.CreatedAt,
+		Data:        data,
+	}
+}
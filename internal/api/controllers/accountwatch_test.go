@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	controllers_test "github.com/alexsibrin/runbot-auth/internal/api/controllers/mocks"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestAccountWatch_Watch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+	mockedUsecase := controllers_test.NewMockIAccountWatchUsecase(ctrl)
+	controller := &AccountWatch{usecase: mockedUsecase}
+	errClosed := errors.New("stream is closed")
+
+	t.Run("Changes are mapped", func(t *testing.T) {
+		mockedUsecase.EXPECT().Watch(ctx, int64(41), gomock.Any()).DoAndReturn(func(_ context.Context, _ int64, send func(event *entities.DomainEvent) error) error {
+			if err := send(&entities.DomainEvent{
+				ID:          7,
+				UUID:        "eventuuid",
+				Type:        entities.DomainEventAccountStatusChanged,
+				AccountUUID: "accountuuid",
+				Payload:     `{"ToStatus":2}`,
+				CreatedAt:   100,
+				Seq:         42,
+			}); err != nil {
+				return err
+			}
+			return send(&entities.DomainEvent{ID: 8, UUID: "another", Type: entities.DomainEventAccountPurged, Seq: 43})
+		})
+
+		var changes []*models.AccountChange
+		err := controller.Watch(ctx, "41", func(change *models.AccountChange) error {
+			changes = append(changes, change)
+			return errClosed
+		})
+		assert.ErrorIs(t, err, errClosed)
+		assert.Equal(t, []*models.AccountChange{{
+			Cursor:      "42",
+			ID:          "eventuuid",
+			Type:        entities.DomainEventAccountStatusChanged,
+			AccountUUID: "accountuuid",
+			CreatedAt:   100,
+			Data:        `{"ToStatus":2}`,
+		}}, changes)
+	})
+
+	t.Run("Cursor is expired", func(t *testing.T) {
+		mockedUsecase.EXPECT().Watch(ctx, int64(0), gomock.Any()).Return(usecases.ErrCursorIsExpired)
+
+		err := controller.Watch(ctx, "", func(*models.AccountChange) error { return nil })
+		assert.ErrorIs(t, err, usecases.ErrCursorIsExpired)
+	})
+
+	t.Run("Cursor is not valid", func(t *testing.T) {
+		err := controller.Watch(ctx, "-1", func(*models.AccountChange) error { return nil })
+		assert.ErrorIs(t, err, validators.ErrCursorIsNotValid)
+	})
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"strconv"
+)
+
+const (
+	auditLogControllerKey = "AuditLog"
+)
+
+//go:generate mockgen -destination ./mocks/mocks_auditlog.go -package controllers_test github.com/alexsibrin/runbot-auth/internal/api/controllers IAuditLogUsecase
+type IAuditLogUsecase interface {
+	List(ctx context.Context, afterid int64, limit int) ([]*entities.AuditRecord, error)
+}
+
+type AuditLogDependencies struct {
+	Usecase IAuditLogUsecase
+}
+
+type AuditLog struct {
+	usecase IAuditLogUsecase
+}
+
+func NewAuditLog(d *AuditLogDependencies) (*AuditLog, error) {
+	if d == nil {
+		return nil, NewErrUnitIsNil(auditLogControllerKey, "whole struct")
+	}
+	if d.Usecase == nil {
+		return nil, NewErrUnitIsNil(auditLogControllerKey, "Usecase")
+	}
+	return &AuditLog{
+		usecase: d.Usecase,
+	}, nil
+}
+
+// Export returns a page of the audit log with the hashes, so the chain can be verified outside the service.
+// The empty cursor is the first page
+func (c *AuditLog) Export(ctx context.Context, cursor string, limit int) (*models.AuditLogResponse, error) {
+	afterid, err := validators.Cursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := c.usecase.List(ctx, afterid, limit)
+	if err != nil {
+		return nil, err
+	}
+	return c.auditRecords2Response(records, limit), nil
+}
+
+// auditRecords2Response sets the next cursor if the page is full, so the last page may be empty
+func (c *AuditLog) auditRecords2Response(records []*entities.AuditRecord, limit int) *models.AuditLogResponse {
+	result := &models.AuditLogResponse{
+		Records: make([]*models.AuditRecord, 0, len(records)),
+	}
+	for _, record := range records {
+		result.Records = append(result.Records, &models.AuditRecord{
+			ID:         record.ID,
+			Actor:      record.Actor,
+			Action:     record.Action,
+			TargetUUID: record.TargetUUID,
+			Before:     record.Before,
+			After:      record.After,
+			Method:     record.Method,
+			IP:         record.IP,
+			UserAgent:  record.UserAgent,
+			CreatedAt:  record.CreatedAt,
+			PrevHash:   record.PrevHash,
+			Hash:       record.Hash,
+		})
+	}
+	if len(records) > 0 && len(records) >= usecases.AuditLogLimit(limit) {
+		result.NextCursor = strconv.FormatInt(records[len(records)-1].ID, 10)
+	}
+	return result
+}
+
+// auditMetadata2Request keeps the nil metadata, the change isn't audited without it
+func auditMetadata2Request(model *models.AuditMetadata) *usecases.AuditMetadata {
+	if model == nil {
+		return nil
+	}
+	return &usecases.AuditMetadata{
+		Method:    model.Method,
+		IP:        model.IP,
+		UserAgent: model.UserAgent,
+	}
+}
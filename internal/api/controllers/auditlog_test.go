@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"context"
+	controllers_test "github.com/alexsibrin/runbot-auth/internal/api/controllers/mocks"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestAuditLog_Export(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+
+	mockedUsecase := controllers_test.NewMockIAuditLogUsecase(ctrl)
+
+	records := []*entities.AuditRecord{
+		{ID: 4, Actor: "admin", Action: entities.AuditActionStatusChanged, TargetUUID: "accountuuid", CreatedAt: 100, PrevHash: "hash3", Hash: "hash4"},
+		{ID: 5, Actor: "admin", Action: entities.AuditActionDeleted, TargetUUID: "accountuuid", CreatedAt: 200, PrevHash: "hash4", Hash: "hash5"},
+	}
+	expected := []*models.AuditRecord{
+		{ID: 4, Actor: "admin", Action: entities.AuditActionStatusChanged, TargetUUID: "accountuuid", CreatedAt: 100, PrevHash: "hash3", Hash: "hash4"},
+		{ID: 5, Actor: "admin", Action: entities.AuditActionDeleted, TargetUUID: "accountuuid", CreatedAt: 200, PrevHash: "hash4", Hash: "hash5"},
+	}
+
+	testCases := []struct {
+		name          string
+		cursor        string
+		limit         int
+		setupMocks    func()
+		out           *models.AuditLogResponse
+		expectedError error
+	}{
+		{
+			name:   "Full page has the next cursor",
+			cursor: "3",
+			limit:  2,
+			setupMocks: func() {
+				mockedUsecase.EXPECT().List(ctx, int64(3), 2).Return(records, nil)
+			},
+			out: &models.AuditLogResponse{Records: expected, NextCursor: "5"},
+		},
+		{
+			name:   "Last page",
+			cursor: "",
+			limit:  10,
+			setupMocks: func() {
+				mockedUsecase.EXPECT().List(ctx, int64(0), 10).Return(records, nil)
+			},
+			out: &models.AuditLogResponse{Records: expected},
+		},
+		{
+			name:          "Invalid cursor",
+			cursor:        "abc",
+			setupMocks:    func() {},
+			expectedError: validators.ErrCursorIsNotValid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			c, err := NewAuditLog(&AuditLogDependencies{Usecase: mockedUsecase})
+			require.NoError(t, err)
+
+			result, err := c.Export(ctx, tc.cursor, tc.limit)
+
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.out, result)
+		})
+	}
+}
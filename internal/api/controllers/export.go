@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"net/url"
+	"time"
+)
+
+const (
+	exportControllerKey = "Export"
+
+	// ExportExpiresParam and ExportSignatureParam are the query parameters of a signed download URL
+	ExportExpiresParam   = "expires"
+	ExportSignatureParam = "signature"
+)
+
+//go:generate mockgen -destination ./mocks/mocks_export.go -package controllers_test github.com/alexsibrin/runbot-auth/internal/api/controllers IExportUsecase,IURLSigner
+type IExportUsecase interface {
+	RequestExport(ctx context.Context, accountuuid string) (*entities.AccountExport, error)
+	GetExport(ctx context.Context, accountuuid, exportuuid string) (*entities.AccountExport, error)
+	GetArchive(ctx context.Context, exportuuid string) ([]byte, error)
+}
+
+type IURLSigner interface {
+	Sign(resource string) (string, string)
+	Verify(resource, expires, signature string) error
+}
+
+type ExportDependencies struct {
+	Usecase   IExportUsecase
+	URLSigner IURLSigner
+	// DownloadURL is the format of the download URL, %s is replaced with the export UUID
+	DownloadURL string
+}
+
+type Export struct {
+	usecase     IExportUsecase
+	urlsigner   IURLSigner
+	downloadurl string
+}
+
+func NewExport(d *ExportDependencies) (*Export, error) {
+	if d == nil {
+		return nil, NewErrUnitIsNil(exportControllerKey, "whole struct")
+	}
+	if d.Usecase == nil {
+		return nil, NewErrUnitIsNil(exportControllerKey, "Usecase")
+	}
+	if d.URLSigner == nil {
+		return nil, NewErrUnitIsNil(exportControllerKey, "URLSigner")
+	}
+	if d.DownloadURL == "" {
+		return nil, NewErrEmptyValue("export download URL")
+	}
+	return &Export{
+		usecase:     d.Usecase,
+		urlsigner:   d.URLSigner,
+		downloadurl: d.DownloadURL,
+	}, nil
+}
+
+func (c *Export) RequestExport(ctx context.Context, accountuuid string) (*models.Export, error) {
+	export, err := c.usecase.RequestExport(ctx, accountuuid)
+	if err != nil {
+		return nil, err
+	}
+	return c.exportEntity2Model(export), nil
+}
+
+func (c *Export) GetExport(ctx context.Context, accountuuid, exportuuid string) (*models.Export, error) {
+	if err := validators.AccountUUID(exportuuid); err != nil {
+		return nil, err
+	}
+
+	export, err := c.usecase.GetExport(ctx, accountuuid, exportuuid)
+	if err != nil {
+		return nil, err
+	}
+	return c.exportEntity2Model(export), nil
+}
+
+// Download returns the archive if the URL signature is valid, it's the only authorization of the download
+func (c *Export) Download(ctx context.Context, model *models.ExportDownload) ([]byte, error) {
+	if err := validators.AccountUUID(model.UUID); err != nil {
+		return nil, err
+	}
+	if err := c.urlsigner.Verify(model.UUID, model.Expires, model.Signature); err != nil {
+		return nil, err
+	}
+	return c.usecase.GetArchive(ctx, model.UUID)
+}
+
+func (c *Export) exportEntity2Model(export *entities.AccountExport) *models.Export {
+	model := &models.Export{
+		UUID:      export.UUID,
+		Status:    exportStatus(export.Status),
+		CreatedAt: export.CreatedAt,
+		ReadyAt:   export.ReadyAt,
+		ExpiresAt: export.ExpiresAt,
+	}
+	if export.IsReady() && !export.IsExpired(time.Now().Unix()) {
+		model.DownloadURL = c.signedDownloadURL(export.UUID)
+	}
+	return model
+}
+
+func (c *Export) signedDownloadURL(exportuuid string) string {
+	expires, signature := c.urlsigner.Sign(exportuuid)
+	query := url.Values{
+		ExportExpiresParam:   []string{expires},
+		ExportSignatureParam: []string{signature},
+	}
+	return fmt.Sprintf(c.downloadurl, exportuuid) + "?" + query.Encode()
+}
+
+func exportStatus(status uint8) string {
+	switch status {
+	case entities.ExportPending:
+		return "pending"
+	case entities.ExportProcessing:
+		return "processing"
+	case entities.ExportReady:
+		return "ready"
+	case entities.ExportFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
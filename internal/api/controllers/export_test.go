@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"context"
+	controllers_test "github.com/alexsibrin/runbot-auth/internal/api/controllers/mocks"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/urlsigner"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+const testDownloadURL = "https://auth.runbot.io/v1/exports/%s/download"
+
+func TestExport_GetExport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+
+	mockedUsecase := controllers_test.NewMockIExportUsecase(ctrl)
+	mockedSigner := controllers_test.NewMockIURLSigner(ctrl)
+
+	exportuuid := uuid.NewString()
+	future := time.Now().Add(time.Hour).Unix()
+
+	testCases := []struct {
+		name          string
+		in            string
+		setupMocks    func()
+		out           *models.Export
+		expectedError error
+	}{
+		{
+			name: "Ready export has a signed URL",
+			in:   exportuuid,
+			setupMocks: func() {
+				mockedUsecase.EXPECT().GetExport(ctx, "accountuuid", exportuuid).Return(&entities.AccountExport{
+					UUID:      exportuuid,
+					Status:    entities.ExportReady,
+					CreatedAt: 100,
+					ReadyAt:   200,
+					ExpiresAt: future,
+				}, nil)
+				mockedSigner.EXPECT().Sign(exportuuid).Return("300", "somesignature")
+			},
+			out: &models.Export{
+				UUID:        exportuuid,
+				Status:      "ready",
+				CreatedAt:   100,
+				ReadyAt:     200,
+				ExpiresAt:   future,
+				DownloadURL: "https://auth.runbot.io/v1/exports/" + exportuuid + "/download?expires=300&signature=somesignature",
+			},
+		},
+		{
+			name: "Pending export has no URL",
+			in:   exportuuid,
+			setupMocks: func() {
+				mockedUsecase.EXPECT().GetExport(ctx, "accountuuid", exportuuid).Return(&entities.AccountExport{
+					UUID:      exportuuid,
+					Status:    entities.ExportPending,
+					CreatedAt: 100,
+				}, nil)
+			},
+			out: &models.Export{
+				UUID:      exportuuid,
+				Status:    "pending",
+				CreatedAt: 100,
+			},
+		},
+		{
+			name:          "Invalid UUID",
+			in:            "invaliduuid",
+			setupMocks:    func() {},
+			expectedError: validators.ErrUUIDIsNotValid,
+		},
+		{
+			name: "Export is not found",
+			in:   exportuuid,
+			setupMocks: func() {
+				mockedUsecase.EXPECT().GetExport(ctx, "accountuuid", exportuuid).Return(nil, usecases.ErrExportIsNotFound)
+			},
+			expectedError: usecases.ErrExportIsNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			c, err := NewExport(&ExportDependencies{
+				Usecase:     mockedUsecase,
+				URLSigner:   mockedSigner,
+				DownloadURL: testDownloadURL,
+			})
+			require.NoError(t, err)
+
+			result, err := c.GetExport(ctx, "accountuuid", tc.in)
+
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.out, result)
+		})
+	}
+}
+
+func TestExport_Download(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+
+	mockedUsecase := controllers_test.NewMockIExportUsecase(ctrl)
+	mockedSigner := controllers_test.NewMockIURLSigner(ctrl)
+
+	exportuuid := uuid.NewString()
+
+	testCases := []struct {
+		name          string
+		in            *models.ExportDownload
+		setupMocks    func()
+		out           []byte
+		expectedError error
+	}{
+		{
+			name: "Valid signature",
+			in:   &models.ExportDownload{UUID: exportuuid, Expires: "300", Signature: "somesignature"},
+			setupMocks: func() {
+				mockedSigner.EXPECT().Verify(exportuuid, "300", "somesignature").Return(nil)
+				mockedUsecase.EXPECT().GetArchive(ctx, exportuuid).Return([]byte("archive"), nil)
+			},
+			out: []byte("archive"),
+		},
+		{
+			name: "Expired signature",
+			in:   &models.ExportDownload{UUID: exportuuid, Expires: "300", Signature: "somesignature"},
+			setupMocks: func() {
+				mockedSigner.EXPECT().Verify(exportuuid, "300", "somesignature").Return(urlsigner.ErrSignatureIsExpired)
+			},
+			expectedError: urlsigner.ErrSignatureIsExpired,
+		},
+		{
+			name:          "Invalid UUID",
+			in:            &models.ExportDownload{UUID: "invaliduuid"},
+			setupMocks:    func() {},
+			expectedError: validators.ErrUUIDIsNotValid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			c := &Export{usecase: mockedUsecase, urlsigner: mockedSigner, downloadurl: testDownloadURL}
+
+			archive, err := c.Download(ctx, tc.in)
+
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.out, archive)
+		})
+	}
+}
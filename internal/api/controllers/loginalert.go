@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+)
+
+const (
+	loginAlertControllerKey = "LoginAlert"
+)
+
+//go:generate mockgen -destination ./mocks/mocks_loginalert.go -package controllers_test github.com/alexsibrin/runbot-auth/internal/api/controllers ILoginAlertUsecase
+type ILoginAlertUsecase interface {
+	CheckNotMe(ctx context.Context, accountuuid, expires, signature string) error
+	NotMe(ctx context.Context, accountuuid, expires, signature string) (*usecases.PasswordResetToken, error)
+	RequestPasswordReset(ctx context.Context, email string) error
+	CheckPasswordReset(ctx context.Context, accountuuid, expires, signature string) error
+	ResetPassword(ctx context.Context, r *usecases.PasswordResetRequest) error
+}
+
+type LoginAlertDependencies struct {
+	Usecase ILoginAlertUsecase
+}
+
+type LoginAlert struct {
+	usecase ILoginAlertUsecase
+}
+
+func NewLoginAlert(d *LoginAlertDependencies) (*LoginAlert, error) {
+	if d == nil {
+		return nil, NewErrUnitIsNil(loginAlertControllerKey, "whole struct")
+	}
+	if d.Usecase == nil {
+		return nil, NewErrUnitIsNil(loginAlertControllerKey, "Usecase")
+	}
+	return &LoginAlert{
+		usecase: d.Usecase,
+	}, nil
+}
+
+// CheckNotMe verifies the link without signing anything out, so it's safe for the prefetched links
+func (c *LoginAlert) CheckNotMe(ctx context.Context, model *models.NotMe) error {
+	if err := validators.AccountUUID(model.UUID); err != nil {
+		return err
+	}
+	return c.usecase.CheckNotMe(ctx, model.UUID, model.Expires, model.Signature)
+}
+
+// NotMe signs out all the devices of the account and returns the token to set a new password,
+// the signature of the link is the only authorization
+func (c *LoginAlert) NotMe(ctx context.Context, model *models.NotMe) (*models.PasswordResetToken, error) {
+	if err := validators.AccountUUID(model.UUID); err != nil {
+		return nil, err
+	}
+
+	token, err := c.usecase.NotMe(ctx, model.UUID, model.Expires, model.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PasswordResetToken{
+		UUID:      token.AccountUUID,
+		Expires:   token.Expires,
+		Signature: token.Signature,
+	}, nil
+}
+
+// RequestPasswordReset emails the password reset link, it doesn't tell whether the account exists
+func (c *LoginAlert) RequestPasswordReset(ctx context.Context, model *models.PasswordResetRequest) error {
+	email, err := validators.NormalizeEmail(model.Email)
+	if err != nil {
+		return err
+	}
+	return c.usecase.RequestPasswordReset(ctx, email)
+}
+
+// CheckPasswordReset verifies the emailed password reset link without setting anything
+func (c *LoginAlert) CheckPasswordReset(ctx context.Context, model *models.PasswordResetToken) error {
+	if err := validators.AccountUUID(model.UUID); err != nil {
+		return err
+	}
+	return c.usecase.CheckPasswordReset(ctx, model.UUID, model.Expires, model.Signature)
+}
+
+func (c *LoginAlert) ResetPassword(ctx context.Context, model *models.PasswordReset) error {
+	if err := validators.AccountUUID(model.UUID); err != nil {
+		return err
+	}
+	if err := validators.Password(model.Password); err != nil {
+		return err
+	}
+
+	r := &usecases.PasswordResetRequest{
+		AccountUUID: model.UUID,
+		Expires:     model.Expires,
+		Signature:   model.Signature,
+		Password:    model.Password,
+	}
+	if model.Client != nil {
+		r.IP = model.Client.IP
+		r.UserAgent = model.Client.UserAgent
+	}
+
+	return c.usecase.ResetPassword(ctx, r)
+}
@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"context"
+	controllers_test "github.com/alexsibrin/runbot-auth/internal/api/controllers/mocks"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/urlsigner"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestLoginAlert_CheckNotMe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+
+	mockedUsecase := controllers_test.NewMockILoginAlertUsecase(ctrl)
+	c, err := NewLoginAlert(&LoginAlertDependencies{Usecase: mockedUsecase})
+	require.NoError(t, err)
+
+	accountuuid := uuid.NewString()
+
+	mockedUsecase.EXPECT().CheckNotMe(ctx, accountuuid, "300", "somesignature").Return(nil)
+	assert.NoError(t, c.CheckNotMe(ctx, &models.NotMe{UUID: accountuuid, Expires: "300", Signature: "somesignature"}))
+
+	mockedUsecase.EXPECT().CheckNotMe(ctx, accountuuid, "300", "somesignature").Return(urlsigner.ErrSignatureIsNotValid)
+	assert.ErrorIs(t, c.CheckNotMe(ctx, &models.NotMe{UUID: accountuuid, Expires: "300", Signature: "somesignature"}), urlsigner.ErrSignatureIsNotValid)
+
+	assert.ErrorIs(t, c.CheckNotMe(ctx, &models.NotMe{UUID: "invaliduuid"}), validators.ErrUUIDIsNotValid)
+}
+
+func TestLoginAlert_NotMe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+
+	mockedUsecase := controllers_test.NewMockILoginAlertUsecase(ctrl)
+
+	accountuuid := uuid.NewString()
+
+	testCases := []struct {
+		name          string
+		in            *models.NotMe
+		setupMocks    func()
+		out           *models.PasswordResetToken
+		expectedError error
+	}{
+		{
+			name: "Valid link",
+			in:   &models.NotMe{UUID: accountuuid, Expires: "300", Signature: "somesignature"},
+			setupMocks: func() {
+				mockedUsecase.EXPECT().NotMe(ctx, accountuuid, "300", "somesignature").Return(&usecases.PasswordResetToken{
+					AccountUUID: accountuuid,
+					Expires:     "400",
+					Signature:   "resetsignature",
+				}, nil)
+			},
+			out: &models.PasswordResetToken{UUID: accountuuid, Expires: "400", Signature: "resetsignature"},
+		},
+		{
+			name: "Expired link",
+			in:   &models.NotMe{UUID: accountuuid, Expires: "300", Signature: "somesignature"},
+			setupMocks: func() {
+				mockedUsecase.EXPECT().NotMe(ctx, accountuuid, "300", "somesignature").Return(nil, urlsigner.ErrSignatureIsExpired)
+			},
+			expectedError: urlsigner.ErrSignatureIsExpired,
+		},
+		{
+			name:          "Invalid UUID",
+			in:            &models.NotMe{UUID: "invaliduuid"},
+			setupMocks:    func() {},
+			expectedError: validators.ErrUUIDIsNotValid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			c, err := NewLoginAlert(&LoginAlertDependencies{Usecase: mockedUsecase})
+			require.NoError(t, err)
+
+			result, err := c.NotMe(ctx, tc.in)
+
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.out, result)
+		})
+	}
+}
+
+func TestLoginAlert_RequestPasswordReset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+
+	mockedUsecase := controllers_test.NewMockILoginAlertUsecase(ctrl)
+	c, err := NewLoginAlert(&LoginAlertDependencies{Usecase: mockedUsecase})
+	require.NoError(t, err)
+
+	mockedUsecase.EXPECT().RequestPasswordReset(ctx, "bob@example.com").Return(nil)
+	assert.NoError(t, c.RequestPasswordReset(ctx, &models.PasswordResetRequest{Email: " Bob@Example.com "}))
+
+	assert.ErrorIs(t, c.RequestPasswordReset(ctx, &models.PasswordResetRequest{Email: "bob"}), validators.ErrEmailIsTooShort)
+}
+
+func TestLoginAlert_CheckPasswordReset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+
+	mockedUsecase := controllers_test.NewMockILoginAlertUsecase(ctrl)
+	c, err := NewLoginAlert(&LoginAlertDependencies{Usecase: mockedUsecase})
+	require.NoError(t, err)
+
+	accountuuid := uuid.NewString()
+
+	mockedUsecase.EXPECT().CheckPasswordReset(ctx, accountuuid, "400", "resetsignature").Return(nil)
+	assert.NoError(t, c.CheckPasswordReset(ctx, &models.PasswordResetToken{UUID: accountuuid, Expires: "400", Signature: "resetsignature"}))
+
+	mockedUsecase.EXPECT().CheckPasswordReset(ctx, accountuuid, "400", "resetsignature").Return(urlsigner.ErrSignatureIsNotValid)
+	assert.ErrorIs(t, c.CheckPasswordReset(ctx, &models.PasswordResetToken{UUID: accountuuid, Expires: "400", Signature: "resetsignature"}), urlsigner.ErrSignatureIsNotValid)
+
+	assert.ErrorIs(t, c.CheckPasswordReset(ctx, &models.PasswordResetToken{UUID: "invaliduuid"}), validators.ErrUUIDIsNotValid)
+}
+
+func TestLoginAlert_ResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+
+	mockedUsecase := controllers_test.NewMockILoginAlertUsecase(ctrl)
+
+	accountuuid := uuid.NewString()
+
+	testCases := []struct {
+		name          string
+		in            *models.PasswordReset
+		setupMocks    func()
+		expectedError error
+	}{
+		{
+			name: "Valid token",
+			in: &models.PasswordReset{
+				UUID:      accountuuid,
+				Expires:   "400",
+				Signature: "resetsignature",
+				Password:  "NewPassword123",
+				Client:    &models.Client{IP: "10.0.0.1", UserAgent: "curl/8.5.0"},
+			},
+			setupMocks: func() {
+				mockedUsecase.EXPECT().ResetPassword(ctx, &usecases.PasswordResetRequest{
+					AccountUUID: accountuuid,
+					Expires:     "400",
+					Signature:   "resetsignature",
+					Password:    "NewPassword123",
+					IP:          "10.0.0.1",
+					UserAgent:   "curl/8.5.0",
+				}).Return(nil)
+			},
+		},
+		{
+			name: "Invalid signature",
+			in: &models.PasswordReset{
+				UUID:      accountuuid,
+				Expires:   "400",
+				Signature: "wrongsignature",
+				Password:  "NewPassword123",
+			},
+			setupMocks: func() {
+				mockedUsecase.EXPECT().ResetPassword(ctx, &usecases.PasswordResetRequest{
+					AccountUUID: accountuuid,
+					Expires:     "400",
+					Signature:   "wrongsignature",
+					Password:    "NewPassword123",
+				}).Return(urlsigner.ErrSignatureIsNotValid)
+			},
+			expectedError: urlsigner.ErrSignatureIsNotValid,
+		},
+		{
+			name:          "Invalid password",
+			in:            &models.PasswordReset{UUID: accountuuid, Password: "short"},
+			setupMocks:    func() {},
+			expectedError: validators.ErrPasswordIsTooShort,
+		},
+		{
+			name:          "Invalid UUID",
+			in:            &models.PasswordReset{UUID: "invaliduuid", Password: "NewPassword123"},
+			setupMocks:    func() {},
+			expectedError: validators.ErrUUIDIsNotValid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			c := &LoginAlert{usecase: mockedUsecase}
+
+			err := c.ResetPassword(ctx, tc.in)
+
+			assert.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/api/controllers (interfaces: IAccountImportUsecase)
+//
+// Generated by this command:
+//
+//	mockgen -destination ./mocks/mocks_accountimport.go -package controllers_test github.com/alexsibrin/runbot-auth/internal/api/controllers IAccountImportUsecase
+//
+
+// Package controllers_test is a generated GoMock package.
+package controllers_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/alexsibrin/runbot-auth/internal/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIAccountImportUsecase is a mock of IAccountImportUsecase interface.
+type MockIAccountImportUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockIAccountImportUsecaseMockRecorder
+}
+
+// MockIAccountImportUsecaseMockRecorder is the mock recorder for MockIAccountImportUsecase.
+type MockIAccountImportUsecaseMockRecorder struct {
+	mock *MockIAccountImportUsecase
+}
+
+// NewMockIAccountImportUsecase creates a new mock instance.
+func NewMockIAccountImportUsecase(ctrl *gomock.Controller) *MockIAccountImportUsecase {
+	mock := &MockIAccountImportUsecase{ctrl: ctrl}
+	mock.recorder = &MockIAccountImportUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIAccountImportUsecase) EXPECT() *MockIAccountImportUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Export mocks base method.
+func (m *MockIAccountImportUsecase) Export(arg0 context.Context, arg1 func(*entities.Account) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockIAccountImportUsecaseMockRecorder) Export(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockIAccountImportUsecase)(nil).Export), arg0, arg1)
+}
+
+// Import mocks base method.
+func (m *MockIAccountImportUsecase) Import(arg0 context.Context, arg1 []*entities.Account) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockIAccountImportUsecaseMockRecorder) Import(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockIAccountImportUsecase)(nil).Import), arg0, arg1)
+}
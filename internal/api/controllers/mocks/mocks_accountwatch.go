@@ -0,0 +1,55 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/api/controllers (interfaces: IAccountWatchUsecase)
+//
+// Generated by this command:
+//
+//	mockgen -destination ./mocks/mocks_accountwatch.go -package controllers_test github.com/alexsibrin/runbot-auth/internal/api/controllers IAccountWatchUsecase
+//
+
+// Package controllers_test is a generated GoMock package.
+package controllers_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/alexsibrin/runbot-auth/internal/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIAccountWatchUsecase is a mock of IAccountWatchUsecase interface.
+type MockIAccountWatchUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockIAccountWatchUsecaseMockRecorder
+}
+
+// MockIAccountWatchUsecaseMockRecorder is the mock recorder for MockIAccountWatchUsecase.
+type MockIAccountWatchUsecaseMockRecorder struct {
+	mock *MockIAccountWatchUsecase
+}
+
+// NewMockIAccountWatchUsecase creates a new mock instance.
+func NewMockIAccountWatchUsecase(ctrl *gomock.Controller) *MockIAccountWatchUsecase {
+	mock := &MockIAccountWatchUsecase{ctrl: ctrl}
+	mock.recorder = &MockIAccountWatchUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIAccountWatchUsecase) EXPECT() *MockIAccountWatchUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Watch mocks base method.
+func (m *MockIAccountWatchUsecase) Watch(arg0 context.Context, arg1 int64, arg2 func(*entities.DomainEvent) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Watch", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Watch indicates an expected call of Watch.
+func (mr *MockIAccountWatchUsecaseMockRecorder) Watch(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockIAccountWatchUsecase)(nil).Watch), arg0, arg1, arg2)
+}
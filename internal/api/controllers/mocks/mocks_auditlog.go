@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/api/controllers (interfaces: IAuditLogUsecase)
+//
+// Generated by this command:
+//
+//	mockgen -destination ./mocks/mocks_auditlog.go -package controllers_test github.com/alexsibrin/runbot-auth/internal/api/controllers IAuditLogUsecase
+//
+
+// Package controllers_test is a generated GoMock package.
+package controllers_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/alexsibrin/runbot-auth/internal/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIAuditLogUsecase is a mock of IAuditLogUsecase interface.
+type MockIAuditLogUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockIAuditLogUsecaseMockRecorder
+}
+
+// MockIAuditLogUsecaseMockRecorder is the mock recorder for MockIAuditLogUsecase.
+type MockIAuditLogUsecaseMockRecorder struct {
+	mock *MockIAuditLogUsecase
+}
+
+// NewMockIAuditLogUsecase creates a new mock instance.
+func NewMockIAuditLogUsecase(ctrl *gomock.Controller) *MockIAuditLogUsecase {
+	mock := &MockIAuditLogUsecase{ctrl: ctrl}
+	mock.recorder = &MockIAuditLogUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIAuditLogUsecase) EXPECT() *MockIAuditLogUsecaseMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockIAuditLogUsecase) List(arg0 context.Context, arg1 int64, arg2 int) ([]*entities.AuditRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*entities.AuditRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockIAuditLogUsecaseMockRecorder) List(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockIAuditLogUsecase)(nil).List), arg0, arg1, arg2)
+}
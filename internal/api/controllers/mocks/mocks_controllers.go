@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/alexsibrin/runbot-auth/internal/api/controllers (interfaces: IAccountUsecase,ISecurer)
+// Source: github.com/alexsibrin/runbot-auth/internal/api/controllers (interfaces: IAccountUsecase,ISecurer,ISessionTracker)
 //
 // Generated by this command:
 //
-//	mockgen -destination ./mocks/mocks_controllers.go -package controllers_test github.com/alexsibrin/runbot-auth/internal/api/controllers IAccountUsecase,ISecurer
+//	mockgen -destination ./mocks/mocks_controllers.go -package controllers_test github.com/alexsibrin/runbot-auth/internal/api/controllers IAccountUsecase,ISecurer,ISessionTracker
 //
 
 // Package controllers_test is a generated GoMock package.
@@ -12,8 +12,11 @@ package controllers_test
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	entities "github.com/alexsibrin/runbot-auth/internal/entities"
+	repositories "github.com/alexsibrin/runbot-auth/internal/repositories"
+	usecases "github.com/alexsibrin/runbot-auth/internal/usecases"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,17 +44,49 @@ func (m *MockIAccountUsecase) EXPECT() *MockIAccountUsecaseMockRecorder {
 }
 
 // ChangeAccountStatus mocks base method.
-func (m *MockIAccountUsecase) ChangeAccountStatus(arg0 context.Context, arg1 string, arg2 byte) error {
+func (m *MockIAccountUsecase) ChangeAccountStatus(arg0 context.Context, arg1 *usecases.AccountStatusChangeRequest) (*entities.StatusChange, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ChangeAccountStatus", arg0, arg1, arg2)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ChangeAccountStatus", arg0, arg1)
+	ret0, _ := ret[0].(*entities.StatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // ChangeAccountStatus indicates an expected call of ChangeAccountStatus.
-func (mr *MockIAccountUsecaseMockRecorder) ChangeAccountStatus(arg0, arg1, arg2 any) *gomock.Call {
+func (mr *MockIAccountUsecaseMockRecorder) ChangeAccountStatus(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeAccountStatus", reflect.TypeOf((*MockIAccountUsecase)(nil).ChangeAccountStatus), arg0, arg1)
+}
+
+// DeleteAccount mocks base method.
+func (m *MockIAccountUsecase) DeleteAccount(arg0 context.Context, arg1 *usecases.AccountDeleteRequest) (*entities.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockIAccountUsecaseMockRecorder) DeleteAccount(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeAccountStatus", reflect.TypeOf((*MockIAccountUsecase)(nil).ChangeAccountStatus), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockIAccountUsecase)(nil).DeleteAccount), arg0, arg1)
+}
+
+// GetManyByUUIDs mocks base method.
+func (m *MockIAccountUsecase) GetManyByUUIDs(arg0 context.Context, arg1 []string) ([]*entities.Account, []string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetManyByUUIDs", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.Account)
+	ret1, _ := ret[1].([]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetManyByUUIDs indicates an expected call of GetManyByUUIDs.
+func (mr *MockIAccountUsecaseMockRecorder) GetManyByUUIDs(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetManyByUUIDs", reflect.TypeOf((*MockIAccountUsecase)(nil).GetManyByUUIDs), arg0, arg1)
 }
 
 // GetOneByEmail mocks base method.
@@ -84,19 +119,63 @@ func (mr *MockIAccountUsecaseMockRecorder) GetOneByUUID(arg0, arg1 any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOneByUUID", reflect.TypeOf((*MockIAccountUsecase)(nil).GetOneByUUID), arg0, arg1)
 }
 
+// GetStatusHistory mocks base method.
+func (m *MockIAccountUsecase) GetStatusHistory(arg0 context.Context, arg1 string, arg2 int) ([]*entities.StatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatusHistory", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*entities.StatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatusHistory indicates an expected call of GetStatusHistory.
+func (mr *MockIAccountUsecaseMockRecorder) GetStatusHistory(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatusHistory", reflect.TypeOf((*MockIAccountUsecase)(nil).GetStatusHistory), arg0, arg1, arg2)
+}
+
+// PurgeTime mocks base method.
+func (m *MockIAccountUsecase) PurgeTime(arg0 *entities.Account) time.Time {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTime", arg0)
+	ret0, _ := ret[0].(time.Time)
+	return ret0
+}
+
+// PurgeTime indicates an expected call of PurgeTime.
+func (mr *MockIAccountUsecaseMockRecorder) PurgeTime(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTime", reflect.TypeOf((*MockIAccountUsecase)(nil).PurgeTime), arg0)
+}
+
+// Search mocks base method.
+func (m *MockIAccountUsecase) Search(arg0 context.Context, arg1 *repositories.AccountFilter) ([]*entities.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockIAccountUsecaseMockRecorder) Search(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockIAccountUsecase)(nil).Search), arg0, arg1)
+}
+
 // SignIn mocks base method.
-func (m *MockIAccountUsecase) SignIn(arg0 context.Context, arg1, arg2 string) (*entities.Account, error) {
+func (m *MockIAccountUsecase) SignIn(arg0 context.Context, arg1 *usecases.SignInRequest) (*entities.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignIn", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "SignIn", arg0, arg1)
 	ret0, _ := ret[0].(*entities.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SignIn indicates an expected call of SignIn.
-func (mr *MockIAccountUsecaseMockRecorder) SignIn(arg0, arg1, arg2 any) *gomock.Call {
+func (mr *MockIAccountUsecaseMockRecorder) SignIn(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIn", reflect.TypeOf((*MockIAccountUsecase)(nil).SignIn), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIn", reflect.TypeOf((*MockIAccountUsecase)(nil).SignIn), arg0, arg1)
 }
 
 // SignUp mocks base method.
@@ -138,46 +217,128 @@ func (m *MockISecurer) EXPECT() *MockISecurerMockRecorder {
 }
 
 // AccessToken mocks base method.
-func (m *MockISecurer) AccessToken(arg0 *entities.Account) (string, error) {
+func (m *MockISecurer) AccessToken(arg0 *entities.Account, arg1 string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AccessToken", arg0)
+	ret := m.ctrl.Call(m, "AccessToken", arg0, arg1)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AccessToken indicates an expected call of AccessToken.
-func (mr *MockISecurerMockRecorder) AccessToken(arg0 any) *gomock.Call {
+func (mr *MockISecurerMockRecorder) AccessToken(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccessToken", reflect.TypeOf((*MockISecurer)(nil).AccessToken), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccessToken", reflect.TypeOf((*MockISecurer)(nil).AccessToken), arg0, arg1)
 }
 
-// Decrypt mocks base method.
-func (m *MockISecurer) Decrypt(arg0 string) (*entities.Account, error) {
+// DecryptAccess mocks base method.
+func (m *MockISecurer) DecryptAccess(arg0 string) (*entities.TokenClaims, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Decrypt", arg0)
-	ret0, _ := ret[0].(*entities.Account)
+	ret := m.ctrl.Call(m, "DecryptAccess", arg0)
+	ret0, _ := ret[0].(*entities.TokenClaims)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Decrypt indicates an expected call of Decrypt.
-func (mr *MockISecurerMockRecorder) Decrypt(arg0 any) *gomock.Call {
+// DecryptAccess indicates an expected call of DecryptAccess.
+func (mr *MockISecurerMockRecorder) DecryptAccess(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Decrypt", reflect.TypeOf((*MockISecurer)(nil).Decrypt), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecryptAccess", reflect.TypeOf((*MockISecurer)(nil).DecryptAccess), arg0)
+}
+
+// DecryptRefresh mocks base method.
+func (m *MockISecurer) DecryptRefresh(arg0 string) (*entities.TokenClaims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecryptRefresh", arg0)
+	ret0, _ := ret[0].(*entities.TokenClaims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecryptRefresh indicates an expected call of DecryptRefresh.
+func (mr *MockISecurerMockRecorder) DecryptRefresh(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecryptRefresh", reflect.TypeOf((*MockISecurer)(nil).DecryptRefresh), arg0)
 }
 
 // RefreshToken mocks base method.
-func (m *MockISecurer) RefreshToken(arg0 *entities.Account) (string, error) {
+func (m *MockISecurer) RefreshToken(arg0 *entities.Account, arg1 string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RefreshToken", arg0)
+	ret := m.ctrl.Call(m, "RefreshToken", arg0, arg1)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // RefreshToken indicates an expected call of RefreshToken.
-func (mr *MockISecurerMockRecorder) RefreshToken(arg0 any) *gomock.Call {
+func (mr *MockISecurerMockRecorder) RefreshToken(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockISecurer)(nil).RefreshToken), arg0, arg1)
+}
+
+// MockISessionTracker is a mock of ISessionTracker interface.
+type MockISessionTracker struct {
+	ctrl     *gomock.Controller
+	recorder *MockISessionTrackerMockRecorder
+}
+
+// MockISessionTrackerMockRecorder is the mock recorder for MockISessionTracker.
+type MockISessionTrackerMockRecorder struct {
+	mock *MockISessionTracker
+}
+
+// NewMockISessionTracker creates a new mock instance.
+func NewMockISessionTracker(ctrl *gomock.Controller) *MockISessionTracker {
+	mock := &MockISessionTracker{ctrl: ctrl}
+	mock.recorder = &MockISessionTrackerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockISessionTracker) EXPECT() *MockISessionTrackerMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockISessionTracker) Check(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockISessionTrackerMockRecorder) Check(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockISessionTracker)(nil).Check), arg0, arg1, arg2)
+}
+
+// Refresh mocks base method.
+func (m *MockISessionTracker) Refresh(arg0 context.Context, arg1 *usecases.SessionRefreshRequest) (*entities.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockISessionTrackerMockRecorder) Refresh(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockISessionTracker)(nil).Refresh), arg0, arg1)
+}
+
+// Start mocks base method.
+func (m *MockISessionTracker) Start(arg0 context.Context, arg1 *usecases.SessionStartRequest) (*entities.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Start", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockISessionTrackerMockRecorder) Start(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockISecurer)(nil).RefreshToken), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockISessionTracker)(nil).Start), arg0, arg1)
 }
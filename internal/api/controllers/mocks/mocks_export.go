@@ -0,0 +1,138 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/api/controllers (interfaces: IExportUsecase,IURLSigner)
+//
+// Generated by this command:
+//
+//	mockgen -destination ./mocks/mocks_export.go -package controllers_test github.com/alexsibrin/runbot-auth/internal/api/controllers IExportUsecase,IURLSigner
+//
+
+// Package controllers_test is a generated GoMock package.
+package controllers_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/alexsibrin/runbot-auth/internal/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIExportUsecase is a mock of IExportUsecase interface.
+type MockIExportUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockIExportUsecaseMockRecorder
+}
+
+// MockIExportUsecaseMockRecorder is the mock recorder for MockIExportUsecase.
+type MockIExportUsecaseMockRecorder struct {
+	mock *MockIExportUsecase
+}
+
+// NewMockIExportUsecase creates a new mock instance.
+func NewMockIExportUsecase(ctrl *gomock.Controller) *MockIExportUsecase {
+	mock := &MockIExportUsecase{ctrl: ctrl}
+	mock.recorder = &MockIExportUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIExportUsecase) EXPECT() *MockIExportUsecaseMockRecorder {
+	return m.recorder
+}
+
+// GetArchive mocks base method.
+func (m *MockIExportUsecase) GetArchive(arg0 context.Context, arg1 string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArchive", arg0, arg1)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetArchive indicates an expected call of GetArchive.
+func (mr *MockIExportUsecaseMockRecorder) GetArchive(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArchive", reflect.TypeOf((*MockIExportUsecase)(nil).GetArchive), arg0, arg1)
+}
+
+// GetExport mocks base method.
+func (m *MockIExportUsecase) GetExport(arg0 context.Context, arg1, arg2 string) (*entities.AccountExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExport", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entities.AccountExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExport indicates an expected call of GetExport.
+func (mr *MockIExportUsecaseMockRecorder) GetExport(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExport", reflect.TypeOf((*MockIExportUsecase)(nil).GetExport), arg0, arg1, arg2)
+}
+
+// RequestExport mocks base method.
+func (m *MockIExportUsecase) RequestExport(arg0 context.Context, arg1 string) (*entities.AccountExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestExport", arg0, arg1)
+	ret0, _ := ret[0].(*entities.AccountExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestExport indicates an expected call of RequestExport.
+func (mr *MockIExportUsecaseMockRecorder) RequestExport(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestExport", reflect.TypeOf((*MockIExportUsecase)(nil).RequestExport), arg0, arg1)
+}
+
+// MockIURLSigner is a mock of IURLSigner interface.
+type MockIURLSigner struct {
+	ctrl     *gomock.Controller
+	recorder *MockIURLSignerMockRecorder
+}
+
+// MockIURLSignerMockRecorder is the mock recorder for MockIURLSigner.
+type MockIURLSignerMockRecorder struct {
+	mock *MockIURLSigner
+}
+
+// NewMockIURLSigner creates a new mock instance.
+func NewMockIURLSigner(ctrl *gomock.Controller) *MockIURLSigner {
+	mock := &MockIURLSigner{ctrl: ctrl}
+	mock.recorder = &MockIURLSignerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIURLSigner) EXPECT() *MockIURLSignerMockRecorder {
+	return m.recorder
+}
+
+// Sign mocks base method.
+func (m *MockIURLSigner) Sign(arg0 string) (string, string) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sign", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	return ret0, ret1
+}
+
+// Sign indicates an expected call of Sign.
+func (mr *MockIURLSignerMockRecorder) Sign(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sign", reflect.TypeOf((*MockIURLSigner)(nil).Sign), arg0)
+}
+
+// Verify mocks base method.
+func (m *MockIURLSigner) Verify(arg0, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockIURLSignerMockRecorder) Verify(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockIURLSigner)(nil).Verify), arg0, arg1, arg2)
+}
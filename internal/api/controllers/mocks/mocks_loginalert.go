@@ -0,0 +1,112 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/api/controllers (interfaces: ILoginAlertUsecase)
+//
+// Generated by this command:
+//
+//	mockgen -destination ./mocks/mocks_loginalert.go -package controllers_test github.com/alexsibrin/runbot-auth/internal/api/controllers ILoginAlertUsecase
+//
+
+// Package controllers_test is a generated GoMock package.
+package controllers_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	usecases "github.com/alexsibrin/runbot-auth/internal/usecases"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockILoginAlertUsecase is a mock of ILoginAlertUsecase interface.
+type MockILoginAlertUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockILoginAlertUsecaseMockRecorder
+}
+
+// MockILoginAlertUsecaseMockRecorder is the mock recorder for MockILoginAlertUsecase.
+type MockILoginAlertUsecaseMockRecorder struct {
+	mock *MockILoginAlertUsecase
+}
+
+// NewMockILoginAlertUsecase creates a new mock instance.
+func NewMockILoginAlertUsecase(ctrl *gomock.Controller) *MockILoginAlertUsecase {
+	mock := &MockILoginAlertUsecase{ctrl: ctrl}
+	mock.recorder = &MockILoginAlertUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockILoginAlertUsecase) EXPECT() *MockILoginAlertUsecaseMockRecorder {
+	return m.recorder
+}
+
+// CheckNotMe mocks base method.
+func (m *MockILoginAlertUsecase) CheckNotMe(arg0 context.Context, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckNotMe", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckNotMe indicates an expected call of CheckNotMe.
+func (mr *MockILoginAlertUsecaseMockRecorder) CheckNotMe(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckNotMe", reflect.TypeOf((*MockILoginAlertUsecase)(nil).CheckNotMe), arg0, arg1, arg2, arg3)
+}
+
+// CheckPasswordReset mocks base method.
+func (m *MockILoginAlertUsecase) CheckPasswordReset(arg0 context.Context, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckPasswordReset", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckPasswordReset indicates an expected call of CheckPasswordReset.
+func (mr *MockILoginAlertUsecaseMockRecorder) CheckPasswordReset(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckPasswordReset", reflect.TypeOf((*MockILoginAlertUsecase)(nil).CheckPasswordReset), arg0, arg1, arg2, arg3)
+}
+
+// NotMe mocks base method.
+func (m *MockILoginAlertUsecase) NotMe(arg0 context.Context, arg1, arg2, arg3 string) (*usecases.PasswordResetToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotMe", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*usecases.PasswordResetToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NotMe indicates an expected call of NotMe.
+func (mr *MockILoginAlertUsecaseMockRecorder) NotMe(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotMe", reflect.TypeOf((*MockILoginAlertUsecase)(nil).NotMe), arg0, arg1, arg2, arg3)
+}
+
+// RequestPasswordReset mocks base method.
+func (m *MockILoginAlertUsecase) RequestPasswordReset(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestPasswordReset", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestPasswordReset indicates an expected call of RequestPasswordReset.
+func (mr *MockILoginAlertUsecaseMockRecorder) RequestPasswordReset(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestPasswordReset", reflect.TypeOf((*MockILoginAlertUsecase)(nil).RequestPasswordReset), arg0, arg1)
+}
+
+// ResetPassword mocks base method.
+func (m *MockILoginAlertUsecase) ResetPassword(arg0 context.Context, arg1 *usecases.PasswordResetRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockILoginAlertUsecaseMockRecorder) ResetPassword(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockILoginAlertUsecase)(nil).ResetPassword), arg0, arg1)
+}
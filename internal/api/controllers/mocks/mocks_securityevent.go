@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/api/controllers (interfaces: ISecurityEventUsecase)
+//
+// Generated by this command:
+//
+//	mockgen -destination ./mocks/mocks_securityevent.go -package controllers_test github.com/alexsibrin/runbot-auth/internal/api/controllers ISecurityEventUsecase
+//
+
+// Package controllers_test is a generated GoMock package.
+package controllers_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/alexsibrin/runbot-auth/internal/entities"
+	repositories "github.com/alexsibrin/runbot-auth/internal/repositories"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockISecurityEventUsecase is a mock of ISecurityEventUsecase interface.
+type MockISecurityEventUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockISecurityEventUsecaseMockRecorder
+}
+
+// MockISecurityEventUsecaseMockRecorder is the mock recorder for MockISecurityEventUsecase.
+type MockISecurityEventUsecaseMockRecorder struct {
+	mock *MockISecurityEventUsecase
+}
+
+// NewMockISecurityEventUsecase creates a new mock instance.
+func NewMockISecurityEventUsecase(ctrl *gomock.Controller) *MockISecurityEventUsecase {
+	mock := &MockISecurityEventUsecase{ctrl: ctrl}
+	mock.recorder = &MockISecurityEventUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockISecurityEventUsecase) EXPECT() *MockISecurityEventUsecaseMockRecorder {
+	return m.recorder
+}
+
+// GetByAccount mocks base method.
+func (m *MockISecurityEventUsecase) GetByAccount(arg0 context.Context, arg1 string, arg2 int64, arg3 int) ([]*entities.SecurityEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByAccount", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*entities.SecurityEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByAccount indicates an expected call of GetByAccount.
+func (mr *MockISecurityEventUsecaseMockRecorder) GetByAccount(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByAccount", reflect.TypeOf((*MockISecurityEventUsecase)(nil).GetByAccount), arg0, arg1, arg2, arg3)
+}
+
+// Search mocks base method.
+func (m *MockISecurityEventUsecase) Search(arg0 context.Context, arg1 *repositories.SecurityEventFilter) ([]*entities.SecurityEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.SecurityEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockISecurityEventUsecaseMockRecorder) Search(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockISecurityEventUsecase)(nil).Search), arg0, arg1)
+}
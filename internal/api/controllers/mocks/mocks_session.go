@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/api/controllers (interfaces: ISessionUsecase)
+//
+// Generated by this command:
+//
+//	mockgen -destination ./mocks/mocks_session.go -package controllers_test github.com/alexsibrin/runbot-auth/internal/api/controllers ISessionUsecase
+//
+
+// Package controllers_test is a generated GoMock package.
+package controllers_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/alexsibrin/runbot-auth/internal/entities"
+	usecases "github.com/alexsibrin/runbot-auth/internal/usecases"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockISessionUsecase is a mock of ISessionUsecase interface.
+type MockISessionUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockISessionUsecaseMockRecorder
+}
+
+// MockISessionUsecaseMockRecorder is the mock recorder for MockISessionUsecase.
+type MockISessionUsecaseMockRecorder struct {
+	mock *MockISessionUsecase
+}
+
+// NewMockISessionUsecase creates a new mock instance.
+func NewMockISessionUsecase(ctrl *gomock.Controller) *MockISessionUsecase {
+	mock := &MockISessionUsecase{ctrl: ctrl}
+	mock.recorder = &MockISessionUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockISessionUsecase) EXPECT() *MockISessionUsecaseMockRecorder {
+	return m.recorder
+}
+
+// GetActive mocks base method.
+func (m *MockISessionUsecase) GetActive(arg0 context.Context, arg1 string) ([]*entities.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActive", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActive indicates an expected call of GetActive.
+func (mr *MockISessionUsecaseMockRecorder) GetActive(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActive", reflect.TypeOf((*MockISessionUsecase)(nil).GetActive), arg0, arg1)
+}
+
+// Revoke mocks base method.
+func (m *MockISessionUsecase) Revoke(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockISessionUsecaseMockRecorder) Revoke(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockISessionUsecase)(nil).Revoke), arg0, arg1, arg2)
+}
+
+// RevokeAll mocks base method.
+func (m *MockISessionUsecase) RevokeAll(arg0 context.Context, arg1 *usecases.SessionRevokeAllRequest) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAll", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeAll indicates an expected call of RevokeAll.
+func (mr *MockISessionUsecaseMockRecorder) RevokeAll(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAll", reflect.TypeOf((*MockISessionUsecase)(nil).RevokeAll), arg0, arg1)
+}
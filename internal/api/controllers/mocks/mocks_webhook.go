@@ -0,0 +1,147 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/api/controllers (interfaces: IWebhookUsecase)
+//
+// Generated by this command:
+//
+//	mockgen -destination ./mocks/mocks_webhook.go -package controllers_test github.com/alexsibrin/runbot-auth/internal/api/controllers IWebhookUsecase
+//
+
+// Package controllers_test is a generated GoMock package.
+package controllers_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/alexsibrin/runbot-auth/internal/entities"
+	repositories "github.com/alexsibrin/runbot-auth/internal/repositories"
+	usecases "github.com/alexsibrin/runbot-auth/internal/usecases"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIWebhookUsecase is a mock of IWebhookUsecase interface.
+type MockIWebhookUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockIWebhookUsecaseMockRecorder
+}
+
+// MockIWebhookUsecaseMockRecorder is the mock recorder for MockIWebhookUsecase.
+type MockIWebhookUsecaseMockRecorder struct {
+	mock *MockIWebhookUsecase
+}
+
+// NewMockIWebhookUsecase creates a new mock instance.
+func NewMockIWebhookUsecase(ctrl *gomock.Controller) *MockIWebhookUsecase {
+	mock := &MockIWebhookUsecase{ctrl: ctrl}
+	mock.recorder = &MockIWebhookUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIWebhookUsecase) EXPECT() *MockIWebhookUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockIWebhookUsecase) Create(arg0 context.Context, arg1 *usecases.WebhookCreateRequest) (*entities.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIWebhookUsecaseMockRecorder) Create(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIWebhookUsecase)(nil).Create), arg0, arg1)
+}
+
+// Delete mocks base method.
+func (m *MockIWebhookUsecase) Delete(arg0 context.Context, arg1 *usecases.WebhookDeleteRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockIWebhookUsecaseMockRecorder) Delete(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIWebhookUsecase)(nil).Delete), arg0, arg1)
+}
+
+// GetAll mocks base method.
+func (m *MockIWebhookUsecase) GetAll(arg0 context.Context) ([]*entities.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", arg0)
+	ret0, _ := ret[0].([]*entities.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockIWebhookUsecaseMockRecorder) GetAll(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockIWebhookUsecase)(nil).GetAll), arg0)
+}
+
+// GetOne mocks base method.
+func (m *MockIWebhookUsecase) GetOne(arg0 context.Context, arg1 string) (*entities.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOne", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOne indicates an expected call of GetOne.
+func (mr *MockIWebhookUsecaseMockRecorder) GetOne(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOne", reflect.TypeOf((*MockIWebhookUsecase)(nil).GetOne), arg0, arg1)
+}
+
+// ListDeliveries mocks base method.
+func (m *MockIWebhookUsecase) ListDeliveries(arg0 context.Context, arg1 *repositories.WebhookDeliveryFilter) ([]*entities.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeliveries indicates an expected call of ListDeliveries.
+func (mr *MockIWebhookUsecaseMockRecorder) ListDeliveries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeliveries", reflect.TypeOf((*MockIWebhookUsecase)(nil).ListDeliveries), arg0, arg1)
+}
+
+// Redeliver mocks base method.
+func (m *MockIWebhookUsecase) Redeliver(arg0 context.Context, arg1 *usecases.WebhookRedeliverRequest) (*entities.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redeliver", arg0, arg1)
+	ret0, _ := ret[0].(*entities.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Redeliver indicates an expected call of Redeliver.
+func (mr *MockIWebhookUsecaseMockRecorder) Redeliver(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redeliver", reflect.TypeOf((*MockIWebhookUsecase)(nil).Redeliver), arg0, arg1)
+}
+
+// Update mocks base method.
+func (m *MockIWebhookUsecase) Update(arg0 context.Context, arg1 *usecases.WebhookUpdateRequest) (*entities.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockIWebhookUsecaseMockRecorder) Update(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockIWebhookUsecase)(nil).Update), arg0, arg1)
+}
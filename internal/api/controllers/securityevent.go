@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"strconv"
+)
+
+const (
+	securityEventControllerKey = "SecurityEvent"
+)
+
+//go:generate mockgen -destination ./mocks/mocks_securityevent.go -package controllers_test github.com/alexsibrin/runbot-auth/internal/api/controllers ISecurityEventUsecase
+type ISecurityEventUsecase interface {
+	GetByAccount(ctx context.Context, accountuuid string, beforeid int64, limit int) ([]*entities.SecurityEvent, error)
+	Search(ctx context.Context, filter *repositories.SecurityEventFilter) ([]*entities.SecurityEvent, error)
+}
+
+type SecurityEventDependencies struct {
+	Usecase ISecurityEventUsecase
+}
+
+type SecurityEvent struct {
+	usecase ISecurityEventUsecase
+}
+
+func NewSecurityEvent(d *SecurityEventDependencies) (*SecurityEvent, error) {
+	if d == nil {
+		return nil, NewErrUnitIsNil(securityEventControllerKey, "whole struct")
+	}
+	if d.Usecase == nil {
+		return nil, NewErrUnitIsNil(securityEventControllerKey, "Usecase")
+	}
+	return &SecurityEvent{
+		usecase: d.Usecase,
+	}, nil
+}
+
+// GetByAccount returns a page of the login history of the account, the empty cursor is the first page
+func (c *SecurityEvent) GetByAccount(ctx context.Context, accountuuid, cursor string, limit int) (*models.SecurityEventsResponse, error) {
+	beforeid, err := validators.Cursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := c.usecase.GetByAccount(ctx, accountuuid, beforeid, limit)
+	if err != nil {
+		return nil, err
+	}
+	return c.securityEvents2Response(events, limit), nil
+}
+
+// Search returns a page of the events of all the accounts for admins
+func (c *SecurityEvent) Search(ctx context.Context, model *models.SecurityEventsSearch) (*models.SecurityEventsResponse, error) {
+	filter, err := c.securityEventsSearch2Filter(model)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := c.usecase.Search(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return c.securityEvents2Response(events, model.Limit), nil
+}
+
+func (c *SecurityEvent) securityEventsSearch2Filter(model *models.SecurityEventsSearch) (*repositories.SecurityEventFilter, error) {
+	if model.AccountUUID != "" {
+		if err := validators.AccountUUID(model.AccountUUID); err != nil {
+			return nil, err
+		}
+	}
+	if model.IP != "" {
+		if err := validators.IP(model.IP); err != nil {
+			return nil, err
+		}
+	}
+	if err := validators.TimeRange(model.Since, model.Until); err != nil {
+		return nil, err
+	}
+	beforeid, err := validators.Cursor(model.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &repositories.SecurityEventFilter{
+		AccountUUID: model.AccountUUID,
+		IP:          model.IP,
+		Since:       model.Since,
+		Until:       model.Until,
+		BeforeID:    beforeid,
+		Limit:       model.Limit,
+	}
+	for _, name := range model.Types {
+		eventtype, ok := securityEventType(name)
+		if !ok {
+			return nil, validators.ErrSecurityEventTypeIsNotValid
+		}
+		filter.Types = append(filter.Types, eventtype)
+	}
+	return filter, nil
+}
+
+// securityEvents2Response sets the next cursor if the page is full, so the last page may be empty
+func (c *SecurityEvent) securityEvents2Response(events []*entities.SecurityEvent, limit int) *models.SecurityEventsResponse {
+	result := &models.SecurityEventsResponse{
+		Events: make([]*models.SecurityEvent, 0, len(events)),
+	}
+	for _, event := range events {
+		result.Events = append(result.Events, &models.SecurityEvent{
+			ID:          event.ID,
+			AccountUUID: event.AccountUUID,
+			Type:        entities.SecurityEventTypeName(event.Type),
+			IP:          event.IP,
+			UserAgent:   event.UserAgent,
+			Details:     event.Details,
+			CreatedAt:   event.CreatedAt,
+		})
+	}
+	if len(events) > 0 && len(events) >= usecases.SecurityEventsLimit(limit) {
+		result.NextCursor = strconv.FormatInt(events[len(events)-1].ID, 10)
+	}
+	return result
+}
+
+func securityEventType(name string) (uint8, bool) {
+	for eventtype, typename := range entities.SecurityEventTypeNames {
+		if typename == name {
+			return eventtype, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"context"
+	controllers_test "github.com/alexsibrin/runbot-auth/internal/api/controllers/mocks"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestSecurityEvent_GetByAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+	mockedUsecase := controllers_test.NewMockISecurityEventUsecase(ctrl)
+	controller := &SecurityEvent{usecase: mockedUsecase}
+
+	t.Run("Events are mapped", func(t *testing.T) {
+		mockedUsecase.EXPECT().GetByAccount(ctx, "accountuuid", int64(0), 10).Return([]*entities.SecurityEvent{{
+			ID:          7,
+			AccountUUID: "accountuuid",
+			Type:        entities.SecurityEventSignInFailed,
+			IP:          "10.0.0.1",
+			UserAgent:   "someagent",
+			Details:     "wrong password",
+			CreatedAt:   100,
+		}}, nil)
+
+		result, err := controller.GetByAccount(ctx, "accountuuid", "", 10)
+		require.NoError(t, err)
+		assert.Equal(t, &models.SecurityEventsResponse{
+			Events: []*models.SecurityEvent{{
+				ID:          7,
+				AccountUUID: "accountuuid",
+				Type:        "signin_failed",
+				IP:          "10.0.0.1",
+				UserAgent:   "someagent",
+				Details:     "wrong password",
+				CreatedAt:   100,
+			}},
+		}, result)
+	})
+
+	t.Run("Full page has the next cursor", func(t *testing.T) {
+		mockedUsecase.EXPECT().GetByAccount(ctx, "accountuuid", int64(10), 2).Return([]*entities.SecurityEvent{{ID: 9}, {ID: 8}}, nil)
+
+		result, err := controller.GetByAccount(ctx, "accountuuid", "10", 2)
+		require.NoError(t, err)
+		assert.Equal(t, "8", result.NextCursor)
+	})
+
+	t.Run("No events are an empty list", func(t *testing.T) {
+		mockedUsecase.EXPECT().GetByAccount(ctx, "accountuuid", int64(0), 0).Return(nil, nil)
+
+		result, err := controller.GetByAccount(ctx, "accountuuid", "", 0)
+		require.NoError(t, err)
+		assert.NotNil(t, result.Events)
+		assert.Empty(t, result.Events)
+		assert.Empty(t, result.NextCursor)
+	})
+
+	t.Run("Cursor is not valid", func(t *testing.T) {
+		_, err := controller.GetByAccount(ctx, "accountuuid", "abc", 0)
+		assert.ErrorIs(t, err, validators.ErrCursorIsNotValid)
+	})
+}
+
+func TestSecurityEvent_Search(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+	mockedUsecase := controllers_test.NewMockISecurityEventUsecase(ctrl)
+	controller := &SecurityEvent{usecase: mockedUsecase}
+	accountuuid := uuid.NewString()
+
+	testCases := []struct {
+		name        string
+		in          *models.SecurityEventsSearch
+		setupMocks  func()
+		expectedErr error
+	}{
+		{
+			name: "Filter is mapped",
+			in: &models.SecurityEventsSearch{
+				AccountUUID: accountuuid,
+				Types:       []string{"signin", "signin_failed"},
+				IP:          "10.0.0.1",
+				Since:       100,
+				Until:       200,
+				Cursor:      "50",
+				Limit:       20,
+			},
+			setupMocks: func() {
+				mockedUsecase.EXPECT().Search(ctx, &repositories.SecurityEventFilter{
+					AccountUUID: accountuuid,
+					Types:       []uint8{entities.SecurityEventSignIn, entities.SecurityEventSignInFailed},
+					IP:          "10.0.0.1",
+					Since:       100,
+					Until:       200,
+					BeforeID:    50,
+					Limit:       20,
+				}).Return(nil, nil)
+			},
+		},
+		{
+			name:        "Account UUID is not valid",
+			in:          &models.SecurityEventsSearch{AccountUUID: "notuuid"},
+			setupMocks:  func() {},
+			expectedErr: validators.ErrUUIDIsNotValid,
+		},
+		{
+			name:        "Type is not valid",
+			in:          &models.SecurityEventsSearch{Types: []string{"logout"}},
+			setupMocks:  func() {},
+			expectedErr: validators.ErrSecurityEventTypeIsNotValid,
+		},
+		{
+			name:        "IP is not valid",
+			in:          &models.SecurityEventsSearch{IP: "10.0.0"},
+			setupMocks:  func() {},
+			expectedErr: validators.ErrIPIsNotValid,
+		},
+		{
+			name:        "Time range is not valid",
+			in:          &models.SecurityEventsSearch{Since: 200, Until: 100},
+			setupMocks:  func() {},
+			expectedErr: validators.ErrTimeRangeIsNotValid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			_, err := controller.Search(ctx, tc.in)
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
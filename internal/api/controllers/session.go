@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+)
+
+const (
+	sessionControllerKey = "Session"
+)
+
+//go:generate mockgen -destination ./mocks/mocks_session.go -package controllers_test github.com/alexsibrin/runbot-auth/internal/api/controllers ISessionUsecase
+type ISessionUsecase interface {
+	GetActive(ctx context.Context, accountuuid string) ([]*entities.Session, error)
+	Revoke(ctx context.Context, accountuuid, sessionuuid string) error
+	RevokeAll(ctx context.Context, r *usecases.SessionRevokeAllRequest) (int, error)
+}
+
+type SessionDependencies struct {
+	Usecase ISessionUsecase
+}
+
+type Session struct {
+	usecase ISessionUsecase
+}
+
+func NewSession(d *SessionDependencies) (*Session, error) {
+	if d == nil {
+		return nil, NewErrUnitIsNil(sessionControllerKey, "whole struct")
+	}
+	if d.Usecase == nil {
+		return nil, NewErrUnitIsNil(sessionControllerKey, "Usecase")
+	}
+	return &Session{
+		usecase: d.Usecase,
+	}, nil
+}
+
+// GetActive returns the devices signed in to the account
+func (c *Session) GetActive(ctx context.Context, accountuuid string) (*models.SessionsResponse, error) {
+	sessions, err := c.usecase.GetActive(ctx, accountuuid)
+	if err != nil {
+		return nil, err
+	}
+	return c.sessionEntities2Response(sessions), nil
+}
+
+// Revoke signs the device out, its refresh token can't be used anymore
+func (c *Session) Revoke(ctx context.Context, accountuuid, sessionuuid string) error {
+	if err := validators.AccountUUID(sessionuuid); err != nil {
+		return err
+	}
+	return c.usecase.Revoke(ctx, accountuuid, sessionuuid)
+}
+
+// RevokeAll signs all the devices out of the account on behalf of the actor, it returns how many sessions were active
+func (c *Session) RevokeAll(ctx context.Context, model *models.RevokeSessions) (int, error) {
+	if err := validators.AccountUUID(model.UUID); err != nil {
+		return 0, err
+	}
+	if err := validators.Actor(model.Actor); err != nil {
+		return 0, err
+	}
+	return c.usecase.RevokeAll(ctx, &usecases.SessionRevokeAllRequest{
+		AccountUUID: model.UUID,
+		Actor:       model.Actor,
+		Audit:       auditMetadata2Request(model.Audit),
+	})
+}
+
+func (c *Session) sessionEntities2Response(sessions []*entities.Session) *models.SessionsResponse {
+	result := &models.SessionsResponse{
+		Sessions: make([]*models.Session, 0, len(sessions)),
+	}
+	for _, session := range sessions {
+		result.Sessions = append(result.Sessions, &models.Session{
+			UUID:        session.UUID,
+			IP:          session.IP,
+			DeviceName:  session.DeviceName,
+			UserAgent:   session.UserAgent,
+			CreatedAt:   session.CreatedAt,
+			RefreshedAt: session.RefreshedAt,
+			ExpiresAt:   session.ExpiresAt,
+		})
+	}
+	return result
+}
@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"context"
+	controllers_test "github.com/alexsibrin/runbot-auth/internal/api/controllers/mocks"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestSession_GetActive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+	mockedUsecase := controllers_test.NewMockISessionUsecase(ctrl)
+	controller := &Session{usecase: mockedUsecase}
+
+	t.Run("Sessions are mapped", func(t *testing.T) {
+		mockedUsecase.EXPECT().GetActive(ctx, "accountuuid").Return([]*entities.Session{{
+			UUID:        "sessionuuid",
+			AccountUUID: "accountuuid",
+			IP:          "10.0.0.1",
+			UserAgent:   "someagent",
+			DeviceName:  "Chrome on Windows",
+			CreatedAt:   100,
+			RefreshedAt: 200,
+			ExpiresAt:   300,
+		}}, nil)
+
+		result, err := controller.GetActive(ctx, "accountuuid")
+		require.NoError(t, err)
+		assert.Equal(t, &models.SessionsResponse{
+			Sessions: []*models.Session{{
+				UUID:        "sessionuuid",
+				IP:          "10.0.0.1",
+				DeviceName:  "Chrome on Windows",
+				UserAgent:   "someagent",
+				CreatedAt:   100,
+				RefreshedAt: 200,
+				ExpiresAt:   300,
+			}},
+		}, result)
+	})
+
+	t.Run("No sessions are an empty list", func(t *testing.T) {
+		mockedUsecase.EXPECT().GetActive(ctx, "accountuuid").Return(nil, nil)
+
+		result, err := controller.GetActive(ctx, "accountuuid")
+		require.NoError(t, err)
+		assert.NotNil(t, result.Sessions)
+		assert.Empty(t, result.Sessions)
+	})
+}
+
+func TestSession_Revoke(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+	mockedUsecase := controllers_test.NewMockISessionUsecase(ctrl)
+	controller := &Session{usecase: mockedUsecase}
+
+	sessionuuid := uuid.NewString()
+
+	testCases := []struct {
+		name        string
+		in          string
+		setupMocks  func()
+		expectedErr error
+	}{
+		{
+			name: "Session is revoked",
+			in:   sessionuuid,
+			setupMocks: func() {
+				mockedUsecase.EXPECT().Revoke(ctx, "accountuuid", sessionuuid).Return(nil)
+			},
+		},
+		{
+			name:        "Invalid UUID",
+			in:          "invaliduuid",
+			setupMocks:  func() {},
+			expectedErr: validators.ErrUUIDIsNotValid,
+		},
+		{
+			name: "Session is not exist",
+			in:   sessionuuid,
+			setupMocks: func() {
+				mockedUsecase.EXPECT().Revoke(ctx, "accountuuid", sessionuuid).Return(usecases.ErrSessionIsNotExist)
+			},
+			expectedErr: usecases.ErrSessionIsNotExist,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			err := controller.Revoke(ctx, "accountuuid", tc.in)
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestSession_RevokeAll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+	mockedUsecase := controllers_test.NewMockISessionUsecase(ctrl)
+	controller := &Session{usecase: mockedUsecase}
+
+	accountuuid := uuid.NewString()
+
+	testCases := []struct {
+		name          string
+		in            *models.RevokeSessions
+		setupMocks    func()
+		expectedCount int
+		expectedErr   error
+	}{
+		{
+			name: "Sessions are revoked",
+			in:   &models.RevokeSessions{UUID: accountuuid, Actor: "alice", Audit: &models.AuditMetadata{Method: "cli revoke-sessions"}},
+			setupMocks: func() {
+				mockedUsecase.EXPECT().RevokeAll(ctx, &usecases.SessionRevokeAllRequest{
+					AccountUUID: accountuuid,
+					Actor:       "alice",
+					Audit:       &usecases.AuditMetadata{Method: "cli revoke-sessions"},
+				}).Return(3, nil)
+			},
+			expectedCount: 3,
+		},
+		{
+			name:        "Invalid UUID",
+			in:          &models.RevokeSessions{UUID: "invaliduuid", Actor: "alice"},
+			setupMocks:  func() {},
+			expectedErr: validators.ErrUUIDIsNotValid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			count, err := controller.RevokeAll(ctx, tc.in)
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expectedCount, count)
+		})
+	}
+}
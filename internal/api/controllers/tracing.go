@@ -0,0 +1,8 @@
+package controllers
+
+import (
+	"go.opentelemetry.io/otel"
+)
+
+// tracer starts the spans of the controllers, they aren't recorded until the tracer provider is set up
+var tracer = otel.Tracer("github.com/alexsibrin/runbot-auth/internal/api/controllers")
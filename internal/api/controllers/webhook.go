@@ -0,0 +1,273 @@
+package controllers
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"strconv"
+)
+
+const (
+	webhookControllerKey = "Webhook"
+)
+
+// webhookDeliveryStatuses are the names of the delivery statuses in the API
+var webhookDeliveryStatuses = map[uint8]string{
+	entities.WebhookDeliveryPending:   "pending",
+	entities.WebhookDeliveryDelivered: "delivered",
+	entities.WebhookDeliveryDead:      "dead",
+}
+
+//go:generate mockgen -destination ./mocks/mocks_webhook.go -package controllers_test github.com/alexsibrin/runbot-auth/internal/api/controllers IWebhookUsecase
+type IWebhookUsecase interface {
+	Create(ctx context.Context, r *usecases.WebhookCreateRequest) (*entities.Webhook, error)
+	GetOne(ctx context.Context, webhookuuid string) (*entities.Webhook, error)
+	GetAll(ctx context.Context) ([]*entities.Webhook, error)
+	Update(ctx context.Context, r *usecases.WebhookUpdateRequest) (*entities.Webhook, error)
+	Delete(ctx context.Context, r *usecases.WebhookDeleteRequest) error
+	ListDeliveries(ctx context.Context, filter *repositories.WebhookDeliveryFilter) ([]*entities.WebhookDelivery, error)
+	Redeliver(ctx context.Context, r *usecases.WebhookRedeliverRequest) (*entities.WebhookDelivery, error)
+}
+
+type WebhookDependencies struct {
+	Usecase IWebhookUsecase
+}
+
+type Webhook struct {
+	usecase IWebhookUsecase
+}
+
+func NewWebhook(d *WebhookDependencies) (*Webhook, error) {
+	if d == nil {
+		return nil, NewErrUnitIsNil(webhookControllerKey, "whole struct")
+	}
+	if d.Usecase == nil {
+		return nil, NewErrUnitIsNil(webhookControllerKey, "Usecase")
+	}
+	return &Webhook{
+		usecase: d.Usecase,
+	}, nil
+}
+
+// Create returns the webhook with its secret, it's the only time the secret is returned but a rotation
+func (c *Webhook) Create(ctx context.Context, model *models.WebhookCreate) (*models.Webhook, error) {
+	if err := c.validateWebhook(model.URL, model.EventTypes); err != nil {
+		return nil, err
+	}
+	if err := validators.Actor(model.Actor); err != nil {
+		return nil, err
+	}
+
+	webhook, err := c.usecase.Create(ctx, &usecases.WebhookCreateRequest{
+		URL:        model.URL,
+		EventTypes: model.EventTypes,
+		Actor:      model.Actor,
+		Audit:      auditMetadata2Request(model.Audit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.webhook2Model(webhook, true), nil
+}
+
+func (c *Webhook) GetOne(ctx context.Context, webhookuuid string) (*models.Webhook, error) {
+	if err := validators.AccountUUID(webhookuuid); err != nil {
+		return nil, err
+	}
+
+	webhook, err := c.usecase.GetOne(ctx, webhookuuid)
+	if err != nil {
+		return nil, err
+	}
+	return c.webhook2Model(webhook, false), nil
+}
+
+func (c *Webhook) GetAll(ctx context.Context) (*models.WebhooksResponse, error) {
+	webhooks, err := c.usecase.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.WebhooksResponse{
+		Webhooks: make([]*models.Webhook, 0, len(webhooks)),
+	}
+	for _, webhook := range webhooks {
+		result.Webhooks = append(result.Webhooks, c.webhook2Model(webhook, false))
+	}
+	return result, nil
+}
+
+// Update returns the new secret if it's rotated
+func (c *Webhook) Update(ctx context.Context, model *models.WebhookUpdate) (*models.Webhook, error) {
+	if err := validators.AccountUUID(model.UUID); err != nil {
+		return nil, err
+	}
+	if err := c.validateWebhook(model.URL, model.EventTypes); err != nil {
+		return nil, err
+	}
+	if err := validators.Actor(model.Actor); err != nil {
+		return nil, err
+	}
+
+	webhook, err := c.usecase.Update(ctx, &usecases.WebhookUpdateRequest{
+		UUID:         model.UUID,
+		URL:          model.URL,
+		EventTypes:   model.EventTypes,
+		Active:       model.Active,
+		RotateSecret: model.RotateSecret,
+		Actor:        model.Actor,
+		Audit:        auditMetadata2Request(model.Audit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.webhook2Model(webhook, model.RotateSecret), nil
+}
+
+func (c *Webhook) Delete(ctx context.Context, model *models.WebhookDelete) error {
+	if err := validators.AccountUUID(model.UUID); err != nil {
+		return err
+	}
+	if err := validators.Actor(model.Actor); err != nil {
+		return err
+	}
+	return c.usecase.Delete(ctx, &usecases.WebhookDeleteRequest{
+		UUID:  model.UUID,
+		Actor: model.Actor,
+		Audit: auditMetadata2Request(model.Audit),
+	})
+}
+
+// ListDeliveries returns a page of the delivery log, the empty cursor is the first page
+func (c *Webhook) ListDeliveries(ctx context.Context, model *models.WebhookDeliveriesSearch) (*models.WebhookDeliveriesResponse, error) {
+	filter, err := c.webhookDeliveriesSearch2Filter(model)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := c.usecase.ListDeliveries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.WebhookDeliveriesResponse{
+		Deliveries: make([]*models.WebhookDelivery, 0, len(deliveries)),
+	}
+	for _, delivery := range deliveries {
+		result.Deliveries = append(result.Deliveries, c.webhookDelivery2Model(delivery))
+	}
+	if len(deliveries) > 0 && len(deliveries) >= usecases.WebhookDeliveriesLimit(model.Limit) {
+		result.NextCursor = strconv.FormatInt(deliveries[len(deliveries)-1].ID, 10)
+	}
+	return result, nil
+}
+
+// Redeliver schedules the delivery to be sent again, e.g. a dead one
+func (c *Webhook) Redeliver(ctx context.Context, model *models.WebhookRedeliver) (*models.WebhookDelivery, error) {
+	if model.ID <= 0 {
+		return nil, usecases.ErrWebhookDeliveryIsNotFound
+	}
+	if err := validators.Actor(model.Actor); err != nil {
+		return nil, err
+	}
+
+	delivery, err := c.usecase.Redeliver(ctx, &usecases.WebhookRedeliverRequest{
+		ID:    model.ID,
+		Actor: model.Actor,
+		Audit: auditMetadata2Request(model.Audit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.webhookDelivery2Model(delivery), nil
+}
+
+func (c *Webhook) validateWebhook(webhookurl string, eventtypes []string) error {
+	if err := validators.WebhookURL(webhookurl); err != nil {
+		return err
+	}
+	for _, eventtype := range eventtypes {
+		if err := validators.DomainEventType(eventtype); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Webhook) webhookDeliveriesSearch2Filter(model *models.WebhookDeliveriesSearch) (*repositories.WebhookDeliveryFilter, error) {
+	if model.WebhookUUID != "" {
+		if err := validators.AccountUUID(model.WebhookUUID); err != nil {
+			return nil, err
+		}
+	}
+	beforeid, err := validators.Cursor(model.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &repositories.WebhookDeliveryFilter{
+		WebhookUUID: model.WebhookUUID,
+		BeforeID:    beforeid,
+		Limit:       model.Limit,
+	}
+	for _, name := range model.Statuses {
+		status, ok := webhookDeliveryStatus(name)
+		if !ok {
+			return nil, validators.ErrWebhookDeliveryStatusIsNotValid
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+	return filter, nil
+}
+
+func (c *Webhook) webhook2Model(webhook *entities.Webhook, withsecret bool) *models.Webhook {
+	model := &models.Webhook{
+		UUID:       webhook.UUID,
+		URL:        webhook.URL,
+		EventTypes: webhook.EventTypes,
+		Active:     webhook.Active,
+		CreatedAt:  webhook.CreatedAt,
+		UpdatedAt:  webhook.UpdatedAt,
+	}
+	if withsecret {
+		model.Secret = webhook.Secret
+	}
+	return model
+}
+
+func (c *Webhook) webhookDelivery2Model(delivery *entities.WebhookDelivery) *models.WebhookDelivery {
+	return &models.WebhookDelivery{
+		ID:             delivery.ID,
+		WebhookUUID:    delivery.WebhookUUID,
+		EventUUID:      delivery.EventUUID,
+		EventType:      delivery.EventType,
+		Payload:        delivery.Payload,
+		Status:         webhookDeliveryStatusName(delivery.Status),
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+		UpdatedAt:      delivery.UpdatedAt,
+	}
+}
+
+func webhookDeliveryStatusName(status uint8) string {
+	name, ok := webhookDeliveryStatuses[status]
+	if !ok {
+		return "unknown"
+	}
+	return name
+}
+
+func webhookDeliveryStatus(name string) (uint8, bool) {
+	for status, statusname := range webhookDeliveryStatuses {
+		if statusname == name {
+			return status, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,247 @@
+package controllers
+
+import (
+	"context"
+	controllers_test "github.com/alexsibrin/runbot-auth/internal/api/controllers/mocks"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestWebhook_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+	mockedUsecase := controllers_test.NewMockIWebhookUsecase(ctrl)
+	controller := &Webhook{usecase: mockedUsecase}
+
+	t.Run("Secret is returned", func(t *testing.T) {
+		mockedUsecase.EXPECT().Create(ctx, &usecases.WebhookCreateRequest{
+			URL:        "https://partner.example.com/hooks",
+			EventTypes: []string{entities.DomainEventAccountCreated},
+			Actor:      "alice",
+			Audit:      &usecases.AuditMetadata{Method: "/runbotauth.Webhooks/Create", IP: "10.0.0.1:5000"},
+		}).Return(&entities.Webhook{
+			UUID:       "webhookuuid",
+			URL:        "https://partner.example.com/hooks",
+			Secret:     "secret",
+			EventTypes: []string{entities.DomainEventAccountCreated},
+			Active:     true,
+			CreatedAt:  100,
+			UpdatedAt:  100,
+		}, nil)
+
+		result, err := controller.Create(ctx, &models.WebhookCreate{
+			URL:        "https://partner.example.com/hooks",
+			EventTypes: []string{entities.DomainEventAccountCreated},
+			Actor:      "alice",
+			Audit:      &models.AuditMetadata{Method: "/runbotauth.Webhooks/Create", IP: "10.0.0.1:5000"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &models.Webhook{
+			UUID:       "webhookuuid",
+			URL:        "https://partner.example.com/hooks",
+			Secret:     "secret",
+			EventTypes: []string{entities.DomainEventAccountCreated},
+			Active:     true,
+			CreatedAt:  100,
+			UpdatedAt:  100,
+		}, result)
+	})
+
+	t.Run("URL is not valid", func(t *testing.T) {
+		_, err := controller.Create(ctx, &models.WebhookCreate{URL: "partner.example.com"})
+		assert.ErrorIs(t, err, validators.ErrWebhookURLIsNotValid)
+	})
+
+	t.Run("Event type is not valid", func(t *testing.T) {
+		_, err := controller.Create(ctx, &models.WebhookCreate{
+			URL:        "https://partner.example.com/hooks",
+			EventTypes: []string{entities.DomainEventAccountCreated, "account.unknown"},
+		})
+		assert.ErrorIs(t, err, validators.ErrDomainEventTypeIsNotValid)
+	})
+}
+
+func TestWebhook_GetAll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+	mockedUsecase := controllers_test.NewMockIWebhookUsecase(ctrl)
+	controller := &Webhook{usecase: mockedUsecase}
+
+	mockedUsecase.EXPECT().GetAll(ctx).Return([]*entities.Webhook{{UUID: "webhookuuid", Secret: "secret", Active: true}}, nil)
+
+	result, err := controller.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &models.WebhooksResponse{
+		Webhooks: []*models.Webhook{{UUID: "webhookuuid", Active: true}},
+	}, result)
+
+	mockedUsecase.EXPECT().GetAll(ctx).Return(nil, nil)
+
+	result, err = controller.GetAll(ctx)
+	require.NoError(t, err)
+	assert.NotNil(t, result.Webhooks)
+	assert.Empty(t, result.Webhooks)
+}
+
+func TestWebhook_Update(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+	mockedUsecase := controllers_test.NewMockIWebhookUsecase(ctrl)
+	controller := &Webhook{usecase: mockedUsecase}
+	webhookuuid := uuid.NewString()
+
+	testCases := []struct {
+		name           string
+		in             *models.WebhookUpdate
+		setupMocks     func()
+		expectedSecret string
+		expectedErr    error
+	}{
+		{
+			name: "Secret isn't returned",
+			in:   &models.WebhookUpdate{UUID: webhookuuid, URL: "https://partner.example.com/hooks"},
+			setupMocks: func() {
+				mockedUsecase.EXPECT().Update(ctx, &usecases.WebhookUpdateRequest{
+					UUID: webhookuuid,
+					URL:  "https://partner.example.com/hooks",
+				}).Return(&entities.Webhook{UUID: webhookuuid, Secret: "secret"}, nil)
+			},
+		},
+		{
+			name: "Rotated secret is returned",
+			in:   &models.WebhookUpdate{UUID: webhookuuid, URL: "https://partner.example.com/hooks", Active: true, RotateSecret: true},
+			setupMocks: func() {
+				mockedUsecase.EXPECT().Update(ctx, &usecases.WebhookUpdateRequest{
+					UUID:         webhookuuid,
+					URL:          "https://partner.example.com/hooks",
+					Active:       true,
+					RotateSecret: true,
+				}).Return(&entities.Webhook{UUID: webhookuuid, Secret: "rotated"}, nil)
+			},
+			expectedSecret: "rotated",
+		},
+		{
+			name:        "UUID is not valid",
+			in:          &models.WebhookUpdate{UUID: "webhookuuid", URL: "https://partner.example.com/hooks"},
+			setupMocks:  func() {},
+			expectedErr: validators.ErrUUIDIsNotValid,
+		},
+		{
+			name: "Webhook is not found",
+			in:   &models.WebhookUpdate{UUID: webhookuuid, URL: "https://partner.example.com/hooks"},
+			setupMocks: func() {
+				mockedUsecase.EXPECT().Update(ctx, gomock.Any()).Return(nil, usecases.ErrWebhookIsNotFound)
+			},
+			expectedErr: usecases.ErrWebhookIsNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			result, err := controller.Update(ctx, tc.in)
+			assert.ErrorIs(t, err, tc.expectedErr)
+			if tc.expectedErr == nil {
+				assert.Equal(t, tc.expectedSecret, result.Secret)
+			}
+		})
+	}
+}
+
+func TestWebhook_ListDeliveries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+	mockedUsecase := controllers_test.NewMockIWebhookUsecase(ctrl)
+	controller := &Webhook{usecase: mockedUsecase}
+	webhookuuid := uuid.NewString()
+
+	t.Run("Deliveries are mapped", func(t *testing.T) {
+		mockedUsecase.EXPECT().ListDeliveries(ctx, &repositories.WebhookDeliveryFilter{
+			WebhookUUID: webhookuuid,
+			Statuses:    []uint8{entities.WebhookDeliveryDead},
+			BeforeID:    10,
+			Limit:       1,
+		}).Return([]*entities.WebhookDelivery{{
+			ID:             9,
+			WebhookUUID:    webhookuuid,
+			EventUUID:      "eventuuid",
+			EventType:      entities.DomainEventAccountCreated,
+			Payload:        "{}",
+			Status:         entities.WebhookDeliveryDead,
+			Attempts:       10,
+			LastStatusCode: 500,
+			LastError:      "500 Internal Server Error",
+			CreatedAt:      100,
+			UpdatedAt:      200,
+		}}, nil)
+
+		result, err := controller.ListDeliveries(ctx, &models.WebhookDeliveriesSearch{
+			WebhookUUID: webhookuuid,
+			Statuses:    []string{"dead"},
+			Cursor:      "10",
+			Limit:       1,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &models.WebhookDeliveriesResponse{
+			Deliveries: []*models.WebhookDelivery{{
+				ID:             9,
+				WebhookUUID:    webhookuuid,
+				EventUUID:      "eventuuid",
+				EventType:      entities.DomainEventAccountCreated,
+				Payload:        "{}",
+				Status:         "dead",
+				Attempts:       10,
+				LastStatusCode: 500,
+				LastError:      "500 Internal Server Error",
+				CreatedAt:      100,
+				UpdatedAt:      200,
+			}},
+			NextCursor: "9",
+		}, result)
+	})
+
+	t.Run("Status is not valid", func(t *testing.T) {
+		_, err := controller.ListDeliveries(ctx, &models.WebhookDeliveriesSearch{Statuses: []string{"lost"}})
+		assert.ErrorIs(t, err, validators.ErrWebhookDeliveryStatusIsNotValid)
+	})
+
+	t.Run("Cursor is not valid", func(t *testing.T) {
+		_, err := controller.ListDeliveries(ctx, &models.WebhookDeliveriesSearch{Cursor: "abc"})
+		assert.ErrorIs(t, err, validators.ErrCursorIsNotValid)
+	})
+}
+
+func TestWebhook_Redeliver(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+	mockedUsecase := controllers_test.NewMockIWebhookUsecase(ctrl)
+	controller := &Webhook{usecase: mockedUsecase}
+
+	mockedUsecase.EXPECT().Redeliver(ctx, &usecases.WebhookRedeliverRequest{ID: 9, Actor: "alice"}).Return(&entities.WebhookDelivery{ID: 9, Status: entities.WebhookDeliveryPending}, nil)
+
+	result, err := controller.Redeliver(ctx, &models.WebhookRedeliver{ID: 9, Actor: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, "pending", result.Status)
+
+	_, err = controller.Redeliver(ctx, &models.WebhookRedeliver{ID: 0, Actor: "alice"})
+	assert.ErrorIs(t, err, usecases.ErrWebhookDeliveryIsNotFound)
+}
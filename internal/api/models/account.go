@@ -9,6 +9,7 @@ type SignUp struct {
 	Email    string
 	Password string
 	Name     string
+	Client   *Client `json:"-"`
 }
 
 // SignUpResponse the response for the successful signing up
@@ -21,6 +22,7 @@ type SignUpResponse struct {
 type SignIn struct {
 	Email    string
 	Password string
+	Client   *Client `json:"-"`
 }
 
 // SignIn the response for the successful signing in
@@ -64,6 +66,12 @@ type AccountGetModel struct {
 	UpdatedAt int64 `json:"UpdatedAt,omitempty"`
 }
 
+// AccountBatchGetResponse output model for a batch lookup, Missing are the UUIDs of no account
+type AccountBatchGetResponse struct {
+	Accounts []*AccountGetModel
+	Missing  []string
+}
+
 // UpdateAccount input model for an updating an account
 type UpdateAccount struct {
 	UUID  string
@@ -81,13 +89,85 @@ func (t Token) MarshalJSON() ([]byte, error) {
 	return json.Marshal(t.Access)
 }
 
+// ChangeAccountStatus the input model for a status change, a non-zero Until makes the status temporary
 type ChangeAccountStatus struct {
 	UUID   string
 	Status uint8
+	Reason string
+	Actor  string
+	Until  int64
+	Audit  *AuditMetadata `json:"-"`
 }
 
 type ChangeAccountStatusResponse struct {
 	UUID      string
 	Status    uint8
+	Until     int64
 	UpdatedAt int64
 }
+
+// StatusChange a record of the account status history
+type StatusChange struct {
+	ID         int64
+	FromStatus uint8
+	ToStatus   uint8
+	Reason     string
+	Actor      string
+	Until      int64
+	CreatedAt  int64
+}
+
+// StatusHistoryResponse the latest status changes of an account, the latest go first
+type StatusHistoryResponse struct {
+	UUID    string
+	Changes []*StatusChange
+}
+
+// DeleteAccount the input model for the account deletion
+type DeleteAccount struct {
+	UUID  string
+	Actor string
+	Audit *AuditMetadata `json:"-"`
+}
+
+// DeleteAccountResponse the result of the account deletion, the account can be restored until PurgeAt
+type DeleteAccountResponse struct {
+	UUID      string
+	Status    uint8
+	DeletedAt int64
+	PurgeAt   int64
+}
+
+// AccountsSearch input model for the admin account search, the empty fields don't filter.
+// Sort is "created_at" (the default) or "email", Cursor is the NextCursor of the previous page with the same sort
+type AccountsSearch struct {
+	Statuses     []string
+	EmailPrefix  string
+	NameContains string
+	// CreatedSince and CreatedUntil bound the creation time, CreatedUntil is exclusive
+	CreatedSince int64
+	CreatedUntil int64
+	Sort         string
+	Descending   bool
+	Cursor       string
+	Limit        int
+}
+
+// AccountSummary output model of an account in the admin search
+type AccountSummary struct {
+	UUID        string
+	Email       string
+	Name        string
+	Status      string
+	StatusUntil int64 `json:"StatusUntil,omitempty"`
+	CreatedAt   int64
+	UpdatedAt   int64 `json:"UpdatedAt,omitempty"`
+	DeletedAt   int64 `json:"DeletedAt,omitempty"`
+}
+
+// AccountsResponse a page of the found accounts.
+// NextCursor is set if there may be more accounts, it's the cursor of the next page
+type AccountsResponse struct {
+	Accounts   []*AccountSummary
+	NextCursor string `json:"NextCursor,omitempty"`
+}
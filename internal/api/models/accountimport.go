@@ -0,0 +1,26 @@
+package models
+
+// AccountRecord a row of the account import and export files, the empty fields are chosen by the service on import:
+// a new UUID, the active status and the current time. The empty password hash makes the owner reset the password
+type AccountRecord struct {
+	UUID         string `json:"uuid,omitempty"`
+	Email        string `json:"email"`
+	Name         string `json:"name"`
+	PasswordHash string `json:"password_hash,omitempty"`
+	Status       string `json:"status,omitempty"`
+	StatusUntil  int64  `json:"status_until,omitempty"`
+	CreatedAt    int64  `json:"created_at,omitempty"`
+}
+
+// AccountImportRow a record of the import file with its row number, the rows are counted from 1 without the header
+type AccountImportRow struct {
+	Row    int
+	Record *AccountRecord
+}
+
+// AccountImportError a rejected row of the import file
+type AccountImportError struct {
+	Row   int
+	Email string
+	Err   error
+}
@@ -0,0 +1,14 @@
+package models
+
+// The stream of the account changes
+
+// AccountChange is a domain event of an account, Data is the JSON of the event data.
+// Cursor resumes the watch after this change
+type AccountChange struct {
+	Cursor      string
+	ID          string
+	Type        string
+	AccountUUID string
+	CreatedAt   int64
+	Data        string
+}
@@ -0,0 +1,32 @@
+package models
+
+// The admin audit log
+
+// AuditMetadata describes the admin request, it's filled by the API layer, not by the user input
+type AuditMetadata struct {
+	Method    string
+	IP        string
+	UserAgent string
+}
+
+// AuditRecord a record of the hash-chained audit log, Before and After are the JSON of the changed values
+type AuditRecord struct {
+	ID         int64
+	Actor      string
+	Action     string
+	TargetUUID string
+	Before     string
+	After      string
+	Method     string
+	IP         string
+	UserAgent  string
+	CreatedAt  int64
+	PrevHash   string
+	Hash       string
+}
+
+// AuditLogResponse a page of the audit log from the earliest record, NextCursor is empty on the last page
+type AuditLogResponse struct {
+	Records    []*AuditRecord
+	NextCursor string `json:"NextCursor,omitempty"`
+}
@@ -0,0 +1,20 @@
+package models
+
+// The personal data export
+
+// Export the state of a personal data export, DownloadURL is set once the archive is ready
+type Export struct {
+	UUID        string
+	Status      string
+	CreatedAt   int64
+	ReadyAt     int64  `json:"ReadyAt,omitempty"`
+	ExpiresAt   int64  `json:"ExpiresAt,omitempty"`
+	DownloadURL string `json:"DownloadURL,omitempty"`
+}
+
+// ExportDownload input model for a download by the signed URL
+type ExportDownload struct {
+	UUID      string
+	Expires   string
+	Signature string
+}
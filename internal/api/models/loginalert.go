@@ -0,0 +1,38 @@
+package models
+
+// The "this wasn't me" link of the login alerts and the password reset
+
+// NotMe input model for the signed link of a login alert
+type NotMe struct {
+	UUID      string
+	Expires   string
+	Signature string
+}
+
+// NotMeConfirmation is the answer to opening the link, the link is confirmed by sending it with Method
+type NotMeConfirmation struct {
+	UUID   string
+	Method string
+}
+
+// PasswordResetToken authorizes a single password reset of the account, it's also the input model for checking
+// the emailed password reset link
+type PasswordResetToken struct {
+	UUID      string
+	Expires   string
+	Signature string
+}
+
+// PasswordReset input model for setting a new password by the reset token
+type PasswordReset struct {
+	UUID      string
+	Expires   string
+	Signature string
+	Password  string
+	Client    *Client `json:"-"`
+}
+
+// PasswordResetRequest input model for emailing the password reset link to the owner of the account
+type PasswordResetRequest struct {
+	Email string
+}
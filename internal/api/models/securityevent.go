@@ -0,0 +1,33 @@
+package models
+
+// The security log of the accounts
+
+// SecurityEvent a sign in, refresh, password or status change of an account
+type SecurityEvent struct {
+	ID          int64
+	AccountUUID string
+	Type        string
+	IP          string
+	UserAgent   string
+	Details     string
+	CreatedAt   int64
+}
+
+// SecurityEventsResponse a page of the events, the latest go first.
+// NextCursor is set if there may be more events, it's the cursor of the next page
+type SecurityEventsResponse struct {
+	Events     []*SecurityEvent
+	NextCursor string `json:"NextCursor,omitempty"`
+}
+
+// SecurityEventsSearch input model for the admin search, the empty fields don't filter
+type SecurityEventsSearch struct {
+	AccountUUID string
+	Types       []string
+	IP          string
+	// Since and Until bound the creation time, Until is exclusive
+	Since  int64
+	Until  int64
+	Cursor string
+	Limit  int
+}
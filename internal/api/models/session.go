@@ -0,0 +1,32 @@
+package models
+
+// The sessions of the signed in devices
+
+// Client the device which makes the request, it's filled by the API layer, not by the user input
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+// Session a device signed in to the account
+type Session struct {
+	UUID        string
+	IP          string
+	DeviceName  string
+	UserAgent   string
+	CreatedAt   int64
+	RefreshedAt int64
+	ExpiresAt   int64
+}
+
+// SessionsResponse the active sessions of the account, the last refreshed go first
+type SessionsResponse struct {
+	Sessions []*Session
+}
+
+// RevokeSessions the input model for signing all the devices out of the account by an admin
+type RevokeSessions struct {
+	UUID  string
+	Actor string
+	Audit *AuditMetadata `json:"-"`
+}
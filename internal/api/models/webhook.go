@@ -0,0 +1,83 @@
+package models
+
+// The webhooks of the partners and their delivery log
+
+// WebhookCreate input model, the empty EventTypes subscribe to all the events
+type WebhookCreate struct {
+	URL        string
+	EventTypes []string
+	Actor      string
+	Audit      *AuditMetadata `json:"-"`
+}
+
+// WebhookUpdate input model, it replaces the URL, the event types and the activity.
+// RotateSecret replaces the secret, the new one is returned once
+type WebhookUpdate struct {
+	UUID         string
+	URL          string
+	EventTypes   []string
+	Active       bool
+	RotateSecret bool
+	Actor        string
+	Audit        *AuditMetadata `json:"-"`
+}
+
+// WebhookDelete input model for the webhook deletion
+type WebhookDelete struct {
+	UUID  string
+	Actor string
+	Audit *AuditMetadata `json:"-"`
+}
+
+// WebhookRedeliver input model for sending a delivery again
+type WebhookRedeliver struct {
+	ID    int64
+	Actor string
+	Audit *AuditMetadata `json:"-"`
+}
+
+// Webhook the secret is returned only by the creation and the rotation
+type Webhook struct {
+	UUID       string
+	URL        string
+	Secret     string `json:"Secret,omitempty"`
+	EventTypes []string
+	Active     bool
+	CreatedAt  int64
+	UpdatedAt  int64
+}
+
+type WebhooksResponse struct {
+	Webhooks []*Webhook
+}
+
+// WebhookDelivery an event sent or to be sent to a webhook, Status is pending, delivered or dead
+type WebhookDelivery struct {
+	ID             int64
+	WebhookUUID    string
+	EventUUID      string
+	EventType      string
+	Payload        string
+	Status         string
+	Attempts       int
+	NextAttemptAt  int64
+	LastStatusCode int
+	LastError      string
+	CreatedAt      int64
+	UpdatedAt      int64
+}
+
+// WebhookDeliveriesSearch input model for the delivery log, the empty fields don't filter
+type WebhookDeliveriesSearch struct {
+	WebhookUUID string
+	Statuses    []string
+	Cursor      string
+	Limit       int
+}
+
+// WebhookDeliveriesResponse a page of the deliveries, the latest go first.
+// NextCursor is set if there may be more deliveries, it's the cursor of the next page
+type WebhookDeliveriesResponse struct {
+	Deliveries []*WebhookDelivery
+	NextCursor string `json:"NextCursor,omitempty"`
+}
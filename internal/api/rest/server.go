@@ -4,10 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 )
 
+const (
+	DefaultDrainTimeout = 15 * time.Second
+)
+
 var (
 	ErrDepServerIsNil        = errors.New("DepServer is nil")
 	ErrDepServerConfigIsNil  = errors.New("DepServer.Config is nil")
@@ -21,17 +26,24 @@ type Config struct {
 	ReadHeaderTimeout time.Duration
 	WriteTimeout      time.Duration
 	IdleTimeout       time.Duration
+	// DrainTimeout is how long the in-flight requests are waited for on the shutdown,
+	// DefaultDrainTimeout is used if it isn't positive
+	DrainTimeout time.Duration
 }
 
 type DependenciesServer struct {
 	Config  *Config
 	Handler http.Handler
+	// Listener replaces the one on the configured address, e.g. in the tests
+	Listener net.Listener
 }
 
 type Server struct {
 	config *Config
 
-	server *http.Server
+	server   *http.Server
+	listener net.Listener
+	drain    time.Duration
 }
 
 func NewServer(d *DependenciesServer) (*Server, error) {
@@ -58,27 +70,48 @@ func NewServer(d *DependenciesServer) (*Server, error) {
 		IdleTimeout:       d.Config.IdleTimeout,
 	}
 
+	drain := d.Config.DrainTimeout
+	if drain <= 0 {
+		drain = DefaultDrainTimeout
+	}
+
 	return &Server{
-		config: d.Config,
-		server: hs,
+		config:   d.Config,
+		server:   hs,
+		listener: d.Listener,
+		drain:    drain,
 	}, nil
 }
 
+// Run serves until ctx is done, then the in-flight requests are drained
 func (s *Server) Run(ctx context.Context) error {
-	errchan := make(chan error)
+	errchan := make(chan error, 1)
 
 	go func() {
+		if s.listener != nil {
+			errchan <- s.server.Serve(s.listener)
+			return
+		}
 		errchan <- s.server.ListenAndServe()
 	}()
 
 	select {
 	case <-ctx.Done():
-		return s.ShutDown(ctx)
+		// ctx is already done, so the drain gets its own deadline
+		drainctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.drain)
+		defer cancel()
+		return s.ShutDown(drainctx)
 	case err := <-errchan:
 		return err
 	}
 }
 
+// ShutDown stops accepting the connections and waits for the in-flight requests until ctx is done,
+// then the remaining connections are closed
 func (s *Server) ShutDown(ctx context.Context) error {
-	return s.server.Shutdown(ctx)
+	err := s.server.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return errors.Join(err, s.server.Close())
+	}
+	return err
 }
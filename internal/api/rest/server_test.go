@@ -0,0 +1,92 @@
+package rest
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestServer_RunDrains(t *testing.T) {
+	testCases := []struct {
+		name         string
+		drain        time.Duration
+		expectedBody string
+		expectedErr  bool
+	}{
+		{
+			name:         "In-flight request is finished",
+			drain:        time.Second,
+			expectedBody: "done",
+		},
+		{
+			name:        "In-flight request is cut off after the drain timeout",
+			drain:       10 * time.Millisecond,
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+
+			started := make(chan struct{})
+			release := make(chan struct{})
+			server, err := NewServer(&DependenciesServer{
+				Config: &Config{DrainTimeout: tc.drain},
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					close(started)
+					select {
+					case <-release:
+					case <-r.Context().Done():
+						return
+					}
+					_, _ = io.WriteString(w, "done")
+				}),
+				Listener: listener,
+			})
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			result := make(chan error)
+			go func() {
+				result <- server.Run(ctx)
+			}()
+
+			type response struct {
+				body string
+				err  error
+			}
+			responses := make(chan response)
+			go func() {
+				r, err := http.Get("http://" + listener.Addr().String())
+				if err != nil {
+					responses <- response{err: err}
+					return
+				}
+				defer r.Body.Close()
+				body, err := io.ReadAll(r.Body)
+				responses <- response{body: string(body), err: err}
+			}()
+
+			<-started
+			cancel()
+			time.AfterFunc(50*time.Millisecond, func() { close(release) })
+
+			got := <-responses
+			if tc.expectedErr {
+				assert.Error(t, got.err)
+				assert.Error(t, <-result)
+				return
+			}
+			assert.NoError(t, got.err)
+			assert.Equal(t, tc.expectedBody, got.body)
+			assert.NoError(t, <-result)
+		})
+	}
+}
@@ -4,12 +4,12 @@ import (
 	"context"
 	"errors"
 	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/rest/v1/middlewares"
 	"github.com/alexsibrin/runbot-auth/internal/api/validators"
 	"github.com/alexsibrin/runbot-auth/internal/logapp"
 	"github.com/alexsibrin/runbot-auth/internal/usecases"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/sirupsen/logrus"
 	"io"
 	"net/http"
 )
@@ -23,7 +23,8 @@ const (
 type IAccountController interface {
 	SignIn(ctx context.Context, model *models.SignIn) (*models.SignInResponse, error)
 	SignUp(ctx context.Context, model *models.SignUp) (*models.SignUpResponse, error)
-	RefreshToken(_ context.Context, token string) (string, error)
+	RefreshToken(_ context.Context, token string, client *models.Client) (string, error)
+	DeleteAccount(ctx context.Context, model *models.DeleteAccount) (*models.DeleteAccountResponse, error)
 }
 
 type DependenciesAccount struct {
@@ -66,6 +67,7 @@ func (h *Account) SignIn(g *gin.Context) {
 		h.handleError(g, logger, err)
 		return
 	}
+	model.Client = clientOf(g)
 
 	reponsemodel, err := h.controller.SignIn(g, &model)
 	if err != nil {
@@ -87,6 +89,7 @@ func (h *Account) SignUp(g *gin.Context) {
 		h.handleError(g, logger, err)
 		return
 	}
+	model.Client = clientOf(g)
 
 	reponsemodel, err := h.controller.SignUp(g, &model)
 	if err != nil {
@@ -106,7 +109,7 @@ func (h *Account) RefreshToken(g *gin.Context) {
 		h.handleError(g, logger, ErrDidntGetRefreshToken)
 		return
 	}
-	newtoken, err := h.controller.RefreshToken(g, token)
+	newtoken, err := h.controller.RefreshToken(g, token, clientOf(g))
 	if err != nil {
 		h.handleError(g, logger, err)
 		return
@@ -115,6 +118,23 @@ func (h *Account) RefreshToken(g *gin.Context) {
 	g.JSON(http.StatusOK, "ok")
 }
 
+// DeleteAccount deletes the account of the access token owner, the refresh token is dropped
+func (h *Account) DeleteAccount(g *gin.Context) {
+	logger := h.logger.WithField(methodKey, "DeleteAccount")
+
+	// the owner is the actor of the deletion
+	accountuuid := middlewares.AccountUUID(g)
+	reponsemodel, err := h.controller.DeleteAccount(g, &models.DeleteAccount{UUID: accountuuid, Actor: accountuuid})
+	if err != nil {
+		h.handleError(g, logger, err)
+		return
+	}
+
+	g.SetCookie(refreshTokenCookieKey, "", -1, "", "", true, true)
+
+	g.JSON(http.StatusAccepted, reponsemodel)
+}
+
 func (h *Account) addTokenToCookie(g *gin.Context, token string) {
 	g.SetCookie(refreshTokenCookieKey, token, 36000, "", "", true, true)
 }
@@ -123,8 +143,8 @@ func (h *Account) getTokenFromCookie(g *gin.Context) (string, error) {
 	return g.Cookie(refreshTokenCookieKey)
 }
 
-func (h *Account) handleError(g *gin.Context, logger logrus.FieldLogger, err error) {
-	logger.Error(err)
+func (h *Account) handleError(g *gin.Context, logger logapp.ILogger, err error) {
+	logger.WithContext(g).Error(err)
 	code := h.getStatusCode(err)
 	msg := h.getErrorMessage(err)
 	g.JSON(code, gin.H{"error": msg})
@@ -146,6 +166,8 @@ func (h *Account) getErrorMessage(err error) string {
 		return defaultInputErr
 	case errors.Is(err, validators.ErrNameFormatIsNotCorrect):
 		return defaultInputErr
+	case errors.Is(err, validators.ErrEmailDomainIsNotAllowed):
+		return "email domain is not allowed, please use another email"
 	default:
 		return err.Error()
 	}
@@ -155,6 +177,12 @@ func (h *Account) getStatusCode(err error) int {
 	switch {
 	case errors.Is(err, validators.ErrEmailIsTooShort):
 		return http.StatusBadRequest
+	case errors.Is(err, validators.ErrEmailIsTooLong):
+		return http.StatusBadRequest
+	case errors.Is(err, validators.ErrEmailFormatIsNotCorrect):
+		return http.StatusBadRequest
+	case errors.Is(err, validators.ErrEmailDomainIsNotAllowed):
+		return http.StatusBadRequest
 	case errors.Is(err, usecases.ErrEmailIsWrong):
 		return http.StatusBadRequest
 	case errors.Is(err, usecases.ErrPasswordIsWrong):
@@ -179,6 +207,20 @@ func (h *Account) getStatusCode(err error) int {
 		return http.StatusBadRequest
 	case errors.Is(err, jwt.ErrHashUnavailable):
 		return http.StatusBadRequest
+	case errors.Is(err, usecases.ErrAccountIsNotActive):
+		return http.StatusUnauthorized
+	case errors.Is(err, usecases.ErrSessionIsNotExist):
+		return http.StatusUnauthorized
+	case errors.Is(err, usecases.ErrSessionIsRevoked):
+		return http.StatusUnauthorized
+	case errors.Is(err, usecases.ErrSessionIsExpired):
+		return http.StatusUnauthorized
+	case errors.Is(err, usecases.ErrPasswordResetRequired):
+		return http.StatusForbidden
+	case errors.Is(err, usecases.ErrAccountIsNotExist):
+		return http.StatusNotFound
+	case errors.Is(err, usecases.ErrAccountIsDeleted):
+		return http.StatusConflict
 	default:
 		return http.StatusInternalServerError
 	}
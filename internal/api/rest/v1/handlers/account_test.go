@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"github.com/alexsibrin/runbot-auth/internal/api/models"
 	resthandlers_test "github.com/alexsibrin/runbot-auth/internal/api/rest/v1/handlers/mocks"
+	"github.com/alexsibrin/runbot-auth/internal/api/rest/v1/middlewares"
 	"github.com/alexsibrin/runbot-auth/internal/api/validators"
 	"github.com/alexsibrin/runbot-auth/internal/usecases"
 	"github.com/gin-gonic/gin"
@@ -247,3 +248,70 @@ func TestSignUp(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedController := resthandlers_test.NewMockIAccountController(ctrl)
+
+	type testCase struct {
+		name         string
+		setupMocks   func()
+		expectedBody string
+		expectedCode int
+	}
+
+	testCases := []testCase{
+		{
+			name: "Valid deletion",
+			setupMocks: func() {
+				mockedController.EXPECT().DeleteAccount(gomock.Any(), &models.DeleteAccount{UUID: "someuuid", Actor: "someuuid"}).Return(&models.DeleteAccountResponse{
+					UUID:      "someuuid",
+					Status:    3,
+					DeletedAt: 100,
+					PurgeAt:   200,
+				}, nil)
+			},
+			expectedBody: `{"UUID":"someuuid","Status":3,"DeletedAt":100,"PurgeAt":200}`,
+			expectedCode: 202,
+		},
+		{
+			name: "Account is already deleted",
+			setupMocks: func() {
+				mockedController.EXPECT().DeleteAccount(gomock.Any(), &models.DeleteAccount{UUID: "someuuid", Actor: "someuuid"}).Return(nil, usecases.ErrAccountIsDeleted)
+			},
+			expectedBody: `{"error":"account is already deleted"}`,
+			expectedCode: 409,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			tc.setupMocks()
+
+			handler, err := NewAccount(&DependenciesAccount{
+				AccountController: mockedController,
+				Logger:            logrus.New(),
+			})
+			assert.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodDelete, "/account", nil)
+			assert.NoError(t, err)
+
+			w := httptest.NewRecorder()
+
+			gctx, _ := gin.CreateTestContext(w)
+
+			gctx.Request = req
+			gctx.Set(middlewares.AccountUUIDKey, "someuuid")
+
+			handler.DeleteAccount(gctx)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+		})
+	}
+}
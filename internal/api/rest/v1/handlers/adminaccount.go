@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+const (
+	adminAccountHandlerKey = "AdminAccount"
+
+	// The query parameters of the account search, StatusQuery may be repeated
+	StatusQuery       = "status"
+	EmailPrefixQuery  = "email_prefix"
+	NameQuery         = "name"
+	CreatedSinceQuery = "created_since"
+	CreatedUntilQuery = "created_until"
+	SortQuery         = "sort"
+	// OrderQuery is asc (the default) or desc
+	OrderQuery = "order"
+)
+
+//go:generate mockgen -destination mocks/resthandlers_adminaccount_mocks.go -package resthandlers_test github.com/alexsibrin/runbot-auth/internal/api/rest/v1/handlers IAdminAccountController
+type IAdminAccountController interface {
+	Search(ctx context.Context, model *models.AccountsSearch) (*models.AccountsResponse, error)
+}
+
+type DependenciesAdminAccount struct {
+	AccountController IAdminAccountController
+	Logger            logapp.ILogger
+}
+
+// AdminAccount is the admin API of the accounts, it's behind the admin token
+type AdminAccount struct {
+	controller IAdminAccountController
+	logger     logapp.ILogger
+}
+
+func NewAdminAccount(dep *DependenciesAdminAccount) (*AdminAccount, error) {
+	if dep == nil {
+		return nil, NewErrUnitIsNil("dep AdminAccount")
+	}
+	if dep.AccountController == nil {
+		return nil, NewErrUnitIsNil("dep AdminAccount controller")
+	}
+	if dep.Logger == nil {
+		return nil, NewErrUnitIsNil("dep AdminAccount logger")
+	}
+
+	return &AdminAccount{
+		controller: dep.AccountController,
+		logger:     dep.Logger.WithField(handlerKey, adminAccountHandlerKey),
+	}, nil
+}
+
+// List returns a page of the accounts matching the query, the cursor is the NextCursor of the previous page
+func (h *AdminAccount) List(g *gin.Context) {
+	logger := h.logger.WithField(methodKey, "List")
+
+	search, err := h.query2AccountsSearch(g)
+	if err != nil {
+		h.handleError(g, logger, err)
+		return
+	}
+
+	reponsemodel, err := h.controller.Search(g, search)
+	if err != nil {
+		h.handleError(g, logger, err)
+		return
+	}
+
+	g.JSON(http.StatusOK, reponsemodel)
+}
+
+func (h *AdminAccount) query2AccountsSearch(g *gin.Context) (*models.AccountsSearch, error) {
+	limit, err := validators.Limit(g.Query(LimitQuery))
+	if err != nil {
+		return nil, err
+	}
+	since, err := queryTime(g, CreatedSinceQuery)
+	if err != nil {
+		return nil, err
+	}
+	until, err := queryTime(g, CreatedUntilQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	search := &models.AccountsSearch{
+		Statuses:     g.QueryArray(StatusQuery),
+		EmailPrefix:  g.Query(EmailPrefixQuery),
+		NameContains: g.Query(NameQuery),
+		CreatedSince: since,
+		CreatedUntil: until,
+		Sort:         g.Query(SortQuery),
+		Cursor:       g.Query(CursorQuery),
+		Limit:        limit,
+	}
+	switch g.Query(OrderQuery) {
+	case "", "asc":
+	case "desc":
+		search.Descending = true
+	default:
+		return nil, validators.ErrAccountSortIsNotValid
+	}
+	return search, nil
+}
+
+func (h *AdminAccount) handleError(g *gin.Context, logger logapp.ILogger, err error) {
+	logger.WithContext(g).Error(err)
+	code := h.getStatusCode(err)
+	msg := err.Error()
+	if code == http.StatusInternalServerError {
+		msg = http.StatusText(code)
+	}
+	g.JSON(code, gin.H{"error": msg})
+}
+
+func (h *AdminAccount) getStatusCode(err error) int {
+	switch {
+	case errors.Is(err, validators.ErrCursorIsNotValid),
+		errors.Is(err, validators.ErrLimitIsNotValid),
+		errors.Is(err, validators.ErrTimeRangeIsNotValid),
+		errors.Is(err, validators.ErrStatusIsNotValid),
+		errors.Is(err, validators.ErrAccountSortIsNotValid):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// queryTime parses a unix time of the query, the empty one is zero
+func queryTime(g *gin.Context, key string) (int64, error) {
+	value := g.Query(key)
+	if value == "" {
+		return 0, nil
+	}
+	t, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, validators.ErrTimeRangeIsNotValid
+	}
+	return t, nil
+}
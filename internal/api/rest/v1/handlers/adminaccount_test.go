@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	resthandlers_test "github.com/alexsibrin/runbot-auth/internal/api/rest/v1/handlers/mocks"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminAccount_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedController := resthandlers_test.NewMockIAdminAccountController(ctrl)
+
+	handler, err := NewAdminAccount(&DependenciesAdminAccount{
+		AccountController: mockedController,
+		Logger:            logrus.New(),
+	})
+	assert.NoError(t, err)
+
+	router := gin.New()
+	router.GET("/admin/accounts", handler.List)
+
+	type testCase struct {
+		name         string
+		path         string
+		setupMocks   func()
+		expectedBody string
+		expectedCode int
+	}
+
+	testCases := []testCase{
+		{
+			name: "List accounts",
+			path: "/admin/accounts?status=active&status=suspended&email_prefix=bob&name=tables&created_since=100&created_until=200&sort=email&order=desc&cursor=abc&limit=1",
+			setupMocks: func() {
+				mockedController.EXPECT().Search(gomock.Any(), &models.AccountsSearch{
+					Statuses:     []string{"active", "suspended"},
+					EmailPrefix:  "bob",
+					NameContains: "tables",
+					CreatedSince: 100,
+					CreatedUntil: 200,
+					Sort:         "email",
+					Descending:   true,
+					Cursor:       "abc",
+					Limit:        1,
+				}).Return(&models.AccountsResponse{
+					Accounts: []*models.AccountSummary{{
+						UUID:      "accountuuid",
+						Email:     "bob@example.com",
+						Name:      "Bobby Tables",
+						Status:    "active",
+						CreatedAt: 150,
+					}},
+					NextCursor: "next",
+				}, nil)
+			},
+			expectedBody: `{"Accounts":[{"UUID":"accountuuid","Email":"bob@example.com","Name":"Bobby Tables","Status":"active","CreatedAt":150}],"NextCursor":"next"}`,
+			expectedCode: 200,
+		},
+		{
+			name: "Last page has no cursor",
+			path: "/admin/accounts",
+			setupMocks: func() {
+				mockedController.EXPECT().Search(gomock.Any(), &models.AccountsSearch{}).Return(&models.AccountsResponse{
+					Accounts: []*models.AccountSummary{},
+				}, nil)
+			},
+			expectedBody: `{"Accounts":[]}`,
+			expectedCode: 200,
+		},
+		{
+			name:         "Order is not valid",
+			path:         "/admin/accounts?order=random",
+			setupMocks:   func() {},
+			expectedBody: `{"error":"` + validators.ErrAccountSortIsNotValid.Error() + `"}`,
+			expectedCode: 400,
+		},
+		{
+			name:         "Creation time is not valid",
+			path:         "/admin/accounts?created_since=yesterday",
+			setupMocks:   func() {},
+			expectedBody: `{"error":"` + validators.ErrTimeRangeIsNotValid.Error() + `"}`,
+			expectedCode: 400,
+		},
+		{
+			name: "Status is not valid",
+			path: "/admin/accounts?status=unknown",
+			setupMocks: func() {
+				mockedController.EXPECT().Search(gomock.Any(), &models.AccountsSearch{Statuses: []string{"unknown"}}).Return(nil, validators.ErrStatusIsNotValid)
+			},
+			expectedBody: `{"error":"` + validators.ErrStatusIsNotValid.Error() + `"}`,
+			expectedCode: 400,
+		},
+		{
+			name: "List accounts fails",
+			path: "/admin/accounts",
+			setupMocks: func() {
+				mockedController.EXPECT().Search(gomock.Any(), &models.AccountsSearch{}).Return(nil, errors.New("connection refused"))
+			},
+			expectedBody: `{"error":"Internal Server Error"}`,
+			expectedCode: 500,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+		})
+	}
+}
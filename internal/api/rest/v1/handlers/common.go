@@ -5,14 +5,25 @@ import (
 	"net/http"
 )
 
+const (
+	notReady = "not ready"
+)
+
+type IReadiness interface {
+	IsReady() bool
+}
+
 type DependenciesCommon struct {
 	Version string
 	Health  string
+	// Readiness makes the health check fail while the app starts or stops, it's always ready if nil
+	Readiness IReadiness
 }
 
 type Common struct {
-	version string
-	health  string
+	version   string
+	health    string
+	readiness IReadiness
 }
 
 func NewCommon(d *DependenciesCommon) (*Common, error) {
@@ -26,8 +37,9 @@ func NewCommon(d *DependenciesCommon) (*Common, error) {
 		return nil, NewErrUnitIsNil("dep Common Health")
 	}
 	return &Common{
-		version: d.Version,
-		health:  d.Health,
+		version:   d.Version,
+		health:    d.Health,
+		readiness: d.Readiness,
 	}, nil
 }
 
@@ -36,5 +48,9 @@ func (h *Common) Version(g *gin.Context) {
 }
 
 func (h *Common) Health(g *gin.Context) {
+	if h.readiness != nil && !h.readiness.IsReady() {
+		g.JSON(http.StatusServiceUnavailable, notReady)
+		return
+	}
 	g.JSON(http.StatusOK, h.health)
 }
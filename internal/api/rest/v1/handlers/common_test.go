@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type readiness bool
+
+func (r readiness) IsReady() bool {
+	return bool(r)
+}
+
+func TestCommon_Health(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name         string
+		readiness    IReadiness
+		expectedBody string
+		expectedCode int
+	}{
+		{
+			name:         "Without readiness",
+			expectedBody: `"ok"`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Ready",
+			readiness:    readiness(true),
+			expectedBody: `"ok"`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Not ready",
+			readiness:    readiness(false),
+			expectedBody: `"not ready"`,
+			expectedCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler, err := NewCommon(&DependenciesCommon{
+				Version:   "v1",
+				Health:    "ok",
+				Readiness: tc.readiness,
+			})
+			require.NoError(t, err)
+
+			router := gin.New()
+			router.GET("/health", handler.Health)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+		})
+	}
+}
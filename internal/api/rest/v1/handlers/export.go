@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/alexsibrin/runbot-auth/internal/api/controllers"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/rest/v1/middlewares"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/alexsibrin/runbot-auth/internal/urlsigner"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+const (
+	exportHandlerKey = "Export"
+
+	// ExportUUIDParam is the path parameter of the export UUID
+	ExportUUIDParam = "uuid"
+
+	exportContentType = "application/zip"
+)
+
+//go:generate mockgen -destination mocks/resthandlers_export_mocks.go -package resthandlers_test github.com/alexsibrin/runbot-auth/internal/api/rest/v1/handlers IExportController
+type IExportController interface {
+	RequestExport(ctx context.Context, accountuuid string) (*models.Export, error)
+	GetExport(ctx context.Context, accountuuid, exportuuid string) (*models.Export, error)
+	Download(ctx context.Context, model *models.ExportDownload) ([]byte, error)
+}
+
+type DependenciesExport struct {
+	ExportController IExportController
+	Logger           logapp.ILogger
+}
+
+type Export struct {
+	controller IExportController
+	logger     logapp.ILogger
+}
+
+func NewExport(dep *DependenciesExport) (*Export, error) {
+	if dep == nil {
+		return nil, NewErrUnitIsNil("dep Export")
+	}
+	if dep.ExportController == nil {
+		return nil, NewErrUnitIsNil("dep Export controller")
+	}
+	if dep.Logger == nil {
+		return nil, NewErrUnitIsNil("dep Export logger")
+	}
+
+	return &Export{
+		controller: dep.ExportController,
+		logger:     dep.Logger.WithField(handlerKey, exportHandlerKey),
+	}, nil
+}
+
+// RequestExport schedules an export of the authenticated account, it's polled with GetExport
+func (h *Export) RequestExport(g *gin.Context) {
+	logger := h.logger.WithField(methodKey, "RequestExport")
+
+	reponsemodel, err := h.controller.RequestExport(g, middlewares.AccountUUID(g))
+	if err != nil {
+		h.handleError(g, logger, err)
+		return
+	}
+
+	g.JSON(http.StatusAccepted, reponsemodel)
+}
+
+func (h *Export) GetExport(g *gin.Context) {
+	logger := h.logger.WithField(methodKey, "GetExport")
+
+	reponsemodel, err := h.controller.GetExport(g, middlewares.AccountUUID(g), g.Param(ExportUUIDParam))
+	if err != nil {
+		h.handleError(g, logger, err)
+		return
+	}
+
+	g.JSON(http.StatusOK, reponsemodel)
+}
+
+// Download is authorized by the signed URL only, so it's available without a token
+func (h *Export) Download(g *gin.Context) {
+	logger := h.logger.WithField(methodKey, "Download")
+
+	model := &models.ExportDownload{
+		UUID:      g.Param(ExportUUIDParam),
+		Expires:   g.Query(controllers.ExportExpiresParam),
+		Signature: g.Query(controllers.ExportSignatureParam),
+	}
+
+	archive, err := h.controller.Download(g, model)
+	if err != nil {
+		h.handleError(g, logger, err)
+		return
+	}
+
+	g.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="runbot-export-%s.zip"`, model.UUID))
+	g.Header("Cache-Control", "no-store")
+	g.Data(http.StatusOK, exportContentType, archive)
+}
+
+func (h *Export) handleError(g *gin.Context, logger logapp.ILogger, err error) {
+	logger.WithContext(g).Error(err)
+	code := h.getStatusCode(err)
+	msg := err.Error()
+	if code == http.StatusInternalServerError {
+		msg = http.StatusText(code)
+	}
+	g.JSON(code, gin.H{"error": msg})
+}
+
+func (h *Export) getStatusCode(err error) int {
+	switch {
+	case errors.Is(err, validators.ErrUUIDIsNotValid):
+		return http.StatusBadRequest
+	case errors.Is(err, urlsigner.ErrExpirationIsNotValid):
+		return http.StatusBadRequest
+	case errors.Is(err, urlsigner.ErrSignatureIsNotValid):
+		return http.StatusForbidden
+	case errors.Is(err, urlsigner.ErrSignatureIsExpired):
+		return http.StatusForbidden
+	case errors.Is(err, usecases.ErrAccountIsNotActive):
+		return http.StatusUnauthorized
+	case errors.Is(err, usecases.ErrAccountIsNotExist):
+		return http.StatusNotFound
+	case errors.Is(err, usecases.ErrExportIsNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecases.ErrExportIsNotReady):
+		return http.StatusConflict
+	case errors.Is(err, usecases.ErrExportIsExpired):
+		return http.StatusGone
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	resthandlers_test "github.com/alexsibrin/runbot-auth/internal/api/rest/v1/handlers/mocks"
+	"github.com/alexsibrin/runbot-auth/internal/api/rest/v1/middlewares"
+	"github.com/alexsibrin/runbot-auth/internal/urlsigner"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestExportRouter(t *testing.T, controller IExportController) *gin.Engine {
+	handler, err := NewExport(&DependenciesExport{
+		ExportController: controller,
+		Logger:           logrus.New(),
+	})
+	assert.NoError(t, err)
+
+	authenticated := func(g *gin.Context) {
+		g.Set(middlewares.AccountUUIDKey, "accountuuid")
+	}
+
+	router := gin.New()
+	router.POST("/account/export", authenticated, handler.RequestExport)
+	router.GET("/account/export/:uuid", authenticated, handler.GetExport)
+	router.GET("/exports/:uuid/download", handler.Download)
+	return router
+}
+
+func TestExport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedController := resthandlers_test.NewMockIExportController(ctrl)
+
+	type testCase struct {
+		name         string
+		method       string
+		path         string
+		setupMocks   func()
+		expectedBody string
+		expectedType string
+		expectedCode int
+	}
+
+	testCases := []testCase{
+		{
+			name:   "Request export",
+			method: http.MethodPost,
+			path:   "/account/export",
+			setupMocks: func() {
+				mockedController.EXPECT().RequestExport(gomock.Any(), "accountuuid").Return(&models.Export{
+					UUID:      "exportuuid",
+					Status:    "pending",
+					CreatedAt: 100,
+				}, nil)
+			},
+			expectedBody: `{"UUID":"exportuuid","Status":"pending","CreatedAt":100}`,
+			expectedCode: 202,
+		},
+		{
+			name:   "Get export of another account",
+			method: http.MethodGet,
+			path:   "/account/export/exportuuid",
+			setupMocks: func() {
+				mockedController.EXPECT().GetExport(gomock.Any(), "accountuuid", "exportuuid").Return(nil, usecases.ErrExportIsNotFound)
+			},
+			expectedBody: `{"error":"export is not found"}`,
+			expectedCode: 404,
+		},
+		{
+			name:   "Download archive",
+			method: http.MethodGet,
+			path:   "/exports/exportuuid/download?expires=300&signature=somesignature",
+			setupMocks: func() {
+				mockedController.EXPECT().Download(gomock.Any(), &models.ExportDownload{
+					UUID:      "exportuuid",
+					Expires:   "300",
+					Signature: "somesignature",
+				}).Return([]byte("archive"), nil)
+			},
+			expectedBody: "archive",
+			expectedType: "application/zip",
+			expectedCode: 200,
+		},
+		{
+			name:   "Download with an expired signature",
+			method: http.MethodGet,
+			path:   "/exports/exportuuid/download?expires=300&signature=somesignature",
+			setupMocks: func() {
+				mockedController.EXPECT().Download(gomock.Any(), gomock.Any()).Return(nil, urlsigner.ErrSignatureIsExpired)
+			},
+			expectedBody: `{"error":"url signature is expired"}`,
+			expectedCode: 403,
+		},
+		{
+			name:   "Download an expired export",
+			method: http.MethodGet,
+			path:   "/exports/exportuuid/download?expires=300&signature=somesignature",
+			setupMocks: func() {
+				mockedController.EXPECT().Download(gomock.Any(), gomock.Any()).Return(nil, usecases.ErrExportIsExpired)
+			},
+			expectedBody: `{"error":"export is expired"}`,
+			expectedCode: 410,
+		},
+	}
+
+	router := newTestExportRouter(t, mockedController)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+			if tc.expectedType != "" {
+				assert.Equal(t, tc.expectedType, w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
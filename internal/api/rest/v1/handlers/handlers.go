@@ -3,6 +3,7 @@ package handlers
 import (
 	"errors"
 	"fmt"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
 	"github.com/gin-gonic/gin"
 )
 
@@ -53,3 +54,11 @@ func NewHandlers(d *HandlersDependencies) (*Handlers, error) {
 		Common: d.Common,
 	}, nil
 }
+
+// clientOf describes the device which sends the request, the client IP respects the trusted proxies of gin
+func clientOf(g *gin.Context) *models.Client {
+	return &models.Client{
+		IP:        g.ClientIP(),
+		UserAgent: g.Request.UserAgent(),
+	}
+}
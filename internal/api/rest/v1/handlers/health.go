@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/health"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+const (
+	healthHandlerKey = "Health"
+)
+
+type IHealthChecker interface {
+	Check(ctx context.Context) *health.Report
+}
+
+type DependenciesHealth struct {
+	Checker IHealthChecker
+	Logger  logapp.ILogger
+}
+
+// Health is the probes of the orchestrator, the liveness doesn't depend on anything,
+// the readiness checks the dependencies of the app
+type Health struct {
+	checker IHealthChecker
+	logger  logapp.ILogger
+}
+
+func NewHealth(d *DependenciesHealth) (*Health, error) {
+	if d == nil {
+		return nil, NewErrUnitIsNil("dep Health")
+	}
+	if d.Checker == nil {
+		return nil, NewErrUnitIsNil("dep Health Checker")
+	}
+	if d.Logger == nil {
+		return nil, NewErrUnitIsNil("dep Health logger")
+	}
+	return &Health{
+		checker: d.Checker,
+		logger:  d.Logger.WithField(handlerKey, healthHandlerKey),
+	}, nil
+}
+
+func (h *Health) Livez(g *gin.Context) {
+	g.JSON(http.StatusOK, &health.Report{Status: health.StatusOk, Checks: []*health.CheckResult{}})
+}
+
+// Readyz answers the name and the status of each check, the errors of the failed ones are only logged
+func (h *Health) Readyz(g *gin.Context) {
+	report := h.checker.Check(g.Request.Context())
+	if !report.IsOk() {
+		logger := h.logger.WithField(methodKey, "Readyz")
+		for _, result := range report.Checks {
+			if result.Status != health.StatusOk {
+				logger.Warnf("%s check failed in %dms: %s", result.Name, result.DurationMs, result.Error)
+			}
+		}
+		g.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	g.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/health"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealth_Livez(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	checker := health.NewChecker(nil)
+	checker.Add("postgres", func(context.Context) error { return errors.New("down") })
+	handler, err := NewHealth(&DependenciesHealth{Checker: checker, Logger: logrus.New()})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.GET("/livez", handler.Livez)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok","checks":[]}`, w.Body.String())
+}
+
+func TestHealth_Readyz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name         string
+		err          error
+		expectedBody string
+		expectedCode int
+		expectedLog  string
+	}{
+		{
+			name:         "Ready",
+			expectedBody: `{"status":"ok","checks":[{"name":"postgres","status":"ok"}]}`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Dependency is down",
+			err:          errors.New("dial tcp db.internal:5432: connection refused"),
+			expectedBody: `{"status":"fail","checks":[{"name":"postgres","status":"fail"}]}`,
+			expectedCode: http.StatusServiceUnavailable,
+			expectedLog:  "dial tcp db.internal:5432: connection refused",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			checker := health.NewChecker(nil)
+			checker.Add("postgres", func(context.Context) error { return tc.err })
+			var log bytes.Buffer
+			logger := logrus.New()
+			logger.SetOutput(&log)
+			handler, err := NewHealth(&DependenciesHealth{Checker: checker, Logger: logger})
+			require.NoError(t, err)
+
+			router := gin.New()
+			router.GET("/readyz", handler.Readyz)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.JSONEq(t, tc.expectedBody, w.Body.String())
+			if tc.expectedLog == "" {
+				assert.Empty(t, log.String())
+			} else {
+				assert.Contains(t, log.String(), tc.expectedLog)
+			}
+		})
+	}
+}
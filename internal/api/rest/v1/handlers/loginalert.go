@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"github.com/alexsibrin/runbot-auth/internal/urlsigner"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+)
+
+const (
+	loginAlertHandlerKey = "LoginAlert"
+
+	// NotMeUUIDParam is the path parameter of the account UUID in the "this wasn't me" link
+	NotMeUUIDParam = "uuid"
+	// PasswordResetUUIDParam is the path parameter of the account UUID in the password reset link
+	PasswordResetUUIDParam = "uuid"
+)
+
+//go:generate mockgen -destination mocks/resthandlers_loginalert_mocks.go -package resthandlers_test github.com/alexsibrin/runbot-auth/internal/api/rest/v1/handlers ILoginAlertController
+type ILoginAlertController interface {
+	CheckNotMe(ctx context.Context, model *models.NotMe) error
+	NotMe(ctx context.Context, model *models.NotMe) (*models.PasswordResetToken, error)
+	RequestPasswordReset(ctx context.Context, model *models.PasswordResetRequest) error
+	CheckPasswordReset(ctx context.Context, model *models.PasswordResetToken) error
+	ResetPassword(ctx context.Context, model *models.PasswordReset) error
+}
+
+type DependenciesLoginAlert struct {
+	LoginAlertController ILoginAlertController
+	Logger               logapp.ILogger
+}
+
+type LoginAlert struct {
+	controller ILoginAlertController
+	logger     logapp.ILogger
+}
+
+func NewLoginAlert(dep *DependenciesLoginAlert) (*LoginAlert, error) {
+	if dep == nil {
+		return nil, NewErrUnitIsNil("dep LoginAlert")
+	}
+	if dep.LoginAlertController == nil {
+		return nil, NewErrUnitIsNil("dep LoginAlert controller")
+	}
+	if dep.Logger == nil {
+		return nil, NewErrUnitIsNil("dep LoginAlert logger")
+	}
+
+	return &LoginAlert{
+		controller: dep.LoginAlertController,
+		logger:     dep.Logger.WithField(handlerKey, loginAlertHandlerKey),
+	}, nil
+}
+
+// ConfirmNotMe is the link of the login alert email opened by GET, it only verifies the link and asks
+// to confirm it by POST, since the mail scanners and the prefetchers open the links too
+func (h *LoginAlert) ConfirmNotMe(g *gin.Context) {
+	logger := h.logger.WithField(methodKey, "ConfirmNotMe")
+
+	model := notMeOf(g)
+	if err := h.controller.CheckNotMe(g, model); err != nil {
+		h.handleError(g, logger, err)
+		return
+	}
+
+	g.Header("Cache-Control", "no-store")
+	g.JSON(http.StatusOK, &models.NotMeConfirmation{
+		UUID:   model.UUID,
+		Method: http.MethodPost,
+	})
+}
+
+// NotMe is the confirmed link of the login alert email, it's authorized by the signature only.
+// The password reset token is returned and emailed to the owner
+func (h *LoginAlert) NotMe(g *gin.Context) {
+	logger := h.logger.WithField(methodKey, "NotMe")
+
+	responsemodel, err := h.controller.NotMe(g, notMeOf(g))
+	if err != nil {
+		h.handleError(g, logger, err)
+		return
+	}
+
+	g.Header("Cache-Control", "no-store")
+	g.JSON(http.StatusOK, responsemodel)
+}
+
+// RequestPasswordReset emails the password reset link, it's accepted whether the account exists or not
+func (h *LoginAlert) RequestPasswordReset(g *gin.Context) {
+	logger := h.logger.WithField(methodKey, "RequestPasswordReset")
+
+	var model models.PasswordResetRequest
+	if err := g.ShouldBindJSON(&model); err != nil {
+		h.handleError(g, logger, err)
+		return
+	}
+
+	if err := h.controller.RequestPasswordReset(g, &model); err != nil {
+		h.handleError(g, logger, err)
+		return
+	}
+
+	g.Status(http.StatusAccepted)
+}
+
+// ConfirmPasswordReset is the emailed password reset link, it only verifies the link and answers the token
+// to post with a new password
+func (h *LoginAlert) ConfirmPasswordReset(g *gin.Context) {
+	logger := h.logger.WithField(methodKey, "ConfirmPasswordReset")
+
+	model := &models.PasswordResetToken{
+		UUID:      g.Param(PasswordResetUUIDParam),
+		Expires:   g.Query(usecases.LinkExpiresParam),
+		Signature: g.Query(usecases.LinkSignatureParam),
+	}
+	if err := h.controller.CheckPasswordReset(g, model); err != nil {
+		h.handleError(g, logger, err)
+		return
+	}
+
+	g.Header("Cache-Control", "no-store")
+	g.JSON(http.StatusOK, model)
+}
+
+// ResetPassword sets a new password by the token returned from NotMe or the emailed link
+func (h *LoginAlert) ResetPassword(g *gin.Context) {
+	logger := h.logger.WithField(methodKey, "ResetPassword")
+
+	var model models.PasswordReset
+	err := g.ShouldBindJSON(&model)
+	if err != nil {
+		h.handleError(g, logger, err)
+		return
+	}
+	model.Client = clientOf(g)
+
+	if err := h.controller.ResetPassword(g, &model); err != nil {
+		h.handleError(g, logger, err)
+		return
+	}
+
+	g.Status(http.StatusNoContent)
+}
+
+// notMeOf reads the signed link, the signature is in the query for both GET and POST
+func notMeOf(g *gin.Context) *models.NotMe {
+	return &models.NotMe{
+		UUID:      g.Param(NotMeUUIDParam),
+		Expires:   g.Query(usecases.LinkExpiresParam),
+		Signature: g.Query(usecases.LinkSignatureParam),
+	}
+}
+
+func (h *LoginAlert) handleError(g *gin.Context, logger logapp.ILogger, err error) {
+	logger.WithContext(g).Error(err)
+	code := h.getStatusCode(err)
+	msg := err.Error()
+	if code == http.StatusInternalServerError {
+		msg = http.StatusText(code)
+	}
+	g.JSON(code, gin.H{"error": msg})
+}
+
+func (h *LoginAlert) getStatusCode(err error) int {
+	switch {
+	case errors.Is(err, io.EOF):
+		return http.StatusBadRequest
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return http.StatusBadRequest
+	case errors.Is(err, validators.ErrUUIDIsNotValid):
+		return http.StatusBadRequest
+	case errors.Is(err, validators.ErrEmailIsTooShort):
+		return http.StatusBadRequest
+	case errors.Is(err, validators.ErrEmailIsTooLong):
+		return http.StatusBadRequest
+	case errors.Is(err, validators.ErrEmailFormatIsNotCorrect):
+		return http.StatusBadRequest
+	case errors.Is(err, validators.ErrPasswordIsTooShort):
+		return http.StatusBadRequest
+	case errors.Is(err, validators.ErrPasswordFormatIsNotCorrect):
+		return http.StatusBadRequest
+	case errors.Is(err, urlsigner.ErrExpirationIsNotValid):
+		return http.StatusBadRequest
+	case errors.Is(err, urlsigner.ErrSignatureIsNotValid):
+		return http.StatusForbidden
+	case errors.Is(err, urlsigner.ErrSignatureIsExpired):
+		return http.StatusForbidden
+	case errors.Is(err, usecases.ErrAccountIsNotExist):
+		return http.StatusNotFound
+	case errors.As(err, &repositories.ErrAccountNotFoundByUUID{}):
+		return http.StatusNotFound
+	case errors.Is(err, usecases.ErrAccountIsDeleted):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
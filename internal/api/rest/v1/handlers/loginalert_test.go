@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	resthandlers_test "github.com/alexsibrin/runbot-auth/internal/api/rest/v1/handlers/mocks"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"github.com/alexsibrin/runbot-auth/internal/urlsigner"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestLoginAlertRouter(t *testing.T, controller ILoginAlertController) *gin.Engine {
+	handler, err := NewLoginAlert(&DependenciesLoginAlert{
+		LoginAlertController: controller,
+		Logger:               logrus.New(),
+	})
+	assert.NoError(t, err)
+
+	router := gin.New()
+	router.GET("/account/not-me/:uuid", handler.ConfirmNotMe)
+	router.POST("/account/not-me/:uuid", handler.NotMe)
+	router.POST("/password-reset", handler.ResetPassword)
+	router.POST("/password-reset/request", handler.RequestPasswordReset)
+	router.GET("/password-reset/:uuid", handler.ConfirmPasswordReset)
+	return router
+}
+
+func TestLoginAlert(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedController := resthandlers_test.NewMockILoginAlertController(ctrl)
+
+	type testCase struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		setupMocks   func()
+		expectedBody string
+		expectedCode int
+	}
+
+	testCases := []testCase{
+		{
+			name:   "Not me link is opened",
+			method: http.MethodGet,
+			path:   "/account/not-me/accountuuid?expires=300&signature=somesignature",
+			setupMocks: func() {
+				mockedController.EXPECT().CheckNotMe(gomock.Any(), &models.NotMe{
+					UUID:      "accountuuid",
+					Expires:   "300",
+					Signature: "somesignature",
+				}).Return(nil)
+			},
+			expectedBody: `{"UUID":"accountuuid","Method":"POST"}`,
+			expectedCode: 200,
+		},
+		{
+			name:   "Not me link is used",
+			method: http.MethodGet,
+			path:   "/account/not-me/accountuuid?expires=300&signature=somesignature",
+			setupMocks: func() {
+				mockedController.EXPECT().CheckNotMe(gomock.Any(), gomock.Any()).Return(urlsigner.ErrSignatureIsNotValid)
+			},
+			expectedBody: `{"error":"url signature is not valid"}`,
+			expectedCode: 403,
+		},
+		{
+			name:   "Not me",
+			method: http.MethodPost,
+			path:   "/account/not-me/accountuuid?expires=300&signature=somesignature",
+			setupMocks: func() {
+				mockedController.EXPECT().NotMe(gomock.Any(), &models.NotMe{
+					UUID:      "accountuuid",
+					Expires:   "300",
+					Signature: "somesignature",
+				}).Return(&models.PasswordResetToken{
+					UUID:      "accountuuid",
+					Expires:   "400",
+					Signature: "resetsignature",
+				}, nil)
+			},
+			expectedBody: `{"UUID":"accountuuid","Expires":"400","Signature":"resetsignature"}`,
+			expectedCode: 200,
+		},
+		{
+			name:   "Not me with an expired link",
+			method: http.MethodPost,
+			path:   "/account/not-me/accountuuid?expires=300&signature=somesignature",
+			setupMocks: func() {
+				mockedController.EXPECT().NotMe(gomock.Any(), gomock.Any()).Return(nil, urlsigner.ErrSignatureIsExpired)
+			},
+			expectedBody: `{"error":"url signature is expired"}`,
+			expectedCode: 403,
+		},
+		{
+			name:   "Not me for an unknown account",
+			method: http.MethodPost,
+			path:   "/account/not-me/accountuuid?expires=300&signature=somesignature",
+			setupMocks: func() {
+				mockedController.EXPECT().NotMe(gomock.Any(), gomock.Any()).Return(nil, repositories.NewErrAccountNotFoundByUUID("accountuuid"))
+			},
+			expectedBody: `{"error":"` + repositories.NewErrAccountNotFoundByUUID("accountuuid").Error() + `"}`,
+			expectedCode: 404,
+		},
+		{
+			name:   "Request a password reset",
+			method: http.MethodPost,
+			path:   "/password-reset/request",
+			body:   `{"Email":"bob@example.com"}`,
+			setupMocks: func() {
+				mockedController.EXPECT().RequestPasswordReset(gomock.Any(), &models.PasswordResetRequest{Email: "bob@example.com"}).Return(nil)
+			},
+			expectedCode: 202,
+		},
+		{
+			name:   "Request a password reset with a wrong email",
+			method: http.MethodPost,
+			path:   "/password-reset/request",
+			body:   `{"Email":"bob"}`,
+			setupMocks: func() {
+				mockedController.EXPECT().RequestPasswordReset(gomock.Any(), gomock.Any()).Return(validators.ErrEmailIsTooShort)
+			},
+			expectedBody: `{"error":"email is too short"}`,
+			expectedCode: 400,
+		},
+		{
+			name:   "Password reset link is opened",
+			method: http.MethodGet,
+			path:   "/password-reset/accountuuid?expires=400&signature=resetsignature",
+			setupMocks: func() {
+				mockedController.EXPECT().CheckPasswordReset(gomock.Any(), &models.PasswordResetToken{
+					UUID:      "accountuuid",
+					Expires:   "400",
+					Signature: "resetsignature",
+				}).Return(nil)
+			},
+			expectedBody: `{"UUID":"accountuuid","Expires":"400","Signature":"resetsignature"}`,
+			expectedCode: 200,
+		},
+		{
+			name:   "Password reset link is used",
+			method: http.MethodGet,
+			path:   "/password-reset/accountuuid?expires=400&signature=resetsignature",
+			setupMocks: func() {
+				mockedController.EXPECT().CheckPasswordReset(gomock.Any(), gomock.Any()).Return(urlsigner.ErrSignatureIsNotValid)
+			},
+			expectedBody: `{"error":"url signature is not valid"}`,
+			expectedCode: 403,
+		},
+		{
+			name:   "Reset password",
+			method: http.MethodPost,
+			path:   "/password-reset",
+			body:   `{"UUID":"accountuuid","Expires":"400","Signature":"resetsignature","Password":"NewPassword123"}`,
+			setupMocks: func() {
+				mockedController.EXPECT().ResetPassword(gomock.Any(), &models.PasswordReset{
+					UUID:      "accountuuid",
+					Expires:   "400",
+					Signature: "resetsignature",
+					Password:  "NewPassword123",
+					Client:    &models.Client{IP: "192.0.2.1"},
+				}).Return(nil)
+			},
+			expectedCode: 204,
+		},
+		{
+			name:   "Reset password with a used token",
+			method: http.MethodPost,
+			path:   "/password-reset",
+			body:   `{"UUID":"accountuuid","Expires":"400","Signature":"resetsignature","Password":"NewPassword123"}`,
+			setupMocks: func() {
+				mockedController.EXPECT().ResetPassword(gomock.Any(), gomock.Any()).Return(urlsigner.ErrSignatureIsNotValid)
+			},
+			expectedBody: `{"error":"url signature is not valid"}`,
+			expectedCode: 403,
+		},
+		{
+			name:   "Reset password with a weak password",
+			method: http.MethodPost,
+			path:   "/password-reset",
+			body:   `{"UUID":"accountuuid","Password":"short"}`,
+			setupMocks: func() {
+				mockedController.EXPECT().ResetPassword(gomock.Any(), gomock.Any()).Return(validators.ErrPasswordIsTooShort)
+			},
+			expectedBody: `{"error":"password is too short"}`,
+			expectedCode: 400,
+		},
+		{
+			name:         "Reset password without a body",
+			method:       http.MethodPost,
+			path:         "/password-reset",
+			setupMocks:   func() {},
+			expectedBody: `{"error":"EOF"}`,
+			expectedCode: 400,
+		},
+	}
+
+	router := newTestLoginAlertRouter(t, mockedController)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/api/rest/v1/handlers (interfaces: IAdminAccountController)
+//
+// Generated by this command:
+//
+//	mockgen -destination mocks/resthandlers_adminaccount_mocks.go -package resthandlers_test github.com/alexsibrin/runbot-auth/internal/api/rest/v1/handlers IAdminAccountController
+//
+
+// Package resthandlers_test is a generated GoMock package.
+package resthandlers_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/alexsibrin/runbot-auth/internal/api/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIAdminAccountController is a mock of IAdminAccountController interface.
+type MockIAdminAccountController struct {
+	ctrl     *gomock.Controller
+	recorder *MockIAdminAccountControllerMockRecorder
+}
+
+// MockIAdminAccountControllerMockRecorder is the mock recorder for MockIAdminAccountController.
+type MockIAdminAccountControllerMockRecorder struct {
+	mock *MockIAdminAccountController
+}
+
+// NewMockIAdminAccountController creates a new mock instance.
+func NewMockIAdminAccountController(ctrl *gomock.Controller) *MockIAdminAccountController {
+	mock := &MockIAdminAccountController{ctrl: ctrl}
+	mock.recorder = &MockIAdminAccountControllerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIAdminAccountController) EXPECT() *MockIAdminAccountControllerMockRecorder {
+	return m.recorder
+}
+
+// Search mocks base method.
+func (m *MockIAdminAccountController) Search(arg0 context.Context, arg1 *models.AccountsSearch) (*models.AccountsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", arg0, arg1)
+	ret0, _ := ret[0].(*models.AccountsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockIAdminAccountControllerMockRecorder) Search(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockIAdminAccountController)(nil).Search), arg0, arg1)
+}
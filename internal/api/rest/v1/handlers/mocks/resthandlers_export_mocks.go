@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/api/rest/v1/handlers (interfaces: IExportController)
+//
+// Generated by this command:
+//
+//	mockgen -destination mocks/resthandlers_export_mocks.go -package resthandlers_test github.com/alexsibrin/runbot-auth/internal/api/rest/v1/handlers IExportController
+//
+
+// Package resthandlers_test is a generated GoMock package.
+package resthandlers_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/alexsibrin/runbot-auth/internal/api/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIExportController is a mock of IExportController interface.
+type MockIExportController struct {
+	ctrl     *gomock.Controller
+	recorder *MockIExportControllerMockRecorder
+}
+
+// MockIExportControllerMockRecorder is the mock recorder for MockIExportController.
+type MockIExportControllerMockRecorder struct {
+	mock *MockIExportController
+}
+
+// NewMockIExportController creates a new mock instance.
+func NewMockIExportController(ctrl *gomock.Controller) *MockIExportController {
+	mock := &MockIExportController{ctrl: ctrl}
+	mock.recorder = &MockIExportControllerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIExportController) EXPECT() *MockIExportControllerMockRecorder {
+	return m.recorder
+}
+
+// Download mocks base method.
+func (m *MockIExportController) Download(arg0 context.Context, arg1 *models.ExportDownload) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Download", arg0, arg1)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Download indicates an expected call of Download.
+func (mr *MockIExportControllerMockRecorder) Download(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Download", reflect.TypeOf((*MockIExportController)(nil).Download), arg0, arg1)
+}
+
+// GetExport mocks base method.
+func (m *MockIExportController) GetExport(arg0 context.Context, arg1, arg2 string) (*models.Export, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExport", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Export)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExport indicates an expected call of GetExport.
+func (mr *MockIExportControllerMockRecorder) GetExport(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExport", reflect.TypeOf((*MockIExportController)(nil).GetExport), arg0, arg1, arg2)
+}
+
+// RequestExport mocks base method.
+func (m *MockIExportController) RequestExport(arg0 context.Context, arg1 string) (*models.Export, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestExport", arg0, arg1)
+	ret0, _ := ret[0].(*models.Export)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestExport indicates an expected call of RequestExport.
+func (mr *MockIExportControllerMockRecorder) RequestExport(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestExport", reflect.TypeOf((*MockIExportController)(nil).RequestExport), arg0, arg1)
+}
@@ -0,0 +1,112 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/api/rest/v1/handlers (interfaces: ILoginAlertController)
+//
+// Generated by this command:
+//
+//	mockgen -destination mocks/resthandlers_loginalert_mocks.go -package resthandlers_test github.com/alexsibrin/runbot-auth/internal/api/rest/v1/handlers ILoginAlertController
+//
+
+// Package resthandlers_test is a generated GoMock package.
+package resthandlers_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/alexsibrin/runbot-auth/internal/api/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockILoginAlertController is a mock of ILoginAlertController interface.
+type MockILoginAlertController struct {
+	ctrl     *gomock.Controller
+	recorder *MockILoginAlertControllerMockRecorder
+}
+
+// MockILoginAlertControllerMockRecorder is the mock recorder for MockILoginAlertController.
+type MockILoginAlertControllerMockRecorder struct {
+	mock *MockILoginAlertController
+}
+
+// NewMockILoginAlertController creates a new mock instance.
+func NewMockILoginAlertController(ctrl *gomock.Controller) *MockILoginAlertController {
+	mock := &MockILoginAlertController{ctrl: ctrl}
+	mock.recorder = &MockILoginAlertControllerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockILoginAlertController) EXPECT() *MockILoginAlertControllerMockRecorder {
+	return m.recorder
+}
+
+// CheckNotMe mocks base method.
+func (m *MockILoginAlertController) CheckNotMe(arg0 context.Context, arg1 *models.NotMe) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckNotMe", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckNotMe indicates an expected call of CheckNotMe.
+func (mr *MockILoginAlertControllerMockRecorder) CheckNotMe(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckNotMe", reflect.TypeOf((*MockILoginAlertController)(nil).CheckNotMe), arg0, arg1)
+}
+
+// CheckPasswordReset mocks base method.
+func (m *MockILoginAlertController) CheckPasswordReset(arg0 context.Context, arg1 *models.PasswordResetToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckPasswordReset", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckPasswordReset indicates an expected call of CheckPasswordReset.
+func (mr *MockILoginAlertControllerMockRecorder) CheckPasswordReset(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckPasswordReset", reflect.TypeOf((*MockILoginAlertController)(nil).CheckPasswordReset), arg0, arg1)
+}
+
+// NotMe mocks base method.
+func (m *MockILoginAlertController) NotMe(arg0 context.Context, arg1 *models.NotMe) (*models.PasswordResetToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotMe", arg0, arg1)
+	ret0, _ := ret[0].(*models.PasswordResetToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NotMe indicates an expected call of NotMe.
+func (mr *MockILoginAlertControllerMockRecorder) NotMe(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotMe", reflect.TypeOf((*MockILoginAlertController)(nil).NotMe), arg0, arg1)
+}
+
+// RequestPasswordReset mocks base method.
+func (m *MockILoginAlertController) RequestPasswordReset(arg0 context.Context, arg1 *models.PasswordResetRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestPasswordReset", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestPasswordReset indicates an expected call of RequestPasswordReset.
+func (mr *MockILoginAlertControllerMockRecorder) RequestPasswordReset(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestPasswordReset", reflect.TypeOf((*MockILoginAlertController)(nil).RequestPasswordReset), arg0, arg1)
+}
+
+// ResetPassword mocks base method.
+func (m *MockILoginAlertController) ResetPassword(arg0 context.Context, arg1 *models.PasswordReset) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockILoginAlertControllerMockRecorder) ResetPassword(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockILoginAlertController)(nil).ResetPassword), arg0, arg1)
+}
@@ -40,49 +40,34 @@ func (m *MockIAccountController) EXPECT() *MockIAccountControllerMockRecorder {
 	return m.recorder
 }
 
-// GetOneByEmail mocks base method.
-func (m *MockIAccountController) GetOneByEmail(arg0 context.Context, arg1 string) (*models.AccountGetModel, error) {
+// DeleteAccount mocks base method.
+func (m *MockIAccountController) DeleteAccount(arg0 context.Context, arg1 *models.DeleteAccount) (*models.DeleteAccountResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOneByEmail", arg0, arg1)
-	ret0, _ := ret[0].(*models.AccountGetModel)
+	ret := m.ctrl.Call(m, "DeleteAccount", arg0, arg1)
+	ret0, _ := ret[0].(*models.DeleteAccountResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetOneByEmail indicates an expected call of GetOneByEmail.
-func (mr *MockIAccountControllerMockRecorder) GetOneByEmail(arg0, arg1 any) *gomock.Call {
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockIAccountControllerMockRecorder) DeleteAccount(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOneByEmail", reflect.TypeOf((*MockIAccountController)(nil).GetOneByEmail), arg0, arg1)
-}
-
-// GetOneByUUID mocks base method.
-func (m *MockIAccountController) GetOneByUUID(arg0 context.Context, arg1 string) (*models.AccountGetModel, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOneByUUID", arg0, arg1)
-	ret0, _ := ret[0].(*models.AccountGetModel)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GetOneByUUID indicates an expected call of GetOneByUUID.
-func (mr *MockIAccountControllerMockRecorder) GetOneByUUID(arg0, arg1 any) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOneByUUID", reflect.TypeOf((*MockIAccountController)(nil).GetOneByUUID), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockIAccountController)(nil).DeleteAccount), arg0, arg1)
 }
 
 // RefreshToken mocks base method.
-func (m *MockIAccountController) RefreshToken(arg0 context.Context, arg1 string) (string, error) {
+func (m *MockIAccountController) RefreshToken(arg0 context.Context, arg1 string, arg2 *models.Client) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RefreshToken", arg0, arg1)
+	ret := m.ctrl.Call(m, "RefreshToken", arg0, arg1, arg2)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // RefreshToken indicates an expected call of RefreshToken.
-func (mr *MockIAccountControllerMockRecorder) RefreshToken(arg0, arg1 any) *gomock.Call {
+func (mr *MockIAccountControllerMockRecorder) RefreshToken(arg0, arg1, arg2 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockIAccountController)(nil).RefreshToken), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockIAccountController)(nil).RefreshToken), arg0, arg1, arg2)
 }
 
 // SignIn mocks base method.
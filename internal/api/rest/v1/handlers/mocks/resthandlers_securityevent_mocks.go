@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/api/rest/v1/handlers (interfaces: ISecurityEventController)
+//
+// Generated by this command:
+//
+//	mockgen -destination mocks/resthandlers_securityevent_mocks.go -package resthandlers_test github.com/alexsibrin/runbot-auth/internal/api/rest/v1/handlers ISecurityEventController
+//
+
+// Package resthandlers_test is a generated GoMock package.
+package resthandlers_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/alexsibrin/runbot-auth/internal/api/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockISecurityEventController is a mock of ISecurityEventController interface.
+type MockISecurityEventController struct {
+	ctrl     *gomock.Controller
+	recorder *MockISecurityEventControllerMockRecorder
+}
+
+// MockISecurityEventControllerMockRecorder is the mock recorder for MockISecurityEventController.
+type MockISecurityEventControllerMockRecorder struct {
+	mock *MockISecurityEventController
+}
+
+// NewMockISecurityEventController creates a new mock instance.
+func NewMockISecurityEventController(ctrl *gomock.Controller) *MockISecurityEventController {
+	mock := &MockISecurityEventController{ctrl: ctrl}
+	mock.recorder = &MockISecurityEventControllerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockISecurityEventController) EXPECT() *MockISecurityEventControllerMockRecorder {
+	return m.recorder
+}
+
+// GetByAccount mocks base method.
+func (m *MockISecurityEventController) GetByAccount(arg0 context.Context, arg1, arg2 string, arg3 int) (*models.SecurityEventsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByAccount", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.SecurityEventsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByAccount indicates an expected call of GetByAccount.
+func (mr *MockISecurityEventControllerMockRecorder) GetByAccount(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByAccount", reflect.TypeOf((*MockISecurityEventController)(nil).GetByAccount), arg0, arg1, arg2, arg3)
+}
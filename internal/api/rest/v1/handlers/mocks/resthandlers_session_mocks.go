@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/api/rest/v1/handlers (interfaces: ISessionController)
+//
+// Generated by this command:
+//
+//	mockgen -destination mocks/resthandlers_session_mocks.go -package resthandlers_test github.com/alexsibrin/runbot-auth/internal/api/rest/v1/handlers ISessionController
+//
+
+// Package resthandlers_test is a generated GoMock package.
+package resthandlers_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/alexsibrin/runbot-auth/internal/api/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockISessionController is a mock of ISessionController interface.
+type MockISessionController struct {
+	ctrl     *gomock.Controller
+	recorder *MockISessionControllerMockRecorder
+}
+
+// MockISessionControllerMockRecorder is the mock recorder for MockISessionController.
+type MockISessionControllerMockRecorder struct {
+	mock *MockISessionController
+}
+
+// NewMockISessionController creates a new mock instance.
+func NewMockISessionController(ctrl *gomock.Controller) *MockISessionController {
+	mock := &MockISessionController{ctrl: ctrl}
+	mock.recorder = &MockISessionControllerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockISessionController) EXPECT() *MockISessionControllerMockRecorder {
+	return m.recorder
+}
+
+// GetActive mocks base method.
+func (m *MockISessionController) GetActive(arg0 context.Context, arg1 string) (*models.SessionsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActive", arg0, arg1)
+	ret0, _ := ret[0].(*models.SessionsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActive indicates an expected call of GetActive.
+func (mr *MockISessionControllerMockRecorder) GetActive(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActive", reflect.TypeOf((*MockISessionController)(nil).GetActive), arg0, arg1)
+}
+
+// Revoke mocks base method.
+func (m *MockISessionController) Revoke(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockISessionControllerMockRecorder) Revoke(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockISessionController)(nil).Revoke), arg0, arg1, arg2)
+}
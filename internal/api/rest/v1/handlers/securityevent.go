@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/rest/v1/middlewares"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+const (
+	securityEventHandlerKey = "SecurityEvent"
+
+	// CursorQuery and LimitQuery are the query parameters of a paginated listing
+	CursorQuery = "cursor"
+	LimitQuery  = "limit"
+)
+
+//go:generate mockgen -destination mocks/resthandlers_securityevent_mocks.go -package resthandlers_test github.com/alexsibrin/runbot-auth/internal/api/rest/v1/handlers ISecurityEventController
+type ISecurityEventController interface {
+	GetByAccount(ctx context.Context, accountuuid, cursor string, limit int) (*models.SecurityEventsResponse, error)
+}
+
+type DependenciesSecurityEvent struct {
+	SecurityEventController ISecurityEventController
+	Logger                  logapp.ILogger
+}
+
+type SecurityEvent struct {
+	controller ISecurityEventController
+	logger     logapp.ILogger
+}
+
+func NewSecurityEvent(dep *DependenciesSecurityEvent) (*SecurityEvent, error) {
+	if dep == nil {
+		return nil, NewErrUnitIsNil("dep SecurityEvent")
+	}
+	if dep.SecurityEventController == nil {
+		return nil, NewErrUnitIsNil("dep SecurityEvent controller")
+	}
+	if dep.Logger == nil {
+		return nil, NewErrUnitIsNil("dep SecurityEvent logger")
+	}
+
+	return &SecurityEvent{
+		controller: dep.SecurityEventController,
+		logger:     dep.Logger.WithField(handlerKey, securityEventHandlerKey),
+	}, nil
+}
+
+// GetByAccount lists the login history of the authenticated account page by page
+func (h *SecurityEvent) GetByAccount(g *gin.Context) {
+	logger := h.logger.WithField(methodKey, "GetByAccount")
+
+	limit, err := validators.Limit(g.Query(LimitQuery))
+	if err != nil {
+		h.handleError(g, logger, err)
+		return
+	}
+
+	reponsemodel, err := h.controller.GetByAccount(g, middlewares.AccountUUID(g), g.Query(CursorQuery), limit)
+	if err != nil {
+		h.handleError(g, logger, err)
+		return
+	}
+
+	g.JSON(http.StatusOK, reponsemodel)
+}
+
+func (h *SecurityEvent) handleError(g *gin.Context, logger logapp.ILogger, err error) {
+	logger.WithContext(g).Error(err)
+	code := h.getStatusCode(err)
+	msg := err.Error()
+	if code == http.StatusInternalServerError {
+		msg = http.StatusText(code)
+	}
+	g.JSON(code, gin.H{"error": msg})
+}
+
+func (h *SecurityEvent) getStatusCode(err error) int {
+	switch {
+	case errors.Is(err, validators.ErrCursorIsNotValid):
+		return http.StatusBadRequest
+	case errors.Is(err, validators.ErrLimitIsNotValid):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	resthandlers_test "github.com/alexsibrin/runbot-auth/internal/api/rest/v1/handlers/mocks"
+	"github.com/alexsibrin/runbot-auth/internal/api/rest/v1/middlewares"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestSecurityEventRouter(t *testing.T, controller ISecurityEventController) *gin.Engine {
+	handler, err := NewSecurityEvent(&DependenciesSecurityEvent{
+		SecurityEventController: controller,
+		Logger:                  logrus.New(),
+	})
+	assert.NoError(t, err)
+
+	authenticated := func(g *gin.Context) {
+		g.Set(middlewares.AccountUUIDKey, "accountuuid")
+	}
+
+	router := gin.New()
+	router.GET("/security-events", authenticated, handler.GetByAccount)
+	return router
+}
+
+func TestSecurityEvent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedController := resthandlers_test.NewMockISecurityEventController(ctrl)
+
+	type testCase struct {
+		name         string
+		path         string
+		setupMocks   func()
+		expectedBody string
+		expectedCode int
+	}
+
+	testCases := []testCase{
+		{
+			name: "List events",
+			path: "/security-events?cursor=10&limit=1",
+			setupMocks: func() {
+				mockedController.EXPECT().GetByAccount(gomock.Any(), "accountuuid", "10", 1).Return(&models.SecurityEventsResponse{
+					Events: []*models.SecurityEvent{{
+						ID:          9,
+						AccountUUID: "accountuuid",
+						Type:        "signin",
+						IP:          "10.0.0.1",
+						UserAgent:   "someagent",
+						Details:     "Chrome on Windows",
+						CreatedAt:   100,
+					}},
+					NextCursor: "9",
+				}, nil)
+			},
+			expectedBody: `{"Events":[{"ID":9,"AccountUUID":"accountuuid","Type":"signin","IP":"10.0.0.1","UserAgent":"someagent","Details":"Chrome on Windows","CreatedAt":100}],"NextCursor":"9"}`,
+			expectedCode: 200,
+		},
+		{
+			name: "Last page has no cursor",
+			path: "/security-events",
+			setupMocks: func() {
+				mockedController.EXPECT().GetByAccount(gomock.Any(), "accountuuid", "", 0).Return(&models.SecurityEventsResponse{
+					Events: []*models.SecurityEvent{},
+				}, nil)
+			},
+			expectedBody: `{"Events":[]}`,
+			expectedCode: 200,
+		},
+		{
+			name:         "Limit is not valid",
+			path:         "/security-events?limit=ten",
+			setupMocks:   func() {},
+			expectedBody: `{"error":"` + validators.ErrLimitIsNotValid.Error() + `"}`,
+			expectedCode: 400,
+		},
+		{
+			name: "Cursor is not valid",
+			path: "/security-events?cursor=abc",
+			setupMocks: func() {
+				mockedController.EXPECT().GetByAccount(gomock.Any(), "accountuuid", "abc", 0).Return(nil, validators.ErrCursorIsNotValid)
+			},
+			expectedBody: `{"error":"` + validators.ErrCursorIsNotValid.Error() + `"}`,
+			expectedCode: 400,
+		},
+		{
+			name: "List events fails",
+			path: "/security-events",
+			setupMocks: func() {
+				mockedController.EXPECT().GetByAccount(gomock.Any(), "accountuuid", "", 0).Return(nil, errors.New("connection refused"))
+			},
+			expectedBody: `{"error":"Internal Server Error"}`,
+			expectedCode: 500,
+		},
+	}
+
+	router := newTestSecurityEventRouter(t, mockedController)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/rest/v1/middlewares"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+const (
+	sessionHandlerKey = "Session"
+
+	// SessionUUIDParam is the path parameter of the session UUID
+	SessionUUIDParam = "uuid"
+)
+
+//go:generate mockgen -destination mocks/resthandlers_session_mocks.go -package resthandlers_test github.com/alexsibrin/runbot-auth/internal/api/rest/v1/handlers ISessionController
+type ISessionController interface {
+	GetActive(ctx context.Context, accountuuid string) (*models.SessionsResponse, error)
+	Revoke(ctx context.Context, accountuuid, sessionuuid string) error
+}
+
+type DependenciesSession struct {
+	SessionController ISessionController
+	Logger            logapp.ILogger
+}
+
+type Session struct {
+	controller ISessionController
+	logger     logapp.ILogger
+}
+
+func NewSession(dep *DependenciesSession) (*Session, error) {
+	if dep == nil {
+		return nil, NewErrUnitIsNil("dep Session")
+	}
+	if dep.SessionController == nil {
+		return nil, NewErrUnitIsNil("dep Session controller")
+	}
+	if dep.Logger == nil {
+		return nil, NewErrUnitIsNil("dep Session logger")
+	}
+
+	return &Session{
+		controller: dep.SessionController,
+		logger:     dep.Logger.WithField(handlerKey, sessionHandlerKey),
+	}, nil
+}
+
+// GetActive lists the devices signed in to the authenticated account
+func (h *Session) GetActive(g *gin.Context) {
+	logger := h.logger.WithField(methodKey, "GetActive")
+
+	reponsemodel, err := h.controller.GetActive(g, middlewares.AccountUUID(g))
+	if err != nil {
+		h.handleError(g, logger, err)
+		return
+	}
+
+	g.JSON(http.StatusOK, reponsemodel)
+}
+
+// Revoke signs the device out, the issued access token stays valid until it expires
+func (h *Session) Revoke(g *gin.Context) {
+	logger := h.logger.WithField(methodKey, "Revoke")
+
+	err := h.controller.Revoke(g, middlewares.AccountUUID(g), g.Param(SessionUUIDParam))
+	if err != nil {
+		h.handleError(g, logger, err)
+		return
+	}
+
+	g.Status(http.StatusNoContent)
+}
+
+func (h *Session) handleError(g *gin.Context, logger logapp.ILogger, err error) {
+	logger.WithContext(g).Error(err)
+	code := h.getStatusCode(err)
+	msg := err.Error()
+	if code == http.StatusInternalServerError {
+		msg = http.StatusText(code)
+	}
+	g.JSON(code, gin.H{"error": msg})
+}
+
+func (h *Session) getStatusCode(err error) int {
+	switch {
+	case errors.Is(err, validators.ErrUUIDIsNotValid):
+		return http.StatusBadRequest
+	case errors.Is(err, usecases.ErrSessionIsNotExist):
+		return http.StatusNotFound
+	case errors.Is(err, usecases.ErrSessionIsRevoked):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
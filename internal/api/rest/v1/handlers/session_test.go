@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	resthandlers_test "github.com/alexsibrin/runbot-auth/internal/api/rest/v1/handlers/mocks"
+	"github.com/alexsibrin/runbot-auth/internal/api/rest/v1/middlewares"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestSessionRouter(t *testing.T, controller ISessionController) *gin.Engine {
+	handler, err := NewSession(&DependenciesSession{
+		SessionController: controller,
+		Logger:            logrus.New(),
+	})
+	assert.NoError(t, err)
+
+	authenticated := func(g *gin.Context) {
+		g.Set(middlewares.AccountUUIDKey, "accountuuid")
+	}
+
+	router := gin.New()
+	router.GET("/sessions", authenticated, handler.GetActive)
+	router.DELETE("/sessions/:uuid", authenticated, handler.Revoke)
+	return router
+}
+
+func TestSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedController := resthandlers_test.NewMockISessionController(ctrl)
+
+	type testCase struct {
+		name         string
+		method       string
+		path         string
+		setupMocks   func()
+		expectedBody string
+		expectedCode int
+	}
+
+	testCases := []testCase{
+		{
+			name:   "List sessions",
+			method: http.MethodGet,
+			path:   "/sessions",
+			setupMocks: func() {
+				mockedController.EXPECT().GetActive(gomock.Any(), "accountuuid").Return(&models.SessionsResponse{
+					Sessions: []*models.Session{{
+						UUID:        "sessionuuid",
+						IP:          "10.0.0.1",
+						DeviceName:  "Chrome on Windows",
+						UserAgent:   "someagent",
+						CreatedAt:   100,
+						RefreshedAt: 200,
+						ExpiresAt:   300,
+					}},
+				}, nil)
+			},
+			expectedBody: `{"Sessions":[{"UUID":"sessionuuid","IP":"10.0.0.1","DeviceName":"Chrome on Windows","UserAgent":"someagent","CreatedAt":100,"RefreshedAt":200,"ExpiresAt":300}]}`,
+			expectedCode: 200,
+		},
+		{
+			name:   "List sessions fails",
+			method: http.MethodGet,
+			path:   "/sessions",
+			setupMocks: func() {
+				mockedController.EXPECT().GetActive(gomock.Any(), "accountuuid").Return(nil, errors.New("connection refused"))
+			},
+			expectedBody: `{"error":"Internal Server Error"}`,
+			expectedCode: 500,
+		},
+		{
+			name:   "Revoke session",
+			method: http.MethodDelete,
+			path:   "/sessions/sessionuuid",
+			setupMocks: func() {
+				mockedController.EXPECT().Revoke(gomock.Any(), "accountuuid", "sessionuuid").Return(nil)
+			},
+			expectedBody: ``,
+			expectedCode: 204,
+		},
+		{
+			name:   "Revoke session of another account",
+			method: http.MethodDelete,
+			path:   "/sessions/sessionuuid",
+			setupMocks: func() {
+				mockedController.EXPECT().Revoke(gomock.Any(), "accountuuid", "sessionuuid").Return(usecases.ErrSessionIsNotExist)
+			},
+			expectedBody: `{"error":"session is not exist"}`,
+			expectedCode: 404,
+		},
+		{
+			name:   "Revoke session with an invalid UUID",
+			method: http.MethodDelete,
+			path:   "/sessions/invaliduuid",
+			setupMocks: func() {
+				mockedController.EXPECT().Revoke(gomock.Any(), "accountuuid", "invaliduuid").Return(validators.ErrUUIDIsNotValid)
+			},
+			expectedBody: `{"error":"` + validators.ErrUUIDIsNotValid.Error() + `"}`,
+			expectedCode: 400,
+		},
+	}
+
+	router := newTestSessionRouter(t, mockedController)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package middlewares
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"time"
+)
+
+type DependenciesAccessLog struct {
+	Logger logapp.ILogger
+}
+
+// AccessLog logs each request once it's served, the query isn't logged as it can carry the signed links
+type AccessLog struct {
+	logger logapp.ILogger
+}
+
+func NewAccessLog(d *DependenciesAccessLog) (*AccessLog, error) {
+	if d == nil {
+		return nil, ErrDependenciesAreNil
+	}
+	if d.Logger == nil {
+		return nil, ErrLoggerIsNil
+	}
+	return &AccessLog{
+		logger: d.Logger.WithField("unit", "access"),
+	}, nil
+}
+
+func (m *AccessLog) Handle(g *gin.Context) {
+	start := time.Now()
+	g.Next()
+
+	status := g.Writer.Status()
+	entry := m.logger.WithContext(g).WithFields(logrus.Fields{
+		"method":      g.Request.Method,
+		"route":       g.FullPath(),
+		"path":        g.Request.URL.Path,
+		"status":      status,
+		"duration_ms": time.Since(start).Milliseconds(),
+		"client_ip":   g.ClientIP(),
+		"user_agent":  g.Request.UserAgent(),
+	})
+	if status >= http.StatusInternalServerError {
+		entry.Warn("request")
+		return
+	}
+	entry.Info("request")
+}
@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAccessLog_Handle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	_, err := NewAccessLog(&DependenciesAccessLog{})
+	assert.ErrorIs(t, err, ErrLoggerIsNil)
+
+	out := &bytes.Buffer{}
+	middleware, err := NewAccessLog(&DependenciesAccessLog{
+		Logger: logapp.NewLogger(&logapp.Config{Format: logapp.FormatJSON, Output: out}),
+	})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(RequestID, middleware.Handle)
+	router.GET("/notme/:uuid", func(g *gin.Context) {
+		g.Status(http.StatusInternalServerError)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/notme/one?signature=secret", nil)
+	request.Header.Set(logapp.RequestIDHeader, "req-1")
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &entry))
+	assert.Equal(t, "warning", entry["level"])
+	assert.Equal(t, "request", entry["msg"])
+	assert.Equal(t, "access", entry["unit"])
+	assert.Equal(t, http.MethodGet, entry["method"])
+	assert.Equal(t, "/notme/:uuid", entry["route"])
+	assert.Equal(t, "/notme/one", entry["path"])
+	assert.EqualValues(t, http.StatusInternalServerError, entry["status"])
+	assert.Equal(t, "req-1", entry[logapp.RequestIDField])
+	assert.NotContains(t, out.String(), "secret")
+}
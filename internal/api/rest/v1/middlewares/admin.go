@@ -0,0 +1,69 @@
+package middlewares
+
+import (
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/adminauth"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+const (
+	adminMiddlewareKey = "Admin"
+)
+
+var (
+	ErrAdminsAreNil = errors.New("dependency admins is nil")
+)
+
+type DependenciesAdmin struct {
+	// Admins are the holders of the admin tokens, several tokens let a token be rotated without a downtime
+	Admins *adminauth.Admins
+	Logger logapp.ILogger
+}
+
+// Admin lets only the requests with one of the admin tokens through, the admin is the actor of the request
+type Admin struct {
+	admins *adminauth.Admins
+	logger logapp.ILogger
+}
+
+func NewAdmin(d *DependenciesAdmin) (*Admin, error) {
+	if d == nil {
+		return nil, ErrDependenciesAreNil
+	}
+	if d.Admins == nil {
+		return nil, ErrAdminsAreNil
+	}
+	if d.Logger == nil {
+		return nil, ErrLoggerIsNil
+	}
+
+	return &Admin{
+		admins: d.Admins,
+		logger: d.Logger.WithField(middlewareKey, adminMiddlewareKey),
+	}, nil
+}
+
+func (m *Admin) Handle(g *gin.Context) {
+	token, ok := strings.CutPrefix(g.GetHeader(authorizationHeader), bearerPrefix)
+	if !ok || token == "" {
+		m.abort(g, ErrTokenIsMissing)
+		return
+	}
+
+	actor, err := m.admins.Authenticate(token)
+	if err != nil {
+		m.abort(g, err)
+		return
+	}
+
+	g.Request = g.Request.WithContext(adminauth.WithActor(g.Request.Context(), actor))
+	g.Next()
+}
+
+func (m *Admin) abort(g *gin.Context, err error) {
+	m.logger.WithContext(g).Warn(err)
+	g.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+}
@@ -0,0 +1,72 @@
+package middlewares
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/adminauth"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewAdmin(t *testing.T) {
+	admins, err := adminauth.New([]string{"admintoken"})
+	require.NoError(t, err)
+
+	_, err = NewAdmin(nil)
+	assert.ErrorIs(t, err, ErrDependenciesAreNil)
+
+	_, err = NewAdmin(&DependenciesAdmin{Logger: logrus.New()})
+	assert.ErrorIs(t, err, ErrAdminsAreNil)
+
+	_, err = NewAdmin(&DependenciesAdmin{Admins: admins})
+	assert.ErrorIs(t, err, ErrLoggerIsNil)
+}
+
+func TestAdmin_Handle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	admins, err := adminauth.New([]string{"alice:oldtoken", "alice:newtoken"})
+	require.NoError(t, err)
+	admin, err := NewAdmin(&DependenciesAdmin{
+		Admins: admins,
+		Logger: logrus.New(),
+	})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.GET("/", admin.Handle, func(g *gin.Context) {
+		// the handlers get the actor of the token
+		g.String(http.StatusOK, adminauth.ActorFrom(g.Request.Context()))
+	})
+
+	testCases := []struct {
+		name         string
+		header       string
+		expectedCode int
+	}{
+		{name: "Valid token", header: "Bearer newtoken", expectedCode: http.StatusOK},
+		{name: "Rotated token is still valid", header: "Bearer oldtoken", expectedCode: http.StatusOK},
+		{name: "Token is missing", header: "", expectedCode: http.StatusUnauthorized},
+		{name: "Wrong scheme", header: "Basic bmV3dG9rZW4=", expectedCode: http.StatusUnauthorized},
+		{name: "Wrong token", header: "Bearer newtoke", expectedCode: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			if tc.expectedCode == http.StatusOK {
+				assert.Equal(t, "alice", w.Body.String())
+			}
+		})
+	}
+}
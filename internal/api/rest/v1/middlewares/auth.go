@@ -0,0 +1,87 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+//go:generate mockgen -destination mocks/middlewares_mocks.go -package middlewares_test github.com/alexsibrin/runbot-auth/internal/api/rest/v1/middlewares IAuthController
+
+const (
+	// AccountUUIDKey is the gin context key of the authenticated account UUID
+	AccountUUIDKey = "account_uuid"
+
+	authorizationHeader = "Authorization"
+	bearerPrefix        = "Bearer "
+	middlewareKey       = "rest_middleware"
+	authMiddlewareKey   = "Auth"
+)
+
+var (
+	ErrDependenciesAreNil = errors.New("dependencies are nil")
+	ErrControllerIsNil    = errors.New("controller is nil")
+	ErrLoggerIsNil        = errors.New("logger is nil")
+	ErrTokenIsMissing     = errors.New("bearer token is missing")
+)
+
+type IAuthController interface {
+	Authenticate(ctx context.Context, token string) (string, error)
+}
+
+type DependenciesAuth struct {
+	Controller IAuthController
+	Logger     logapp.ILogger
+}
+
+// Auth lets only the requests with an access token of an active account through
+type Auth struct {
+	controller IAuthController
+	logger     logapp.ILogger
+}
+
+func NewAuth(d *DependenciesAuth) (*Auth, error) {
+	if d == nil {
+		return nil, ErrDependenciesAreNil
+	}
+	if d.Controller == nil {
+		return nil, ErrControllerIsNil
+	}
+	if d.Logger == nil {
+		return nil, ErrLoggerIsNil
+	}
+	return &Auth{
+		controller: d.Controller,
+		logger:     d.Logger.WithField(middlewareKey, authMiddlewareKey),
+	}, nil
+}
+
+func (m *Auth) Handle(g *gin.Context) {
+	token, ok := strings.CutPrefix(g.GetHeader(authorizationHeader), bearerPrefix)
+	if !ok || token == "" {
+		m.abort(g, ErrTokenIsMissing)
+		return
+	}
+
+	accountuuid, err := m.controller.Authenticate(g, token)
+	if err != nil {
+		m.abort(g, err)
+		return
+	}
+
+	g.Set(AccountUUIDKey, accountuuid)
+	g.Next()
+}
+
+func (m *Auth) abort(g *gin.Context, err error) {
+	m.logger.WithContext(g).Info(err)
+	g.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+}
+
+// AccountUUID returns the UUID of the account authenticated by Auth
+func AccountUUID(g *gin.Context) string {
+	return g.GetString(AccountUUIDKey)
+}
@@ -0,0 +1,88 @@
+package middlewares
+
+import (
+	middlewares_test "github.com/alexsibrin/runbot-auth/internal/api/rest/v1/middlewares/mocks"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuth_Handle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedController := middlewares_test.NewMockIAuthController(ctrl)
+
+	testCases := []struct {
+		name         string
+		header       string
+		setupMocks   func()
+		expectedUUID string
+		expectedCode int
+	}{
+		{
+			name:   "Valid token",
+			header: "Bearer accesstoken",
+			setupMocks: func() {
+				mockedController.EXPECT().Authenticate(gomock.Any(), "accesstoken").Return("someuuid", nil)
+			},
+			expectedUUID: "someuuid",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Token is missing",
+			header:       "",
+			setupMocks:   func() {},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "Wrong scheme",
+			header:       "Basic dXNlcjpwYXNz",
+			setupMocks:   func() {},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:   "Account is not active",
+			header: "Bearer accesstoken",
+			setupMocks: func() {
+				mockedController.EXPECT().Authenticate(gomock.Any(), "accesstoken").Return("", usecases.ErrAccountIsNotActive)
+			},
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			auth, err := NewAuth(&DependenciesAuth{
+				Controller: mockedController,
+				Logger:     logrus.New(),
+			})
+			assert.NoError(t, err)
+
+			var gotuuid string
+			router := gin.New()
+			router.GET("/", auth.Handle, func(g *gin.Context) {
+				gotuuid = AccountUUID(g)
+				g.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.Equal(t, tc.expectedUUID, gotuuid)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package middlewares
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+var (
+	ErrRecorderIsNil = errors.New("recorder is nil")
+)
+
+type IHTTPRecorder interface {
+	ObserveHTTP(method, route string, code int, duration time.Duration)
+}
+
+type DependenciesMetrics struct {
+	Recorder IHTTPRecorder
+}
+
+// Metrics records the count and the latency of the requests by the route pattern, so the path parameters
+// don't make a series each
+type Metrics struct {
+	recorder IHTTPRecorder
+}
+
+func NewMetrics(d *DependenciesMetrics) (*Metrics, error) {
+	if d == nil {
+		return nil, ErrDependenciesAreNil
+	}
+	if d.Recorder == nil {
+		return nil, ErrRecorderIsNil
+	}
+	return &Metrics{
+		recorder: d.Recorder,
+	}, nil
+}
+
+func (m *Metrics) Handle(g *gin.Context) {
+	start := time.Now()
+	g.Next()
+	m.recorder.ObserveHTTP(g.Request.Method, g.FullPath(), g.Writer.Status(), time.Since(start))
+}
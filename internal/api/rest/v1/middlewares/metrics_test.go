@@ -0,0 +1,52 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type httpObservation struct {
+	method string
+	route  string
+	code   int
+}
+
+type recorderStub struct {
+	observations []httpObservation
+}
+
+func (r *recorderStub) ObserveHTTP(method, route string, code int, _ time.Duration) {
+	r.observations = append(r.observations, httpObservation{method: method, route: route, code: code})
+}
+
+func TestMetrics_Handle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	_, err := NewMetrics(&DependenciesMetrics{})
+	assert.ErrorIs(t, err, ErrRecorderIsNil)
+
+	recorder := &recorderStub{}
+	middleware, err := NewMetrics(&DependenciesMetrics{Recorder: recorder})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(middleware.Handle)
+	router.GET("/sessions/:uuid", func(g *gin.Context) {
+		g.Status(http.StatusNoContent)
+	})
+
+	for _, path := range []string{"/sessions/one", "/sessions/two", "/unknown"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, []httpObservation{
+		{method: http.MethodGet, route: "/sessions/:uuid", code: http.StatusNoContent},
+		{method: http.MethodGet, route: "/sessions/:uuid", code: http.StatusNoContent},
+		{method: http.MethodGet, route: "", code: http.StatusNotFound},
+	}, recorder.observations)
+}
@@ -0,0 +1,55 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/api/rest/v1/middlewares (interfaces: IAuthController)
+//
+// Generated by this command:
+//
+//	mockgen -destination mocks/middlewares_mocks.go -package middlewares_test github.com/alexsibrin/runbot-auth/internal/api/rest/v1/middlewares IAuthController
+//
+
+// Package middlewares_test is a generated GoMock package.
+package middlewares_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIAuthController is a mock of IAuthController interface.
+type MockIAuthController struct {
+	ctrl     *gomock.Controller
+	recorder *MockIAuthControllerMockRecorder
+}
+
+// MockIAuthControllerMockRecorder is the mock recorder for MockIAuthController.
+type MockIAuthControllerMockRecorder struct {
+	mock *MockIAuthController
+}
+
+// NewMockIAuthController creates a new mock instance.
+func NewMockIAuthController(ctrl *gomock.Controller) *MockIAuthController {
+	mock := &MockIAuthController{ctrl: ctrl}
+	mock.recorder = &MockIAuthControllerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIAuthController) EXPECT() *MockIAuthControllerMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockIAuthController) Authenticate(arg0 context.Context, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockIAuthControllerMockRecorder) Authenticate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockIAuthController)(nil).Authenticate), arg0, arg1)
+}
@@ -0,0 +1,15 @@
+package middlewares
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestID keeps the X-Request-ID of the caller or makes a new one, the ID is sent back in the response
+// and put into the context of the request, so it's logged with all the entries of the request
+func RequestID(g *gin.Context) {
+	id := logapp.EnsureRequestID(g.GetHeader(logapp.RequestIDHeader))
+	g.Request = g.Request.WithContext(logapp.WithRequestID(g.Request.Context(), id))
+	g.Header(logapp.RequestIDHeader, id)
+	g.Next()
+}
@@ -0,0 +1,51 @@
+package middlewares
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		header string
+		kept   bool
+	}{
+		{name: "caller's id", header: "req-42", kept: true},
+		{name: "without id"},
+		{name: "unsafe id", header: "id level=error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			router := gin.New()
+			router.ContextWithFallback = true
+			router.Use(RequestID)
+			router.GET("/", func(g *gin.Context) {
+				got = logapp.RequestID(g)
+				g.Status(http.StatusNoContent)
+			})
+
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				request.Header.Set(logapp.RequestIDHeader, tt.header)
+			}
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, got, response.Header().Get(logapp.RequestIDHeader))
+			if tt.kept {
+				assert.Equal(t, tt.header, got)
+				return
+			}
+			assert.NoError(t, uuid.Validate(got))
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package middlewares
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+const (
+	tracerName = "github.com/alexsibrin/runbot-auth/internal/api/rest/v1/middlewares"
+)
+
+type DependenciesTracing struct {
+	// Provider is the global one if it's nil
+	Provider trace.TracerProvider
+	// Propagator is the global one if it's nil
+	Propagator propagation.TextMapPropagator
+}
+
+// Tracing starts the server span of the request, it continues the trace of the traceparent header
+type Tracing struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+func NewTracing(d *DependenciesTracing) (*Tracing, error) {
+	if d == nil {
+		return nil, ErrDependenciesAreNil
+	}
+	provider := d.Provider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	propagator := d.Propagator
+	if propagator == nil {
+		propagator = otel.GetTextMapPropagator()
+	}
+	return &Tracing{
+		tracer:     provider.Tracer(tracerName),
+		propagator: propagator,
+	}, nil
+}
+
+func (m *Tracing) Handle(g *gin.Context) {
+	ctx := m.propagator.Extract(g.Request.Context(), propagation.HeaderCarrier(g.Request.Header))
+
+	route := g.FullPath()
+	name := g.Request.Method
+	if route != "" {
+		name = fmt.Sprintf("%s %s", g.Request.Method, route)
+	}
+
+	ctx, span := m.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(g.Request.Method),
+			semconv.HTTPRoute(route),
+			semconv.URLPath(g.Request.URL.Path),
+			semconv.UserAgentOriginal(g.Request.UserAgent()),
+			semconv.ClientAddress(g.ClientIP()),
+		),
+	)
+	defer span.End()
+
+	g.Request = g.Request.WithContext(ctx)
+	g.Next()
+
+	status := g.Writer.Status()
+	span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+}
@@ -3,20 +3,54 @@ package v1
 import (
 	"errors"
 	"github.com/alexsibrin/runbot-auth/internal/api/rest/v1/handlers"
+	"github.com/alexsibrin/runbot-auth/internal/api/rest/v1/middlewares"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
 
 const (
-	v1path     = "/v1"
+	V1Path     = "/v1"
 	SignUpPath = "/signup"
 	SignInPath = "/signin"
 
 	AccountPath  = "/account"
 	RefreshToken = "/refresh"
 
+	AccountExportPath    = AccountPath + "/export"
+	AccountExportOnePath = AccountExportPath + "/:" + handlers.ExportUUIDParam
+	// ExportDownloadPath is signed, see controllers.Export
+	ExportDownloadPath = "/exports/:" + handlers.ExportUUIDParam + "/download"
+	// ExportDownloadURL is the ExportDownloadPath format for controllers.ExportDependencies
+	ExportDownloadURL = V1Path + "/exports/%s/download"
+
+	SessionsPath = "/sessions"
+	SessionPath  = SessionsPath + "/:" + handlers.SessionUUIDParam
+
+	AccountSecurityEventsPath = AccountPath + "/security-events"
+
+	// NotMePath is signed, see usecases.LoginAlert
+	NotMePath = AccountPath + "/not-me/:" + handlers.NotMeUUIDParam
+	// NotMeURL is the NotMePath format for usecases.LoginAlertDependencies
+	NotMeURL          = V1Path + AccountPath + "/not-me/%s"
+	PasswordResetPath = "/password-reset"
+	// PasswordResetRequestPath emails the PasswordResetLinkPath link to the owner
+	PasswordResetRequestPath = PasswordResetPath + "/request"
+	// PasswordResetLinkPath is signed, see usecases.LoginAlert
+	PasswordResetLinkPath = PasswordResetPath + "/:" + handlers.PasswordResetUUIDParam
+	// PasswordResetURL is the PasswordResetLinkPath format for usecases.LoginAlertDependencies
+	PasswordResetURL = V1Path + PasswordResetPath + "/%s"
+
+	AdminPath         = "/admin"
+	AdminAccountsPath = AdminPath + "/accounts"
+
 	VersionPath = "/version"
 	HealthPath  = "/health"
+
+	// LivezPath and ReadyzPath are the probes of the orchestrator, they aren't versioned
+	LivezPath  = "/livez"
+	ReadyzPath = "/readyz"
+	// MetricsPath is scraped by Prometheus, it isn't versioned either
+	MetricsPath = "/metrics"
 )
 
 var (
@@ -26,12 +60,34 @@ var (
 )
 
 type Handlers struct {
-	Account *handlers.Account
-	Common  *handlers.Common
+	Account       *handlers.Account
+	Export        *handlers.Export
+	Session       *handlers.Session
+	SecurityEvent *handlers.SecurityEvent
+	LoginAlert    *handlers.LoginAlert
+	AdminAccount  *handlers.AdminAccount
+	Common        *handlers.Common
+	// Health is optional, the probes aren't routed if it's nil
+	Health *handlers.Health
+	// Metrics is optional, the metrics aren't exposed if it's nil
+	Metrics http.Handler
+}
+
+type Middlewares struct {
+	Auth *middlewares.Auth
+	// Admin is optional, the admin handlers aren't routed if it's nil
+	Admin *middlewares.Admin
+	// Metrics is optional, it records all the requests
+	Metrics *middlewares.Metrics
+	// Tracing is optional, it starts the spans of all the requests
+	Tracing *middlewares.Tracing
+	// AccessLog is optional, it logs the requests of V1Path, the probes and the scrapes aren't logged
+	AccessLog *middlewares.AccessLog
 }
 
 type DependenciesRouter struct {
-	Handlers *Handlers
+	Handlers    *Handlers
+	Middlewares *Middlewares
 }
 
 func NewRouter(dep *DependenciesRouter) (http.Handler, error) {
@@ -44,16 +100,35 @@ func NewRouter(dep *DependenciesRouter) (http.Handler, error) {
 		return nil, ErrDepHandlersAreNil
 	}
 
-	/*
-		if dep.Middlewares == nil {
-			return nil, ErrDepMiddlewaresAreNil
-		}
-	*/
+	if dep.Middlewares == nil {
+		return nil, ErrDepMiddlewaresAreNil
+	}
 
 	rootrouter := gin.New()
+	// the handlers pass gin.Context down, so it has to expose the values of the request context, e.g. the span
+	rootrouter.ContextWithFallback = true
+	rootrouter.Use(middlewares.RequestID)
+	if dep.Middlewares.Tracing != nil {
+		rootrouter.Use(dep.Middlewares.Tracing.Handle)
+	}
+	if dep.Middlewares.Metrics != nil {
+		rootrouter.Use(dep.Middlewares.Metrics.Handle)
+	}
+
+	// Probes
+	if dep.Handlers.Health != nil {
+		rootrouter.GET(LivezPath, dep.Handlers.Health.Livez)
+		rootrouter.GET(ReadyzPath, dep.Handlers.Health.Readyz)
+	}
+	if dep.Handlers.Metrics != nil {
+		rootrouter.GET(MetricsPath, gin.WrapH(dep.Handlers.Metrics))
+	}
 
 	// Creating router 1st version
-	router := rootrouter.Group(v1path)
+	router := rootrouter.Group(V1Path)
+	if dep.Middlewares.AccessLog != nil {
+		router.Use(dep.Middlewares.AccessLog.Handle)
+	}
 
 	// Common handlers
 	router.GET(VersionPath, dep.Handlers.Common.Version)
@@ -64,5 +139,30 @@ func NewRouter(dep *DependenciesRouter) (http.Handler, error) {
 	router.POST(SignUpPath, dep.Handlers.Account.SignUp)
 	router.POST(SignInPath, dep.Handlers.Account.SignIn)
 
+	// Export handlers
+	router.GET(ExportDownloadPath, dep.Handlers.Export.Download)
+
+	// Login alert handlers, authorized by the signed links
+	router.GET(NotMePath, dep.Handlers.LoginAlert.ConfirmNotMe)
+	router.POST(NotMePath, dep.Handlers.LoginAlert.NotMe)
+	router.POST(PasswordResetPath, dep.Handlers.LoginAlert.ResetPassword)
+	router.POST(PasswordResetRequestPath, dep.Handlers.LoginAlert.RequestPasswordReset)
+	router.GET(PasswordResetLinkPath, dep.Handlers.LoginAlert.ConfirmPasswordReset)
+
+	// Account handlers for the authenticated users
+	authorized := router.Group("", dep.Middlewares.Auth.Handle)
+	authorized.DELETE(AccountPath, dep.Handlers.Account.DeleteAccount)
+	authorized.POST(AccountExportPath, dep.Handlers.Export.RequestExport)
+	authorized.GET(AccountExportOnePath, dep.Handlers.Export.GetExport)
+	authorized.GET(SessionsPath, dep.Handlers.Session.GetActive)
+	authorized.DELETE(SessionPath, dep.Handlers.Session.Revoke)
+	authorized.GET(AccountSecurityEventsPath, dep.Handlers.SecurityEvent.GetByAccount)
+
+	// Admin handlers for the admin tools
+	if dep.Middlewares.Admin != nil {
+		admin := router.Group("", dep.Middlewares.Admin.Handle)
+		admin.GET(AdminAccountsPath, dep.Handlers.AdminAccount.List)
+	}
+
 	return rootrouter, nil
 }
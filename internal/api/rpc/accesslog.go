@@ -0,0 +1,58 @@
+package rpc
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+	"time"
+)
+
+const (
+	// healthMethods are polled by the probes, so they aren't logged
+	healthMethods = "/grpc.health.v1.Health/"
+)
+
+// unaryAccessLog logs each call once it's served
+func unaryAccessLog(logger logapp.ILogger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if strings.HasPrefix(info.FullMethod, healthMethods) {
+			return handler(ctx, req)
+		}
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, logger, info.FullMethod, err, time.Since(start))
+		return resp, err
+	}
+}
+
+// streamAccessLog logs the streams once they're closed
+func streamAccessLog(logger logapp.ILogger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if strings.HasPrefix(info.FullMethod, healthMethods) {
+			return handler(srv, ss)
+		}
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(ss.Context(), logger, info.FullMethod, err, time.Since(start))
+		return err
+	}
+}
+
+func logCall(ctx context.Context, logger logapp.ILogger, method string, err error, duration time.Duration) {
+	code := status.Code(err)
+	entry := logger.WithContext(ctx).WithFields(logrus.Fields{
+		"method":      method,
+		"code":        code.String(),
+		"duration_ms": duration.Milliseconds(),
+	})
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		entry.Warn("call")
+	default:
+		entry.Info("call")
+	}
+}
@@ -0,0 +1,57 @@
+package rpc
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/adminauth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+const (
+	authorizationKey = "authorization"
+	bearerPrefix     = "Bearer "
+)
+
+// unaryAdmin authenticates the caller by the authorization metadata with an admin token and puts the admin
+// into the context as the actor. A call without the metadata goes on unauthenticated, the handlers of the
+// audited changes reject it, a call with a wrong token is rejected at once
+func unaryAdmin(admins *adminauth.Admins) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticateAdmin(ctx, admins)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamAdmin(admins *adminauth.Admins) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticateAdmin(ss.Context(), admins)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func authenticateAdmin(ctx context.Context, admins *adminauth.Admins) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(authorizationKey)
+	if len(values) == 0 {
+		return ctx, nil
+	}
+
+	token, ok := strings.CutPrefix(values[0], bearerPrefix)
+	if !ok || token == "" || admins == nil {
+		return nil, status.Error(codes.Unauthenticated, adminauth.ErrTokenIsWrong.Error())
+	}
+	actor, err := admins.Authenticate(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return adminauth.WithActor(ctx, actor), nil
+}
@@ -0,0 +1,54 @@
+package rpc
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/adminauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestUnaryAdmin(t *testing.T) {
+	admins, err := adminauth.New([]string{"alice:alicetoken"})
+	require.NoError(t, err)
+	info := &grpc.UnaryServerInfo{FullMethod: "/runbotauth.Account/SetStatus"}
+
+	testCases := []struct {
+		name          string
+		admins        *adminauth.Admins
+		authorization []string
+		expectedActor string
+		expectedCode  codes.Code
+	}{
+		{name: "Admin is authenticated", admins: admins, authorization: []string{"Bearer alicetoken"}, expectedActor: "alice"},
+		{name: "Call without a token is unauthenticated", admins: admins},
+		{name: "Wrong token", admins: admins, authorization: []string{"Bearer bobtoken"}, expectedCode: codes.Unauthenticated},
+		{name: "Wrong scheme", admins: admins, authorization: []string{"alicetoken"}, expectedCode: codes.Unauthenticated},
+		{name: "No admins are configured", authorization: []string{"Bearer alicetoken"}, expectedCode: codes.Unauthenticated},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.authorization != nil {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(authorizationKey, tc.authorization[0]))
+			}
+
+			var actor string
+			called := false
+			_, err := unaryAdmin(tc.admins)(ctx, nil, info, func(ctx context.Context, _ any) (any, error) {
+				called = true
+				actor = adminauth.ActorFrom(ctx)
+				return nil, nil
+			})
+
+			assert.Equal(t, tc.expectedCode, status.Code(err))
+			assert.Equal(t, tc.expectedCode == codes.OK, called)
+			assert.Equal(t, tc.expectedActor, actor)
+		})
+	}
+}
@@ -7,37 +7,62 @@ import (
 	"github.com/alexsibrin/runbot-auth/internal/api/validators"
 	"github.com/alexsibrin/runbot-auth/internal/logapp"
 	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
 	"github.com/alexsibrin/runbot-auth/pkg/runbotauthproto"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
-// TODO: Add tests
-
 const (
 	accountKey = "accounts"
 )
 
 var (
-	ErrDependenciesAreNil = errors.New("dependencies are nil")
-	ErrControllerIsNil    = errors.New("controller is nil")
-	ErrLoggerIsNil        = errors.New("logger is nil")
+	ErrDependenciesAreNil           = errors.New("dependencies are nil")
+	ErrControllerIsNil              = errors.New("controller is nil")
+	ErrSecurityEventControllerIsNil = errors.New("security event controller is nil")
+	ErrAuditLogControllerIsNil      = errors.New("audit log controller is nil")
+	ErrWatchControllerIsNil         = errors.New("watch controller is nil")
+	ErrLoggerIsNil                  = errors.New("logger is nil")
 )
 
+//go:generate mockgen -destination ./mocks/mocks_account.go -package handlers_test github.com/alexsibrin/runbot-auth/internal/api/rpc/handlers IController,ISecurityEventController,IAuditLogController,IAccountWatchController
 type IController interface {
 	ChangeAccountStatus(ctx context.Context, model *models.ChangeAccountStatus) (*models.ChangeAccountStatusResponse, error)
 	GetOneByUUID(ctx context.Context, uuid string) (*models.AccountGetModel, error)
+	GetManyByUUIDs(ctx context.Context, uuids []string) (*models.AccountBatchGetResponse, error)
+	Search(ctx context.Context, model *models.AccountsSearch) (*models.AccountsResponse, error)
+	DeleteAccount(ctx context.Context, model *models.DeleteAccount) (*models.DeleteAccountResponse, error)
+	GetStatusHistory(ctx context.Context, uuid string, limit int) (*models.StatusHistoryResponse, error)
+}
+
+type ISecurityEventController interface {
+	Search(ctx context.Context, model *models.SecurityEventsSearch) (*models.SecurityEventsResponse, error)
+}
+
+type IAuditLogController interface {
+	Export(ctx context.Context, cursor string, limit int) (*models.AuditLogResponse, error)
+}
+
+type IAccountWatchController interface {
+	Watch(ctx context.Context, cursor string, send func(change *models.AccountChange) error) error
 }
 
 type AccountDependencies struct {
-	Controller IController
-	Logger     logapp.ILogger
+	Controller              IController
+	SecurityEventController ISecurityEventController
+	AuditLogController      IAuditLogController
+	WatchController         IAccountWatchController
+	Logger                  logapp.ILogger
 }
 
 type Account struct {
-	controller IController
-	logger     logapp.ILogger
+	controller              IController
+	securityeventcontroller ISecurityEventController
+	auditlogcontroller      IAuditLogController
+	watchcontroller         IAccountWatchController
+	logger                  logapp.ILogger
 	runbotauthproto.UnimplementedAccountServer
 }
 
@@ -48,14 +73,26 @@ func NewAccount(d *AccountDependencies) (*Account, error) {
 	if d.Controller == nil {
 		return nil, ErrControllerIsNil
 	}
+	if d.SecurityEventController == nil {
+		return nil, ErrSecurityEventControllerIsNil
+	}
+	if d.AuditLogController == nil {
+		return nil, ErrAuditLogControllerIsNil
+	}
+	if d.WatchController == nil {
+		return nil, ErrWatchControllerIsNil
+	}
 	if d.Logger == nil {
 		return nil, ErrLoggerIsNil
 	}
 
 	l := d.Logger.WithField(handlersKey, accountKey)
 	return &Account{
-		controller: d.Controller,
-		logger:     l,
+		controller:              d.Controller,
+		securityeventcontroller: d.SecurityEventController,
+		auditlogcontroller:      d.AuditLogController,
+		watchcontroller:         d.WatchController,
+		logger:                  l,
 	}, nil
 }
 
@@ -66,24 +103,181 @@ func (h *Account) Register(s *grpc.Server) {
 func (h *Account) Get(ctx context.Context, model *runbotauthproto.GetAccount) (*runbotauthproto.GetAccountResponse, error) {
 	modelout, err := h.controller.GetOneByUUID(ctx, model.UUID)
 	if err != nil {
-		return nil, h.handlerError(err)
+		return nil, h.handlerError(ctx, err)
 	}
 	response := h.accountGetModelToResponse(modelout)
 	return response, nil
 }
 
-func (h *Account) ChangeAccountStatus(ctx context.Context, model *runbotauthproto.ChangeAccountStatus) (*runbotauthproto.ChangeAccountStatusResponse, error) {
-	result, err := h.controller.ChangeAccountStatus(ctx, h.changeAccountStatusToModel(model))
+// BatchGet looks the accounts up with one query, the missing UUIDs aren't an error
+func (h *Account) BatchGet(ctx context.Context, model *runbotauthproto.AccountBatchGet) (*runbotauthproto.AccountBatchGetResponse, error) {
+	result, err := h.controller.GetManyByUUIDs(ctx, model.UUIDs)
 	if err != nil {
-		return nil, h.handlerError(err)
+		return nil, h.handlerError(ctx, err)
+	}
+
+	response := &runbotauthproto.AccountBatchGetResponse{
+		Accounts: make([]*runbotauthproto.GetAccountResponse, 0, len(result.Accounts)),
+		Missing:  result.Missing,
+	}
+	for _, account := range result.Accounts {
+		response.Accounts = append(response.Accounts, h.accountGetModelToResponse(account))
+	}
+	return response, nil
+}
+
+// ListAccounts returns a page of the accounts matching the filters to an admin, the limit is chosen by the service
+// if it's zero
+func (h *Account) ListAccounts(ctx context.Context, model *runbotauthproto.AccountsList) (*runbotauthproto.AccountsListResponse, error) {
+	if _, err := adminActor(ctx); err != nil {
+		return nil, err
+	}
+
+	result, err := h.controller.Search(ctx, &models.AccountsSearch{
+		Statuses:     model.Statuses,
+		EmailPrefix:  model.EmailPrefix,
+		NameContains: model.NameContains,
+		CreatedSince: model.CreatedSince,
+		CreatedUntil: model.CreatedUntil,
+		Sort:         model.Sort,
+		Descending:   model.Descending,
+		Cursor:       model.Cursor,
+		Limit:        int(model.Limit),
+	})
+	if err != nil {
+		return nil, h.handlerError(ctx, err)
+	}
+
+	response := &runbotauthproto.AccountsListResponse{
+		Accounts:   make([]*runbotauthproto.AccountSummary, 0, len(result.Accounts)),
+		NextCursor: result.NextCursor,
+	}
+	for _, account := range result.Accounts {
+		response.Accounts = append(response.Accounts, &runbotauthproto.AccountSummary{
+			UUID:        account.UUID,
+			Email:       account.Email,
+			Name:        account.Name,
+			Status:      account.Status,
+			StatusUntil: account.StatusUntil,
+			CreatedAt:   account.CreatedAt,
+			UpdatedAt:   account.UpdatedAt,
+			DeletedAt:   account.DeletedAt,
+		})
+	}
+	return response, nil
+}
+
+// SetStatus is the admin status change recorded in the audit log, the actor is the admin of the token
+func (h *Account) SetStatus(ctx context.Context, model *runbotauthproto.ChangeAccountStatus) (*runbotauthproto.ChangeAccountStatusResponse, error) {
+	actor, err := adminActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := h.controller.ChangeAccountStatus(ctx, h.changeAccountStatusToModel(ctx, actor, model))
+	if err != nil {
+		return nil, h.handlerError(ctx, err)
 	}
 
 	response := h.changeAccountStatusToResponse(result)
 	return response, nil
 }
 
-func (h *Account) handlerError(err error) error {
-	h.logger.Error(err)
+// DeleteAccount is the admin deletion recorded in the audit log, the account can be restored with SetStatus until the purging
+func (h *Account) DeleteAccount(ctx context.Context, model *runbotauthproto.AccountDelete) (*runbotauthproto.AccountDeleteResponse, error) {
+	actor, err := adminActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := h.controller.DeleteAccount(ctx, &models.DeleteAccount{
+		UUID:  model.UUID,
+		Actor: actor,
+		Audit: auditOf(ctx),
+	})
+	if err != nil {
+		return nil, h.handlerError(ctx, err)
+	}
+
+	response := h.deleteAccountToResponse(result)
+	return response, nil
+}
+
+// GetStatusHistory returns the latest status changes to an admin, the limit is chosen by the service if it's zero
+func (h *Account) GetStatusHistory(ctx context.Context, model *runbotauthproto.AccountStatusHistory) (*runbotauthproto.AccountStatusHistoryResponse, error) {
+	if _, err := adminActor(ctx); err != nil {
+		return nil, err
+	}
+
+	result, err := h.controller.GetStatusHistory(ctx, model.UUID, int(model.Limit))
+	if err != nil {
+		return nil, h.handlerError(ctx, err)
+	}
+
+	response := h.statusHistoryToResponse(result)
+	return response, nil
+}
+
+// SearchSecurityEvents returns a page of the security events of all the accounts to an admin, the limit is chosen
+// by the service if it's zero
+func (h *Account) SearchSecurityEvents(ctx context.Context, model *runbotauthproto.SecurityEventsSearch) (*runbotauthproto.SecurityEventsResponse, error) {
+	if _, err := adminActor(ctx); err != nil {
+		return nil, err
+	}
+
+	result, err := h.securityeventcontroller.Search(ctx, h.securityEventsSearchToModel(model))
+	if err != nil {
+		return nil, h.handlerError(ctx, err)
+	}
+
+	response := h.securityEventsToResponse(result)
+	return response, nil
+}
+
+// ExportAuditLog returns a page of the admin audit log from the earliest record to an admin, the limit is chosen
+// by the service if it's zero
+func (h *Account) ExportAuditLog(ctx context.Context, model *runbotauthproto.AuditLogExport) (*runbotauthproto.AuditLogResponse, error) {
+	if _, err := adminActor(ctx); err != nil {
+		return nil, err
+	}
+
+	result, err := h.auditlogcontroller.Export(ctx, model.Cursor, int(model.Limit))
+	if err != nil {
+		return nil, h.handlerError(ctx, err)
+	}
+
+	response := h.auditLogToResponse(result)
+	return response, nil
+}
+
+// WatchAccounts streams the account changes until the client leaves or the service stops with Unavailable.
+// The client resumes with the cursor of the last received change, FailedPrecondition means the changes
+// after it are deleted and the accounts have to be reloaded
+func (h *Account) WatchAccounts(model *runbotauthproto.AccountWatch, stream runbotauthproto.Account_WatchAccountsServer) error {
+	ctx := stream.Context()
+	err := h.watchcontroller.Watch(ctx, model.Cursor, func(change *models.AccountChange) error {
+		return stream.Send(&runbotauthproto.AccountChange{
+			Cursor:      change.Cursor,
+			ID:          change.ID,
+			Type:        change.Type,
+			AccountUUID: change.AccountUUID,
+			CreatedAt:   change.CreatedAt,
+			Data:        change.Data,
+		})
+	})
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() != nil:
+		// the client has left, it isn't an error of the service
+		return status.FromContextError(ctx.Err()).Err()
+	default:
+		return h.handlerError(ctx, err)
+	}
+}
+
+func (h *Account) handlerError(ctx context.Context, err error) error {
+	h.logger.WithContext(ctx).Error(err)
 
 	s := codes.Internal
 
@@ -92,8 +286,31 @@ func (h *Account) handlerError(err error) error {
 		s = codes.Canceled
 	case errors.Is(err, validators.ErrEmailFormatIsNotCorrect):
 		s = codes.Canceled
+	case errors.Is(err, validators.ErrEmailIsTooLong):
+		s = codes.Canceled
+	case errors.Is(err, validators.ErrUUIDIsNotValid),
+		errors.Is(err, usecases.ErrTooManyUUIDs):
+		s = codes.InvalidArgument
+	case errors.Is(err, validators.ErrStatusIsNotValid),
+		errors.Is(err, validators.ErrStatusUntilIsNotValid),
+		errors.Is(err, validators.ErrStatusReasonIsTooLong),
+		errors.Is(err, validators.ErrActorIsTooLong):
+		s = codes.InvalidArgument
+	case errors.Is(err, validators.ErrCursorIsNotValid),
+		errors.Is(err, validators.ErrSecurityEventTypeIsNotValid),
+		errors.Is(err, validators.ErrIPIsNotValid),
+		errors.Is(err, validators.ErrTimeRangeIsNotValid),
+		errors.Is(err, validators.ErrAccountSortIsNotValid):
+		s = codes.InvalidArgument
 	case errors.As(err, &repositories.ErrAccountNotFoundByUUID{}):
 		s = codes.NotFound
+	case errors.Is(err, usecases.ErrAccountIsNotExist):
+		s = codes.NotFound
+	case errors.Is(err, usecases.ErrAccountIsDeleted),
+		errors.Is(err, usecases.ErrCursorIsExpired):
+		s = codes.FailedPrecondition
+	case errors.Is(err, usecases.ErrWatchIsClosed):
+		s = codes.Unavailable
 	}
 
 	return status.Error(s, err.Error())
@@ -101,9 +318,10 @@ func (h *Account) handlerError(err error) error {
 
 func (h *Account) accountGetModelToResponse(model *models.AccountGetModel) *runbotauthproto.GetAccountResponse {
 	return &runbotauthproto.GetAccountResponse{
-		UUID:  model.UUID,
-		Name:  model.Name,
-		Email: model.Email,
+		UUID:      model.UUID,
+		Name:      model.Name,
+		Email:     model.Email,
+		CreatedAt: model.CreatedAt,
 	}
 }
 
@@ -112,12 +330,100 @@ func (h *Account) changeAccountStatusToResponse(model *models.ChangeAccountStatu
 		UUID:     model.UUID,
 		Status:   uint32(model.Status),
 		UpdateAt: model.UpdatedAt,
+		Until:    model.Until,
 	}
 }
 
-func (h *Account) changeAccountStatusToModel(request *runbotauthproto.ChangeAccountStatus) *models.ChangeAccountStatus {
+func (h *Account) changeAccountStatusToModel(ctx context.Context, actor string, request *runbotauthproto.ChangeAccountStatus) *models.ChangeAccountStatus {
 	return &models.ChangeAccountStatus{
 		UUID:   request.UUID,
 		Status: uint8(request.Status),
+		Reason: request.Reason,
+		Actor:  actor,
+		Until:  request.Until,
+		Audit:  auditOf(ctx),
+	}
+}
+
+func (h *Account) deleteAccountToResponse(model *models.DeleteAccountResponse) *runbotauthproto.AccountDeleteResponse {
+	return &runbotauthproto.AccountDeleteResponse{
+		UUID:      model.UUID,
+		Status:    uint32(model.Status),
+		DeletedAt: model.DeletedAt,
+		PurgeAt:   model.PurgeAt,
+	}
+}
+
+func (h *Account) statusHistoryToResponse(model *models.StatusHistoryResponse) *runbotauthproto.AccountStatusHistoryResponse {
+	response := &runbotauthproto.AccountStatusHistoryResponse{
+		UUID:    model.UUID,
+		Changes: make([]*runbotauthproto.StatusChange, 0, len(model.Changes)),
+	}
+	for _, change := range model.Changes {
+		response.Changes = append(response.Changes, &runbotauthproto.StatusChange{
+			ID:         change.ID,
+			FromStatus: uint32(change.FromStatus),
+			ToStatus:   uint32(change.ToStatus),
+			Reason:     change.Reason,
+			Actor:      change.Actor,
+			Until:      change.Until,
+			CreatedAt:  change.CreatedAt,
+		})
+	}
+	return response
+}
+
+func (h *Account) securityEventsSearchToModel(request *runbotauthproto.SecurityEventsSearch) *models.SecurityEventsSearch {
+	return &models.SecurityEventsSearch{
+		AccountUUID: request.AccountUUID,
+		Types:       request.Types,
+		IP:          request.IP,
+		Since:       request.Since,
+		Until:       request.Until,
+		Cursor:      request.Cursor,
+		Limit:       int(request.Limit),
+	}
+}
+
+func (h *Account) securityEventsToResponse(model *models.SecurityEventsResponse) *runbotauthproto.SecurityEventsResponse {
+	response := &runbotauthproto.SecurityEventsResponse{
+		Events:     make([]*runbotauthproto.SecurityEvent, 0, len(model.Events)),
+		NextCursor: model.NextCursor,
+	}
+	for _, event := range model.Events {
+		response.Events = append(response.Events, &runbotauthproto.SecurityEvent{
+			ID:          event.ID,
+			AccountUUID: event.AccountUUID,
+			Type:        event.Type,
+			IP:          event.IP,
+			UserAgent:   event.UserAgent,
+			Details:     event.Details,
+			CreatedAt:   event.CreatedAt,
+		})
+	}
+	return response
+}
+
+func (h *Account) auditLogToResponse(model *models.AuditLogResponse) *runbotauthproto.AuditLogResponse {
+	response := &runbotauthproto.AuditLogResponse{
+		Records:    make([]*runbotauthproto.AuditRecord, 0, len(model.Records)),
+		NextCursor: model.NextCursor,
+	}
+	for _, record := range model.Records {
+		response.Records = append(response.Records, &runbotauthproto.AuditRecord{
+			ID:         record.ID,
+			Actor:      record.Actor,
+			Action:     record.Action,
+			TargetUUID: record.TargetUUID,
+			Before:     record.Before,
+			After:      record.After,
+			Method:     record.Method,
+			IP:         record.IP,
+			UserAgent:  record.UserAgent,
+			CreatedAt:  record.CreatedAt,
+			PrevHash:   record.PrevHash,
+			Hash:       record.Hash,
+		})
 	}
+	return response
 }
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	handlers_test "github.com/alexsibrin/runbot-auth/internal/api/rpc/handlers/mocks"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/alexsibrin/runbot-auth/pkg/runbotauthproto"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestAccount_BatchGet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+
+	mockedController := handlers_test.NewMockIController(ctrl)
+	handler, err := NewAccount(&AccountDependencies{
+		Controller:              mockedController,
+		SecurityEventController: handlers_test.NewMockISecurityEventController(ctrl),
+		AuditLogController:      handlers_test.NewMockIAuditLogController(ctrl),
+		WatchController:         handlers_test.NewMockIAccountWatchController(ctrl),
+		Logger:                  logrus.New(),
+	})
+	require.NoError(t, err)
+
+	founduuid, missinguuid := uuid.NewString(), uuid.NewString()
+	found := &models.AccountGetModel{UUID: founduuid, Email: "bob@example.com", Name: "Bob", CreatedAt: 100}
+	foundresponse := &runbotauthproto.GetAccountResponse{UUID: founduuid, Email: "bob@example.com", Name: "Bob", CreatedAt: 100}
+
+	toomany := make([]string, usecases.MaxBatchGetUUIDs+1)
+	for i := range toomany {
+		toomany[i] = uuid.NewString()
+	}
+
+	testCases := []struct {
+		name         string
+		in           []string
+		setupMocks   func()
+		out          *runbotauthproto.AccountBatchGetResponse
+		expectedCode codes.Code
+	}{
+		{
+			name: "Found and missing accounts",
+			in:   []string{founduuid, missinguuid},
+			setupMocks: func() {
+				mockedController.EXPECT().GetManyByUUIDs(ctx, []string{founduuid, missinguuid}).Return(&models.AccountBatchGetResponse{
+					Accounts: []*models.AccountGetModel{found},
+					Missing:  []string{missinguuid},
+				}, nil)
+			},
+			out: &runbotauthproto.AccountBatchGetResponse{
+				Accounts: []*runbotauthproto.GetAccountResponse{foundresponse},
+				Missing:  []string{missinguuid},
+			},
+		},
+		{
+			name: "Duplicate UUIDs are returned once",
+			in:   []string{founduuid, founduuid, missinguuid, missinguuid},
+			setupMocks: func() {
+				mockedController.EXPECT().GetManyByUUIDs(ctx, []string{founduuid, founduuid, missinguuid, missinguuid}).Return(&models.AccountBatchGetResponse{
+					Accounts: []*models.AccountGetModel{found},
+					Missing:  []string{missinguuid},
+				}, nil)
+			},
+			out: &runbotauthproto.AccountBatchGetResponse{
+				Accounts: []*runbotauthproto.GetAccountResponse{foundresponse},
+				Missing:  []string{missinguuid},
+			},
+		},
+		{
+			name: "No accounts are found",
+			in:   []string{missinguuid},
+			setupMocks: func() {
+				mockedController.EXPECT().GetManyByUUIDs(ctx, []string{missinguuid}).Return(&models.AccountBatchGetResponse{
+					Missing: []string{missinguuid},
+				}, nil)
+			},
+			out: &runbotauthproto.AccountBatchGetResponse{
+				Accounts: []*runbotauthproto.GetAccountResponse{},
+				Missing:  []string{missinguuid},
+			},
+		},
+		{
+			name: "Too many UUIDs",
+			in:   toomany,
+			setupMocks: func() {
+				mockedController.EXPECT().GetManyByUUIDs(ctx, toomany).Return(nil, usecases.ErrTooManyUUIDs)
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "Invalid UUID",
+			in:   []string{founduuid, "invaliduuid"},
+			setupMocks: func() {
+				mockedController.EXPECT().GetManyByUUIDs(ctx, []string{founduuid, "invaliduuid"}).Return(nil, validators.ErrUUIDIsNotValid)
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			result, err := handler.BatchGet(ctx, &runbotauthproto.AccountBatchGet{UUIDs: tc.in})
+
+			assert.Equal(t, tc.expectedCode, status.Code(err))
+			assert.Equal(t, tc.out, result)
+		})
+	}
+}
@@ -1,5 +1,50 @@
 package handlers
 
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/adminauth"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"net"
+	"strings"
+)
+
 const (
 	handlersKey = "rpc handler"
+
+	userAgentKey = "user-agent"
 )
+
+var (
+	ErrAdminIsNotAuthenticated = errors.New("admin token is required in the authorization metadata")
+)
+
+// adminActor is the admin authenticated by the token of the call, the audited changes are made only by them
+func adminActor(ctx context.Context) (string, error) {
+	actor := adminauth.ActorFrom(ctx)
+	if actor == "" {
+		return "", status.Error(codes.Unauthenticated, ErrAdminIsNotAuthenticated.Error())
+	}
+	return actor, nil
+}
+
+// auditOf describes the admin request for the audit log
+func auditOf(ctx context.Context) *models.AuditMetadata {
+	audit := &models.AuditMetadata{}
+	audit.Method, _ = grpc.Method(ctx)
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		audit.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(audit.IP); err == nil {
+			audit.IP = host
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		audit.UserAgent = strings.Join(md.Get(userAgentKey), " ")
+	}
+	return audit
+}
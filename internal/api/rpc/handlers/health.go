@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/health"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"time"
+)
+
+const (
+	healthKey = "health"
+
+	DefaultHealthInterval = 5 * time.Second
+)
+
+var (
+	ErrCheckerIsNil = errors.New("checker is nil")
+)
+
+type IHealthChecker interface {
+	Check(ctx context.Context) *health.Report
+}
+
+type HealthConfig struct {
+	// Interval is how often the checks are run, DefaultHealthInterval is used if it isn't positive
+	Interval time.Duration
+	// Services are reported along with the overall "" one
+	Services []string
+}
+
+type HealthDependencies struct {
+	Checker IHealthChecker
+	Logger  logapp.ILogger
+	Config  *HealthConfig
+}
+
+// Health is the standard grpc.health.v1 service, its status follows the checks of the app
+type Health struct {
+	checker  IHealthChecker
+	logger   logapp.ILogger
+	server   *grpchealth.Server
+	interval time.Duration
+	services []string
+}
+
+func NewHealth(d *HealthDependencies) (*Health, error) {
+	if d == nil {
+		return nil, ErrDependenciesAreNil
+	}
+	if d.Checker == nil {
+		return nil, ErrCheckerIsNil
+	}
+	if d.Logger == nil {
+		return nil, ErrLoggerIsNil
+	}
+
+	h := &Health{
+		checker:  d.Checker,
+		logger:   d.Logger.WithField(handlersKey, healthKey),
+		server:   grpchealth.NewServer(),
+		interval: DefaultHealthInterval,
+		// the services aren't serving until they're checked
+		services: []string{""},
+	}
+	if d.Config != nil {
+		if d.Config.Interval > 0 {
+			h.interval = d.Config.Interval
+		}
+		h.services = append(h.services, d.Config.Services...)
+	}
+	h.set(healthpb.HealthCheckResponse_NOT_SERVING)
+	return h, nil
+}
+
+func (h *Health) Register(s *grpc.Server) {
+	healthpb.RegisterHealthServer(s, h.server)
+}
+
+// Run updates the status every interval until ctx is done, then the services are reported as stopped
+func (h *Health) Run(ctx context.Context) error {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		h.check(ctx)
+
+		select {
+		case <-ctx.Done():
+			h.server.Shutdown()
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *Health) check(ctx context.Context) {
+	report := h.checker.Check(ctx)
+	if report.IsOk() {
+		h.set(healthpb.HealthCheckResponse_SERVING)
+		return
+	}
+	for _, result := range report.Checks {
+		if result.Status != health.StatusOk {
+			h.logger.Warnf("%s check failed: %s", result.Name, result.Error)
+		}
+	}
+	h.set(healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+func (h *Health) set(status healthpb.HealthCheckResponse_ServingStatus) {
+	for _, service := range h.services {
+		h.server.SetServingStatus(service, status)
+	}
+}
@@ -0,0 +1,244 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/api/rpc/handlers (interfaces: IController,ISecurityEventController,IAuditLogController,IAccountWatchController)
+//
+// Generated by this command:
+//
+//	mockgen -destination ./mocks/mocks_account.go -package handlers_test github.com/alexsibrin/runbot-auth/internal/api/rpc/handlers IController,ISecurityEventController,IAuditLogController,IAccountWatchController
+//
+
+// Package handlers_test is a generated GoMock package.
+package handlers_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/alexsibrin/runbot-auth/internal/api/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIController is a mock of IController interface.
+type MockIController struct {
+	ctrl     *gomock.Controller
+	recorder *MockIControllerMockRecorder
+}
+
+// MockIControllerMockRecorder is the mock recorder for MockIController.
+type MockIControllerMockRecorder struct {
+	mock *MockIController
+}
+
+// NewMockIController creates a new mock instance.
+func NewMockIController(ctrl *gomock.Controller) *MockIController {
+	mock := &MockIController{ctrl: ctrl}
+	mock.recorder = &MockIControllerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIController) EXPECT() *MockIControllerMockRecorder {
+	return m.recorder
+}
+
+// ChangeAccountStatus mocks base method.
+func (m *MockIController) ChangeAccountStatus(arg0 context.Context, arg1 *models.ChangeAccountStatus) (*models.ChangeAccountStatusResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeAccountStatus", arg0, arg1)
+	ret0, _ := ret[0].(*models.ChangeAccountStatusResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeAccountStatus indicates an expected call of ChangeAccountStatus.
+func (mr *MockIControllerMockRecorder) ChangeAccountStatus(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeAccountStatus", reflect.TypeOf((*MockIController)(nil).ChangeAccountStatus), arg0, arg1)
+}
+
+// DeleteAccount mocks base method.
+func (m *MockIController) DeleteAccount(arg0 context.Context, arg1 *models.DeleteAccount) (*models.DeleteAccountResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", arg0, arg1)
+	ret0, _ := ret[0].(*models.DeleteAccountResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockIControllerMockRecorder) DeleteAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockIController)(nil).DeleteAccount), arg0, arg1)
+}
+
+// GetManyByUUIDs mocks base method.
+func (m *MockIController) GetManyByUUIDs(arg0 context.Context, arg1 []string) (*models.AccountBatchGetResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetManyByUUIDs", arg0, arg1)
+	ret0, _ := ret[0].(*models.AccountBatchGetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetManyByUUIDs indicates an expected call of GetManyByUUIDs.
+func (mr *MockIControllerMockRecorder) GetManyByUUIDs(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetManyByUUIDs", reflect.TypeOf((*MockIController)(nil).GetManyByUUIDs), arg0, arg1)
+}
+
+// GetOneByUUID mocks base method.
+func (m *MockIController) GetOneByUUID(arg0 context.Context, arg1 string) (*models.AccountGetModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOneByUUID", arg0, arg1)
+	ret0, _ := ret[0].(*models.AccountGetModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOneByUUID indicates an expected call of GetOneByUUID.
+func (mr *MockIControllerMockRecorder) GetOneByUUID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOneByUUID", reflect.TypeOf((*MockIController)(nil).GetOneByUUID), arg0, arg1)
+}
+
+// GetStatusHistory mocks base method.
+func (m *MockIController) GetStatusHistory(arg0 context.Context, arg1 string, arg2 int) (*models.StatusHistoryResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatusHistory", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.StatusHistoryResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatusHistory indicates an expected call of GetStatusHistory.
+func (mr *MockIControllerMockRecorder) GetStatusHistory(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatusHistory", reflect.TypeOf((*MockIController)(nil).GetStatusHistory), arg0, arg1, arg2)
+}
+
+// Search mocks base method.
+func (m *MockIController) Search(arg0 context.Context, arg1 *models.AccountsSearch) (*models.AccountsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", arg0, arg1)
+	ret0, _ := ret[0].(*models.AccountsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockIControllerMockRecorder) Search(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockIController)(nil).Search), arg0, arg1)
+}
+
+// MockISecurityEventController is a mock of ISecurityEventController interface.
+type MockISecurityEventController struct {
+	ctrl     *gomock.Controller
+	recorder *MockISecurityEventControllerMockRecorder
+}
+
+// MockISecurityEventControllerMockRecorder is the mock recorder for MockISecurityEventController.
+type MockISecurityEventControllerMockRecorder struct {
+	mock *MockISecurityEventController
+}
+
+// NewMockISecurityEventController creates a new mock instance.
+func NewMockISecurityEventController(ctrl *gomock.Controller) *MockISecurityEventController {
+	mock := &MockISecurityEventController{ctrl: ctrl}
+	mock.recorder = &MockISecurityEventControllerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockISecurityEventController) EXPECT() *MockISecurityEventControllerMockRecorder {
+	return m.recorder
+}
+
+// Search mocks base method.
+func (m *MockISecurityEventController) Search(arg0 context.Context, arg1 *models.SecurityEventsSearch) (*models.SecurityEventsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", arg0, arg1)
+	ret0, _ := ret[0].(*models.SecurityEventsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockISecurityEventControllerMockRecorder) Search(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockISecurityEventController)(nil).Search), arg0, arg1)
+}
+
+// MockIAuditLogController is a mock of IAuditLogController interface.
+type MockIAuditLogController struct {
+	ctrl     *gomock.Controller
+	recorder *MockIAuditLogControllerMockRecorder
+}
+
+// MockIAuditLogControllerMockRecorder is the mock recorder for MockIAuditLogController.
+type MockIAuditLogControllerMockRecorder struct {
+	mock *MockIAuditLogController
+}
+
+// NewMockIAuditLogController creates a new mock instance.
+func NewMockIAuditLogController(ctrl *gomock.Controller) *MockIAuditLogController {
+	mock := &MockIAuditLogController{ctrl: ctrl}
+	mock.recorder = &MockIAuditLogControllerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIAuditLogController) EXPECT() *MockIAuditLogControllerMockRecorder {
+	return m.recorder
+}
+
+// Export mocks base method.
+func (m *MockIAuditLogController) Export(arg0 context.Context, arg1 string, arg2 int) (*models.AuditLogResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.AuditLogResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockIAuditLogControllerMockRecorder) Export(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockIAuditLogController)(nil).Export), arg0, arg1, arg2)
+}
+
+// MockIAccountWatchController is a mock of IAccountWatchController interface.
+type MockIAccountWatchController struct {
+	ctrl     *gomock.Controller
+	recorder *MockIAccountWatchControllerMockRecorder
+}
+
+// MockIAccountWatchControllerMockRecorder is the mock recorder for MockIAccountWatchController.
+type MockIAccountWatchControllerMockRecorder struct {
+	mock *MockIAccountWatchController
+}
+
+// NewMockIAccountWatchController creates a new mock instance.
+func NewMockIAccountWatchController(ctrl *gomock.Controller) *MockIAccountWatchController {
+	mock := &MockIAccountWatchController{ctrl: ctrl}
+	mock.recorder = &MockIAccountWatchControllerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIAccountWatchController) EXPECT() *MockIAccountWatchControllerMockRecorder {
+	return m.recorder
+}
+
+// Watch mocks base method.
+func (m *MockIAccountWatchController) Watch(arg0 context.Context, arg1 string, arg2 func(*models.AccountChange) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Watch", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Watch indicates an expected call of Watch.
+func (mr *MockIAccountWatchControllerMockRecorder) Watch(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockIAccountWatchController)(nil).Watch), arg0, arg1, arg2)
+}
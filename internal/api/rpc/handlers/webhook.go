@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/api/models"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/alexsibrin/runbot-auth/pkg/runbotauthproto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TODO: Add tests
+
+const (
+	webhookKey = "webhooks"
+)
+
+type IWebhookController interface {
+	Create(ctx context.Context, model *models.WebhookCreate) (*models.Webhook, error)
+	GetOne(ctx context.Context, webhookuuid string) (*models.Webhook, error)
+	GetAll(ctx context.Context) (*models.WebhooksResponse, error)
+	Update(ctx context.Context, model *models.WebhookUpdate) (*models.Webhook, error)
+	Delete(ctx context.Context, model *models.WebhookDelete) error
+	ListDeliveries(ctx context.Context, model *models.WebhookDeliveriesSearch) (*models.WebhookDeliveriesResponse, error)
+	Redeliver(ctx context.Context, model *models.WebhookRedeliver) (*models.WebhookDelivery, error)
+}
+
+type WebhookDependencies struct {
+	Controller IWebhookController
+	Logger     logapp.ILogger
+}
+
+// Webhook is the admin API of the partner webhooks
+type Webhook struct {
+	controller IWebhookController
+	logger     logapp.ILogger
+	runbotauthproto.UnimplementedWebhooksServer
+}
+
+func NewWebhook(d *WebhookDependencies) (*Webhook, error) {
+	if d == nil {
+		return nil, ErrDependenciesAreNil
+	}
+	if d.Controller == nil {
+		return nil, ErrControllerIsNil
+	}
+	if d.Logger == nil {
+		return nil, ErrLoggerIsNil
+	}
+
+	l := d.Logger.WithField(handlersKey, webhookKey)
+	return &Webhook{
+		controller: d.Controller,
+		logger:     l,
+	}, nil
+}
+
+func (h *Webhook) Register(s *grpc.Server) {
+	runbotauthproto.RegisterWebhooksServer(s, h)
+}
+
+// Create subscribes a partner endpoint to the events, the response has the signing secret.
+// The changes of the webhooks are recorded in the audit log, the actor is the admin of the token
+func (h *Webhook) Create(ctx context.Context, model *runbotauthproto.WebhookCreate) (*runbotauthproto.WebhookResponse, error) {
+	actor, err := adminActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := h.controller.Create(ctx, &models.WebhookCreate{
+		URL:        model.URL,
+		EventTypes: model.EventTypes,
+		Actor:      actor,
+		Audit:      auditOf(ctx),
+	})
+	if err != nil {
+		return nil, h.handlerError(ctx, err)
+	}
+	return h.webhookToResponse(result), nil
+}
+
+func (h *Webhook) Get(ctx context.Context, model *runbotauthproto.WebhookGet) (*runbotauthproto.WebhookResponse, error) {
+	if _, err := adminActor(ctx); err != nil {
+		return nil, err
+	}
+
+	result, err := h.controller.GetOne(ctx, model.UUID)
+	if err != nil {
+		return nil, h.handlerError(ctx, err)
+	}
+	return h.webhookToResponse(result), nil
+}
+
+func (h *Webhook) List(ctx context.Context, _ *runbotauthproto.WebhookList) (*runbotauthproto.WebhookListResponse, error) {
+	if _, err := adminActor(ctx); err != nil {
+		return nil, err
+	}
+
+	result, err := h.controller.GetAll(ctx)
+	if err != nil {
+		return nil, h.handlerError(ctx, err)
+	}
+
+	response := &runbotauthproto.WebhookListResponse{
+		Webhooks: make([]*runbotauthproto.WebhookResponse, 0, len(result.Webhooks)),
+	}
+	for _, webhook := range result.Webhooks {
+		response.Webhooks = append(response.Webhooks, h.webhookToResponse(webhook))
+	}
+	return response, nil
+}
+
+// Update replaces the URL, the event types and the activity, the response has the secret if it's rotated
+func (h *Webhook) Update(ctx context.Context, model *runbotauthproto.WebhookUpdate) (*runbotauthproto.WebhookResponse, error) {
+	actor, err := adminActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := h.controller.Update(ctx, &models.WebhookUpdate{
+		UUID:         model.UUID,
+		URL:          model.URL,
+		EventTypes:   model.EventTypes,
+		Active:       model.Active,
+		RotateSecret: model.RotateSecret,
+		Actor:        actor,
+		Audit:        auditOf(ctx),
+	})
+	if err != nil {
+		return nil, h.handlerError(ctx, err)
+	}
+	return h.webhookToResponse(result), nil
+}
+
+// Delete deletes the webhook with its delivery log, the pending deliveries are dropped
+func (h *Webhook) Delete(ctx context.Context, model *runbotauthproto.WebhookDelete) (*runbotauthproto.WebhookDeleteResponse, error) {
+	actor, err := adminActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.controller.Delete(ctx, &models.WebhookDelete{
+		UUID:  model.UUID,
+		Actor: actor,
+		Audit: auditOf(ctx),
+	})
+	if err != nil {
+		return nil, h.handlerError(ctx, err)
+	}
+	return &runbotauthproto.WebhookDeleteResponse{UUID: model.UUID}, nil
+}
+
+// ListDeliveries returns a page of the delivery log, the limit is chosen by the service if it's zero
+func (h *Webhook) ListDeliveries(ctx context.Context, model *runbotauthproto.WebhookDeliveriesSearch) (*runbotauthproto.WebhookDeliveriesResponse, error) {
+	if _, err := adminActor(ctx); err != nil {
+		return nil, err
+	}
+
+	result, err := h.controller.ListDeliveries(ctx, &models.WebhookDeliveriesSearch{
+		WebhookUUID: model.WebhookUUID,
+		Statuses:    model.Statuses,
+		Cursor:      model.Cursor,
+		Limit:       int(model.Limit),
+	})
+	if err != nil {
+		return nil, h.handlerError(ctx, err)
+	}
+
+	response := &runbotauthproto.WebhookDeliveriesResponse{
+		Deliveries: make([]*runbotauthproto.WebhookDelivery, 0, len(result.Deliveries)),
+		NextCursor: result.NextCursor,
+	}
+	for _, delivery := range result.Deliveries {
+		response.Deliveries = append(response.Deliveries, h.webhookDeliveryToResponse(delivery))
+	}
+	return response, nil
+}
+
+// Redeliver sends the delivery again with all the attempts, e.g. a dead one once the endpoint is fixed
+func (h *Webhook) Redeliver(ctx context.Context, model *runbotauthproto.WebhookRedeliver) (*runbotauthproto.WebhookDelivery, error) {
+	actor, err := adminActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := h.controller.Redeliver(ctx, &models.WebhookRedeliver{
+		ID:    model.ID,
+		Actor: actor,
+		Audit: auditOf(ctx),
+	})
+	if err != nil {
+		return nil, h.handlerError(ctx, err)
+	}
+	return h.webhookDeliveryToResponse(result), nil
+}
+
+func (h *Webhook) handlerError(ctx context.Context, err error) error {
+	h.logger.WithContext(ctx).Error(err)
+
+	s := codes.Internal
+
+	switch {
+	case errors.Is(err, validators.ErrUUIDIsNotValid),
+		errors.Is(err, validators.ErrActorIsTooLong),
+		errors.Is(err, validators.ErrWebhookURLIsNotValid),
+		errors.Is(err, validators.ErrDomainEventTypeIsNotValid),
+		errors.Is(err, validators.ErrWebhookDeliveryStatusIsNotValid),
+		errors.Is(err, validators.ErrCursorIsNotValid):
+		s = codes.InvalidArgument
+	case errors.Is(err, usecases.ErrWebhookIsNotFound),
+		errors.Is(err, usecases.ErrWebhookDeliveryIsNotFound):
+		s = codes.NotFound
+	}
+
+	return status.Error(s, err.Error())
+}
+
+func (h *Webhook) webhookToResponse(model *models.Webhook) *runbotauthproto.WebhookResponse {
+	return &runbotauthproto.WebhookResponse{
+		UUID:       model.UUID,
+		URL:        model.URL,
+		Secret:     model.Secret,
+		EventTypes: model.EventTypes,
+		Active:     model.Active,
+		CreatedAt:  model.CreatedAt,
+		UpdatedAt:  model.UpdatedAt,
+	}
+}
+
+func (h *Webhook) webhookDeliveryToResponse(model *models.WebhookDelivery) *runbotauthproto.WebhookDelivery {
+	return &runbotauthproto.WebhookDelivery{
+		ID:             model.ID,
+		WebhookUUID:    model.WebhookUUID,
+		EventUUID:      model.EventUUID,
+		EventType:      model.EventType,
+		Payload:        model.Payload,
+		Status:         model.Status,
+		Attempts:       uint32(model.Attempts),
+		NextAttemptAt:  model.NextAttemptAt,
+		LastStatusCode: uint32(model.LastStatusCode),
+		LastError:      model.LastError,
+		CreatedAt:      model.CreatedAt,
+		UpdatedAt:      model.UpdatedAt,
+	}
+}
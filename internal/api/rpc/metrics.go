@@ -0,0 +1,32 @@
+package rpc
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+type IGRPCRecorder interface {
+	ObserveGRPC(method, code string, duration time.Duration)
+}
+
+// unaryMetrics records the count and the latency of the unary calls
+func unaryMetrics(recorder IGRPCRecorder) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		recorder.ObserveGRPC(info.FullMethod, status.Code(err).String(), time.Since(start))
+		return resp, err
+	}
+}
+
+// streamMetrics records the streams once they're closed, so the latency is the lifetime of the stream
+func streamMetrics(recorder IGRPCRecorder) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		recorder.ObserveGRPC(info.FullMethod, status.Code(err).String(), time.Since(start))
+		return err
+	}
+}
@@ -0,0 +1,51 @@
+package rpc
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"strings"
+)
+
+var (
+	requestIDKey = strings.ToLower(logapp.RequestIDHeader)
+)
+
+// unaryRequestID keeps the x-request-id metadata of the caller or makes a new ID,
+// it's sent back in the header and put into the context of the call
+func unaryRequestID(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	id := incomingRequestID(ctx)
+	// SetHeader fails only if the headers are already sent, the ID is logged anyway
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+	return handler(logapp.WithRequestID(ctx, id), req)
+}
+
+func streamRequestID(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	id := incomingRequestID(ss.Context())
+	_ = ss.SetHeader(metadata.Pairs(requestIDKey, id))
+	return handler(srv, &contextStream{
+		ServerStream: ss,
+		ctx:          logapp.WithRequestID(ss.Context(), id),
+	})
+}
+
+func incomingRequestID(ctx context.Context) string {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDKey); len(values) > 0 {
+			id = values[0]
+		}
+	}
+	return logapp.EnsureRequestID(id)
+}
+
+// contextStream replaces the context of the stream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,48 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestUnaryRequestID(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := logapp.NewLogger(&logapp.Config{Format: logapp.FormatJSON, Output: out})
+	info := &grpc.UnaryServerInfo{FullMethod: "/runbotauth.Account/Get"}
+
+	var got string
+	handler := func(ctx context.Context, _ any) (any, error) {
+		got = logapp.RequestID(ctx)
+		return nil, status.Error(codes.Internal, "failed")
+	}
+	interceptor := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return unaryRequestID(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+			return unaryAccessLog(logger)(ctx, req, info, handler)
+		})
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestIDKey, "req-1"))
+	_, err := interceptor(ctx, nil, info, handler)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, "req-1", got)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &entry))
+	assert.Equal(t, "warning", entry["level"])
+	assert.Equal(t, info.FullMethod, entry["method"])
+	assert.Equal(t, codes.Internal.String(), entry["code"])
+	assert.Equal(t, "req-1", entry[logapp.RequestIDField])
+
+	_, _ = interceptor(context.Background(), nil, info, handler)
+	assert.NotEmpty(t, got)
+	assert.NotEqual(t, "req-1", got)
+}
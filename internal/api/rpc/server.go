@@ -4,12 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/alexsibrin/runbot-auth/internal/adminauth"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"net"
+	"time"
+)
+
+const (
+	DefaultDrainTimeout = 15 * time.Second
 )
 
 var (
-	ErrConfigIsNil = errors.New("config is nil")
+	ErrConfigIsNil   = errors.New("config is nil")
+	ErrDrainTimedOut = errors.New("grpc calls aren't drained in time")
 )
 
 type IServiceRegister interface {
@@ -18,21 +27,63 @@ type IServiceRegister interface {
 
 type Config struct {
 	Port int
+	// Listener replaces the one on Port, e.g. in the tests
+	Listener net.Listener
+	// DrainTimeout is how long the in-flight calls are waited for on the shutdown, then they're cancelled.
+	// DefaultDrainTimeout is used if it isn't positive
+	DrainTimeout time.Duration
+	// Metrics is optional, it records all the calls
+	Metrics IGRPCRecorder
+	// Logger is optional, all the calls are logged with it
+	Logger logapp.ILogger
+	// Tracing starts the spans of the calls with the global tracer provider,
+	// it continues the trace of the traceparent metadata
+	Tracing bool
+	// Admins authenticate the callers passing an admin token in the authorization metadata, they're the actors
+	// of the audited changes. Without them a token is rejected, so the audited changes aren't available
+	Admins *adminauth.Admins
 }
 
 type Server struct {
 	server *grpc.Server
 	config *Config
+	drain  time.Duration
 }
 
 func NewServer(c *Config) (*Server, error) {
 	if c == nil {
 		return nil, ErrConfigIsNil
 	}
-	server := grpc.NewServer()
+	// the request ID goes first, so the other interceptors and the handlers log it
+	unary := []grpc.UnaryServerInterceptor{unaryRequestID}
+	stream := []grpc.StreamServerInterceptor{streamRequestID}
+	if c.Metrics != nil {
+		unary = append(unary, unaryMetrics(c.Metrics))
+		stream = append(stream, streamMetrics(c.Metrics))
+	}
+	if c.Logger != nil {
+		unary = append(unary, unaryAccessLog(c.Logger.WithField("unit", "access")))
+		stream = append(stream, streamAccessLog(c.Logger.WithField("unit", "access")))
+	}
+	// the rejected tokens are logged and counted
+	unary = append(unary, unaryAdmin(c.Admins))
+	stream = append(stream, streamAdmin(c.Admins))
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+	if c.Tracing {
+		opts = append(opts, grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	}
+	server := grpc.NewServer(opts...)
+	drain := c.DrainTimeout
+	if drain <= 0 {
+		drain = DefaultDrainTimeout
+	}
 	return &Server{
 		server: server,
 		config: c,
+		drain:  drain,
 	}, nil
 }
 
@@ -40,14 +91,18 @@ func (s *Server) Add(service IServiceRegister) {
 	service.Register(s.server)
 }
 
+// Run serves until ctx is done, then the in-flight calls are drained
 func (s *Server) Run(ctx context.Context) error {
-	addr := fmt.Sprintf(":%d", s.config.Port)
-	listener, err := net.Listen("tcp", addr)
-	if err != nil {
-		return err
+	listener := s.config.Listener
+	if listener == nil {
+		var err error
+		listener, err = net.Listen("tcp", fmt.Sprintf(":%d", s.config.Port))
+		if err != nil {
+			return err
+		}
 	}
 
-	errchan := make(chan error)
+	errchan := make(chan error, 1)
 
 	go func() {
 		errchan <- s.server.Serve(listener)
@@ -55,9 +110,29 @@ func (s *Server) Run(ctx context.Context) error {
 
 	select {
 	case <-ctx.Done():
-		s.server.GracefulStop()
-		return nil
+		return s.stop()
 	case err := <-errchan:
 		return err
 	}
 }
+
+// stop waits for the in-flight calls up to the drain timeout, then the remaining ones are cancelled
+func (s *Server) stop() error {
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	timer := time.NewTimer(s.drain)
+	defer timer.Stop()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-timer.C:
+		s.server.Stop()
+		<-stopped
+		return ErrDrainTimedOut
+	}
+}
@@ -0,0 +1,232 @@
+package validators
+
+import (
+	"bufio"
+	"errors"
+	"golang.org/x/net/idna"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// RFC 5321 4.5.3.1: limits are measured in octets
+	emailMaxLength      = 254
+	emailLocalMaxLength = 64
+	domainMaxLength     = 253
+	domainLabelMaxLen   = 63
+
+	// RFC 5322 specials which are not allowed in an unquoted local part
+	emailLocalSpecials = `()<>[]:;@\,"`
+)
+
+var (
+	ErrEmailIsTooLong            = errors.New("email is too long")
+	ErrEmailDomainIsNotAllowed   = errors.New("email domain is not allowed")
+	ErrEmailBlocklistFileIsEmpty = errors.New("email blocklist file path is empty")
+)
+
+var emailIDNA = idna.New(
+	idna.MapForLookup(),
+	idna.BidiRule(),
+	idna.Transitional(false),
+)
+
+// NormalizeEmail validates the address according to RFC 5321 with the
+// RFC 6531 extension (UTF-8 local parts and internationalized domains)
+// and returns its canonical form: the local part is lowercased and the
+// domain is converted to lowercase ASCII (punycode)
+func NormalizeEmail(e string) (string, error) {
+	e = strings.TrimSpace(e)
+	if len(e) < emailMinLength {
+		return "", ErrEmailIsTooShort
+	}
+	if len(e) > emailMaxLength {
+		return "", ErrEmailIsTooLong
+	}
+
+	at := strings.LastIndexByte(e, '@')
+	if at <= 0 || at == len(e)-1 {
+		return "", ErrEmailFormatIsNotCorrect
+	}
+
+	local, err := normalizeEmailLocal(e[:at])
+	if err != nil {
+		return "", err
+	}
+
+	domain, err := NormalizeDomain(e[at+1:])
+	if err != nil {
+		return "", err
+	}
+
+	normalized := local + "@" + domain
+	if len(normalized) > emailMaxLength {
+		return "", ErrEmailIsTooLong
+	}
+
+	return normalized, nil
+}
+
+// NormalizeDomain converts an internationalized domain name to its
+// lowercase ASCII form and checks it is a fully qualified host name
+func NormalizeDomain(d string) (string, error) {
+	d = strings.TrimSuffix(d, ".")
+	if d == "" {
+		return "", ErrEmailFormatIsNotCorrect
+	}
+
+	ascii, err := emailIDNA.ToASCII(d)
+	if err != nil {
+		return "", ErrEmailFormatIsNotCorrect
+	}
+	ascii = strings.ToLower(ascii)
+	if len(ascii) > domainMaxLength {
+		return "", ErrEmailFormatIsNotCorrect
+	}
+
+	labels := strings.Split(ascii, ".")
+	if len(labels) < 2 {
+		return "", ErrEmailFormatIsNotCorrect
+	}
+
+	for _, label := range labels {
+		if !isDomainLabel(label) {
+			return "", ErrEmailFormatIsNotCorrect
+		}
+	}
+
+	if isNumeric(labels[len(labels)-1]) {
+		return "", ErrEmailFormatIsNotCorrect
+	}
+
+	return ascii, nil
+}
+
+func normalizeEmailLocal(local string) (string, error) {
+	if len(local) > emailLocalMaxLength {
+		return "", ErrEmailIsTooLong
+	}
+	if !utf8.ValidString(local) {
+		return "", ErrEmailFormatIsNotCorrect
+	}
+	if strings.HasPrefix(local, ".") || strings.HasSuffix(local, ".") || strings.Contains(local, "..") {
+		return "", ErrEmailFormatIsNotCorrect
+	}
+
+	for _, r := range local {
+		switch {
+		case r < utf8.RuneSelf && (r <= ' ' || r == 0x7f):
+			return "", ErrEmailFormatIsNotCorrect
+		case strings.ContainsRune(emailLocalSpecials, r):
+			return "", ErrEmailFormatIsNotCorrect
+		case r >= utf8.RuneSelf && !unicode.IsGraphic(r):
+			return "", ErrEmailFormatIsNotCorrect
+		}
+	}
+
+	return strings.ToLower(local), nil
+}
+
+func isDomainLabel(label string) bool {
+	if label == "" || len(label) > domainLabelMaxLen {
+		return false
+	}
+	if label[0] == '-' || label[len(label)-1] == '-' {
+		return false
+	}
+	for i := 0; i < len(label); i++ {
+		c := label[i]
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+func isNumeric(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// EmailDomainBlocklist rejects addresses from the listed domains and their subdomains,
+// it's used to block disposable mailboxes
+type EmailDomainBlocklist struct {
+	domains map[string]struct{}
+}
+
+func NewEmailDomainBlocklist(domains []string) (*EmailDomainBlocklist, error) {
+	b := &EmailDomainBlocklist{
+		domains: make(map[string]struct{}, len(domains)),
+	}
+	for _, d := range domains {
+		d = strings.TrimSpace(d)
+		if d == "" {
+			continue
+		}
+		normalized, err := NormalizeDomain(d)
+		if err != nil {
+			return nil, err
+		}
+		b.domains[normalized] = struct{}{}
+	}
+	return b, nil
+}
+
+// LoadEmailDomainBlocklist reads domains from the file, one per line.
+// Empty lines and lines started with # are skipped
+func LoadEmailDomainBlocklist(path string, extra []string) (*EmailDomainBlocklist, error) {
+	if path == "" {
+		return nil, ErrEmailBlocklistFileIsEmpty
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	domains := append([]string{}, extra...)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		domains = append(domains, line)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewEmailDomainBlocklist(domains)
+}
+
+// Check expects the normalized email
+func (b *EmailDomainBlocklist) Check(email string) error {
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return ErrEmailFormatIsNotCorrect
+	}
+
+	domain := email[at+1:]
+	for {
+		if _, ok := b.domains[domain]; ok {
+			return ErrEmailDomainIsNotAllowed
+		}
+		dot := strings.IndexByte(domain, '.')
+		if dot < 0 {
+			return nil
+		}
+		domain = domain[dot+1:]
+	}
+}
+
+func (b *EmailDomainBlocklist) Len() int {
+	return len(b.domains)
+}
@@ -0,0 +1,163 @@
+package validators
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	testCases := []struct {
+		name        string
+		in          string
+		out         string
+		expectedErr error
+	}{
+		{
+			name: "Regular email",
+			in:   "test@test.ru",
+			out:  "test@test.ru",
+		},
+		{
+			name: "Mixed case is lowercased",
+			in:   "Bob@Example.COM",
+			out:  "bob@example.com",
+		},
+		{
+			name: "Plus alias",
+			in:   "bob+runbot@example.com",
+			out:  "bob+runbot@example.com",
+		},
+		{
+			name: "Long TLD",
+			in:   "bob@example.technology",
+			out:  "bob@example.technology",
+		},
+		{
+			name: "Surrounding spaces are trimmed",
+			in:   "  bob@example.com ",
+			out:  "bob@example.com",
+		},
+		{
+			name: "IDN domain is converted to punycode",
+			in:   "bob@пример.рф",
+			out:  "bob@xn--e1afmkfd.xn--p1ai",
+		},
+		{
+			name: "UTF-8 local part",
+			in:   "Пользователь@example.com",
+			out:  "пользователь@example.com",
+		},
+		{
+			name:        "Too short",
+			in:          "a@b",
+			expectedErr: ErrEmailIsTooShort,
+		},
+		{
+			name:        "Without at",
+			in:          "test33",
+			expectedErr: ErrEmailFormatIsNotCorrect,
+		},
+		{
+			name:        "Domain without TLD",
+			in:          "some@ru",
+			expectedErr: ErrEmailFormatIsNotCorrect,
+		},
+		{
+			name:        "Numeric TLD",
+			in:          "some@127.0.0.1",
+			expectedErr: ErrEmailFormatIsNotCorrect,
+		},
+		{
+			name:        "Double dot in local part",
+			in:          "bo..b@example.com",
+			expectedErr: ErrEmailFormatIsNotCorrect,
+		},
+		{
+			name:        "Space in local part",
+			in:          "bo b@example.com",
+			expectedErr: ErrEmailFormatIsNotCorrect,
+		},
+		{
+			name:        "Display name is not accepted",
+			in:          "Bob <bob@example.com>",
+			expectedErr: ErrEmailFormatIsNotCorrect,
+		},
+		{
+			name:        "Label started with hyphen",
+			in:          "bob@-example.com",
+			expectedErr: ErrEmailFormatIsNotCorrect,
+		},
+		{
+			name:        "Too long local part",
+			in:          strings.Repeat("a", 65) + "@example.com",
+			expectedErr: ErrEmailIsTooLong,
+		},
+		{
+			name:        "Too long email",
+			in:          "bob@" + strings.Repeat(strings.Repeat("a", 60)+".", 5) + "com",
+			expectedErr: ErrEmailIsTooLong,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := NormalizeEmail(tc.in)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.out, out)
+			}
+		})
+	}
+}
+
+func TestEmailDomainBlocklist(t *testing.T) {
+	blocklist, err := NewEmailDomainBlocklist([]string{"Mailinator.com", "", "почта.рф"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, blocklist.Len())
+
+	testCases := []struct {
+		name        string
+		in          string
+		expectedErr error
+	}{
+		{
+			name:        "Allowed domain",
+			in:          "bob@example.com",
+			expectedErr: nil,
+		},
+		{
+			name:        "Blocked domain",
+			in:          "bob@mailinator.com",
+			expectedErr: ErrEmailDomainIsNotAllowed,
+		},
+		{
+			name:        "Subdomain of a blocked domain",
+			in:          "bob@eu.mailinator.com",
+			expectedErr: ErrEmailDomainIsNotAllowed,
+		},
+		{
+			name:        "Blocked IDN domain",
+			in:          "bob@xn--80a1acny.xn--p1ai",
+			expectedErr: ErrEmailDomainIsNotAllowed,
+		},
+		{
+			name:        "Similar domain is allowed",
+			in:          "bob@notmailinator.com",
+			expectedErr: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := blocklist.Check(tc.in)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -3,8 +3,15 @@ package validators
 import (
 	"errors"
 	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/hasher"
 	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"net/netip"
+	"net/url"
 	"regexp"
+	"slices"
+	"strconv"
+	"unicode/utf8"
 )
 
 const (
@@ -12,10 +19,16 @@ const (
 	pswdMinLength  = 8
 	nameMinLength  = 4
 
-	emailRegexp = `^[\w-\.]+@([\w-]+\.)+[\w-]{2,4}$`
+	statusReasonMaxLength = 512
+	actorMaxLength        = 256
+	webhookURLMaxLength   = 2048
+
 	// FIXME: reg is not correct
 	pswdRegexp = `^[A-Za-z0-9].{8,}$`
 	nameRegexp = `^[a-zA-Z0-9]{4,30}$`
+
+	// argon2Regexp is the PHC format of the argon2 hashes, e.g. $argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>
+	argon2Regexp = `^\$argon2(id|i)\$v=19\$m=[1-9][0-9]*,t=[1-9][0-9]*,p=[1-9][0-9]*\$[A-Za-z0-9+/]+\$[A-Za-z0-9+/]+$`
 )
 
 var (
@@ -24,26 +37,35 @@ var (
 
 	ErrPasswordIsTooShort         = errors.New("password is too short")
 	ErrPasswordFormatIsNotCorrect = errors.New("password format is not correct")
+	ErrPasswordHashIsNotValid     = errors.New("password hash is not bcrypt or argon2")
 
 	ErrNameIsTooShort         = errors.New("name is too short")
 	ErrNameFormatIsNotCorrect = errors.New("name format is not correct")
 
 	ErrUUIDIsNotValid   = errors.New("UUID is not valid")
 	ErrStatusIsNotValid = errors.New("status is not valid")
+
+	ErrStatusReasonIsTooLong = errors.New("status reason is too long")
+	ErrStatusUntilIsNotValid = errors.New("status expiration is not valid")
+	ErrActorIsTooLong        = errors.New("actor is too long")
+
+	ErrCursorIsNotValid            = errors.New("cursor is not valid")
+	ErrLimitIsNotValid             = errors.New("limit is not valid")
+	ErrSecurityEventTypeIsNotValid = errors.New("security event type is not valid")
+	ErrIPIsNotValid                = errors.New("IP is not valid")
+	ErrTimeRangeIsNotValid         = errors.New("time range is not valid")
+	ErrAccountSortIsNotValid       = errors.New("account sort is not valid")
+
+	ErrWebhookURLIsNotValid            = errors.New("webhook URL is not valid")
+	ErrDomainEventTypeIsNotValid       = errors.New("event type is not valid")
+	ErrWebhookDeliveryStatusIsNotValid = errors.New("webhook delivery status is not valid")
 )
 
+var argon2Hash = regexp.MustCompile(argon2Regexp)
+
 func Email(e string) error {
-	if len(e) < emailMinLength {
-		return ErrEmailIsTooShort
-	}
-	matched, err := regexp.Match(emailRegexp, []byte(e))
-	if err != nil {
-		return err
-	}
-	if !matched {
-		return ErrEmailFormatIsNotCorrect
-	}
-	return nil
+	_, err := NormalizeEmail(e)
+	return err
 }
 
 func Password(pswd string) error {
@@ -60,6 +82,21 @@ func Password(pswd string) error {
 	return nil
 }
 
+// PasswordHash checks a hash of the imported account is bcrypt or argon2 with the bounded parameters.
+// The empty hash makes the owner request a password reset link by email
+func PasswordHash(hash string) error {
+	if hash == "" {
+		return nil
+	}
+	if _, err := bcrypt.Cost([]byte(hash)); err == nil {
+		return nil
+	}
+	if argon2Hash.MatchString(hash) && hasher.CheckArgon2(hash) == nil {
+		return nil
+	}
+	return ErrPasswordHashIsNotValid
+}
+
 func Name(n string) error {
 	if len(n) < nameMinLength {
 		return ErrNameIsTooShort
@@ -89,3 +126,87 @@ func AccountStatus(status uint8) error {
 		return ErrStatusIsNotValid
 	}
 }
+
+func StatusReason(reason string) error {
+	if utf8.RuneCountInString(reason) > statusReasonMaxLength {
+		return ErrStatusReasonIsTooLong
+	}
+	return nil
+}
+
+// StatusUntil checks the expiration of a temporary status, zero until is a permanent status.
+// Only a suspension or a block can be temporary and it must expire in the future
+func StatusUntil(status uint8, until, now int64) error {
+	if until == 0 {
+		return nil
+	}
+	if status == entities.Active || until <= now {
+		return ErrStatusUntilIsNotValid
+	}
+	return nil
+}
+
+func Actor(actor string) error {
+	if utf8.RuneCountInString(actor) > actorMaxLength {
+		return ErrActorIsTooLong
+	}
+	return nil
+}
+
+// Cursor parses the cursor of a paginated listing, the empty cursor is the first page
+func Cursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(cursor, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, ErrCursorIsNotValid
+	}
+	return id, nil
+}
+
+// Limit parses the page size of a paginated listing, the empty limit is chosen by the usecase
+func Limit(limit string) (int, error) {
+	if limit == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(limit)
+	if err != nil || n < 0 {
+		return 0, ErrLimitIsNotValid
+	}
+	return n, nil
+}
+
+func IP(ip string) error {
+	if _, err := netip.ParseAddr(ip); err != nil {
+		return ErrIPIsNotValid
+	}
+	return nil
+}
+
+// TimeRange checks the [since, until) range, the zero bounds are open
+func TimeRange(since, until int64) error {
+	if since < 0 || until < 0 || (until != 0 && until <= since) {
+		return ErrTimeRangeIsNotValid
+	}
+	return nil
+}
+
+// WebhookURL checks the endpoint of a webhook is an absolute http(s) URL
+func WebhookURL(webhookurl string) error {
+	if len(webhookurl) > webhookURLMaxLength {
+		return ErrWebhookURLIsNotValid
+	}
+	u, err := url.Parse(webhookurl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrWebhookURLIsNotValid
+	}
+	return nil
+}
+
+func DomainEventType(eventtype string) error {
+	if !slices.Contains(entities.DomainEventTypes, eventtype) {
+		return ErrDomainEventTypeIsNotValid
+	}
+	return nil
+}
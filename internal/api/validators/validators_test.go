@@ -0,0 +1,151 @@
+package validators
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestStatusUntil(t *testing.T) {
+	const now = 1000
+
+	testCases := []struct {
+		name        string
+		status      uint8
+		until       int64
+		expectedErr error
+	}{
+		{name: "Permanent suspension", status: entities.Suspended, until: 0},
+		{name: "Temporary suspension", status: entities.Suspended, until: now + 1},
+		{name: "Temporary block", status: entities.Blocked, until: now + 1},
+		{name: "Permanent activation", status: entities.Active, until: 0},
+		{name: "Temporary activation", status: entities.Active, until: now + 1, expectedErr: ErrStatusUntilIsNotValid},
+		{name: "Until is now", status: entities.Suspended, until: now, expectedErr: ErrStatusUntilIsNotValid},
+		{name: "Until is in the past", status: entities.Suspended, until: now - 1, expectedErr: ErrStatusUntilIsNotValid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ErrorIs(t, StatusUntil(tc.status, tc.until, now), tc.expectedErr)
+		})
+	}
+}
+
+func TestStatusReason(t *testing.T) {
+	assert.NoError(t, StatusReason(""))
+	assert.NoError(t, StatusReason(strings.Repeat("ж", statusReasonMaxLength)))
+	assert.ErrorIs(t, StatusReason(strings.Repeat("a", statusReasonMaxLength+1)), ErrStatusReasonIsTooLong)
+}
+
+func TestCursor(t *testing.T) {
+	testCases := []struct {
+		name        string
+		in          string
+		out         int64
+		expectedErr error
+	}{
+		{name: "First page", in: "", out: 0},
+		{name: "Valid cursor", in: "42", out: 42},
+		{name: "Zero cursor", in: "0", expectedErr: ErrCursorIsNotValid},
+		{name: "Negative cursor", in: "-1", expectedErr: ErrCursorIsNotValid},
+		{name: "Not a number", in: "abc", expectedErr: ErrCursorIsNotValid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			id, err := Cursor(tc.in)
+			assert.Equal(t, tc.out, id)
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestLimit(t *testing.T) {
+	testCases := []struct {
+		name        string
+		in          string
+		out         int
+		expectedErr error
+	}{
+		{name: "Default limit", in: "", out: 0},
+		{name: "Valid limit", in: "10", out: 10},
+		{name: "Negative limit", in: "-1", expectedErr: ErrLimitIsNotValid},
+		{name: "Not a number", in: "ten", expectedErr: ErrLimitIsNotValid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			limit, err := Limit(tc.in)
+			assert.Equal(t, tc.out, limit)
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestTimeRange(t *testing.T) {
+	assert.NoError(t, TimeRange(0, 0))
+	assert.NoError(t, TimeRange(100, 0))
+	assert.NoError(t, TimeRange(0, 100))
+	assert.NoError(t, TimeRange(100, 101))
+	assert.ErrorIs(t, TimeRange(100, 100), ErrTimeRangeIsNotValid)
+	assert.ErrorIs(t, TimeRange(-1, 0), ErrTimeRangeIsNotValid)
+}
+
+func TestWebhookURL(t *testing.T) {
+	testCases := []struct {
+		name        string
+		in          string
+		expectedErr error
+	}{
+		{name: "HTTPS URL", in: "https://partner.example.com/hooks?source=runbot"},
+		{name: "HTTP URL with a port", in: "http://10.0.0.1:8080/hooks"},
+		{name: "Empty URL", in: "", expectedErr: ErrWebhookURLIsNotValid},
+		{name: "Relative URL", in: "/hooks", expectedErr: ErrWebhookURLIsNotValid},
+		{name: "Other scheme", in: "ftp://partner.example.com/hooks", expectedErr: ErrWebhookURLIsNotValid},
+		{name: "No host", in: "https:///hooks", expectedErr: ErrWebhookURLIsNotValid},
+		{name: "Too long URL", in: "https://partner.example.com/" + strings.Repeat("a", webhookURLMaxLength), expectedErr: ErrWebhookURLIsNotValid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ErrorIs(t, WebhookURL(tc.in), tc.expectedErr)
+		})
+	}
+}
+
+func TestDomainEventType(t *testing.T) {
+	for _, eventtype := range entities.DomainEventTypes {
+		assert.NoError(t, DomainEventType(eventtype))
+	}
+	assert.ErrorIs(t, DomainEventType("account.unknown"), ErrDomainEventTypeIsNotValid)
+	assert.ErrorIs(t, DomainEventType(""), ErrDomainEventTypeIsNotValid)
+}
+
+func TestPasswordHash(t *testing.T) {
+	testCases := []struct {
+		name        string
+		in          string
+		expectedErr error
+	}{
+		{name: "Empty hash", in: ""},
+		{name: "bcrypt", in: "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"},
+		{name: "argon2id", in: "$argon2id$v=19$m=65536,t=3,p=4$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG"},
+		{name: "argon2i", in: "$argon2i$v=19$m=4096,t=3,p=1$c29tZXNhbHQ$aGFzaA"},
+		{name: "Plain password", in: "password123", expectedErr: ErrPasswordHashIsNotValid},
+		{name: "Truncated bcrypt", in: "$2a$10$N9qo8uLOickgx2ZMRZoMye", expectedErr: ErrPasswordHashIsNotValid},
+		{name: "Old argon2 version", in: "$argon2id$v=16$m=65536,t=3,p=4$c29tZXNhbHQ$aGFzaA", expectedErr: ErrPasswordHashIsNotValid},
+		{name: "argon2d", in: "$argon2d$v=19$m=65536,t=3,p=4$c29tZXNhbHQ$aGFzaA", expectedErr: ErrPasswordHashIsNotValid},
+		{name: "argon2 with too much memory", in: "$argon2id$v=19$m=4194304,t=3,p=4$c29tZXNhbHQ$aGFzaA", expectedErr: ErrPasswordHashIsNotValid},
+		{name: "argon2 with too many iterations", in: "$argon2id$v=19$m=65536,t=1000,p=4$c29tZXNhbHQ$aGFzaA", expectedErr: ErrPasswordHashIsNotValid},
+		{name: "argon2 with too many threads", in: "$argon2id$v=19$m=65536,t=3,p=64$c29tZXNhbHQ$aGFzaA", expectedErr: ErrPasswordHashIsNotValid},
+		{name: "argon2 with an overflowing parameter", in: "$argon2id$v=19$m=99999999999,t=3,p=4$c29tZXNhbHQ$aGFzaA", expectedErr: ErrPasswordHashIsNotValid},
+		{name: "MD5 crypt", in: "$1$somesalt$somehash", expectedErr: ErrPasswordHashIsNotValid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ErrorIs(t, PasswordHash(tc.in), tc.expectedErr)
+		})
+	}
+}
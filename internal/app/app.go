@@ -0,0 +1,301 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/adminauth"
+	"github.com/alexsibrin/runbot-auth/internal/api/rest"
+	"github.com/alexsibrin/runbot-auth/internal/api/rpc"
+	"github.com/alexsibrin/runbot-auth/internal/config"
+	"github.com/alexsibrin/runbot-auth/internal/health"
+	"github.com/alexsibrin/runbot-auth/internal/jwtapp"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/alexsibrin/runbot-auth/internal/mailer"
+	"github.com/alexsibrin/runbot-auth/internal/metrics"
+	"github.com/alexsibrin/runbot-auth/internal/tracing"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	// the purposes of the URL signing secrets derived from Jwt.Salt when they aren't configured
+	exportURLSecretPurpose = "export download url"
+	linkSecretPurpose      = "login alert link"
+)
+
+var (
+	ErrConfigIsNil = errors.New("config is nil")
+)
+
+// pinger is the publisher which can check the broker is reachable
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Dependencies of the app, the config and the logger are required.
+// The other ones replace the parts built from the config, e.g. in the integration tests
+type Dependencies struct {
+	Config *config.Config
+	Logger logapp.ILogger
+	// Storage is closed by the caller
+	Storage   *Storage
+	Publisher usecases.IPublisher
+	Mailer    usecases.IMailer
+	// RestListener and GRPCListener replace the listeners on the configured ports
+	RestListener net.Listener
+	GRPCListener net.Listener
+}
+
+// App is the servers and the workers of the service with their dependencies
+type App struct {
+	lifecycle *Lifecycle
+}
+
+// New builds the app, the parts which are already opened are closed if it fails
+func New(ctx context.Context, d *Dependencies) (*App, error) {
+	if d == nil {
+		return nil, ErrDependenciesAreNil
+	}
+	if d.Config == nil {
+		return nil, ErrConfigIsNil
+	}
+	if d.Logger == nil {
+		return nil, ErrLoggerIsNil
+	}
+
+	lifecycle, err := NewLifecycle(&LifecycleDependencies{
+		Logger: d.Logger,
+		Config: &LifecycleConfig{
+			StopTimeout: d.Config.Shutdown.StopTimeout,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a := &App{
+		lifecycle: lifecycle,
+	}
+	if err = a.build(ctx, d); err != nil {
+		return nil, errors.Join(err, lifecycle.Close())
+	}
+	return a, nil
+}
+
+// Run starts the app and stops it once ctx is done or any of its parts fails
+func (a *App) Run(ctx context.Context) error {
+	return a.lifecycle.Run(ctx)
+}
+
+// Close releases the app which isn't run
+func (a *App) Close() error {
+	return a.lifecycle.Close()
+}
+
+// parts of the app shared by its subsystems
+type parts struct {
+	conf    *config.Config
+	logger  logapp.ILogger
+	store   *Storage
+	metrics *appMetrics
+	// tracing tells the servers to trace the requests
+	tracing bool
+	appsec  *jwtapp.JwtWrapper
+	// admins is nil without the admin tokens
+	admins    *adminauth.Admins
+	checker   *health.Checker
+	readiness *health.Readiness
+}
+
+// appMetrics are the recorders of the parts, they're nil if the metrics are disabled
+type appMetrics struct {
+	app     *metrics.Metrics
+	usecase usecases.IMetrics
+	http    http.Handler
+	grpc    rpc.IGRPCRecorder
+}
+
+// build wires the app, the subsystems append their components in the order they're started
+func (a *App) build(ctx context.Context, d *Dependencies) error {
+	conf := d.Config
+
+	tracingenabled, err := a.buildTracing(ctx, conf)
+	if err != nil {
+		return err
+	}
+
+	store, err := a.buildStorage(ctx, d)
+	if err != nil {
+		return err
+	}
+
+	appmetrics, err := newAppMetrics(conf, store)
+	if err != nil {
+		return err
+	}
+
+	// the admin REST API and the audited admin RPCs are disabled without the admin tokens
+	var admins *adminauth.Admins
+	if len(conf.Admin.Tokens) > 0 {
+		admins, err = adminauth.New(conf.Admin.Tokens)
+		if err != nil {
+			return err
+		}
+	}
+
+	p := &parts{
+		conf:    conf,
+		logger:  d.Logger,
+		store:   store,
+		metrics: appmetrics,
+		tracing: tracingenabled,
+		appsec:  NewJwt(conf),
+		admins:  admins,
+		// the app is ready once it runs
+		readiness: health.NewReadiness(conf.Shutdown.ReadinessDelay),
+		// the readiness probes check the dependencies, the broker one is added along with the publisher
+		checker: health.NewChecker(&health.CheckerConfig{
+			Timeout: conf.HealthCheck.Timeout,
+		}),
+	}
+	p.checker.Add("readiness", p.readiness.Check)
+	p.checker.Add("storage", store.Ping)
+	p.checker.Add("signing keys", func(context.Context) error {
+		return p.appsec.CheckKeys()
+	})
+
+	uc, err := newUsecases(p, d.Mailer)
+	if err != nil {
+		return err
+	}
+
+	ctrl, err := newControllers(p, uc)
+	if err != nil {
+		return err
+	}
+
+	// the workers go before the servers, so the servers are stopped first
+	if err = a.buildWorkers(p, uc, d.Publisher); err != nil {
+		return err
+	}
+
+	if err = a.buildREST(p, ctrl, d.RestListener); err != nil {
+		return err
+	}
+
+	grpchealth, err := a.buildGRPC(p, ctrl, uc, d.GRPCListener)
+	if err != nil {
+		return err
+	}
+
+	// the readiness is stopped first, the servers keep serving while the load balancers notice it
+	a.lifecycle.Append(&Component{
+		Name:        "readiness",
+		Run:         p.readiness.Run,
+		StopTimeout: conf.Shutdown.ReadinessDelay + a.lifecycle.stoptimeout,
+	})
+
+	// the grpc health service reports the services aren't serving as soon as the app stops
+	a.lifecycle.Append(&Component{Name: "grpc health", Run: grpchealth.Run})
+
+	return nil
+}
+
+// buildTracing sets up the tracing, it's stopped last, so the spans of the other parts are flushed.
+// It tells whether the spans are exported
+func (a *App) buildTracing(ctx context.Context, conf *config.Config) (bool, error) {
+	traces, err := tracing.New(ctx, &tracing.Config{
+		Exporter:    conf.Tracing.Exporter,
+		Endpoint:    conf.Tracing.Endpoint,
+		Insecure:    conf.Tracing.Insecure,
+		SampleRatio: conf.Tracing.SampleRatio,
+		Version:     conf.Common.Version,
+	})
+	if err != nil {
+		return false, err
+	}
+	a.lifecycle.Append(&Component{
+		Name: "tracing",
+		Stop: traces.Shutdown,
+	})
+	return conf.Tracing.Exporter != tracing.ExporterNone, nil
+}
+
+// newAppMetrics registers the metrics if they're enabled
+func newAppMetrics(conf *config.Config, store *Storage) (*appMetrics, error) {
+	if !conf.Metrics.Enabled {
+		return &appMetrics{}, nil
+	}
+
+	m := metrics.New()
+	if err := registerDB(m, store); err != nil {
+		return nil, err
+	}
+	return &appMetrics{
+		app:     m,
+		usecase: m,
+		http:    m.Handler(),
+		grpc:    m,
+	}, nil
+}
+
+// serverStopTimeout lets a server drain the requests before it stops
+func (a *App) serverStopTimeout(conf *config.Config) time.Duration {
+	drain := conf.Shutdown.DrainTimeout
+	if drain <= 0 {
+		drain = rest.DefaultDrainTimeout
+	}
+	return drain + a.lifecycle.stoptimeout
+}
+
+// registerDB exports the connection pool stats of the storage, the memory one has no pool
+func registerDB(m *metrics.Metrics, store *Storage) error {
+	switch {
+	case store.Postgres != nil:
+		return m.RegisterDB(storagePostgres, store.Postgres)
+	case store.SQLite != nil:
+		return m.RegisterDB(storageSQLite, store.SQLite)
+	}
+	return nil
+}
+
+// newMailer returns the SMTP mailer, the emails are only logged without an SMTP server
+func newMailer(conf *config.Config, logger logapp.ILogger) (usecases.IMailer, error) {
+	if conf.Mail.Host == "" {
+		return mailer.NewLog(logger)
+	}
+	return mailer.NewSMTP(&mailer.Config{
+		Host:     conf.Mail.Host,
+		Port:     conf.Mail.Port,
+		Username: conf.Mail.Username,
+		Password: conf.Mail.Password,
+		From:     conf.Mail.From,
+		Timeout:  conf.Mail.Timeout,
+	})
+}
+
+// NewJwt is the token issuer of the config
+func NewJwt(conf *config.Config) *jwtapp.JwtWrapper {
+	return jwtapp.New(&jwtapp.Config{
+		Salt:          conf.Jwt.Salt,
+		PreviousSalts: conf.Jwt.PreviousSalts,
+		Issuer:        conf.Jwt.Issuer,
+		Subject:       conf.Jwt.Subject,
+		Audience:      conf.Jwt.Audience,
+		ExpiresIn:     conf.Jwt.ExpiresIn,
+	})
+}
+
+// NewSessionUsecase builds the session usecase, the sessions live as long as their refresh tokens.
+// The metrics are optional
+func NewSessionUsecase(conf *config.Config, store *Storage, m usecases.IMetrics) (*usecases.Session, error) {
+	return usecases.NewSession(&usecases.SessionDependencies{
+		Repo:           store.Session,
+		SecurityEvents: store.SecurityEvent,
+		Transactor:     store.Transactor,
+		TTL:            NewJwt(conf).RefreshExpiresIn(),
+		Metrics:        m,
+	})
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/config"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	logger := logapp.NewLogger(&logapp.Config{})
+
+	_, err := New(context.Background(), nil)
+	assert.ErrorIs(t, err, ErrDependenciesAreNil)
+
+	_, err = New(context.Background(), &Dependencies{Logger: logger})
+	assert.ErrorIs(t, err, ErrConfigIsNil)
+
+	_, err = New(context.Background(), &Dependencies{Config: &config.Config{}})
+	assert.ErrorIs(t, err, ErrLoggerIsNil)
+
+	conf := testConfig()
+	conf.Storage.Driver = "unknown"
+	_, err = New(context.Background(), &Dependencies{Config: conf, Logger: logger})
+	assert.ErrorIs(t, err, ErrStorageDriverIsUnknown)
+}
+
+func TestApp_Run(t *testing.T) {
+	restlistener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpclistener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	service, err := New(context.Background(), &Dependencies{
+		Config:       testConfig(),
+		Logger:       logapp.NewLogger(&logapp.Config{}),
+		RestListener: restlistener,
+		GRPCListener: grpclistener,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() {
+		result <- service.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		response, err := http.Get("http://" + restlistener.Addr().String() + "/v1/health")
+		if err != nil {
+			return false
+		}
+		response.Body.Close()
+		return response.StatusCode == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond)
+
+	response, err := http.Get("http://" + restlistener.Addr().String() + "/readyz")
+	require.NoError(t, err)
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Contains(t, string(body), `"name":"storage","status":"ok"`)
+	assert.NotEmpty(t, response.Header.Get(logapp.RequestIDHeader))
+
+	conn, err := grpc.Dial(grpclistener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	healthclient := healthpb.NewHealthClient(conn)
+	require.Eventually(t, func() bool {
+		status, err := healthclient.Check(ctx, &healthpb.HealthCheckRequest{Service: "Account"})
+		return err == nil && status.Status == healthpb.HealthCheckResponse_SERVING
+	}, 5*time.Second, 10*time.Millisecond)
+
+	response, err = http.Get("http://" + restlistener.Addr().String() + "/metrics")
+	require.NoError(t, err)
+	body, err = io.ReadAll(response.Body)
+	response.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Contains(t, string(body), `runbot_auth_http_requests_total{code="200",method="GET",route="/readyz"} 1`)
+	assert.Contains(t, string(body), `runbot_auth_grpc_requests_total{code="OK",method="/grpc.health.v1.Health/Check"}`)
+
+	cancel()
+	select {
+	case err = <-result:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("app isn't stopped")
+	}
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Storage: config.Storage{Driver: storageMemory},
+		Jwt: config.Jwt{
+			Salt:      "somesalt",
+			ExpiresIn: time.Minute,
+		},
+		Common: config.Common{
+			Version: "test",
+			Health:  "ok",
+		},
+		HealthCheck: config.HealthCheck{
+			Interval: 10 * time.Millisecond,
+		},
+		Metrics: config.Metrics{
+			Enabled: true,
+		},
+	}
+}
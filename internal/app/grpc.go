@@ -0,0 +1,85 @@
+package app
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/api/rpc"
+	handlersrpc "github.com/alexsibrin/runbot-auth/internal/api/rpc/handlers"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/alexsibrin/runbot-auth/pkg/runbotauthproto"
+	"net"
+)
+
+// buildGRPC appends the gRPC server, it listens on the configured port unless the listener is given.
+// The health service is returned, it's run apart from the server, so it reports the stop before the server drains
+func (a *App) buildGRPC(p *parts, ctrl *appControllers, uc *appUsecases, listener net.Listener) (*handlersrpc.Health, error) {
+	conf, logger := p.conf, p.logger
+
+	accountrpchandlers, err := handlersrpc.NewAccount(&handlersrpc.AccountDependencies{
+		Controller:              ctrl.account,
+		SecurityEventController: ctrl.securityevent,
+		AuditLogController:      ctrl.auditlog,
+		WatchController:         ctrl.accountwatch,
+		Logger:                  logger,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	webhookrpchandlers, err := handlersrpc.NewWebhook(&handlersrpc.WebhookDependencies{
+		Controller: ctrl.webhook,
+		Logger:     logger,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	healthrpchandlers, err := handlersrpc.NewHealth(&handlersrpc.HealthDependencies{
+		Checker: p.checker,
+		Logger:  logger,
+		Config: &handlersrpc.HealthConfig{
+			Interval: conf.HealthCheck.Interval,
+			Services: []string{
+				runbotauthproto.Account_ServiceDesc.ServiceName,
+				runbotauthproto.Webhooks_ServiceDesc.ServiceName,
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var accesslogger logapp.ILogger
+	if conf.Logger.AccessLog {
+		accesslogger = logger
+	}
+
+	grpcserver, err := rpc.NewServer(&rpc.Config{
+		Port:         conf.GRPCServer.Port,
+		Listener:     listener,
+		DrainTimeout: conf.Shutdown.DrainTimeout,
+		Metrics:      p.metrics.grpc,
+		Logger:       accesslogger,
+		Tracing:      p.tracing,
+		Admins:       p.admins,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	grpcserver.Add(accountrpchandlers)
+	grpcserver.Add(webhookrpchandlers)
+	grpcserver.Add(healthrpchandlers)
+
+	a.lifecycle.Append(&Component{Name: "grpc server", Run: grpcserver.Run, StopTimeout: a.serverStopTimeout(conf)})
+
+	// the watch streams are endless, they're closed before the grpc server waits for them to stop
+	a.lifecycle.Append(&Component{
+		Name: "account watch",
+		Stop: func(context.Context) error {
+			uc.accountwatch.Close()
+			return nil
+		},
+	})
+
+	return healthrpchandlers, nil
+}
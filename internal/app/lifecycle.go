@@ -0,0 +1,178 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"time"
+)
+
+const (
+	DefaultStopTimeout = 10 * time.Second
+)
+
+var (
+	ErrDependenciesAreNil = errors.New("dependencies are nil")
+	ErrLoggerIsNil        = errors.New("logger is nil")
+	ErrStopTimeout        = errors.New("component didn't stop in time")
+)
+
+// Component is a part of the app started and stopped by the Lifecycle
+type Component struct {
+	Name string
+	// Run blocks until ctx is done or the component fails, the components without Run are only stopped
+	Run func(ctx context.Context) error
+	// Stop releases the component once Run has returned
+	Stop func(ctx context.Context) error
+	// StopTimeout bounds both the return of Run and Stop, the Lifecycle's one is used if it isn't positive
+	StopTimeout time.Duration
+}
+
+type LifecycleConfig struct {
+	// StopTimeout is DefaultStopTimeout if it isn't positive
+	StopTimeout time.Duration
+}
+
+type LifecycleDependencies struct {
+	Logger logapp.ILogger
+	Config *LifecycleConfig
+}
+
+// Lifecycle starts the components in the order they're appended and stops them in the reverse order,
+// so a component is stopped before the ones it depends on
+type Lifecycle struct {
+	logger      logapp.ILogger
+	stoptimeout time.Duration
+	components  []*Component
+}
+
+// running is a started component, done is closed once Run returns err
+type running struct {
+	component *Component
+	cancel    context.CancelFunc
+	done      chan struct{}
+	err       error
+}
+
+func NewLifecycle(d *LifecycleDependencies) (*Lifecycle, error) {
+	if d == nil {
+		return nil, ErrDependenciesAreNil
+	}
+	if d.Logger == nil {
+		return nil, ErrLoggerIsNil
+	}
+
+	l := &Lifecycle{
+		logger:      d.Logger.WithField("unit", "lifecycle"),
+		stoptimeout: DefaultStopTimeout,
+	}
+	if d.Config != nil && d.Config.StopTimeout > 0 {
+		l.stoptimeout = d.Config.StopTimeout
+	}
+	return l, nil
+}
+
+// Append adds the component, it's started after the ones appended before
+func (l *Lifecycle) Append(c *Component) {
+	l.components = append(l.components, c)
+}
+
+// Run starts the components and stops them once ctx is done or any of them fails.
+// The components get their own contexts, so ctx being done doesn't stop them all at once
+func (l *Lifecycle) Run(ctx context.Context) error {
+	failed := make(chan struct{}, len(l.components))
+	started := make([]*running, 0, len(l.components))
+
+	for _, c := range l.components {
+		r := &running{
+			component: c,
+			done:      make(chan struct{}),
+		}
+		started = append(started, r)
+		if c.Run == nil {
+			close(r.done)
+			continue
+		}
+
+		var runctx context.Context
+		runctx, r.cancel = context.WithCancel(context.WithoutCancel(ctx))
+		go func() {
+			r.err = r.component.Run(runctx)
+			close(r.done)
+			if r.err != nil {
+				failed <- struct{}{}
+			}
+		}()
+		l.logger.Infof("%s is started", c.Name)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-failed:
+	}
+	return l.stop(started)
+}
+
+// Close stops the components which aren't started, e.g. when the app failed to be built
+func (l *Lifecycle) Close() error {
+	started := make([]*running, 0, len(l.components))
+	for _, c := range l.components {
+		r := &running{
+			component: c,
+			done:      make(chan struct{}),
+		}
+		close(r.done)
+		started = append(started, r)
+	}
+	return l.stop(started)
+}
+
+func (l *Lifecycle) stop(started []*running) error {
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		if err := l.stopOne(started[i]); err != nil {
+			l.logger.Error(err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// stopOne cancels the context of the component and waits for Run to return before Stop is called
+func (l *Lifecycle) stopOne(r *running) error {
+	timeout := r.component.StopTimeout
+	if timeout <= 0 {
+		timeout = l.stoptimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if r.cancel != nil {
+		r.cancel()
+	}
+
+	var errs []error
+	select {
+	case <-r.done:
+		if r.err != nil && !errors.Is(r.err, context.Canceled) {
+			errs = append(errs, r.err)
+		}
+	case <-ctx.Done():
+		errs = append(errs, ErrStopTimeout)
+	}
+
+	if r.component.Stop != nil {
+		if err := r.component.Stop(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("%s: %w", r.component.Name, err)
+	}
+	if r.component.Run != nil {
+		l.logger.Infof("%s is stopped", r.component.Name)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder records the events of the components in their order
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+// component runs until its context is done, started is closed once it runs
+func (r *recorder) component(name string, started chan<- struct{}) *Component {
+	return &Component{
+		Name: name,
+		Run: func(ctx context.Context) error {
+			r.add(name + " started")
+			started <- struct{}{}
+			<-ctx.Done()
+			r.add(name + " done")
+			return ctx.Err()
+		},
+		Stop: func(context.Context) error {
+			r.add(name + " stopped")
+			return nil
+		},
+	}
+}
+
+func newTestLifecycle(t *testing.T, stoptimeout time.Duration) *Lifecycle {
+	l, err := NewLifecycle(&LifecycleDependencies{
+		Logger: logapp.NewLogger(&logapp.Config{}),
+		Config: &LifecycleConfig{StopTimeout: stoptimeout},
+	})
+	require.NoError(t, err)
+	return l
+}
+
+func TestNewLifecycle(t *testing.T) {
+	_, err := NewLifecycle(nil)
+	assert.ErrorIs(t, err, ErrDependenciesAreNil)
+
+	_, err = NewLifecycle(&LifecycleDependencies{})
+	assert.ErrorIs(t, err, ErrLoggerIsNil)
+
+	l, err := NewLifecycle(&LifecycleDependencies{Logger: logapp.NewLogger(&logapp.Config{})})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultStopTimeout, l.stoptimeout)
+}
+
+func TestLifecycle_Run(t *testing.T) {
+	l := newTestLifecycle(t, time.Second)
+	r := &recorder{}
+	started := make(chan struct{}, 2)
+
+	l.Append(&Component{
+		Name: "storage",
+		Stop: func(context.Context) error {
+			r.add("storage stopped")
+			return nil
+		},
+	})
+	l.Append(r.component("worker", started))
+	l.Append(r.component("server", started))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() {
+		result <- l.Run(ctx)
+	}()
+	<-started
+	<-started
+	cancel()
+
+	require.NoError(t, <-result)
+	assert.Equal(t, []string{
+		"server done", "server stopped",
+		"worker done", "worker stopped",
+		"storage stopped",
+	}, r.get()[2:], "the components are stopped in the reverse order")
+}
+
+func TestLifecycle_RunFailed(t *testing.T) {
+	l := newTestLifecycle(t, time.Second)
+	r := &recorder{}
+	started := make(chan struct{}, 1)
+	failure := errors.New("listen failed")
+
+	l.Append(r.component("worker", started))
+	l.Append(&Component{
+		Name: "server",
+		Run: func(context.Context) error {
+			<-started
+			return failure
+		},
+	})
+
+	err := l.Run(context.Background())
+	assert.ErrorIs(t, err, failure)
+	assert.Equal(t, []string{"worker started", "worker done", "worker stopped"}, r.get())
+}
+
+func TestLifecycle_RunStopTimeout(t *testing.T) {
+	l := newTestLifecycle(t, time.Second)
+	release := make(chan struct{})
+	defer close(release)
+	stopped := false
+
+	l.Append(&Component{
+		Name: "stuck",
+		Run: func(context.Context) error {
+			<-release
+			return nil
+		},
+		Stop: func(context.Context) error {
+			stopped = true
+			return nil
+		},
+		StopTimeout: 10 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := l.Run(ctx)
+	assert.ErrorIs(t, err, ErrStopTimeout)
+	assert.True(t, stopped, "the component is stopped even if Run doesn't return")
+}
+
+func TestLifecycle_Close(t *testing.T) {
+	l := newTestLifecycle(t, time.Second)
+	r := &recorder{}
+	failure := errors.New("close failed")
+
+	l.Append(&Component{
+		Name: "storage",
+		Stop: func(context.Context) error {
+			r.add("storage stopped")
+			return nil
+		},
+	})
+	l.Append(&Component{
+		Name: "publisher",
+		Stop: func(context.Context) error {
+			r.add("publisher stopped")
+			return failure
+		},
+	})
+
+	err := l.Close()
+	assert.ErrorIs(t, err, failure)
+	assert.Equal(t, []string{"publisher stopped", "storage stopped"}, r.get())
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"github.com/alexsibrin/runbot-auth/internal/config"
+	"github.com/alexsibrin/runbot-auth/internal/publisher"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+)
+
+const (
+	publisherInProcess = "inprocess"
+	publisherNATS      = "nats"
+	publisherKafka     = "kafka"
+)
+
+var (
+	ErrPublisherIsUnknown = errors.New("outbox publisher is unknown")
+)
+
+// NewPublisher returns the publisher of the domain events for the configured broker
+func NewPublisher(conf *config.Config) (usecases.IPublisher, error) {
+	switch conf.Outbox.Publisher {
+	case publisherInProcess, "":
+		return publisher.NewInProcess(), nil
+
+	case publisherNATS:
+		return publisher.NewNATS(&publisher.NATSConfig{
+			URL:           conf.NATS.URL,
+			SubjectPrefix: conf.NATS.SubjectPrefix,
+			Timeout:       conf.NATS.Timeout,
+		})
+
+	case publisherKafka:
+		return publisher.NewKafka(&publisher.KafkaConfig{
+			RESTProxyURL: conf.Kafka.RESTProxyURL,
+			Topic:        conf.Kafka.Topic,
+			Timeout:      conf.Kafka.Timeout,
+		})
+
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrPublisherIsUnknown, conf.Outbox.Publisher)
+	}
+}
@@ -0,0 +1,170 @@
+package app
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/api/rest"
+	restv1 "github.com/alexsibrin/runbot-auth/internal/api/rest/v1"
+	handlersrest "github.com/alexsibrin/runbot-auth/internal/api/rest/v1/handlers"
+	"github.com/alexsibrin/runbot-auth/internal/api/rest/v1/middlewares"
+	"net"
+)
+
+// buildREST appends the REST server, it listens on the configured port unless the listener is given
+func (a *App) buildREST(p *parts, ctrl *appControllers, listener net.Listener) error {
+	conf, logger := p.conf, p.logger
+
+	accounthandlers, err := handlersrest.NewAccount(&handlersrest.DependenciesAccount{
+		AccountController: ctrl.account,
+		Logger:            logger,
+	})
+	if err != nil {
+		return err
+	}
+
+	exporthandlers, err := handlersrest.NewExport(&handlersrest.DependenciesExport{
+		ExportController: ctrl.export,
+		Logger:           logger,
+	})
+	if err != nil {
+		return err
+	}
+
+	sessionhandlers, err := handlersrest.NewSession(&handlersrest.DependenciesSession{
+		SessionController: ctrl.session,
+		Logger:            logger,
+	})
+	if err != nil {
+		return err
+	}
+
+	securityeventhandlers, err := handlersrest.NewSecurityEvent(&handlersrest.DependenciesSecurityEvent{
+		SecurityEventController: ctrl.securityevent,
+		Logger:                  logger,
+	})
+	if err != nil {
+		return err
+	}
+
+	loginalerthandlers, err := handlersrest.NewLoginAlert(&handlersrest.DependenciesLoginAlert{
+		LoginAlertController: ctrl.loginalert,
+		Logger:               logger,
+	})
+	if err != nil {
+		return err
+	}
+
+	adminaccounthandlers, err := handlersrest.NewAdminAccount(&handlersrest.DependenciesAdminAccount{
+		AccountController: ctrl.account,
+		Logger:            logger,
+	})
+	if err != nil {
+		return err
+	}
+
+	healthhandlers, err := handlersrest.NewHealth(&handlersrest.DependenciesHealth{
+		Checker: p.checker,
+		Logger:  logger,
+	})
+	if err != nil {
+		return err
+	}
+
+	commonhandlers, err := handlersrest.NewCommon(&handlersrest.DependenciesCommon{
+		Health:    conf.Common.Health,
+		Version:   conf.Common.Version,
+		Readiness: p.readiness,
+	})
+	if err != nil {
+		return err
+	}
+
+	authmiddleware, err := middlewares.NewAuth(&middlewares.DependenciesAuth{
+		Controller: ctrl.account,
+		Logger:     logger,
+	})
+	if err != nil {
+		return err
+	}
+
+	var adminmiddleware *middlewares.Admin
+	if p.admins != nil {
+		adminmiddleware, err = middlewares.NewAdmin(&middlewares.DependenciesAdmin{
+			Admins: p.admins,
+			Logger: logger,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	var metricsmiddleware *middlewares.Metrics
+	if p.metrics.app != nil {
+		metricsmiddleware, err = middlewares.NewMetrics(&middlewares.DependenciesMetrics{
+			Recorder: p.metrics.app,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	var tracingmiddleware *middlewares.Tracing
+	if p.tracing {
+		tracingmiddleware, err = middlewares.NewTracing(&middlewares.DependenciesTracing{})
+		if err != nil {
+			return err
+		}
+	}
+
+	var accesslogmiddleware *middlewares.AccessLog
+	if conf.Logger.AccessLog {
+		accesslogmiddleware, err = middlewares.NewAccessLog(&middlewares.DependenciesAccessLog{
+			Logger: logger,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	router, err := restv1.NewRouter(&restv1.DependenciesRouter{
+		Handlers: &restv1.Handlers{
+			Account:       accounthandlers,
+			Export:        exporthandlers,
+			Session:       sessionhandlers,
+			SecurityEvent: securityeventhandlers,
+			LoginAlert:    loginalerthandlers,
+			AdminAccount:  adminaccounthandlers,
+			Common:        commonhandlers,
+			Health:        healthhandlers,
+			Metrics:       p.metrics.http,
+		},
+		Middlewares: &restv1.Middlewares{
+			Auth:      authmiddleware,
+			Admin:     adminmiddleware,
+			Metrics:   metricsmiddleware,
+			Tracing:   tracingmiddleware,
+			AccessLog: accesslogmiddleware,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	restserver, err := rest.NewServer(&rest.DependenciesServer{
+		Config: &rest.Config{
+			Host:              conf.RestServer.Host,
+			Port:              conf.RestServer.Port,
+			ReadTimeout:       conf.RestServer.ReadTimeout,
+			ReadHeaderTimeout: conf.RestServer.ReadHeaderTimeout,
+			WriteTimeout:      conf.RestServer.WriteTimeout,
+			IdleTimeout:       conf.RestServer.IdleTimeout,
+			DrainTimeout:      conf.Shutdown.DrainTimeout,
+		},
+		Handler:  router,
+		Listener: listener,
+	})
+	if err != nil {
+		return err
+	}
+
+	a.lifecycle.Append(&Component{Name: "rest server", Run: restserver.Run, StopTimeout: a.serverStopTimeout(conf)})
+	return nil
+}
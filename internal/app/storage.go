@@ -0,0 +1,267 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/alexsibrin/runbot-auth/internal/config"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/alexsibrin/runbot-auth/internal/repositories/dbmemory"
+	"github.com/alexsibrin/runbot-auth/internal/repositories/dbpostgres"
+	"github.com/alexsibrin/runbot-auth/internal/repositories/dbsqlite"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+)
+
+const (
+	storagePostgres = "postgres"
+	storageSQLite   = "sqlite"
+	storageMemory   = "memory"
+)
+
+var (
+	ErrStorageDriverIsUnknown = errors.New("storage driver is unknown")
+)
+
+// Storage holds the repositories of the configured driver
+type Storage struct {
+	// Postgres is nil unless the driver is postgres
+	Postgres *dbpostgres.PostgreSQL
+	SQLite   *dbsqlite.SQLite
+
+	Account         usecases.IAccountRepo
+	AccountImport   usecases.IAccountImportRepo
+	StatusHistory   usecases.IStatusHistoryRepo
+	Session         usecases.ISessionRepo
+	SecurityEvent   usecases.ISecurityEventRepo
+	AuditLog        usecases.IAuditLogRepo
+	Outbox          usecases.IOutboxRepo
+	Transactor      usecases.ITransactor
+	Export          usecases.IExportRepo
+	Webhook         usecases.IWebhookRepo
+	WebhookDelivery usecases.IWebhookDeliveryRepo
+}
+
+func NewStorage(conf *config.Config) (*Storage, error) {
+	s := &Storage{}
+
+	switch conf.Storage.Driver {
+	case storagePostgres, "":
+		db, err := dbpostgres.New(&dbpostgres.Config{
+			Db:       conf.PostgreSQL.Db,
+			Host:     conf.PostgreSQL.Host,
+			Port:     conf.PostgreSQL.Port,
+			User:     conf.PostgreSQL.User,
+			Password: conf.PostgreSQL.Password,
+			SSLMode:  conf.PostgreSQL.SSLMode,
+		})
+		if err != nil {
+			return nil, err
+		}
+		s.Postgres = db
+
+		s.Account, err = dbpostgres.NewAccount(db)
+		if err != nil {
+			return nil, s.closeWith(err)
+		}
+
+		s.AccountImport, err = dbpostgres.NewAccountImport(db)
+		if err != nil {
+			return nil, s.closeWith(err)
+		}
+
+		s.StatusHistory, err = dbpostgres.NewStatusHistory(db)
+		if err != nil {
+			return nil, s.closeWith(err)
+		}
+
+		s.Session, err = dbpostgres.NewSession(db)
+		if err != nil {
+			return nil, s.closeWith(err)
+		}
+
+		s.SecurityEvent, err = dbpostgres.NewSecurityEvent(db)
+		if err != nil {
+			return nil, s.closeWith(err)
+		}
+
+		s.AuditLog, err = dbpostgres.NewAuditLog(db)
+		if err != nil {
+			return nil, s.closeWith(err)
+		}
+
+		s.Outbox, err = dbpostgres.NewOutbox(db)
+		if err != nil {
+			return nil, s.closeWith(err)
+		}
+
+		s.Transactor, err = dbpostgres.NewTransactor(db)
+		if err != nil {
+			return nil, s.closeWith(err)
+		}
+
+		s.Export, err = dbpostgres.NewExport(db)
+		if err != nil {
+			return nil, s.closeWith(err)
+		}
+
+		s.Webhook, err = dbpostgres.NewWebhook(db)
+		if err != nil {
+			return nil, s.closeWith(err)
+		}
+
+		s.WebhookDelivery, err = dbpostgres.NewWebhookDelivery(db)
+		if err != nil {
+			return nil, s.closeWith(err)
+		}
+
+	case storageSQLite:
+		db, err := dbsqlite.New(&dbsqlite.Config{
+			Path: conf.Storage.SQLitePath,
+		})
+		if err != nil {
+			return nil, err
+		}
+		s.SQLite = db
+
+		s.Account, err = dbsqlite.NewAccount(db)
+		if err != nil {
+			return nil, s.closeWith(err)
+		}
+
+		s.AccountImport, err = dbsqlite.NewAccountImport(db)
+		if err != nil {
+			return nil, s.closeWith(err)
+		}
+
+		s.StatusHistory, err = dbsqlite.NewStatusHistory(db)
+		if err != nil {
+			return nil, s.closeWith(err)
+		}
+
+		s.Session, err = dbsqlite.NewSession(db)
+		if err != nil {
+			return nil, s.closeWith(err)
+		}
+
+		s.SecurityEvent, err = dbsqlite.NewSecurityEvent(db)
+		if err != nil {
+			return nil, s.closeWith(err)
+		}
+
+		s.AuditLog, err = dbsqlite.NewAuditLog(db)
+		if err != nil {
+			return nil, s.closeWith(err)
+		}
+
+		s.Outbox, err = dbsqlite.NewOutbox(db)
+		if err != nil {
+			return nil, s.closeWith(err)
+		}
+
+		s.Transactor, err = dbsqlite.NewTransactor(db)
+		if err != nil {
+			return nil, s.closeWith(err)
+		}
+
+		s.Export, err = dbsqlite.NewExport(db)
+		if err != nil {
+			return nil, s.closeWith(err)
+		}
+
+		s.Webhook, err = dbsqlite.NewWebhook(db)
+		if err != nil {
+			return nil, s.closeWith(err)
+		}
+
+		s.WebhookDelivery, err = dbsqlite.NewWebhookDelivery(db)
+		if err != nil {
+			return nil, s.closeWith(err)
+		}
+
+	case storageMemory:
+		account, statushistory, session, securityevent, auditlog, outbox := dbmemory.NewAccount(), dbmemory.NewStatusHistory(), dbmemory.NewSession(), dbmemory.NewSecurityEvent(), dbmemory.NewAuditLog(), dbmemory.NewOutbox()
+		export, webhook, delivery := dbmemory.NewExport(), dbmemory.NewWebhook(), dbmemory.NewWebhookDelivery()
+		s.Account = account
+		s.AccountImport = dbmemory.NewAccountImport(account)
+		s.StatusHistory = statushistory
+		s.Session = session
+		s.SecurityEvent = securityevent
+		s.AuditLog = auditlog
+		s.Outbox = outbox
+		s.Transactor = dbmemory.NewTransactor(account, statushistory, session, securityevent, auditlog, export, outbox, webhook, delivery)
+		s.Export = export
+		s.Webhook = webhook
+		s.WebhookDelivery = delivery
+
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrStorageDriverIsUnknown, conf.Storage.Driver)
+	}
+
+	return s, nil
+}
+
+// buildStorage opens the configured storage unless it's given, the given one is closed by the caller.
+// The pending migrations are applied if it's enabled
+func (a *App) buildStorage(ctx context.Context, d *Dependencies) (*Storage, error) {
+	conf, store := d.Config, d.Storage
+	if store == nil {
+		var err error
+		store, err = NewStorage(conf)
+		if err != nil {
+			return nil, err
+		}
+		a.lifecycle.Append(&Component{
+			Name: "storage",
+			Stop: func(context.Context) error {
+				return store.Close()
+			},
+		})
+	}
+
+	if conf.PostgreSQL.AutoMigrate && store.Postgres != nil {
+		if err := migrate(ctx, d.Logger, store.Postgres); err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// migrate applies the pending migrations
+func migrate(ctx context.Context, logger logapp.ILogger, db *dbpostgres.PostgreSQL) error {
+	migrator, err := dbpostgres.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		return err
+	}
+	logger.Infof("%d migrations are applied", len(applied))
+	return nil
+}
+
+func (s *Storage) Close() error {
+	var errs []error
+	if s.Postgres != nil {
+		errs = append(errs, s.Postgres.Close())
+	}
+	if s.SQLite != nil {
+		errs = append(errs, s.SQLite.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Storage) closeWith(err error) error {
+	return errors.Join(err, s.Close())
+}
+
+// Ping checks the database answers, the memory storage always does
+func (s *Storage) Ping(ctx context.Context) error {
+	switch {
+	case s.Postgres != nil:
+		return s.Postgres.Ping(ctx)
+	case s.SQLite != nil:
+		return s.SQLite.Ping(ctx)
+	}
+	return nil
+}
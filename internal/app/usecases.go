@@ -0,0 +1,282 @@
+package app
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/api/controllers"
+	restv1 "github.com/alexsibrin/runbot-auth/internal/api/rest/v1"
+	"github.com/alexsibrin/runbot-auth/internal/api/validators"
+	"github.com/alexsibrin/runbot-auth/internal/geoip"
+	"github.com/alexsibrin/runbot-auth/internal/hasher"
+	"github.com/alexsibrin/runbot-auth/internal/urlsigner"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/alexsibrin/runbot-auth/internal/webhook"
+	"strings"
+)
+
+type appUsecases struct {
+	account       *usecases.Account
+	session       *usecases.Session
+	securityevent *usecases.SecurityEvent
+	auditlog      *usecases.AuditLog
+	export        *usecases.Export
+	webhook       *usecases.Webhook
+	accountwatch  *usecases.AccountWatch
+	loginalert    *usecases.LoginAlert
+}
+
+// appControllers are shared by the REST and the gRPC APIs
+type appControllers struct {
+	account       *controllers.Account
+	session       *controllers.Session
+	securityevent *controllers.SecurityEvent
+	auditlog      *controllers.AuditLog
+	export        *controllers.Export
+	webhook       *controllers.Webhook
+	accountwatch  *controllers.AccountWatch
+	loginalert    *controllers.LoginAlert
+}
+
+// newUsecases builds the usecases on the storage, the mailer of the config is used unless it's given
+func newUsecases(p *parts, mail usecases.IMailer) (*appUsecases, error) {
+	conf, store := p.conf, p.store
+
+	var stringHasher usecases.IPasswordHasher = hasher.NewStringHasher()
+	if p.metrics.app != nil {
+		stringHasher = p.metrics.app.Hasher(stringHasher)
+	}
+
+	var err error
+	if mail == nil {
+		mail, err = newMailer(conf, p.logger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var geoipdb usecases.IGeoIP
+	if conf.GeoIP.File != "" {
+		db, err := geoip.Load(conf.GeoIP.File)
+		if err != nil {
+			return nil, err
+		}
+		p.logger.Infof("GeoIP database contains %d ranges", db.Len())
+		geoipdb = db
+	}
+
+	linksecret := conf.LoginAlert.LinkSecret
+	if linksecret == "" {
+		if linksecret, err = urlsigner.DeriveSecret(conf.Jwt.Salt, linkSecretPurpose); err != nil {
+			return nil, err
+		}
+	}
+	linkttl := conf.LoginAlert.LinkTTL
+	if linkttl <= 0 {
+		linkttl = usecases.DefaultLinkTTL
+	}
+	linksigner, err := urlsigner.New(&urlsigner.Config{
+		Secret: linksecret,
+		TTL:    linkttl,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uc := &appUsecases{}
+
+	uc.loginalert, err = usecases.NewLoginAlert(&usecases.LoginAlertDependencies{
+		AccountRepo:      store.Account,
+		SessionRepo:      store.Session,
+		Transactor:       store.Transactor,
+		PasswordHasher:   stringHasher,
+		Mailer:           mail,
+		GeoIP:            geoipdb,
+		Links:            linksigner,
+		NotMeURL:         strings.TrimSuffix(conf.Export.PublicURL, "/") + restv1.NotMeURL,
+		PasswordResetURL: strings.TrimSuffix(conf.Export.PublicURL, "/") + restv1.PasswordResetURL,
+		Logger:           p.logger,
+		Metrics:          p.metrics.usecase,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uc.account, err = usecases.NewAccount(&usecases.AccountDependencies{
+		Repo:                store.Account,
+		StatusHistory:       store.StatusHistory,
+		SecurityEvents:      store.SecurityEvent,
+		Transactor:          store.Transactor,
+		PasswordHasher:      stringHasher,
+		LoginAlert:          uc.loginalert,
+		Metrics:             p.metrics.usecase,
+		DeletionGracePeriod: conf.Account.DeletionGracePeriod,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uc.session, err = NewSessionUsecase(conf, store, p.metrics.usecase)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.securityevent, err = usecases.NewSecurityEvent(&usecases.SecurityEventDependencies{
+		Repo:        store.SecurityEvent,
+		AccountRepo: store.Account,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uc.auditlog, err = usecases.NewAuditLog(&usecases.AuditLogDependencies{
+		Repo: store.AuditLog,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uc.export, err = usecases.NewExport(&usecases.ExportDependencies{
+		Repo:              store.Export,
+		AccountRepo:       store.Account,
+		SessionRepo:       store.Session,
+		SecurityEventRepo: store.SecurityEvent,
+		Retention:         conf.Export.Retention,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	webhooksender, err := webhook.NewSender(&webhook.Config{
+		Timeout: conf.Webhook.Timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uc.webhook, err = usecases.NewWebhook(&usecases.WebhookDependencies{
+		Repo:         store.Webhook,
+		DeliveryRepo: store.WebhookDelivery,
+		Sender:       webhooksender,
+		Transactor:   store.Transactor,
+		Config: &usecases.WebhookConfig{
+			MaxAttempts: conf.Webhook.MaxAttempts,
+			BackoffBase: conf.Webhook.BackoffBase,
+			BackoffMax:  conf.Webhook.BackoffMax,
+			Retention:   conf.Webhook.Retention,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uc.accountwatch, err = usecases.NewAccountWatch(&usecases.AccountWatchDependencies{
+		Repo: store.Outbox,
+		Config: &usecases.AccountWatchConfig{
+			PollInterval: conf.Account.WatchPollInterval,
+			BatchSize:    conf.Account.WatchBatchSize,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return uc, nil
+}
+
+func newControllers(p *parts, uc *appUsecases) (*appControllers, error) {
+	conf := p.conf
+
+	var emailblocklist controllers.IEmailBlocklist
+	if conf.Email.DisposableDomainsFile != "" || len(conf.Email.DisposableDomains) > 0 {
+		var blocklist *validators.EmailDomainBlocklist
+		var err error
+		if conf.Email.DisposableDomainsFile != "" {
+			blocklist, err = validators.LoadEmailDomainBlocklist(conf.Email.DisposableDomainsFile, conf.Email.DisposableDomains)
+		} else {
+			blocklist, err = validators.NewEmailDomainBlocklist(conf.Email.DisposableDomains)
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.logger.Infof("Email blocklist contains %d domains", blocklist.Len())
+		emailblocklist = blocklist
+	}
+
+	exporturlsecret := conf.Export.URLSecret
+	if exporturlsecret == "" {
+		var err error
+		if exporturlsecret, err = urlsigner.DeriveSecret(conf.Jwt.Salt, exportURLSecretPurpose); err != nil {
+			return nil, err
+		}
+	}
+	exportsigner, err := urlsigner.New(&urlsigner.Config{
+		Secret: exporturlsecret,
+		TTL:    conf.Export.URLTTL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctrl := &appControllers{}
+
+	ctrl.account, err = controllers.NewAccount(&controllers.AccountDependencies{
+		Usecase:        uc.account,
+		Securer:        p.appsec,
+		Sessions:       uc.session,
+		EmailBlocklist: emailblocklist,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctrl.session, err = controllers.NewSession(&controllers.SessionDependencies{
+		Usecase: uc.session,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctrl.securityevent, err = controllers.NewSecurityEvent(&controllers.SecurityEventDependencies{
+		Usecase: uc.securityevent,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctrl.auditlog, err = controllers.NewAuditLog(&controllers.AuditLogDependencies{
+		Usecase: uc.auditlog,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctrl.accountwatch, err = controllers.NewAccountWatch(&controllers.AccountWatchDependencies{
+		Usecase: uc.accountwatch,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctrl.webhook, err = controllers.NewWebhook(&controllers.WebhookDependencies{
+		Usecase: uc.webhook,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctrl.loginalert, err = controllers.NewLoginAlert(&controllers.LoginAlertDependencies{
+		Usecase: uc.loginalert,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctrl.export, err = controllers.NewExport(&controllers.ExportDependencies{
+		Usecase:     uc.export,
+		URLSigner:   exportsigner,
+		DownloadURL: strings.TrimSuffix(conf.Export.PublicURL, "/") + restv1.ExportDownloadURL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ctrl, nil
+}
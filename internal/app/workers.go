@@ -0,0 +1,112 @@
+package app
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/publisher"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/alexsibrin/runbot-auth/internal/workers"
+	"io"
+)
+
+// buildWorkers appends the background workers and the publisher of the events,
+// the publisher of the config is used unless it's given
+func (a *App) buildWorkers(p *parts, uc *appUsecases, eventpublisher usecases.IPublisher) error {
+	conf, logger := p.conf, p.logger
+
+	purger, err := workers.NewPurger(&workers.PurgerDependencies{
+		Usecase: uc.account,
+		Logger:  logger,
+		Config: &workers.PurgerConfig{
+			Interval:  conf.Account.PurgeInterval,
+			BatchSize: conf.Account.PurgeBatchSize,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	reactivator, err := workers.NewReactivator(&workers.ReactivatorDependencies{
+		Usecase: uc.account,
+		Logger:  logger,
+		Config: &workers.ReactivatorConfig{
+			Interval:  conf.Account.ReactivateInterval,
+			BatchSize: conf.Account.ReactivateBatchSize,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	exporter, err := workers.NewExporter(&workers.ExporterDependencies{
+		Usecase: uc.export,
+		Logger:  logger,
+		Config: &workers.ExporterConfig{
+			Interval: conf.Export.Interval,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if eventpublisher == nil {
+		eventpublisher, err = NewPublisher(conf)
+		if err != nil {
+			return err
+		}
+		if pinger, ok := eventpublisher.(pinger); ok {
+			p.checker.Add("broker", pinger.Ping)
+		}
+		if closer, ok := eventpublisher.(io.Closer); ok {
+			a.lifecycle.Append(&Component{
+				Name: "publisher",
+				Stop: func(context.Context) error {
+					return closer.Close()
+				},
+			})
+		}
+	}
+
+	// the events go to the broker and are queued for the webhooks, the account watchers are woken up
+	// once the events are marked published
+	outboxusecase, err := usecases.NewOutbox(&usecases.OutboxDependencies{
+		Repo:      p.store.Outbox,
+		Publisher: publisher.NewFanout(eventpublisher, uc.webhook),
+		Listener:  uc.accountwatch,
+		Config: &usecases.OutboxConfig{
+			BatchSize: conf.Outbox.BatchSize,
+			Retention: conf.Outbox.Retention,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	relay, err := workers.NewRelay(&workers.RelayDependencies{
+		Usecase: outboxusecase,
+		Logger:  logger,
+		Config: &workers.RelayConfig{
+			Interval: conf.Outbox.Interval,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	dispatcher, err := workers.NewDispatcher(&workers.DispatcherDependencies{
+		Usecase: uc.webhook,
+		Logger:  logger,
+		Config: &workers.DispatcherConfig{
+			Interval: conf.Webhook.Interval,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	a.lifecycle.Append(&Component{Name: "purger", Run: purger.Run})
+	a.lifecycle.Append(&Component{Name: "reactivator", Run: reactivator.Run})
+	a.lifecycle.Append(&Component{Name: "exporter", Run: exporter.Run})
+	a.lifecycle.Append(&Component{Name: "relay", Run: relay.Run})
+	a.lifecycle.Append(&Component{Name: "dispatcher", Run: dispatcher.Run})
+	return nil
+}
@@ -14,12 +14,34 @@ const (
 )
 
 type Config struct {
+	Storage
 	PostgreSQL
 	RestServer
 	GRPCServer
 	Logger
 	Jwt
 	Common
+	Email
+	Account
+	Export
+	Mail
+	GeoIP
+	LoginAlert
+	Outbox
+	NATS
+	Kafka
+	Webhook
+	Admin
+	Shutdown
+	HealthCheck
+	Metrics
+	Tracing
+}
+
+type Storage struct {
+	// Driver is one of postgres (default), sqlite or memory
+	Driver     string
+	SQLitePath string
 }
 
 type PostgreSQL struct {
@@ -29,6 +51,8 @@ type PostgreSQL struct {
 	User     string
 	Password string
 	SSLMode  string
+	// AutoMigrate applies pending migrations at the startup
+	AutoMigrate bool
 }
 
 type RestServer struct {
@@ -48,14 +72,152 @@ type Logger struct {
 	Level         int
 	Colors        bool
 	FullTimestamp bool
+	// Format is text or json
+	Format string
+	// AccessLog logs the REST requests and the gRPC calls
+	AccessLog bool
 }
 
 type Jwt struct {
-	Salt      string
-	Issuer    string
-	Subject   string
-	Audience  []string
-	ExpiresIn time.Duration
+	Salt string
+	// PreviousSalts still verify the tokens after Salt is rotated, they're dropped once the refresh tokens expire
+	PreviousSalts []string
+	Issuer        string
+	Subject       string
+	Audience      []string
+	ExpiresIn     time.Duration
+}
+
+type Email struct {
+	// DisposableDomains and DisposableDomainsFile are merged,
+	// the blocking is disabled when both of them are empty
+	DisposableDomains     []string
+	DisposableDomainsFile string
+}
+
+type Account struct {
+	// DeletionGracePeriod is the time a deleted account can be restored before its PII is purged
+	DeletionGracePeriod time.Duration
+	PurgeInterval       time.Duration
+	PurgeBatchSize      int
+	// ReactivateInterval is how often the lapsed temporary suspensions and blocks are lifted
+	ReactivateInterval  time.Duration
+	ReactivateBatchSize int
+	// WatchPollInterval is how often the watch streams read the events of the other instances,
+	// the events of this instance are streamed once they're relayed
+	WatchPollInterval time.Duration
+	WatchBatchSize    int
+}
+
+type Export struct {
+	// PublicURL is the base of the download URLs, e.g. https://auth.runbot.io
+	PublicURL string
+	// URLSecret signs the download URLs, a secret derived from Jwt.Salt is used if it's empty
+	URLSecret string
+	URLTTL    time.Duration
+	Retention time.Duration
+	Interval  time.Duration
+}
+
+type Mail struct {
+	// Host of the SMTP server, the emails are only logged if it's empty
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	Timeout  time.Duration
+}
+
+type GeoIP struct {
+	// File is a CSV database of the IP ranges and their countries, the location isn't checked if it's empty
+	File string
+}
+
+type LoginAlert struct {
+	// LinkSecret signs the "this wasn't me" and password reset links, a secret derived from Jwt.Salt is used if it's empty
+	LinkSecret string
+	LinkTTL    time.Duration
+}
+
+type Outbox struct {
+	// Publisher is one of inprocess (default), nats or kafka.
+	// The in-process publisher has no subscribers outside the app, so the events are only marked published
+	Publisher string
+	Interval  time.Duration
+	BatchSize int
+	// Retention is how long the published events are kept
+	Retention time.Duration
+}
+
+type NATS struct {
+	// URL is nats://[user:password@]host:port, tls:// makes the connection encrypted
+	URL string
+	// SubjectPrefix is followed by the event type, e.g. runbot.auth.account.created,
+	// a JetStream stream must capture the subjects
+	SubjectPrefix string
+	Timeout       time.Duration
+}
+
+type Kafka struct {
+	// RESTProxyURL is the base URL of the Confluent Kafka REST Proxy, the events are produced through it,
+	// the service doesn't talk to the Kafka brokers directly
+	RESTProxyURL string
+	Topic        string
+	Timeout      time.Duration
+}
+
+type Webhook struct {
+	// Interval is how often the due deliveries are sent
+	Interval time.Duration
+	// Timeout of a delivery request, the partner endpoint should answer quickly and process the event later
+	Timeout     time.Duration
+	MaxAttempts int
+	// BackoffBase is the delay after the first failed attempt, it's doubled up to BackoffMax
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// Retention is how long the delivered and dead deliveries are kept
+	Retention time.Duration
+}
+
+type Admin struct {
+	// Tokens are the NAME:TOKEN bearer tokens of the admin REST API and the audited admin RPCs, the NAME is
+	// the actor of the admin's changes. The admin APIs are disabled when it's empty.
+	// Several tokens let a token be rotated, the new one is added before the old one is removed
+	Tokens []string
+}
+
+type Tracing struct {
+	// Exporter is otlp, stdout or empty, the spans aren't recorded without an exporter
+	Exporter string
+	// Endpoint is host:port of the OTLP gRPC collector, OTEL_EXPORTER_OTLP_ENDPOINT is used if it's empty
+	Endpoint string
+	// Insecure disables TLS to the collector
+	Insecure bool
+	// SampleRatio of the traces started by the app, 1 by default. The traces started upstream follow their decision
+	SampleRatio float64
+}
+
+type Metrics struct {
+	// Enabled exposes the Prometheus metrics on /metrics of the REST server
+	Enabled bool
+}
+
+type HealthCheck struct {
+	// Interval is how often the grpc health service runs the checks, 5s by default
+	Interval time.Duration
+	// Timeout bounds each check of the readiness, 2s by default
+	Timeout time.Duration
+}
+
+type Shutdown struct {
+	// StopTimeout bounds the stop of each part of the app, 10s by default
+	StopTimeout time.Duration
+	// DrainTimeout is how long the servers wait for the in-flight requests, 15s by default
+	DrainTimeout time.Duration
+	// ReadinessDelay is how long the app reports it's not ready before the servers stop,
+	// so the load balancers stop routing to it first
+	ReadinessDelay time.Duration
 }
 
 type Common struct {
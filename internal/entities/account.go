@@ -4,16 +4,22 @@ const (
 	Active uint8 = iota
 	Suspended
 	Blocked
+	// Deleted accounts can be restored until the grace period is over, then their PII is purged
+	Deleted
 )
 
 type Account struct {
-	UUID      string
-	Email     string
-	Password  string
-	Name      string
-	Status    uint8
-	CreatedAt int64
-	UpdatedAt int64
+	UUID     string
+	Email    string
+	Password string
+	Name     string
+	Status   uint8
+	// StatusUntil is when a temporary status lapses and the account is reactivated, zero if it's permanent
+	StatusUntil int64
+	CreatedAt   int64
+	UpdatedAt   int64
+	DeletedAt   int64
+	PurgedAt    int64
 }
 
 func (e *Account) IsActive() bool {
@@ -24,3 +30,47 @@ func (e *Account) IsActive() bool {
 		return false
 	}
 }
+
+func (e *Account) IsDeleted() bool {
+	return e.Status == Deleted
+}
+
+func (e *Account) IsPurged() bool {
+	return e.PurgedAt != 0
+}
+
+// IsPasswordResetRequired reports whether the password is cleared, e.g. after a sign in the owner didn't recognize
+func (e *Account) IsPasswordResetRequired() bool {
+	return e.Password == ""
+}
+
+// IsStatusExpired reports whether the temporary status has lapsed by now
+func (e *Account) IsStatusExpired(now int64) bool {
+	return e.StatusUntil != 0 && e.StatusUntil <= now
+}
+
+// StatusName is the human-readable name of the account status
+func StatusName(status uint8) string {
+	switch status {
+	case Active:
+		return "active"
+	case Suspended:
+		return "suspended"
+	case Blocked:
+		return "blocked"
+	case Deleted:
+		return "deleted"
+	default:
+		return "unknown"
+	}
+}
+
+// StatusByName is the account status of the human-readable name, see StatusName
+func StatusByName(name string) (uint8, bool) {
+	for _, status := range []uint8{Active, Suspended, Blocked, Deleted} {
+		if StatusName(status) == name {
+			return status, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,62 @@
+package entities
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+)
+
+const (
+	AuditActionStatusChanged      = "account.status_changed"
+	AuditActionDeleted            = "account.deleted"
+	AuditActionSessionsRevoked    = "account.sessions_revoked"
+	AuditActionWebhookCreated     = "webhook.created"
+	AuditActionWebhookUpdated     = "webhook.updated"
+	AuditActionWebhookDeleted     = "webhook.deleted"
+	AuditActionWebhookRedelivered = "webhook.redelivered"
+)
+
+// AuditRecord is a record of the admin audit log. The records are chained: every record keeps the hash
+// of the previous one and its own hash covers all the fields with PrevHash, so a changed or removed record
+// breaks the chain. PrevHash of the first record is empty
+type AuditRecord struct {
+	ID         int64
+	Actor      string
+	Action     string
+	TargetUUID string
+	// Before and After are the JSON of the changed values
+	Before string
+	After  string
+	// Method, IP and UserAgent describe the admin request
+	Method    string
+	IP        string
+	UserAgent string
+	CreatedAt int64
+	PrevHash  string
+	Hash      string
+}
+
+// ComputeHash returns the hex SHA-256 of the record fields, ID and Hash aren't covered
+func (e *AuditRecord) ComputeHash() string {
+	h := sha256.New()
+	var size [binary.MaxVarintLen64]byte
+	// every field is prefixed with its length, so the fields can't be shifted into each other
+	for _, field := range []string{
+		e.PrevHash,
+		e.Actor,
+		e.Action,
+		e.TargetUUID,
+		e.Before,
+		e.After,
+		e.Method,
+		e.IP,
+		e.UserAgent,
+		strconv.FormatInt(e.CreatedAt, 10),
+	} {
+		n := binary.PutUvarint(size[:], uint64(len(field)))
+		h.Write(size[:n])
+		h.Write([]byte(field))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,64 @@
+package entities
+
+import "encoding/json"
+
+const (
+	DomainEventAccountCreated       = "account.created"
+	DomainEventAccountStatusChanged = "account.status_changed"
+	DomainEventAccountDeleted       = "account.deleted"
+	DomainEventAccountPurged        = "account.purged"
+	DomainEventPasswordChanged      = "account.password_changed"
+)
+
+// DomainEventTypes are all the event types, e.g. to subscribe to
+var DomainEventTypes = []string{
+	DomainEventAccountCreated,
+	DomainEventAccountStatusChanged,
+	DomainEventAccountDeleted,
+	DomainEventAccountPurged,
+	DomainEventPasswordChanged,
+}
+
+// DomainEvent is a change of an account the other services react to. The event is stored in the outbox
+// in the transaction of the change and published by the relay later, so it's delivered at least once
+type DomainEvent struct {
+	ID int64
+	// UUID identifies the event for the consumers, a redelivered event has the same UUID
+	UUID        string
+	Type        string
+	AccountUUID string
+	// Payload is the JSON of the event data
+	Payload   string
+	CreatedAt int64
+	// PublishedAt is zero until the event is published
+	PublishedAt int64
+	// Seq numbers the events in the order they're marked published, zero until then. The numbers are
+	// committed in their order, unlike the IDs taken by the concurrent transactions
+	Seq       int64
+	Attempts  int
+	LastError string
+}
+
+// domainEventMessage is the published form of the event
+type domainEventMessage struct {
+	ID          string
+	Type        string
+	AccountUUID string
+	CreatedAt   int64
+	Data        json.RawMessage
+}
+
+// Message returns the JSON the event is published as
+func (e *DomainEvent) Message() ([]byte, error) {
+	data := json.RawMessage(e.Payload)
+	if len(data) == 0 {
+		data = json.RawMessage("{}")
+	}
+	return json.Marshal(&domainEventMessage{
+		ID:          e.UUID,
+		Type:        e.Type,
+		AccountUUID: e.AccountUUID,
+		CreatedAt:   e.CreatedAt,
+		Data:        data,
+	})
+}
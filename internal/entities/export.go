@@ -0,0 +1,32 @@
+package entities
+
+const (
+	ExportPending uint8 = iota
+	ExportProcessing
+	ExportReady
+	ExportFailed
+)
+
+// AccountExport is an archive of the personal data requested by the account owner
+type AccountExport struct {
+	UUID        string
+	AccountUUID string
+	Status      uint8
+	Archive     []byte
+	CreatedAt   int64
+	UpdatedAt   int64
+	ReadyAt     int64
+	ExpiresAt   int64
+}
+
+func (e *AccountExport) IsReady() bool {
+	return e.Status == ExportReady
+}
+
+func (e *AccountExport) IsFinished() bool {
+	return e.Status == ExportReady || e.Status == ExportFailed
+}
+
+func (e *AccountExport) IsExpired(now int64) bool {
+	return e.ExpiresAt != 0 && e.ExpiresAt <= now
+}
@@ -0,0 +1,41 @@
+package entities
+
+const (
+	SecurityEventSignIn uint8 = iota
+	SecurityEventSignInFailed
+	SecurityEventRefresh
+	SecurityEventPasswordChanged
+	SecurityEventStatusChanged
+	SecurityEventSessionsRevoked
+)
+
+// SecurityEventTypeNames are the names of the event types in the API and in the personal data exports
+var SecurityEventTypeNames = map[uint8]string{
+	SecurityEventSignIn:          "signin",
+	SecurityEventSignInFailed:    "signin_failed",
+	SecurityEventRefresh:         "refresh",
+	SecurityEventPasswordChanged: "password_changed",
+	SecurityEventStatusChanged:   "status_changed",
+	SecurityEventSessionsRevoked: "sessions_revoked",
+}
+
+// SecurityEventTypeName is the name of the event type, "unknown" for the types without one
+func SecurityEventTypeName(eventtype uint8) string {
+	name, ok := SecurityEventTypeNames[eventtype]
+	if !ok {
+		return "unknown"
+	}
+	return name
+}
+
+// SecurityEvent is a record of the account security log, it answers who and from where used the account
+type SecurityEvent struct {
+	ID          int64
+	AccountUUID string
+	Type        uint8
+	IP          string
+	UserAgent   string
+	// Details explains the event, e.g. the reason of a failed sign in or a status change
+	Details   string
+	CreatedAt int64
+}
@@ -0,0 +1,32 @@
+package entities
+
+// Session is a device signed in to the account, it lives as long as its refresh token
+type Session struct {
+	UUID        string
+	AccountUUID string
+	IP          string
+	UserAgent   string
+	DeviceName  string
+	CreatedAt   int64
+	RefreshedAt int64
+	ExpiresAt   int64
+	RevokedAt   int64
+}
+
+func (e *Session) IsRevoked() bool {
+	return e.RevokedAt != 0
+}
+
+func (e *Session) IsExpired(now int64) bool {
+	return e.ExpiresAt <= now
+}
+
+func (e *Session) IsActive(now int64) bool {
+	return !e.IsRevoked() && !e.IsExpired(now)
+}
+
+// TokenClaims identify the owner and the session of a token
+type TokenClaims struct {
+	AccountUUID string
+	SessionUUID string
+}
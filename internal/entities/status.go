@@ -0,0 +1,23 @@
+package entities
+
+const (
+	// SystemActor is the actor of the status changes made by the service itself
+	SystemActor = "system"
+)
+
+// StatusChange is a record of the account status history
+type StatusChange struct {
+	ID          int64
+	AccountUUID string
+	FromStatus  uint8
+	ToStatus    uint8
+	Reason      string
+	Actor       string
+	// Until is when a temporary status lapses, it's zero for a permanent one
+	Until     int64
+	CreatedAt int64
+}
+
+func (e *StatusChange) IsTemporary() bool {
+	return e.Until != 0
+}
@@ -0,0 +1,51 @@
+package entities
+
+import "slices"
+
+const (
+	WebhookDeliveryPending uint8 = iota
+	WebhookDeliveryDelivered
+	// WebhookDeliveryDead is a delivery which ran out of attempts, it's sent again only if an admin redelivers it
+	WebhookDeliveryDead
+)
+
+// Webhook is an endpoint of a partner which receives the domain events signed with the secret
+type Webhook struct {
+	UUID   string
+	URL    string
+	Secret string
+	// EventTypes are the subscribed domain event types, the empty list subscribes to all of them
+	EventTypes []string
+	Active     bool
+	CreatedAt  int64
+	UpdatedAt  int64
+}
+
+// Subscribes tells whether the active webhook receives the events of the type
+func (w *Webhook) Subscribes(eventtype string) bool {
+	return w.Active && (len(w.EventTypes) == 0 || slices.Contains(w.EventTypes, eventtype))
+}
+
+// WebhookDelivery is a domain event to deliver to a webhook, it's kept after the delivery as the delivery log
+type WebhookDelivery struct {
+	ID          int64
+	WebhookUUID string
+	// EventUUID identifies the event for the receiver, a redelivered event has the same UUID
+	EventUUID string
+	EventType string
+	// Payload is the message of the event, see DomainEvent.Message
+	Payload  string
+	Status   uint8
+	Attempts int
+	// NextAttemptAt is the time of the next attempt of a pending delivery
+	NextAttemptAt int64
+	// LastStatusCode is the HTTP status of the last attempt, zero if there was no response
+	LastStatusCode int
+	LastError      string
+	CreatedAt      int64
+	UpdatedAt      int64
+}
+
+func (d *WebhookDelivery) IsFinished() bool {
+	return d.Status == WebhookDeliveryDelivered || d.Status == WebhookDeliveryDead
+}
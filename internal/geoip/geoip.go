@@ -0,0 +1,144 @@
+package geoip
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrPathIsEmpty        = errors.New("geoip database path is empty")
+	ErrRangeIsNotValid    = errors.New("geoip range is not valid")
+	ErrDatabaseIsNotValid = errors.New("geoip database is not valid")
+)
+
+// ipRange is an inclusive range of the addresses of one family
+type ipRange struct {
+	from    netip.Addr
+	to      netip.Addr
+	country string
+}
+
+// DB resolves the IP addresses to the ISO country codes by a local database file.
+// The file is a CSV with the "network,country" rows, e.g. "203.0.113.0/24,AU",
+// or the "first,last,country" rows of the DB-IP Lite country database.
+// The lines starting with # are comments
+type DB struct {
+	ranges []ipRange
+}
+
+func Load(path string) (*DB, error) {
+	if path == "" {
+		return nil, ErrPathIsEmpty
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+func Read(r io.Reader) (*DB, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	db := &DB{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDatabaseIsNotValid, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		r, err := parseRange(record)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrDatabaseIsNotValid, line, err)
+		}
+		db.ranges = append(db.ranges, r)
+	}
+
+	sort.Slice(db.ranges, func(i, j int) bool {
+		return db.ranges[i].from.Less(db.ranges[j].from)
+	})
+	return db, nil
+}
+
+// Country returns the ISO code of the IP country, the empty string if it's unknown
+func (db *DB) Country(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+
+	// the last range which starts at addr or before it
+	i := sort.Search(len(db.ranges), func(i int) bool {
+		return addr.Less(db.ranges[i].from)
+	}) - 1
+	if i < 0 {
+		return ""
+	}
+	r := db.ranges[i]
+	if r.from.Is4() != addr.Is4() || r.to.Less(addr) {
+		return ""
+	}
+	return r.country
+}
+
+func (db *DB) Len() int {
+	return len(db.ranges)
+}
+
+func parseRange(record []string) (ipRange, error) {
+	switch len(record) {
+	case 2:
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(record[0]))
+		if err != nil {
+			return ipRange{}, err
+		}
+		prefix = prefix.Masked()
+		return newRange(prefix.Addr(), lastAddr(prefix), record[1])
+	case 3:
+		from, err := netip.ParseAddr(strings.TrimSpace(record[0]))
+		if err != nil {
+			return ipRange{}, err
+		}
+		to, err := netip.ParseAddr(strings.TrimSpace(record[1]))
+		if err != nil {
+			return ipRange{}, err
+		}
+		return newRange(from, to, record[2])
+	default:
+		return ipRange{}, ErrRangeIsNotValid
+	}
+}
+
+func newRange(from, to netip.Addr, country string) (ipRange, error) {
+	from, to = from.Unmap(), to.Unmap()
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if from.Is4() != to.Is4() || to.Less(from) || country == "" {
+		return ipRange{}, ErrRangeIsNotValid
+	}
+	return ipRange{from: from, to: to, country: country}, nil
+}
+
+// lastAddr is the last address of the masked prefix
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	b := prefix.Addr().AsSlice()
+	for bit := prefix.Bits(); bit < len(b)*8; bit++ {
+		b[bit/8] |= 1 << (7 - bit%8)
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
@@ -0,0 +1,72 @@
+package geoip
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testDatabase = `# network,country
+203.0.113.0/24,AU
+198.51.100.0/25, de
+2001:db8::/32,NL
+# first,last,country
+192.0.2.10,192.0.2.20,FR
+`
+
+func TestDB_Country(t *testing.T) {
+	db, err := Read(strings.NewReader(testDatabase))
+	require.NoError(t, err)
+	assert.Equal(t, 4, db.Len())
+
+	testCases := []struct {
+		ip      string
+		country string
+	}{
+		{ip: "203.0.113.0", country: "AU"},
+		{ip: "203.0.113.255", country: "AU"},
+		{ip: "198.51.100.127", country: "DE"},
+		{ip: "198.51.100.128", country: ""},
+		{ip: "192.0.2.15", country: "FR"},
+		{ip: "192.0.2.21", country: ""},
+		{ip: "::ffff:203.0.113.7", country: "AU"},
+		{ip: "2001:db8::1", country: "NL"},
+		{ip: "2001:db9::1", country: ""},
+		{ip: "10.0.0.1", country: ""},
+		{ip: "notip", country: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.ip, func(t *testing.T) {
+			assert.Equal(t, tc.country, db.Country(tc.ip))
+		})
+	}
+}
+
+func TestRead_NotValid(t *testing.T) {
+	for _, content := range []string{
+		"203.0.113.0/33,AU\n",
+		"203.0.113.0/24\n",
+		"203.0.113.0/24,\n",
+		"192.0.2.20,192.0.2.10,FR\n",
+		"192.0.2.10,2001:db8::1,FR\n",
+	} {
+		_, err := Read(strings.NewReader(content))
+		assert.ErrorIs(t, err, ErrDatabaseIsNotValid, content)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	_, err := Load("")
+	assert.ErrorIs(t, err, ErrPathIsEmpty)
+
+	path := filepath.Join(t.TempDir(), "geoip.csv")
+	require.NoError(t, os.WriteFile(path, []byte(testDatabase), 0o600))
+
+	db, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "AU", db.Country("203.0.113.1"))
+}
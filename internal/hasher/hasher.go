@@ -1,6 +1,31 @@
 package hasher
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+)
+
+const (
+	argon2idPrefix = "$argon2id$"
+	argon2iPrefix  = "$argon2i$"
+
+	// the argon2 parameters are bounded, so an imported hash can't make a sign in use too much memory or CPU.
+	// The memory is in KiB, 256 MiB at most
+	argon2MaxMemory    = 256 * 1024
+	argon2MaxTime      = 16
+	argon2MaxThreads   = 16
+	argon2MaxKeyLength = 64
+)
+
+var (
+	ErrHashIsNotValid = errors.New("password hash is not valid")
+	ErrMismatchedHash = errors.New("password doesn't match the hash")
+)
 
 type StringHasher struct{}
 
@@ -13,6 +38,82 @@ func (sh *StringHasher) Hash(str string) (string, error) {
 	return string(h), err
 }
 
+// Compare checks str against a bcrypt hash or an argon2 one in the PHC format,
+// the argon2 hashes come only from the imported accounts
 func (sh *StringHasher) Compare(str, hash string) error {
+	if strings.HasPrefix(hash, argon2idPrefix) || strings.HasPrefix(hash, argon2iPrefix) {
+		return compareArgon2(str, hash)
+	}
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(str))
 }
+
+// CheckArgon2 checks the argon2 hash in the PHC format can be compared with, e.g. before it's imported
+func CheckArgon2(hash string) error {
+	_, err := parseArgon2(hash)
+	return err
+}
+
+// argon2Hash is a parsed $argon2id$v=19$m=65536,t=3,p=4$<salt>$<key> hash
+type argon2Hash struct {
+	variant string
+	memory  uint32
+	time    uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+func compareArgon2(str, hash string) error {
+	h, err := parseArgon2(hash)
+	if err != nil {
+		return err
+	}
+
+	var key []byte
+	switch h.variant {
+	case "argon2id":
+		key = argon2.IDKey([]byte(str), h.salt, h.time, h.memory, h.threads, uint32(len(h.key)))
+	default:
+		key = argon2.Key([]byte(str), h.salt, h.time, h.memory, h.threads, uint32(len(h.key)))
+	}
+	if subtle.ConstantTimeCompare(key, h.key) != 1 {
+		return ErrMismatchedHash
+	}
+	return nil
+}
+
+func parseArgon2(hash string) (*argon2Hash, error) {
+	// "", variant, version, params, salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return nil, ErrHashIsNotValid
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, ErrHashIsNotValid
+	}
+
+	h := &argon2Hash{variant: parts[1]}
+	if h.variant != "argon2id" && h.variant != "argon2i" {
+		return nil, ErrHashIsNotValid
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &h.memory, &h.time, &h.threads); err != nil {
+		return nil, ErrHashIsNotValid
+	}
+	if h.memory == 0 || h.time == 0 || h.threads == 0 {
+		return nil, ErrHashIsNotValid
+	}
+	if h.memory > argon2MaxMemory || h.time > argon2MaxTime || h.threads > argon2MaxThreads {
+		return nil, ErrHashIsNotValid
+	}
+
+	var err error
+	if h.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, ErrHashIsNotValid
+	}
+	if h.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(h.key) == 0 || len(h.key) > argon2MaxKeyLength {
+		return nil, ErrHashIsNotValid
+	}
+	return h, nil
+}
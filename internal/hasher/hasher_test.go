@@ -0,0 +1,58 @@
+package hasher
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/argon2"
+	"testing"
+)
+
+func argon2Of(variant, password string) string {
+	salt := []byte("somesaltsomesalt")
+	key := argon2.IDKey([]byte(password), salt, 1, 64, 1, 32)
+	if variant == "argon2i" {
+		key = argon2.Key([]byte(password), salt, 1, 64, 1, 32)
+	}
+	return fmt.Sprintf("$%s$v=%d$m=64,t=1,p=1$%s$%s", variant, argon2.Version,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+func TestStringHasher_Compare(t *testing.T) {
+	sh := NewStringHasher()
+
+	bcrypthash, err := sh.Hash("password123")
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name        string
+		password    string
+		hash        string
+		expectedErr bool
+	}{
+		{name: "bcrypt", password: "password123", hash: bcrypthash},
+		{name: "Wrong password for bcrypt", password: "password124", hash: bcrypthash, expectedErr: true},
+		{name: "argon2id", password: "password123", hash: argon2Of("argon2id", "password123")},
+		{name: "argon2i", password: "password123", hash: argon2Of("argon2i", "password123")},
+		{name: "Wrong password for argon2id", password: "password124", hash: argon2Of("argon2id", "password123"), expectedErr: true},
+		{name: "Other argon2 version", password: "password123", hash: "$argon2id$v=16$m=64,t=1,p=1$c2FsdA$a2V5", expectedErr: true},
+		{name: "Broken argon2 params", password: "password123", hash: "$argon2id$v=19$m=0,t=1,p=1$c2FsdA$a2V5", expectedErr: true},
+		{name: "Too much argon2 memory", password: "password123", hash: "$argon2id$v=19$m=4194304,t=1,p=1$c2FsdA$a2V5", expectedErr: true},
+		{name: "Too many argon2 iterations", password: "password123", hash: "$argon2id$v=19$m=64,t=1000,p=1$c2FsdA$a2V5", expectedErr: true},
+		{name: "Too many argon2 threads", password: "password123", hash: "$argon2id$v=19$m=64,t=1,p=255$c2FsdA$a2V5", expectedErr: true},
+		{name: "argon2d", password: "password123", hash: "$argon2d$v=19$m=64,t=1,p=1$c2FsdA$a2V5", expectedErr: true},
+		{name: "Broken argon2 salt", password: "password123", hash: "$argon2id$v=19$m=64,t=1,p=1$!!!$a2V5", expectedErr: true},
+		{name: "Unknown hash", password: "password123", hash: "$1$somesalt$somehash", expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := sh.Compare(tc.password, tc.hash)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
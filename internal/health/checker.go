@@ -0,0 +1,125 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultCheckTimeout = 2 * time.Second
+
+	StatusOk   = "ok"
+	StatusFail = "fail"
+)
+
+var (
+	ErrIsNotReady = errors.New("app is not ready")
+)
+
+// Check fails if the dependency isn't reachable
+type Check func(ctx context.Context) error
+
+type CheckerConfig struct {
+	// Timeout bounds each check, DefaultCheckTimeout is used if it isn't positive
+	Timeout time.Duration
+}
+
+// Checker runs the named checks of the app dependencies
+type Checker struct {
+	timeout time.Duration
+	names   []string
+	checks  map[string]Check
+}
+
+// CheckResult is the outcome of a single check. The error and the duration are logged, they aren't answered
+// by the probe: the errors may disclose the hosts and the users of the dependencies
+type CheckResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Error      string `json:"-"`
+	DurationMs int64  `json:"-"`
+}
+
+// Report is ok only if all the checks are ok
+type Report struct {
+	Status string         `json:"status"`
+	Checks []*CheckResult `json:"checks"`
+}
+
+func (r *Report) IsOk() bool {
+	return r.Status == StatusOk
+}
+
+func NewChecker(c *CheckerConfig) *Checker {
+	timeout := DefaultCheckTimeout
+	if c != nil && c.Timeout > 0 {
+		timeout = c.Timeout
+	}
+	return &Checker{
+		timeout: timeout,
+		checks:  make(map[string]Check),
+	}
+}
+
+// Add registers the check, the one with the same name is replaced
+func (c *Checker) Add(name string, check Check) {
+	if _, ok := c.checks[name]; !ok {
+		c.names = append(c.names, name)
+		sort.Strings(c.names)
+	}
+	c.checks[name] = check
+}
+
+// Check runs the checks concurrently, the report lists them by name
+func (c *Checker) Check(ctx context.Context) *Report {
+	report := &Report{
+		Status: StatusOk,
+		Checks: make([]*CheckResult, len(c.names)),
+	}
+
+	var wg sync.WaitGroup
+	for i, name := range c.names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			report.Checks[i] = c.run(ctx, name, c.checks[name])
+		}(i, name)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusOk {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, name string, check Check) *CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := &CheckResult{
+		Name:       name,
+		Status:     StatusOk,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Check of the readiness fails while the app starts or stops
+func (r *Readiness) Check(context.Context) error {
+	if !r.IsReady() {
+		return ErrIsNotReady
+	}
+	return nil
+}
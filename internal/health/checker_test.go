@@ -0,0 +1,89 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestChecker_Check(t *testing.T) {
+	errDown := errors.New("down")
+
+	testCases := []struct {
+		name           string
+		checks         map[string]Check
+		expectedStatus string
+		expectedChecks []*CheckResult
+	}{
+		{
+			name:           "Without checks",
+			expectedStatus: StatusOk,
+			expectedChecks: []*CheckResult{},
+		},
+		{
+			name: "All are ok",
+			checks: map[string]Check{
+				"postgres": func(context.Context) error { return nil },
+				"keys":     func(context.Context) error { return nil },
+			},
+			expectedStatus: StatusOk,
+			expectedChecks: []*CheckResult{
+				{Name: "keys", Status: StatusOk},
+				{Name: "postgres", Status: StatusOk},
+			},
+		},
+		{
+			name: "One fails",
+			checks: map[string]Check{
+				"postgres": func(context.Context) error { return errDown },
+				"keys":     func(context.Context) error { return nil },
+			},
+			expectedStatus: StatusFail,
+			expectedChecks: []*CheckResult{
+				{Name: "keys", Status: StatusOk},
+				{Name: "postgres", Status: StatusFail, Error: "down"},
+			},
+		},
+		{
+			name: "Timeout",
+			checks: map[string]Check{
+				"broker": func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				},
+			},
+			expectedStatus: StatusFail,
+			expectedChecks: []*CheckResult{
+				{Name: "broker", Status: StatusFail, Error: context.DeadlineExceeded.Error()},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			checker := NewChecker(&CheckerConfig{Timeout: 10 * time.Millisecond})
+			for name, check := range tc.checks {
+				checker.Add(name, check)
+			}
+
+			report := checker.Check(context.Background())
+			assert.Equal(t, tc.expectedStatus, report.Status)
+			require.Len(t, report.Checks, len(tc.expectedChecks))
+			for i, result := range report.Checks {
+				result.DurationMs = 0
+				assert.Equal(t, tc.expectedChecks[i], result)
+			}
+		})
+	}
+}
+
+func TestReadiness_Check(t *testing.T) {
+	r := NewReadiness(0)
+	assert.ErrorIs(t, r.Check(context.Background()), ErrIsNotReady)
+
+	r.ready.Store(true)
+	assert.NoError(t, r.Check(context.Background()))
+}
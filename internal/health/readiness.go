@@ -0,0 +1,36 @@
+package health
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Readiness reports whether the app takes the traffic. It isn't ready until it's run,
+// and it isn't ready for the delay before the servers are stopped, so the load balancers stop routing to it first
+type Readiness struct {
+	ready atomic.Bool
+	delay time.Duration
+}
+
+func NewReadiness(delay time.Duration) *Readiness {
+	return &Readiness{
+		delay: delay,
+	}
+}
+
+func (r *Readiness) IsReady() bool {
+	return r.ready.Load()
+}
+
+// Run makes the app ready until ctx is done, then it returns after the delay
+func (r *Readiness) Run(ctx context.Context) error {
+	r.ready.Store(true)
+	<-ctx.Done()
+	r.ready.Store(false)
+
+	timer := time.NewTimer(r.delay)
+	defer timer.Stop()
+	<-timer.C
+	return nil
+}
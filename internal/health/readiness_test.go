@@ -0,0 +1,30 @@
+package health
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestReadiness_Run(t *testing.T) {
+	r := NewReadiness(50 * time.Millisecond)
+	assert.False(t, r.IsReady(), "it isn't ready until it's run")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		assert.NoError(t, r.Run(ctx))
+		close(done)
+	}()
+	assert.Eventually(t, r.IsReady, time.Second, time.Millisecond)
+
+	cancel()
+	assert.Eventually(t, func() bool { return !r.IsReady() }, time.Second, time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("Run returned before the delay")
+	case <-time.After(10 * time.Millisecond):
+	}
+	<-done
+}
@@ -9,23 +9,37 @@ import (
 	"time"
 )
 
+const (
+	// the types keep a refresh token from being used as an access token and the other way round
+	accessTokenType  = "access"
+	refreshTokenType = "refresh"
+)
+
 var (
-	ErrTokenIsNonValid = errors.New("token is not valid")
+	ErrTokenIsNonValid  = errors.New("token is not valid")
+	ErrTokenTypeIsWrong = errors.New("token type is wrong")
+	ErrSaltIsEmpty      = errors.New("salt is empty")
 )
 
 type myClaims struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
 	UUID  string `json:"uuid"`
+	// SessionUUID is the session the token is issued for, the tokens issued before the sessions have none
+	SessionUUID string `json:"sid,omitempty"`
+	// Type is accessTokenType or refreshTokenType, the tokens issued before the types have none
+	Type string `json:"typ"`
 	jwt.RegisteredClaims
 }
 
 type Config struct {
-	Salt      string
-	Issuer    string
-	Subject   string
-	Audience  []string
-	ExpiresIn time.Duration
+	Salt string
+	// PreviousSalts still verify the tokens after Salt is rotated, until the tokens signed with them expire
+	PreviousSalts []string
+	Issuer        string
+	Subject       string
+	Audience      []string
+	ExpiresIn     time.Duration
 }
 
 type JwtWrapper struct {
@@ -38,16 +52,34 @@ func New(c *Config) *JwtWrapper {
 	}
 }
 
-func (j *JwtWrapper) AccessToken(a *entities.Account) (string, error) {
-	return j.createToken(a, j.config.ExpiresIn, jwt.SigningMethodHS256)
+func (j *JwtWrapper) AccessToken(a *entities.Account, sessionuuid string) (string, error) {
+	return j.createToken(a, sessionuuid, accessTokenType, j.config.ExpiresIn, jwt.SigningMethodHS256)
+}
+
+func (j *JwtWrapper) RefreshToken(a *entities.Account, sessionuuid string) (string, error) {
+	return j.createToken(a, sessionuuid, refreshTokenType, j.RefreshExpiresIn(), jwt.SigningMethodHS512)
+}
+
+// CheckKeys reports whether the signing keys are loaded, the empty ones would sign forgeable tokens
+func (j *JwtWrapper) CheckKeys() error {
+	if j.config.Salt == "" {
+		return ErrSaltIsEmpty
+	}
+	for _, salt := range j.config.PreviousSalts {
+		if salt == "" {
+			return ErrSaltIsEmpty
+		}
+	}
+	return nil
 }
 
-func (j *JwtWrapper) RefreshToken(a *entities.Account) (string, error) {
-	return j.createToken(a, j.config.ExpiresIn*10, jwt.SigningMethodHS512)
+// RefreshExpiresIn is the lifetime of the refresh tokens, the sessions live as long
+func (j *JwtWrapper) RefreshExpiresIn() time.Duration {
+	return j.config.ExpiresIn * 10
 }
 
-func (j *JwtWrapper) createToken(a *entities.Account, expiresin time.Duration, method jwt.SigningMethod) (string, error) {
-	claims := j.convertEntity2Claims(a, expiresin)
+func (j *JwtWrapper) createToken(a *entities.Account, sessionuuid, tokentype string, expiresin time.Duration, method jwt.SigningMethod) (string, error) {
+	claims := j.convertEntity2Claims(a, sessionuuid, tokentype, expiresin)
 	token := jwt.NewWithClaims(method, claims)
 
 	signedString, err := token.SignedString([]byte(j.config.Salt))
@@ -58,24 +90,50 @@ func (j *JwtWrapper) createToken(a *entities.Account, expiresin time.Duration, m
 	return signedString, nil
 }
 
-func (j *JwtWrapper) Decrypt(t string) (*entities.Account, error) {
-	token, err := jwt.Parse(t, func(token *jwt.Token) (interface{}, error) {
+// DecryptAccess verifies the access token, the refresh tokens are rejected
+func (j *JwtWrapper) DecryptAccess(t string) (*entities.TokenClaims, error) {
+	return j.decrypt(t, accessTokenType)
+}
+
+// DecryptRefresh verifies the refresh token, the access tokens are rejected
+func (j *JwtWrapper) DecryptRefresh(t string) (*entities.TokenClaims, error) {
+	return j.decrypt(t, refreshTokenType)
+}
+
+func (j *JwtWrapper) decrypt(t, tokentype string) (*entities.TokenClaims, error) {
+	token, err := jwt.ParseWithClaims(t, &myClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(j.config.Salt), nil
+		return j.verificationKeys(), nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(myClaims); ok && token.Valid {
-		return j.convertClaims2Entity(&claims), nil
+	claims, ok := token.Claims.(*myClaims)
+	if !ok || !token.Valid {
+		return nil, ErrTokenIsNonValid
+	}
+	if claims.Type != tokentype {
+		return nil, ErrTokenTypeIsWrong
+	}
+	return j.convertClaims2Entity(claims), nil
+}
+
+// verificationKeys are the current salt and the previous ones, the current one goes first
+func (j *JwtWrapper) verificationKeys() jwt.VerificationKeySet {
+	keys := jwt.VerificationKeySet{
+		Keys: make([]jwt.VerificationKey, 0, len(j.config.PreviousSalts)+1),
+	}
+	keys.Keys = append(keys.Keys, []byte(j.config.Salt))
+	for _, salt := range j.config.PreviousSalts {
+		keys.Keys = append(keys.Keys, []byte(salt))
 	}
-	return nil, ErrTokenIsNonValid
+	return keys
 }
 
-func (j *JwtWrapper) convertEntity2Claims(a *entities.Account, expiresat time.Duration) *myClaims {
+func (j *JwtWrapper) convertEntity2Claims(a *entities.Account, sessionuuid, tokentype string, expiresat time.Duration) *myClaims {
 	now := time.Now()
 	claims := jwt.RegisteredClaims{
 		Issuer:    j.config.Issuer,
@@ -91,14 +149,15 @@ func (j *JwtWrapper) convertEntity2Claims(a *entities.Account, expiresat time.Du
 		Name:             a.Name,
 		Email:            a.Email,
 		UUID:             a.UUID,
+		SessionUUID:      sessionuuid,
+		Type:             tokentype,
 		RegisteredClaims: claims,
 	}
 }
 
-func (j *JwtWrapper) convertClaims2Entity(claims *myClaims) *entities.Account {
-	return &entities.Account{
-		UUID:  claims.UUID,
-		Email: claims.Email,
-		Name:  claims.Name,
+func (j *JwtWrapper) convertClaims2Entity(claims *myClaims) *entities.TokenClaims {
+	return &entities.TokenClaims{
+		AccountUUID: claims.UUID,
+		SessionUUID: claims.SessionUUID,
 	}
 }
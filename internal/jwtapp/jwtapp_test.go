@@ -0,0 +1,94 @@
+package jwtapp
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestDecryptAccess(t *testing.T) {
+	j := New(&Config{
+		Salt:      "somesalt",
+		Issuer:    "runbot-auth",
+		ExpiresIn: time.Minute,
+	})
+	account := &entities.Account{
+		UUID:  "someuuid",
+		Email: "some@email.com",
+		Name:  "SomeName",
+	}
+
+	atoken, err := j.AccessToken(account, "sessionuuid")
+	require.NoError(t, err)
+	rtoken, err := j.RefreshToken(account, "sessionuuid")
+	require.NoError(t, err)
+
+	decrypted, err := j.DecryptAccess(atoken)
+	require.NoError(t, err)
+	assert.Equal(t, &entities.TokenClaims{AccountUUID: "someuuid", SessionUUID: "sessionuuid"}, decrypted)
+
+	decrypted, err = j.DecryptRefresh(rtoken)
+	require.NoError(t, err)
+	assert.Equal(t, &entities.TokenClaims{AccountUUID: "someuuid", SessionUUID: "sessionuuid"}, decrypted)
+
+	_, err = j.DecryptAccess(rtoken)
+	assert.ErrorIs(t, err, ErrTokenTypeIsWrong, "a refresh token isn't an access token")
+	_, err = j.DecryptRefresh(atoken)
+	assert.ErrorIs(t, err, ErrTokenTypeIsWrong, "an access token isn't a refresh token")
+
+	_, err = New(&Config{Salt: "othersalt", ExpiresIn: time.Minute}).DecryptAccess(atoken)
+	assert.Error(t, err)
+
+	expired, err := New(&Config{Salt: "somesalt", ExpiresIn: -time.Minute}).AccessToken(account, "sessionuuid")
+	require.NoError(t, err)
+	_, err = j.DecryptAccess(expired)
+	assert.Error(t, err)
+}
+
+func TestDecryptUntypedToken(t *testing.T) {
+	// a token signed before the types were added
+	claims := &myClaims{UUID: "someuuid", SessionUUID: "sessionuuid", RegisteredClaims: jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS512, claims).SignedString([]byte("somesalt"))
+	require.NoError(t, err)
+
+	j := New(&Config{Salt: "somesalt", ExpiresIn: time.Minute})
+	_, err = j.DecryptAccess(token)
+	assert.ErrorIs(t, err, ErrTokenTypeIsWrong)
+	_, err = j.DecryptRefresh(token)
+	assert.ErrorIs(t, err, ErrTokenTypeIsWrong)
+}
+
+func TestDecryptPreviousSalts(t *testing.T) {
+	account := &entities.Account{UUID: "someuuid"}
+	oldtoken, err := New(&Config{Salt: "oldsalt", ExpiresIn: time.Minute}).AccessToken(account, "sessionuuid")
+	require.NoError(t, err)
+
+	rotated := New(&Config{Salt: "newsalt", PreviousSalts: []string{"oldsalt"}, ExpiresIn: time.Minute})
+	decrypted, err := rotated.DecryptAccess(oldtoken)
+	require.NoError(t, err)
+	assert.Equal(t, "someuuid", decrypted.AccountUUID)
+
+	newtoken, err := rotated.AccessToken(account, "sessionuuid")
+	require.NoError(t, err)
+	_, err = New(&Config{Salt: "oldsalt", ExpiresIn: time.Minute}).DecryptAccess(newtoken)
+	assert.Error(t, err, "the new tokens are signed with the new salt")
+
+	_, err = New(&Config{Salt: "newsalt", ExpiresIn: time.Minute}).DecryptAccess(oldtoken)
+	assert.Error(t, err, "the previous salt is dropped")
+}
+
+func TestRefreshExpiresIn(t *testing.T) {
+	j := New(&Config{Salt: "somesalt", ExpiresIn: time.Minute})
+	assert.Equal(t, 10*time.Minute, j.RefreshExpiresIn())
+}
+
+func TestCheckKeys(t *testing.T) {
+	assert.NoError(t, New(&Config{Salt: "somesalt", PreviousSalts: []string{"oldsalt"}}).CheckKeys())
+	assert.ErrorIs(t, New(&Config{}).CheckKeys(), ErrSaltIsEmpty)
+	assert.ErrorIs(t, New(&Config{Salt: "somesalt", PreviousSalts: []string{""}}).CheckKeys(), ErrSaltIsEmpty)
+}
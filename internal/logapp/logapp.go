@@ -1,29 +1,55 @@
 package logapp
 
 import (
+	"context"
 	"github.com/sirupsen/logrus"
+	"io"
 	"os"
 	"time"
 )
 
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
 type ILogger interface {
 	logrus.FieldLogger
+	// WithContext binds the entry to ctx, so the request ID of ctx is logged with it
+	WithContext(ctx context.Context) *logrus.Entry
 }
 
 type Config struct {
 	Level         int
 	Colors        bool
 	FullTimestamp bool
+	// Format is FormatText or FormatJSON, the text is used if it's empty
+	Format string
+	// Output is os.Stdout if it's nil
+	Output io.Writer
 }
 
+// NewLogger makes the logger of the app, it logs the request IDs of the contexts
+// and redacts the emails, the tokens and the passwords of all the entries
 func NewLogger(config *Config) ILogger {
 	l := logrus.New()
-	l.SetFormatter(&logrus.TextFormatter{
-		ForceColors:     config.Colors,
-		FullTimestamp:   config.FullTimestamp,
-		TimestampFormat: time.RFC3339,
-	})
-	l.SetOutput(os.Stdout)
+	if config.Format == FormatJSON {
+		l.SetFormatter(&logrus.JSONFormatter{
+			TimestampFormat: time.RFC3339,
+		})
+	} else {
+		l.SetFormatter(&logrus.TextFormatter{
+			ForceColors:     config.Colors,
+			FullTimestamp:   config.FullTimestamp,
+			TimestampFormat: time.RFC3339,
+		})
+	}
+
+	out := config.Output
+	if out == nil {
+		out = os.Stdout
+	}
+	l.SetOutput(out)
 
 	lvl := config.Level
 	if lvl == 0 {
@@ -32,5 +58,8 @@ func NewLogger(config *Config) ILogger {
 
 	l.SetLevel(logrus.Level(lvl))
 
+	l.AddHook(&requestIDHook{})
+	l.AddHook(&redactionHook{})
+
 	return l
 }
@@ -0,0 +1,70 @@
+package logapp
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"regexp"
+	"strings"
+)
+
+const (
+	redacted = "[REDACTED]"
+)
+
+var (
+	// emailPattern matches the internationalized addresses too, any characters but the spaces and the delimiters
+	// of the quoted, the listed and the key=value emails
+	emailPattern  = regexp.MustCompile(`([^\s@"'<>()\[\],;:=])[^\s@"'<>()\[\],;:=]*@([^\s@"'<>()\[\],;:=]+\.[^\s@"'<>()\[\],;:=]{2,})`)
+	jwtPattern    = regexp.MustCompile(`eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`)
+	bearerPattern = regexp.MustCompile(`(?i)(bearer\s+)[^\s"',]+`)
+	secretPattern = regexp.MustCompile(`(?i)((?:password|passwd|secret|token)[a-z_]*"?\s*[:=]\s*"?)[^\s"',&]+`)
+
+	// sensitiveKeys are the parts of the field names whose values are never logged
+	sensitiveKeys = []string{"password", "passwd", "secret", "token", "authorization", "salt"}
+)
+
+// Redact masks the emails, keeping their first letter and domain, the JWTs, the bearer tokens
+// and the values of the password and token pairs, e.g. `password=...`
+func Redact(s string) string {
+	s = jwtPattern.ReplaceAllString(s, redacted)
+	s = bearerPattern.ReplaceAllString(s, "${1}"+redacted)
+	s = secretPattern.ReplaceAllString(s, "${1}"+redacted)
+	return emailPattern.ReplaceAllString(s, "${1}***@${2}")
+}
+
+// redactionHook redacts the message and the fields before the entry is formatted,
+// the values of the sensitive fields are dropped as a whole
+type redactionHook struct{}
+
+func (h *redactionHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *redactionHook) Fire(entry *logrus.Entry) error {
+	entry.Message = Redact(entry.Message)
+	for k, v := range entry.Data {
+		if isSensitiveKey(k) {
+			entry.Data[k] = redacted
+			continue
+		}
+		switch value := v.(type) {
+		case string:
+			entry.Data[k] = Redact(value)
+		case error:
+			entry.Data[k] = Redact(value.Error())
+		case fmt.Stringer:
+			entry.Data[k] = Redact(value.String())
+		}
+	}
+	return nil
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
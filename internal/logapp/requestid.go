@@ -0,0 +1,62 @@
+package logapp
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	RequestIDField  = "request_id"
+	RequestIDHeader = "X-Request-ID"
+	// maxRequestIDLength bounds the IDs of the callers, a longer one is replaced
+	maxRequestIDLength = 128
+)
+
+type requestIDKey struct{}
+
+// WithRequestID puts the ID of the request into ctx
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID is the ID of the request of ctx, it's empty if there isn't any
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// requestIDHook adds the request ID of the entry's context to the fields
+type requestIDHook struct{}
+
+func (h *requestIDHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *requestIDHook) Fire(entry *logrus.Entry) error {
+	if id := RequestID(entry.Context); id != "" {
+		entry.Data[RequestIDField] = id
+	}
+	return nil
+}
+
+// EnsureRequestID keeps the ID of the caller if it's safe to log, otherwise a new one is made
+func EnsureRequestID(id string) string {
+	if id == "" || len(id) > maxRequestIDLength {
+		return uuid.NewString()
+	}
+	for _, r := range id {
+		if !isRequestIDRune(r) {
+			return uuid.NewString()
+		}
+	}
+	return id
+}
+
+func isRequestIDRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+		r == '-' || r == '_' || r == '.' || r == ':'
+}
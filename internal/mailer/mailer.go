@@ -0,0 +1,177 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultPort    = 587
+	DefaultTimeout = 10 * time.Second
+)
+
+var (
+	ErrConfigIsNil      = errors.New("config is nil")
+	ErrHostIsEmpty      = errors.New("smtp host is empty")
+	ErrSenderIsEmpty    = errors.New("mail sender is empty")
+	ErrLoggerIsNil      = errors.New("logger is nil")
+	ErrRecipientIsEmpty = errors.New("mail recipient is empty")
+	ErrHeaderIsNotValid = errors.New("mail header is not valid")
+)
+
+type Config struct {
+	Host string
+	// Port is DefaultPort if it isn't positive
+	Port     int
+	Username string
+	Password string
+	From     string
+	// Timeout bounds the whole SMTP conversation, DefaultTimeout if it isn't positive
+	Timeout time.Duration
+}
+
+// SMTP sends plain text emails, STARTTLS is used if the server supports it
+type SMTP struct {
+	addr    string
+	host    string
+	auth    smtp.Auth
+	from    string
+	timeout time.Duration
+}
+
+func NewSMTP(c *Config) (*SMTP, error) {
+	if c == nil {
+		return nil, ErrConfigIsNil
+	}
+	if c.Host == "" {
+		return nil, ErrHostIsEmpty
+	}
+	if c.From == "" {
+		return nil, ErrSenderIsEmpty
+	}
+	port := c.Port
+	if port <= 0 {
+		port = DefaultPort
+	}
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	m := &SMTP{
+		addr:    net.JoinHostPort(c.Host, strconv.Itoa(port)),
+		host:    c.Host,
+		from:    c.From,
+		timeout: timeout,
+	}
+	if c.Username != "" {
+		m.auth = smtp.PlainAuth("", c.Username, c.Password, c.Host)
+	}
+	return m, nil
+}
+
+func (m *SMTP) Send(ctx context.Context, to, subject, body string) error {
+	msg, err := message(m.from, to, subject, body)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	if err = conn.SetDeadline(deadline); err != nil {
+		return errors.Join(err, conn.Close())
+	}
+
+	c, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		return errors.Join(err, conn.Close())
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err = c.StartTLS(nil); err != nil {
+			return err
+		}
+	}
+	if m.auth != nil {
+		if err = c.Auth(m.auth); err != nil {
+			return err
+		}
+	}
+	if err = c.Mail(m.from); err != nil {
+		return err
+	}
+	if err = c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(msg); err != nil {
+		return errors.Join(err, w.Close())
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// Log only logs the recipient and the subject of the emails, it stands for SMTP when the mail isn't configured
+type Log struct {
+	logger logapp.ILogger
+}
+
+func NewLog(logger logapp.ILogger) (*Log, error) {
+	if logger == nil {
+		return nil, ErrLoggerIsNil
+	}
+	return &Log{
+		logger: logger,
+	}, nil
+}
+
+func (m *Log) Send(_ context.Context, to, subject, _ string) error {
+	m.logger.WithField("to", to).Warnf("Mail isn't configured, the email %q isn't sent", subject)
+	return nil
+}
+
+// message builds the RFC 5322 message, the headers are checked against the header injection
+func message(from, to, subject, body string) ([]byte, error) {
+	if to == "" {
+		return nil, ErrRecipientIsEmpty
+	}
+	for _, header := range []string{from, to, subject} {
+		if strings.ContainsAny(header, "\r\n") {
+			return nil, ErrHeaderIsNotValid
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String()), nil
+}
@@ -0,0 +1,43 @@
+package mailer
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestNewSMTP(t *testing.T) {
+	_, err := NewSMTP(nil)
+	assert.ErrorIs(t, err, ErrConfigIsNil)
+
+	_, err = NewSMTP(&Config{From: "noreply@runbot.io"})
+	assert.ErrorIs(t, err, ErrHostIsEmpty)
+
+	_, err = NewSMTP(&Config{Host: "smtp.runbot.io"})
+	assert.ErrorIs(t, err, ErrSenderIsEmpty)
+
+	m, err := NewSMTP(&Config{Host: "smtp.runbot.io", From: "noreply@runbot.io"})
+	require.NoError(t, err)
+	assert.Equal(t, "smtp.runbot.io:587", m.addr)
+	assert.Equal(t, DefaultTimeout, m.timeout)
+	assert.Nil(t, m.auth)
+}
+
+func TestMessage(t *testing.T) {
+	msg, err := message("noreply@runbot.io", "bob@example.com", "New sign in", "line one\nline two")
+	require.NoError(t, err)
+
+	headers, body, found := strings.Cut(string(msg), "\r\n\r\n")
+	require.True(t, found)
+	assert.Contains(t, headers, "From: noreply@runbot.io\r\n")
+	assert.Contains(t, headers, "To: bob@example.com\r\n")
+	assert.Contains(t, headers, "Subject: New sign in\r\n")
+	assert.Equal(t, "line one\r\nline two\r\n", body)
+
+	_, err = message("noreply@runbot.io", "", "New sign in", "body")
+	assert.ErrorIs(t, err, ErrRecipientIsEmpty)
+
+	_, err = message("noreply@runbot.io", "bob@example.com\r\nBcc: eve@example.com", "New sign in", "body")
+	assert.ErrorIs(t, err, ErrHeaderIsNotValid)
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"database/sql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type IDBStats interface {
+	Stats() sql.DBStats
+}
+
+// dbCollector exports the connection pool stats of the database when it's scraped
+type dbCollector struct {
+	db IDBStats
+
+	maxopen      *prometheus.Desc
+	open         *prometheus.Desc
+	inuse        *prometheus.Desc
+	idle         *prometheus.Desc
+	waitcount    *prometheus.Desc
+	waitduration *prometheus.Desc
+	closedidle   *prometheus.Desc
+	closedlife   *prometheus.Desc
+}
+
+func newDBCollector(name string, db IDBStats) *dbCollector {
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "db", metric),
+			help,
+			nil,
+			prometheus.Labels{"db": name},
+		)
+	}
+	return &dbCollector{
+		db:           db,
+		maxopen:      desc("max_open_connections", "Maximum number of open connections to the database."),
+		open:         desc("open_connections", "The number of established connections both in use and idle."),
+		inuse:        desc("in_use_connections", "The number of connections currently in use."),
+		idle:         desc("idle_connections", "The number of idle connections."),
+		waitcount:    desc("wait_count_total", "The total number of connections waited for."),
+		waitduration: desc("wait_duration_seconds_total", "The total time blocked waiting for a new connection."),
+		closedidle:   desc("max_idle_time_closed_total", "The total number of connections closed due to the idle time limits."),
+		closedlife:   desc("max_lifetime_closed_total", "The total number of connections closed due to the lifetime limit."),
+	}
+}
+
+func (c *dbCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxopen
+	ch <- c.open
+	ch <- c.inuse
+	ch <- c.idle
+	ch <- c.waitcount
+	ch <- c.waitduration
+	ch <- c.closedidle
+	ch <- c.closedlife
+}
+
+func (c *dbCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.db.Stats()
+	ch <- prometheus.MustNewConstMetric(c.maxopen, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.inuse, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(c.waitcount, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitduration, prometheus.CounterValue, stats.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.closedidle, prometheus.CounterValue, float64(stats.MaxIdleClosed+stats.MaxIdleTimeClosed))
+	ch <- prometheus.MustNewConstMetric(c.closedlife, prometheus.CounterValue, float64(stats.MaxLifetimeClosed))
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"time"
+)
+
+type IPasswordHasher interface {
+	Hash(str string) (string, error)
+	Compare(str, hash string) error
+}
+
+// Hasher measures the duration of the password hasher
+type Hasher struct {
+	hasher  IPasswordHasher
+	metrics *Metrics
+}
+
+func (m *Metrics) Hasher(hasher IPasswordHasher) *Hasher {
+	return &Hasher{
+		hasher:  hasher,
+		metrics: m,
+	}
+}
+
+func (h *Hasher) Hash(str string) (string, error) {
+	defer h.observe(HashOperation, time.Now())
+	return h.hasher.Hash(str)
+}
+
+func (h *Hasher) Compare(str, hash string) error {
+	defer h.observe(CompareOperation, time.Now())
+	return h.hasher.Compare(str, hash)
+}
+
+func (h *Hasher) observe(operation string, start time.Time) {
+	h.metrics.ObserveHash(operation, time.Since(start))
+}
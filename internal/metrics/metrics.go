@@ -0,0 +1,157 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	namespace = "runbot_auth"
+
+	// unmatchedRoute labels the requests which don't match any route, so the paths don't blow the cardinality up
+	unmatchedRoute = "unmatched"
+
+	HashOperation    = "hash"
+	CompareOperation = "compare"
+)
+
+// Metrics are the Prometheus collectors of the servers, the storage and the auth flows
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httprequests *prometheus.CounterVec
+	httpduration *prometheus.HistogramVec
+	grpcrequests *prometheus.CounterVec
+	grpcduration *prometheus.HistogramVec
+	hashduration *prometheus.HistogramVec
+
+	signups        prometheus.Counter
+	signinfailures *prometheus.CounterVec
+	refreshes      prometheus.Counter
+	lockouts       *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httprequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "REST requests by the route and the status code.",
+		}, []string{"method", "route", "code"}),
+		httpduration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "REST request latency by the route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		grpcrequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "grpc",
+			Name:      "requests_total",
+			Help:      "gRPC calls by the method and the status code.",
+		}, []string{"method", "code"}),
+		grpcduration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "grpc",
+			Name:      "request_duration_seconds",
+			Help:      "gRPC call latency by the method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		hashduration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "password",
+			Name:      "hash_duration_seconds",
+			Help:      "Password hashing and comparison duration.",
+			// bcrypt takes tens of milliseconds
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 8),
+		}, []string{"operation"}),
+		signups: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "signups_total",
+			Help:      "Accounts signed up.",
+		}),
+		signinfailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "signin_failures_total",
+			Help:      "Failed sign ins by the reason.",
+		}, []string{"reason"}),
+		refreshes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "refreshes_total",
+			Help:      "Sessions refreshed.",
+		}),
+		lockouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "lockouts_total",
+			Help:      "Accounts locked out by the reason.",
+		}, []string{"reason"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httprequests,
+		m.httpduration,
+		m.grpcrequests,
+		m.grpcduration,
+		m.hashduration,
+		m.signups,
+		m.signinfailures,
+		m.refreshes,
+		m.lockouts,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// RegisterDB exports the connection pool stats of the database, they're read when the metrics are scraped
+func (m *Metrics) RegisterDB(name string, db IDBStats) error {
+	return m.registry.Register(newDBCollector(name, db))
+}
+
+// ObserveHTTP records the REST request, route is the pattern of the matched route or empty
+func (m *Metrics) ObserveHTTP(method, route string, code int, duration time.Duration) {
+	if route == "" {
+		route = unmatchedRoute
+	}
+	m.httprequests.WithLabelValues(method, route, strconv.Itoa(code)).Inc()
+	m.httpduration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObserveGRPC records the gRPC call, method is the full method name
+func (m *Metrics) ObserveGRPC(method, code string, duration time.Duration) {
+	m.grpcrequests.WithLabelValues(method, code).Inc()
+	m.grpcduration.WithLabelValues(method).Observe(duration.Seconds())
+}
+
+// ObserveHash records the password hashing, operation is HashOperation or CompareOperation
+func (m *Metrics) ObserveHash(operation string, duration time.Duration) {
+	m.hashduration.WithLabelValues(operation).Observe(duration.Seconds())
+}
+
+func (m *Metrics) SignedUp() {
+	m.signups.Inc()
+}
+
+func (m *Metrics) SignInFailed(reason string) {
+	m.signinfailures.WithLabelValues(reason).Inc()
+}
+
+func (m *Metrics) Refreshed() {
+	m.refreshes.Inc()
+}
+
+func (m *Metrics) LockedOut(reason string) {
+	m.lockouts.WithLabelValues(reason).Inc()
+}
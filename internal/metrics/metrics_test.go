@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type dbStub struct{}
+
+func (dbStub) Stats() sql.DBStats {
+	return sql.DBStats{MaxOpenConnections: 10, OpenConnections: 3, InUse: 2, Idle: 1}
+}
+
+type hasherStub struct{}
+
+func (hasherStub) Hash(str string) (string, error) {
+	return "hash", nil
+}
+
+func (hasherStub) Compare(str, hash string) error {
+	return errors.New("mismatch")
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	m := New()
+	require.NoError(t, m.RegisterDB("postgres", dbStub{}))
+
+	m.ObserveHTTP(http.MethodGet, "/v1/sessions/:uuid", http.StatusOK, time.Millisecond)
+	m.ObserveHTTP(http.MethodGet, "", http.StatusNotFound, time.Millisecond)
+	m.ObserveGRPC("/Account/GetOne", "NotFound", time.Millisecond)
+	m.SignedUp()
+	m.SignInFailed("wrong password")
+	m.SignInFailed("wrong password")
+	m.Refreshed()
+	m.LockedOut("not me")
+
+	hasher := m.Hasher(hasherStub{})
+	_, err := hasher.Hash("password")
+	require.NoError(t, err)
+	assert.Error(t, hasher.Compare("password", "hash"))
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+
+	for _, line := range []string{
+		`runbot_auth_http_requests_total{code="200",method="GET",route="/v1/sessions/:uuid"} 1`,
+		`runbot_auth_http_requests_total{code="404",method="GET",route="unmatched"} 1`,
+		`runbot_auth_http_request_duration_seconds_count{method="GET",route="/v1/sessions/:uuid"} 1`,
+		`runbot_auth_grpc_requests_total{code="NotFound",method="/Account/GetOne"} 1`,
+		`runbot_auth_password_hash_duration_seconds_count{operation="hash"} 1`,
+		`runbot_auth_password_hash_duration_seconds_count{operation="compare"} 1`,
+		`runbot_auth_signups_total 1`,
+		`runbot_auth_signin_failures_total{reason="wrong password"} 2`,
+		`runbot_auth_refreshes_total 1`,
+		`runbot_auth_lockouts_total{reason="not me"} 1`,
+		`runbot_auth_db_open_connections{db="postgres"} 3`,
+		`runbot_auth_db_max_open_connections{db="postgres"} 10`,
+	} {
+		assert.Contains(t, string(body), line)
+	}
+}
@@ -0,0 +1,149 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	kafkaContentType = "application/vnd.kafka.json.v2+json"
+	kafkaAccept      = "application/vnd.kafka.v2+json"
+)
+
+type KafkaConfig struct {
+	// RESTProxyURL is the base URL of the Kafka REST Proxy, e.g. http://kafka-rest:8082
+	RESTProxyURL string
+	Topic        string
+	// Timeout bounds a produce request, DefaultTimeout if it isn't positive
+	Timeout time.Duration
+}
+
+// Kafka produces the events through the Confluent Kafka REST Proxy (API v2), it doesn't talk to the brokers
+// directly. The account UUID is the record key, so the events of an account go to the same partition
+// and keep their order
+type Kafka struct {
+	endpoint string
+	client   *http.Client
+}
+
+// kafkaRecords is the produce request
+type kafkaRecords struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+type kafkaRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// kafkaOffsets is the produce response, a record which isn't written has the error set
+type kafkaOffsets struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+func NewKafka(c *KafkaConfig) (*Kafka, error) {
+	if c == nil {
+		return nil, ErrConfigIsNil
+	}
+	if c.RESTProxyURL == "" {
+		return nil, ErrURLIsEmpty
+	}
+	if c.Topic == "" {
+		return nil, ErrTopicIsEmpty
+	}
+
+	u, err := url.Parse(c.RESTProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrURLIsNotValid, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: http(s)://host:port is expected", ErrURLIsNotValid)
+	}
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &Kafka{
+		endpoint: strings.TrimSuffix(u.String(), "/") + "/topics/" + url.PathEscape(c.Topic),
+		client:   &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (p *Kafka) Publish(ctx context.Context, event *entities.DomainEvent) error {
+	msg, err := event.Message()
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(&kafkaRecords{
+		Records: []kafkaRecord{{Key: event.AccountUUID, Value: msg}},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", kafkaAccept)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respbody, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%w: %s: %s", ErrBrokerIsRejected, resp.Status, truncate(string(respbody), responseMaxLength))
+	}
+
+	var offsets kafkaOffsets
+	if err = json.Unmarshal(respbody, &offsets); err != nil {
+		return err
+	}
+	for _, offset := range offsets.Offsets {
+		if offset.ErrorCode != nil {
+			return fmt.Errorf("%w: error code %d: %s", ErrBrokerIsRejected, *offset.ErrorCode, truncate(offset.Error, responseMaxLength))
+		}
+	}
+	return nil
+}
+
+// Ping checks the REST Proxy knows the topic
+func (p *Kafka) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", kafkaAccept)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%w: %s", ErrBrokerIsNotReady, resp.Status)
+	}
+	return nil
+}
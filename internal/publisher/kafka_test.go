@@ -0,0 +1,125 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewKafka(t *testing.T) {
+	_, err := NewKafka(nil)
+	assert.ErrorIs(t, err, ErrConfigIsNil)
+
+	_, err = NewKafka(&KafkaConfig{Topic: "runbot.auth"})
+	assert.ErrorIs(t, err, ErrURLIsEmpty)
+
+	_, err = NewKafka(&KafkaConfig{RESTProxyURL: "http://kafka-rest:8082"})
+	assert.ErrorIs(t, err, ErrTopicIsEmpty)
+
+	_, err = NewKafka(&KafkaConfig{RESTProxyURL: "kafka-rest:8082", Topic: "runbot.auth"})
+	assert.ErrorIs(t, err, ErrURLIsNotValid)
+
+	p, err := NewKafka(&KafkaConfig{RESTProxyURL: "http://kafka-rest:8082/", Topic: "runbot.auth"})
+	require.NoError(t, err)
+	assert.Equal(t, "http://kafka-rest:8082/topics/runbot.auth", p.endpoint)
+	assert.Equal(t, DefaultTimeout, p.client.Timeout)
+}
+
+func TestKafka_Publish(t *testing.T) {
+	testCases := []struct {
+		name        string
+		status      int
+		response    string
+		expectedErr error
+	}{
+		{
+			name:     "Published",
+			status:   http.StatusOK,
+			response: `{"offsets":[{"partition":0,"offset":42,"error_code":null,"error":null}]}`,
+		},
+		{
+			name:        "Record is rejected",
+			status:      http.StatusOK,
+			response:    `{"offsets":[{"partition":null,"offset":null,"error_code":50002,"error":"Kafka error"}]}`,
+			expectedErr: ErrBrokerIsRejected,
+		},
+		{
+			name:        "Topic is not found",
+			status:      http.StatusNotFound,
+			response:    `{"error_code":40401,"message":"Topic not found."}`,
+			expectedErr: ErrBrokerIsRejected,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/topics/runbot.auth", r.URL.Path)
+				assert.Equal(t, kafkaContentType, r.Header.Get("Content-Type"))
+
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				var records kafkaRecords
+				require.NoError(t, json.Unmarshal(body, &records))
+				require.Len(t, records.Records, 1)
+				assert.Equal(t, "accountuuid", records.Records[0].Key)
+				assert.Contains(t, string(records.Records[0].Value), `"ID":"eventuuid"`)
+
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.response))
+			}))
+			defer server.Close()
+
+			p, err := NewKafka(&KafkaConfig{RESTProxyURL: server.URL, Topic: "runbot.auth"})
+			require.NoError(t, err)
+
+			err = p.Publish(context.Background(), newTestEvent())
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestKafka_Ping(t *testing.T) {
+	testCases := []struct {
+		name        string
+		status      int
+		expectedErr error
+	}{
+		{
+			name:   "Topic is found",
+			status: http.StatusOK,
+		},
+		{
+			name:        "Topic is not found",
+			status:      http.StatusNotFound,
+			expectedErr: ErrBrokerIsNotReady,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodGet, r.Method)
+				assert.Equal(t, "/topics/runbot.auth", r.URL.Path)
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			p, err := NewKafka(&KafkaConfig{RESTProxyURL: server.URL, Topic: "runbot.auth"})
+			require.NoError(t, err)
+
+			err = p.Ping(context.Background())
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
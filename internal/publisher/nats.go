@@ -0,0 +1,167 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	natsClientName = "runbot-auth"
+)
+
+type NATSConfig struct {
+	// URL is nats://[user:password@]host[:port] or tls://..., the credentials are optional
+	URL string
+	// SubjectPrefix is followed by the event type, e.g. runbot.auth gives runbot.auth.account.created.
+	// A JetStream stream must capture the subjects, e.g. runbot.auth.>
+	SubjectPrefix string
+	// Timeout bounds a publish with its ack, DefaultTimeout if it isn't positive
+	Timeout time.Duration
+}
+
+// NATS publishes the events to a NATS JetStream stream. A publish returns once the stream has stored
+// the event and acked it, the event UUID is the message ID, so the stream drops the republished events
+// within its duplicate window. The connection is dialed by the first publish or ping, reconnected
+// by the client and redialed once it's closed
+type NATS struct {
+	url     string
+	prefix  string
+	timeout time.Duration
+
+	mu   sync.Mutex
+	conn *nats.Conn
+	js   jetstream.JetStream
+}
+
+func NewNATS(c *NATSConfig) (*NATS, error) {
+	if c == nil {
+		return nil, ErrConfigIsNil
+	}
+	if c.URL == "" {
+		return nil, ErrURLIsEmpty
+	}
+	if c.SubjectPrefix == "" {
+		return nil, ErrSubjectIsEmpty
+	}
+	if strings.ContainsAny(c.SubjectPrefix, " \t\r\n") {
+		return nil, ErrSubjectIsNotValid
+	}
+
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrURLIsNotValid, err)
+	}
+	if (u.Scheme != "nats" && u.Scheme != "tls") || u.Hostname() == "" {
+		return nil, fmt.Errorf("%w: nats://host:port is expected", ErrURLIsNotValid)
+	}
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &NATS{
+		url:     c.URL,
+		prefix:  strings.TrimSuffix(c.SubjectPrefix, "."),
+		timeout: timeout,
+	}, nil
+}
+
+func (p *NATS) Publish(ctx context.Context, event *entities.DomainEvent) error {
+	msg, err := event.Message()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	_, js, err := p.connect()
+	if err != nil {
+		return err
+	}
+
+	// the ack means the event is stored, a duplicate ack means it had been stored before
+	_, err = js.Publish(ctx, p.prefix+"."+event.Type, msg, jetstream.WithMsgID(event.UUID))
+	return natsError(err)
+}
+
+// Ping checks JetStream answers, the connection is dialed if there's none
+func (p *NATS) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	conn, js, err := p.connect()
+	if err != nil {
+		return err
+	}
+	if status := conn.Status(); status != nats.CONNECTED {
+		return fmt.Errorf("%w: the connection is %s", ErrBrokerIsNotReady, status)
+	}
+	_, err = js.AccountInfo(ctx)
+	return natsError(err)
+}
+
+// Close closes the connection, a following publish redials
+func (p *NATS) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn, p.js = nil, nil
+	}
+	return nil
+}
+
+// connect returns the connection and its JetStream context, the connection is dialed if there's none
+// or the client has given up reconnecting
+func (p *NATS) connect() (*nats.Conn, jetstream.JetStream, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn != nil && !p.conn.IsClosed() {
+		return p.conn, p.js, nil
+	}
+
+	conn, err := nats.Connect(p.url,
+		nats.Name(natsClientName),
+		nats.Timeout(p.timeout),
+		nats.MaxReconnects(-1),
+	)
+	if err != nil {
+		return nil, nil, natsError(err)
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	p.conn, p.js = conn, js
+	return conn, js, nil
+}
+
+// natsError marks the refusals of the server with ErrBrokerIsRejected, e.g. no stream captures the subject
+func natsError(err error) error {
+	var apierr *jetstream.APIError
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, nats.ErrAuthorization),
+		errors.Is(err, jetstream.ErrNoStreamResponse),
+		errors.Is(err, jetstream.ErrJetStreamNotEnabled),
+		errors.Is(err, jetstream.ErrJetStreamNotEnabledForAccount),
+		errors.As(err, &apierr):
+		return fmt.Errorf("%w: %s", ErrBrokerIsRejected, truncate(err.Error(), responseMaxLength))
+	default:
+		return err
+	}
+}
@@ -0,0 +1,111 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultTimeout = 10 * time.Second
+
+	// responseMaxLength bounds the broker response quoted in an error
+	responseMaxLength = 256
+)
+
+var (
+	ErrConfigIsNil       = errors.New("config is nil")
+	ErrURLIsEmpty        = errors.New("broker url is empty")
+	ErrURLIsNotValid     = errors.New("broker url is not valid")
+	ErrSubjectIsEmpty    = errors.New("nats subject prefix is empty")
+	ErrSubjectIsNotValid = errors.New("nats subject prefix has whitespaces")
+	ErrTopicIsEmpty      = errors.New("kafka topic is empty")
+	ErrBrokerIsRejected  = errors.New("broker rejected the event")
+	ErrBrokerIsNotReady  = errors.New("broker is not ready")
+)
+
+// InProcess delivers the events to the subscribers of the same process, the subscribers are called
+// one by one in the relay goroutine, so they must be quick. It stands for a broker in the tests
+// and when no broker is configured
+type InProcess struct {
+	mu          sync.RWMutex
+	lastid      int
+	subscribers map[int]func(ctx context.Context, event *entities.DomainEvent)
+}
+
+func NewInProcess() *InProcess {
+	return &InProcess{
+		subscribers: make(map[int]func(ctx context.Context, event *entities.DomainEvent)),
+	}
+}
+
+// Subscribe adds fn to the subscribers, the returned function removes it
+func (p *InProcess) Subscribe(fn func(ctx context.Context, event *entities.DomainEvent)) func() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.lastid++
+	id := p.lastid
+	p.subscribers[id] = fn
+
+	return func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		delete(p.subscribers, id)
+	}
+}
+
+// Publish gives every subscriber its own copy of the event
+func (p *InProcess) Publish(ctx context.Context, event *entities.DomainEvent) error {
+	// the subscribers are called without the lock, so they may unsubscribe
+	p.mu.RLock()
+	subscribers := make([]func(ctx context.Context, event *entities.DomainEvent), 0, len(p.subscribers))
+	for _, fn := range p.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	p.mu.RUnlock()
+
+	for _, fn := range subscribers {
+		copied := *event
+		fn(ctx, &copied)
+	}
+	return nil
+}
+
+// IPublisher is a destination of Fanout
+type IPublisher interface {
+	Publish(ctx context.Context, event *entities.DomainEvent) error
+}
+
+// Fanout publishes every event to all the publishers, e.g. to the broker and the webhooks.
+// The event is published again to all of them if any fails, so they must tolerate the duplicates
+type Fanout struct {
+	publishers []IPublisher
+}
+
+func NewFanout(publishers ...IPublisher) *Fanout {
+	return &Fanout{
+		publishers: publishers,
+	}
+}
+
+// Publish tries all the publishers and joins their errors
+func (p *Fanout) Publish(ctx context.Context, event *entities.DomainEvent) error {
+	var errs []error
+	for _, publisher := range p.publishers {
+		if err := publisher.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// truncate shortens the broker response for an error
+func truncate(s string, length int) string {
+	if len(s) <= length {
+		return s
+	}
+	return s[:length]
+}
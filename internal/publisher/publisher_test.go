@@ -0,0 +1,93 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func newTestEvent() *entities.DomainEvent {
+	return &entities.DomainEvent{
+		ID:          1,
+		UUID:        "eventuuid",
+		Type:        entities.DomainEventAccountCreated,
+		AccountUUID: "accountuuid",
+		Payload:     `{"Email":"bob@example.com"}`,
+		CreatedAt:   100,
+	}
+}
+
+func TestInProcess(t *testing.T) {
+	p := NewInProcess()
+	ctx := context.Background()
+
+	// nobody listens, the event is dropped
+	require.NoError(t, p.Publish(ctx, newTestEvent()))
+
+	var first, second []*entities.DomainEvent
+	unsubscribe := p.Subscribe(func(_ context.Context, event *entities.DomainEvent) {
+		first = append(first, event)
+	})
+	p.Subscribe(func(_ context.Context, event *entities.DomainEvent) {
+		event.Type = "changed by the subscriber"
+		second = append(second, event)
+	})
+
+	event := newTestEvent()
+	require.NoError(t, p.Publish(ctx, event))
+	require.Len(t, first, 1)
+	require.Len(t, second, 1)
+	assert.Equal(t, newTestEvent(), first[0])
+	assert.Equal(t, entities.DomainEventAccountCreated, event.Type)
+
+	unsubscribe()
+	require.NoError(t, p.Publish(ctx, event))
+	assert.Len(t, first, 1)
+	assert.Len(t, second, 2)
+}
+
+// publisherFunc is a publisher of the Fanout tests
+type publisherFunc func(ctx context.Context, event *entities.DomainEvent) error
+
+func (f publisherFunc) Publish(ctx context.Context, event *entities.DomainEvent) error {
+	return f(ctx, event)
+}
+
+func TestFanout(t *testing.T) {
+	ctx := context.Background()
+
+	var published []string
+	publisher := func(name string, err error) IPublisher {
+		return publisherFunc(func(_ context.Context, event *entities.DomainEvent) error {
+			published = append(published, name+":"+event.UUID)
+			return err
+		})
+	}
+
+	require.NoError(t, NewFanout(publisher("broker", nil), publisher("webhooks", nil)).Publish(ctx, newTestEvent()))
+	assert.Equal(t, []string{"broker:eventuuid", "webhooks:eventuuid"}, published)
+
+	// a failed publisher doesn't stop the others
+	published = nil
+	failure := errors.New("broker is unavailable")
+	err := NewFanout(publisher("broker", failure), publisher("webhooks", nil)).Publish(ctx, newTestEvent())
+	assert.ErrorIs(t, err, failure)
+	assert.Equal(t, []string{"broker:eventuuid", "webhooks:eventuuid"}, published)
+
+	require.NoError(t, NewFanout().Publish(ctx, newTestEvent()))
+}
+
+func TestMessage(t *testing.T) {
+	msg, err := newTestEvent().Message()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"ID": "eventuuid",
+		"Type": "account.created",
+		"AccountUUID": "accountuuid",
+		"CreatedAt": 100,
+		"Data": {"Email": "bob@example.com"}
+	}`, string(msg))
+}
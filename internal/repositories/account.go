@@ -1,11 +1,63 @@
 package repositories
 
+import "strings"
+
+const (
+	// AnonymizedEmailDomain is a reserved domain (RFC 2606), so purged emails can't collide with real ones
+	AnonymizedEmailDomain = "deleted.invalid"
+)
+
 type Account struct {
-	UUID      string
-	Name      string
-	Email     string
-	Password  string
-	Status    uint8
+	UUID        string
+	Name        string
+	Email       string
+	Password    string
+	Status      uint8
+	StatusUntil int64
+	CreatedAt   int64
+	UpdatedAt   int64
+	DeletedAt   int64
+	PurgedAt    int64
+}
+
+// AnonymizedEmail replaces the email of a purged account and keeps the email unique
+func AnonymizedEmail(uuid string) string {
+	return uuid + "@" + AnonymizedEmailDomain
+}
+
+const (
+	AccountSortCreatedAt = "createdat"
+	AccountSortEmail     = "email"
+)
+
+// AccountKey is the position of an account in a sorted listing, only the key of the sort is set with the UUID
+type AccountKey struct {
 	CreatedAt int64
-	UpdatedAt int64
+	// Email is lowercase
+	Email string
+	UUID  string
+}
+
+// AccountFilter selects the accounts for the admin search, the zero fields don't filter.
+// The accounts are sorted by Sort and then by UUID, After continues the listing after the account with this key
+type AccountFilter struct {
+	Statuses []uint8
+	// EmailPrefix and NameContains match case-insensitively
+	EmailPrefix  string
+	NameContains string
+	// CreatedSince and CreatedUntil bound the creation time, CreatedUntil is exclusive
+	CreatedSince int64
+	CreatedUntil int64
+	// Sort is AccountSortCreatedAt or AccountSortEmail
+	Sort       string
+	Descending bool
+	After      *AccountKey
+	Limit      int
 }
+
+// EscapeLike makes s match itself in a LIKE pattern with the backslash escape
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
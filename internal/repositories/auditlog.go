@@ -0,0 +1,16 @@
+package repositories
+
+type AuditRecord struct {
+	ID         int64
+	Actor      string
+	Action     string
+	TargetUUID string
+	Before     string
+	After      string
+	Method     string
+	IP         string
+	UserAgent  string
+	CreatedAt  int64
+	PrevHash   string
+	Hash       string
+}
@@ -0,0 +1,316 @@
+package dbmemory
+
+import (
+	"cmp"
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Account keeps accounts in the process memory, it's intended for local development and tests
+type Account struct {
+	mu      sync.RWMutex
+	byuuid  map[string]*repositories.Account
+	byemail map[string]string
+}
+
+func NewAccount() *Account {
+	return &Account{
+		byuuid:  make(map[string]*repositories.Account),
+		byemail: make(map[string]string),
+	}
+}
+
+func (r *Account) IsExist(_ context.Context, account *entities.Account) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.byemail[emailKey(account.Email)]
+	return ok, nil
+}
+
+func (r *Account) IsExistByUUID(_ context.Context, uuid string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.byuuid[uuid]
+	return ok, nil
+}
+
+func (r *Account) Create(_ context.Context, account *entities.Account) (*entities.Account, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := emailKey(account.Email)
+	if _, ok := r.byemail[key]; ok {
+		return nil, repositories.NewErrAccountAlreadyExists(account.Email)
+	}
+	if _, ok := r.byuuid[account.UUID]; ok {
+		return nil, repositories.NewErrAccountAlreadyExists(account.Email)
+	}
+
+	repoaccount := r.entity2repo(account)
+	r.byuuid[repoaccount.UUID] = repoaccount
+	r.byemail[key] = repoaccount.UUID
+
+	return r.repo2entity(repoaccount), nil
+}
+
+func (r *Account) GetOneByEmail(_ context.Context, email string) (*entities.Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	uuid, ok := r.byemail[emailKey(email)]
+	if !ok {
+		return nil, repositories.NewErrAccountNotFoundByEmail(email)
+	}
+	return r.repo2entity(r.byuuid[uuid]), nil
+}
+
+func (r *Account) GetOneByUUID(_ context.Context, uuid string) (*entities.Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	account, ok := r.byuuid[uuid]
+	if !ok {
+		return nil, repositories.NewErrAccountNotFoundByUUID(uuid)
+	}
+	return r.repo2entity(account), nil
+}
+
+func (r *Account) GetManyByUUIDs(_ context.Context, uuids []string) ([]*entities.Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var accounts []*entities.Account
+	for _, uuid := range uuids {
+		if account, ok := r.byuuid[uuid]; ok {
+			accounts = append(accounts, r.repo2entity(account))
+		}
+	}
+	return accounts, nil
+}
+
+func (r *Account) Find(_ context.Context, filter *repositories.AccountFilter) ([]*entities.Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var found []*repositories.Account
+	for _, account := range r.byuuid {
+		if r.match(account, filter) {
+			found = append(found, account)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if filter.Descending {
+			i, j = j, i
+		}
+		return r.compare(found[i], accountKey(found[j]), filter.Sort) < 0
+	})
+	return r.limit(found, filter.Limit), nil
+}
+
+func (r *Account) SetAccountStatus(_ context.Context, uuid string, status uint8, until int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	account, ok := r.byuuid[uuid]
+	if !ok || account.PurgedAt != 0 {
+		return repositories.NewErrAccountNotFoundByUUID(uuid)
+	}
+	account.Status = status
+	account.StatusUntil = until
+	account.UpdatedAt = time.Now().Unix()
+	// restoring a deleted account cancels its purging
+	account.DeletedAt = 0
+	return nil
+}
+
+func (r *Account) SetPassword(_ context.Context, uuid, password string, updatedat int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	account, ok := r.byuuid[uuid]
+	if !ok || account.PurgedAt != 0 {
+		return repositories.NewErrAccountNotFoundByUUID(uuid)
+	}
+	account.Password = password
+	account.UpdatedAt = updatedat
+	return nil
+}
+
+func (r *Account) MarkAccountDeleted(_ context.Context, uuid string, deletedat int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	account, ok := r.byuuid[uuid]
+	if !ok || account.PurgedAt != 0 {
+		return repositories.NewErrAccountNotFoundByUUID(uuid)
+	}
+	account.Status = entities.Deleted
+	account.StatusUntil = 0
+	account.DeletedAt = deletedat
+	account.UpdatedAt = deletedat
+	return nil
+}
+
+func (r *Account) GetDeletedBefore(_ context.Context, before int64, limit int) ([]*entities.Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var found []*repositories.Account
+	for _, account := range r.byuuid {
+		if account.Status == entities.Deleted && account.PurgedAt == 0 && account.DeletedAt <= before {
+			found = append(found, account)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].DeletedAt < found[j].DeletedAt
+	})
+	return r.limit(found, limit), nil
+}
+
+func (r *Account) GetStatusExpiredBefore(_ context.Context, before int64, limit int) ([]*entities.Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var found []*repositories.Account
+	for _, account := range r.byuuid {
+		if account.StatusUntil != 0 && account.StatusUntil <= before && account.PurgedAt == 0 {
+			found = append(found, account)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].StatusUntil < found[j].StatusUntil
+	})
+	return r.limit(found, limit), nil
+}
+
+func (r *Account) PurgeAccount(_ context.Context, uuid string, purgedat int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	account, ok := r.byuuid[uuid]
+	if !ok || account.Status != entities.Deleted || account.PurgedAt != 0 {
+		return repositories.NewErrAccountNotFoundByUUID(uuid)
+	}
+
+	delete(r.byemail, emailKey(account.Email))
+	account.Email = repositories.AnonymizedEmail(uuid)
+	account.Name = ""
+	account.Password = ""
+	account.PurgedAt = purgedat
+	account.UpdatedAt = purgedat
+	r.byemail[emailKey(account.Email)] = uuid
+	return nil
+}
+
+// limit copies up to limit sorted accounts
+func (r *Account) limit(found []*repositories.Account, limit int) []*entities.Account {
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	accounts := make([]*entities.Account, 0, len(found))
+	for _, account := range found {
+		accounts = append(accounts, r.repo2entity(account))
+	}
+	return accounts
+}
+
+// clone must be called under the lock
+func (r *Account) clone() *Account {
+	c := NewAccount()
+	for uuid, account := range r.byuuid {
+		copied := *account
+		c.byuuid[uuid] = &copied
+	}
+	for email, uuid := range r.byemail {
+		c.byemail[email] = uuid
+	}
+	return c
+}
+
+// emailKey makes the lookups case-insensitive like the unique index of the SQL storages
+func (r *Account) match(account *repositories.Account, filter *repositories.AccountFilter) bool {
+	switch {
+	case len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, account.Status):
+		return false
+	case filter.EmailPrefix != "" && !strings.HasPrefix(emailKey(account.Email), strings.ToLower(filter.EmailPrefix)):
+		return false
+	case filter.NameContains != "" && !strings.Contains(strings.ToLower(account.Name), strings.ToLower(filter.NameContains)):
+		return false
+	case filter.CreatedSince > 0 && account.CreatedAt < filter.CreatedSince:
+		return false
+	case filter.CreatedUntil > 0 && account.CreatedAt >= filter.CreatedUntil:
+		return false
+	case filter.After == nil:
+		return true
+	case filter.Descending:
+		return r.compare(account, filter.After, filter.Sort) < 0
+	default:
+		return r.compare(account, filter.After, filter.Sort) > 0
+	}
+}
+
+// compare orders the account and the key as the SQL repositories do
+func (r *Account) compare(account *repositories.Account, key *repositories.AccountKey, sortkey string) int {
+	accountkey := accountKey(account)
+	var c int
+	if sortkey == repositories.AccountSortEmail {
+		c = strings.Compare(accountkey.Email, key.Email)
+	} else {
+		c = cmp.Compare(accountkey.CreatedAt, key.CreatedAt)
+	}
+	if c != 0 {
+		return c
+	}
+	return strings.Compare(accountkey.UUID, key.UUID)
+}
+
+func accountKey(account *repositories.Account) *repositories.AccountKey {
+	return &repositories.AccountKey{
+		CreatedAt: account.CreatedAt,
+		Email:     emailKey(account.Email),
+		UUID:      account.UUID,
+	}
+}
+
+func emailKey(email string) string {
+	return strings.ToLower(email)
+}
+
+// entity2repo and repo2entity copy the account, so callers can't change the stored one
+func (r *Account) entity2repo(entity *entities.Account) *repositories.Account {
+	return &repositories.Account{
+		UUID:        entity.UUID,
+		Name:        entity.Name,
+		Email:       entity.Email,
+		Password:    entity.Password,
+		Status:      entity.Status,
+		StatusUntil: entity.StatusUntil,
+		CreatedAt:   entity.CreatedAt,
+		UpdatedAt:   entity.UpdatedAt,
+		DeletedAt:   entity.DeletedAt,
+		PurgedAt:    entity.PurgedAt,
+	}
+}
+
+func (r *Account) repo2entity(repo *repositories.Account) *entities.Account {
+	return &entities.Account{
+		UUID:        repo.UUID,
+		Name:        repo.Name,
+		Email:       repo.Email,
+		Password:    repo.Password,
+		Status:      repo.Status,
+		StatusUntil: repo.StatusUntil,
+		CreatedAt:   repo.CreatedAt,
+		UpdatedAt:   repo.UpdatedAt,
+		DeletedAt:   repo.DeletedAt,
+		PurgedAt:    repo.PurgedAt,
+	}
+}
@@ -0,0 +1,38 @@
+package dbmemory
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/repositories/repotest"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"testing"
+)
+
+func TestAccount(t *testing.T) {
+	repotest.AccountRepo(t, func(t *testing.T) usecases.IAccountRepo {
+		return NewAccount()
+	})
+}
+
+func TestAccountImport(t *testing.T) {
+	repotest.AccountImportRepo(t, func(t *testing.T) (usecases.IAccountImportRepo, usecases.IAccountRepo) {
+		account := NewAccount()
+		return NewAccountImport(account), account
+	})
+}
+
+func TestTransactor(t *testing.T) {
+	repotest.Transactor(t, func(t *testing.T) (usecases.ITransactor, *usecases.TxRepos) {
+		account, statushistory, session, securityevent, auditlog, outbox := NewAccount(), NewStatusHistory(), NewSession(), NewSecurityEvent(), NewAuditLog(), NewOutbox()
+		export, webhook, delivery := NewExport(), NewWebhook(), NewWebhookDelivery()
+		return NewTransactor(account, statushistory, session, securityevent, auditlog, export, outbox, webhook, delivery), &usecases.TxRepos{
+			Account:         account,
+			StatusHistory:   statushistory,
+			Session:         session,
+			SecurityEvent:   securityevent,
+			AuditLog:        auditlog,
+			Export:          export,
+			Outbox:          outbox,
+			Webhook:         webhook,
+			WebhookDelivery: delivery,
+		}
+	})
+}
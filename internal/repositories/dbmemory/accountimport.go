@@ -0,0 +1,39 @@
+package dbmemory
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+)
+
+// AccountImport loads the accounts in bulk into the Account repository
+type AccountImport struct {
+	account *Account
+}
+
+func NewAccountImport(account *Account) *AccountImport {
+	return &AccountImport{
+		account: account,
+	}
+}
+
+// Import inserts the accounts, the accounts whose UUID or email exists are skipped, their UUIDs are returned
+func (r *AccountImport) Import(_ context.Context, accounts []*entities.Account) ([]string, error) {
+	r.account.mu.Lock()
+	defer r.account.mu.Unlock()
+
+	var skipped []string
+	for _, account := range accounts {
+		key := emailKey(account.Email)
+		_, emailexists := r.account.byemail[key]
+		_, uuidexists := r.account.byuuid[account.UUID]
+		if emailexists || uuidexists {
+			skipped = append(skipped, account.UUID)
+			continue
+		}
+
+		repoaccount := r.account.entity2repo(account)
+		r.account.byuuid[repoaccount.UUID] = repoaccount
+		r.account.byemail[key] = repoaccount.UUID
+	}
+	return skipped, nil
+}
@@ -0,0 +1,110 @@
+package dbmemory
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"slices"
+	"sort"
+	"sync"
+)
+
+var ErrAuditRecordIsNotChained = errors.New("audit record doesn't follow the latest one")
+
+type AuditLog struct {
+	mu     sync.RWMutex
+	lastid int64
+	// records are kept in the order of addition, so the IDs are ascending
+	records []*repositories.AuditRecord
+}
+
+func NewAuditLog() *AuditLog {
+	return &AuditLog{}
+}
+
+func (r *AuditLog) Add(_ context.Context, record *entities.AuditRecord) (*entities.AuditRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// the same as the unique prevhash of the SQL storages
+	for _, existing := range r.records {
+		if existing.PrevHash == record.PrevHash {
+			return nil, ErrAuditRecordIsNotChained
+		}
+	}
+
+	r.lastid++
+	reporecord := r.entity2repo(record)
+	reporecord.ID = r.lastid
+	r.records = append(r.records, reporecord)
+
+	return r.repo2entity(reporecord), nil
+}
+
+func (r *AuditLog) Last(_ context.Context) (*entities.AuditRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.records) == 0 {
+		return nil, nil
+	}
+	return r.repo2entity(r.records[len(r.records)-1]), nil
+}
+
+func (r *AuditLog) List(_ context.Context, afterid int64, limit int) ([]*entities.AuditRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	start := sort.Search(len(r.records), func(i int) bool {
+		return r.records[i].ID > afterid
+	})
+
+	var records []*entities.AuditRecord
+	for i := start; i < len(r.records) && len(records) < limit; i++ {
+		records = append(records, r.repo2entity(r.records[i]))
+	}
+	return records, nil
+}
+
+// clone must be called under the lock
+func (r *AuditLog) clone() *AuditLog {
+	return &AuditLog{
+		lastid:  r.lastid,
+		records: slices.Clone(r.records),
+	}
+}
+
+func (r *AuditLog) entity2repo(entity *entities.AuditRecord) *repositories.AuditRecord {
+	return &repositories.AuditRecord{
+		ID:         entity.ID,
+		Actor:      entity.Actor,
+		Action:     entity.Action,
+		TargetUUID: entity.TargetUUID,
+		Before:     entity.Before,
+		After:      entity.After,
+		Method:     entity.Method,
+		IP:         entity.IP,
+		UserAgent:  entity.UserAgent,
+		CreatedAt:  entity.CreatedAt,
+		PrevHash:   entity.PrevHash,
+		Hash:       entity.Hash,
+	}
+}
+
+func (r *AuditLog) repo2entity(repo *repositories.AuditRecord) *entities.AuditRecord {
+	return &entities.AuditRecord{
+		ID:         repo.ID,
+		Actor:      repo.Actor,
+		Action:     repo.Action,
+		TargetUUID: repo.TargetUUID,
+		Before:     repo.Before,
+		After:      repo.After,
+		Method:     repo.Method,
+		IP:         repo.IP,
+		UserAgent:  repo.UserAgent,
+		CreatedAt:  repo.CreatedAt,
+		PrevHash:   repo.PrevHash,
+		Hash:       repo.Hash,
+	}
+}
@@ -0,0 +1,13 @@
+package dbmemory
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/repositories/repotest"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"testing"
+)
+
+func TestAuditLog(t *testing.T) {
+	repotest.AuditLogRepo(t, func(t *testing.T) usecases.IAuditLogRepo {
+		return NewAuditLog()
+	})
+}
@@ -0,0 +1,176 @@
+package dbmemory
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"sync"
+)
+
+type Export struct {
+	mu     sync.Mutex
+	byuuid map[string]*repositories.AccountExport
+}
+
+func NewExport() *Export {
+	return &Export{
+		byuuid: make(map[string]*repositories.AccountExport),
+	}
+}
+
+func (r *Export) Create(_ context.Context, export *entities.AccountExport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byuuid[export.UUID] = r.entity2repo(export)
+	return nil
+}
+
+func (r *Export) GetOneByUUID(_ context.Context, uuid string) (*entities.AccountExport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	export, ok := r.byuuid[uuid]
+	if !ok {
+		return nil, repositories.NewErrExportNotFoundByUUID(uuid)
+	}
+	return r.repo2entity(export), nil
+}
+
+func (r *Export) GetUnfinishedByAccount(_ context.Context, accountuuid string) (*entities.AccountExport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var found *repositories.AccountExport
+	for _, export := range r.byuuid {
+		if export.AccountUUID != accountuuid {
+			continue
+		}
+		if export.Status != entities.ExportPending && export.Status != entities.ExportProcessing {
+			continue
+		}
+		if found == nil || export.CreatedAt > found.CreatedAt {
+			found = export
+		}
+	}
+	if found == nil {
+		return nil, nil
+	}
+	return r.repo2entity(found), nil
+}
+
+func (r *Export) ClaimPending(_ context.Context, now, stalebefore int64) (*entities.AccountExport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var found *repositories.AccountExport
+	for _, export := range r.byuuid {
+		claimable := export.Status == entities.ExportPending ||
+			(export.Status == entities.ExportProcessing && export.UpdatedAt <= stalebefore)
+		if claimable && (found == nil || export.CreatedAt < found.CreatedAt) {
+			found = export
+		}
+	}
+	if found == nil {
+		return nil, nil
+	}
+
+	found.Status = entities.ExportProcessing
+	found.UpdatedAt = now
+	return r.repo2entity(found), nil
+}
+
+func (r *Export) SetReady(_ context.Context, uuid string, archive []byte, readyat, expiresat int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	export, ok := r.byuuid[uuid]
+	if !ok {
+		return repositories.NewErrExportNotFoundByUUID(uuid)
+	}
+	export.Status = entities.ExportReady
+	export.Archive = append([]byte(nil), archive...)
+	export.ReadyAt = readyat
+	export.UpdatedAt = readyat
+	export.ExpiresAt = expiresat
+	return nil
+}
+
+func (r *Export) SetFailed(_ context.Context, uuid string, failedat, expiresat int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	export, ok := r.byuuid[uuid]
+	if !ok {
+		return repositories.NewErrExportNotFoundByUUID(uuid)
+	}
+	export.Status = entities.ExportFailed
+	export.UpdatedAt = failedat
+	export.ExpiresAt = expiresat
+	return nil
+}
+
+func (r *Export) DeleteExpired(_ context.Context, now int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int
+	for uuid, export := range r.byuuid {
+		if export.ExpiresAt != 0 && export.ExpiresAt <= now {
+			delete(r.byuuid, uuid)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (r *Export) DeleteByAccount(_ context.Context, accountuuid string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for uuid, export := range r.byuuid {
+		if export.AccountUUID == accountuuid {
+			delete(r.byuuid, uuid)
+		}
+	}
+	return nil
+}
+
+// clone must be called under the lock
+func (r *Export) clone() *Export {
+	c := &Export{
+		byuuid: make(map[string]*repositories.AccountExport, len(r.byuuid)),
+	}
+	for uuid, export := range r.byuuid {
+		copied := *export
+		c.byuuid[uuid] = &copied
+	}
+	return c
+}
+
+// entity2repo and repo2entity copy the export, so callers can't change the stored one
+func (r *Export) entity2repo(entity *entities.AccountExport) *repositories.AccountExport {
+	return &repositories.AccountExport{
+		UUID:        entity.UUID,
+		AccountUUID: entity.AccountUUID,
+		Status:      entity.Status,
+		Archive:     append([]byte(nil), entity.Archive...),
+		CreatedAt:   entity.CreatedAt,
+		UpdatedAt:   entity.UpdatedAt,
+		ReadyAt:     entity.ReadyAt,
+		ExpiresAt:   entity.ExpiresAt,
+	}
+}
+
+func (r *Export) repo2entity(repo *repositories.AccountExport) *entities.AccountExport {
+	return &entities.AccountExport{
+		UUID:        repo.UUID,
+		AccountUUID: repo.AccountUUID,
+		Status:      repo.Status,
+		Archive:     append([]byte(nil), repo.Archive...),
+		CreatedAt:   repo.CreatedAt,
+		UpdatedAt:   repo.UpdatedAt,
+		ReadyAt:     repo.ReadyAt,
+		ExpiresAt:   repo.ExpiresAt,
+	}
+}
@@ -0,0 +1,13 @@
+package dbmemory
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/repositories/repotest"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	repotest.ExportRepo(t, func(t *testing.T) (usecases.IExportRepo, usecases.IAccountRepo) {
+		return NewExport(), NewAccount()
+	})
+}
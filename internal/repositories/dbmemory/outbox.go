@@ -0,0 +1,199 @@
+package dbmemory
+
+import (
+	"cmp"
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"slices"
+	"sync"
+)
+
+type Outbox struct {
+	mu      sync.RWMutex
+	lastid  int64
+	lastseq int64
+	// events are kept in the order of addition, so the IDs are ascending
+	events []*repositories.DomainEvent
+}
+
+func NewOutbox() *Outbox {
+	return &Outbox{}
+}
+
+func (r *Outbox) Add(_ context.Context, event *entities.DomainEvent) (*entities.DomainEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastid++
+	repoevent := r.entity2repo(event)
+	repoevent.ID = r.lastid
+	repoevent.PublishedAt = 0
+	repoevent.Seq = 0
+	repoevent.Attempts = 0
+	repoevent.LastError = ""
+	r.events = append(r.events, repoevent)
+
+	return r.repo2entity(repoevent), nil
+}
+
+func (r *Outbox) GetUnpublished(_ context.Context, limit int) ([]*entities.DomainEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var events []*entities.DomainEvent
+	for i := 0; i < len(r.events) && len(events) < limit; i++ {
+		if r.events[i].PublishedAt == 0 {
+			events = append(events, r.repo2entity(r.events[i]))
+		}
+	}
+	return events, nil
+}
+
+func (r *Outbox) GetPublishedAfter(_ context.Context, afterseq int64, limit int) ([]*entities.DomainEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	published := r.published()
+	i, _ := slices.BinarySearchFunc(published, afterseq+1, func(event *repositories.DomainEvent, seq int64) int {
+		return cmp.Compare(event.Seq, seq)
+	})
+
+	var events []*entities.DomainEvent
+	for ; i < len(published) && len(events) < limit; i++ {
+		events = append(events, r.repo2entity(published[i]))
+	}
+	return events, nil
+}
+
+func (r *Outbox) GetSeqRange(_ context.Context) (int64, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	published := r.published()
+	if len(published) == 0 {
+		return 0, 0, nil
+	}
+	return published[0].Seq, published[len(published)-1].Seq, nil
+}
+
+func (r *Outbox) MarkPublished(_ context.Context, id int64, publishedat int64) error {
+	// update holds the lock, so the seq are taken in order
+	return r.update(id, func(event *repositories.DomainEvent) {
+		if event.Seq == 0 {
+			r.lastseq++
+			event.Seq = r.lastseq
+		}
+		event.PublishedAt = publishedat
+		event.Attempts++
+		event.LastError = ""
+	})
+}
+
+func (r *Outbox) MarkFailed(_ context.Context, id int64, lasterror string) error {
+	return r.update(id, func(event *repositories.DomainEvent) {
+		event.Attempts++
+		event.LastError = lasterror
+	})
+}
+
+func (r *Outbox) DeletePublishedBefore(_ context.Context, before int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := len(r.events)
+	r.events = slices.DeleteFunc(r.events, func(event *repositories.DomainEvent) bool {
+		return event.PublishedAt != 0 && event.PublishedAt <= before
+	})
+	return n - len(r.events), nil
+}
+
+func (r *Outbox) GetByAccount(_ context.Context, accountuuid, eventtype string) ([]*entities.DomainEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var events []*entities.DomainEvent
+	for _, event := range r.events {
+		if event.AccountUUID == accountuuid && event.Type == eventtype {
+			events = append(events, r.repo2entity(event))
+		}
+	}
+	return events, nil
+}
+
+func (r *Outbox) SetPayload(_ context.Context, id int64, payload string) error {
+	return r.update(id, func(event *repositories.DomainEvent) {
+		event.Payload = payload
+	})
+}
+
+// update replaces the event with its changed copy, so a clone taken by a transaction isn't affected
+func (r *Outbox) update(id int64, change func(event *repositories.DomainEvent)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := slices.IndexFunc(r.events, func(event *repositories.DomainEvent) bool {
+		return event.ID == id
+	})
+	if i < 0 {
+		return repositories.NewErrDomainEventNotFoundByID(id)
+	}
+
+	updated := *r.events[i]
+	change(&updated)
+	r.events[i] = &updated
+	return nil
+}
+
+// published returns the events with the seq in the seq order, it must be called under the lock
+func (r *Outbox) published() []*repositories.DomainEvent {
+	var published []*repositories.DomainEvent
+	for _, event := range r.events {
+		if event.Seq != 0 {
+			published = append(published, event)
+		}
+	}
+	slices.SortFunc(published, func(a, b *repositories.DomainEvent) int {
+		return cmp.Compare(a.Seq, b.Seq)
+	})
+	return published
+}
+
+// clone must be called under the lock
+func (r *Outbox) clone() *Outbox {
+	return &Outbox{
+		lastid:  r.lastid,
+		lastseq: r.lastseq,
+		events:  slices.Clone(r.events),
+	}
+}
+
+func (r *Outbox) entity2repo(entity *entities.DomainEvent) *repositories.DomainEvent {
+	return &repositories.DomainEvent{
+		ID:          entity.ID,
+		UUID:        entity.UUID,
+		Type:        entity.Type,
+		AccountUUID: entity.AccountUUID,
+		Payload:     entity.Payload,
+		CreatedAt:   entity.CreatedAt,
+		PublishedAt: entity.PublishedAt,
+		Seq:         entity.Seq,
+		Attempts:    entity.Attempts,
+		LastError:   entity.LastError,
+	}
+}
+
+func (r *Outbox) repo2entity(repo *repositories.DomainEvent) *entities.DomainEvent {
+	return &entities.DomainEvent{
+		ID:          repo.ID,
+		UUID:        repo.UUID,
+		Type:        repo.Type,
+		AccountUUID: repo.AccountUUID,
+		Payload:     repo.Payload,
+		CreatedAt:   repo.CreatedAt,
+		PublishedAt: repo.PublishedAt,
+		Seq:         repo.Seq,
+		Attempts:    repo.Attempts,
+		LastError:   repo.LastError,
+	}
+}
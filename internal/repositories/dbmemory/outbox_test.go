@@ -0,0 +1,13 @@
+package dbmemory
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/repositories/repotest"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"testing"
+)
+
+func TestOutbox(t *testing.T) {
+	repotest.OutboxRepo(t, func(t *testing.T) usecases.IOutboxRepo {
+		return NewOutbox()
+	})
+}
@@ -0,0 +1,106 @@
+package dbmemory
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"slices"
+	"sync"
+)
+
+type SecurityEvent struct {
+	mu     sync.RWMutex
+	lastid int64
+	// events are kept in the order of addition, so the IDs are ascending
+	events []*repositories.SecurityEvent
+}
+
+func NewSecurityEvent() *SecurityEvent {
+	return &SecurityEvent{}
+}
+
+func (r *SecurityEvent) Add(_ context.Context, event *entities.SecurityEvent) (*entities.SecurityEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastid++
+	repoevent := r.entity2repo(event)
+	repoevent.ID = r.lastid
+	r.events = append(r.events, repoevent)
+
+	return r.repo2entity(repoevent), nil
+}
+
+func (r *SecurityEvent) Find(_ context.Context, filter *repositories.SecurityEventFilter) ([]*entities.SecurityEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var events []*entities.SecurityEvent
+	for i := len(r.events) - 1; i >= 0 && len(events) < filter.Limit; i-- {
+		if r.match(r.events[i], filter) {
+			events = append(events, r.repo2entity(r.events[i]))
+		}
+	}
+	return events, nil
+}
+
+func (r *SecurityEvent) DeleteByAccount(_ context.Context, accountuuid string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = slices.DeleteFunc(r.events, func(event *repositories.SecurityEvent) bool {
+		return event.AccountUUID == accountuuid
+	})
+	return nil
+}
+
+func (r *SecurityEvent) match(event *repositories.SecurityEvent, filter *repositories.SecurityEventFilter) bool {
+	switch {
+	case filter.AccountUUID != "" && event.AccountUUID != filter.AccountUUID:
+		return false
+	case len(filter.Types) > 0 && !slices.Contains(filter.Types, event.Type):
+		return false
+	case filter.IP != "" && event.IP != filter.IP:
+		return false
+	case filter.Since > 0 && event.CreatedAt < filter.Since:
+		return false
+	case filter.Until > 0 && event.CreatedAt >= filter.Until:
+		return false
+	case filter.BeforeID > 0 && event.ID >= filter.BeforeID:
+		return false
+	default:
+		return true
+	}
+}
+
+// clone must be called under the lock
+func (r *SecurityEvent) clone() *SecurityEvent {
+	return &SecurityEvent{
+		lastid: r.lastid,
+		events: slices.Clone(r.events),
+	}
+}
+
+func (r *SecurityEvent) entity2repo(entity *entities.SecurityEvent) *repositories.SecurityEvent {
+	return &repositories.SecurityEvent{
+		ID:          entity.ID,
+		AccountUUID: entity.AccountUUID,
+		Type:        entity.Type,
+		IP:          entity.IP,
+		UserAgent:   entity.UserAgent,
+		Details:     entity.Details,
+		CreatedAt:   entity.CreatedAt,
+	}
+}
+
+func (r *SecurityEvent) repo2entity(repo *repositories.SecurityEvent) *entities.SecurityEvent {
+	return &entities.SecurityEvent{
+		ID:          repo.ID,
+		AccountUUID: repo.AccountUUID,
+		Type:        repo.Type,
+		IP:          repo.IP,
+		UserAgent:   repo.UserAgent,
+		Details:     repo.Details,
+		CreatedAt:   repo.CreatedAt,
+	}
+}
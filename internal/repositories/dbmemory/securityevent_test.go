@@ -0,0 +1,13 @@
+package dbmemory
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/repositories/repotest"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"testing"
+)
+
+func TestSecurityEvent(t *testing.T) {
+	repotest.SecurityEventRepo(t, func(t *testing.T) (usecases.ISecurityEventRepo, usecases.IAccountRepo) {
+		return NewSecurityEvent(), NewAccount()
+	})
+}
@@ -0,0 +1,173 @@
+package dbmemory
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"sort"
+	"sync"
+)
+
+type Session struct {
+	mu     sync.RWMutex
+	byuuid map[string]*repositories.Session
+}
+
+func NewSession() *Session {
+	return &Session{
+		byuuid: make(map[string]*repositories.Session),
+	}
+}
+
+func (r *Session) Create(_ context.Context, session *entities.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byuuid[session.UUID] = r.entity2repo(session)
+	return nil
+}
+
+func (r *Session) GetOneByUUID(_ context.Context, uuid string) (*entities.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, ok := r.byuuid[uuid]
+	if !ok {
+		return nil, repositories.NewErrSessionNotFoundByUUID(uuid)
+	}
+	return r.repo2entity(session), nil
+}
+
+func (r *Session) GetActiveByAccount(_ context.Context, accountuuid string, now int64) ([]*entities.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.filter(func(session *repositories.Session) bool {
+		return session.AccountUUID == accountuuid && session.RevokedAt == 0 && session.ExpiresAt > now
+	}), nil
+}
+
+func (r *Session) GetByAccount(_ context.Context, accountuuid string) ([]*entities.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.filter(func(session *repositories.Session) bool {
+		return session.AccountUUID == accountuuid
+	}), nil
+}
+
+func (r *Session) Refresh(_ context.Context, session *entities.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.byuuid[session.UUID]
+	if !ok || stored.RevokedAt != 0 {
+		return repositories.NewErrSessionNotFoundByUUID(session.UUID)
+	}
+	stored.IP = session.IP
+	stored.UserAgent = session.UserAgent
+	stored.DeviceName = session.DeviceName
+	stored.RefreshedAt = session.RefreshedAt
+	stored.ExpiresAt = session.ExpiresAt
+	return nil
+}
+
+func (r *Session) Revoke(_ context.Context, uuid string, revokedat int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.byuuid[uuid]
+	if !ok || stored.RevokedAt != 0 {
+		return repositories.NewErrSessionNotFoundByUUID(uuid)
+	}
+	stored.RevokedAt = revokedat
+	return nil
+}
+
+func (r *Session) RevokeAllByAccount(_ context.Context, accountuuid string, revokedat int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, session := range r.byuuid {
+		if session.AccountUUID == accountuuid && session.RevokedAt == 0 {
+			session.RevokedAt = revokedat
+		}
+	}
+	return nil
+}
+
+func (r *Session) DeleteByAccount(_ context.Context, accountuuid string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for uuid, session := range r.byuuid {
+		if session.AccountUUID == accountuuid {
+			delete(r.byuuid, uuid)
+		}
+	}
+	return nil
+}
+
+// filter must be called under the lock, the sessions are sorted like in the SQL storages
+func (r *Session) filter(match func(session *repositories.Session) bool) []*entities.Session {
+	var found []*repositories.Session
+	for _, session := range r.byuuid {
+		if match(session) {
+			found = append(found, session)
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].RefreshedAt != found[j].RefreshedAt {
+			return found[i].RefreshedAt > found[j].RefreshedAt
+		}
+		if found[i].CreatedAt != found[j].CreatedAt {
+			return found[i].CreatedAt > found[j].CreatedAt
+		}
+		return found[i].UUID < found[j].UUID
+	})
+
+	var sessions []*entities.Session
+	for _, session := range found {
+		sessions = append(sessions, r.repo2entity(session))
+	}
+	return sessions
+}
+
+// clone must be called under the lock
+func (r *Session) clone() *Session {
+	c := NewSession()
+	for uuid, session := range r.byuuid {
+		copied := *session
+		c.byuuid[uuid] = &copied
+	}
+	return c
+}
+
+func (r *Session) entity2repo(entity *entities.Session) *repositories.Session {
+	return &repositories.Session{
+		UUID:        entity.UUID,
+		AccountUUID: entity.AccountUUID,
+		IP:          entity.IP,
+		UserAgent:   entity.UserAgent,
+		DeviceName:  entity.DeviceName,
+		CreatedAt:   entity.CreatedAt,
+		RefreshedAt: entity.RefreshedAt,
+		ExpiresAt:   entity.ExpiresAt,
+		RevokedAt:   entity.RevokedAt,
+	}
+}
+
+func (r *Session) repo2entity(repo *repositories.Session) *entities.Session {
+	return &entities.Session{
+		UUID:        repo.UUID,
+		AccountUUID: repo.AccountUUID,
+		IP:          repo.IP,
+		UserAgent:   repo.UserAgent,
+		DeviceName:  repo.DeviceName,
+		CreatedAt:   repo.CreatedAt,
+		RefreshedAt: repo.RefreshedAt,
+		ExpiresAt:   repo.ExpiresAt,
+		RevokedAt:   repo.RevokedAt,
+	}
+}
@@ -0,0 +1,13 @@
+package dbmemory
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/repositories/repotest"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"testing"
+)
+
+func TestSession(t *testing.T) {
+	repotest.SessionRepo(t, func(t *testing.T) (usecases.ISessionRepo, usecases.IAccountRepo) {
+		return NewSession(), NewAccount()
+	})
+}
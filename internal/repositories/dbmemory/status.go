@@ -0,0 +1,81 @@
+package dbmemory
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"sync"
+)
+
+type StatusHistory struct {
+	mu        sync.RWMutex
+	lastid    int64
+	byaccount map[string][]*repositories.StatusChange
+}
+
+func NewStatusHistory() *StatusHistory {
+	return &StatusHistory{
+		byaccount: make(map[string][]*repositories.StatusChange),
+	}
+}
+
+func (r *StatusHistory) Add(_ context.Context, change *entities.StatusChange) (*entities.StatusChange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastid++
+	repochange := r.entity2repo(change)
+	repochange.ID = r.lastid
+	r.byaccount[repochange.AccountUUID] = append(r.byaccount[repochange.AccountUUID], repochange)
+
+	return r.repo2entity(repochange), nil
+}
+
+func (r *StatusHistory) GetByAccount(_ context.Context, accountuuid string, limit int) ([]*entities.StatusChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored := r.byaccount[accountuuid]
+
+	var changes []*entities.StatusChange
+	for i := len(stored) - 1; i >= 0 && len(changes) < limit; i-- {
+		changes = append(changes, r.repo2entity(stored[i]))
+	}
+	return changes, nil
+}
+
+// clone must be called under the lock
+func (r *StatusHistory) clone() *StatusHistory {
+	c := NewStatusHistory()
+	c.lastid = r.lastid
+	for accountuuid, changes := range r.byaccount {
+		c.byaccount[accountuuid] = append([]*repositories.StatusChange(nil), changes...)
+	}
+	return c
+}
+
+func (r *StatusHistory) entity2repo(entity *entities.StatusChange) *repositories.StatusChange {
+	return &repositories.StatusChange{
+		ID:          entity.ID,
+		AccountUUID: entity.AccountUUID,
+		FromStatus:  entity.FromStatus,
+		ToStatus:    entity.ToStatus,
+		Reason:      entity.Reason,
+		Actor:       entity.Actor,
+		Until:       entity.Until,
+		CreatedAt:   entity.CreatedAt,
+	}
+}
+
+func (r *StatusHistory) repo2entity(repo *repositories.StatusChange) *entities.StatusChange {
+	return &entities.StatusChange{
+		ID:          repo.ID,
+		AccountUUID: repo.AccountUUID,
+		FromStatus:  repo.FromStatus,
+		ToStatus:    repo.ToStatus,
+		Reason:      repo.Reason,
+		Actor:       repo.Actor,
+		Until:       repo.Until,
+		CreatedAt:   repo.CreatedAt,
+	}
+}
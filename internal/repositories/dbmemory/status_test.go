@@ -0,0 +1,13 @@
+package dbmemory
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/repositories/repotest"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"testing"
+)
+
+func TestStatusHistory(t *testing.T) {
+	repotest.StatusHistoryRepo(t, func(t *testing.T) (usecases.IStatusHistoryRepo, usecases.IAccountRepo) {
+		return NewStatusHistory(), NewAccount()
+	})
+}
@@ -0,0 +1,100 @@
+package dbmemory
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+)
+
+// Transactor serializes transactions over the repositories.
+// fn works with a copy of the data which replaces the original one on success,
+// so an error rolls all the changes back. fn must use only the repos it's given,
+// the original repositories are locked until the transaction is finished
+type Transactor struct {
+	account       *Account
+	statushistory *StatusHistory
+	session       *Session
+	securityevent *SecurityEvent
+	auditlog      *AuditLog
+	export        *Export
+	outbox        *Outbox
+	webhook       *Webhook
+	delivery      *WebhookDelivery
+}
+
+func NewTransactor(account *Account, statushistory *StatusHistory, session *Session, securityevent *SecurityEvent, auditlog *AuditLog, export *Export, outbox *Outbox, webhook *Webhook, delivery *WebhookDelivery) *Transactor {
+	return &Transactor{
+		account:       account,
+		statushistory: statushistory,
+		session:       session,
+		securityevent: securityevent,
+		auditlog:      auditlog,
+		export:        export,
+		outbox:        outbox,
+		webhook:       webhook,
+		delivery:      delivery,
+	}
+}
+
+func (t *Transactor) WithTx(_ context.Context, fn func(repos *usecases.TxRepos) error) error {
+	// the locks are always taken in the same order, so transactions can't deadlock
+	t.account.mu.Lock()
+	defer t.account.mu.Unlock()
+	t.statushistory.mu.Lock()
+	defer t.statushistory.mu.Unlock()
+	t.session.mu.Lock()
+	defer t.session.mu.Unlock()
+	t.securityevent.mu.Lock()
+	defer t.securityevent.mu.Unlock()
+	t.auditlog.mu.Lock()
+	defer t.auditlog.mu.Unlock()
+	t.outbox.mu.Lock()
+	defer t.outbox.mu.Unlock()
+	t.webhook.mu.Lock()
+	defer t.webhook.mu.Unlock()
+	t.delivery.mu.Lock()
+	defer t.delivery.mu.Unlock()
+	t.export.mu.Lock()
+	defer t.export.mu.Unlock()
+
+	txaccount := t.account.clone()
+	txstatushistory := t.statushistory.clone()
+	txsession := t.session.clone()
+	txsecurityevent := t.securityevent.clone()
+	txauditlog := t.auditlog.clone()
+	txoutbox := t.outbox.clone()
+	txwebhook := t.webhook.clone()
+	txdelivery := t.delivery.clone()
+	txexport := t.export.clone()
+	err := fn(&usecases.TxRepos{
+		Account:         txaccount,
+		StatusHistory:   txstatushistory,
+		Session:         txsession,
+		SecurityEvent:   txsecurityevent,
+		AuditLog:        txauditlog,
+		Export:          txexport,
+		Outbox:          txoutbox,
+		Webhook:         txwebhook,
+		WebhookDelivery: txdelivery,
+	})
+	if err != nil {
+		return err
+	}
+
+	t.account.byuuid = txaccount.byuuid
+	t.account.byemail = txaccount.byemail
+	t.statushistory.lastid = txstatushistory.lastid
+	t.statushistory.byaccount = txstatushistory.byaccount
+	t.session.byuuid = txsession.byuuid
+	t.securityevent.lastid = txsecurityevent.lastid
+	t.securityevent.events = txsecurityevent.events
+	t.auditlog.lastid = txauditlog.lastid
+	t.auditlog.records = txauditlog.records
+	t.outbox.lastid = txoutbox.lastid
+	t.outbox.lastseq = txoutbox.lastseq
+	t.outbox.events = txoutbox.events
+	t.webhook.byuuid = txwebhook.byuuid
+	t.delivery.lastid = txdelivery.lastid
+	t.delivery.deliveries = txdelivery.deliveries
+	t.export.byuuid = txexport.byuuid
+	return nil
+}
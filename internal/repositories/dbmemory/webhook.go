@@ -0,0 +1,321 @@
+package dbmemory
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type Webhook struct {
+	mu     sync.RWMutex
+	byuuid map[string]*repositories.Webhook
+}
+
+func NewWebhook() *Webhook {
+	return &Webhook{
+		byuuid: make(map[string]*repositories.Webhook),
+	}
+}
+
+func (r *Webhook) Create(_ context.Context, webhook *entities.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byuuid[webhook.UUID] = r.entity2repo(webhook)
+	return nil
+}
+
+func (r *Webhook) GetOneByUUID(_ context.Context, uuid string) (*entities.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhook, ok := r.byuuid[uuid]
+	if !ok {
+		return nil, repositories.NewErrWebhookNotFoundByUUID(uuid)
+	}
+	return r.repo2entity(webhook), nil
+}
+
+func (r *Webhook) GetAll(_ context.Context) ([]*entities.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhooks := make([]*repositories.Webhook, 0, len(r.byuuid))
+	for _, webhook := range r.byuuid {
+		webhooks = append(webhooks, webhook)
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		if webhooks[i].CreatedAt != webhooks[j].CreatedAt {
+			return webhooks[i].CreatedAt < webhooks[j].CreatedAt
+		}
+		return webhooks[i].UUID < webhooks[j].UUID
+	})
+
+	var result []*entities.Webhook
+	for _, webhook := range webhooks {
+		result = append(result, r.repo2entity(webhook))
+	}
+	return result, nil
+}
+
+func (r *Webhook) Update(_ context.Context, webhook *entities.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.byuuid[webhook.UUID]
+	if !ok {
+		return repositories.NewErrWebhookNotFoundByUUID(webhook.UUID)
+	}
+
+	updated := r.entity2repo(webhook)
+	updated.CreatedAt = stored.CreatedAt
+	r.byuuid[webhook.UUID] = updated
+	return nil
+}
+
+func (r *Webhook) Delete(_ context.Context, uuid string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byuuid[uuid]; !ok {
+		return repositories.NewErrWebhookNotFoundByUUID(uuid)
+	}
+	delete(r.byuuid, uuid)
+	return nil
+}
+
+// clone must be called under the lock
+func (r *Webhook) clone() *Webhook {
+	c := NewWebhook()
+	for uuid, webhook := range r.byuuid {
+		copied := *webhook
+		c.byuuid[uuid] = &copied
+	}
+	return c
+}
+
+func (r *Webhook) entity2repo(entity *entities.Webhook) *repositories.Webhook {
+	return &repositories.Webhook{
+		UUID:       entity.UUID,
+		URL:        entity.URL,
+		Secret:     entity.Secret,
+		EventTypes: strings.Join(entity.EventTypes, ","),
+		Active:     entity.Active,
+		CreatedAt:  entity.CreatedAt,
+		UpdatedAt:  entity.UpdatedAt,
+	}
+}
+
+func (r *Webhook) repo2entity(repo *repositories.Webhook) *entities.Webhook {
+	var eventtypes []string
+	if repo.EventTypes != "" {
+		eventtypes = strings.Split(repo.EventTypes, ",")
+	}
+	return &entities.Webhook{
+		UUID:       repo.UUID,
+		URL:        repo.URL,
+		Secret:     repo.Secret,
+		EventTypes: eventtypes,
+		Active:     repo.Active,
+		CreatedAt:  repo.CreatedAt,
+		UpdatedAt:  repo.UpdatedAt,
+	}
+}
+
+type WebhookDelivery struct {
+	mu     sync.RWMutex
+	lastid int64
+	// deliveries are kept in the order of addition, so the IDs are ascending
+	deliveries []*repositories.WebhookDelivery
+}
+
+func NewWebhookDelivery() *WebhookDelivery {
+	return &WebhookDelivery{}
+}
+
+func (r *WebhookDelivery) Add(_ context.Context, delivery *entities.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// a republished event is delivered to the webhook once
+	for _, stored := range r.deliveries {
+		if stored.WebhookUUID == delivery.WebhookUUID && stored.EventUUID == delivery.EventUUID {
+			return nil
+		}
+	}
+
+	r.lastid++
+	repodelivery := r.entity2repo(delivery)
+	repodelivery.ID = r.lastid
+	r.deliveries = append(r.deliveries, repodelivery)
+	return nil
+}
+
+func (r *WebhookDelivery) GetOneByID(_ context.Context, id int64) (*entities.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	i := r.index(id)
+	if i < 0 {
+		return nil, repositories.NewErrWebhookDeliveryNotFoundByID(id)
+	}
+	return r.repo2entity(r.deliveries[i]), nil
+}
+
+func (r *WebhookDelivery) ClaimDue(_ context.Context, now, leaseuntil int64) (*entities.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due *repositories.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.Status != entities.WebhookDeliveryPending || delivery.NextAttemptAt > now {
+			continue
+		}
+		if due == nil || delivery.NextAttemptAt < due.NextAttemptAt {
+			due = delivery
+		}
+	}
+	if due == nil {
+		return nil, nil
+	}
+
+	due.NextAttemptAt = leaseuntil
+	due.UpdatedAt = now
+	return r.repo2entity(due), nil
+}
+
+func (r *WebhookDelivery) Update(_ context.Context, delivery *entities.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.index(delivery.ID)
+	if i < 0 {
+		return repositories.NewErrWebhookDeliveryNotFoundByID(delivery.ID)
+	}
+
+	stored := r.deliveries[i]
+	stored.Status = delivery.Status
+	stored.Attempts = delivery.Attempts
+	stored.NextAttemptAt = delivery.NextAttemptAt
+	stored.LastStatusCode = delivery.LastStatusCode
+	stored.LastError = delivery.LastError
+	stored.UpdatedAt = delivery.UpdatedAt
+	return nil
+}
+
+func (r *WebhookDelivery) Find(_ context.Context, filter *repositories.WebhookDeliveryFilter) ([]*entities.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var deliveries []*entities.WebhookDelivery
+	for i := len(r.deliveries) - 1; i >= 0 && len(deliveries) < filter.Limit; i-- {
+		if r.match(r.deliveries[i], filter) {
+			deliveries = append(deliveries, r.repo2entity(r.deliveries[i]))
+		}
+	}
+	return deliveries, nil
+}
+
+func (r *WebhookDelivery) DeleteByWebhook(_ context.Context, webhookuuid string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.deliveries = slices.DeleteFunc(r.deliveries, func(delivery *repositories.WebhookDelivery) bool {
+		return delivery.WebhookUUID == webhookuuid
+	})
+	return nil
+}
+
+func (r *WebhookDelivery) SetPayloadByEvent(_ context.Context, eventuuid, payload string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, delivery := range r.deliveries {
+		if delivery.EventUUID == eventuuid {
+			delivery.Payload = payload
+		}
+	}
+	return nil
+}
+
+func (r *WebhookDelivery) DeleteFinishedBefore(_ context.Context, before int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := len(r.deliveries)
+	r.deliveries = slices.DeleteFunc(r.deliveries, func(delivery *repositories.WebhookDelivery) bool {
+		return delivery.Status != entities.WebhookDeliveryPending && delivery.UpdatedAt <= before
+	})
+	return n - len(r.deliveries), nil
+}
+
+// clone must be called under the lock
+func (r *WebhookDelivery) clone() *WebhookDelivery {
+	c := &WebhookDelivery{
+		lastid:     r.lastid,
+		deliveries: make([]*repositories.WebhookDelivery, 0, len(r.deliveries)),
+	}
+	for _, delivery := range r.deliveries {
+		copied := *delivery
+		c.deliveries = append(c.deliveries, &copied)
+	}
+	return c
+}
+
+// index must be called under the lock
+func (r *WebhookDelivery) index(id int64) int {
+	return slices.IndexFunc(r.deliveries, func(delivery *repositories.WebhookDelivery) bool {
+		return delivery.ID == id
+	})
+}
+
+func (r *WebhookDelivery) match(delivery *repositories.WebhookDelivery, filter *repositories.WebhookDeliveryFilter) bool {
+	switch {
+	case filter.WebhookUUID != "" && delivery.WebhookUUID != filter.WebhookUUID:
+		return false
+	case len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, delivery.Status):
+		return false
+	case filter.BeforeID > 0 && delivery.ID >= filter.BeforeID:
+		return false
+	default:
+		return true
+	}
+}
+
+func (r *WebhookDelivery) entity2repo(entity *entities.WebhookDelivery) *repositories.WebhookDelivery {
+	return &repositories.WebhookDelivery{
+		ID:             entity.ID,
+		WebhookUUID:    entity.WebhookUUID,
+		EventUUID:      entity.EventUUID,
+		EventType:      entity.EventType,
+		Payload:        entity.Payload,
+		Status:         entity.Status,
+		Attempts:       entity.Attempts,
+		NextAttemptAt:  entity.NextAttemptAt,
+		LastStatusCode: entity.LastStatusCode,
+		LastError:      entity.LastError,
+		CreatedAt:      entity.CreatedAt,
+		UpdatedAt:      entity.UpdatedAt,
+	}
+}
+
+func (r *WebhookDelivery) repo2entity(repo *repositories.WebhookDelivery) *entities.WebhookDelivery {
+	return &entities.WebhookDelivery{
+		ID:             repo.ID,
+		WebhookUUID:    repo.WebhookUUID,
+		EventUUID:      repo.EventUUID,
+		EventType:      repo.EventType,
+		Payload:        repo.Payload,
+		Status:         repo.Status,
+		Attempts:       repo.Attempts,
+		NextAttemptAt:  repo.NextAttemptAt,
+		LastStatusCode: repo.LastStatusCode,
+		LastError:      repo.LastError,
+		CreatedAt:      repo.CreatedAt,
+		UpdatedAt:      repo.UpdatedAt,
+	}
+}
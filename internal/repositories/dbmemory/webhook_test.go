@@ -0,0 +1,13 @@
+package dbmemory
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/repositories/repotest"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"testing"
+)
+
+func TestWebhook(t *testing.T) {
+	repotest.WebhookRepos(t, func(t *testing.T) (usecases.IWebhookRepo, usecases.IWebhookDeliveryRepo) {
+		return NewWebhook(), NewWebhookDelivery()
+	})
+}
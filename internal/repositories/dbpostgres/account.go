@@ -6,10 +6,18 @@ import (
 	"errors"
 	"github.com/alexsibrin/runbot-auth/internal/entities"
 	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"github.com/lib/pq"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	accountColumns = `uuid, email, password, name, status, statusuntil, createdat, updatedat, deletedat, purgedat`
 )
 
 type Account struct {
-	db *sql.DB
+	db querier
 }
 
 func NewAccount(dbinst *PostgreSQL) (*Account, error) {
@@ -17,13 +25,13 @@ func NewAccount(dbinst *PostgreSQL) (*Account, error) {
 		return nil, ErrDbIsNil
 	}
 	return &Account{
-		db: dbinst.db,
+		db: traced(dbinst.db),
 	}, nil
 }
 
 func (r *Account) IsExist(ctx context.Context, account *entities.Account) (bool, error) {
 	query := `
-		SELECT EXISTS(SELECT 1 FROM accounts WHERE email = $1);
+		SELECT EXISTS(SELECT 1 FROM accounts WHERE lower(email) = lower($1));
 	`
 	var exists bool
 	err := r.db.QueryRowContext(ctx, query, account.Email).Scan(&exists)
@@ -46,29 +54,45 @@ func (r *Account) IsExistByUUID(ctx context.Context, uuid string) (bool, error)
 }
 
 func (r *Account) Create(ctx context.Context, account *entities.Account) (*entities.Account, error) {
-
 	repoaccount := r.entity2repo(account)
 
 	query := `
-		INSERT INTO accounts (UUID, Name, Email, Password, CreatedAt, UpdatedAt) 
-		VALUES ($1, $2, $3, $4, $5, $6);
+		INSERT INTO accounts (uuid, name, email, password, status, statusuntil, createdat, updatedat, deletedat, purgedat)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING ` + accountColumns + `;
 	`
 
-	_, err := r.db.QueryContext(ctx, query, &repoaccount.UUID, &repoaccount.Name, &repoaccount.Email, &repoaccount.Password, &repoaccount.CreatedAt, &repoaccount.UpdatedAt)
+	row := r.db.QueryRowContext(ctx, query,
+		repoaccount.UUID,
+		repoaccount.Name,
+		repoaccount.Email,
+		repoaccount.Password,
+		repoaccount.Status,
+		repoaccount.StatusUntil,
+		repoaccount.CreatedAt,
+		repoaccount.UpdatedAt,
+		repoaccount.DeletedAt,
+		repoaccount.PurgedAt,
+	)
+
+	created, err := r.scan(row)
+	if isUniqueViolation(err) {
+		return nil, repositories.NewErrAccountAlreadyExists(account.Email)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	return r.repo2entity(repoaccount), err
+	return r.repo2entity(created), nil
 }
 
 func (r *Account) GetOneByEmail(ctx context.Context, email string) (*entities.Account, error) {
 	query := `
-		SELECT DISTINCT UUID, Email, Password, Name, CreatedAt, UpdatedAt FROM accounts
-		WHERE Email=$1;
+		SELECT ` + accountColumns + ` FROM accounts
+		WHERE lower(email) = lower($1);
 	`
 
-	var account entities.Account
-
-	err := r.db.QueryRowContext(ctx, query, email).
-		Scan(&account.UUID, &account.Email, &account.Password, &account.Name, &account.CreatedAt, &account.UpdatedAt)
+	account, err := r.scan(r.db.QueryRowContext(ctx, query, email))
 
 	switch {
 	case errors.Is(err, sql.ErrNoRows):
@@ -76,20 +100,17 @@ func (r *Account) GetOneByEmail(ctx context.Context, email string) (*entities.Ac
 	case err != nil:
 		return nil, err
 	default:
-		return &account, nil
+		return r.repo2entity(account), nil
 	}
 }
 
 func (r *Account) GetOneByUUID(ctx context.Context, uuid string) (*entities.Account, error) {
 	query := `
-		SELECT DISTINCT UUID, Email, Password, Name, CreatedAt, UpdatedAt FROM accounts
-		WHERE UUID=$1;
+		SELECT ` + accountColumns + ` FROM accounts
+		WHERE uuid = $1;
 	`
 
-	var account entities.Account
-
-	err := r.db.QueryRowContext(ctx, query, uuid).
-		Scan(&account.UUID, &account.Email, &account.Password, &account.Name, &account.CreatedAt, &account.UpdatedAt)
+	account, err := r.scan(r.db.QueryRowContext(ctx, query, uuid))
 
 	switch {
 	case errors.Is(err, sql.ErrNoRows):
@@ -97,42 +118,222 @@ func (r *Account) GetOneByUUID(ctx context.Context, uuid string) (*entities.Acco
 	case err != nil:
 		return nil, err
 	default:
-		return &account, nil
+		return r.repo2entity(account), nil
+	}
+}
+
+func (r *Account) GetManyByUUIDs(ctx context.Context, uuids []string) ([]*entities.Account, error) {
+	query := `
+		SELECT ` + accountColumns + ` FROM accounts
+		WHERE uuid = ANY($1);
+	`
+
+	return r.query(ctx, query, pq.Array(uuids))
+}
+
+func (r *Account) Find(ctx context.Context, filter *repositories.AccountFilter) ([]*entities.Account, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	where := func(condition string, values ...any) {
+		for _, value := range values {
+			args = append(args, value)
+			condition = strings.Replace(condition, "?", "$"+strconv.Itoa(len(args)), 1)
+		}
+		conditions = append(conditions, condition)
+	}
+
+	if len(filter.Statuses) > 0 {
+		statuses := make([]int64, 0, len(filter.Statuses))
+		for _, status := range filter.Statuses {
+			statuses = append(statuses, int64(status))
+		}
+		where("status = ANY(?)", pq.Array(statuses))
+	}
+	if filter.EmailPrefix != "" {
+		where(`lower(email) COLLATE "C" LIKE ? ESCAPE '\'`, repositories.EscapeLike(strings.ToLower(filter.EmailPrefix))+"%")
+	}
+	if filter.NameContains != "" {
+		where(`name ILIKE ? ESCAPE '\'`, "%"+repositories.EscapeLike(filter.NameContains)+"%")
+	}
+	if filter.CreatedSince > 0 {
+		where("createdat >= ?", filter.CreatedSince)
+	}
+	if filter.CreatedUntil > 0 {
+		where("createdat < ?", filter.CreatedUntil)
 	}
+
+	// the keys match the indexes of the search, see the migrations
+	key, order, compare := "createdat", "ASC", ">"
+	if filter.Sort == repositories.AccountSortEmail {
+		key = `lower(email) COLLATE "C"`
+	}
+	if filter.Descending {
+		order, compare = "DESC", "<"
+	}
+	if filter.After != nil {
+		var after any = filter.After.CreatedAt
+		if filter.Sort == repositories.AccountSortEmail {
+			after = filter.After.Email
+		}
+		where("("+key+", uuid) "+compare+" (?, ?)", after, filter.After.UUID)
+	}
+
+	query := `SELECT ` + accountColumns + ` FROM accounts`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += ` ORDER BY ` + key + ` ` + order + `, uuid ` + order + ` LIMIT $` + strconv.Itoa(len(args)) + `;`
+
+	return r.query(ctx, query, args...)
 }
 
-func (r *Account) SetAccountStatus(ctx context.Context, uuid string, status uint8) error {
-	q := `UPDATE accounts SET status=$1 WHERE UUID=$2`
-	rows, err := r.db.QueryContext(ctx, q, status, uuid)
+func (r *Account) SetAccountStatus(ctx context.Context, uuid string, status uint8, until int64) error {
+	// restoring a deleted account cancels its purging, purged accounts can't be restored
+	q := `UPDATE accounts SET status = $1, statusuntil = $2, updatedat = $3, deletedat = 0 WHERE uuid = $4 AND purgedat = 0;`
+
+	result, err := r.db.ExecContext(ctx, q, status, until, time.Now().Unix(), uuid)
 	if err != nil {
 		return err
 	}
+	return r.checkAffected(result, uuid)
+}
+
+func (r *Account) SetPassword(ctx context.Context, uuid, password string, updatedat int64) error {
+	q := `UPDATE accounts SET password = $1, updatedat = $2 WHERE uuid = $3 AND purgedat = 0;`
 
-	if err = rows.Close(); err != nil {
+	result, err := r.db.ExecContext(ctx, q, password, updatedat, uuid)
+	if err != nil {
 		return err
 	}
+	return r.checkAffected(result, uuid)
+}
 
+func (r *Account) MarkAccountDeleted(ctx context.Context, uuid string, deletedat int64) error {
+	q := `UPDATE accounts SET status = $1, statusuntil = 0, deletedat = $2, updatedat = $3 WHERE uuid = $4 AND purgedat = 0;`
+
+	result, err := r.db.ExecContext(ctx, q, entities.Deleted, deletedat, deletedat, uuid)
+	if err != nil {
+		return err
+	}
+	return r.checkAffected(result, uuid)
+}
+
+func (r *Account) GetDeletedBefore(ctx context.Context, before int64, limit int) ([]*entities.Account, error) {
+	query := `
+		SELECT ` + accountColumns + ` FROM accounts
+		WHERE status = $1 AND purgedat = 0 AND deletedat <= $2
+		ORDER BY deletedat
+		LIMIT $3;
+	`
+
+	return r.query(ctx, query, entities.Deleted, before, limit)
+}
+
+func (r *Account) GetStatusExpiredBefore(ctx context.Context, before int64, limit int) ([]*entities.Account, error) {
+	query := `
+		SELECT ` + accountColumns + ` FROM accounts
+		WHERE statusuntil <> 0 AND statusuntil <= $1 AND purgedat = 0
+		ORDER BY statusuntil
+		LIMIT $2;
+	`
+
+	return r.query(ctx, query, before, limit)
+}
+
+func (r *Account) PurgeAccount(ctx context.Context, uuid string, purgedat int64) error {
+	q := `
+		UPDATE accounts SET email = $1, name = '', password = '', purgedat = $2, updatedat = $3
+		WHERE uuid = $4 AND status = $5 AND purgedat = 0;
+	`
+
+	result, err := r.db.ExecContext(ctx, q, repositories.AnonymizedEmail(uuid), purgedat, purgedat, uuid, entities.Deleted)
+	if err != nil {
+		return err
+	}
+	return r.checkAffected(result, uuid)
+}
+
+// checkAffected reports an account which isn't updated as not found
+func (r *Account) checkAffected(result sql.Result, uuid string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repositories.NewErrAccountNotFoundByUUID(uuid)
+	}
 	return nil
 }
 
+// query reads the rows selected with accountColumns
+func (r *Account) query(ctx context.Context, query string, args ...any) ([]*entities.Account, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []*entities.Account
+	for rows.Next() {
+		account, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, r.repo2entity(account))
+	}
+	return accounts, rows.Err()
+}
+
+// scan reads a row selected with accountColumns
+func (r *Account) scan(row scanner) (*repositories.Account, error) {
+	var account repositories.Account
+	err := row.Scan(
+		&account.UUID,
+		&account.Email,
+		&account.Password,
+		&account.Name,
+		&account.Status,
+		&account.StatusUntil,
+		&account.CreatedAt,
+		&account.UpdatedAt,
+		&account.DeletedAt,
+		&account.PurgedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
 func (r *Account) entity2repo(entity *entities.Account) *repositories.Account {
 	return &repositories.Account{
-		UUID:      entity.UUID,
-		Name:      entity.Name,
-		Email:     entity.Email,
-		Password:  entity.Password,
-		CreatedAt: entity.CreatedAt,
-		UpdatedAt: entity.UpdatedAt,
+		UUID:        entity.UUID,
+		Name:        entity.Name,
+		Email:       entity.Email,
+		Password:    entity.Password,
+		Status:      entity.Status,
+		StatusUntil: entity.StatusUntil,
+		CreatedAt:   entity.CreatedAt,
+		UpdatedAt:   entity.UpdatedAt,
+		DeletedAt:   entity.DeletedAt,
+		PurgedAt:    entity.PurgedAt,
 	}
 }
 
 func (r *Account) repo2entity(repo *repositories.Account) *entities.Account {
 	return &entities.Account{
-		UUID:      repo.UUID,
-		Name:      repo.Name,
-		Email:     repo.Email,
-		Password:  repo.Password,
-		CreatedAt: repo.CreatedAt,
-		UpdatedAt: repo.UpdatedAt,
+		UUID:        repo.UUID,
+		Name:        repo.Name,
+		Email:       repo.Email,
+		Password:    repo.Password,
+		Status:      repo.Status,
+		StatusUntil: repo.StatusUntil,
+		CreatedAt:   repo.CreatedAt,
+		UpdatedAt:   repo.UpdatedAt,
+		DeletedAt:   repo.DeletedAt,
+		PurgedAt:    repo.PurgedAt,
 	}
 }
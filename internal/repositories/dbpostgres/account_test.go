@@ -0,0 +1,84 @@
+package dbpostgres
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/repositories/repotest"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestAccount(t *testing.T) {
+	repotest.AccountRepo(t, func(t *testing.T) usecases.IAccountRepo {
+		repo, err := NewAccount(requireDB(t))
+		require.NoError(t, err)
+		return repo
+	})
+}
+
+func TestMigrator_Status(t *testing.T) {
+	db := requireDB(t)
+	ctx := context.Background()
+
+	migrator, err := NewMigrator(db)
+	require.NoError(t, err)
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, statuses)
+	for _, s := range statuses {
+		require.True(t, s.Applied, "migration %d_%s is not applied", s.Version, s.Name)
+	}
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	require.Empty(t, applied)
+}
+
+func TestTransactor(t *testing.T) {
+	repotest.Transactor(t, func(t *testing.T) (usecases.ITransactor, *usecases.TxRepos) {
+		db := requireDB(t)
+		account, err := NewAccount(db)
+		require.NoError(t, err)
+		statushistory, err := NewStatusHistory(db)
+		require.NoError(t, err)
+		session, err := NewSession(db)
+		require.NoError(t, err)
+		securityevent, err := NewSecurityEvent(db)
+		require.NoError(t, err)
+		auditlog, err := NewAuditLog(db)
+		require.NoError(t, err)
+		export, err := NewExport(db)
+		require.NoError(t, err)
+		outbox, err := NewOutbox(db)
+		require.NoError(t, err)
+		webhook, err := NewWebhook(db)
+		require.NoError(t, err)
+		delivery, err := NewWebhookDelivery(db)
+		require.NoError(t, err)
+		tx, err := NewTransactor(db)
+		require.NoError(t, err)
+		return tx, &usecases.TxRepos{
+			Account:         account,
+			StatusHistory:   statushistory,
+			Session:         session,
+			SecurityEvent:   securityevent,
+			AuditLog:        auditlog,
+			Export:          export,
+			Outbox:          outbox,
+			Webhook:         webhook,
+			WebhookDelivery: delivery,
+		}
+	})
+}
+
+func TestAccountImport(t *testing.T) {
+	repotest.AccountImportRepo(t, func(t *testing.T) (usecases.IAccountImportRepo, usecases.IAccountRepo) {
+		db := requireDB(t)
+		importrepo, err := NewAccountImport(db)
+		require.NoError(t, err)
+		accountrepo, err := NewAccount(db)
+		require.NoError(t, err)
+		return importrepo, accountrepo
+	})
+}
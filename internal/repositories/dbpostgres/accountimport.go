@@ -0,0 +1,134 @@
+package dbpostgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/lib/pq"
+)
+
+const (
+	accountImportTable = "accounts_import"
+)
+
+// AccountImport loads the accounts in bulk, e.g. migrated from another install
+type AccountImport struct {
+	db *sql.DB
+}
+
+func NewAccountImport(dbinst *PostgreSQL) (*AccountImport, error) {
+	if dbinst == nil || dbinst.db == nil {
+		return nil, ErrDbIsNil
+	}
+	return &AccountImport{
+		db: dbinst.db,
+	}, nil
+}
+
+// Import copies the accounts into a temporary table and moves them to the accounts in one statement,
+// so a batch costs a few round trips. The accounts whose UUID or email exists or repeats in the batch are skipped,
+// their UUIDs are returned
+func (r *AccountImport) Import(ctx context.Context, accounts []*entities.Account) ([]string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	skipped, err := r.importTx(ctx, tx, accounts)
+	if err != nil {
+		if rberr := tx.Rollback(); rberr != nil && !errors.Is(rberr, sql.ErrTxDone) {
+			return nil, errors.Join(err, rberr)
+		}
+		return nil, err
+	}
+	return skipped, tx.Commit()
+}
+
+func (r *AccountImport) importTx(ctx context.Context, tx *sql.Tx, accounts []*entities.Account) ([]string, error) {
+	// the inserted accounts are told apart by the UUIDs, so only the first account of a UUID is copied
+	// and the repeated ones are skipped
+	var skipped []string
+	unique := make([]*entities.Account, 0, len(accounts))
+	seen := make(map[string]struct{}, len(accounts))
+	for _, account := range accounts {
+		if _, ok := seen[account.UUID]; ok {
+			skipped = append(skipped, account.UUID)
+			continue
+		}
+		seen[account.UUID] = struct{}{}
+		unique = append(unique, account)
+	}
+
+	query := `CREATE TEMPORARY TABLE ` + accountImportTable + ` (LIKE accounts INCLUDING DEFAULTS) ON COMMIT DROP;`
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return nil, err
+	}
+
+	if err := r.copy(ctx, tx, unique); err != nil {
+		return nil, err
+	}
+
+	// no conflict target, so both the UUID and the email conflicts skip the row
+	query = `
+		INSERT INTO accounts (` + accountColumns + `)
+		SELECT ` + accountColumns + ` FROM ` + accountImportTable + `
+		ON CONFLICT DO NOTHING
+		RETURNING uuid;
+	`
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	inserted := make(map[string]struct{}, len(unique))
+	for rows.Next() {
+		var uuid string
+		if err = rows.Scan(&uuid); err != nil {
+			return nil, err
+		}
+		inserted[uuid] = struct{}{}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, account := range unique {
+		if _, ok := inserted[account.UUID]; !ok {
+			skipped = append(skipped, account.UUID)
+		}
+	}
+	return skipped, nil
+}
+
+func (r *AccountImport) copy(ctx context.Context, tx *sql.Tx, accounts []*entities.Account) error {
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(accountImportTable,
+		"uuid", "email", "password", "name", "status", "statusuntil", "createdat", "updatedat", "deletedat", "purgedat"))
+	if err != nil {
+		return err
+	}
+
+	for _, account := range accounts {
+		_, err = stmt.ExecContext(ctx,
+			account.UUID,
+			account.Email,
+			account.Password,
+			account.Name,
+			account.Status,
+			account.StatusUntil,
+			account.CreatedAt,
+			account.UpdatedAt,
+			account.DeletedAt,
+			account.PurgedAt,
+		)
+		if err != nil {
+			return errors.Join(err, stmt.Close())
+		}
+	}
+	// the exec without arguments flushes the buffered rows
+	if _, err = stmt.ExecContext(ctx); err != nil {
+		return errors.Join(err, stmt.Close())
+	}
+	return stmt.Close()
+}
@@ -1,10 +1,11 @@
 package dbpostgres
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"time"
 )
 
@@ -15,6 +16,12 @@ var (
 
 const (
 	postgresKey = "postgres"
+
+	// https://www.postgresql.org/docs/current/errcodes-appendix.html
+	pqUniqueViolation      = "23505"
+	pqForeignKeyViolation  = "23503"
+	pqSerializationFailure = "40001"
+	pqDeadlockDetected     = "40P01"
 )
 
 type Config struct {
@@ -30,6 +37,19 @@ type Config struct {
 	ConnectionMaxIdletime time.Duration
 }
 
+// querier is implemented by both *sql.DB and *sql.Tx,
+// so repositories can run the same queries inside and outside of a transaction
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
 type PostgreSQL struct {
 	db *sql.DB
 }
@@ -58,9 +78,37 @@ func New(c *Config) (*PostgreSQL, error) {
 	return &PostgreSQL{db}, nil
 }
 
+// Stats are the connection pool stats, e.g. for the metrics
+func (p *PostgreSQL) Stats() sql.DBStats {
+	return p.db.Stats()
+}
+
+// Ping checks the database answers
+func (p *PostgreSQL) Ping(ctx context.Context) error {
+	if p.db != nil {
+		return p.db.PingContext(ctx)
+	}
+	return ErrDbIsNil
+}
+
 func (p *PostgreSQL) Close() error {
 	if p.db != nil {
 		return p.db.Close()
 	}
 	return ErrDbIsNil
 }
+
+func isUniqueViolation(err error) bool {
+	var pqerr *pq.Error
+	return errors.As(err, &pqerr) && pqerr.Code == pqUniqueViolation
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pqerr *pq.Error
+	return errors.As(err, &pqerr) && pqerr.Code == pqForeignKeyViolation
+}
+
+func isRetryable(err error) bool {
+	var pqerr *pq.Error
+	return errors.As(err, &pqerr) && (pqerr.Code == pqSerializationFailure || pqerr.Code == pqDeadlockDetected)
+}
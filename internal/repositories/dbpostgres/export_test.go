@@ -0,0 +1,19 @@
+package dbpostgres
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/repositories/repotest"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	repotest.ExportRepo(t, func(t *testing.T) (usecases.IExportRepo, usecases.IAccountRepo) {
+		db := requireDB(t)
+		repo, err := NewExport(db)
+		require.NoError(t, err)
+		accounts, err := NewAccount(db)
+		require.NoError(t, err)
+		return repo, accounts
+	})
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS accounts_email_lower_uidx;
+DROP TABLE IF EXISTS accounts;
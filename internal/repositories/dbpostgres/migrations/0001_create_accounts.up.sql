@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS accounts (
+    uuid      text PRIMARY KEY,
+    name      text NOT NULL,
+    email     text NOT NULL,
+    password  text NOT NULL,
+    status    smallint NOT NULL DEFAULT 0,
+    createdat bigint NOT NULL,
+    updatedat bigint NOT NULL DEFAULT 0
+);
+
+-- Emails are compared case-insensitively, see validators.NormalizeEmail
+CREATE UNIQUE INDEX IF NOT EXISTS accounts_email_lower_uidx ON accounts (lower(email));
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS accounts_deleted_idx;
+
+ALTER TABLE accounts
+    DROP COLUMN IF EXISTS purgedat,
+    DROP COLUMN IF EXISTS deletedat;
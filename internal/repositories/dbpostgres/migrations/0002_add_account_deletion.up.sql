@@ -0,0 +1,5 @@
+ALTER TABLE accounts
+    ADD COLUMN deletedat bigint NOT NULL DEFAULT 0,
+    ADD COLUMN purgedat  bigint NOT NULL DEFAULT 0;
+
+CREATE INDEX accounts_deleted_idx ON accounts (deletedat) WHERE status = 3 AND purgedat = 0;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS account_exports (
+    uuid        text PRIMARY KEY,
+    accountuuid text NOT NULL REFERENCES accounts (uuid) ON DELETE CASCADE,
+    status      smallint NOT NULL DEFAULT 0,
+    archive     bytea,
+    createdat   bigint NOT NULL,
+    updatedat   bigint NOT NULL DEFAULT 0,
+    readyat     bigint NOT NULL DEFAULT 0,
+    expiresat   bigint NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS account_exports_accountuuid_idx ON account_exports (accountuuid);
+CREATE INDEX IF NOT EXISTS account_exports_status_idx ON account_exports (status, createdat);
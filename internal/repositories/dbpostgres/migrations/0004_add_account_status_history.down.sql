@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS account_status_history;
+
+DROP INDEX IF EXISTS accounts_statusuntil_idx;
+
+ALTER TABLE accounts
+    DROP COLUMN IF EXISTS statusuntil;
@@ -0,0 +1,17 @@
+ALTER TABLE accounts
+    ADD COLUMN statusuntil bigint NOT NULL DEFAULT 0;
+
+CREATE INDEX accounts_statusuntil_idx ON accounts (statusuntil) WHERE statusuntil <> 0;
+
+CREATE TABLE IF NOT EXISTS account_status_history (
+    id          bigserial PRIMARY KEY,
+    accountuuid text NOT NULL REFERENCES accounts (uuid) ON DELETE CASCADE,
+    fromstatus  smallint NOT NULL,
+    tostatus    smallint NOT NULL,
+    reason      text NOT NULL DEFAULT '',
+    actor       text NOT NULL DEFAULT '',
+    until       bigint NOT NULL DEFAULT 0,
+    createdat   bigint NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS account_status_history_accountuuid_idx ON account_status_history (accountuuid, id DESC);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS sessions (
+    uuid        text PRIMARY KEY,
+    accountuuid text NOT NULL REFERENCES accounts (uuid) ON DELETE CASCADE,
+    ip          text NOT NULL DEFAULT '',
+    useragent   text NOT NULL DEFAULT '',
+    devicename  text NOT NULL DEFAULT '',
+    createdat   bigint NOT NULL,
+    refreshedat bigint NOT NULL,
+    expiresat   bigint NOT NULL,
+    revokedat   bigint NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS sessions_accountuuid_idx ON sessions (accountuuid, refreshedat DESC);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS security_events (
+    id          bigserial PRIMARY KEY,
+    accountuuid text NOT NULL REFERENCES accounts (uuid) ON DELETE CASCADE,
+    type        smallint NOT NULL,
+    ip          text NOT NULL DEFAULT '',
+    useragent   text NOT NULL DEFAULT '',
+    details     text NOT NULL DEFAULT '',
+    createdat   bigint NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS security_events_accountuuid_idx ON security_events (accountuuid, id DESC);
+CREATE INDEX IF NOT EXISTS security_events_createdat_idx ON security_events (createdat);
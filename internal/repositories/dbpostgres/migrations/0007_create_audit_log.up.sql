@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+    id         bigserial PRIMARY KEY,
+    actor      text NOT NULL,
+    action     text NOT NULL,
+    targetuuid text NOT NULL DEFAULT '',
+    before     text NOT NULL DEFAULT '',
+    after      text NOT NULL DEFAULT '',
+    method     text NOT NULL DEFAULT '',
+    ip         text NOT NULL DEFAULT '',
+    useragent  text NOT NULL DEFAULT '',
+    createdat  bigint NOT NULL,
+    prevhash   text NOT NULL,
+    hash       text NOT NULL,
+    -- a record can follow only one record, so concurrent appends can't fork the chain
+    CONSTRAINT audit_log_prevhash_key UNIQUE (prevhash)
+);
+
+-- the log is append-only, the changes are rejected before they break the chain
+CREATE OR REPLACE FUNCTION audit_log_reject_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_reject_change();
@@ -0,0 +1,15 @@
+-- the events aren't bound to the accounts, an event outlives the purge of its account until it's published
+CREATE TABLE IF NOT EXISTS outbox (
+    id          bigserial PRIMARY KEY,
+    uuid        text NOT NULL UNIQUE,
+    type        text NOT NULL,
+    accountuuid text NOT NULL,
+    payload     text NOT NULL,
+    createdat   bigint NOT NULL,
+    publishedat bigint NOT NULL DEFAULT 0,
+    attempts    integer NOT NULL DEFAULT 0,
+    lasterror   text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS outbox_unpublished_idx ON outbox (id) WHERE publishedat = 0;
+CREATE INDEX IF NOT EXISTS outbox_publishedat_idx ON outbox (publishedat) WHERE publishedat <> 0;
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+    uuid       text PRIMARY KEY,
+    url        text NOT NULL,
+    secret     text NOT NULL,
+    eventtypes text NOT NULL DEFAULT '',
+    active     boolean NOT NULL DEFAULT true,
+    createdat  bigint NOT NULL,
+    updatedat  bigint NOT NULL
+);
+
+-- the deliveries are the delivery log of the webhook, they're deleted with it
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id             bigserial PRIMARY KEY,
+    webhookuuid    text NOT NULL REFERENCES webhooks (uuid) ON DELETE CASCADE,
+    eventuuid      text NOT NULL,
+    eventtype      text NOT NULL,
+    payload        text NOT NULL,
+    status         smallint NOT NULL DEFAULT 0,
+    attempts       integer NOT NULL DEFAULT 0,
+    nextattemptat  bigint NOT NULL DEFAULT 0,
+    laststatuscode integer NOT NULL DEFAULT 0,
+    lasterror      text NOT NULL DEFAULT '',
+    createdat      bigint NOT NULL,
+    updatedat      bigint NOT NULL,
+    UNIQUE (webhookuuid, eventuuid)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (nextattemptat, id) WHERE status = 0;
+CREATE INDEX IF NOT EXISTS webhook_deliveries_updatedat_idx ON webhook_deliveries (updatedat) WHERE status <> 0;
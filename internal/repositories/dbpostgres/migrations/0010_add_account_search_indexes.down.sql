@@ -0,0 +1,4 @@
+-- pg_trgm is kept, other database objects may use it
+DROP INDEX IF EXISTS accounts_name_trgm_idx;
+DROP INDEX IF EXISTS accounts_email_c_idx;
+DROP INDEX IF EXISTS accounts_createdat_idx;
@@ -0,0 +1,9 @@
+-- The admin search pages the accounts by (createdat, uuid) or (lower(email), uuid)
+CREATE INDEX IF NOT EXISTS accounts_createdat_idx ON accounts (createdat, uuid);
+
+-- The "C" collation orders the emails bytewise, so the index serves both the sorting and the prefix LIKE
+CREATE INDEX IF NOT EXISTS accounts_email_c_idx ON accounts ((lower(email) COLLATE "C"), uuid);
+
+-- The name substrings are matched with ILIKE through the trigrams
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS accounts_name_trgm_idx ON accounts USING gin (name gin_trgm_ops);
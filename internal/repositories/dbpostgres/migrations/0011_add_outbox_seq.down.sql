@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS outbox_seq_idx;
+DROP TABLE IF EXISTS outboxseq;
+ALTER TABLE outbox DROP COLUMN IF EXISTS seq;
@@ -0,0 +1,16 @@
+-- seq numbers the events in the order they're marked published. The IDs can't be the watch cursor:
+-- a transaction may commit its event after the events with the greater IDs
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS seq bigint NOT NULL DEFAULT 0;
+
+-- outboxseq holds the last assigned seq. Its row is locked until the assigning transaction commits,
+-- so the seq are committed in their order, and it keeps the seq from being reused after the deletion
+CREATE TABLE IF NOT EXISTS outboxseq (
+    id    smallint PRIMARY KEY CHECK (id = 1),
+    value bigint NOT NULL
+);
+
+-- the published events keep their IDs as the seq, so the cursors of the watchers stay valid
+UPDATE outbox SET seq = id WHERE publishedat <> 0 AND seq = 0;
+INSERT INTO outboxseq (id, value) SELECT 1, COALESCE(MAX(seq), 0) FROM outbox ON CONFLICT (id) DO NOTHING;
+
+CREATE UNIQUE INDEX IF NOT EXISTS outbox_seq_idx ON outbox (seq) WHERE seq <> 0;
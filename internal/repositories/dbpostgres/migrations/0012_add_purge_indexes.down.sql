@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS webhook_deliveries_eventuuid_idx;
+DROP INDEX IF EXISTS outbox_accountuuid_idx;
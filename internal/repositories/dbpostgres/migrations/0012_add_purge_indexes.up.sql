@@ -0,0 +1,3 @@
+-- The purge erases the email and the name in the created events of the account and in their webhook deliveries
+CREATE INDEX IF NOT EXISTS outbox_accountuuid_idx ON outbox (accountuuid, type);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_eventuuid_idx ON webhook_deliveries (eventuuid);
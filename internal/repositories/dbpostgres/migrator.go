@@ -0,0 +1,313 @@
+package dbpostgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationsLockKey is the key of the advisory lock taken while migrations are applied,
+// it's "runbot" in ASCII
+const migrationsLockKey int64 = 0x72756e626f74
+
+const (
+	migrationsDir     = "migrations"
+	migrationUpExt    = ".up.sql"
+	migrationDownExt  = ".down.sql"
+	migrationsTable   = "schema_migrations"
+	migrationsAllDown = -1
+)
+
+var (
+	ErrMigrationIsNotPaired  = errors.New("migration doesn't have up or down file")
+	ErrMigrationIsUnknown    = errors.New("applied migration is not found in the embedded files")
+	ErrMigrationIsDuplicated = errors.New("migration version is used by several names")
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+type Migration struct {
+	Version int64
+	Name    string
+	up      string
+	down    string
+}
+
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+func NewMigrator(dbinst *PostgreSQL) (*Migrator, error) {
+	if dbinst == nil || dbinst.db == nil {
+		return nil, ErrDbIsNil
+	}
+
+	migrations, err := loadMigrations(migrationsFS)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         dbinst.db,
+		migrations: migrations,
+	}, nil
+}
+
+// Up applies all pending migrations and returns them
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var result []Migration
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			q := fmt.Sprintf(`INSERT INTO %s (version, name) VALUES ($1, $2)`, migrationsTable)
+			err = m.exec(ctx, conn, migration.up, q, migration.Version, migration.Name)
+			if err != nil {
+				return fmt.Errorf("migration %d_%s up: %w", migration.Version, migration.Name, err)
+			}
+			result = append(result, migration)
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// Down rolls back the last steps migrations, all of them are rolled back if steps is negative
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var result []Migration
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			if steps != migrationsAllDown && len(result) >= steps {
+				break
+			}
+
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+
+			q := fmt.Sprintf(`DELETE FROM %s WHERE version = $1`, migrationsTable)
+			err = m.exec(ctx, conn, migration.down, q, migration.Version)
+			if err != nil {
+				return fmt.Errorf("migration %d_%s down: %w", migration.Version, migration.Name, err)
+			}
+			result = append(result, migration)
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// Status compares the embedded migrations with the applied ones. It only reads: no lock is taken
+// and the bookkeeping table isn't created, a database without the table has nothing applied
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var exists bool
+	// to_regclass resolves the name by the search_path like the other queries do
+	err := m.db.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, migrationsTable).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int64]time.Time)
+	if exists {
+		if applied, err = m.applied(ctx, m.db); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]MigrationStatus, 0, len(m.migrations))
+	known := make(map[int64]struct{}, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = struct{}{}
+		appliedat, ok := applied[migration.Version]
+		result = append(result, MigrationStatus{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Applied:   ok,
+			AppliedAt: appliedat,
+		})
+	}
+
+	for version := range applied {
+		if _, ok := known[version]; !ok {
+			return nil, fmt.Errorf("%w: version %d", ErrMigrationIsUnknown, version)
+		}
+	}
+	return result, nil
+}
+
+// withLock runs fn on a single connection holding the advisory lock,
+// so concurrent runners (e.g. several replicas at startup) wait for each other
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) (err error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err = conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationsLockKey); err != nil {
+		return err
+	}
+	defer func() {
+		// the context may be already cancelled here, but the lock must be released anyway
+		_, unlockerr := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationsLockKey)
+		if err == nil {
+			err = unlockerr
+		}
+	}()
+
+	q := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			version    bigint PRIMARY KEY,
+			name       text NOT NULL,
+			applied_at timestamptz NOT NULL DEFAULT now()
+		);
+	`, migrationsTable)
+	if _, err = conn.ExecContext(ctx, q); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) applied(ctx context.Context, db querier) (map[int64]time.Time, error) {
+	q := fmt.Sprintf(`SELECT version, applied_at FROM %s`, migrationsTable)
+	rows, err := db.QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedat time.Time
+		if err = rows.Scan(&version, &appliedat); err != nil {
+			return nil, err
+		}
+		result[version] = appliedat
+	}
+
+	return result, rows.Err()
+}
+
+// exec runs the migration script and the bookkeeping query in one transaction
+func (m *Migrator) exec(ctx context.Context, conn *sql.Conn, script, bookkeeping string, args ...any) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, script); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, bookkeeping, args...); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// loadMigrations reads files named as <version>_<name>.up.sql and <version>_<name>.down.sql,
+// a version must have a single name
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, migrationsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	byversion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		filename := entry.Name()
+
+		var base string
+		var isup bool
+		switch {
+		case strings.HasSuffix(filename, migrationUpExt):
+			base, isup = strings.TrimSuffix(filename, migrationUpExt), true
+		case strings.HasSuffix(filename, migrationDownExt):
+			base = strings.TrimSuffix(filename, migrationDownExt)
+		default:
+			continue
+		}
+
+		versionstr, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration file name %s is not correct", filename)
+		}
+		version, err := strconv.ParseInt(versionstr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration file name %s is not correct: %w", filename, err)
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(migrationsDir, filename))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byversion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byversion[version] = migration
+		}
+		// e.g. two branches have added the same version, one of them would be silently lost
+		if migration.Name != name {
+			return nil, fmt.Errorf("%w: version %d is %s and %s", ErrMigrationIsDuplicated, version, migration.Name, name)
+		}
+		if isup {
+			migration.up = string(content)
+		} else {
+			migration.down = string(content)
+		}
+	}
+
+	result := make([]Migration, 0, len(byversion))
+	for _, migration := range byversion {
+		if migration.up == "" || migration.down == "" {
+			return nil, fmt.Errorf("%w: version %d", ErrMigrationIsNotPaired, migration.Version)
+		}
+		result = append(result, *migration)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+
+	return result, nil
+}
@@ -0,0 +1,104 @@
+package dbpostgres
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	testCases := []struct {
+		name        string
+		in          fstest.MapFS
+		versions    []int64
+		expectedErr error
+	}{
+		{
+			name: "Migrations are sorted by version",
+			in: fstest.MapFS{
+				"migrations/0010_second.up.sql":   {Data: []byte("SELECT 2;")},
+				"migrations/0010_second.down.sql": {Data: []byte("SELECT 2;")},
+				"migrations/0002_first.up.sql":    {Data: []byte("SELECT 1;")},
+				"migrations/0002_first.down.sql":  {Data: []byte("SELECT 1;")},
+				"migrations/README.md":            {Data: []byte("skipped")},
+			},
+			versions: []int64{2, 10},
+		},
+		{
+			name: "Down file is missed",
+			in: fstest.MapFS{
+				"migrations/0001_first.up.sql": {Data: []byte("SELECT 1;")},
+			},
+			expectedErr: ErrMigrationIsNotPaired,
+		},
+		{
+			name: "Version has two names",
+			in: fstest.MapFS{
+				"migrations/0003_first.up.sql":    {Data: []byte("SELECT 1;")},
+				"migrations/0003_first.down.sql":  {Data: []byte("SELECT 1;")},
+				"migrations/0003_second.up.sql":   {Data: []byte("SELECT 2;")},
+				"migrations/0003_second.down.sql": {Data: []byte("SELECT 2;")},
+			},
+			expectedErr: ErrMigrationIsDuplicated,
+		},
+		{
+			name: "Version has an up and a down of different names",
+			in: fstest.MapFS{
+				"migrations/0003_first.up.sql":    {Data: []byte("SELECT 1;")},
+				"migrations/0003_second.down.sql": {Data: []byte("SELECT 2;")},
+			},
+			expectedErr: ErrMigrationIsDuplicated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			migrations, err := loadMigrations(tc.in)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			var versions []int64
+			for _, m := range migrations {
+				versions = append(versions, m.Version)
+			}
+			assert.Equal(t, tc.versions, versions)
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := loadMigrations(migrationsFS)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+}
+
+// TestMigrator_StatusWithoutTable checks a database never migrated has nothing applied
+// and the status doesn't create the bookkeeping table
+func TestMigrator_StatusWithoutTable(t *testing.T) {
+	db := requireDB(t)
+	ctx := context.Background()
+	migrator, err := NewMigrator(db)
+	require.NoError(t, err)
+
+	_, err = db.db.ExecContext(ctx, `ALTER TABLE schema_migrations RENAME TO schema_migrations_saved`)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := db.db.ExecContext(ctx, `ALTER TABLE schema_migrations_saved RENAME TO schema_migrations`)
+		require.NoError(t, err)
+	})
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, len(migrator.migrations))
+	for _, status := range statuses {
+		assert.False(t, status.Applied, status.Name)
+	}
+
+	var exists bool
+	require.NoError(t, db.db.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists))
+	assert.False(t, exists)
+}
@@ -0,0 +1,16 @@
+package dbpostgres
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/repositories/repotest"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestOutbox(t *testing.T) {
+	repotest.OutboxRepo(t, func(t *testing.T) usecases.IOutboxRepo {
+		repo, err := NewOutbox(requireDB(t))
+		require.NoError(t, err)
+		return repo
+	})
+}
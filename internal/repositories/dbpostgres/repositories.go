@@ -0,0 +1,82 @@
+package dbpostgres
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/repositories/dbsql"
+)
+
+var dialect = &dbsql.Dialect{
+	// SKIP LOCKED lets the concurrent workers claim different rows
+	ClaimLock: "FOR UPDATE SKIP LOCKED",
+	// the outboxseq row stays locked until the commit, so a concurrent relay takes the next seq after it
+	MarkOutboxPublished: `
+		WITH next AS (
+			INSERT INTO outboxseq (id, value)
+			SELECT 1, 1 WHERE EXISTS (SELECT 1 FROM outbox WHERE id = $2 AND seq = 0)
+			ON CONFLICT (id) DO UPDATE SET value = outboxseq.value + 1
+			RETURNING value
+		)
+		UPDATE outbox SET
+			publishedat = $1,
+			seq = CASE WHEN seq = 0 THEN COALESCE((SELECT value FROM next), 0) ELSE seq END,
+			attempts = attempts + 1,
+			lasterror = ''
+		WHERE id = $2;
+	`,
+	IsForeignKeyViolation: isForeignKeyViolation,
+}
+
+func NewStatusHistory(dbinst *PostgreSQL) (*dbsql.StatusHistory, error) {
+	if dbinst == nil || dbinst.db == nil {
+		return nil, ErrDbIsNil
+	}
+	return dbsql.NewStatusHistory(traced(dbinst.db), dialect), nil
+}
+
+func NewSession(dbinst *PostgreSQL) (*dbsql.Session, error) {
+	if dbinst == nil || dbinst.db == nil {
+		return nil, ErrDbIsNil
+	}
+	return dbsql.NewSession(traced(dbinst.db), dialect), nil
+}
+
+func NewSecurityEvent(dbinst *PostgreSQL) (*dbsql.SecurityEvent, error) {
+	if dbinst == nil || dbinst.db == nil {
+		return nil, ErrDbIsNil
+	}
+	return dbsql.NewSecurityEvent(traced(dbinst.db), dialect), nil
+}
+
+func NewAuditLog(dbinst *PostgreSQL) (*dbsql.AuditLog, error) {
+	if dbinst == nil || dbinst.db == nil {
+		return nil, ErrDbIsNil
+	}
+	return dbsql.NewAuditLog(traced(dbinst.db), dialect), nil
+}
+
+func NewOutbox(dbinst *PostgreSQL) (*dbsql.Outbox, error) {
+	if dbinst == nil || dbinst.db == nil {
+		return nil, ErrDbIsNil
+	}
+	return dbsql.NewOutbox(traced(dbinst.db), dialect), nil
+}
+
+func NewExport(dbinst *PostgreSQL) (*dbsql.Export, error) {
+	if dbinst == nil || dbinst.db == nil {
+		return nil, ErrDbIsNil
+	}
+	return dbsql.NewExport(traced(dbinst.db), dialect), nil
+}
+
+func NewWebhook(dbinst *PostgreSQL) (*dbsql.Webhook, error) {
+	if dbinst == nil || dbinst.db == nil {
+		return nil, ErrDbIsNil
+	}
+	return dbsql.NewWebhook(traced(dbinst.db), dialect), nil
+}
+
+func NewWebhookDelivery(dbinst *PostgreSQL) (*dbsql.WebhookDelivery, error) {
+	if dbinst == nil || dbinst.db == nil {
+		return nil, ErrDbIsNil
+	}
+	return dbsql.NewWebhookDelivery(traced(dbinst.db), dialect), nil
+}
@@ -0,0 +1,19 @@
+package dbpostgres
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/repositories/repotest"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSecurityEvent(t *testing.T) {
+	repotest.SecurityEventRepo(t, func(t *testing.T) (usecases.ISecurityEventRepo, usecases.IAccountRepo) {
+		db := requireDB(t)
+		repo, err := NewSecurityEvent(db)
+		require.NoError(t, err)
+		accounts, err := NewAccount(db)
+		require.NoError(t, err)
+		return repo, accounts
+	})
+}
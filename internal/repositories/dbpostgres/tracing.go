@@ -0,0 +1,77 @@
+package dbpostgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+)
+
+// tracer starts the spans of the queries, they aren't recorded until the tracer provider is set up
+var tracer = otel.Tracer("github.com/alexsibrin/runbot-auth/internal/repositories/dbpostgres")
+
+// tracedQuerier starts a span for each query, the statements have only the placeholders, so no values are exported
+type tracedQuerier struct {
+	q querier
+}
+
+func traced(q querier) querier {
+	return &tracedQuerier{q: q}
+}
+
+func (t *tracedQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, span := startQuery(ctx, query)
+	defer span.End()
+
+	result, err := t.q.ExecContext(ctx, query, args...)
+	recordError(span, err)
+	return result, err
+}
+
+func (t *tracedQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx, span := startQuery(ctx, query)
+	defer span.End()
+
+	rows, err := t.q.QueryContext(ctx, query, args...)
+	recordError(span, err)
+	return rows, err
+}
+
+func (t *tracedQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx, span := startQuery(ctx, query)
+	defer span.End()
+
+	row := t.q.QueryRowContext(ctx, query, args...)
+	recordError(span, row.Err())
+	return row
+}
+
+// startQuery names the span after the SQL operation, e.g. "postgres SELECT"
+func startQuery(ctx context.Context, query string) (context.Context, trace.Span) {
+	statement := strings.Join(strings.Fields(query), " ")
+	operation, _, _ := strings.Cut(statement, " ")
+	operation = strings.ToUpper(operation)
+
+	return tracing.Start(ctx, tracer, postgresKey+" "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperation(operation),
+			semconv.DBStatement(statement),
+		),
+	)
+}
+
+// recordError marks the span failed, the missing rows aren't a failure of the query
+func recordError(span trace.Span, err error) {
+	if err == nil || errors.Is(err, sql.ErrNoRows) {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,46 @@
+package dbpostgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"testing"
+)
+
+// querierStub fails the Exec calls, the queries aren't called by the test
+type querierStub struct {
+	querier
+	err error
+}
+
+func (q *querierStub) ExecContext(context.Context, string, ...any) (sql.Result, error) {
+	return nil, q.err
+}
+
+func TestTracedQuerier(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	errDown := errors.New("connection refused")
+	q := traced(&querierStub{err: errDown})
+
+	_, err := q.ExecContext(context.Background(), `
+		UPDATE accounts
+		SET status = $1
+		WHERE uuid = $2;
+	`, 1, "uuid")
+	assert.ErrorIs(t, err, errDown)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "postgres UPDATE", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), semconv.DBStatement("UPDATE accounts SET status = $1 WHERE uuid = $2;"))
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+}
@@ -0,0 +1,85 @@
+package dbpostgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/repositories/dbsql"
+	"github.com/alexsibrin/runbot-auth/internal/tracing"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+const (
+	txMaxAttempts = 3
+	txRetryDelay  = 20 * time.Millisecond
+)
+
+// Transactor runs repository calls in serializable transactions.
+// Serialization failures and deadlocks are retried, so concurrent check-then-write
+// sequences (e.g. IsExist and Create of a sign up) can't interleave
+type Transactor struct {
+	db *sql.DB
+}
+
+func NewTransactor(dbinst *PostgreSQL) (*Transactor, error) {
+	if dbinst == nil || dbinst.db == nil {
+		return nil, ErrDbIsNil
+	}
+	return &Transactor{
+		db: dbinst.db,
+	}, nil
+}
+
+func (t *Transactor) WithTx(ctx context.Context, fn func(repos *usecases.TxRepos) error) error {
+	var err error
+	for attempt := 1; attempt <= txMaxAttempts; attempt++ {
+		err = t.withTx(ctx, fn)
+		if err == nil || !isRetryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(txRetryDelay * time.Duration(attempt)):
+		}
+	}
+	return err
+}
+
+// withTx is traced as a whole, so the retried attempts are seen apart
+func (t *Transactor) withTx(ctx context.Context, fn func(repos *usecases.TxRepos) error) (err error) {
+	ctx, span := tracing.Start(ctx, tracer, postgresKey+" transaction", trace.WithSpanKind(trace.SpanKindClient))
+	defer func() {
+		recordError(span, err)
+		span.End()
+	}()
+
+	tx, err := t.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return err
+	}
+
+	repos := &usecases.TxRepos{
+		Account:         &Account{db: traced(tx)},
+		StatusHistory:   dbsql.NewStatusHistory(traced(tx), dialect),
+		Session:         dbsql.NewSession(traced(tx), dialect),
+		SecurityEvent:   dbsql.NewSecurityEvent(traced(tx), dialect),
+		AuditLog:        dbsql.NewAuditLog(traced(tx), dialect),
+		Export:          dbsql.NewExport(traced(tx), dialect),
+		Outbox:          dbsql.NewOutbox(traced(tx), dialect),
+		Webhook:         dbsql.NewWebhook(traced(tx), dialect),
+		WebhookDelivery: dbsql.NewWebhookDelivery(traced(tx), dialect),
+	}
+	if err = fn(repos); err != nil {
+		// the transaction may be already rolled back if the context is cancelled
+		if rberr := tx.Rollback(); rberr != nil && !errors.Is(rberr, sql.ErrTxDone) {
+			return errors.Join(err, rberr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,147 @@
+package dbsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+)
+
+const (
+	auditRecordColumns = `id, actor, action, targetuuid, before, after, method, ip, useragent, createdat, prevhash, hash`
+)
+
+type AuditLog struct {
+	db      Querier
+	dialect *Dialect
+}
+
+func NewAuditLog(db Querier, dialect *Dialect) *AuditLog {
+	return &AuditLog{
+		db:      dialect.bind(db),
+		dialect: dialect,
+	}
+}
+
+func (r *AuditLog) Add(ctx context.Context, record *entities.AuditRecord) (*entities.AuditRecord, error) {
+	reporecord := r.entity2repo(record)
+
+	query := `
+		INSERT INTO audit_log (actor, action, targetuuid, before, after, method, ip, useragent, createdat, prevhash, hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING ` + auditRecordColumns + `;
+	`
+
+	row := r.db.QueryRowContext(ctx, query,
+		reporecord.Actor,
+		reporecord.Action,
+		reporecord.TargetUUID,
+		reporecord.Before,
+		reporecord.After,
+		reporecord.Method,
+		reporecord.IP,
+		reporecord.UserAgent,
+		reporecord.CreatedAt,
+		reporecord.PrevHash,
+		reporecord.Hash,
+	)
+
+	added, err := r.scan(row)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.repo2entity(added), nil
+}
+
+func (r *AuditLog) Last(ctx context.Context) (*entities.AuditRecord, error) {
+	query := `SELECT ` + auditRecordColumns + ` FROM audit_log ORDER BY id DESC LIMIT 1;`
+
+	record, err := r.scan(r.db.QueryRowContext(ctx, query))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return r.repo2entity(record), nil
+}
+
+func (r *AuditLog) List(ctx context.Context, afterid int64, limit int) ([]*entities.AuditRecord, error) {
+	query := `SELECT ` + auditRecordColumns + ` FROM audit_log WHERE id > $1 ORDER BY id LIMIT $2;`
+
+	rows, err := r.db.QueryContext(ctx, query, afterid, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []*entities.AuditRecord
+	for rows.Next() {
+		record, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r.repo2entity(record))
+	}
+	return records, rows.Err()
+}
+
+// scan reads a row selected with auditRecordColumns
+func (r *AuditLog) scan(row scanner) (*repositories.AuditRecord, error) {
+	var record repositories.AuditRecord
+	err := row.Scan(
+		&record.ID,
+		&record.Actor,
+		&record.Action,
+		&record.TargetUUID,
+		&record.Before,
+		&record.After,
+		&record.Method,
+		&record.IP,
+		&record.UserAgent,
+		&record.CreatedAt,
+		&record.PrevHash,
+		&record.Hash,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r *AuditLog) entity2repo(entity *entities.AuditRecord) *repositories.AuditRecord {
+	return &repositories.AuditRecord{
+		ID:         entity.ID,
+		Actor:      entity.Actor,
+		Action:     entity.Action,
+		TargetUUID: entity.TargetUUID,
+		Before:     entity.Before,
+		After:      entity.After,
+		Method:     entity.Method,
+		IP:         entity.IP,
+		UserAgent:  entity.UserAgent,
+		CreatedAt:  entity.CreatedAt,
+		PrevHash:   entity.PrevHash,
+		Hash:       entity.Hash,
+	}
+}
+
+func (r *AuditLog) repo2entity(repo *repositories.AuditRecord) *entities.AuditRecord {
+	return &entities.AuditRecord{
+		ID:         repo.ID,
+		Actor:      repo.Actor,
+		Action:     repo.Action,
+		TargetUUID: repo.TargetUUID,
+		Before:     repo.Before,
+		After:      repo.After,
+		Method:     repo.Method,
+		IP:         repo.IP,
+		UserAgent:  repo.UserAgent,
+		CreatedAt:  repo.CreatedAt,
+		PrevHash:   repo.PrevHash,
+		Hash:       repo.Hash,
+	}
+}
@@ -0,0 +1,58 @@
+package dbsql
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Querier is implemented by both *sql.DB and *sql.Tx,
+// so repositories can run the same queries inside and outside of a transaction
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// Dialect is what the queries of the repositories differ in between the databases.
+// The queries are written with the numbered placeholders $1, $2..., an argument may be referenced several times
+type Dialect struct {
+	// Rebind replaces the numbered placeholders with the ones of the database, the queries are kept if it's nil
+	Rebind func(query string) string
+	// ClaimLock follows the SELECT of the row a worker claims, so the concurrent workers claim different rows
+	ClaimLock string
+	// MarkOutboxPublished sets publishedat ($1) of the outbox event ($2) and takes the next seq
+	// for it if it has none
+	MarkOutboxPublished string
+	// IsForeignKeyViolation tells the referenced row doesn't exist
+	IsForeignKeyViolation func(err error) bool
+}
+
+// bind rebinds the queries run on db
+func (d *Dialect) bind(db Querier) Querier {
+	if d.Rebind == nil {
+		return db
+	}
+	return &rebound{db: db, rebind: d.Rebind}
+}
+
+type rebound struct {
+	db     Querier
+	rebind func(query string) string
+}
+
+func (r *rebound) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return r.db.ExecContext(ctx, r.rebind(query), args...)
+}
+
+func (r *rebound) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return r.db.QueryContext(ctx, r.rebind(query), args...)
+}
+
+func (r *rebound) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return r.db.QueryRowContext(ctx, r.rebind(query), args...)
+}
@@ -0,0 +1,208 @@
+package dbsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+)
+
+const (
+	exportColumns = `uuid, accountuuid, status, archive, createdat, updatedat, readyat, expiresat`
+)
+
+type Export struct {
+	db      Querier
+	dialect *Dialect
+}
+
+func NewExport(db Querier, dialect *Dialect) *Export {
+	return &Export{
+		db:      dialect.bind(db),
+		dialect: dialect,
+	}
+}
+
+func (r *Export) Create(ctx context.Context, export *entities.AccountExport) error {
+	repoexport := r.entity2repo(export)
+
+	query := `
+		INSERT INTO account_exports (` + exportColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		repoexport.UUID,
+		repoexport.AccountUUID,
+		repoexport.Status,
+		repoexport.Archive,
+		repoexport.CreatedAt,
+		repoexport.UpdatedAt,
+		repoexport.ReadyAt,
+		repoexport.ExpiresAt,
+	)
+	return err
+}
+
+func (r *Export) GetOneByUUID(ctx context.Context, uuid string) (*entities.AccountExport, error) {
+	query := `SELECT ` + exportColumns + ` FROM account_exports WHERE uuid = $1;`
+
+	export, err := r.scan(r.db.QueryRowContext(ctx, query, uuid))
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, repositories.NewErrExportNotFoundByUUID(uuid)
+	case err != nil:
+		return nil, err
+	default:
+		return r.repo2entity(export), nil
+	}
+}
+
+func (r *Export) GetUnfinishedByAccount(ctx context.Context, accountuuid string) (*entities.AccountExport, error) {
+	query := `
+		SELECT ` + exportColumns + ` FROM account_exports
+		WHERE accountuuid = $1 AND status IN ($2, $3)
+		ORDER BY createdat DESC
+		LIMIT 1;
+	`
+
+	export, err := r.scan(r.db.QueryRowContext(ctx, query, accountuuid, entities.ExportPending, entities.ExportProcessing))
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	default:
+		return r.repo2entity(export), nil
+	}
+}
+
+func (r *Export) ClaimPending(ctx context.Context, now, stalebefore int64) (*entities.AccountExport, error) {
+	// the claim lock of the dialect keeps the concurrent workers from claiming the same export
+	query := `
+		UPDATE account_exports SET status = $1, updatedat = $2
+		WHERE uuid = (
+			SELECT uuid FROM account_exports
+			WHERE status = $3 OR (status = $1 AND updatedat <= $4)
+			ORDER BY createdat
+			LIMIT 1
+			` + r.dialect.ClaimLock + `
+		)
+		RETURNING ` + exportColumns + `;
+	`
+
+	export, err := r.scan(r.db.QueryRowContext(ctx, query, entities.ExportProcessing, now, entities.ExportPending, stalebefore))
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	default:
+		return r.repo2entity(export), nil
+	}
+}
+
+func (r *Export) SetReady(ctx context.Context, uuid string, archive []byte, readyat, expiresat int64) error {
+	q := `
+		UPDATE account_exports SET status = $1, archive = $2, readyat = $3, updatedat = $4, expiresat = $5
+		WHERE uuid = $6;
+	`
+
+	result, err := r.db.ExecContext(ctx, q, entities.ExportReady, archive, readyat, readyat, expiresat, uuid)
+	if err != nil {
+		return err
+	}
+	return r.checkAffected(result, uuid)
+}
+
+func (r *Export) SetFailed(ctx context.Context, uuid string, failedat, expiresat int64) error {
+	q := `UPDATE account_exports SET status = $1, updatedat = $2, expiresat = $3 WHERE uuid = $4;`
+
+	result, err := r.db.ExecContext(ctx, q, entities.ExportFailed, failedat, expiresat, uuid)
+	if err != nil {
+		return err
+	}
+	return r.checkAffected(result, uuid)
+}
+
+func (r *Export) DeleteExpired(ctx context.Context, now int64) (int, error) {
+	q := `DELETE FROM account_exports WHERE expiresat <> 0 AND expiresat <= $1;`
+
+	result, err := r.db.ExecContext(ctx, q, now)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+func (r *Export) DeleteByAccount(ctx context.Context, accountuuid string) error {
+	q := `DELETE FROM account_exports WHERE accountuuid = $1;`
+
+	_, err := r.db.ExecContext(ctx, q, accountuuid)
+	return err
+}
+
+func (r *Export) checkAffected(result sql.Result, uuid string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repositories.NewErrExportNotFoundByUUID(uuid)
+	}
+	return nil
+}
+
+// scan reads a row selected with exportColumns
+func (r *Export) scan(row scanner) (*repositories.AccountExport, error) {
+	var export repositories.AccountExport
+	err := row.Scan(
+		&export.UUID,
+		&export.AccountUUID,
+		&export.Status,
+		&export.Archive,
+		&export.CreatedAt,
+		&export.UpdatedAt,
+		&export.ReadyAt,
+		&export.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *Export) entity2repo(entity *entities.AccountExport) *repositories.AccountExport {
+	return &repositories.AccountExport{
+		UUID:        entity.UUID,
+		AccountUUID: entity.AccountUUID,
+		Status:      entity.Status,
+		Archive:     entity.Archive,
+		CreatedAt:   entity.CreatedAt,
+		UpdatedAt:   entity.UpdatedAt,
+		ReadyAt:     entity.ReadyAt,
+		ExpiresAt:   entity.ExpiresAt,
+	}
+}
+
+func (r *Export) repo2entity(repo *repositories.AccountExport) *entities.AccountExport {
+	return &entities.AccountExport{
+		UUID:        repo.UUID,
+		AccountUUID: repo.AccountUUID,
+		Status:      repo.Status,
+		Archive:     repo.Archive,
+		CreatedAt:   repo.CreatedAt,
+		UpdatedAt:   repo.UpdatedAt,
+		ReadyAt:     repo.ReadyAt,
+		ExpiresAt:   repo.ExpiresAt,
+	}
+}
@@ -0,0 +1,227 @@
+package dbsql
+
+import (
+	"context"
+	"database/sql"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+)
+
+const (
+	domainEventColumns = `id, uuid, type, accountuuid, payload, createdat, publishedat, seq, attempts, lasterror`
+)
+
+type Outbox struct {
+	db      Querier
+	dialect *Dialect
+}
+
+func NewOutbox(db Querier, dialect *Dialect) *Outbox {
+	return &Outbox{
+		db:      dialect.bind(db),
+		dialect: dialect,
+	}
+}
+
+func (r *Outbox) Add(ctx context.Context, event *entities.DomainEvent) (*entities.DomainEvent, error) {
+	repoevent := r.entity2repo(event)
+
+	query := `
+		INSERT INTO outbox (uuid, type, accountuuid, payload, createdat)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + domainEventColumns + `;
+	`
+
+	row := r.db.QueryRowContext(ctx, query,
+		repoevent.UUID,
+		repoevent.Type,
+		repoevent.AccountUUID,
+		repoevent.Payload,
+		repoevent.CreatedAt,
+	)
+
+	added, err := r.scan(row)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.repo2entity(added), nil
+}
+
+func (r *Outbox) GetUnpublished(ctx context.Context, limit int) ([]*entities.DomainEvent, error) {
+	query := `SELECT ` + domainEventColumns + ` FROM outbox WHERE publishedat = 0 ORDER BY id LIMIT $1;`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*entities.DomainEvent
+	for rows.Next() {
+		event, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, r.repo2entity(event))
+	}
+	return events, rows.Err()
+}
+
+func (r *Outbox) GetPublishedAfter(ctx context.Context, afterseq int64, limit int) ([]*entities.DomainEvent, error) {
+	query := `SELECT ` + domainEventColumns + ` FROM outbox WHERE seq > $1 ORDER BY seq LIMIT $2;`
+
+	rows, err := r.db.QueryContext(ctx, query, afterseq, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*entities.DomainEvent
+	for rows.Next() {
+		event, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, r.repo2entity(event))
+	}
+	return events, rows.Err()
+}
+
+func (r *Outbox) GetSeqRange(ctx context.Context) (int64, int64, error) {
+	query := `SELECT COALESCE(MIN(seq), 0), COALESCE(MAX(seq), 0) FROM outbox WHERE seq <> 0;`
+
+	var first, last int64
+	if err := r.db.QueryRowContext(ctx, query).Scan(&first, &last); err != nil {
+		return 0, 0, err
+	}
+	return first, last, nil
+}
+
+func (r *Outbox) MarkPublished(ctx context.Context, id int64, publishedat int64) error {
+	// the seq is taken only for an event without one, a republished event keeps its place
+	query := r.dialect.MarkOutboxPublished
+
+	result, err := r.db.ExecContext(ctx, query, publishedat, id)
+	if err != nil {
+		return err
+	}
+	return r.checkAffected(result, id)
+}
+
+func (r *Outbox) MarkFailed(ctx context.Context, id int64, lasterror string) error {
+	query := `UPDATE outbox SET attempts = attempts + 1, lasterror = $1 WHERE id = $2;`
+
+	result, err := r.db.ExecContext(ctx, query, lasterror, id)
+	if err != nil {
+		return err
+	}
+	return r.checkAffected(result, id)
+}
+
+func (r *Outbox) DeletePublishedBefore(ctx context.Context, before int64) (int, error) {
+	query := `DELETE FROM outbox WHERE publishedat <> 0 AND publishedat <= $1;`
+
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+func (r *Outbox) GetByAccount(ctx context.Context, accountuuid, eventtype string) ([]*entities.DomainEvent, error) {
+	query := `SELECT ` + domainEventColumns + ` FROM outbox WHERE accountuuid = $1 AND type = $2 ORDER BY id;`
+
+	rows, err := r.db.QueryContext(ctx, query, accountuuid, eventtype)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*entities.DomainEvent
+	for rows.Next() {
+		event, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, r.repo2entity(event))
+	}
+	return events, rows.Err()
+}
+
+func (r *Outbox) SetPayload(ctx context.Context, id int64, payload string) error {
+	query := `UPDATE outbox SET payload = $1 WHERE id = $2;`
+
+	result, err := r.db.ExecContext(ctx, query, payload, id)
+	if err != nil {
+		return err
+	}
+	return r.checkAffected(result, id)
+}
+
+func (r *Outbox) checkAffected(result sql.Result, id int64) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repositories.NewErrDomainEventNotFoundByID(id)
+	}
+	return nil
+}
+
+// scan reads a row selected with domainEventColumns
+func (r *Outbox) scan(row scanner) (*repositories.DomainEvent, error) {
+	var event repositories.DomainEvent
+	err := row.Scan(
+		&event.ID,
+		&event.UUID,
+		&event.Type,
+		&event.AccountUUID,
+		&event.Payload,
+		&event.CreatedAt,
+		&event.PublishedAt,
+		&event.Seq,
+		&event.Attempts,
+		&event.LastError,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (r *Outbox) entity2repo(entity *entities.DomainEvent) *repositories.DomainEvent {
+	return &repositories.DomainEvent{
+		ID:          entity.ID,
+		UUID:        entity.UUID,
+		Type:        entity.Type,
+		AccountUUID: entity.AccountUUID,
+		Payload:     entity.Payload,
+		CreatedAt:   entity.CreatedAt,
+		PublishedAt: entity.PublishedAt,
+		Seq:         entity.Seq,
+		Attempts:    entity.Attempts,
+		LastError:   entity.LastError,
+	}
+}
+
+func (r *Outbox) repo2entity(repo *repositories.DomainEvent) *entities.DomainEvent {
+	return &entities.DomainEvent{
+		ID:          repo.ID,
+		UUID:        repo.UUID,
+		Type:        repo.Type,
+		AccountUUID: repo.AccountUUID,
+		Payload:     repo.Payload,
+		CreatedAt:   repo.CreatedAt,
+		PublishedAt: repo.PublishedAt,
+		Seq:         repo.Seq,
+		Attempts:    repo.Attempts,
+		LastError:   repo.LastError,
+	}
+}
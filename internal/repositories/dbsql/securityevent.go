@@ -0,0 +1,161 @@
+package dbsql
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"strconv"
+	"strings"
+)
+
+const (
+	securityEventColumns = `id, accountuuid, type, ip, useragent, details, createdat`
+)
+
+type SecurityEvent struct {
+	db      Querier
+	dialect *Dialect
+}
+
+func NewSecurityEvent(db Querier, dialect *Dialect) *SecurityEvent {
+	return &SecurityEvent{
+		db:      dialect.bind(db),
+		dialect: dialect,
+	}
+}
+
+func (r *SecurityEvent) Add(ctx context.Context, event *entities.SecurityEvent) (*entities.SecurityEvent, error) {
+	repoevent := r.entity2repo(event)
+
+	query := `
+		INSERT INTO security_events (accountuuid, type, ip, useragent, details, createdat)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + securityEventColumns + `;
+	`
+
+	row := r.db.QueryRowContext(ctx, query,
+		repoevent.AccountUUID,
+		repoevent.Type,
+		repoevent.IP,
+		repoevent.UserAgent,
+		repoevent.Details,
+		repoevent.CreatedAt,
+	)
+
+	added, err := r.scan(row)
+	if r.dialect.IsForeignKeyViolation(err) {
+		return nil, repositories.NewErrAccountNotFoundByUUID(event.AccountUUID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return r.repo2entity(added), nil
+}
+
+func (r *SecurityEvent) Find(ctx context.Context, filter *repositories.SecurityEventFilter) ([]*entities.SecurityEvent, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	where := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, strings.ReplaceAll(condition, "?", "$"+strconv.Itoa(len(args))))
+	}
+
+	if filter.AccountUUID != "" {
+		where("accountuuid = ?", filter.AccountUUID)
+	}
+	if len(filter.Types) > 0 {
+		placeholders := make([]string, 0, len(filter.Types))
+		for _, t := range filter.Types {
+			args = append(args, t)
+			placeholders = append(placeholders, "$"+strconv.Itoa(len(args)))
+		}
+		conditions = append(conditions, "type IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if filter.IP != "" {
+		where("ip = ?", filter.IP)
+	}
+	if filter.Since > 0 {
+		where("createdat >= ?", filter.Since)
+	}
+	if filter.Until > 0 {
+		where("createdat < ?", filter.Until)
+	}
+	if filter.BeforeID > 0 {
+		where("id < ?", filter.BeforeID)
+	}
+
+	query := `SELECT ` + securityEventColumns + ` FROM security_events`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += ` ORDER BY id DESC LIMIT $` + strconv.Itoa(len(args)) + `;`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*entities.SecurityEvent
+	for rows.Next() {
+		event, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, r.repo2entity(event))
+	}
+	return events, rows.Err()
+}
+
+func (r *SecurityEvent) DeleteByAccount(ctx context.Context, accountuuid string) error {
+	query := `DELETE FROM security_events WHERE accountuuid = $1;`
+
+	_, err := r.db.ExecContext(ctx, query, accountuuid)
+	return err
+}
+
+// scan reads a row selected with securityEventColumns
+func (r *SecurityEvent) scan(row scanner) (*repositories.SecurityEvent, error) {
+	var event repositories.SecurityEvent
+	err := row.Scan(
+		&event.ID,
+		&event.AccountUUID,
+		&event.Type,
+		&event.IP,
+		&event.UserAgent,
+		&event.Details,
+		&event.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (r *SecurityEvent) entity2repo(entity *entities.SecurityEvent) *repositories.SecurityEvent {
+	return &repositories.SecurityEvent{
+		ID:          entity.ID,
+		AccountUUID: entity.AccountUUID,
+		Type:        entity.Type,
+		IP:          entity.IP,
+		UserAgent:   entity.UserAgent,
+		Details:     entity.Details,
+		CreatedAt:   entity.CreatedAt,
+	}
+}
+
+func (r *SecurityEvent) repo2entity(repo *repositories.SecurityEvent) *entities.SecurityEvent {
+	return &entities.SecurityEvent{
+		ID:          repo.ID,
+		AccountUUID: repo.AccountUUID,
+		Type:        repo.Type,
+		IP:          repo.IP,
+		UserAgent:   repo.UserAgent,
+		Details:     repo.Details,
+		CreatedAt:   repo.CreatedAt,
+	}
+}
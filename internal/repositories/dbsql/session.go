@@ -0,0 +1,206 @@
+package dbsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+)
+
+const (
+	sessionColumns = `uuid, accountuuid, ip, useragent, devicename, createdat, refreshedat, expiresat, revokedat`
+)
+
+type Session struct {
+	db      Querier
+	dialect *Dialect
+}
+
+func NewSession(db Querier, dialect *Dialect) *Session {
+	return &Session{
+		db:      dialect.bind(db),
+		dialect: dialect,
+	}
+}
+
+func (r *Session) Create(ctx context.Context, session *entities.Session) error {
+	reposession := r.entity2repo(session)
+
+	q := `
+		INSERT INTO sessions (` + sessionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+	`
+
+	_, err := r.db.ExecContext(ctx, q,
+		reposession.UUID,
+		reposession.AccountUUID,
+		reposession.IP,
+		reposession.UserAgent,
+		reposession.DeviceName,
+		reposession.CreatedAt,
+		reposession.RefreshedAt,
+		reposession.ExpiresAt,
+		reposession.RevokedAt,
+	)
+	if r.dialect.IsForeignKeyViolation(err) {
+		return repositories.NewErrAccountNotFoundByUUID(session.AccountUUID)
+	}
+	return err
+}
+
+func (r *Session) GetOneByUUID(ctx context.Context, uuid string) (*entities.Session, error) {
+	q := `SELECT ` + sessionColumns + ` FROM sessions WHERE uuid = $1;`
+
+	session, err := r.scan(r.db.QueryRowContext(ctx, q, uuid))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repositories.NewErrSessionNotFoundByUUID(uuid)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.repo2entity(session), nil
+}
+
+func (r *Session) GetActiveByAccount(ctx context.Context, accountuuid string, now int64) ([]*entities.Session, error) {
+	q := `
+		SELECT ` + sessionColumns + ` FROM sessions
+		WHERE accountuuid = $1 AND revokedat = 0 AND expiresat > $2
+		ORDER BY refreshedat DESC, createdat DESC, uuid;
+	`
+	return r.query(ctx, q, accountuuid, now)
+}
+
+func (r *Session) GetByAccount(ctx context.Context, accountuuid string) ([]*entities.Session, error) {
+	q := `
+		SELECT ` + sessionColumns + ` FROM sessions
+		WHERE accountuuid = $1
+		ORDER BY refreshedat DESC, createdat DESC, uuid;
+	`
+	return r.query(ctx, q, accountuuid)
+}
+
+func (r *Session) Refresh(ctx context.Context, session *entities.Session) error {
+	reposession := r.entity2repo(session)
+
+	q := `
+		UPDATE sessions SET ip = $1, useragent = $2, devicename = $3, refreshedat = $4, expiresat = $5
+		WHERE uuid = $6 AND revokedat = 0;
+	`
+
+	result, err := r.db.ExecContext(ctx, q,
+		reposession.IP,
+		reposession.UserAgent,
+		reposession.DeviceName,
+		reposession.RefreshedAt,
+		reposession.ExpiresAt,
+		reposession.UUID,
+	)
+	if err != nil {
+		return err
+	}
+	return r.checkAffected(result, session.UUID)
+}
+
+func (r *Session) Revoke(ctx context.Context, uuid string, revokedat int64) error {
+	q := `UPDATE sessions SET revokedat = $1 WHERE uuid = $2 AND revokedat = 0;`
+
+	result, err := r.db.ExecContext(ctx, q, revokedat, uuid)
+	if err != nil {
+		return err
+	}
+	return r.checkAffected(result, uuid)
+}
+
+func (r *Session) RevokeAllByAccount(ctx context.Context, accountuuid string, revokedat int64) error {
+	q := `UPDATE sessions SET revokedat = $1 WHERE accountuuid = $2 AND revokedat = 0;`
+
+	_, err := r.db.ExecContext(ctx, q, revokedat, accountuuid)
+	return err
+}
+
+func (r *Session) DeleteByAccount(ctx context.Context, accountuuid string) error {
+	q := `DELETE FROM sessions WHERE accountuuid = $1;`
+
+	_, err := r.db.ExecContext(ctx, q, accountuuid)
+	return err
+}
+
+// checkAffected reports a session which isn't updated as not found
+func (r *Session) checkAffected(result sql.Result, uuid string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repositories.NewErrSessionNotFoundByUUID(uuid)
+	}
+	return nil
+}
+
+// query reads the rows selected with sessionColumns
+func (r *Session) query(ctx context.Context, query string, args ...any) ([]*entities.Session, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*entities.Session
+	for rows.Next() {
+		session, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, r.repo2entity(session))
+	}
+	return sessions, rows.Err()
+}
+
+// scan reads a row selected with sessionColumns
+func (r *Session) scan(row scanner) (*repositories.Session, error) {
+	var session repositories.Session
+	err := row.Scan(
+		&session.UUID,
+		&session.AccountUUID,
+		&session.IP,
+		&session.UserAgent,
+		&session.DeviceName,
+		&session.CreatedAt,
+		&session.RefreshedAt,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *Session) entity2repo(entity *entities.Session) *repositories.Session {
+	return &repositories.Session{
+		UUID:        entity.UUID,
+		AccountUUID: entity.AccountUUID,
+		IP:          entity.IP,
+		UserAgent:   entity.UserAgent,
+		DeviceName:  entity.DeviceName,
+		CreatedAt:   entity.CreatedAt,
+		RefreshedAt: entity.RefreshedAt,
+		ExpiresAt:   entity.ExpiresAt,
+		RevokedAt:   entity.RevokedAt,
+	}
+}
+
+func (r *Session) repo2entity(repo *repositories.Session) *entities.Session {
+	return &entities.Session{
+		UUID:        repo.UUID,
+		AccountUUID: repo.AccountUUID,
+		IP:          repo.IP,
+		UserAgent:   repo.UserAgent,
+		DeviceName:  repo.DeviceName,
+		CreatedAt:   repo.CreatedAt,
+		RefreshedAt: repo.RefreshedAt,
+		ExpiresAt:   repo.ExpiresAt,
+		RevokedAt:   repo.RevokedAt,
+	}
+}
@@ -0,0 +1,123 @@
+package dbsql
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+)
+
+const (
+	statusChangeColumns = `id, accountuuid, fromstatus, tostatus, reason, actor, until, createdat`
+)
+
+type StatusHistory struct {
+	db      Querier
+	dialect *Dialect
+}
+
+func NewStatusHistory(db Querier, dialect *Dialect) *StatusHistory {
+	return &StatusHistory{
+		db:      dialect.bind(db),
+		dialect: dialect,
+	}
+}
+
+func (r *StatusHistory) Add(ctx context.Context, change *entities.StatusChange) (*entities.StatusChange, error) {
+	repochange := r.entity2repo(change)
+
+	query := `
+		INSERT INTO account_status_history (accountuuid, fromstatus, tostatus, reason, actor, until, createdat)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + statusChangeColumns + `;
+	`
+
+	row := r.db.QueryRowContext(ctx, query,
+		repochange.AccountUUID,
+		repochange.FromStatus,
+		repochange.ToStatus,
+		repochange.Reason,
+		repochange.Actor,
+		repochange.Until,
+		repochange.CreatedAt,
+	)
+
+	added, err := r.scan(row)
+	if r.dialect.IsForeignKeyViolation(err) {
+		return nil, repositories.NewErrAccountNotFoundByUUID(change.AccountUUID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return r.repo2entity(added), nil
+}
+
+func (r *StatusHistory) GetByAccount(ctx context.Context, accountuuid string, limit int) ([]*entities.StatusChange, error) {
+	query := `
+		SELECT ` + statusChangeColumns + ` FROM account_status_history
+		WHERE accountuuid = $1
+		ORDER BY id DESC
+		LIMIT $2;
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, accountuuid, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []*entities.StatusChange
+	for rows.Next() {
+		change, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, r.repo2entity(change))
+	}
+	return changes, rows.Err()
+}
+
+// scan reads a row selected with statusChangeColumns
+func (r *StatusHistory) scan(row scanner) (*repositories.StatusChange, error) {
+	var change repositories.StatusChange
+	err := row.Scan(
+		&change.ID,
+		&change.AccountUUID,
+		&change.FromStatus,
+		&change.ToStatus,
+		&change.Reason,
+		&change.Actor,
+		&change.Until,
+		&change.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+func (r *StatusHistory) entity2repo(entity *entities.StatusChange) *repositories.StatusChange {
+	return &repositories.StatusChange{
+		ID:          entity.ID,
+		AccountUUID: entity.AccountUUID,
+		FromStatus:  entity.FromStatus,
+		ToStatus:    entity.ToStatus,
+		Reason:      entity.Reason,
+		Actor:       entity.Actor,
+		Until:       entity.Until,
+		CreatedAt:   entity.CreatedAt,
+	}
+}
+
+func (r *StatusHistory) repo2entity(repo *repositories.StatusChange) *entities.StatusChange {
+	return &entities.StatusChange{
+		ID:          repo.ID,
+		AccountUUID: repo.AccountUUID,
+		FromStatus:  repo.FromStatus,
+		ToStatus:    repo.ToStatus,
+		Reason:      repo.Reason,
+		Actor:       repo.Actor,
+		Until:       repo.Until,
+		CreatedAt:   repo.CreatedAt,
+	}
+}
@@ -0,0 +1,418 @@
+package dbsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"strconv"
+	"strings"
+)
+
+const (
+	webhookColumns         = `uuid, url, secret, eventtypes, active, createdat, updatedat`
+	webhookDeliveryColumns = `id, webhookuuid, eventuuid, eventtype, payload, status, attempts, nextattemptat, laststatuscode, lasterror, createdat, updatedat`
+)
+
+type Webhook struct {
+	db      Querier
+	dialect *Dialect
+}
+
+func NewWebhook(db Querier, dialect *Dialect) *Webhook {
+	return &Webhook{
+		db:      dialect.bind(db),
+		dialect: dialect,
+	}
+}
+
+func (r *Webhook) Create(ctx context.Context, webhook *entities.Webhook) error {
+	repowebhook := r.entity2repo(webhook)
+
+	q := `
+		INSERT INTO webhooks (` + webhookColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7);
+	`
+
+	_, err := r.db.ExecContext(ctx, q,
+		repowebhook.UUID,
+		repowebhook.URL,
+		repowebhook.Secret,
+		repowebhook.EventTypes,
+		repowebhook.Active,
+		repowebhook.CreatedAt,
+		repowebhook.UpdatedAt,
+	)
+	return err
+}
+
+func (r *Webhook) GetOneByUUID(ctx context.Context, uuid string) (*entities.Webhook, error) {
+	q := `SELECT ` + webhookColumns + ` FROM webhooks WHERE uuid = $1;`
+
+	webhook, err := r.scan(r.db.QueryRowContext(ctx, q, uuid))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repositories.NewErrWebhookNotFoundByUUID(uuid)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.repo2entity(webhook), nil
+}
+
+func (r *Webhook) GetAll(ctx context.Context) ([]*entities.Webhook, error) {
+	q := `SELECT ` + webhookColumns + ` FROM webhooks ORDER BY createdat, uuid;`
+
+	rows, err := r.db.QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []*entities.Webhook
+	for rows.Next() {
+		webhook, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, r.repo2entity(webhook))
+	}
+	return webhooks, rows.Err()
+}
+
+func (r *Webhook) Update(ctx context.Context, webhook *entities.Webhook) error {
+	repowebhook := r.entity2repo(webhook)
+
+	q := `
+		UPDATE webhooks SET url = $1, secret = $2, eventtypes = $3, active = $4, updatedat = $5
+		WHERE uuid = $6;
+	`
+
+	result, err := r.db.ExecContext(ctx, q,
+		repowebhook.URL,
+		repowebhook.Secret,
+		repowebhook.EventTypes,
+		repowebhook.Active,
+		repowebhook.UpdatedAt,
+		repowebhook.UUID,
+	)
+	if err != nil {
+		return err
+	}
+	return r.checkAffected(result, webhook.UUID)
+}
+
+func (r *Webhook) Delete(ctx context.Context, uuid string) error {
+	q := `DELETE FROM webhooks WHERE uuid = $1;`
+
+	result, err := r.db.ExecContext(ctx, q, uuid)
+	if err != nil {
+		return err
+	}
+	return r.checkAffected(result, uuid)
+}
+
+func (r *Webhook) checkAffected(result sql.Result, uuid string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repositories.NewErrWebhookNotFoundByUUID(uuid)
+	}
+	return nil
+}
+
+// scan reads a row selected with webhookColumns
+func (r *Webhook) scan(row scanner) (*repositories.Webhook, error) {
+	var webhook repositories.Webhook
+	err := row.Scan(
+		&webhook.UUID,
+		&webhook.URL,
+		&webhook.Secret,
+		&webhook.EventTypes,
+		&webhook.Active,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *Webhook) entity2repo(entity *entities.Webhook) *repositories.Webhook {
+	return &repositories.Webhook{
+		UUID:       entity.UUID,
+		URL:        entity.URL,
+		Secret:     entity.Secret,
+		EventTypes: strings.Join(entity.EventTypes, ","),
+		Active:     entity.Active,
+		CreatedAt:  entity.CreatedAt,
+		UpdatedAt:  entity.UpdatedAt,
+	}
+}
+
+func (r *Webhook) repo2entity(repo *repositories.Webhook) *entities.Webhook {
+	var eventtypes []string
+	if repo.EventTypes != "" {
+		eventtypes = strings.Split(repo.EventTypes, ",")
+	}
+	return &entities.Webhook{
+		UUID:       repo.UUID,
+		URL:        repo.URL,
+		Secret:     repo.Secret,
+		EventTypes: eventtypes,
+		Active:     repo.Active,
+		CreatedAt:  repo.CreatedAt,
+		UpdatedAt:  repo.UpdatedAt,
+	}
+}
+
+type WebhookDelivery struct {
+	db      Querier
+	dialect *Dialect
+}
+
+func NewWebhookDelivery(db Querier, dialect *Dialect) *WebhookDelivery {
+	return &WebhookDelivery{
+		db:      dialect.bind(db),
+		dialect: dialect,
+	}
+}
+
+func (r *WebhookDelivery) Add(ctx context.Context, delivery *entities.WebhookDelivery) error {
+	repodelivery := r.entity2repo(delivery)
+
+	// a republished event is delivered to the webhook once
+	q := `
+		INSERT INTO webhook_deliveries (webhookuuid, eventuuid, eventtype, payload, status, attempts,
+			nextattemptat, laststatuscode, lasterror, createdat, updatedat)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (webhookuuid, eventuuid) DO NOTHING;
+	`
+
+	_, err := r.db.ExecContext(ctx, q,
+		repodelivery.WebhookUUID,
+		repodelivery.EventUUID,
+		repodelivery.EventType,
+		repodelivery.Payload,
+		repodelivery.Status,
+		repodelivery.Attempts,
+		repodelivery.NextAttemptAt,
+		repodelivery.LastStatusCode,
+		repodelivery.LastError,
+		repodelivery.CreatedAt,
+		repodelivery.UpdatedAt,
+	)
+	if r.dialect.IsForeignKeyViolation(err) {
+		return repositories.NewErrWebhookNotFoundByUUID(delivery.WebhookUUID)
+	}
+	return err
+}
+
+func (r *WebhookDelivery) GetOneByID(ctx context.Context, id int64) (*entities.WebhookDelivery, error) {
+	q := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = $1;`
+
+	delivery, err := r.scan(r.db.QueryRowContext(ctx, q, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repositories.NewErrWebhookDeliveryNotFoundByID(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.repo2entity(delivery), nil
+}
+
+func (r *WebhookDelivery) ClaimDue(ctx context.Context, now, leaseuntil int64) (*entities.WebhookDelivery, error) {
+	// the claim lock of the dialect keeps the concurrent workers from claiming the same delivery
+	q := `
+		UPDATE webhook_deliveries SET nextattemptat = $1, updatedat = $2
+		WHERE id = (
+			SELECT id FROM webhook_deliveries
+			WHERE status = $3 AND nextattemptat <= $2
+			ORDER BY nextattemptat, id
+			LIMIT 1
+			` + r.dialect.ClaimLock + `
+		)
+		RETURNING ` + webhookDeliveryColumns + `;
+	`
+
+	delivery, err := r.scan(r.db.QueryRowContext(ctx, q, leaseuntil, now, entities.WebhookDeliveryPending))
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	default:
+		return r.repo2entity(delivery), nil
+	}
+}
+
+func (r *WebhookDelivery) Update(ctx context.Context, delivery *entities.WebhookDelivery) error {
+	repodelivery := r.entity2repo(delivery)
+
+	q := `
+		UPDATE webhook_deliveries SET status = $1, attempts = $2, nextattemptat = $3, laststatuscode = $4,
+			lasterror = $5, updatedat = $6
+		WHERE id = $7;
+	`
+
+	result, err := r.db.ExecContext(ctx, q,
+		repodelivery.Status,
+		repodelivery.Attempts,
+		repodelivery.NextAttemptAt,
+		repodelivery.LastStatusCode,
+		repodelivery.LastError,
+		repodelivery.UpdatedAt,
+		repodelivery.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repositories.NewErrWebhookDeliveryNotFoundByID(delivery.ID)
+	}
+	return nil
+}
+
+func (r *WebhookDelivery) Find(ctx context.Context, filter *repositories.WebhookDeliveryFilter) ([]*entities.WebhookDelivery, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	where := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, strings.ReplaceAll(condition, "?", "$"+strconv.Itoa(len(args))))
+	}
+
+	if filter.WebhookUUID != "" {
+		where("webhookuuid = ?", filter.WebhookUUID)
+	}
+	if len(filter.Statuses) > 0 {
+		placeholders := make([]string, 0, len(filter.Statuses))
+		for _, s := range filter.Statuses {
+			args = append(args, s)
+			placeholders = append(placeholders, "$"+strconv.Itoa(len(args)))
+		}
+		conditions = append(conditions, "status IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if filter.BeforeID > 0 {
+		where("id < ?", filter.BeforeID)
+	}
+
+	q := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries`
+	if len(conditions) > 0 {
+		q += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+	q += ` ORDER BY id DESC LIMIT $` + strconv.Itoa(len(args)) + `;`
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*entities.WebhookDelivery
+	for rows.Next() {
+		delivery, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, r.repo2entity(delivery))
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *WebhookDelivery) DeleteByWebhook(ctx context.Context, webhookuuid string) error {
+	q := `DELETE FROM webhook_deliveries WHERE webhookuuid = $1;`
+
+	_, err := r.db.ExecContext(ctx, q, webhookuuid)
+	return err
+}
+
+func (r *WebhookDelivery) SetPayloadByEvent(ctx context.Context, eventuuid, payload string) error {
+	q := `UPDATE webhook_deliveries SET payload = $1 WHERE eventuuid = $2;`
+
+	_, err := r.db.ExecContext(ctx, q, payload, eventuuid)
+	return err
+}
+
+func (r *WebhookDelivery) DeleteFinishedBefore(ctx context.Context, before int64) (int, error) {
+	q := `DELETE FROM webhook_deliveries WHERE status IN ($1, $2) AND updatedat <= $3;`
+
+	result, err := r.db.ExecContext(ctx, q, entities.WebhookDeliveryDelivered, entities.WebhookDeliveryDead, before)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+// scan reads a row selected with webhookDeliveryColumns
+func (r *WebhookDelivery) scan(row scanner) (*repositories.WebhookDelivery, error) {
+	var delivery repositories.WebhookDelivery
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.WebhookUUID,
+		&delivery.EventUUID,
+		&delivery.EventType,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastStatusCode,
+		&delivery.LastError,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *WebhookDelivery) entity2repo(entity *entities.WebhookDelivery) *repositories.WebhookDelivery {
+	return &repositories.WebhookDelivery{
+		ID:             entity.ID,
+		WebhookUUID:    entity.WebhookUUID,
+		EventUUID:      entity.EventUUID,
+		EventType:      entity.EventType,
+		Payload:        entity.Payload,
+		Status:         entity.Status,
+		Attempts:       entity.Attempts,
+		NextAttemptAt:  entity.NextAttemptAt,
+		LastStatusCode: entity.LastStatusCode,
+		LastError:      entity.LastError,
+		CreatedAt:      entity.CreatedAt,
+		UpdatedAt:      entity.UpdatedAt,
+	}
+}
+
+func (r *WebhookDelivery) repo2entity(repo *repositories.WebhookDelivery) *entities.WebhookDelivery {
+	return &entities.WebhookDelivery{
+		ID:             repo.ID,
+		WebhookUUID:    repo.WebhookUUID,
+		EventUUID:      repo.EventUUID,
+		EventType:      repo.EventType,
+		Payload:        repo.Payload,
+		Status:         repo.Status,
+		Attempts:       repo.Attempts,
+		NextAttemptAt:  repo.NextAttemptAt,
+		LastStatusCode: repo.LastStatusCode,
+		LastError:      repo.LastError,
+		CreatedAt:      repo.CreatedAt,
+		UpdatedAt:      repo.UpdatedAt,
+	}
+}
@@ -0,0 +1,347 @@
+package dbsqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"github.com/mattn/go-sqlite3"
+	"strings"
+	"time"
+)
+
+const (
+	accountColumns = `uuid, email, password, name, status, statusuntil, createdat, updatedat, deletedat, purgedat`
+)
+
+type Account struct {
+	db querier
+}
+
+func NewAccount(dbinst *SQLite) (*Account, error) {
+	if dbinst == nil || dbinst.db == nil {
+		return nil, ErrDbIsNil
+	}
+	return &Account{
+		db: dbinst.db,
+	}, nil
+}
+
+func (r *Account) IsExist(ctx context.Context, account *entities.Account) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM accounts WHERE lower(email) = lower(?));`
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, account.Email).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *Account) IsExistByUUID(ctx context.Context, uuid string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM accounts WHERE uuid = ?);`
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, uuid).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *Account) Create(ctx context.Context, account *entities.Account) (*entities.Account, error) {
+	repoaccount := r.entity2repo(account)
+
+	query := `
+		INSERT INTO accounts (uuid, name, email, password, status, statusuntil, createdat, updatedat, deletedat, purgedat)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING ` + accountColumns + `;
+	`
+
+	row := r.db.QueryRowContext(ctx, query,
+		repoaccount.UUID,
+		repoaccount.Name,
+		repoaccount.Email,
+		repoaccount.Password,
+		repoaccount.Status,
+		repoaccount.StatusUntil,
+		repoaccount.CreatedAt,
+		repoaccount.UpdatedAt,
+		repoaccount.DeletedAt,
+		repoaccount.PurgedAt,
+	)
+
+	created, err := r.scan(row)
+	if isUniqueViolation(err) {
+		return nil, repositories.NewErrAccountAlreadyExists(account.Email)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return r.repo2entity(created), nil
+}
+
+func (r *Account) GetOneByEmail(ctx context.Context, email string) (*entities.Account, error) {
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE lower(email) = lower(?);`
+
+	account, err := r.scan(r.db.QueryRowContext(ctx, query, email))
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, repositories.NewErrAccountNotFoundByEmail(email)
+	case err != nil:
+		return nil, err
+	default:
+		return r.repo2entity(account), nil
+	}
+}
+
+func (r *Account) GetOneByUUID(ctx context.Context, uuid string) (*entities.Account, error) {
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE uuid = ?;`
+
+	account, err := r.scan(r.db.QueryRowContext(ctx, query, uuid))
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, repositories.NewErrAccountNotFoundByUUID(uuid)
+	case err != nil:
+		return nil, err
+	default:
+		return r.repo2entity(account), nil
+	}
+}
+
+func (r *Account) GetManyByUUIDs(ctx context.Context, uuids []string) ([]*entities.Account, error) {
+	if len(uuids) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT ` + accountColumns + ` FROM accounts
+		WHERE uuid IN (?` + strings.Repeat(", ?", len(uuids)-1) + `);
+	`
+
+	args := make([]any, 0, len(uuids))
+	for _, uuid := range uuids {
+		args = append(args, uuid)
+	}
+	return r.query(ctx, query, args...)
+}
+
+func (r *Account) Find(ctx context.Context, filter *repositories.AccountFilter) ([]*entities.Account, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	where := func(condition string, values ...any) {
+		args = append(args, values...)
+		conditions = append(conditions, condition)
+	}
+
+	if len(filter.Statuses) > 0 {
+		statuses := make([]any, 0, len(filter.Statuses))
+		for _, status := range filter.Statuses {
+			statuses = append(statuses, status)
+		}
+		where("status IN (?"+strings.Repeat(", ?", len(statuses)-1)+")", statuses...)
+	}
+	// LIKE is case-insensitive for ASCII in SQLite
+	if filter.EmailPrefix != "" {
+		where(`lower(email) LIKE ? ESCAPE '\'`, repositories.EscapeLike(strings.ToLower(filter.EmailPrefix))+"%")
+	}
+	if filter.NameContains != "" {
+		where(`name LIKE ? ESCAPE '\'`, "%"+repositories.EscapeLike(filter.NameContains)+"%")
+	}
+	if filter.CreatedSince > 0 {
+		where("createdat >= ?", filter.CreatedSince)
+	}
+	if filter.CreatedUntil > 0 {
+		where("createdat < ?", filter.CreatedUntil)
+	}
+
+	key, order, compare := "createdat", "ASC", ">"
+	if filter.Sort == repositories.AccountSortEmail {
+		key = "lower(email)"
+	}
+	if filter.Descending {
+		order, compare = "DESC", "<"
+	}
+	if filter.After != nil {
+		var after any = filter.After.CreatedAt
+		if filter.Sort == repositories.AccountSortEmail {
+			after = filter.After.Email
+		}
+		where("("+key+", uuid) "+compare+" (?, ?)", after, filter.After.UUID)
+	}
+
+	query := `SELECT ` + accountColumns + ` FROM accounts`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += ` ORDER BY ` + key + ` ` + order + `, uuid ` + order + ` LIMIT ?;`
+
+	return r.query(ctx, query, args...)
+}
+
+func (r *Account) SetAccountStatus(ctx context.Context, uuid string, status uint8, until int64) error {
+	// restoring a deleted account cancels its purging, purged accounts can't be restored
+	q := `UPDATE accounts SET status = ?, statusuntil = ?, updatedat = ?, deletedat = 0 WHERE uuid = ? AND purgedat = 0;`
+
+	result, err := r.db.ExecContext(ctx, q, status, until, time.Now().Unix(), uuid)
+	if err != nil {
+		return err
+	}
+	return r.checkAffected(result, uuid)
+}
+
+func (r *Account) SetPassword(ctx context.Context, uuid, password string, updatedat int64) error {
+	q := `UPDATE accounts SET password = ?, updatedat = ? WHERE uuid = ? AND purgedat = 0;`
+
+	result, err := r.db.ExecContext(ctx, q, password, updatedat, uuid)
+	if err != nil {
+		return err
+	}
+	return r.checkAffected(result, uuid)
+}
+
+func (r *Account) MarkAccountDeleted(ctx context.Context, uuid string, deletedat int64) error {
+	q := `UPDATE accounts SET status = ?, statusuntil = 0, deletedat = ?, updatedat = ? WHERE uuid = ? AND purgedat = 0;`
+
+	result, err := r.db.ExecContext(ctx, q, entities.Deleted, deletedat, deletedat, uuid)
+	if err != nil {
+		return err
+	}
+	return r.checkAffected(result, uuid)
+}
+
+func (r *Account) GetDeletedBefore(ctx context.Context, before int64, limit int) ([]*entities.Account, error) {
+	query := `
+		SELECT ` + accountColumns + ` FROM accounts
+		WHERE status = ? AND purgedat = 0 AND deletedat <= ?
+		ORDER BY deletedat
+		LIMIT ?;
+	`
+
+	return r.query(ctx, query, entities.Deleted, before, limit)
+}
+
+func (r *Account) GetStatusExpiredBefore(ctx context.Context, before int64, limit int) ([]*entities.Account, error) {
+	query := `
+		SELECT ` + accountColumns + ` FROM accounts
+		WHERE statusuntil <> 0 AND statusuntil <= ? AND purgedat = 0
+		ORDER BY statusuntil
+		LIMIT ?;
+	`
+
+	return r.query(ctx, query, before, limit)
+}
+
+func (r *Account) PurgeAccount(ctx context.Context, uuid string, purgedat int64) error {
+	q := `
+		UPDATE accounts SET email = ?, name = '', password = '', purgedat = ?, updatedat = ?
+		WHERE uuid = ? AND status = ? AND purgedat = 0;
+	`
+
+	result, err := r.db.ExecContext(ctx, q, repositories.AnonymizedEmail(uuid), purgedat, purgedat, uuid, entities.Deleted)
+	if err != nil {
+		return err
+	}
+	return r.checkAffected(result, uuid)
+}
+
+// checkAffected reports an account which isn't updated as not found
+func (r *Account) checkAffected(result sql.Result, uuid string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repositories.NewErrAccountNotFoundByUUID(uuid)
+	}
+	return nil
+}
+
+// query reads the rows selected with accountColumns
+func (r *Account) query(ctx context.Context, query string, args ...any) ([]*entities.Account, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []*entities.Account
+	for rows.Next() {
+		account, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, r.repo2entity(account))
+	}
+	return accounts, rows.Err()
+}
+
+// scan reads a row selected with accountColumns
+func (r *Account) scan(row scanner) (*repositories.Account, error) {
+	var account repositories.Account
+	err := row.Scan(
+		&account.UUID,
+		&account.Email,
+		&account.Password,
+		&account.Name,
+		&account.Status,
+		&account.StatusUntil,
+		&account.CreatedAt,
+		&account.UpdatedAt,
+		&account.DeletedAt,
+		&account.PurgedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *Account) entity2repo(entity *entities.Account) *repositories.Account {
+	return &repositories.Account{
+		UUID:        entity.UUID,
+		Name:        entity.Name,
+		Email:       entity.Email,
+		Password:    entity.Password,
+		Status:      entity.Status,
+		StatusUntil: entity.StatusUntil,
+		CreatedAt:   entity.CreatedAt,
+		UpdatedAt:   entity.UpdatedAt,
+		DeletedAt:   entity.DeletedAt,
+		PurgedAt:    entity.PurgedAt,
+	}
+}
+
+func (r *Account) repo2entity(repo *repositories.Account) *entities.Account {
+	return &entities.Account{
+		UUID:        repo.UUID,
+		Name:        repo.Name,
+		Email:       repo.Email,
+		Password:    repo.Password,
+		Status:      repo.Status,
+		StatusUntil: repo.StatusUntil,
+		CreatedAt:   repo.CreatedAt,
+		UpdatedAt:   repo.UpdatedAt,
+		DeletedAt:   repo.DeletedAt,
+		PurgedAt:    repo.PurgedAt,
+	}
+}
+
+func isUniqueViolation(err error) bool {
+	var sqliteerr sqlite3.Error
+	if !errors.As(err, &sqliteerr) {
+		return false
+	}
+	return sqliteerr.ExtendedCode == sqlite3.ErrConstraintUnique ||
+		sqliteerr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+}
+
+func isForeignKeyViolation(err error) bool {
+	var sqliteerr sqlite3.Error
+	return errors.As(err, &sqliteerr) && sqliteerr.ExtendedCode == sqlite3.ErrConstraintForeignKey
+}
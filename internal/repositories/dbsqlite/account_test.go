@@ -0,0 +1,96 @@
+package dbsqlite
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/repositories/repotest"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+func newTestSQLite(t *testing.T) *SQLite {
+	db, err := New(&Config{Path: filepath.Join(t.TempDir(), "runbot.db")})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	return db
+}
+
+func TestSQLite_Ping(t *testing.T) {
+	db := newTestSQLite(t)
+	require.NoError(t, db.Ping(context.Background()))
+
+	require.NoError(t, db.Close())
+	require.Error(t, db.Ping(context.Background()))
+}
+
+func TestAccount(t *testing.T) {
+	repotest.AccountRepo(t, func(t *testing.T) usecases.IAccountRepo {
+		repo, err := NewAccount(newTestSQLite(t))
+		require.NoError(t, err)
+		return repo
+	})
+}
+
+func TestAccount_InMemory(t *testing.T) {
+	repotest.AccountRepo(t, func(t *testing.T) usecases.IAccountRepo {
+		db, err := New(&Config{Path: InMemoryPath})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = db.Close()
+		})
+		repo, err := NewAccount(db)
+		require.NoError(t, err)
+		return repo
+	})
+}
+
+func TestTransactor(t *testing.T) {
+	repotest.Transactor(t, func(t *testing.T) (usecases.ITransactor, *usecases.TxRepos) {
+		db := newTestSQLite(t)
+		account, err := NewAccount(db)
+		require.NoError(t, err)
+		statushistory, err := NewStatusHistory(db)
+		require.NoError(t, err)
+		session, err := NewSession(db)
+		require.NoError(t, err)
+		securityevent, err := NewSecurityEvent(db)
+		require.NoError(t, err)
+		auditlog, err := NewAuditLog(db)
+		require.NoError(t, err)
+		export, err := NewExport(db)
+		require.NoError(t, err)
+		outbox, err := NewOutbox(db)
+		require.NoError(t, err)
+		webhook, err := NewWebhook(db)
+		require.NoError(t, err)
+		delivery, err := NewWebhookDelivery(db)
+		require.NoError(t, err)
+		tx, err := NewTransactor(db)
+		require.NoError(t, err)
+		return tx, &usecases.TxRepos{
+			Account:         account,
+			StatusHistory:   statushistory,
+			Session:         session,
+			SecurityEvent:   securityevent,
+			AuditLog:        auditlog,
+			Export:          export,
+			Outbox:          outbox,
+			Webhook:         webhook,
+			WebhookDelivery: delivery,
+		}
+	})
+}
+
+func TestAccountImport(t *testing.T) {
+	repotest.AccountImportRepo(t, func(t *testing.T) (usecases.IAccountImportRepo, usecases.IAccountRepo) {
+		db := newTestSQLite(t)
+		importrepo, err := NewAccountImport(db)
+		require.NoError(t, err)
+		accountrepo, err := NewAccount(db)
+		require.NoError(t, err)
+		return importrepo, accountrepo
+	})
+}
@@ -0,0 +1,80 @@
+package dbsqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+)
+
+// AccountImport loads the accounts in bulk, e.g. migrated from another install
+type AccountImport struct {
+	db *sql.DB
+}
+
+func NewAccountImport(dbinst *SQLite) (*AccountImport, error) {
+	if dbinst == nil || dbinst.db == nil {
+		return nil, ErrDbIsNil
+	}
+	return &AccountImport{
+		db: dbinst.db,
+	}, nil
+}
+
+// Import inserts the accounts in one transaction with a prepared statement.
+// The accounts whose UUID or email exists are skipped, their UUIDs are returned
+func (r *AccountImport) Import(ctx context.Context, accounts []*entities.Account) ([]string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	skipped, err := r.importTx(ctx, tx, accounts)
+	if err != nil {
+		if rberr := tx.Rollback(); rberr != nil && !errors.Is(rberr, sql.ErrTxDone) {
+			return nil, errors.Join(err, rberr)
+		}
+		return nil, err
+	}
+	return skipped, tx.Commit()
+}
+
+func (r *AccountImport) importTx(ctx context.Context, tx *sql.Tx, accounts []*entities.Account) ([]string, error) {
+	query := `
+		INSERT INTO accounts (` + accountColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT DO NOTHING;
+	`
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	var skipped []string
+	for _, account := range accounts {
+		result, err := stmt.ExecContext(ctx,
+			account.UUID,
+			account.Email,
+			account.Password,
+			account.Name,
+			account.Status,
+			account.StatusUntil,
+			account.CreatedAt,
+			account.UpdatedAt,
+			account.DeletedAt,
+			account.PurgedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			skipped = append(skipped, account.UUID)
+		}
+	}
+	return skipped, nil
+}
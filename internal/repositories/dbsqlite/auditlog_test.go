@@ -0,0 +1,34 @@
+package dbsqlite
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/repositories/repotest"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestAuditLog(t *testing.T) {
+	repotest.AuditLogRepo(t, func(t *testing.T) usecases.IAuditLogRepo {
+		repo, err := NewAuditLog(newTestSQLite(t))
+		require.NoError(t, err)
+		return repo
+	})
+}
+
+func TestAuditLog_AppendOnly(t *testing.T) {
+	db := newTestSQLite(t)
+	ctx := context.Background()
+
+	_, err := db.db.ExecContext(ctx, `
+		INSERT INTO audit_log (actor, action, createdat, prevhash, hash) VALUES ('admin', 'account.deleted', 1, '', 'hash');
+	`)
+	require.NoError(t, err)
+
+	_, err = db.db.ExecContext(ctx, `UPDATE audit_log SET actor = 'someone';`)
+	assert.ErrorContains(t, err, "append-only")
+
+	_, err = db.db.ExecContext(ctx, `DELETE FROM audit_log;`)
+	assert.ErrorContains(t, err, "append-only")
+}
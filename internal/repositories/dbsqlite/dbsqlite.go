@@ -0,0 +1,104 @@
+package dbsqlite
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"errors"
+	"fmt"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var (
+	ErrConfigIsNil = errors.New("config is nil")
+	ErrDbIsNil     = errors.New("db is nil")
+	ErrPathIsEmpty = errors.New("sqlite path is empty")
+)
+
+const (
+	sqliteKey = "sqlite3"
+
+	// InMemoryPath keeps the database in memory while the process is running
+	InMemoryPath = ":memory:"
+)
+
+// schema is applied at the startup, the storage is intended for local development
+// so it doesn't have versioned migrations like dbpostgres
+//
+//go:embed schema.sql
+var schema string
+
+type Config struct {
+	Path string
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+type SQLite struct {
+	db *sql.DB
+}
+
+func New(c *Config) (*SQLite, error) {
+	if c == nil {
+		return nil, ErrConfigIsNil
+	}
+	if c.Path == "" {
+		return nil, ErrPathIsEmpty
+	}
+
+	dsn := fmt.Sprintf("file:%s?_busy_timeout=5000&_foreign_keys=on", c.Path)
+
+	db, err := sql.Open(sqliteKey, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite has a single writer, so the pool is limited to avoid "database is locked" errors.
+	// It also keeps an in-memory database alive, it's dropped with its last connection
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+
+	if err = db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	if _, err = db.ExecContext(context.Background(), schema); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &SQLite{db}, nil
+}
+
+// Stats are the connection pool stats, e.g. for the metrics
+func (s *SQLite) Stats() sql.DBStats {
+	return s.db.Stats()
+}
+
+// Ping checks the database answers
+func (s *SQLite) Ping(ctx context.Context) error {
+	if s.db != nil {
+		return s.db.PingContext(ctx)
+	}
+	return ErrDbIsNil
+}
+
+func (s *SQLite) Close() error {
+	if s.db != nil {
+		return s.db.Close()
+	}
+	return ErrDbIsNil
+}
@@ -0,0 +1,16 @@
+package dbsqlite
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/repositories/repotest"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestOutbox(t *testing.T) {
+	repotest.OutboxRepo(t, func(t *testing.T) usecases.IOutboxRepo {
+		repo, err := NewOutbox(newTestSQLite(t))
+		require.NoError(t, err)
+		return repo
+	})
+}
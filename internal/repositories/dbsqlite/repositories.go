@@ -0,0 +1,84 @@
+package dbsqlite
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/repositories/dbsql"
+	"regexp"
+)
+
+// placeholder is a numbered placeholder of the shared queries, SQLite numbers them as ?1, ?2...
+var placeholder = regexp.MustCompile(`\$(\d+)`)
+
+var dialect = &dbsql.Dialect{
+	Rebind: func(query string) string {
+		return placeholder.ReplaceAllString(query, "?$1")
+	},
+	// the single connection of the pool serializes the claims
+	ClaimLock: "",
+	// SQLite has a single writer, so the seq are committed in their order. The trigger outbox_seq_trg
+	// stores the taken seq
+	MarkOutboxPublished: `
+		UPDATE outbox SET
+			publishedat = $1,
+			seq = CASE WHEN seq = 0 THEN (SELECT value + 1 FROM outboxseq WHERE id = 1) ELSE seq END,
+			attempts = attempts + 1,
+			lasterror = ''
+		WHERE id = $2;
+	`,
+	IsForeignKeyViolation: isForeignKeyViolation,
+}
+
+func NewStatusHistory(dbinst *SQLite) (*dbsql.StatusHistory, error) {
+	if dbinst == nil || dbinst.db == nil {
+		return nil, ErrDbIsNil
+	}
+	return dbsql.NewStatusHistory(dbinst.db, dialect), nil
+}
+
+func NewSession(dbinst *SQLite) (*dbsql.Session, error) {
+	if dbinst == nil || dbinst.db == nil {
+		return nil, ErrDbIsNil
+	}
+	return dbsql.NewSession(dbinst.db, dialect), nil
+}
+
+func NewSecurityEvent(dbinst *SQLite) (*dbsql.SecurityEvent, error) {
+	if dbinst == nil || dbinst.db == nil {
+		return nil, ErrDbIsNil
+	}
+	return dbsql.NewSecurityEvent(dbinst.db, dialect), nil
+}
+
+func NewAuditLog(dbinst *SQLite) (*dbsql.AuditLog, error) {
+	if dbinst == nil || dbinst.db == nil {
+		return nil, ErrDbIsNil
+	}
+	return dbsql.NewAuditLog(dbinst.db, dialect), nil
+}
+
+func NewOutbox(dbinst *SQLite) (*dbsql.Outbox, error) {
+	if dbinst == nil || dbinst.db == nil {
+		return nil, ErrDbIsNil
+	}
+	return dbsql.NewOutbox(dbinst.db, dialect), nil
+}
+
+func NewExport(dbinst *SQLite) (*dbsql.Export, error) {
+	if dbinst == nil || dbinst.db == nil {
+		return nil, ErrDbIsNil
+	}
+	return dbsql.NewExport(dbinst.db, dialect), nil
+}
+
+func NewWebhook(dbinst *SQLite) (*dbsql.Webhook, error) {
+	if dbinst == nil || dbinst.db == nil {
+		return nil, ErrDbIsNil
+	}
+	return dbsql.NewWebhook(dbinst.db, dialect), nil
+}
+
+func NewWebhookDelivery(dbinst *SQLite) (*dbsql.WebhookDelivery, error) {
+	if dbinst == nil || dbinst.db == nil {
+		return nil, ErrDbIsNil
+	}
+	return dbsql.NewWebhookDelivery(dbinst.db, dialect), nil
+}
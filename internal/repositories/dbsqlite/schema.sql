@@ -0,0 +1,162 @@
+CREATE TABLE IF NOT EXISTS accounts (
+    uuid      TEXT PRIMARY KEY,
+    name      TEXT NOT NULL,
+    email     TEXT NOT NULL,
+    password  TEXT NOT NULL,
+    status      INTEGER NOT NULL DEFAULT 0,
+    statusuntil INTEGER NOT NULL DEFAULT 0,
+    createdat   INTEGER NOT NULL,
+    updatedat   INTEGER NOT NULL DEFAULT 0,
+    deletedat   INTEGER NOT NULL DEFAULT 0,
+    purgedat    INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS accounts_email_lower_uidx ON accounts (lower(email));
+
+CREATE INDEX IF NOT EXISTS accounts_deleted_idx ON accounts (deletedat) WHERE status = 3 AND purgedat = 0;
+
+CREATE INDEX IF NOT EXISTS accounts_statusuntil_idx ON accounts (statusuntil) WHERE statusuntil <> 0;
+
+CREATE INDEX IF NOT EXISTS accounts_createdat_idx ON accounts (createdat, uuid);
+CREATE INDEX IF NOT EXISTS accounts_email_search_idx ON accounts (lower(email), uuid);
+
+CREATE TABLE IF NOT EXISTS account_exports (
+    uuid        TEXT PRIMARY KEY,
+    accountuuid TEXT NOT NULL REFERENCES accounts (uuid) ON DELETE CASCADE,
+    status      INTEGER NOT NULL DEFAULT 0,
+    archive     BLOB,
+    createdat   INTEGER NOT NULL,
+    updatedat   INTEGER NOT NULL DEFAULT 0,
+    readyat     INTEGER NOT NULL DEFAULT 0,
+    expiresat   INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS account_exports_accountuuid_idx ON account_exports (accountuuid);
+CREATE INDEX IF NOT EXISTS account_exports_status_idx ON account_exports (status, createdat);
+
+CREATE TABLE IF NOT EXISTS account_status_history (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    accountuuid TEXT NOT NULL REFERENCES accounts (uuid) ON DELETE CASCADE,
+    fromstatus  INTEGER NOT NULL,
+    tostatus    INTEGER NOT NULL,
+    reason      TEXT NOT NULL DEFAULT '',
+    actor       TEXT NOT NULL DEFAULT '',
+    until       INTEGER NOT NULL DEFAULT 0,
+    createdat   INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS account_status_history_accountuuid_idx ON account_status_history (accountuuid, id DESC);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    uuid        TEXT PRIMARY KEY,
+    accountuuid TEXT NOT NULL REFERENCES accounts (uuid) ON DELETE CASCADE,
+    ip          TEXT NOT NULL DEFAULT '',
+    useragent   TEXT NOT NULL DEFAULT '',
+    devicename  TEXT NOT NULL DEFAULT '',
+    createdat   INTEGER NOT NULL,
+    refreshedat INTEGER NOT NULL,
+    expiresat   INTEGER NOT NULL,
+    revokedat   INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS sessions_accountuuid_idx ON sessions (accountuuid, refreshedat DESC);
+
+CREATE TABLE IF NOT EXISTS security_events (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    accountuuid TEXT NOT NULL REFERENCES accounts (uuid) ON DELETE CASCADE,
+    type        INTEGER NOT NULL,
+    ip          TEXT NOT NULL DEFAULT '',
+    useragent   TEXT NOT NULL DEFAULT '',
+    details     TEXT NOT NULL DEFAULT '',
+    createdat   INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS security_events_accountuuid_idx ON security_events (accountuuid, id DESC);
+CREATE INDEX IF NOT EXISTS security_events_createdat_idx ON security_events (createdat);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor      TEXT NOT NULL,
+    action     TEXT NOT NULL,
+    targetuuid TEXT NOT NULL DEFAULT '',
+    before     TEXT NOT NULL DEFAULT '',
+    after      TEXT NOT NULL DEFAULT '',
+    method     TEXT NOT NULL DEFAULT '',
+    ip         TEXT NOT NULL DEFAULT '',
+    useragent  TEXT NOT NULL DEFAULT '',
+    createdat  INTEGER NOT NULL,
+    prevhash   TEXT NOT NULL UNIQUE,
+    hash       TEXT NOT NULL
+);
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TABLE IF NOT EXISTS outbox (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid        TEXT NOT NULL UNIQUE,
+    type        TEXT NOT NULL,
+    accountuuid TEXT NOT NULL,
+    payload     TEXT NOT NULL,
+    createdat   INTEGER NOT NULL,
+    publishedat INTEGER NOT NULL DEFAULT 0,
+    seq         INTEGER NOT NULL DEFAULT 0,
+    attempts    INTEGER NOT NULL DEFAULT 0,
+    lasterror   TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS outbox_unpublished_idx ON outbox (id) WHERE publishedat = 0;
+CREATE INDEX IF NOT EXISTS outbox_publishedat_idx ON outbox (publishedat) WHERE publishedat <> 0;
+CREATE UNIQUE INDEX IF NOT EXISTS outbox_seq_idx ON outbox (seq) WHERE seq <> 0;
+CREATE INDEX IF NOT EXISTS outbox_accountuuid_idx ON outbox (accountuuid, type);
+
+-- outboxseq holds the last assigned seq, so the seq aren't reused after the deletion
+CREATE TABLE IF NOT EXISTS outboxseq (
+    id    INTEGER PRIMARY KEY CHECK (id = 1),
+    value INTEGER NOT NULL
+);
+
+INSERT OR IGNORE INTO outboxseq (id, value) VALUES (1, 0);
+
+-- the seq assigned by MarkPublished is stored in the same statement
+CREATE TRIGGER IF NOT EXISTS outbox_seq_trg AFTER UPDATE OF seq ON outbox WHEN NEW.seq > OLD.seq
+BEGIN
+    UPDATE outboxseq SET value = NEW.seq WHERE id = 1;
+END;
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    uuid       TEXT PRIMARY KEY,
+    url        TEXT NOT NULL,
+    secret     TEXT NOT NULL,
+    eventtypes TEXT NOT NULL DEFAULT '',
+    active     BOOLEAN NOT NULL DEFAULT 1,
+    createdat  INTEGER NOT NULL,
+    updatedat  INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhookuuid    TEXT NOT NULL REFERENCES webhooks (uuid) ON DELETE CASCADE,
+    eventuuid      TEXT NOT NULL,
+    eventtype      TEXT NOT NULL,
+    payload        TEXT NOT NULL,
+    status         INTEGER NOT NULL DEFAULT 0,
+    attempts       INTEGER NOT NULL DEFAULT 0,
+    nextattemptat  INTEGER NOT NULL DEFAULT 0,
+    laststatuscode INTEGER NOT NULL DEFAULT 0,
+    lasterror      TEXT NOT NULL DEFAULT '',
+    createdat      INTEGER NOT NULL,
+    updatedat      INTEGER NOT NULL,
+    UNIQUE (webhookuuid, eventuuid)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (nextattemptat, id) WHERE status = 0;
+CREATE INDEX IF NOT EXISTS webhook_deliveries_updatedat_idx ON webhook_deliveries (updatedat) WHERE status <> 0;
+CREATE INDEX IF NOT EXISTS webhook_deliveries_eventuuid_idx ON webhook_deliveries (eventuuid);
@@ -0,0 +1,19 @@
+package dbsqlite
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/repositories/repotest"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStatusHistory(t *testing.T) {
+	repotest.StatusHistoryRepo(t, func(t *testing.T) (usecases.IStatusHistoryRepo, usecases.IAccountRepo) {
+		db := newTestSQLite(t)
+		repo, err := NewStatusHistory(db)
+		require.NoError(t, err)
+		accounts, err := NewAccount(db)
+		require.NoError(t, err)
+		return repo, accounts
+	})
+}
@@ -0,0 +1,82 @@
+package dbsqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/repositories/dbsql"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/mattn/go-sqlite3"
+	"time"
+)
+
+const (
+	txMaxAttempts = 3
+	txRetryDelay  = 20 * time.Millisecond
+)
+
+// Transactor runs repository calls in transactions, SQLite transactions are always serializable
+type Transactor struct {
+	db *sql.DB
+}
+
+func NewTransactor(dbinst *SQLite) (*Transactor, error) {
+	if dbinst == nil || dbinst.db == nil {
+		return nil, ErrDbIsNil
+	}
+	return &Transactor{
+		db: dbinst.db,
+	}, nil
+}
+
+func (t *Transactor) WithTx(ctx context.Context, fn func(repos *usecases.TxRepos) error) error {
+	var err error
+	for attempt := 1; attempt <= txMaxAttempts; attempt++ {
+		err = t.withTx(ctx, fn)
+		if err == nil || !isBusy(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(txRetryDelay * time.Duration(attempt)):
+		}
+	}
+	return err
+}
+
+func (t *Transactor) withTx(ctx context.Context, fn func(repos *usecases.TxRepos) error) error {
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	repos := &usecases.TxRepos{
+		Account:         &Account{db: tx},
+		StatusHistory:   dbsql.NewStatusHistory(tx, dialect),
+		Session:         dbsql.NewSession(tx, dialect),
+		SecurityEvent:   dbsql.NewSecurityEvent(tx, dialect),
+		AuditLog:        dbsql.NewAuditLog(tx, dialect),
+		Export:          dbsql.NewExport(tx, dialect),
+		Outbox:          dbsql.NewOutbox(tx, dialect),
+		Webhook:         dbsql.NewWebhook(tx, dialect),
+		WebhookDelivery: dbsql.NewWebhookDelivery(tx, dialect),
+	}
+	if err = fn(repos); err != nil {
+		if rberr := tx.Rollback(); rberr != nil && !errors.Is(rberr, sql.ErrTxDone) {
+			return errors.Join(err, rberr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func isBusy(err error) bool {
+	var sqliteerr sqlite3.Error
+	if !errors.As(err, &sqliteerr) {
+		return false
+	}
+	return sqliteerr.Code == sqlite3.ErrBusy || sqliteerr.Code == sqlite3.ErrLocked
+}
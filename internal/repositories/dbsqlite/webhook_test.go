@@ -0,0 +1,19 @@
+package dbsqlite
+
+import (
+	"github.com/alexsibrin/runbot-auth/internal/repositories/repotest"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestWebhook(t *testing.T) {
+	repotest.WebhookRepos(t, func(t *testing.T) (usecases.IWebhookRepo, usecases.IWebhookDeliveryRepo) {
+		db := newTestSQLite(t)
+		webhooks, err := NewWebhook(db)
+		require.NoError(t, err)
+		deliveries, err := NewWebhookDelivery(db)
+		require.NoError(t, err)
+		return webhooks, deliveries
+	})
+}
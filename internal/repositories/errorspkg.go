@@ -26,3 +26,75 @@ func (err ErrAccountNotFoundByUUID) Error() string {
 func NewErrAccountNotFoundByUUID(uuid string) error {
 	return ErrAccountNotFoundByUUID{uuid}
 }
+
+type ErrAccountAlreadyExists struct {
+	email string
+}
+
+func (err ErrAccountAlreadyExists) Error() string {
+	return fmt.Sprintf("account with email=%s already exists", err.email)
+}
+
+func NewErrAccountAlreadyExists(email string) error {
+	return ErrAccountAlreadyExists{email}
+}
+
+type ErrExportNotFoundByUUID struct {
+	uuid string
+}
+
+func (err ErrExportNotFoundByUUID) Error() string {
+	return fmt.Sprintf("export with UUID=%s is not found", err.uuid)
+}
+
+func NewErrExportNotFoundByUUID(uuid string) error {
+	return ErrExportNotFoundByUUID{uuid}
+}
+
+type ErrSessionNotFoundByUUID struct {
+	uuid string
+}
+
+func (err ErrSessionNotFoundByUUID) Error() string {
+	return fmt.Sprintf("session with UUID=%s is not found", err.uuid)
+}
+
+func NewErrSessionNotFoundByUUID(uuid string) error {
+	return ErrSessionNotFoundByUUID{uuid}
+}
+
+type ErrDomainEventNotFoundByID struct {
+	id int64
+}
+
+func (err ErrDomainEventNotFoundByID) Error() string {
+	return fmt.Sprintf("domain event with ID=%d is not found", err.id)
+}
+
+func NewErrDomainEventNotFoundByID(id int64) error {
+	return ErrDomainEventNotFoundByID{id}
+}
+
+type ErrWebhookNotFoundByUUID struct {
+	uuid string
+}
+
+func (err ErrWebhookNotFoundByUUID) Error() string {
+	return fmt.Sprintf("webhook with UUID=%s is not found", err.uuid)
+}
+
+func NewErrWebhookNotFoundByUUID(uuid string) error {
+	return ErrWebhookNotFoundByUUID{uuid}
+}
+
+type ErrWebhookDeliveryNotFoundByID struct {
+	id int64
+}
+
+func (err ErrWebhookDeliveryNotFoundByID) Error() string {
+	return fmt.Sprintf("webhook delivery with ID=%d is not found", err.id)
+}
+
+func NewErrWebhookDeliveryNotFoundByID(id int64) error {
+	return ErrWebhookDeliveryNotFoundByID{id}
+}
@@ -0,0 +1,12 @@
+package repositories
+
+type AccountExport struct {
+	UUID        string
+	AccountUUID string
+	Status      uint8
+	Archive     []byte
+	CreatedAt   int64
+	UpdatedAt   int64
+	ReadyAt     int64
+	ExpiresAt   int64
+}
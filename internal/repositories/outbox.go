@@ -0,0 +1,14 @@
+package repositories
+
+type DomainEvent struct {
+	ID          int64
+	UUID        string
+	Type        string
+	AccountUUID string
+	Payload     string
+	CreatedAt   int64
+	PublishedAt int64
+	Seq         int64
+	Attempts    int
+	LastError   string
+}
@@ -0,0 +1,521 @@
+// Package repotest contains the conformance suites every repository implementation must pass
+package repotest
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// NewAccountRepo must return an empty repository
+type NewAccountRepo func(t *testing.T) usecases.IAccountRepo
+
+func NewTestAccount(email string) *entities.Account {
+	now := time.Now().Unix()
+	return &entities.Account{
+		UUID:      uuid.NewString(),
+		Email:     email,
+		Password:  "$2a$10$hashedpassword",
+		Name:      "SomeName",
+		Status:    entities.Suspended,
+		CreatedAt: now,
+		UpdatedAt: now + 1,
+	}
+}
+
+func AccountRepo(t *testing.T, newrepo NewAccountRepo) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		testAccountRoundTrip(t, newrepo(t))
+	})
+	t.Run("NotFound", func(t *testing.T) {
+		testAccountNotFound(t, newrepo(t))
+	})
+	t.Run("GetManyByUUIDs", func(t *testing.T) {
+		testAccountGetManyByUUIDs(t, newrepo(t))
+	})
+	t.Run("Find", func(t *testing.T) {
+		testAccountFind(t, newrepo(t))
+	})
+	t.Run("FindPages", func(t *testing.T) {
+		testAccountFindPages(t, newrepo(t))
+	})
+	t.Run("EmailIsCaseInsensitive", func(t *testing.T) {
+		testAccountEmailIsCaseInsensitive(t, newrepo(t))
+	})
+	t.Run("SetAccountStatus", func(t *testing.T) {
+		testAccountSetAccountStatus(t, newrepo(t))
+	})
+	t.Run("SetPassword", func(t *testing.T) {
+		testAccountSetPassword(t, newrepo(t))
+	})
+	t.Run("StatusExpiration", func(t *testing.T) {
+		testAccountStatusExpiration(t, newrepo(t))
+	})
+	t.Run("MarkAccountDeleted", func(t *testing.T) {
+		testAccountMarkAccountDeleted(t, newrepo(t))
+	})
+	t.Run("PurgeAccount", func(t *testing.T) {
+		testAccountPurgeAccount(t, newrepo(t))
+	})
+	t.Run("ReturnedAccountIsCopy", func(t *testing.T) {
+		testAccountReturnedAccountIsCopy(t, newrepo(t))
+	})
+	t.Run("ConcurrentCreate", func(t *testing.T) {
+		testAccountConcurrentCreate(t, newrepo(t))
+	})
+}
+
+func testAccountRoundTrip(t *testing.T, repo usecases.IAccountRepo) {
+	ctx := context.Background()
+	in := NewTestAccount("bob@example.com")
+	in.StatusUntil = in.CreatedAt + 3600
+
+	created, err := repo.Create(ctx, in)
+	require.NoError(t, err)
+	assert.Equal(t, in, created)
+
+	byemail, err := repo.GetOneByEmail(ctx, in.Email)
+	require.NoError(t, err)
+	assert.Equal(t, in, byemail)
+
+	byuuid, err := repo.GetOneByUUID(ctx, in.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, in, byuuid)
+
+	exists, err := repo.IsExist(ctx, &entities.Account{Email: in.Email})
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = repo.IsExistByUUID(ctx, in.UUID)
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func testAccountNotFound(t *testing.T, repo usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	_, err := repo.GetOneByEmail(ctx, "nobody@example.com")
+	assert.ErrorAs(t, err, &repositories.ErrAccountNotFoundByEmail{})
+
+	_, err = repo.GetOneByUUID(ctx, uuid.NewString())
+	assert.ErrorAs(t, err, &repositories.ErrAccountNotFoundByUUID{})
+
+	exists, err := repo.IsExist(ctx, &entities.Account{Email: "nobody@example.com"})
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	exists, err = repo.IsExistByUUID(ctx, uuid.NewString())
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func testAccountGetManyByUUIDs(t *testing.T, repo usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	var created []*entities.Account
+	for _, email := range []string{"bob@example.com", "alice@example.com", "carol@example.com"} {
+		account, err := repo.Create(ctx, NewTestAccount(email))
+		require.NoError(t, err)
+		created = append(created, account)
+	}
+
+	accounts, err := repo.GetManyByUUIDs(ctx, []string{created[2].UUID, uuid.NewString(), created[0].UUID})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*entities.Account{created[0], created[2]}, accounts)
+
+	accounts, err = repo.GetManyByUUIDs(ctx, []string{uuid.NewString()})
+	require.NoError(t, err)
+	assert.Empty(t, accounts)
+
+	accounts, err = repo.GetManyByUUIDs(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, accounts)
+}
+
+// createSearchAccounts creates the accounts with the emails, the names and the creation times
+func createSearchAccounts(t *testing.T, repo usecases.IAccountRepo, accounts ...*entities.Account) []*entities.Account {
+	var created []*entities.Account
+	for _, in := range accounts {
+		account := NewTestAccount(in.Email)
+		account.Name = in.Name
+		account.Status = in.Status
+		account.CreatedAt = in.CreatedAt
+		account, err := repo.Create(context.Background(), account)
+		require.NoError(t, err)
+		created = append(created, account)
+	}
+	return created
+}
+
+func accountUUIDs(accounts []*entities.Account) []string {
+	uuids := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		uuids = append(uuids, account.UUID)
+	}
+	return uuids
+}
+
+func testAccountFind(t *testing.T, repo usecases.IAccountRepo) {
+	ctx := context.Background()
+	accounts := createSearchAccounts(t, repo,
+		&entities.Account{Email: "Bob@example.com", Name: "Bob Builder", Status: entities.Active, CreatedAt: 100},
+		&entities.Account{Email: "bobby@example.com", Name: "Bobby Tables", Status: entities.Suspended, CreatedAt: 200},
+		&entities.Account{Email: "alice@example.com", Name: "Alice", Status: entities.Active, CreatedAt: 300},
+		&entities.Account{Email: "b_b@example.com", Name: "100% Bob", Status: entities.Blocked, CreatedAt: 400},
+	)
+	bob, bobby, alice, bb := accounts[0], accounts[1], accounts[2], accounts[3]
+
+	testCases := []struct {
+		name     string
+		filter   *repositories.AccountFilter
+		expected []*entities.Account
+	}{
+		{
+			name:     "All by the creation time",
+			filter:   &repositories.AccountFilter{},
+			expected: []*entities.Account{bob, bobby, alice, bb},
+		},
+		{
+			name:     "All by the email descending",
+			filter:   &repositories.AccountFilter{Sort: repositories.AccountSortEmail, Descending: true},
+			expected: []*entities.Account{bobby, bob, bb, alice},
+		},
+		{
+			name:     "Statuses",
+			filter:   &repositories.AccountFilter{Statuses: []uint8{entities.Suspended, entities.Blocked}},
+			expected: []*entities.Account{bobby, bb},
+		},
+		{
+			name:     "Email prefix is case-insensitive",
+			filter:   &repositories.AccountFilter{EmailPrefix: "BOB"},
+			expected: []*entities.Account{bob, bobby},
+		},
+		{
+			name:     "Email prefix wildcards are literal",
+			filter:   &repositories.AccountFilter{EmailPrefix: "b_"},
+			expected: []*entities.Account{bb},
+		},
+		{
+			name:     "Name substring is case-insensitive",
+			filter:   &repositories.AccountFilter{NameContains: "bob"},
+			expected: []*entities.Account{bob, bobby, bb},
+		},
+		{
+			name:     "Name substring wildcards are literal",
+			filter:   &repositories.AccountFilter{NameContains: "0%"},
+			expected: []*entities.Account{bb},
+		},
+		{
+			name:     "Creation time range",
+			filter:   &repositories.AccountFilter{CreatedSince: 200, CreatedUntil: 400},
+			expected: []*entities.Account{bobby, alice},
+		},
+		{
+			name:     "Filters are combined",
+			filter:   &repositories.AccountFilter{Statuses: []uint8{entities.Active}, NameContains: "bob", CreatedSince: 50},
+			expected: []*entities.Account{bob},
+		},
+		{
+			name:   "Nothing matches",
+			filter: &repositories.AccountFilter{EmailPrefix: "carol"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter := *tc.filter
+			filter.Limit = 10
+			found, err := repo.Find(ctx, &filter)
+			require.NoError(t, err)
+			assert.Equal(t, accountUUIDs(tc.expected), accountUUIDs(found))
+		})
+	}
+
+	found, err := repo.Find(ctx, &repositories.AccountFilter{Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []*entities.Account{bob}, found)
+}
+
+// testAccountFindPages lists the accounts with the same sort keys page by page in both directions
+func testAccountFindPages(t *testing.T, repo usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	var in []*entities.Account
+	for i, email := range []string{"e@example.com", "d@example.com", "c@example.com", "b@example.com", "a@example.com"} {
+		in = append(in, &entities.Account{Email: email, CreatedAt: int64(100 + i/2)})
+	}
+	createSearchAccounts(t, repo, in...)
+
+	for _, sortkey := range []string{repositories.AccountSortCreatedAt, repositories.AccountSortEmail} {
+		for _, descending := range []bool{false, true} {
+			all, err := repo.Find(ctx, &repositories.AccountFilter{Sort: sortkey, Descending: descending, Limit: 10})
+			require.NoError(t, err)
+			require.Len(t, all, 5)
+
+			var paged []*entities.Account
+			filter := &repositories.AccountFilter{Sort: sortkey, Descending: descending, Limit: 2}
+			for {
+				page, err := repo.Find(ctx, filter)
+				require.NoError(t, err)
+				paged = append(paged, page...)
+				if len(page) < filter.Limit {
+					break
+				}
+				last := page[len(page)-1]
+				filter.After = &repositories.AccountKey{CreatedAt: last.CreatedAt, Email: strings.ToLower(last.Email), UUID: last.UUID}
+			}
+			assert.Equal(t, accountUUIDs(all), accountUUIDs(paged), "sort %s, descending %t", sortkey, descending)
+		}
+	}
+}
+
+func testAccountEmailIsCaseInsensitive(t *testing.T, repo usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	_, err := repo.Create(ctx, NewTestAccount("bob@example.com"))
+	require.NoError(t, err)
+
+	_, err = repo.GetOneByEmail(ctx, "Bob@Example.COM")
+	assert.NoError(t, err)
+
+	exists, err := repo.IsExist(ctx, &entities.Account{Email: "BOB@example.com"})
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	_, err = repo.Create(ctx, NewTestAccount("Bob@Example.com"))
+	assert.ErrorAs(t, err, &repositories.ErrAccountAlreadyExists{})
+}
+
+func testAccountSetAccountStatus(t *testing.T, repo usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	in := NewTestAccount("bob@example.com")
+	in.Status = entities.Active
+	_, err := repo.Create(ctx, in)
+	require.NoError(t, err)
+
+	err = repo.SetAccountStatus(ctx, in.UUID, entities.Blocked, 0)
+	require.NoError(t, err)
+
+	account, err := repo.GetOneByEmail(ctx, in.Email)
+	require.NoError(t, err)
+	assert.Equal(t, entities.Blocked, account.Status)
+	assert.False(t, account.IsActive())
+	assert.GreaterOrEqual(t, account.UpdatedAt, in.CreatedAt)
+
+	err = repo.SetAccountStatus(ctx, uuid.NewString(), entities.Blocked, 0)
+	assert.ErrorAs(t, err, &repositories.ErrAccountNotFoundByUUID{})
+}
+
+func testAccountSetPassword(t *testing.T, repo usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	in := NewTestAccount("bob@example.com")
+	_, err := repo.Create(ctx, in)
+	require.NoError(t, err)
+
+	updatedat := in.CreatedAt + 10
+	require.NoError(t, repo.SetPassword(ctx, in.UUID, "newhash", updatedat))
+
+	account, err := repo.GetOneByUUID(ctx, in.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, "newhash", account.Password)
+	assert.Equal(t, updatedat, account.UpdatedAt)
+
+	require.NoError(t, repo.SetPassword(ctx, in.UUID, "", updatedat))
+	account, err = repo.GetOneByUUID(ctx, in.UUID)
+	require.NoError(t, err)
+	assert.True(t, account.IsPasswordResetRequired())
+
+	err = repo.SetPassword(ctx, uuid.NewString(), "newhash", updatedat)
+	assert.ErrorAs(t, err, &repositories.ErrAccountNotFoundByUUID{})
+}
+
+func testAccountStatusExpiration(t *testing.T, repo usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	now := time.Now().Unix()
+	var uuids []string
+	for i, email := range []string{"bob@example.com", "alice@example.com", "eve@example.com"} {
+		in := NewTestAccount(email)
+		_, err := repo.Create(ctx, in)
+		require.NoError(t, err)
+		require.NoError(t, repo.SetAccountStatus(ctx, in.UUID, entities.Suspended, now+int64(i)))
+		uuids = append(uuids, in.UUID)
+	}
+
+	account, err := repo.GetOneByUUID(ctx, uuids[0])
+	require.NoError(t, err)
+	assert.Equal(t, now, account.StatusUntil)
+	assert.True(t, account.IsStatusExpired(now))
+	assert.False(t, account.IsStatusExpired(now-1))
+
+	expired, err := repo.GetStatusExpiredBefore(ctx, now-1, 10)
+	require.NoError(t, err)
+	assert.Empty(t, expired)
+
+	// the earliest expirations go first
+	expired, err = repo.GetStatusExpiredBefore(ctx, now+10, 2)
+	require.NoError(t, err)
+	require.Len(t, expired, 2)
+	assert.Equal(t, uuids[0], expired[0].UUID)
+	assert.Equal(t, uuids[1], expired[1].UUID)
+
+	// permanent and deleted accounts don't expire
+	require.NoError(t, repo.SetAccountStatus(ctx, uuids[0], entities.Blocked, 0))
+	require.NoError(t, repo.MarkAccountDeleted(ctx, uuids[1], now))
+
+	account, err = repo.GetOneByUUID(ctx, uuids[1])
+	require.NoError(t, err)
+	assert.Zero(t, account.StatusUntil)
+
+	expired, err = repo.GetStatusExpiredBefore(ctx, now+10, 10)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, uuids[2], expired[0].UUID)
+}
+
+func testAccountMarkAccountDeleted(t *testing.T, repo usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	in := NewTestAccount("bob@example.com")
+	in.Status = entities.Active
+	_, err := repo.Create(ctx, in)
+	require.NoError(t, err)
+
+	deletedat := in.CreatedAt + 10
+	err = repo.MarkAccountDeleted(ctx, in.UUID, deletedat)
+	require.NoError(t, err)
+
+	account, err := repo.GetOneByUUID(ctx, in.UUID)
+	require.NoError(t, err)
+	assert.True(t, account.IsDeleted())
+	assert.Equal(t, deletedat, account.DeletedAt)
+
+	// the account waits for the purging once the grace period is over
+	deleted, err := repo.GetDeletedBefore(ctx, deletedat-1, 10)
+	require.NoError(t, err)
+	assert.Empty(t, deleted)
+
+	deleted, err = repo.GetDeletedBefore(ctx, deletedat, 10)
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	assert.Equal(t, in.UUID, deleted[0].UUID)
+
+	// restoring the account cancels its purging
+	err = repo.SetAccountStatus(ctx, in.UUID, entities.Active, 0)
+	require.NoError(t, err)
+
+	account, err = repo.GetOneByUUID(ctx, in.UUID)
+	require.NoError(t, err)
+	assert.True(t, account.IsActive())
+	assert.Zero(t, account.DeletedAt)
+
+	deleted, err = repo.GetDeletedBefore(ctx, deletedat, 10)
+	require.NoError(t, err)
+	assert.Empty(t, deleted)
+
+	err = repo.MarkAccountDeleted(ctx, uuid.NewString(), deletedat)
+	assert.ErrorAs(t, err, &repositories.ErrAccountNotFoundByUUID{})
+}
+
+func testAccountPurgeAccount(t *testing.T, repo usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	var uuids []string
+	for i, email := range []string{"bob@example.com", "alice@example.com", "eve@example.com"} {
+		in := NewTestAccount(email)
+		_, err := repo.Create(ctx, in)
+		require.NoError(t, err)
+		require.NoError(t, repo.MarkAccountDeleted(ctx, in.UUID, in.CreatedAt+int64(i)))
+		uuids = append(uuids, in.UUID)
+	}
+
+	// the oldest deletions are purged first
+	deleted, err := repo.GetDeletedBefore(ctx, time.Now().Add(time.Hour).Unix(), 2)
+	require.NoError(t, err)
+	require.Len(t, deleted, 2)
+	assert.Equal(t, uuids[0], deleted[0].UUID)
+	assert.Equal(t, uuids[1], deleted[1].UUID)
+
+	purgedat := time.Now().Unix()
+	err = repo.PurgeAccount(ctx, uuids[0], purgedat)
+	require.NoError(t, err)
+
+	account, err := repo.GetOneByUUID(ctx, uuids[0])
+	require.NoError(t, err)
+	assert.True(t, account.IsPurged())
+	assert.Equal(t, purgedat, account.PurgedAt)
+	assert.Equal(t, repositories.AnonymizedEmail(uuids[0]), account.Email)
+	assert.Empty(t, account.Name)
+	assert.Empty(t, account.Password)
+
+	// the email is released for a new sign up
+	exists, err := repo.IsExist(ctx, &entities.Account{Email: "bob@example.com"})
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	deleted, err = repo.GetDeletedBefore(ctx, time.Now().Add(time.Hour).Unix(), 10)
+	require.NoError(t, err)
+	assert.Len(t, deleted, 2)
+
+	// purged accounts can be neither purged again nor restored
+	err = repo.PurgeAccount(ctx, uuids[0], purgedat)
+	assert.ErrorAs(t, err, &repositories.ErrAccountNotFoundByUUID{})
+
+	err = repo.SetAccountStatus(ctx, uuids[0], entities.Active, 0)
+	assert.ErrorAs(t, err, &repositories.ErrAccountNotFoundByUUID{})
+}
+
+func testAccountReturnedAccountIsCopy(t *testing.T, repo usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	in := NewTestAccount("bob@example.com")
+	created, err := repo.Create(ctx, in)
+	require.NoError(t, err)
+
+	in.Name = "ChangedName"
+	created.Name = "ChangedName"
+
+	account, err := repo.GetOneByUUID(ctx, in.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, "SomeName", account.Name)
+}
+
+// testAccountConcurrentCreate checks only one of the concurrent creates with the same email succeeds
+func testAccountConcurrentCreate(t *testing.T, repo usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	const workers = 8
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.Create(ctx, NewTestAccount("bob@example.com"))
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	var succeeded int
+	for err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.ErrorAs(t, err, &repositories.ErrAccountAlreadyExists{})
+	}
+	assert.Equal(t, 1, succeeded)
+}
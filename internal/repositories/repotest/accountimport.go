@@ -0,0 +1,76 @@
+package repotest
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// NewAccountImportRepo must return an empty repository with the account repository it imports into
+type NewAccountImportRepo func(t *testing.T) (usecases.IAccountImportRepo, usecases.IAccountRepo)
+
+func AccountImportRepo(t *testing.T, newrepos NewAccountImportRepo) {
+	t.Run("Import", func(t *testing.T) {
+		importrepo, accountrepo := newrepos(t)
+		testAccountImport(t, importrepo, accountrepo)
+	})
+	t.Run("ImportSkipsExisting", func(t *testing.T) {
+		importrepo, accountrepo := newrepos(t)
+		testAccountImportSkipsExisting(t, importrepo, accountrepo)
+	})
+}
+
+func testAccountImport(t *testing.T, importrepo usecases.IAccountImportRepo, accountrepo usecases.IAccountRepo) {
+	ctx := context.Background()
+	bob, alice := NewTestAccount("bob@example.com"), NewTestAccount("alice@example.com")
+	alice.Password = "$argon2id$v=19$m=65536,t=3,p=4$c29tZXNhbHQ$aGFzaA"
+	alice.StatusUntil = alice.CreatedAt + 3600
+
+	skipped, err := importrepo.Import(ctx, []*entities.Account{bob, alice})
+	require.NoError(t, err)
+	assert.Empty(t, skipped)
+
+	for _, in := range []*entities.Account{bob, alice} {
+		imported, err := accountrepo.GetOneByUUID(ctx, in.UUID)
+		require.NoError(t, err)
+		assert.Equal(t, in, imported)
+	}
+}
+
+func testAccountImportSkipsExisting(t *testing.T, importrepo usecases.IAccountImportRepo, accountrepo usecases.IAccountRepo) {
+	ctx := context.Background()
+	existing := NewTestAccount("bob@example.com")
+	_, err := accountrepo.Create(ctx, existing)
+	require.NoError(t, err)
+
+	sameemail := NewTestAccount("Bob@Example.com")
+	sameuuid := NewTestAccount("robert@example.com")
+	sameuuid.UUID = existing.UUID
+	fresh := NewTestAccount("alice@example.com")
+	// the second account with the same email or UUID in the batch is skipped too
+	duplicate := NewTestAccount("ALICE@example.com")
+	other := NewTestAccount("carol@example.com")
+	repeated := NewTestAccount("dave@example.com")
+	repeated.UUID = other.UUID
+
+	skipped, err := importrepo.Import(ctx, []*entities.Account{sameemail, sameuuid, fresh, duplicate, other, repeated})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{sameemail.UUID, sameuuid.UUID, duplicate.UUID, repeated.UUID}, skipped)
+
+	imported, err := accountrepo.GetOneByUUID(ctx, other.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, other, imported)
+	_, err = accountrepo.GetOneByEmail(ctx, repeated.Email)
+	assert.Error(t, err)
+
+	imported, err = accountrepo.GetOneByEmail(ctx, fresh.Email)
+	require.NoError(t, err)
+	assert.Equal(t, fresh, imported)
+
+	kept, err := accountrepo.GetOneByUUID(ctx, existing.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, existing, kept)
+}
@@ -0,0 +1,117 @@
+package repotest
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// NewAuditLogRepo must return an empty repository
+type NewAuditLogRepo func(t *testing.T) usecases.IAuditLogRepo
+
+func newTestAuditRecord(targetuuid, prevhash string) *entities.AuditRecord {
+	record := &entities.AuditRecord{
+		Actor:      "admin@runbot.io",
+		Action:     entities.AuditActionStatusChanged,
+		TargetUUID: targetuuid,
+		Before:     `{"Status":0}`,
+		After:      `{"Status":2,"Until":1700000000}`,
+		Method:     "/Account/SetStatus",
+		IP:         "10.0.0.1",
+		UserAgent:  "grpc-go/1.61.0",
+		CreatedAt:  time.Now().Unix(),
+		PrevHash:   prevhash,
+	}
+	record.Hash = record.ComputeHash()
+	return record
+}
+
+func AuditLogRepo(t *testing.T, newrepo NewAuditLogRepo) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		testAuditLogRoundTrip(t, newrepo(t))
+	})
+	t.Run("List", func(t *testing.T) {
+		testAuditLogList(t, newrepo(t))
+	})
+	t.Run("ForkIsRejected", func(t *testing.T) {
+		testAuditLogForkIsRejected(t, newrepo(t))
+	})
+}
+
+func testAuditLogRoundTrip(t *testing.T, repo usecases.IAuditLogRepo) {
+	ctx := context.Background()
+
+	last, err := repo.Last(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, last)
+
+	in := newTestAuditRecord(uuid.NewString(), "")
+	added, err := repo.Add(ctx, in)
+	require.NoError(t, err)
+	assert.NotZero(t, added.ID)
+
+	in.ID = added.ID
+	assert.Equal(t, in, added)
+
+	last, err = repo.Last(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, added, last)
+}
+
+func testAuditLogList(t *testing.T, repo usecases.IAuditLogRepo) {
+	ctx := context.Background()
+
+	var (
+		ids      []int64
+		prevhash string
+	)
+	for i := 0; i < 5; i++ {
+		added, err := repo.Add(ctx, newTestAuditRecord(uuid.NewString(), prevhash))
+		require.NoError(t, err)
+		ids = append(ids, added.ID)
+		prevhash = added.Hash
+	}
+
+	var (
+		found   []int64
+		afterid int64
+	)
+	for {
+		records, err := repo.List(ctx, afterid, 2)
+		require.NoError(t, err)
+		for _, record := range records {
+			found = append(found, record.ID)
+		}
+		if len(records) < 2 {
+			break
+		}
+		afterid = records[len(records)-1].ID
+	}
+	assert.Equal(t, ids, found)
+
+	last, err := repo.Last(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ids[len(ids)-1], last.ID)
+}
+
+// testAuditLogForkIsRejected adds two records following the same one, the chain must stay linear
+func testAuditLogForkIsRejected(t *testing.T, repo usecases.IAuditLogRepo) {
+	ctx := context.Background()
+
+	first, err := repo.Add(ctx, newTestAuditRecord(uuid.NewString(), ""))
+	require.NoError(t, err)
+	_, err = repo.Add(ctx, newTestAuditRecord(uuid.NewString(), first.Hash))
+	require.NoError(t, err)
+
+	_, err = repo.Add(ctx, newTestAuditRecord(uuid.NewString(), first.Hash))
+	assert.Error(t, err)
+
+	records, err := repo.List(ctx, 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+}
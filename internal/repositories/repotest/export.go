@@ -0,0 +1,229 @@
+package repotest
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+)
+
+// NewExportRepo must return the export and the account repositories of the same empty storage
+type NewExportRepo func(t *testing.T) (usecases.IExportRepo, usecases.IAccountRepo)
+
+// newTestExport creates the owner account, exports reference it in the SQL storages
+func newTestExport(t *testing.T, accounts usecases.IAccountRepo, createdat int64) *entities.AccountExport {
+	account, err := accounts.Create(context.Background(), NewTestAccount(uuid.NewString()+"@example.com"))
+	require.NoError(t, err)
+
+	return &entities.AccountExport{
+		UUID:        uuid.NewString(),
+		AccountUUID: account.UUID,
+		Status:      entities.ExportPending,
+		CreatedAt:   createdat,
+		UpdatedAt:   createdat,
+	}
+}
+
+func ExportRepo(t *testing.T, newrepo NewExportRepo) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		repo, accounts := newrepo(t)
+		testExportRoundTrip(t, repo, accounts)
+	})
+	t.Run("GetUnfinishedByAccount", func(t *testing.T) {
+		repo, accounts := newrepo(t)
+		testExportGetUnfinishedByAccount(t, repo, accounts)
+	})
+	t.Run("ClaimPending", func(t *testing.T) {
+		repo, accounts := newrepo(t)
+		testExportClaimPending(t, repo, accounts)
+	})
+	t.Run("ConcurrentClaimPending", func(t *testing.T) {
+		repo, accounts := newrepo(t)
+		testExportConcurrentClaimPending(t, repo, accounts)
+	})
+	t.Run("DeleteExpired", func(t *testing.T) {
+		repo, accounts := newrepo(t)
+		testExportDeleteExpired(t, repo, accounts)
+	})
+	t.Run("DeleteByAccount", func(t *testing.T) {
+		repo, accounts := newrepo(t)
+		testExportDeleteByAccount(t, repo, accounts)
+	})
+}
+
+func testExportRoundTrip(t *testing.T, repo usecases.IExportRepo, accounts usecases.IAccountRepo) {
+	ctx := context.Background()
+	in := newTestExport(t, accounts, 100)
+
+	require.NoError(t, repo.Create(ctx, in))
+
+	export, err := repo.GetOneByUUID(ctx, in.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, in, export)
+
+	archive := []byte("PK\x03\x04archive")
+	require.NoError(t, repo.SetReady(ctx, in.UUID, archive, 200, 300))
+
+	export, err = repo.GetOneByUUID(ctx, in.UUID)
+	require.NoError(t, err)
+	assert.True(t, export.IsReady())
+	assert.Equal(t, archive, export.Archive)
+	assert.Equal(t, int64(200), export.ReadyAt)
+	assert.Equal(t, int64(300), export.ExpiresAt)
+
+	_, err = repo.GetOneByUUID(ctx, uuid.NewString())
+	assert.ErrorAs(t, err, &repositories.ErrExportNotFoundByUUID{})
+
+	err = repo.SetReady(ctx, uuid.NewString(), archive, 200, 300)
+	assert.ErrorAs(t, err, &repositories.ErrExportNotFoundByUUID{})
+
+	err = repo.SetFailed(ctx, uuid.NewString(), 200, 300)
+	assert.ErrorAs(t, err, &repositories.ErrExportNotFoundByUUID{})
+}
+
+func testExportGetUnfinishedByAccount(t *testing.T, repo usecases.IExportRepo, accounts usecases.IAccountRepo) {
+	ctx := context.Background()
+	in := newTestExport(t, accounts, 100)
+
+	export, err := repo.GetUnfinishedByAccount(ctx, in.AccountUUID)
+	require.NoError(t, err)
+	assert.Nil(t, export)
+
+	require.NoError(t, repo.Create(ctx, in))
+
+	export, err = repo.GetUnfinishedByAccount(ctx, in.AccountUUID)
+	require.NoError(t, err)
+	require.NotNil(t, export)
+	assert.Equal(t, in.UUID, export.UUID)
+
+	require.NoError(t, repo.SetFailed(ctx, in.UUID, 200, 300))
+
+	export, err = repo.GetUnfinishedByAccount(ctx, in.AccountUUID)
+	require.NoError(t, err)
+	assert.Nil(t, export)
+}
+
+func testExportClaimPending(t *testing.T, repo usecases.IExportRepo, accounts usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	older := newTestExport(t, accounts, 100)
+	newer := newTestExport(t, accounts, 200)
+	require.NoError(t, repo.Create(ctx, newer))
+	require.NoError(t, repo.Create(ctx, older))
+
+	// the oldest export goes first
+	claimed, err := repo.ClaimPending(ctx, 1000, 0)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, older.UUID, claimed.UUID)
+	assert.Equal(t, entities.ExportProcessing, claimed.Status)
+	assert.Equal(t, int64(1000), claimed.UpdatedAt)
+
+	claimed, err = repo.ClaimPending(ctx, 1000, 0)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, newer.UUID, claimed.UUID)
+
+	claimed, err = repo.ClaimPending(ctx, 1000, 0)
+	require.NoError(t, err)
+	assert.Nil(t, claimed)
+
+	// the export of a crashed worker is claimed again once it's stale
+	claimed, err = repo.ClaimPending(ctx, 2000, 1000)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, older.UUID, claimed.UUID)
+	assert.Equal(t, int64(2000), claimed.UpdatedAt)
+}
+
+// testExportConcurrentClaimPending checks an export is claimed by a single worker
+func testExportConcurrentClaimPending(t *testing.T, repo usecases.IExportRepo, accounts usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	const exports = 4
+	for i := 0; i < exports; i++ {
+		require.NoError(t, repo.Create(ctx, newTestExport(t, accounts, int64(100+i))))
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		claimed = make(map[string]int)
+	)
+	for i := 0; i < exports*2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			export, err := repo.ClaimPending(ctx, 1000, 0)
+			assert.NoError(t, err)
+			if export == nil {
+				return
+			}
+			mu.Lock()
+			claimed[export.UUID]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, claimed, exports)
+	for uuid, times := range claimed {
+		assert.Equal(t, 1, times, uuid)
+	}
+}
+
+func testExportDeleteExpired(t *testing.T, repo usecases.IExportRepo, accounts usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	ready := newTestExport(t, accounts, 100)
+	failed := newTestExport(t, accounts, 100)
+	pending := newTestExport(t, accounts, 100)
+	for _, export := range []*entities.AccountExport{ready, failed, pending} {
+		require.NoError(t, repo.Create(ctx, export))
+	}
+	require.NoError(t, repo.SetReady(ctx, ready.UUID, []byte("archive"), 200, 300))
+	require.NoError(t, repo.SetFailed(ctx, failed.UUID, 200, 400))
+
+	deleted, err := repo.DeleteExpired(ctx, 299)
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+
+	deleted, err = repo.DeleteExpired(ctx, 400)
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	_, err = repo.GetOneByUUID(ctx, ready.UUID)
+	assert.ErrorAs(t, err, &repositories.ErrExportNotFoundByUUID{})
+
+	_, err = repo.GetOneByUUID(ctx, pending.UUID)
+	assert.NoError(t, err)
+}
+
+func testExportDeleteByAccount(t *testing.T, repo usecases.IExportRepo, accounts usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	ready := newTestExport(t, accounts, 100)
+	pending := newTestExport(t, accounts, 200)
+	pending.AccountUUID = ready.AccountUUID
+	kept := newTestExport(t, accounts, 100)
+	for _, export := range []*entities.AccountExport{ready, pending, kept} {
+		require.NoError(t, repo.Create(ctx, export))
+	}
+	require.NoError(t, repo.SetReady(ctx, ready.UUID, []byte("archive"), 200, 300))
+
+	require.NoError(t, repo.DeleteByAccount(ctx, ready.AccountUUID))
+
+	_, err := repo.GetOneByUUID(ctx, ready.UUID)
+	assert.ErrorAs(t, err, &repositories.ErrExportNotFoundByUUID{})
+
+	_, err = repo.GetOneByUUID(ctx, pending.UUID)
+	assert.ErrorAs(t, err, &repositories.ErrExportNotFoundByUUID{})
+
+	_, err = repo.GetOneByUUID(ctx, kept.UUID)
+	assert.NoError(t, err)
+}
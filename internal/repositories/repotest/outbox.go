@@ -0,0 +1,237 @@
+package repotest
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// NewOutboxRepo must return an empty repository
+type NewOutboxRepo func(t *testing.T) usecases.IOutboxRepo
+
+func newTestDomainEvent(accountuuid, eventtype string) *entities.DomainEvent {
+	return &entities.DomainEvent{
+		UUID:        uuid.NewString(),
+		Type:        eventtype,
+		AccountUUID: accountuuid,
+		Payload:     `{"Email":"bob@example.com"}`,
+		CreatedAt:   time.Now().Unix(),
+	}
+}
+
+func OutboxRepo(t *testing.T, newrepo NewOutboxRepo) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		testOutboxRoundTrip(t, newrepo(t))
+	})
+	t.Run("GetUnpublished", func(t *testing.T) {
+		testOutboxGetUnpublished(t, newrepo(t))
+	})
+	t.Run("GetPublishedAfter", func(t *testing.T) {
+		testOutboxGetPublishedAfter(t, newrepo(t))
+	})
+	t.Run("MarkFailed", func(t *testing.T) {
+		testOutboxMarkFailed(t, newrepo(t))
+	})
+	t.Run("DeletePublishedBefore", func(t *testing.T) {
+		testOutboxDeletePublishedBefore(t, newrepo(t))
+	})
+	t.Run("GetByAccountAndSetPayload", func(t *testing.T) {
+		testOutboxGetByAccountAndSetPayload(t, newrepo(t))
+	})
+	t.Run("NotFound", func(t *testing.T) {
+		testOutboxNotFound(t, newrepo(t))
+	})
+}
+
+func testOutboxRoundTrip(t *testing.T, repo usecases.IOutboxRepo) {
+	ctx := context.Background()
+
+	in := newTestDomainEvent(uuid.NewString(), entities.DomainEventAccountCreated)
+	added, err := repo.Add(ctx, in)
+	require.NoError(t, err)
+	assert.NotZero(t, added.ID)
+
+	in.ID = added.ID
+	assert.Equal(t, in, added)
+
+	events, err := repo.GetUnpublished(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []*entities.DomainEvent{added}, events)
+}
+
+func testOutboxGetUnpublished(t *testing.T, repo usecases.IOutboxRepo) {
+	ctx := context.Background()
+	accountuuid := uuid.NewString()
+
+	var ids []int64
+	for _, eventtype := range []string{
+		entities.DomainEventAccountCreated,
+		entities.DomainEventAccountStatusChanged,
+		entities.DomainEventPasswordChanged,
+		entities.DomainEventAccountDeleted,
+	} {
+		added, err := repo.Add(ctx, newTestDomainEvent(accountuuid, eventtype))
+		require.NoError(t, err)
+		ids = append(ids, added.ID)
+	}
+
+	require.NoError(t, repo.MarkPublished(ctx, ids[0], time.Now().Unix()))
+
+	events, err := repo.GetUnpublished(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, ids[1], events[0].ID)
+	assert.Equal(t, entities.DomainEventAccountStatusChanged, events[0].Type)
+	assert.Equal(t, ids[2], events[1].ID)
+
+	events, err = repo.GetUnpublished(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, events, 3)
+}
+
+func testOutboxGetPublishedAfter(t *testing.T, repo usecases.IOutboxRepo) {
+	ctx := context.Background()
+
+	first, last, err := repo.GetSeqRange(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, first)
+	assert.Zero(t, last)
+
+	var ids []int64
+	for i := 0; i < 4; i++ {
+		added, err := repo.Add(ctx, newTestDomainEvent(uuid.NewString(), entities.DomainEventAccountStatusChanged))
+		require.NoError(t, err)
+		assert.Zero(t, added.Seq)
+		ids = append(ids, added.ID)
+	}
+	// the seq follow the publish order, not the IDs, and the unpublished events aren't returned
+	require.NoError(t, repo.MarkPublished(ctx, ids[2], time.Now().Unix()))
+	require.NoError(t, repo.MarkPublished(ctx, ids[0], time.Now().Unix()))
+	require.NoError(t, repo.MarkPublished(ctx, ids[3], time.Now().Unix()))
+	// a republished event keeps its seq
+	require.NoError(t, repo.MarkPublished(ctx, ids[2], time.Now().Unix()))
+
+	events, err := repo.GetPublishedAfter(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, []int64{ids[2], ids[0], ids[3]}, []int64{events[0].ID, events[1].ID, events[2].ID})
+	assert.Equal(t, events[0].Seq+1, events[1].Seq)
+	assert.Equal(t, events[1].Seq+1, events[2].Seq)
+	assert.NotZero(t, events[0].PublishedAt)
+
+	page, err := repo.GetPublishedAfter(ctx, events[0].Seq, 1)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, ids[0], page[0].ID)
+
+	first, last, err = repo.GetSeqRange(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, events[0].Seq, first)
+	assert.Equal(t, events[2].Seq, last)
+
+	// the seq aren't reused after the deletion
+	_, err = repo.DeletePublishedBefore(ctx, time.Now().Unix())
+	require.NoError(t, err)
+	first, last, err = repo.GetSeqRange(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, first)
+	assert.Zero(t, last)
+
+	require.NoError(t, repo.MarkPublished(ctx, ids[1], time.Now().Unix()))
+	events, err = repo.GetPublishedAfter(ctx, events[2].Seq, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, ids[1], events[0].ID)
+}
+
+func testOutboxMarkFailed(t *testing.T, repo usecases.IOutboxRepo) {
+	ctx := context.Background()
+
+	added, err := repo.Add(ctx, newTestDomainEvent(uuid.NewString(), entities.DomainEventAccountCreated))
+	require.NoError(t, err)
+
+	require.NoError(t, repo.MarkFailed(ctx, added.ID, "broker is unavailable"))
+	require.NoError(t, repo.MarkFailed(ctx, added.ID, "timeout"))
+
+	events, err := repo.GetUnpublished(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, 2, events[0].Attempts)
+	assert.Equal(t, "timeout", events[0].LastError)
+
+	require.NoError(t, repo.MarkPublished(ctx, added.ID, time.Now().Unix()))
+
+	events, err = repo.GetUnpublished(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func testOutboxDeletePublishedBefore(t *testing.T, repo usecases.IOutboxRepo) {
+	ctx := context.Background()
+	now := time.Now().Unix()
+
+	old, err := repo.Add(ctx, newTestDomainEvent(uuid.NewString(), entities.DomainEventAccountCreated))
+	require.NoError(t, err)
+	recent, err := repo.Add(ctx, newTestDomainEvent(uuid.NewString(), entities.DomainEventAccountCreated))
+	require.NoError(t, err)
+	_, err = repo.Add(ctx, newTestDomainEvent(uuid.NewString(), entities.DomainEventAccountCreated))
+	require.NoError(t, err)
+
+	require.NoError(t, repo.MarkPublished(ctx, old.ID, now-3600))
+	require.NoError(t, repo.MarkPublished(ctx, recent.ID, now))
+
+	deleted, err := repo.DeletePublishedBefore(ctx, now-60)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	// the unpublished events are never deleted
+	deleted, err = repo.DeletePublishedBefore(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	events, err := repo.GetUnpublished(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+}
+
+func testOutboxGetByAccountAndSetPayload(t *testing.T, repo usecases.IOutboxRepo) {
+	ctx := context.Background()
+	accountuuid := uuid.NewString()
+
+	created, err := repo.Add(ctx, newTestDomainEvent(accountuuid, entities.DomainEventAccountCreated))
+	require.NoError(t, err)
+	_, err = repo.Add(ctx, newTestDomainEvent(accountuuid, entities.DomainEventAccountDeleted))
+	require.NoError(t, err)
+	_, err = repo.Add(ctx, newTestDomainEvent(uuid.NewString(), entities.DomainEventAccountCreated))
+	require.NoError(t, err)
+
+	// the payload of a published event is replaced too, its seq is kept
+	require.NoError(t, repo.MarkPublished(ctx, created.ID, time.Now().Unix()))
+	require.NoError(t, repo.SetPayload(ctx, created.ID, `{"Email":""}`))
+
+	events, err := repo.GetByAccount(ctx, accountuuid, entities.DomainEventAccountCreated)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, created.UUID, events[0].UUID)
+	assert.Equal(t, `{"Email":""}`, events[0].Payload)
+	assert.NotZero(t, events[0].Seq)
+}
+
+func testOutboxNotFound(t *testing.T, repo usecases.IOutboxRepo) {
+	ctx := context.Background()
+
+	err := repo.MarkPublished(ctx, 42, time.Now().Unix())
+	assert.ErrorAs(t, err, &repositories.ErrDomainEventNotFoundByID{})
+
+	err = repo.MarkFailed(ctx, 42, "timeout")
+	assert.ErrorAs(t, err, &repositories.ErrDomainEventNotFoundByID{})
+
+	err = repo.SetPayload(ctx, 42, "{}")
+	assert.ErrorAs(t, err, &repositories.ErrDomainEventNotFoundByID{})
+}
@@ -0,0 +1,200 @@
+package repotest
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// NewSecurityEventRepo must return an empty repository and the account repository of the same storage,
+// the events are added for the existing accounts only
+type NewSecurityEventRepo func(t *testing.T) (usecases.ISecurityEventRepo, usecases.IAccountRepo)
+
+func newTestSecurityEvent(accountuuid string, eventtype uint8) *entities.SecurityEvent {
+	return &entities.SecurityEvent{
+		AccountUUID: accountuuid,
+		Type:        eventtype,
+		IP:          "10.0.0.1",
+		UserAgent:   "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+		Details:     "Firefox on Linux",
+		CreatedAt:   time.Now().Unix(),
+	}
+}
+
+func SecurityEventRepo(t *testing.T, newrepo NewSecurityEventRepo) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		repo, accounts := newrepo(t)
+		testSecurityEventRoundTrip(t, repo, accounts)
+	})
+	t.Run("Find", func(t *testing.T) {
+		repo, accounts := newrepo(t)
+		testSecurityEventFind(t, repo, accounts)
+	})
+	t.Run("Paging", func(t *testing.T) {
+		repo, accounts := newrepo(t)
+		testSecurityEventPaging(t, repo, accounts)
+	})
+	t.Run("DeleteByAccount", func(t *testing.T) {
+		repo, accounts := newrepo(t)
+		testSecurityEventDeleteByAccount(t, repo, accounts)
+	})
+}
+
+func testSecurityEventRoundTrip(t *testing.T, repo usecases.ISecurityEventRepo, accounts usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	account, err := accounts.Create(ctx, NewTestAccount("bob@example.com"))
+	require.NoError(t, err)
+
+	in := newTestSecurityEvent(account.UUID, entities.SecurityEventSignIn)
+	added, err := repo.Add(ctx, in)
+	require.NoError(t, err)
+	assert.NotZero(t, added.ID)
+
+	in.ID = added.ID
+	assert.Equal(t, in, added)
+
+	events, err := repo.Find(ctx, &repositories.SecurityEventFilter{AccountUUID: account.UUID, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []*entities.SecurityEvent{added}, events)
+}
+
+func testSecurityEventFind(t *testing.T, repo usecases.ISecurityEventRepo, accounts usecases.IAccountRepo) {
+	ctx := context.Background()
+	now := time.Now().Unix()
+
+	bob, err := accounts.Create(ctx, NewTestAccount("bob@example.com"))
+	require.NoError(t, err)
+	alice, err := accounts.Create(ctx, NewTestAccount("alice@example.com"))
+	require.NoError(t, err)
+
+	old := newTestSecurityEvent(bob.UUID, entities.SecurityEventSignIn)
+	old.CreatedAt = now - 100
+	failed := newTestSecurityEvent(bob.UUID, entities.SecurityEventSignInFailed)
+	failed.IP = "10.0.0.2"
+	failed.CreatedAt = now - 50
+	refresh := newTestSecurityEvent(bob.UUID, entities.SecurityEventRefresh)
+	refresh.CreatedAt = now
+	other := newTestSecurityEvent(alice.UUID, entities.SecurityEventSignInFailed)
+	other.CreatedAt = now
+
+	ids := make(map[*entities.SecurityEvent]int64)
+	for _, e := range []*entities.SecurityEvent{old, failed, refresh, other} {
+		added, err := repo.Add(ctx, e)
+		require.NoError(t, err)
+		ids[e] = added.ID
+	}
+
+	testCases := []struct {
+		name     string
+		filter   repositories.SecurityEventFilter
+		expected []int64
+	}{
+		{
+			name:     "By account",
+			filter:   repositories.SecurityEventFilter{AccountUUID: bob.UUID},
+			expected: []int64{ids[refresh], ids[failed], ids[old]},
+		},
+		{
+			name:     "By types",
+			filter:   repositories.SecurityEventFilter{Types: []uint8{entities.SecurityEventSignIn, entities.SecurityEventSignInFailed}},
+			expected: []int64{ids[other], ids[failed], ids[old]},
+		},
+		{
+			name:     "By IP",
+			filter:   repositories.SecurityEventFilter{IP: "10.0.0.2"},
+			expected: []int64{ids[failed]},
+		},
+		{
+			name:     "By time range",
+			filter:   repositories.SecurityEventFilter{AccountUUID: bob.UUID, Since: now - 100, Until: now},
+			expected: []int64{ids[failed], ids[old]},
+		},
+		{
+			name:     "Limited",
+			filter:   repositories.SecurityEventFilter{Limit: 2},
+			expected: []int64{ids[other], ids[refresh]},
+		},
+		{
+			name:     "Nothing matches",
+			filter:   repositories.SecurityEventFilter{AccountUUID: uuid.NewString()},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter := tc.filter
+			if filter.Limit == 0 {
+				filter.Limit = 10
+			}
+			events, err := repo.Find(ctx, &filter)
+			require.NoError(t, err)
+
+			var found []int64
+			for _, event := range events {
+				found = append(found, event.ID)
+			}
+			assert.Equal(t, tc.expected, found)
+		})
+	}
+}
+
+func testSecurityEventPaging(t *testing.T, repo usecases.ISecurityEventRepo, accounts usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	account, err := accounts.Create(ctx, NewTestAccount("bob@example.com"))
+	require.NoError(t, err)
+
+	var ids []int64
+	for i := 0; i < 5; i++ {
+		added, err := repo.Add(ctx, newTestSecurityEvent(account.UUID, entities.SecurityEventRefresh))
+		require.NoError(t, err)
+		ids = append([]int64{added.ID}, ids...)
+	}
+
+	var found []int64
+	filter := &repositories.SecurityEventFilter{AccountUUID: account.UUID, Limit: 2}
+	for {
+		events, err := repo.Find(ctx, filter)
+		require.NoError(t, err)
+		for _, event := range events {
+			found = append(found, event.ID)
+		}
+		if len(events) < filter.Limit {
+			break
+		}
+		filter.BeforeID = events[len(events)-1].ID
+	}
+	assert.Equal(t, ids, found)
+}
+
+func testSecurityEventDeleteByAccount(t *testing.T, repo usecases.ISecurityEventRepo, accounts usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	bob, err := accounts.Create(ctx, NewTestAccount("bob@example.com"))
+	require.NoError(t, err)
+	alice, err := accounts.Create(ctx, NewTestAccount("alice@example.com"))
+	require.NoError(t, err)
+
+	for _, accountuuid := range []string{bob.UUID, bob.UUID, alice.UUID} {
+		_, err := repo.Add(ctx, newTestSecurityEvent(accountuuid, entities.SecurityEventSignIn))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, repo.DeleteByAccount(ctx, bob.UUID))
+
+	events, err := repo.Find(ctx, &repositories.SecurityEventFilter{AccountUUID: bob.UUID, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	events, err = repo.Find(ctx, &repositories.SecurityEventFilter{AccountUUID: alice.UUID, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+}
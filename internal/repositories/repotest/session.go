@@ -0,0 +1,204 @@
+package repotest
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// NewSessionRepo must return an empty repository and the account repository of the same storage,
+// the sessions are created for the existing accounts only
+type NewSessionRepo func(t *testing.T) (usecases.ISessionRepo, usecases.IAccountRepo)
+
+func newTestSession(accountuuid string) *entities.Session {
+	now := time.Now().Unix()
+	return &entities.Session{
+		UUID:        uuid.NewString(),
+		AccountUUID: accountuuid,
+		IP:          "10.0.0.1",
+		UserAgent:   "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+		DeviceName:  "Firefox on Linux",
+		CreatedAt:   now,
+		RefreshedAt: now,
+		ExpiresAt:   now + 3600,
+	}
+}
+
+func SessionRepo(t *testing.T, newrepo NewSessionRepo) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		repo, accounts := newrepo(t)
+		testSessionRoundTrip(t, repo, accounts)
+	})
+	t.Run("Refresh", func(t *testing.T) {
+		repo, accounts := newrepo(t)
+		testSessionRefresh(t, repo, accounts)
+	})
+	t.Run("Revoke", func(t *testing.T) {
+		repo, accounts := newrepo(t)
+		testSessionRevoke(t, repo, accounts)
+	})
+	t.Run("ActiveByAccount", func(t *testing.T) {
+		repo, accounts := newrepo(t)
+		testSessionActiveByAccount(t, repo, accounts)
+	})
+	t.Run("DeleteByAccount", func(t *testing.T) {
+		repo, accounts := newrepo(t)
+		testSessionDeleteByAccount(t, repo, accounts)
+	})
+}
+
+func testSessionRoundTrip(t *testing.T, repo usecases.ISessionRepo, accounts usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	account, err := accounts.Create(ctx, NewTestAccount("bob@example.com"))
+	require.NoError(t, err)
+
+	in := newTestSession(account.UUID)
+	require.NoError(t, repo.Create(ctx, in))
+
+	session, err := repo.GetOneByUUID(ctx, in.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, in, session)
+
+	_, err = repo.GetOneByUUID(ctx, uuid.NewString())
+	assert.ErrorAs(t, err, &repositories.ErrSessionNotFoundByUUID{})
+}
+
+func testSessionRefresh(t *testing.T, repo usecases.ISessionRepo, accounts usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	account, err := accounts.Create(ctx, NewTestAccount("bob@example.com"))
+	require.NoError(t, err)
+
+	in := newTestSession(account.UUID)
+	require.NoError(t, repo.Create(ctx, in))
+
+	refreshed := *in
+	refreshed.IP = "10.0.0.2"
+	refreshed.UserAgent = "curl/8.5.0"
+	refreshed.DeviceName = "Unknown device"
+	refreshed.RefreshedAt = in.RefreshedAt + 10
+	refreshed.ExpiresAt = in.ExpiresAt + 10
+	// the creation time is immutable
+	refreshed.CreatedAt = in.CreatedAt + 10
+	require.NoError(t, repo.Refresh(ctx, &refreshed))
+
+	session, err := repo.GetOneByUUID(ctx, in.UUID)
+	require.NoError(t, err)
+	refreshed.CreatedAt = in.CreatedAt
+	assert.Equal(t, &refreshed, session)
+
+	require.NoError(t, repo.Revoke(ctx, in.UUID, time.Now().Unix()))
+	err = repo.Refresh(ctx, &refreshed)
+	assert.ErrorAs(t, err, &repositories.ErrSessionNotFoundByUUID{})
+
+	missing := newTestSession(account.UUID)
+	err = repo.Refresh(ctx, missing)
+	assert.ErrorAs(t, err, &repositories.ErrSessionNotFoundByUUID{})
+}
+
+func testSessionRevoke(t *testing.T, repo usecases.ISessionRepo, accounts usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	bob, err := accounts.Create(ctx, NewTestAccount("bob@example.com"))
+	require.NoError(t, err)
+	alice, err := accounts.Create(ctx, NewTestAccount("alice@example.com"))
+	require.NoError(t, err)
+
+	first, second, other := newTestSession(bob.UUID), newTestSession(bob.UUID), newTestSession(alice.UUID)
+	for _, s := range []*entities.Session{first, second, other} {
+		require.NoError(t, repo.Create(ctx, s))
+	}
+
+	revokedat := time.Now().Unix()
+	require.NoError(t, repo.Revoke(ctx, first.UUID, revokedat))
+
+	session, err := repo.GetOneByUUID(ctx, first.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, revokedat, session.RevokedAt)
+
+	// the revocation time of a revoked session is kept
+	err = repo.Revoke(ctx, first.UUID, revokedat+10)
+	assert.ErrorAs(t, err, &repositories.ErrSessionNotFoundByUUID{})
+	err = repo.Revoke(ctx, uuid.NewString(), revokedat)
+	assert.ErrorAs(t, err, &repositories.ErrSessionNotFoundByUUID{})
+
+	require.NoError(t, repo.RevokeAllByAccount(ctx, bob.UUID, revokedat+10))
+
+	session, err = repo.GetOneByUUID(ctx, first.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, revokedat, session.RevokedAt)
+
+	session, err = repo.GetOneByUUID(ctx, second.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, revokedat+10, session.RevokedAt)
+
+	session, err = repo.GetOneByUUID(ctx, other.UUID)
+	require.NoError(t, err)
+	assert.Zero(t, session.RevokedAt)
+}
+
+func testSessionActiveByAccount(t *testing.T, repo usecases.ISessionRepo, accounts usecases.IAccountRepo) {
+	ctx := context.Background()
+	now := time.Now().Unix()
+
+	bob, err := accounts.Create(ctx, NewTestAccount("bob@example.com"))
+	require.NoError(t, err)
+	alice, err := accounts.Create(ctx, NewTestAccount("alice@example.com"))
+	require.NoError(t, err)
+
+	older := newTestSession(bob.UUID)
+	older.RefreshedAt = now - 20
+	latest := newTestSession(bob.UUID)
+	latest.RefreshedAt = now - 10
+	expired := newTestSession(bob.UUID)
+	expired.ExpiresAt = now
+	revoked := newTestSession(bob.UUID)
+	revoked.RevokedAt = now - 5
+	other := newTestSession(alice.UUID)
+	for _, s := range []*entities.Session{older, latest, expired, revoked, other} {
+		require.NoError(t, repo.Create(ctx, s))
+	}
+
+	sessions, err := repo.GetActiveByAccount(ctx, bob.UUID, now)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, latest.UUID, sessions[0].UUID)
+	assert.Equal(t, older.UUID, sessions[1].UUID)
+
+	sessions, err = repo.GetByAccount(ctx, bob.UUID)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 4)
+	for _, session := range sessions {
+		assert.Equal(t, bob.UUID, session.AccountUUID)
+	}
+}
+
+func testSessionDeleteByAccount(t *testing.T, repo usecases.ISessionRepo, accounts usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	bob, err := accounts.Create(ctx, NewTestAccount("bob@example.com"))
+	require.NoError(t, err)
+	alice, err := accounts.Create(ctx, NewTestAccount("alice@example.com"))
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Create(ctx, newTestSession(bob.UUID)))
+	require.NoError(t, repo.Create(ctx, newTestSession(bob.UUID)))
+	require.NoError(t, repo.Create(ctx, newTestSession(alice.UUID)))
+
+	require.NoError(t, repo.DeleteByAccount(ctx, bob.UUID))
+
+	sessions, err := repo.GetByAccount(ctx, bob.UUID)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	sessions, err = repo.GetByAccount(ctx, alice.UUID)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 1)
+}
@@ -0,0 +1,97 @@
+package repotest
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// NewStatusHistoryRepo must return an empty repository and the account repository of the same storage,
+// the changes are added for the existing accounts only
+type NewStatusHistoryRepo func(t *testing.T) (usecases.IStatusHistoryRepo, usecases.IAccountRepo)
+
+func newTestStatusChange(accountuuid string, from, to uint8) *entities.StatusChange {
+	return &entities.StatusChange{
+		AccountUUID: accountuuid,
+		FromStatus:  from,
+		ToStatus:    to,
+		Reason:      "some reason",
+		Actor:       "admin@example.com",
+		CreatedAt:   time.Now().Unix(),
+	}
+}
+
+func StatusHistoryRepo(t *testing.T, newrepo NewStatusHistoryRepo) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		repo, accounts := newrepo(t)
+		testStatusHistoryRoundTrip(t, repo, accounts)
+	})
+	t.Run("LatestFirst", func(t *testing.T) {
+		repo, accounts := newrepo(t)
+		testStatusHistoryLatestFirst(t, repo, accounts)
+	})
+}
+
+func testStatusHistoryRoundTrip(t *testing.T, repo usecases.IStatusHistoryRepo, accounts usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	account, err := accounts.Create(ctx, NewTestAccount("bob@example.com"))
+	require.NoError(t, err)
+
+	in := newTestStatusChange(account.UUID, entities.Active, entities.Suspended)
+	in.Until = in.CreatedAt + 3600
+
+	added, err := repo.Add(ctx, in)
+	require.NoError(t, err)
+	assert.NotZero(t, added.ID)
+
+	expected := *in
+	expected.ID = added.ID
+	assert.Equal(t, &expected, added)
+
+	changes, err := repo.GetByAccount(ctx, account.UUID, 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, &expected, changes[0])
+	assert.True(t, changes[0].IsTemporary())
+
+	changes, err = repo.GetByAccount(ctx, NewTestAccount("alice@example.com").UUID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func testStatusHistoryLatestFirst(t *testing.T, repo usecases.IStatusHistoryRepo, accounts usecases.IAccountRepo) {
+	ctx := context.Background()
+
+	bob, err := accounts.Create(ctx, NewTestAccount("bob@example.com"))
+	require.NoError(t, err)
+	alice, err := accounts.Create(ctx, NewTestAccount("alice@example.com"))
+	require.NoError(t, err)
+
+	// the changes made within the same second are ordered by IDs
+	statuses := []uint8{entities.Active, entities.Suspended, entities.Active, entities.Blocked}
+	for i := 1; i < len(statuses); i++ {
+		_, err = repo.Add(ctx, newTestStatusChange(bob.UUID, statuses[i-1], statuses[i]))
+		require.NoError(t, err)
+		_, err = repo.Add(ctx, newTestStatusChange(alice.UUID, statuses[i-1], statuses[i]))
+		require.NoError(t, err)
+	}
+
+	changes, err := repo.GetByAccount(ctx, bob.UUID, 2)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, entities.Blocked, changes[0].ToStatus)
+	assert.Equal(t, entities.Active, changes[1].ToStatus)
+	assert.Greater(t, changes[0].ID, changes[1].ID)
+	for _, change := range changes {
+		assert.Equal(t, bob.UUID, change.AccountUUID)
+	}
+
+	changes, err = repo.GetByAccount(ctx, alice.UUID, 10)
+	require.NoError(t, err)
+	assert.Len(t, changes, 3)
+}
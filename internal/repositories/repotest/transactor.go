@@ -0,0 +1,227 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+)
+
+// NewTransactor must return the transactor and the repositories of the same empty storage
+type NewTransactor func(t *testing.T) (usecases.ITransactor, *usecases.TxRepos)
+
+var errTestRollback = errors.New("rollback")
+
+func Transactor(t *testing.T, newtx NewTransactor) {
+	t.Run("Commit", func(t *testing.T) {
+		tx, repos := newtx(t)
+		testTransactorCommit(t, tx, repos)
+	})
+	t.Run("Rollback", func(t *testing.T) {
+		tx, repos := newtx(t)
+		testTransactorRollback(t, tx, repos)
+	})
+	t.Run("ConcurrentCheckAndCreate", func(t *testing.T) {
+		tx, repos := newtx(t)
+		testTransactorConcurrentCheckAndCreate(t, tx, repos)
+	})
+}
+
+func testTransactorCommit(t *testing.T, tx usecases.ITransactor, repos *usecases.TxRepos) {
+	ctx := context.Background()
+	in := NewTestAccount("bob@example.com")
+	webhook := newTestWebhook(100)
+
+	err := tx.WithTx(ctx, func(txrepos *usecases.TxRepos) error {
+		if _, err := txrepos.Account.Create(ctx, in); err != nil {
+			return err
+		}
+		if err := txrepos.Account.SetAccountStatus(ctx, in.UUID, entities.Blocked, 0); err != nil {
+			return err
+		}
+		if _, err := txrepos.StatusHistory.Add(ctx, newTestStatusChange(in.UUID, in.Status, entities.Blocked)); err != nil {
+			return err
+		}
+		if err := txrepos.Session.Create(ctx, newTestSession(in.UUID)); err != nil {
+			return err
+		}
+		if _, err := txrepos.SecurityEvent.Add(ctx, newTestSecurityEvent(in.UUID, entities.SecurityEventSignIn)); err != nil {
+			return err
+		}
+		if _, err := txrepos.AuditLog.Add(ctx, newTestAuditRecord(in.UUID, "")); err != nil {
+			return err
+		}
+		if err := txrepos.Export.Create(ctx, &entities.AccountExport{UUID: uuid.NewString(), AccountUUID: in.UUID, CreatedAt: 100}); err != nil {
+			return err
+		}
+		if _, err := txrepos.Outbox.Add(ctx, newTestDomainEvent(in.UUID, entities.DomainEventAccountCreated)); err != nil {
+			return err
+		}
+		if err := txrepos.Webhook.Create(ctx, webhook); err != nil {
+			return err
+		}
+		return txrepos.WebhookDelivery.Add(ctx, newTestWebhookDelivery(webhook.UUID, 100))
+	})
+	require.NoError(t, err)
+
+	account, err := repos.Account.GetOneByUUID(ctx, in.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.Blocked, account.Status)
+
+	changes, err := repos.StatusHistory.GetByAccount(ctx, in.UUID, 10)
+	require.NoError(t, err)
+	assert.Len(t, changes, 1)
+
+	sessions, err := repos.Session.GetByAccount(ctx, in.UUID)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 1)
+
+	events, err := repos.SecurityEvent.Find(ctx, &repositories.SecurityEventFilter{AccountUUID: in.UUID, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+
+	records, err := repos.AuditLog.List(ctx, 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	export, err := repos.Export.GetUnfinishedByAccount(ctx, in.UUID)
+	require.NoError(t, err)
+	assert.NotNil(t, export)
+
+	domainevents, err := repos.Outbox.GetUnpublished(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, domainevents, 1)
+
+	_, err = repos.Webhook.GetOneByUUID(ctx, webhook.UUID)
+	require.NoError(t, err)
+
+	deliveries, err := repos.WebhookDelivery.Find(ctx, &repositories.WebhookDeliveryFilter{WebhookUUID: webhook.UUID, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+}
+
+func testTransactorRollback(t *testing.T, tx usecases.ITransactor, repos *usecases.TxRepos) {
+	ctx := context.Background()
+	in := NewTestAccount("bob@example.com")
+	webhook := newTestWebhook(100)
+
+	err := tx.WithTx(ctx, func(txrepos *usecases.TxRepos) error {
+		if _, err := txrepos.Account.Create(ctx, in); err != nil {
+			return err
+		}
+		if _, err := txrepos.StatusHistory.Add(ctx, newTestStatusChange(in.UUID, in.Status, entities.Blocked)); err != nil {
+			return err
+		}
+		if err := txrepos.Session.Create(ctx, newTestSession(in.UUID)); err != nil {
+			return err
+		}
+		if _, err := txrepos.SecurityEvent.Add(ctx, newTestSecurityEvent(in.UUID, entities.SecurityEventSignIn)); err != nil {
+			return err
+		}
+		if _, err := txrepos.AuditLog.Add(ctx, newTestAuditRecord(in.UUID, "")); err != nil {
+			return err
+		}
+		if err := txrepos.Export.Create(ctx, &entities.AccountExport{UUID: uuid.NewString(), AccountUUID: in.UUID, CreatedAt: 100}); err != nil {
+			return err
+		}
+		if _, err := txrepos.Outbox.Add(ctx, newTestDomainEvent(in.UUID, entities.DomainEventAccountCreated)); err != nil {
+			return err
+		}
+		if err := txrepos.Webhook.Create(ctx, webhook); err != nil {
+			return err
+		}
+		if err := txrepos.WebhookDelivery.Add(ctx, newTestWebhookDelivery(webhook.UUID, 100)); err != nil {
+			return err
+		}
+		return errTestRollback
+	})
+	assert.ErrorIs(t, err, errTestRollback)
+
+	exists, err := repos.Account.IsExistByUUID(ctx, in.UUID)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	changes, err := repos.StatusHistory.GetByAccount(ctx, in.UUID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	sessions, err := repos.Session.GetByAccount(ctx, in.UUID)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	events, err := repos.SecurityEvent.Find(ctx, &repositories.SecurityEventFilter{AccountUUID: in.UUID, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	last, err := repos.AuditLog.Last(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, last)
+
+	export, err := repos.Export.GetUnfinishedByAccount(ctx, in.UUID)
+	require.NoError(t, err)
+	assert.Nil(t, export)
+
+	domainevents, err := repos.Outbox.GetUnpublished(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, domainevents)
+
+	_, err = repos.Webhook.GetOneByUUID(ctx, webhook.UUID)
+	assert.ErrorAs(t, err, &repositories.ErrWebhookNotFoundByUUID{})
+
+	deliveries, err := repos.WebhookDelivery.Find(ctx, &repositories.WebhookDeliveryFilter{WebhookUUID: webhook.UUID, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+}
+
+// testTransactorConcurrentCheckAndCreate runs the sign up sequence concurrently,
+// only one of the transactions must create the account
+func testTransactorConcurrentCheckAndCreate(t *testing.T, tx usecases.ITransactor, repos *usecases.TxRepos) {
+	ctx := context.Background()
+
+	const workers = 4
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- tx.WithTx(ctx, func(txrepos *usecases.TxRepos) error {
+				account := NewTestAccount("bob@example.com")
+				exists, err := txrepos.Account.IsExist(ctx, account)
+				if err != nil {
+					return err
+				}
+				if exists {
+					return usecases.ErrAccountAlreadyExist
+				}
+				_, err = txrepos.Account.Create(ctx, account)
+				return err
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	var succeeded int
+	for err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		if !errors.Is(err, usecases.ErrAccountAlreadyExist) {
+			assert.ErrorAs(t, err, &repositories.ErrAccountAlreadyExists{})
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+
+	exists, err := repos.Account.IsExist(ctx, &entities.Account{Email: "bob@example.com"})
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
@@ -0,0 +1,347 @@
+package repotest
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// NewWebhookRepos must return the empty webhook and delivery repositories of the same storage,
+// the deliveries are added for the existing webhooks only
+type NewWebhookRepos func(t *testing.T) (usecases.IWebhookRepo, usecases.IWebhookDeliveryRepo)
+
+func newTestWebhook(createdat int64, eventtypes ...string) *entities.Webhook {
+	return &entities.Webhook{
+		UUID:       uuid.NewString(),
+		URL:        "https://partner.example.com/hooks",
+		Secret:     "0123456789abcdef",
+		EventTypes: eventtypes,
+		Active:     true,
+		CreatedAt:  createdat,
+		UpdatedAt:  createdat,
+	}
+}
+
+func newTestWebhookDelivery(webhookuuid string, nextattemptat int64) *entities.WebhookDelivery {
+	return &entities.WebhookDelivery{
+		WebhookUUID:   webhookuuid,
+		EventUUID:     uuid.NewString(),
+		EventType:     entities.DomainEventAccountCreated,
+		Payload:       `{"ID":"event-uuid","Type":"account.created"}`,
+		Status:        entities.WebhookDeliveryPending,
+		NextAttemptAt: nextattemptat,
+		CreatedAt:     nextattemptat,
+		UpdatedAt:     nextattemptat,
+	}
+}
+
+func WebhookRepos(t *testing.T, newrepos NewWebhookRepos) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		webhooks, _ := newrepos(t)
+		testWebhookRoundTrip(t, webhooks)
+	})
+	t.Run("UpdateAndDelete", func(t *testing.T) {
+		webhooks, _ := newrepos(t)
+		testWebhookUpdateAndDelete(t, webhooks)
+	})
+	t.Run("DeliveryIsAddedOnce", func(t *testing.T) {
+		webhooks, deliveries := newrepos(t)
+		testWebhookDeliveryIsAddedOnce(t, webhooks, deliveries)
+	})
+	t.Run("ClaimDue", func(t *testing.T) {
+		webhooks, deliveries := newrepos(t)
+		testWebhookDeliveryClaimDue(t, webhooks, deliveries)
+	})
+	t.Run("Find", func(t *testing.T) {
+		webhooks, deliveries := newrepos(t)
+		testWebhookDeliveryFind(t, webhooks, deliveries)
+	})
+	t.Run("Delete", func(t *testing.T) {
+		webhooks, deliveries := newrepos(t)
+		testWebhookDeliveryDelete(t, webhooks, deliveries)
+	})
+	t.Run("SetPayloadByEvent", func(t *testing.T) {
+		webhooks, deliveries := newrepos(t)
+		testWebhookDeliverySetPayloadByEvent(t, webhooks, deliveries)
+	})
+	t.Run("NotFound", func(t *testing.T) {
+		webhooks, deliveries := newrepos(t)
+		testWebhookNotFound(t, webhooks, deliveries)
+	})
+}
+
+func testWebhookRoundTrip(t *testing.T, repo usecases.IWebhookRepo) {
+	ctx := context.Background()
+	now := time.Now().Unix()
+
+	all := newTestWebhook(now)
+	some := newTestWebhook(now-60, entities.DomainEventAccountCreated, entities.DomainEventAccountDeleted)
+	require.NoError(t, repo.Create(ctx, all))
+	require.NoError(t, repo.Create(ctx, some))
+
+	got, err := repo.GetOneByUUID(ctx, some.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, some, got)
+
+	got, err = repo.GetOneByUUID(ctx, all.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, all, got)
+	assert.Empty(t, got.EventTypes)
+
+	webhooks, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*entities.Webhook{some, all}, webhooks)
+}
+
+func testWebhookUpdateAndDelete(t *testing.T, repo usecases.IWebhookRepo) {
+	ctx := context.Background()
+	now := time.Now().Unix()
+
+	webhook := newTestWebhook(now - 60)
+	require.NoError(t, repo.Create(ctx, webhook))
+
+	webhook.URL = "https://partner.example.com/v2/hooks"
+	webhook.Secret = "fedcba9876543210"
+	webhook.EventTypes = []string{entities.DomainEventAccountPurged}
+	webhook.Active = false
+	webhook.UpdatedAt = now
+	require.NoError(t, repo.Update(ctx, webhook))
+
+	got, err := repo.GetOneByUUID(ctx, webhook.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, webhook, got)
+
+	require.NoError(t, repo.Delete(ctx, webhook.UUID))
+	_, err = repo.GetOneByUUID(ctx, webhook.UUID)
+	assert.ErrorAs(t, err, &repositories.ErrWebhookNotFoundByUUID{})
+
+	webhooks, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, webhooks)
+}
+
+func testWebhookDeliveryIsAddedOnce(t *testing.T, webhooks usecases.IWebhookRepo, repo usecases.IWebhookDeliveryRepo) {
+	ctx := context.Background()
+	now := time.Now().Unix()
+
+	webhook := newTestWebhook(now)
+	require.NoError(t, webhooks.Create(ctx, webhook))
+
+	delivery := newTestWebhookDelivery(webhook.UUID, now)
+	require.NoError(t, repo.Add(ctx, delivery))
+	require.NoError(t, repo.Add(ctx, delivery))
+
+	deliveries, err := repo.Find(ctx, &repositories.WebhookDeliveryFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.NotZero(t, deliveries[0].ID)
+
+	delivery.ID = deliveries[0].ID
+	assert.Equal(t, delivery, deliveries[0])
+
+	got, err := repo.GetOneByID(ctx, delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, delivery, got)
+}
+
+func testWebhookDeliveryClaimDue(t *testing.T, webhooks usecases.IWebhookRepo, repo usecases.IWebhookDeliveryRepo) {
+	ctx := context.Background()
+	now := time.Now().Unix()
+
+	webhook := newTestWebhook(now)
+	require.NoError(t, webhooks.Create(ctx, webhook))
+
+	later := newTestWebhookDelivery(webhook.UUID, now-10)
+	earlier := newTestWebhookDelivery(webhook.UUID, now-20)
+	future := newTestWebhookDelivery(webhook.UUID, now+60)
+	for _, delivery := range []*entities.WebhookDelivery{later, earlier, future} {
+		require.NoError(t, repo.Add(ctx, delivery))
+	}
+
+	claimed, err := repo.ClaimDue(ctx, now, now+300)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, earlier.EventUUID, claimed.EventUUID)
+	assert.Equal(t, now+300, claimed.NextAttemptAt)
+	assert.Equal(t, now, claimed.UpdatedAt)
+
+	claimed, err = repo.ClaimDue(ctx, now, now+300)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, later.EventUUID, claimed.EventUUID)
+
+	// the claimed deliveries are leased and the future one isn't due yet
+	claimed, err = repo.ClaimDue(ctx, now, now+300)
+	require.NoError(t, err)
+	assert.Nil(t, claimed)
+
+	// a delivered delivery is never claimed, a lapsed lease is claimed again
+	claimed, err = repo.ClaimDue(ctx, now+300, now+600)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	claimed.Status = entities.WebhookDeliveryDelivered
+	claimed.Attempts = 1
+	claimed.LastStatusCode = 200
+	claimed.UpdatedAt = now + 300
+	require.NoError(t, repo.Update(ctx, claimed))
+
+	got, err := repo.GetOneByID(ctx, claimed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, claimed, got)
+
+	for i := 0; i < 2; i++ {
+		claimed, err = repo.ClaimDue(ctx, now+300, now+600)
+		require.NoError(t, err)
+		require.NotNil(t, claimed)
+		assert.NotEqual(t, got.ID, claimed.ID)
+	}
+	claimed, err = repo.ClaimDue(ctx, now+300, now+600)
+	require.NoError(t, err)
+	assert.Nil(t, claimed)
+}
+
+func testWebhookDeliveryFind(t *testing.T, webhooks usecases.IWebhookRepo, repo usecases.IWebhookDeliveryRepo) {
+	ctx := context.Background()
+	now := time.Now().Unix()
+
+	first := newTestWebhook(now)
+	second := newTestWebhook(now)
+	require.NoError(t, webhooks.Create(ctx, first))
+	require.NoError(t, webhooks.Create(ctx, second))
+
+	for _, webhookuuid := range []string{first.UUID, second.UUID, first.UUID, first.UUID} {
+		require.NoError(t, repo.Add(ctx, newTestWebhookDelivery(webhookuuid, now)))
+	}
+
+	all, err := repo.Find(ctx, &repositories.WebhookDeliveryFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, all, 4)
+	assert.Greater(t, all[0].ID, all[1].ID)
+
+	dead := all[0]
+	dead.Status = entities.WebhookDeliveryDead
+	require.NoError(t, repo.Update(ctx, dead))
+
+	deliveries, err := repo.Find(ctx, &repositories.WebhookDeliveryFilter{WebhookUUID: first.UUID, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, all[0].ID, deliveries[0].ID)
+	assert.Equal(t, all[1].ID, deliveries[1].ID)
+
+	deliveries, err = repo.Find(ctx, &repositories.WebhookDeliveryFilter{WebhookUUID: first.UUID, BeforeID: deliveries[1].ID, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, all[3].ID, deliveries[0].ID)
+
+	deliveries, err = repo.Find(ctx, &repositories.WebhookDeliveryFilter{
+		Statuses: []uint8{entities.WebhookDeliveryDead, entities.WebhookDeliveryDelivered},
+		Limit:    10,
+	})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, dead, deliveries[0])
+
+	deliveries, err = repo.Find(ctx, &repositories.WebhookDeliveryFilter{WebhookUUID: second.UUID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, all[2].ID, deliveries[0].ID)
+}
+
+func testWebhookDeliveryDelete(t *testing.T, webhooks usecases.IWebhookRepo, repo usecases.IWebhookDeliveryRepo) {
+	ctx := context.Background()
+	now := time.Now().Unix()
+
+	kept := newTestWebhook(now)
+	deleted := newTestWebhook(now)
+	require.NoError(t, webhooks.Create(ctx, kept))
+	require.NoError(t, webhooks.Create(ctx, deleted))
+
+	for _, webhookuuid := range []string{kept.UUID, kept.UUID, kept.UUID, deleted.UUID} {
+		require.NoError(t, repo.Add(ctx, newTestWebhookDelivery(webhookuuid, now)))
+	}
+
+	require.NoError(t, repo.DeleteByWebhook(ctx, deleted.UUID))
+
+	deliveries, err := repo.Find(ctx, &repositories.WebhookDeliveryFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 3)
+
+	old, recent := deliveries[0], deliveries[1]
+	old.Status = entities.WebhookDeliveryDelivered
+	old.UpdatedAt = now - 3600
+	require.NoError(t, repo.Update(ctx, old))
+	recent.Status = entities.WebhookDeliveryDead
+	recent.UpdatedAt = now
+	require.NoError(t, repo.Update(ctx, recent))
+
+	n, err := repo.DeleteFinishedBefore(ctx, now-60)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// the pending deliveries are never deleted
+	n, err = repo.DeleteFinishedBefore(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	deliveries, err = repo.Find(ctx, &repositories.WebhookDeliveryFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, entities.WebhookDeliveryPending, deliveries[0].Status)
+}
+
+func testWebhookDeliverySetPayloadByEvent(t *testing.T, webhooks usecases.IWebhookRepo, repo usecases.IWebhookDeliveryRepo) {
+	ctx := context.Background()
+	now := time.Now().Unix()
+
+	first := newTestWebhook(now)
+	second := newTestWebhook(now)
+	require.NoError(t, webhooks.Create(ctx, first))
+	require.NoError(t, webhooks.Create(ctx, second))
+
+	erased := newTestWebhookDelivery(first.UUID, now)
+	copied := newTestWebhookDelivery(second.UUID, now)
+	copied.EventUUID = erased.EventUUID
+	kept := newTestWebhookDelivery(first.UUID, now)
+	for _, delivery := range []*entities.WebhookDelivery{erased, copied, kept} {
+		require.NoError(t, repo.Add(ctx, delivery))
+	}
+
+	require.NoError(t, repo.SetPayloadByEvent(ctx, erased.EventUUID, `{"ID":"event-uuid"}`))
+
+	deliveries, err := repo.Find(ctx, &repositories.WebhookDeliveryFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 3)
+	for _, delivery := range deliveries {
+		if delivery.EventUUID == erased.EventUUID {
+			assert.Equal(t, `{"ID":"event-uuid"}`, delivery.Payload)
+		} else {
+			assert.Equal(t, kept.Payload, delivery.Payload)
+		}
+	}
+}
+
+func testWebhookNotFound(t *testing.T, webhooks usecases.IWebhookRepo, repo usecases.IWebhookDeliveryRepo) {
+	ctx := context.Background()
+	missing := newTestWebhook(time.Now().Unix())
+
+	_, err := webhooks.GetOneByUUID(ctx, missing.UUID)
+	assert.ErrorAs(t, err, &repositories.ErrWebhookNotFoundByUUID{})
+
+	err = webhooks.Update(ctx, missing)
+	assert.ErrorAs(t, err, &repositories.ErrWebhookNotFoundByUUID{})
+
+	err = webhooks.Delete(ctx, missing.UUID)
+	assert.ErrorAs(t, err, &repositories.ErrWebhookNotFoundByUUID{})
+
+	_, err = repo.GetOneByID(ctx, 42)
+	assert.ErrorAs(t, err, &repositories.ErrWebhookDeliveryNotFoundByID{})
+
+	err = repo.Update(ctx, &entities.WebhookDelivery{ID: 42})
+	assert.ErrorAs(t, err, &repositories.ErrWebhookDeliveryNotFoundByID{})
+}
@@ -0,0 +1,24 @@
+package repositories
+
+type SecurityEvent struct {
+	ID          int64
+	AccountUUID string
+	Type        uint8
+	IP          string
+	UserAgent   string
+	Details     string
+	CreatedAt   int64
+}
+
+// SecurityEventFilter selects the security events, the zero fields don't filter.
+// The events are returned from the latest one, BeforeID continues the listing after the event with this ID
+type SecurityEventFilter struct {
+	AccountUUID string
+	Types       []uint8
+	IP          string
+	// Since and Until bound the creation time, Until is exclusive
+	Since    int64
+	Until    int64
+	BeforeID int64
+	Limit    int
+}
@@ -0,0 +1,13 @@
+package repositories
+
+type Session struct {
+	UUID        string
+	AccountUUID string
+	IP          string
+	UserAgent   string
+	DeviceName  string
+	CreatedAt   int64
+	RefreshedAt int64
+	ExpiresAt   int64
+	RevokedAt   int64
+}
@@ -0,0 +1,12 @@
+package repositories
+
+type StatusChange struct {
+	ID          int64
+	AccountUUID string
+	FromStatus  uint8
+	ToStatus    uint8
+	Reason      string
+	Actor       string
+	Until       int64
+	CreatedAt   int64
+}
@@ -0,0 +1,36 @@
+package repositories
+
+type Webhook struct {
+	UUID   string
+	URL    string
+	Secret string
+	// EventTypes are the subscribed event types separated with commas
+	EventTypes string
+	Active     bool
+	CreatedAt  int64
+	UpdatedAt  int64
+}
+
+type WebhookDelivery struct {
+	ID             int64
+	WebhookUUID    string
+	EventUUID      string
+	EventType      string
+	Payload        string
+	Status         uint8
+	Attempts       int
+	NextAttemptAt  int64
+	LastStatusCode int
+	LastError      string
+	CreatedAt      int64
+	UpdatedAt      int64
+}
+
+// WebhookDeliveryFilter selects the webhook deliveries, the zero fields don't filter.
+// The deliveries are returned from the latest one, BeforeID continues the listing after the delivery with this ID
+type WebhookDeliveryFilter struct {
+	WebhookUUID string
+	Statuses    []uint8
+	BeforeID    int64
+	Limit       int
+}
@@ -0,0 +1,16 @@
+package tracing
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Start starts the span of the tracer, ctx isn't wrapped while the tracing is off,
+// so the spans cost nothing without a tracer provider
+func Start(ctx context.Context, tracer trace.Tracer, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	spanctx, span := tracer.Start(ctx, name, opts...)
+	if !span.SpanContext().IsValid() {
+		return ctx, span
+	}
+	return spanctx, span
+}
@@ -0,0 +1,123 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"io"
+)
+
+const (
+	ExporterNone   = ""
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+
+	ServiceName = "runbot-auth"
+)
+
+var (
+	ErrConfigIsNil        = errors.New("config is nil")
+	ErrExporterIsUnknown  = errors.New("tracing exporter is unknown")
+	ErrSampleRatioIsWrong = errors.New("tracing sample ratio isn't between 0 and 1")
+)
+
+type Config struct {
+	// Exporter is one of otlp, stdout or empty, the spans aren't recorded without an exporter
+	// but the trace context is still propagated
+	Exporter string
+	// Endpoint is the host:port of the OTLP gRPC collector, the OTEL_EXPORTER_OTLP_* variables are used if it's empty
+	Endpoint string
+	Insecure bool
+	// SampleRatio of the new traces, the traces started upstream follow the parent's decision. 1 if it isn't positive
+	SampleRatio float64
+	// Version of the service in the resource of the spans
+	Version string
+	// Output of the stdout exporter, os.Stdout if it's nil
+	Output io.Writer
+}
+
+// Tracing is the global tracer provider of the app, the spans are batched to the exporter
+type Tracing struct {
+	provider *sdktrace.TracerProvider
+}
+
+// New sets the global tracer provider and the W3C trace context propagator up
+func New(ctx context.Context, c *Config) (*Tracing, error) {
+	if c == nil {
+		return nil, ErrConfigIsNil
+	}
+	if c.SampleRatio > 1 {
+		return nil, ErrSampleRatioIsWrong
+	}
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	exporter, err := newExporter(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		return &Tracing{}, nil
+	}
+
+	ratio := c.SampleRatio
+	if ratio <= 0 {
+		ratio = 1
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(ServiceName),
+			semconv.ServiceVersion(c.Version),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+
+	return &Tracing{
+		provider: provider,
+	}, nil
+}
+
+// Shutdown flushes the pending spans
+func (t *Tracing) Shutdown(ctx context.Context) error {
+	if t.provider == nil {
+		return nil
+	}
+	return t.provider.Shutdown(ctx)
+}
+
+func newExporter(ctx context.Context, c *Config) (sdktrace.SpanExporter, error) {
+	switch c.Exporter {
+	case ExporterNone:
+		return nil, nil
+	case ExporterStdout:
+		var opts []stdouttrace.Option
+		if c.Output != nil {
+			opts = append(opts, stdouttrace.WithWriter(c.Output))
+		}
+		return stdouttrace.New(opts...)
+	case ExporterOTLP:
+		var opts []otlptracegrpc.Option
+		if c.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(c.Endpoint))
+		}
+		if c.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		// the exporter connects in the background, so the app starts while the collector is down
+		return otlptracegrpc.New(ctx, opts...)
+	default:
+		return nil, ErrExporterIsUnknown
+	}
+}
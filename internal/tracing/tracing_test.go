@@ -0,0 +1,52 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := New(ctx, nil)
+	assert.ErrorIs(t, err, ErrConfigIsNil)
+
+	_, err = New(ctx, &Config{Exporter: "zipkin"})
+	assert.ErrorIs(t, err, ErrExporterIsUnknown)
+
+	_, err = New(ctx, &Config{SampleRatio: 2})
+	assert.ErrorIs(t, err, ErrSampleRatioIsWrong)
+
+	tracing, err := New(ctx, &Config{})
+	require.NoError(t, err)
+	assert.NoError(t, tracing.Shutdown(ctx), "nothing is exported without an exporter")
+}
+
+func TestNew_Stdout(t *testing.T) {
+	ctx := context.Background()
+	output := &bytes.Buffer{}
+
+	tracing, err := New(ctx, &Config{Exporter: ExporterStdout, Version: "v1", Output: output})
+	require.NoError(t, err)
+
+	spanctx, span := Start(ctx, otel.Tracer("test"), "Account.SignIn")
+	assert.NotEqual(t, ctx, spanctx)
+	span.End()
+
+	require.NoError(t, tracing.Shutdown(ctx))
+	assert.Contains(t, output.String(), `"Name":"Account.SignIn"`)
+	assert.Contains(t, output.String(), `"Value":"runbot-auth"`)
+}
+
+func TestStart(t *testing.T) {
+	ctx := context.Background()
+
+	spanctx, span := Start(ctx, noop.NewTracerProvider().Tracer("test"), "Account.SignIn")
+	defer span.End()
+	assert.Equal(t, ctx, spanctx, "ctx isn't wrapped without the tracing")
+}
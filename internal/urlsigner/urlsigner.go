@@ -0,0 +1,116 @@
+package urlsigner
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"golang.org/x/crypto/hkdf"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultTTL = 15 * time.Minute
+
+	// derivedSecretLength is the number of the bytes of a derived secret, the size of the HMAC-SHA256 key block is enough
+	derivedSecretLength = 32
+	// derivedSecretInfo prefixes the purpose of a derived secret, so the secrets of this service don't match
+	// the ones derived from the same master by anything else
+	derivedSecretInfo = "runbot-auth url signer: "
+)
+
+var (
+	ErrConfigIsNil          = errors.New("config is nil")
+	ErrSecretIsEmpty        = errors.New("url signer secret is empty")
+	ErrSignatureIsNotValid  = errors.New("url signature is not valid")
+	ErrSignatureIsExpired   = errors.New("url signature is expired")
+	ErrExpirationIsNotValid = errors.New("url signature expiration is not valid")
+	ErrPurposeIsEmpty       = errors.New("url signer purpose is empty")
+)
+
+type Config struct {
+	Secret string
+	// TTL is DefaultTTL if it isn't positive
+	TTL time.Duration
+}
+
+// Signer signs resources with HMAC-SHA256, so they can be given out as expiring URLs
+// without any state on the server side
+type Signer struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+func New(c *Config) (*Signer, error) {
+	if c == nil {
+		return nil, ErrConfigIsNil
+	}
+	if c.Secret == "" {
+		return nil, ErrSecretIsEmpty
+	}
+	ttl := c.TTL
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Signer{
+		secret: []byte(c.Secret),
+		ttl:    ttl,
+	}, nil
+}
+
+// Sign returns the expiration unix time and the signature of the resource
+func (s *Signer) Sign(resource string) (string, string) {
+	expires := strconv.FormatInt(time.Now().Add(s.ttl).Unix(), 10)
+	return expires, s.signature(resource, expires)
+}
+
+func (s *Signer) Verify(resource, expires, signature string) error {
+	expiresat, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrExpirationIsNotValid
+	}
+
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrSignatureIsNotValid
+	}
+	want, _ := hex.DecodeString(s.signature(resource, expires))
+	if !hmac.Equal(got, want) {
+		return ErrSignatureIsNotValid
+	}
+
+	// the expiration is checked after the signature, so it can't be forged
+	if time.Now().Unix() >= expiresat {
+		return ErrSignatureIsExpired
+	}
+
+	return nil
+}
+
+// DeriveSecret derives the secret of the purpose from the master secret with HKDF-SHA256. The secrets of the
+// different purposes are independent: a signature made for one purpose is rejected by the others, and none of
+// them discloses the master, e.g. the JWT salt
+func DeriveSecret(master, purpose string) (string, error) {
+	if master == "" {
+		return "", ErrSecretIsEmpty
+	}
+	if purpose == "" {
+		return "", ErrPurposeIsEmpty
+	}
+
+	secret := make([]byte, derivedSecretLength)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, []byte(master), nil, []byte(derivedSecretInfo+purpose)), secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+func (s *Signer) signature(resource, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(resource))
+	mac.Write([]byte{0})
+	mac.Write([]byte(expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,134 @@
+package urlsigner
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New(nil)
+	assert.ErrorIs(t, err, ErrConfigIsNil)
+
+	_, err = New(&Config{})
+	assert.ErrorIs(t, err, ErrSecretIsEmpty)
+
+	s, err := New(&Config{Secret: "secret"})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTTL, s.ttl)
+}
+
+func TestSigner_Verify(t *testing.T) {
+	s, err := New(&Config{Secret: "secret", TTL: time.Minute})
+	require.NoError(t, err)
+
+	expires, signature := s.Sign("someresource")
+
+	expired := strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+	other, err := New(&Config{Secret: "othersecret"})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name        string
+		signer      *Signer
+		resource    string
+		expires     string
+		signature   string
+		expectedErr error
+	}{
+		{
+			name:        "Valid signature",
+			signer:      s,
+			resource:    "someresource",
+			expires:     expires,
+			signature:   signature,
+			expectedErr: nil,
+		},
+		{
+			name:        "Other resource",
+			signer:      s,
+			resource:    "otherresource",
+			expires:     expires,
+			signature:   signature,
+			expectedErr: ErrSignatureIsNotValid,
+		},
+		{
+			name:        "Extended expiration",
+			signer:      s,
+			resource:    "someresource",
+			expires:     strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10),
+			signature:   signature,
+			expectedErr: ErrSignatureIsNotValid,
+		},
+		{
+			name:        "Other secret",
+			signer:      other,
+			resource:    "someresource",
+			expires:     expires,
+			signature:   signature,
+			expectedErr: ErrSignatureIsNotValid,
+		},
+		{
+			name:        "Signature is not hex",
+			signer:      s,
+			resource:    "someresource",
+			expires:     expires,
+			signature:   "nothex",
+			expectedErr: ErrSignatureIsNotValid,
+		},
+		{
+			name:        "Expiration is not a number",
+			signer:      s,
+			resource:    "someresource",
+			expires:     "tomorrow",
+			signature:   signature,
+			expectedErr: ErrExpirationIsNotValid,
+		},
+		{
+			name:        "Expired signature",
+			signer:      s,
+			resource:    "someresource",
+			expires:     expired,
+			signature:   s.signature("someresource", expired),
+			expectedErr: ErrSignatureIsExpired,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.signer.Verify(tc.resource, tc.expires, tc.signature)
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestDeriveSecret(t *testing.T) {
+	_, err := DeriveSecret("", "export")
+	assert.ErrorIs(t, err, ErrSecretIsEmpty)
+	_, err = DeriveSecret("salt", "")
+	assert.ErrorIs(t, err, ErrPurposeIsEmpty)
+
+	export, err := DeriveSecret("salt", "export")
+	require.NoError(t, err)
+	assert.Len(t, export, 2*derivedSecretLength)
+	assert.NotContains(t, export, "salt")
+
+	again, err := DeriveSecret("salt", "export")
+	require.NoError(t, err)
+	assert.Equal(t, export, again)
+
+	links, err := DeriveSecret("salt", "links")
+	require.NoError(t, err)
+	assert.NotEqual(t, export, links)
+
+	// a URL signed for one purpose isn't accepted by the signer of another one
+	exportsigner, err := New(&Config{Secret: export})
+	require.NoError(t, err)
+	linksigner, err := New(&Config{Secret: links})
+	require.NoError(t, err)
+	expires, signature := exportsigner.Sign("someresource")
+	assert.NoError(t, exportsigner.Verify("someresource", expires, signature))
+	assert.ErrorIs(t, linksigner.Verify("someresource", expires, signature), ErrSignatureIsNotValid)
+}
@@ -3,31 +3,83 @@ package usecases
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/alexsibrin/runbot-auth/internal/entities"
 	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"github.com/alexsibrin/runbot-auth/internal/tracing"
 	"github.com/google/uuid"
 	"time"
 )
 
-//go:generate mockgen -destination mocks/mock_usecases.go -package usecases_test github.com/alexsibrin/runbot-auth/internal/usecases IPasswordHasher,IAccountRepo
+//go:generate mockgen -destination mocks/mock_usecases.go -package usecases_test github.com/alexsibrin/runbot-auth/internal/usecases IPasswordHasher,IAccountRepo,IStatusHistoryRepo
 
 var (
 	ErrDependenciesAreNil  = errors.New("dependencies are nil")
 	ErrPaswordHasherIsNil  = errors.New("dependency password hasher is nil")
 	ErrAccountRepoIsNil    = errors.New("dependency account repo is nil")
+	ErrTransactorIsNil     = errors.New("dependency transactor is nil")
+	ErrStatusHistoryIsNil  = errors.New("dependency status history repo is nil")
 	ErrAccountAlreadyExist = errors.New("account already exists")
 	ErrAccountIsNotExist   = errors.New("account is not exist")
 	ErrEmailIsWrong        = errors.New("email is wrong")
 	ErrPasswordIsWrong     = errors.New("password is wrong")
 	ErrAccountIsNotActive  = errors.New("account is not active")
+	ErrAccountIsDeleted    = errors.New("account is already deleted")
+	ErrTooManyUUIDs        = errors.New("too many uuids")
 )
 
+const (
+	DefaultDeletionGracePeriod = 30 * 24 * time.Hour
+
+	DefaultStatusHistoryLimit = 50
+	MaxStatusHistoryLimit     = 500
+
+	// MaxBatchGetUUIDs bounds a batch lookup, the duplicates are counted
+	MaxBatchGetUUIDs = 100
+
+	DefaultAccountsLimit = 50
+	MaxAccountsLimit     = 500
+
+	deletionReason     = "account deletion"
+	reactivationReason = "temporary status lapsed"
+
+	signInFailedWrongPassword = "wrong password"
+	signInFailedNotActive     = "account is not active"
+	signInFailedResetRequired = "password reset is required"
+)
+
+// SignInRequest describes the credentials and the client which signs in
+type SignInRequest struct {
+	Email     string
+	Password  string
+	IP        string
+	UserAgent string
+}
+
 type AccountCreateRequest struct {
 	Name     string
 	Email    string
 	Password string
 }
 
+// AccountStatusChangeRequest changes the account status, a non-zero Until makes the status temporary.
+// The change is recorded in the audit log if Audit is set
+type AccountStatusChangeRequest struct {
+	UUID   string
+	Status uint8
+	Reason string
+	Actor  string
+	Until  int64
+	Audit  *AuditMetadata
+}
+
+// AccountDeleteRequest deletes the account, the deletion is recorded in the audit log if Audit is set
+type AccountDeleteRequest struct {
+	UUID  string
+	Actor string
+	Audit *AuditMetadata
+}
+
 type IPasswordHasher interface {
 	Hash(str string) (string, error)
 	Compare(str, hash string) error
@@ -36,20 +88,76 @@ type IPasswordHasher interface {
 type IAccountRepo interface {
 	GetOneByEmail(ctx context.Context, email string) (*entities.Account, error)
 	GetOneByUUID(ctx context.Context, uuid string) (*entities.Account, error)
+	// GetManyByUUIDs returns the found accounts in any order, the missing UUIDs are skipped
+	GetManyByUUIDs(ctx context.Context, uuids []string) ([]*entities.Account, error)
+	// Find returns up to filter.Limit accounts matching the filter in the order of filter.Sort
+	Find(ctx context.Context, filter *repositories.AccountFilter) ([]*entities.Account, error)
 	IsExist(ctx context.Context, account *entities.Account) (bool, error)
 	IsExistByUUID(ctx context.Context, uuid string) (bool, error)
 	Create(ctx context.Context, account *entities.Account) (*entities.Account, error)
-	SetAccountStatus(ctx context.Context, uuid string, status uint8) error
+	// SetAccountStatus sets the status which lapses at until, zero until makes it permanent
+	SetAccountStatus(ctx context.Context, uuid string, status uint8, until int64) error
+	// GetStatusExpiredBefore returns the accounts which temporary status lapsed by before, the earliest go first
+	GetStatusExpiredBefore(ctx context.Context, before int64, limit int) ([]*entities.Account, error)
+	// SetPassword replaces the password hash, the empty hash makes the user reset the password
+	SetPassword(ctx context.Context, uuid, password string, updatedat int64) error
+	MarkAccountDeleted(ctx context.Context, uuid string, deletedat int64) error
+	// GetDeletedBefore returns deleted but not purged accounts, the oldest deletions go first
+	GetDeletedBefore(ctx context.Context, before int64, limit int) ([]*entities.Account, error)
+	// PurgeAccount erases the PII of a deleted account
+	PurgeAccount(ctx context.Context, uuid string, purgedat int64) error
+}
+
+type IStatusHistoryRepo interface {
+	// Add stores the change and returns it with the assigned ID
+	Add(ctx context.Context, change *entities.StatusChange) (*entities.StatusChange, error)
+	// GetByAccount returns up to limit latest changes of the account, the latest go first
+	GetByAccount(ctx context.Context, accountuuid string, limit int) ([]*entities.StatusChange, error)
+}
+
+// TxRepos are the repositories bound to a transaction
+type TxRepos struct {
+	Account         IAccountRepo
+	StatusHistory   IStatusHistoryRepo
+	Session         ISessionRepo
+	SecurityEvent   ISecurityEventRepo
+	AuditLog        IAuditLogRepo
+	Export          IExportRepo
+	Outbox          IOutboxRepo
+	Webhook         IWebhookRepo
+	WebhookDelivery IWebhookDeliveryRepo
+}
+
+// ITransactor runs fn in a transaction, the repos given to fn are bound to it.
+// The transaction is committed if fn returns nil and rolled back otherwise,
+// fn may be called several times if the transaction has to be retried
+type ITransactor interface {
+	WithTx(ctx context.Context, fn func(repos *TxRepos) error) error
 }
 
 type AccountDependencies struct {
 	Repo           IAccountRepo
+	StatusHistory  IStatusHistoryRepo
+	SecurityEvents ISecurityEventRepo
+	Transactor     ITransactor
 	PasswordHasher IPasswordHasher
+	// LoginAlert is optional, the owners aren't notified about the unusual sign ins without it
+	LoginAlert ILoginAlert
+	// Metrics is optional
+	Metrics IMetrics
+	// DeletionGracePeriod is DefaultDeletionGracePeriod if it isn't positive
+	DeletionGracePeriod time.Duration
 }
 
 type Account struct {
-	repo           IAccountRepo
-	passwordhasher IPasswordHasher
+	repo                IAccountRepo
+	statushistory       IStatusHistoryRepo
+	securityevents      ISecurityEventRepo
+	transactor          ITransactor
+	passwordhasher      IPasswordHasher
+	loginalert          ILoginAlert
+	metrics             IMetrics
+	deletiongraceperiod time.Duration
 }
 
 func NewAccount(d *AccountDependencies) (*Account, error) {
@@ -62,89 +170,597 @@ func NewAccount(d *AccountDependencies) (*Account, error) {
 	if d.Repo == nil {
 		return nil, ErrAccountRepoIsNil
 	}
+	if d.StatusHistory == nil {
+		return nil, ErrStatusHistoryIsNil
+	}
+	if d.SecurityEvents == nil {
+		return nil, ErrSecurityEventRepoIsNil
+	}
+	if d.Transactor == nil {
+		return nil, ErrTransactorIsNil
+	}
+	graceperiod := d.DeletionGracePeriod
+	if graceperiod <= 0 {
+		graceperiod = DefaultDeletionGracePeriod
+	}
 	return &Account{
-		repo:           d.Repo,
-		passwordhasher: d.PasswordHasher,
+		repo:                d.Repo,
+		statushistory:       d.StatusHistory,
+		securityevents:      d.SecurityEvents,
+		transactor:          d.Transactor,
+		passwordhasher:      d.PasswordHasher,
+		loginalert:          d.LoginAlert,
+		metrics:             d.Metrics,
+		deletiongraceperiod: graceperiod,
 	}, nil
 }
 
-func (u *Account) SignIn(ctx context.Context, email, pswd string) (*entities.Account, error) {
-	account, err := u.repo.GetOneByEmail(ctx, email)
+// SignIn checks the credentials, the attempts on the existing accounts are recorded in the security log
+func (u *Account) SignIn(ctx context.Context, r *SignInRequest) (*entities.Account, error) {
+	ctx, span := tracing.Start(ctx, tracer, "Account.SignIn")
+	defer span.End()
+
+	account, err := u.repo.GetOneByEmail(ctx, r.Email)
 	if err != nil {
 		if errors.As(err, &repositories.ErrAccountNotFoundByEmail{}) {
+			if u.metrics != nil {
+				u.metrics.SignInFailed(signInFailedWrongEmail)
+			}
 			return nil, ErrEmailIsWrong
 		}
 		return nil, err
 	}
 
 	if active := account.IsActive(); !active {
-		return nil, ErrAccountIsNotActive
+		return nil, u.signInFailed(ctx, account, r, signInFailedNotActive, ErrAccountIsNotActive)
+	}
+
+	// the cleared password can't match anything, the owner has to reset it
+	if account.IsPasswordResetRequired() {
+		return nil, u.signInFailed(ctx, account, r, signInFailedResetRequired, ErrPasswordResetRequired)
 	}
 
-	err = u.passwordhasher.Compare(pswd, account.Password)
+	err = u.comparePassword(ctx, r.Password, account.Password)
 	if err != nil {
-		return nil, ErrPasswordIsWrong
+		return nil, u.signInFailed(ctx, account, r, signInFailedWrongPassword, ErrPasswordIsWrong)
 	}
 
-	return account, nil
-}
+	// the alert is checked before the sign in starts a session, so the device is compared with the previous ones
+	if u.loginalert != nil {
+		if err = u.loginalert.Check(ctx, account, r.IP, r.UserAgent); err != nil {
+			return nil, err
+		}
+	}
 
-func (u *Account) SignUp(ctx context.Context, account *entities.Account) (*entities.Account, error) {
-	isexist, err := u.repo.IsExist(ctx, account)
+	_, err = u.securityevents.Add(ctx, u.signInEvent(account, r, entities.SecurityEventSignIn, ""))
 	if err != nil {
 		return nil, err
 	}
-	if isexist {
-		return nil, ErrAccountAlreadyExist
-	}
 
-	pswdhash, err := u.passwordhasher.Hash(account.Password)
+	return account, nil
+}
+
+func (u *Account) SignUp(ctx context.Context, account *entities.Account) (*entities.Account, error) {
+	ctx, span := tracing.Start(ctx, tracer, "Account.SignUp")
+	defer span.End()
+
+	// the hash is slow, so it's computed before the transaction to not hold it open and to not repeat it on the retries
+	pswdhash, err := u.hashPassword(ctx, account.Password)
 	if err != nil {
 		return nil, err
 	}
-	account.Password = pswdhash
 
-	account.Status = entities.Active
-	newaccount, err := u.repo.Create(ctx, account)
+	var newaccount *entities.Account
+
+	err = u.transactor.WithTx(ctx, func(repos *TxRepos) error {
+		isexist, err := repos.Account.IsExist(ctx, account)
+		if err != nil {
+			return err
+		}
+		if isexist {
+			return ErrAccountAlreadyExist
+		}
+
+		newaccount, err = repos.Account.Create(ctx, &entities.Account{
+			UUID:      account.UUID,
+			Email:     account.Email,
+			Password:  pswdhash,
+			Name:      account.Name,
+			Status:    entities.Active,
+			CreatedAt: account.CreatedAt,
+			UpdatedAt: account.UpdatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		return u.accountCreated(ctx, repos.Outbox, newaccount)
+	})
 	if err != nil {
-		return nil, err
+		return nil, u.mapCreateError(err)
 	}
 
+	if u.metrics != nil {
+		u.metrics.SignedUp()
+	}
 	return newaccount, nil
 }
 
 func (u *Account) GetOneByEmail(ctx context.Context, email string) (*entities.Account, error) {
+	ctx, span := tracing.Start(ctx, tracer, "Account.GetOneByEmail")
+	defer span.End()
+
 	return u.repo.GetOneByEmail(ctx, email)
 }
 
 func (u *Account) GetOneByUUID(ctx context.Context, uuid string) (*entities.Account, error) {
+	ctx, span := tracing.Start(ctx, tracer, "Account.GetOneByUUID")
+	defer span.End()
+
 	return u.repo.GetOneByUUID(ctx, uuid)
 }
 
+// GetManyByUUIDs looks the accounts up with one query. The found accounts are returned in the order of the uuids
+// without the duplicates, the rest of the uuids are missing
+func (u *Account) GetManyByUUIDs(ctx context.Context, uuids []string) ([]*entities.Account, []string, error) {
+	ctx, span := tracing.Start(ctx, tracer, "Account.GetManyByUUIDs")
+	defer span.End()
+
+	if len(uuids) > MaxBatchGetUUIDs {
+		return nil, nil, ErrTooManyUUIDs
+	}
+
+	unique := make([]string, 0, len(uuids))
+	seen := make(map[string]struct{}, len(uuids))
+	for _, accountuuid := range uuids {
+		if _, ok := seen[accountuuid]; ok {
+			continue
+		}
+		seen[accountuuid] = struct{}{}
+		unique = append(unique, accountuuid)
+	}
+	if len(unique) == 0 {
+		return nil, nil, nil
+	}
+
+	accounts, err := u.repo.GetManyByUUIDs(ctx, unique)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byuuid := make(map[string]*entities.Account, len(accounts))
+	for _, account := range accounts {
+		byuuid[account.UUID] = account
+	}
+
+	found := make([]*entities.Account, 0, len(accounts))
+	var missing []string
+	for _, accountuuid := range unique {
+		if account, ok := byuuid[accountuuid]; ok {
+			found = append(found, account)
+		} else {
+			missing = append(missing, accountuuid)
+		}
+	}
+	return found, missing, nil
+}
+
+// Search lists the accounts for admins, they're sorted by the creation time unless filter.Sort is set
+func (u *Account) Search(ctx context.Context, filter *repositories.AccountFilter) ([]*entities.Account, error) {
+	ctx, span := tracing.Start(ctx, tracer, "Account.Search")
+	defer span.End()
+
+	search := *filter
+	if search.Sort == "" {
+		search.Sort = repositories.AccountSortCreatedAt
+	}
+	search.Limit = AccountsLimit(filter.Limit)
+	return u.repo.Find(ctx, &search)
+}
+
+// AccountsLimit is the page size for the limit, DefaultAccountsLimit if limit isn't positive and MaxAccountsLimit at most
+func AccountsLimit(limit int) int {
+	switch {
+	case limit <= 0:
+		return DefaultAccountsLimit
+	case limit > MaxAccountsLimit:
+		return MaxAccountsLimit
+	default:
+		return limit
+	}
+}
+
 func (u *Account) Create(ctx context.Context, r *AccountCreateRequest) (*entities.Account, error) {
+	ctx, span := tracing.Start(ctx, tracer, "Account.Create")
+	defer span.End()
+
 	account := u.createReq2Entity(r)
-	if isexist, err := u.repo.IsExist(ctx, account); err != nil {
+	account.Status = entities.Active
+
+	var newaccount *entities.Account
+
+	err := u.transactor.WithTx(ctx, func(repos *TxRepos) error {
+		if isexist, err := repos.Account.IsExist(ctx, account); err != nil {
+			return err
+		} else if isexist {
+			return ErrAccountAlreadyExist
+		}
+
+		var err error
+		newaccount, err = repos.Account.Create(ctx, account)
+		if err != nil {
+			return err
+		}
+		return u.accountCreated(ctx, repos.Outbox, newaccount)
+	})
+	if err != nil {
+		return nil, u.mapCreateError(err)
+	}
+
+	return newaccount, nil
+}
+
+// ChangeAccountStatus sets the status and records the change in the status history
+func (u *Account) ChangeAccountStatus(ctx context.Context, r *AccountStatusChangeRequest) (*entities.StatusChange, error) {
+	ctx, span := tracing.Start(ctx, tracer, "Account.ChangeAccountStatus")
+	defer span.End()
+
+	var change *entities.StatusChange
+
+	err := u.transactor.WithTx(ctx, func(repos *TxRepos) error {
+		account, err := u.getAccount(ctx, repos.Account, r.UUID)
+		if err != nil {
+			return err
+		}
+
+		if err = repos.Account.SetAccountStatus(ctx, r.UUID, r.Status, r.Until); err != nil {
+			return u.mapNotFoundError(err)
+		}
+
+		change, err = repos.StatusHistory.Add(ctx, &entities.StatusChange{
+			AccountUUID: r.UUID,
+			FromStatus:  account.Status,
+			ToStatus:    r.Status,
+			Reason:      r.Reason,
+			Actor:       r.Actor,
+			Until:       r.Until,
+			CreatedAt:   time.Now().Unix(),
+		})
+		if err != nil {
+			return err
+		}
+		if _, err = repos.SecurityEvent.Add(ctx, u.statusChangedEvent(change)); err != nil {
+			return err
+		}
+		if err = u.accountStatusChanged(ctx, repos.Outbox, change); err != nil {
+			return err
+		}
+		if r.Audit == nil {
+			return nil
+		}
+		return appendAuditRecord(ctx, repos.AuditLog, newAuditRecord(r.Audit, r.Actor, entities.AuditActionStatusChanged, r.UUID,
+			auditStatus{Status: account.Status, Until: account.StatusUntil},
+			auditStatus{Status: r.Status, Until: r.Until},
+		))
+	})
+	if err != nil {
 		return nil, err
-	} else if isexist {
-		return nil, ErrAccountAlreadyExist
 	}
-	account.Status = entities.Active
-	return u.repo.Create(ctx, account)
+
+	if u.metrics != nil && change.FromStatus == entities.Active && change.ToStatus != entities.Active {
+		u.metrics.LockedOut(entities.StatusName(change.ToStatus))
+	}
+	return change, nil
 }
 
-func (u *Account) ChangeAccountStatus(ctx context.Context, uuid string, status uint8) error {
+// GetStatusHistory returns up to limit latest status changes of the account,
+// limit is DefaultStatusHistoryLimit if it isn't positive and MaxStatusHistoryLimit at most
+func (u *Account) GetStatusHistory(ctx context.Context, uuid string, limit int) ([]*entities.StatusChange, error) {
+	ctx, span := tracing.Start(ctx, tracer, "Account.GetStatusHistory")
+	defer span.End()
+
 	isexist, err := u.repo.IsExistByUUID(ctx, uuid)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if !isexist {
-		return ErrAccountIsNotExist
+		return nil, ErrAccountIsNotExist
+	}
+
+	switch {
+	case limit <= 0:
+		limit = DefaultStatusHistoryLimit
+	case limit > MaxStatusHistoryLimit:
+		limit = MaxStatusHistoryLimit
+	}
+
+	return u.statushistory.GetByAccount(ctx, uuid, limit)
+}
+
+// ReactivateExpiredStatuses activates up to limit accounts which temporary status has lapsed
+func (u *Account) ReactivateExpiredStatuses(ctx context.Context, limit int) (int, error) {
+	ctx, span := tracing.Start(ctx, tracer, "Account.ReactivateExpiredStatuses")
+	defer span.End()
+
+	now := time.Now().Unix()
+
+	accounts, err := u.repo.GetStatusExpiredBefore(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	var reactivated int
+	for _, account := range accounts {
+		var changed bool
+		err = u.transactor.WithTx(ctx, func(repos *TxRepos) error {
+			changed = false
+
+			// the status may be changed concurrently since it's selected
+			current, err := repos.Account.GetOneByUUID(ctx, account.UUID)
+			if err != nil {
+				return err
+			}
+			if !current.IsStatusExpired(now) {
+				return nil
+			}
+
+			if err = repos.Account.SetAccountStatus(ctx, current.UUID, entities.Active, 0); err != nil {
+				return err
+			}
+			change, err := repos.StatusHistory.Add(ctx, &entities.StatusChange{
+				AccountUUID: current.UUID,
+				FromStatus:  current.Status,
+				ToStatus:    entities.Active,
+				Reason:      reactivationReason,
+				Actor:       entities.SystemActor,
+				CreatedAt:   now,
+			})
+			if err != nil {
+				return err
+			}
+			if _, err = repos.SecurityEvent.Add(ctx, u.statusChangedEvent(change)); err != nil {
+				return err
+			}
+			err = u.accountStatusChanged(ctx, repos.Outbox, change)
+			changed = err == nil
+			return err
+		})
+		if errors.As(err, &repositories.ErrAccountNotFoundByUUID{}) {
+			continue
+		}
+		if err != nil {
+			return reactivated, err
+		}
+		if changed {
+			reactivated++
+		}
 	}
-	err = u.repo.SetAccountStatus(ctx, uuid, status)
+
+	return reactivated, nil
+}
+
+// DeleteAccount deactivates the account and revokes its sessions at once, it can be restored
+// with ChangeAccountStatus until the grace period is over
+func (u *Account) DeleteAccount(ctx context.Context, r *AccountDeleteRequest) (*entities.Account, error) {
+	ctx, span := tracing.Start(ctx, tracer, "Account.DeleteAccount")
+	defer span.End()
+
+	var deleted *entities.Account
+
+	err := u.transactor.WithTx(ctx, func(repos *TxRepos) error {
+		account, err := u.getAccount(ctx, repos.Account, r.UUID)
+		if err != nil {
+			return err
+		}
+		if account.IsDeleted() {
+			return ErrAccountIsDeleted
+		}
+
+		now := time.Now().Unix()
+		if err = repos.Account.MarkAccountDeleted(ctx, r.UUID, now); err != nil {
+			return err
+		}
+		if err = repos.Session.RevokeAllByAccount(ctx, r.UUID, now); err != nil {
+			return err
+		}
+
+		change, err := repos.StatusHistory.Add(ctx, &entities.StatusChange{
+			AccountUUID: r.UUID,
+			FromStatus:  account.Status,
+			ToStatus:    entities.Deleted,
+			Reason:      deletionReason,
+			Actor:       r.Actor,
+			CreatedAt:   now,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err = repos.SecurityEvent.Add(ctx, u.statusChangedEvent(change)); err != nil {
+			return err
+		}
+		err = addDomainEvent(ctx, repos.Outbox, entities.DomainEventAccountDeleted, r.UUID, &accountDeletedData{
+			FromStatus: account.Status,
+			DeletedAt:  now,
+			PurgeAt:    time.Unix(now, 0).Add(u.deletiongraceperiod).Unix(),
+		})
+		if err != nil {
+			return err
+		}
+		if r.Audit != nil {
+			err = appendAuditRecord(ctx, repos.AuditLog, newAuditRecord(r.Audit, r.Actor, entities.AuditActionDeleted, r.UUID,
+				auditStatus{Status: account.Status, Until: account.StatusUntil},
+				auditStatus{Status: entities.Deleted, DeletedAt: now},
+			))
+			if err != nil {
+				return err
+			}
+		}
+
+		account.Status = entities.Deleted
+		account.StatusUntil = 0
+		account.DeletedAt = now
+		account.UpdatedAt = now
+		deleted = account
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+// PurgeTime returns when the PII of a deleted account is going to be purged
+func (u *Account) PurgeTime(account *entities.Account) time.Time {
+	return time.Unix(account.DeletedAt, 0).Add(u.deletiongraceperiod)
+}
+
+// PurgeDeletedAccounts erases the PII of up to limit accounts which grace period is over
+func (u *Account) PurgeDeletedAccounts(ctx context.Context, limit int) (int, error) {
+	ctx, span := tracing.Start(ctx, tracer, "Account.PurgeDeletedAccounts")
+	defer span.End()
+
+	before := time.Now().Add(-u.deletiongraceperiod).Unix()
+
+	accounts, err := u.repo.GetDeletedBefore(ctx, before, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	var purged int
+	for _, account := range accounts {
+		// the sessions and the security log keep the IPs and the user agents, the exports and the created events
+		// keep the email and the name, so they're erased along with the account
+		err = u.transactor.WithTx(ctx, func(repos *TxRepos) error {
+			now := time.Now().Unix()
+			if err := repos.Account.PurgeAccount(ctx, account.UUID, now); err != nil {
+				return err
+			}
+			if err := repos.Session.DeleteByAccount(ctx, account.UUID); err != nil {
+				return err
+			}
+			if err := repos.SecurityEvent.DeleteByAccount(ctx, account.UUID); err != nil {
+				return err
+			}
+			if err := repos.Export.DeleteByAccount(ctx, account.UUID); err != nil {
+				return err
+			}
+			if err := eraseAccountCreated(ctx, repos, account.UUID); err != nil {
+				return err
+			}
+			return addDomainEvent(ctx, repos.Outbox, entities.DomainEventAccountPurged, account.UUID, &accountPurgedData{PurgedAt: now})
+		})
+		// the account is restored or purged concurrently
+		if errors.As(err, &repositories.ErrAccountNotFoundByUUID{}) {
+			continue
+		}
+		if err != nil {
+			return purged, err
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+// comparePassword is traced apart, it's the slowest step of a sign in
+func (u *Account) comparePassword(ctx context.Context, password, hash string) error {
+	_, span := tracing.Start(ctx, tracer, "PasswordHasher.Compare")
+	defer span.End()
+	return u.passwordhasher.Compare(password, hash)
+}
+
+func (u *Account) hashPassword(ctx context.Context, password string) (string, error) {
+	_, span := tracing.Start(ctx, tracer, "PasswordHasher.Hash")
+	defer span.End()
+	return u.passwordhasher.Hash(password)
+}
+
+// signInFailed records the failed attempt and returns failure, the recording error is returned instead if any
+func (u *Account) signInFailed(ctx context.Context, account *entities.Account, r *SignInRequest, reason string, failure error) error {
+	if u.metrics != nil {
+		u.metrics.SignInFailed(reason)
+	}
+	_, err := u.securityevents.Add(ctx, u.signInEvent(account, r, entities.SecurityEventSignInFailed, reason))
 	if err != nil {
 		return err
 	}
-	return nil
+	return failure
+}
+
+func (u *Account) signInEvent(account *entities.Account, r *SignInRequest, eventtype uint8, details string) *entities.SecurityEvent {
+	return &entities.SecurityEvent{
+		AccountUUID: account.UUID,
+		Type:        eventtype,
+		IP:          r.IP,
+		UserAgent:   truncate(r.UserAgent, userAgentMaxLength),
+		Details:     details,
+		CreatedAt:   time.Now().Unix(),
+	}
+}
+
+// auditStatus is the audited state of the account status
+type auditStatus struct {
+	Status    uint8
+	Until     int64 `json:",omitempty"`
+	DeletedAt int64 `json:",omitempty"`
+}
+
+func (u *Account) accountCreated(ctx context.Context, outbox IOutboxRepo, account *entities.Account) error {
+	return addDomainEvent(ctx, outbox, entities.DomainEventAccountCreated, account.UUID, &accountCreatedData{
+		Email:     account.Email,
+		Name:      account.Name,
+		Status:    account.Status,
+		CreatedAt: account.CreatedAt,
+	})
+}
+
+func (u *Account) accountStatusChanged(ctx context.Context, outbox IOutboxRepo, change *entities.StatusChange) error {
+	return addDomainEvent(ctx, outbox, entities.DomainEventAccountStatusChanged, change.AccountUUID, &accountStatusChangedData{
+		FromStatus: change.FromStatus,
+		ToStatus:   change.ToStatus,
+		Until:      change.Until,
+		Reason:     change.Reason,
+		Actor:      change.Actor,
+	})
+}
+
+// statusChangedEvent doesn't disclose the actor, the security log is shown to the account owner
+func (u *Account) statusChangedEvent(change *entities.StatusChange) *entities.SecurityEvent {
+	details := entities.StatusName(change.ToStatus)
+	if change.Reason != "" {
+		details = fmt.Sprintf("%s: %s", details, change.Reason)
+	}
+	return &entities.SecurityEvent{
+		AccountUUID: change.AccountUUID,
+		Type:        entities.SecurityEventStatusChanged,
+		Details:     details,
+		CreatedAt:   change.CreatedAt,
+	}
+}
+
+// getAccount reports a missing account as ErrAccountIsNotExist
+func (u *Account) getAccount(ctx context.Context, repo IAccountRepo, uuid string) (*entities.Account, error) {
+	account, err := repo.GetOneByUUID(ctx, uuid)
+	if err != nil {
+		return nil, u.mapNotFoundError(err)
+	}
+	return account, nil
+}
+
+func (u *Account) mapNotFoundError(err error) error {
+	if errors.As(err, &repositories.ErrAccountNotFoundByUUID{}) {
+		return ErrAccountIsNotExist
+	}
+	return err
+}
+
+// mapCreateError reports the unique constraint violation of a concurrent sign up
+// the same way as an account found by IsExist
+func (u *Account) mapCreateError(err error) error {
+	if errors.As(err, &repositories.ErrAccountAlreadyExists{}) {
+		return ErrAccountAlreadyExist
+	}
+	return err
 }
 
 func (u *Account) createReq2Entity(r *AccountCreateRequest) *entities.Account {
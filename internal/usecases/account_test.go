@@ -2,20 +2,47 @@ package usecases
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
 	usecases_test "github.com/alexsibrin/runbot-auth/internal/usecases/mocks"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 	"testing"
+	"time"
 )
 
+// txStub runs fn without a transaction, ITransactor can't be mocked because of the import cycle
+type txStub struct {
+	repos *TxRepos
+}
+
+func (s *txStub) WithTx(_ context.Context, fn func(repos *TxRepos) error) error {
+	return fn(s.repos)
+}
+
+// retryingTxStub runs the transaction twice like a serialization failure retried by the transactor
+type retryingTxStub struct {
+	repos *TxRepos
+}
+
+func (s *retryingTxStub) WithTx(_ context.Context, fn func(repos *TxRepos) error) error {
+	if err := fn(s.repos); err != nil {
+		return err
+	}
+	return fn(s.repos)
+}
+
 func TestAccountInit(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
 	repomock := usecases_test.NewMockIAccountRepo(ctrl)
+	historymock := usecases_test.NewMockIStatusHistoryRepo(ctrl)
+	eventmock := usecases_test.NewMockISecurityEventRepo(ctrl)
 	hashmock := usecases_test.NewMockIPasswordHasher(ctrl)
+	txstub := &txStub{repos: &TxRepos{Account: repomock, StatusHistory: historymock}}
 
 	testCases := []struct {
 		name        string
@@ -27,11 +54,38 @@ func TestAccountInit(t *testing.T) {
 			name: "Regular valid case",
 			in: &AccountDependencies{
 				Repo:           repomock,
+				StatusHistory:  historymock,
+				SecurityEvents: eventmock,
+				Transactor:     txstub,
 				PasswordHasher: hashmock,
 			},
 			outAccount: &Account{
-				repo:           repomock,
-				passwordhasher: hashmock,
+				repo:                repomock,
+				statushistory:       historymock,
+				securityevents:      eventmock,
+				transactor:          txstub,
+				passwordhasher:      hashmock,
+				deletiongraceperiod: DefaultDeletionGracePeriod,
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "Custom deletion grace period",
+			in: &AccountDependencies{
+				Repo:                repomock,
+				StatusHistory:       historymock,
+				SecurityEvents:      eventmock,
+				Transactor:          txstub,
+				PasswordHasher:      hashmock,
+				DeletionGracePeriod: time.Hour,
+			},
+			outAccount: &Account{
+				repo:                repomock,
+				statushistory:       historymock,
+				securityevents:      eventmock,
+				transactor:          txstub,
+				passwordhasher:      hashmock,
+				deletiongraceperiod: time.Hour,
 			},
 			expectedErr: nil,
 		},
@@ -39,6 +93,7 @@ func TestAccountInit(t *testing.T) {
 			name: "Repo is nil case",
 			in: &AccountDependencies{
 				Repo:           nil,
+				Transactor:     txstub,
 				PasswordHasher: hashmock,
 			},
 			outAccount:  nil,
@@ -48,11 +103,47 @@ func TestAccountInit(t *testing.T) {
 			name: "PasswordHasher is nil case",
 			in: &AccountDependencies{
 				Repo:           repomock,
+				StatusHistory:  historymock,
+				Transactor:     txstub,
 				PasswordHasher: nil,
 			},
 			outAccount:  nil,
 			expectedErr: ErrPaswordHasherIsNil,
 		},
+		{
+			name: "StatusHistory is nil case",
+			in: &AccountDependencies{
+				Repo:           repomock,
+				SecurityEvents: eventmock,
+				Transactor:     txstub,
+				PasswordHasher: hashmock,
+			},
+			outAccount:  nil,
+			expectedErr: ErrStatusHistoryIsNil,
+		},
+		{
+			name: "SecurityEvents is nil case",
+			in: &AccountDependencies{
+				Repo:           repomock,
+				StatusHistory:  historymock,
+				Transactor:     txstub,
+				PasswordHasher: hashmock,
+			},
+			outAccount:  nil,
+			expectedErr: ErrSecurityEventRepoIsNil,
+		},
+		{
+			name: "Transactor is nil case",
+			in: &AccountDependencies{
+				Repo:           repomock,
+				StatusHistory:  historymock,
+				SecurityEvents: eventmock,
+				Transactor:     nil,
+				PasswordHasher: hashmock,
+			},
+			outAccount:  nil,
+			expectedErr: ErrTransactorIsNil,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -71,9 +162,26 @@ func TestSignIn(t *testing.T) {
 
 	mockRepo := usecases_test.NewMockIAccountRepo(ctrl)
 	mockHasher := usecases_test.NewMockIPasswordHasher(ctrl)
+	mockEvents := usecases_test.NewMockISecurityEventRepo(ctrl)
+	mockAlert := usecases_test.NewMockILoginAlert(ctrl)
 
 	ctx := context.TODO()
-	testAccount := &entities.Account{Email: "test@example.com", Password: "hashedpassword"}
+	testAccount := &entities.Account{UUID: "accountuuid", Email: "test@example.com", Password: "hashedpassword"}
+	blockedAccount := &entities.Account{UUID: "accountuuid", Email: "test@example.com", Password: "hashedpassword", Status: entities.Blocked}
+	resetAccount := &entities.Account{UUID: "accountuuid", Email: "test@example.com"}
+
+	// expectEvent expects the sign in event of the test client
+	expectEvent := func(eventtype uint8, details string) *gomock.Call {
+		return mockEvents.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *entities.SecurityEvent) (*entities.SecurityEvent, error) {
+			assert.Equal(t, "accountuuid", event.AccountUUID)
+			assert.Equal(t, eventtype, event.Type)
+			assert.Equal(t, "10.0.0.1", event.IP)
+			assert.Equal(t, "someagent", event.UserAgent)
+			assert.Equal(t, details, event.Details)
+			assert.NotZero(t, event.CreatedAt)
+			return event, nil
+		})
+	}
 
 	tests := []struct {
 		name        string
@@ -89,9 +197,54 @@ func TestSignIn(t *testing.T) {
 			setupMocks: func() {
 				mockRepo.EXPECT().GetOneByEmail(ctx, "test@example.com").Return(testAccount, nil)
 				mockHasher.EXPECT().Compare("correctpassword", "hashedpassword").Return(nil)
+				mockAlert.EXPECT().Check(ctx, testAccount, "10.0.0.1", "someagent").Return(nil)
+				expectEvent(entities.SecurityEventSignIn, "")
 			},
 			expectedErr: nil,
 		},
+		{
+			name:  "Login alert fails",
+			email: "test@example.com",
+			pswd:  "correctpassword",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetOneByEmail(ctx, "test@example.com").Return(testAccount, nil)
+				mockHasher.EXPECT().Compare("correctpassword", "hashedpassword").Return(nil)
+				mockAlert.EXPECT().Check(ctx, testAccount, "10.0.0.1", "someagent").Return(errors.New("database error"))
+			},
+			expectedErr: errors.New("database error"),
+		},
+		{
+			name:  "Password reset is required",
+			email: "test@example.com",
+			pswd:  "correctpassword",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetOneByEmail(ctx, "test@example.com").Return(resetAccount, nil)
+				expectEvent(entities.SecurityEventSignInFailed, "password reset is required")
+			},
+			expectedErr: ErrPasswordResetRequired,
+		},
+		{
+			name:  "Security event is not recorded",
+			email: "test@example.com",
+			pswd:  "correctpassword",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetOneByEmail(ctx, "test@example.com").Return(testAccount, nil)
+				mockHasher.EXPECT().Compare("correctpassword", "hashedpassword").Return(nil)
+				mockAlert.EXPECT().Check(ctx, testAccount, "10.0.0.1", "someagent").Return(nil)
+				mockEvents.EXPECT().Add(ctx, gomock.Any()).Return(nil, errors.New("database error"))
+			},
+			expectedErr: errors.New("database error"),
+		},
+		{
+			name:  "Account is not active",
+			email: "test@example.com",
+			pswd:  "correctpassword",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetOneByEmail(ctx, "test@example.com").Return(blockedAccount, nil)
+				expectEvent(entities.SecurityEventSignInFailed, "account is not active")
+			},
+			expectedErr: ErrAccountIsNotActive,
+		},
 		{
 			name:  "Incorrect Email",
 			email: "wrong@example.com",
@@ -108,6 +261,7 @@ func TestSignIn(t *testing.T) {
 			setupMocks: func() {
 				mockRepo.EXPECT().GetOneByEmail(ctx, "test@example.com").Return(testAccount, nil)
 				mockHasher.EXPECT().Compare("wrongpassword", "hashedpassword").Return(errors.New("password mismatch"))
+				expectEvent(entities.SecurityEventSignInFailed, "wrong password")
 			},
 			expectedErr: ErrPasswordIsWrong,
 		},
@@ -127,6 +281,7 @@ func TestSignIn(t *testing.T) {
 			setupMocks: func() {
 				mockRepo.EXPECT().GetOneByEmail(ctx, "test@example.com").Return(testAccount, nil)
 				mockHasher.EXPECT().Compare("correctpassword", "hashedpassword").Return(errors.New("hasher error"))
+				expectEvent(entities.SecurityEventSignInFailed, "wrong password")
 			},
 			expectedErr: ErrPasswordIsWrong,
 		},
@@ -135,8 +290,13 @@ func TestSignIn(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.setupMocks()
-			account := &Account{repo: mockRepo, passwordhasher: mockHasher}
-			_, err := account.SignIn(ctx, tc.email, tc.pswd)
+			account := &Account{repo: mockRepo, passwordhasher: mockHasher, securityevents: mockEvents, loginalert: mockAlert}
+			_, err := account.SignIn(ctx, &SignInRequest{
+				Email:     tc.email,
+				Password:  tc.pswd,
+				IP:        "10.0.0.1",
+				UserAgent: "someagent",
+			})
 
 			t.Log(err)
 
@@ -270,9 +430,11 @@ func TestCreate(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := usecases_test.NewMockIAccountRepo(ctrl)
+	mockOutbox := usecases_test.NewMockIOutboxRepo(ctrl)
 
 	ctx := context.TODO()
 	testAccount := &entities.Account{
+		UUID:     "validuuid",
 		Email:    "myemail",
 		Name:     "My name is",
 		Password: "mypassword",
@@ -295,9 +457,20 @@ func TestCreate(t *testing.T) {
 			setupMocks: func() {
 				mockRepo.EXPECT().IsExist(ctx, gomock.Any()).Return(false, nil)
 				mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(testAccount, nil)
+				expectDomainEvent(t, mockOutbox, entities.DomainEventAccountCreated, "validuuid")
 			},
 			expectedErr: nil,
 		},
+		{
+			name: "Error on Adding Event",
+			req:  testReq,
+			setupMocks: func() {
+				mockRepo.EXPECT().IsExist(ctx, gomock.Any()).Return(false, nil)
+				mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(testAccount, nil)
+				mockOutbox.EXPECT().Add(ctx, gomock.Any()).Return(nil, errors.New("outbox error"))
+			},
+			expectedErr: errors.New("outbox error"),
+		},
 		{
 			name: "Account Already Exists",
 			req:  testReq,
@@ -323,12 +496,21 @@ func TestCreate(t *testing.T) {
 			},
 			expectedErr: errors.New("create error"),
 		},
+		{
+			name: "Account Is Created Concurrently",
+			req:  testReq,
+			setupMocks: func() {
+				mockRepo.EXPECT().IsExist(ctx, gomock.Any()).Return(false, nil)
+				mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil, repositories.NewErrAccountAlreadyExists(testReq.Email))
+			},
+			expectedErr: ErrAccountAlreadyExist,
+		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.setupMocks()
-			account := &Account{repo: mockRepo}
+			account := &Account{repo: mockRepo, transactor: &txStub{repos: &TxRepos{Account: mockRepo, Outbox: mockOutbox}}}
 			acc, err := account.Create(ctx, tc.req)
 
 			if tc.expectedErr != nil {
@@ -342,12 +524,63 @@ func TestCreate(t *testing.T) {
 	}
 }
 
+func TestSignIn_Metrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockRepo := usecases_test.NewMockIAccountRepo(ctrl)
+	mockHasher := usecases_test.NewMockIPasswordHasher(ctrl)
+	mockEvents := usecases_test.NewMockISecurityEventRepo(ctrl)
+	mockMetrics := usecases_test.NewMockIMetrics(ctrl)
+
+	ctx := context.TODO()
+	testAccount := &entities.Account{UUID: "accountuuid", Email: "test@example.com", Password: "hashedpassword"}
+
+	tests := []struct {
+		name       string
+		setupMocks func()
+	}{
+		{
+			name: "Successful Sign-In isn't counted",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetOneByEmail(ctx, "test@example.com").Return(testAccount, nil)
+				mockHasher.EXPECT().Compare("password", "hashedpassword").Return(nil)
+				mockEvents.EXPECT().Add(ctx, gomock.Any()).Return(&entities.SecurityEvent{}, nil)
+			},
+		},
+		{
+			name: "Incorrect Email",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetOneByEmail(ctx, "test@example.com").Return(nil, repositories.ErrAccountNotFoundByEmail{})
+				mockMetrics.EXPECT().SignInFailed("wrong email")
+			},
+		},
+		{
+			name: "Incorrect Password",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetOneByEmail(ctx, "test@example.com").Return(testAccount, nil)
+				mockHasher.EXPECT().Compare("password", "hashedpassword").Return(errors.New("password mismatch"))
+				mockEvents.EXPECT().Add(ctx, gomock.Any()).Return(&entities.SecurityEvent{}, nil)
+				mockMetrics.EXPECT().SignInFailed("wrong password")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			account := &Account{repo: mockRepo, passwordhasher: mockHasher, securityevents: mockEvents, metrics: mockMetrics}
+			_, _ = account.SignIn(ctx, &SignInRequest{Email: "test@example.com", Password: "password"})
+		})
+	}
+}
+
 func TestSignUp(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockRepo := usecases_test.NewMockIAccountRepo(ctrl)
 	mockHasher := usecases_test.NewMockIPasswordHasher(ctrl)
+	mockOutbox := usecases_test.NewMockIOutboxRepo(ctrl)
 
 	ctx := context.TODO()
 	testAccount := &entities.Account{Email: "test@example.com", Password: "password"} // Adjust as needed
@@ -362,9 +595,18 @@ func TestSignUp(t *testing.T) {
 			name:    "Successful Sign-Up",
 			account: testAccount,
 			setupMocks: func() {
-				mockRepo.EXPECT().IsExist(ctx, testAccount).Return(false, nil)
 				mockHasher.EXPECT().Hash(testAccount.Password).Return("hashedpassword", nil)
-				mockRepo.EXPECT().Create(ctx, testAccount).Return(testAccount, nil)
+				mockRepo.EXPECT().IsExist(ctx, testAccount).Return(false, nil)
+				mockRepo.EXPECT().Create(ctx, &entities.Account{
+					Email:    testAccount.Email,
+					Password: "hashedpassword",
+					Status:   entities.Active,
+				}).Return(testAccount, nil)
+				mockOutbox.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *entities.DomainEvent) (*entities.DomainEvent, error) {
+					assert.Equal(t, entities.DomainEventAccountCreated, event.Type)
+					assert.JSONEq(t, `{"Email":"test@example.com","Name":"","Status":0,"CreatedAt":0}`, event.Payload)
+					return event, nil
+				})
 			},
 			expectedErr: nil,
 		},
@@ -372,6 +614,7 @@ func TestSignUp(t *testing.T) {
 			name:    "Account Already Exists",
 			account: testAccount,
 			setupMocks: func() {
+				mockHasher.EXPECT().Hash(testAccount.Password).Return("hashedpassword", nil)
 				mockRepo.EXPECT().IsExist(ctx, testAccount).Return(true, nil)
 			},
 			expectedErr: ErrAccountAlreadyExist,
@@ -380,6 +623,7 @@ func TestSignUp(t *testing.T) {
 			name:    "Error on Checking Existence",
 			account: testAccount,
 			setupMocks: func() {
+				mockHasher.EXPECT().Hash(testAccount.Password).Return("hashedpassword", nil)
 				mockRepo.EXPECT().IsExist(ctx, testAccount).Return(false, errors.New("existence check error"))
 			},
 			expectedErr: errors.New("existence check error"),
@@ -388,7 +632,6 @@ func TestSignUp(t *testing.T) {
 			name:    "Error on Hashing Password",
 			account: testAccount,
 			setupMocks: func() {
-				mockRepo.EXPECT().IsExist(ctx, testAccount).Return(false, nil)
 				mockHasher.EXPECT().Hash(testAccount.Password).Return("", errors.New("hash error"))
 			},
 			expectedErr: errors.New("hash error"),
@@ -397,18 +640,28 @@ func TestSignUp(t *testing.T) {
 			name:    "Error on Creating Account",
 			account: testAccount,
 			setupMocks: func() {
-				mockRepo.EXPECT().IsExist(ctx, testAccount).Return(false, nil)
 				mockHasher.EXPECT().Hash(testAccount.Password).Return("hashedpassword", nil)
-				mockRepo.EXPECT().Create(ctx, testAccount).Return(nil, errors.New("create error"))
+				mockRepo.EXPECT().IsExist(ctx, testAccount).Return(false, nil)
+				mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil, errors.New("create error"))
 			},
 			expectedErr: errors.New("create error"),
 		},
+		{
+			name:    "Account Is Created Concurrently",
+			account: testAccount,
+			setupMocks: func() {
+				mockHasher.EXPECT().Hash(testAccount.Password).Return("hashedpassword", nil)
+				mockRepo.EXPECT().IsExist(ctx, testAccount).Return(false, nil)
+				mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil, repositories.NewErrAccountAlreadyExists(testAccount.Email))
+			},
+			expectedErr: ErrAccountAlreadyExist,
+		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.setupMocks()
-			account := &Account{repo: mockRepo, passwordhasher: mockHasher}
+			account := &Account{repo: mockRepo, transactor: &txStub{repos: &TxRepos{Account: mockRepo, Outbox: mockOutbox}}, passwordhasher: mockHasher}
 			acc, err := account.SignUp(ctx, tc.account)
 
 			if tc.expectedErr != nil {
@@ -420,6 +673,17 @@ func TestSignUp(t *testing.T) {
 			}
 		})
 	}
+
+	t.Run("Password isn't hashed again on the retries", func(t *testing.T) {
+		mockHasher.EXPECT().Hash(testAccount.Password).Return("hashedpassword", nil).Times(1)
+		mockRepo.EXPECT().IsExist(ctx, testAccount).Return(false, nil).Times(2)
+		mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(testAccount, nil).Times(2)
+		mockOutbox.EXPECT().Add(ctx, gomock.Any()).Return(&entities.DomainEvent{}, nil).Times(2)
+
+		account := &Account{repo: mockRepo, transactor: &retryingTxStub{repos: &TxRepos{Account: mockRepo, Outbox: mockOutbox}}, passwordhasher: mockHasher}
+		_, err := account.SignUp(ctx, testAccount)
+		assert.NoError(t, err)
+	})
 }
 
 func TestAccount_ChangeAccountStatus(t *testing.T) {
@@ -427,50 +691,537 @@ func TestAccount_ChangeAccountStatus(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := usecases_test.NewMockIAccountRepo(ctrl)
+	mockHistory := usecases_test.NewMockIStatusHistoryRepo(ctrl)
+	mockEvents := usecases_test.NewMockISecurityEventRepo(ctrl)
+	mockAudit := usecases_test.NewMockIAuditLogRepo(ctrl)
+	mockOutbox := usecases_test.NewMockIOutboxRepo(ctrl)
+	ctx := context.TODO()
+
+	until := time.Now().Add(time.Hour).Unix()
+	request := &AccountStatusChangeRequest{
+		UUID:   "validuuid",
+		Status: entities.Suspended,
+		Reason: "spam",
+		Actor:  "admin",
+		Until:  until,
+		Audit:  &AuditMetadata{Method: "/Account/SetStatus", IP: "10.0.0.1", UserAgent: "grpc-go/1.61.0"},
+	}
+
+	testCases := []struct {
+		name        string
+		setupMocks  func()
+		expectedErr error
+	}{
+		{
+			name: "Valid case",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetOneByUUID(ctx, "validuuid").Return(&entities.Account{UUID: "validuuid", Status: entities.Active}, nil)
+				mockRepo.EXPECT().SetAccountStatus(ctx, "validuuid", entities.Suspended, until).Return(nil)
+				mockHistory.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, change *entities.StatusChange) (*entities.StatusChange, error) {
+					assert.Equal(t, entities.Active, change.FromStatus)
+					assert.Equal(t, entities.Suspended, change.ToStatus)
+					assert.Equal(t, "spam", change.Reason)
+					assert.Equal(t, "admin", change.Actor)
+					assert.Equal(t, until, change.Until)
+					change.ID = 1
+					return change, nil
+				})
+				mockEvents.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *entities.SecurityEvent) (*entities.SecurityEvent, error) {
+					assert.Equal(t, "validuuid", event.AccountUUID)
+					assert.Equal(t, entities.SecurityEventStatusChanged, event.Type)
+					assert.Equal(t, "suspended: spam", event.Details)
+					return event, nil
+				})
+				mockOutbox.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *entities.DomainEvent) (*entities.DomainEvent, error) {
+					assert.Equal(t, entities.DomainEventAccountStatusChanged, event.Type)
+					assert.Equal(t, "validuuid", event.AccountUUID)
+					assert.JSONEq(t, fmt.Sprintf(`{"FromStatus":%d,"ToStatus":%d,"Until":%d,"Reason":"spam","Actor":"admin"}`,
+						entities.Active, entities.Suspended, until), event.Payload)
+					return event, nil
+				})
+				mockAudit.EXPECT().Last(ctx).Return(&entities.AuditRecord{ID: 1, Hash: "prevhash"}, nil)
+				mockAudit.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, record *entities.AuditRecord) (*entities.AuditRecord, error) {
+					assert.Equal(t, "admin", record.Actor)
+					assert.Equal(t, entities.AuditActionStatusChanged, record.Action)
+					assert.Equal(t, "validuuid", record.TargetUUID)
+					assert.Equal(t, `{"Status":0}`, record.Before)
+					assert.Equal(t, fmt.Sprintf(`{"Status":%d,"Until":%d}`, entities.Suspended, until), record.After)
+					assert.Equal(t, "/Account/SetStatus", record.Method)
+					assert.Equal(t, "10.0.0.1", record.IP)
+					assert.Equal(t, "grpc-go/1.61.0", record.UserAgent)
+					assert.Equal(t, "prevhash", record.PrevHash)
+					assert.Equal(t, record.ComputeHash(), record.Hash)
+					record.ID = 2
+					return record, nil
+				})
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "Account is not exist",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetOneByUUID(ctx, "validuuid").Return(nil, repositories.NewErrAccountNotFoundByUUID("validuuid"))
+			},
+			expectedErr: ErrAccountIsNotExist,
+		},
+		{
+			name: "Account is purged",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetOneByUUID(ctx, "validuuid").Return(&entities.Account{UUID: "validuuid", Status: entities.Deleted, PurgedAt: 1}, nil)
+				mockRepo.EXPECT().SetAccountStatus(ctx, "validuuid", entities.Suspended, until).Return(repositories.NewErrAccountNotFoundByUUID("validuuid"))
+			},
+			expectedErr: ErrAccountIsNotExist,
+		},
+		{
+			name: "Repo error SetAccountStatus",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetOneByUUID(ctx, "validuuid").Return(&entities.Account{UUID: "validuuid", Status: entities.Active}, nil)
+				mockRepo.EXPECT().SetAccountStatus(ctx, "validuuid", entities.Suspended, until).Return(fmt.Errorf("some repo error"))
+			},
+			expectedErr: fmt.Errorf("some repo error"),
+		},
+		{
+			name: "Repo error Add",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetOneByUUID(ctx, "validuuid").Return(&entities.Account{UUID: "validuuid", Status: entities.Active}, nil)
+				mockRepo.EXPECT().SetAccountStatus(ctx, "validuuid", entities.Suspended, until).Return(nil)
+				mockHistory.EXPECT().Add(ctx, gomock.Any()).Return(nil, fmt.Errorf("some repo error"))
+			},
+			expectedErr: fmt.Errorf("some repo error"),
+		},
+		{
+			name: "Repo error security event Add",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetOneByUUID(ctx, "validuuid").Return(&entities.Account{UUID: "validuuid", Status: entities.Active}, nil)
+				mockRepo.EXPECT().SetAccountStatus(ctx, "validuuid", entities.Suspended, until).Return(nil)
+				mockHistory.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, change *entities.StatusChange) (*entities.StatusChange, error) {
+					return change, nil
+				})
+				mockEvents.EXPECT().Add(ctx, gomock.Any()).Return(nil, fmt.Errorf("some repo error"))
+			},
+			expectedErr: fmt.Errorf("some repo error"),
+		},
+		{
+			name: "Repo error audit log Add",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetOneByUUID(ctx, "validuuid").Return(&entities.Account{UUID: "validuuid", Status: entities.Active}, nil)
+				mockRepo.EXPECT().SetAccountStatus(ctx, "validuuid", entities.Suspended, until).Return(nil)
+				mockHistory.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, change *entities.StatusChange) (*entities.StatusChange, error) {
+					return change, nil
+				})
+				mockEvents.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *entities.SecurityEvent) (*entities.SecurityEvent, error) {
+					return event, nil
+				})
+				expectDomainEvent(t, mockOutbox, entities.DomainEventAccountStatusChanged, "validuuid")
+				mockAudit.EXPECT().Last(ctx).Return(nil, nil)
+				mockAudit.EXPECT().Add(ctx, gomock.Any()).Return(nil, fmt.Errorf("some repo error"))
+			},
+			expectedErr: fmt.Errorf("some repo error"),
+		},
+		{
+			name: "Repo error outbox Add",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetOneByUUID(ctx, "validuuid").Return(&entities.Account{UUID: "validuuid", Status: entities.Active}, nil)
+				mockRepo.EXPECT().SetAccountStatus(ctx, "validuuid", entities.Suspended, until).Return(nil)
+				mockHistory.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, change *entities.StatusChange) (*entities.StatusChange, error) {
+					return change, nil
+				})
+				mockEvents.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *entities.SecurityEvent) (*entities.SecurityEvent, error) {
+					return event, nil
+				})
+				mockOutbox.EXPECT().Add(ctx, gomock.Any()).Return(nil, fmt.Errorf("some repo error"))
+			},
+			expectedErr: fmt.Errorf("some repo error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			account := &Account{
+				repo:          mockRepo,
+				statushistory: mockHistory,
+				transactor:    &txStub{repos: &TxRepos{Account: mockRepo, StatusHistory: mockHistory, SecurityEvent: mockEvents, AuditLog: mockAudit, Outbox: mockOutbox}},
+			}
+			change, err := account.ChangeAccountStatus(ctx, request)
+
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), change.ID)
+			assert.True(t, change.IsTemporary())
+		})
+	}
+}
+
+func TestAccount_GetStatusHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := usecases_test.NewMockIAccountRepo(ctrl)
+	mockHistory := usecases_test.NewMockIStatusHistoryRepo(ctrl)
+	ctx := context.TODO()
+
+	changes := []*entities.StatusChange{{ID: 2}, {ID: 1}}
+
+	testCases := []struct {
+		name        string
+		limit       int
+		setupMocks  func()
+		expectedErr error
+	}{
+		{
+			name:  "Default limit",
+			limit: 0,
+			setupMocks: func() {
+				mockRepo.EXPECT().IsExistByUUID(ctx, "validuuid").Return(true, nil)
+				mockHistory.EXPECT().GetByAccount(ctx, "validuuid", DefaultStatusHistoryLimit).Return(changes, nil)
+			},
+		},
+		{
+			name:  "Limit is too big",
+			limit: MaxStatusHistoryLimit + 1,
+			setupMocks: func() {
+				mockRepo.EXPECT().IsExistByUUID(ctx, "validuuid").Return(true, nil)
+				mockHistory.EXPECT().GetByAccount(ctx, "validuuid", MaxStatusHistoryLimit).Return(changes, nil)
+			},
+		},
+		{
+			name:  "Account is not exist",
+			limit: 10,
+			setupMocks: func() {
+				mockRepo.EXPECT().IsExistByUUID(ctx, "validuuid").Return(false, nil)
+			},
+			expectedErr: ErrAccountIsNotExist,
+		},
+		{
+			name:  "Repo error GetByAccount",
+			limit: 10,
+			setupMocks: func() {
+				mockRepo.EXPECT().IsExistByUUID(ctx, "validuuid").Return(true, nil)
+				mockHistory.EXPECT().GetByAccount(ctx, "validuuid", 10).Return(nil, fmt.Errorf("some repo error"))
+			},
+			expectedErr: fmt.Errorf("some repo error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			account := &Account{repo: mockRepo, statushistory: mockHistory}
+			result, err := account.GetStatusHistory(ctx, "validuuid", tc.limit)
+
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, changes, result)
+		})
+	}
+}
+
+func TestAccount_GetManyByUUIDs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := usecases_test.NewMockIAccountRepo(ctrl)
+	ctx := context.TODO()
+	account := &Account{repo: mockRepo}
+
+	bob := &entities.Account{UUID: "bobuuid", Email: "bob@example.com"}
+	alice := &entities.Account{UUID: "aliceuuid", Email: "alice@example.com"}
+
+	testCases := []struct {
+		name            string
+		uuids           []string
+		setupMocks      func()
+		expectedFound   []*entities.Account
+		expectedMissing []string
+		expectedErr     error
+	}{
+		{
+			name:  "Found and missing in the order of the uuids",
+			uuids: []string{"aliceuuid", "lostuuid", "bobuuid", "aliceuuid"},
+			setupMocks: func() {
+				mockRepo.EXPECT().GetManyByUUIDs(ctx, []string{"aliceuuid", "lostuuid", "bobuuid"}).Return([]*entities.Account{bob, alice}, nil)
+			},
+			expectedFound:   []*entities.Account{alice, bob},
+			expectedMissing: []string{"lostuuid"},
+		},
+		{
+			name:       "No uuids",
+			setupMocks: func() {},
+		},
+		{
+			name:        "Too many uuids",
+			uuids:       make([]string, MaxBatchGetUUIDs+1),
+			setupMocks:  func() {},
+			expectedErr: ErrTooManyUUIDs,
+		},
+		{
+			name:  "Repo error",
+			uuids: []string{"bobuuid"},
+			setupMocks: func() {
+				mockRepo.EXPECT().GetManyByUUIDs(ctx, []string{"bobuuid"}).Return(nil, fmt.Errorf("some repo error"))
+			},
+			expectedErr: fmt.Errorf("some repo error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			found, missing, err := account.GetManyByUUIDs(ctx, tc.uuids)
+
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedFound, found)
+			assert.Equal(t, tc.expectedMissing, missing)
+		})
+	}
+}
+
+func TestAccount_Search(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := usecases_test.NewMockIAccountRepo(ctrl)
+	ctx := context.TODO()
+	account := &Account{repo: mockRepo}
+	accounts := []*entities.Account{{UUID: "bobuuid"}}
+
+	testCases := []struct {
+		name     string
+		in       *repositories.AccountFilter
+		expected *repositories.AccountFilter
+	}{
+		{
+			name:     "Defaults",
+			in:       &repositories.AccountFilter{EmailPrefix: "bob"},
+			expected: &repositories.AccountFilter{EmailPrefix: "bob", Sort: repositories.AccountSortCreatedAt, Limit: DefaultAccountsLimit},
+		},
+		{
+			name:     "Limit is too big",
+			in:       &repositories.AccountFilter{Sort: repositories.AccountSortEmail, Descending: true, Limit: MaxAccountsLimit + 1},
+			expected: &repositories.AccountFilter{Sort: repositories.AccountSortEmail, Descending: true, Limit: MaxAccountsLimit},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo.EXPECT().Find(ctx, tc.expected).Return(accounts, nil)
+
+			result, err := account.Search(ctx, tc.in)
+			assert.NoError(t, err)
+			assert.Equal(t, accounts, result)
+		})
+	}
+}
+
+func TestAccount_ReactivateExpiredStatuses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := usecases_test.NewMockIAccountRepo(ctrl)
+	mockHistory := usecases_test.NewMockIStatusHistoryRepo(ctrl)
+	mockEvents := usecases_test.NewMockISecurityEventRepo(ctrl)
+	mockOutbox := usecases_test.NewMockIOutboxRepo(ctrl)
+	ctx := context.TODO()
+
+	lapsed := time.Now().Add(-time.Minute).Unix()
+	expired := []*entities.Account{
+		{UUID: "first", Status: entities.Suspended, StatusUntil: lapsed},
+		{UUID: "second", Status: entities.Blocked, StatusUntil: lapsed},
+	}
+
+	testCases := []struct {
+		name                string
+		setupMocks          func()
+		expectedReactivated int
+		expectedErr         error
+	}{
+		{
+			name: "Valid case",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetStatusExpiredBefore(ctx, gomock.Any(), 10).Return(expired, nil)
+				for _, account := range expired {
+					mockRepo.EXPECT().GetOneByUUID(ctx, account.UUID).Return(account, nil)
+					mockRepo.EXPECT().SetAccountStatus(ctx, account.UUID, entities.Active, int64(0)).Return(nil)
+				}
+				mockHistory.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, change *entities.StatusChange) (*entities.StatusChange, error) {
+					assert.Equal(t, entities.Active, change.ToStatus)
+					assert.Equal(t, entities.SystemActor, change.Actor)
+					return change, nil
+				}).Times(2)
+				mockEvents.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *entities.SecurityEvent) (*entities.SecurityEvent, error) {
+					assert.Equal(t, "active: temporary status lapsed", event.Details)
+					return event, nil
+				}).Times(2)
+				expectDomainEvent(t, mockOutbox, entities.DomainEventAccountStatusChanged, "first")
+				expectDomainEvent(t, mockOutbox, entities.DomainEventAccountStatusChanged, "second")
+			},
+			expectedReactivated: 2,
+		},
+		{
+			name: "Status is changed concurrently",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetStatusExpiredBefore(ctx, gomock.Any(), 10).Return(expired, nil)
+				mockRepo.EXPECT().GetOneByUUID(ctx, "first").Return(&entities.Account{UUID: "first", Status: entities.Blocked}, nil)
+				mockRepo.EXPECT().GetOneByUUID(ctx, "second").Return(nil, repositories.NewErrAccountNotFoundByUUID("second"))
+			},
+			expectedReactivated: 0,
+		},
+		{
+			name: "Repo error GetStatusExpiredBefore",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetStatusExpiredBefore(ctx, gomock.Any(), 10).Return(nil, fmt.Errorf("some repo error"))
+			},
+			expectedErr: fmt.Errorf("some repo error"),
+		},
+		{
+			name: "Repo error Add",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetStatusExpiredBefore(ctx, gomock.Any(), 10).Return(expired, nil)
+				mockRepo.EXPECT().GetOneByUUID(ctx, "first").Return(expired[0], nil)
+				mockRepo.EXPECT().SetAccountStatus(ctx, "first", entities.Active, int64(0)).Return(nil)
+				mockHistory.EXPECT().Add(ctx, gomock.Any()).Return(nil, fmt.Errorf("some repo error"))
+			},
+			expectedErr: fmt.Errorf("some repo error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			account := &Account{
+				repo:          mockRepo,
+				statushistory: mockHistory,
+				transactor:    &txStub{repos: &TxRepos{Account: mockRepo, StatusHistory: mockHistory, SecurityEvent: mockEvents, Outbox: mockOutbox}},
+			}
+			reactivated, err := account.ReactivateExpiredStatuses(ctx, 10)
+
+			assert.Equal(t, tc.expectedReactivated, reactivated)
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestAccount_DeleteAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := usecases_test.NewMockIAccountRepo(ctrl)
+	mockHistory := usecases_test.NewMockIStatusHistoryRepo(ctrl)
+	mockSession := usecases_test.NewMockISessionRepo(ctrl)
+	mockEvents := usecases_test.NewMockISecurityEventRepo(ctrl)
+	mockAudit := usecases_test.NewMockIAuditLogRepo(ctrl)
+	mockOutbox := usecases_test.NewMockIOutboxRepo(ctrl)
 	ctx := context.TODO()
 
 	testCases := []struct {
 		name        string
 		uuid        string
-		status      uint8
+		audit       *AuditMetadata
 		setupMocks  func()
 		expectedErr error
 	}{
 		{
-			name:   "Valid case",
-			uuid:   "validuuid",
-			status: 1,
+			name: "Valid case",
+			uuid: "validuuid",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetOneByUUID(ctx, "validuuid").Return(&entities.Account{UUID: "validuuid", Status: entities.Active}, nil)
+				mockRepo.EXPECT().MarkAccountDeleted(ctx, "validuuid", gomock.Any()).Return(nil)
+				mockSession.EXPECT().RevokeAllByAccount(ctx, "validuuid", gomock.Any()).Return(nil)
+				mockHistory.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, change *entities.StatusChange) (*entities.StatusChange, error) {
+					assert.Equal(t, entities.Deleted, change.ToStatus)
+					assert.Equal(t, "validuuid", change.Actor)
+					return change, nil
+				})
+				mockEvents.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *entities.SecurityEvent) (*entities.SecurityEvent, error) {
+					assert.Equal(t, entities.SecurityEventStatusChanged, event.Type)
+					assert.Equal(t, "deleted: account deletion", event.Details)
+					return event, nil
+				})
+				mockOutbox.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *entities.DomainEvent) (*entities.DomainEvent, error) {
+					assert.Equal(t, entities.DomainEventAccountDeleted, event.Type)
+					var data accountDeletedData
+					assert.NoError(t, json.Unmarshal([]byte(event.Payload), &data))
+					assert.Equal(t, entities.Active, data.FromStatus)
+					assert.Equal(t, data.DeletedAt+int64(time.Hour.Seconds()), data.PurgeAt)
+					return event, nil
+				})
+			},
+			expectedErr: nil,
+		},
+		{
+			name:  "Admin deletion is audited",
+			uuid:  "validuuid",
+			audit: &AuditMetadata{Method: "/Account/DeleteAccount", IP: "10.0.0.1"},
 			setupMocks: func() {
-				mockRepo.EXPECT().IsExistByUUID(ctx, gomock.Any()).Return(true, nil)
-				mockRepo.EXPECT().SetAccountStatus(ctx, gomock.Any(), gomock.Any()).Return(nil)
+				mockRepo.EXPECT().GetOneByUUID(ctx, "validuuid").Return(&entities.Account{UUID: "validuuid", Status: entities.Blocked}, nil)
+				mockRepo.EXPECT().MarkAccountDeleted(ctx, "validuuid", gomock.Any()).Return(nil)
+				mockSession.EXPECT().RevokeAllByAccount(ctx, "validuuid", gomock.Any()).Return(nil)
+				mockHistory.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, change *entities.StatusChange) (*entities.StatusChange, error) {
+					return change, nil
+				})
+				mockEvents.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *entities.SecurityEvent) (*entities.SecurityEvent, error) {
+					return event, nil
+				})
+				expectDomainEvent(t, mockOutbox, entities.DomainEventAccountDeleted, "validuuid")
+				mockAudit.EXPECT().Last(ctx).Return(nil, nil)
+				mockAudit.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, record *entities.AuditRecord) (*entities.AuditRecord, error) {
+					assert.Equal(t, entities.AuditActionDeleted, record.Action)
+					assert.Equal(t, fmt.Sprintf(`{"Status":%d}`, entities.Blocked), record.Before)
+					assert.Contains(t, record.After, fmt.Sprintf(`{"Status":%d,"DeletedAt":`, entities.Deleted))
+					assert.Empty(t, record.PrevHash)
+					assert.Equal(t, record.ComputeHash(), record.Hash)
+					return record, nil
+				})
 			},
 			expectedErr: nil,
 		},
 		{
-			name:   "Account is not exist",
-			uuid:   "validuuid",
-			status: 1,
+			name: "Account is not exist",
+			uuid: "validuuid",
 			setupMocks: func() {
-				mockRepo.EXPECT().IsExistByUUID(ctx, gomock.Any()).Return(false, nil)
+				mockRepo.EXPECT().GetOneByUUID(ctx, "validuuid").Return(nil, repositories.NewErrAccountNotFoundByUUID("validuuid"))
 			},
 			expectedErr: ErrAccountIsNotExist,
 		},
 		{
-			name:   "Repo error IsExistByUUID",
-			uuid:   "validuuid",
-			status: 1,
+			name: "Account is already deleted",
+			uuid: "validuuid",
 			setupMocks: func() {
-				mockRepo.EXPECT().IsExistByUUID(ctx, gomock.Any()).Return(false, fmt.Errorf("some repo error"))
+				mockRepo.EXPECT().GetOneByUUID(ctx, "validuuid").Return(&entities.Account{UUID: "validuuid", Status: entities.Deleted}, nil)
+			},
+			expectedErr: ErrAccountIsDeleted,
+		},
+		{
+			name: "Repo error RevokeAllByAccount",
+			uuid: "validuuid",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetOneByUUID(ctx, "validuuid").Return(&entities.Account{UUID: "validuuid", Status: entities.Active}, nil)
+				mockRepo.EXPECT().MarkAccountDeleted(ctx, "validuuid", gomock.Any()).Return(nil)
+				mockSession.EXPECT().RevokeAllByAccount(ctx, "validuuid", gomock.Any()).Return(fmt.Errorf("some repo error"))
 			},
 			expectedErr: fmt.Errorf("some repo error"),
 		},
 		{
-			name:   "Repo error SetAccountStatus",
-			uuid:   "validuuid",
-			status: 1,
+			name: "Repo error MarkAccountDeleted",
+			uuid: "validuuid",
 			setupMocks: func() {
-				mockRepo.EXPECT().IsExistByUUID(ctx, gomock.Any()).Return(true, nil)
-				mockRepo.EXPECT().SetAccountStatus(ctx, gomock.Any(), gomock.Any()).Return(fmt.Errorf("some repo error"))
+				mockRepo.EXPECT().GetOneByUUID(ctx, "validuuid").Return(&entities.Account{UUID: "validuuid", Status: entities.Blocked}, nil)
+				mockRepo.EXPECT().MarkAccountDeleted(ctx, "validuuid", gomock.Any()).Return(fmt.Errorf("some repo error"))
 			},
 			expectedErr: fmt.Errorf("some repo error"),
 		},
@@ -479,11 +1230,153 @@ func TestAccount_ChangeAccountStatus(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.setupMocks()
-			account := &Account{repo: mockRepo}
-			err := account.ChangeAccountStatus(ctx, tc.uuid, tc.status)
+			account := &Account{
+				repo:                mockRepo,
+				transactor:          &txStub{repos: &TxRepos{Account: mockRepo, StatusHistory: mockHistory, Session: mockSession, SecurityEvent: mockEvents, AuditLog: mockAudit, Outbox: mockOutbox}},
+				deletiongraceperiod: time.Hour,
+			}
+			deleted, err := account.DeleteAccount(ctx, &AccountDeleteRequest{UUID: tc.uuid, Actor: tc.uuid, Audit: tc.audit})
 
 			if tc.expectedErr != nil {
-				assert.Error(t, err)
+				assert.EqualError(t, err, tc.expectedErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, deleted.IsDeleted())
+			assert.NotZero(t, deleted.DeletedAt)
+			assert.Equal(t, time.Unix(deleted.DeletedAt, 0).Add(time.Hour), account.PurgeTime(deleted))
+		})
+	}
+}
+
+func TestAccount_PurgeDeletedAccounts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := usecases_test.NewMockIAccountRepo(ctrl)
+	mockSession := usecases_test.NewMockISessionRepo(ctrl)
+	mockEvents := usecases_test.NewMockISecurityEventRepo(ctrl)
+	mockOutbox := usecases_test.NewMockIOutboxRepo(ctrl)
+	mockExports := usecases_test.NewMockIExportRepo(ctrl)
+	mockDeliveries := usecases_test.NewMockIWebhookDeliveryRepo(ctrl)
+	ctx := context.TODO()
+
+	deleted := []*entities.Account{
+		{UUID: "first", Status: entities.Deleted},
+		{UUID: "second", Status: entities.Deleted},
+	}
+	created := &entities.DomainEvent{
+		ID:          7,
+		UUID:        "created",
+		Type:        entities.DomainEventAccountCreated,
+		AccountUUID: "first",
+		Payload:     `{"Email":"bob@example.com","Name":"Bob","Status":0,"CreatedAt":100}`,
+		CreatedAt:   100,
+	}
+
+	testCases := []struct {
+		name           string
+		setupMocks     func()
+		expectedPurged int
+		expectedErr    error
+	}{
+		{
+			name: "Valid case",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetDeletedBefore(ctx, gomock.Any(), 10).Return(deleted, nil)
+				mockRepo.EXPECT().PurgeAccount(ctx, "first", gomock.Any()).Return(nil)
+				mockSession.EXPECT().DeleteByAccount(ctx, "first").Return(nil)
+				mockEvents.EXPECT().DeleteByAccount(ctx, "first").Return(nil)
+				mockExports.EXPECT().DeleteByAccount(ctx, "first").Return(nil)
+				mockOutbox.EXPECT().GetByAccount(ctx, "first", entities.DomainEventAccountCreated).Return([]*entities.DomainEvent{created}, nil)
+				mockOutbox.EXPECT().SetPayload(ctx, int64(7), `{"Email":"","Name":"","Status":0,"CreatedAt":100}`).Return(nil)
+				mockDeliveries.EXPECT().SetPayloadByEvent(ctx, "created",
+					`{"ID":"created","Type":"account.created","AccountUUID":"first","CreatedAt":100,"Data":{"Email":"","Name":"","Status":0,"CreatedAt":100}}`,
+				).Return(nil)
+				expectDomainEvent(t, mockOutbox, entities.DomainEventAccountPurged, "first")
+				mockRepo.EXPECT().PurgeAccount(ctx, "second", gomock.Any()).Return(nil)
+				mockSession.EXPECT().DeleteByAccount(ctx, "second").Return(nil)
+				mockEvents.EXPECT().DeleteByAccount(ctx, "second").Return(nil)
+				mockExports.EXPECT().DeleteByAccount(ctx, "second").Return(nil)
+				mockOutbox.EXPECT().GetByAccount(ctx, "second", entities.DomainEventAccountCreated).Return(nil, nil)
+				expectDomainEvent(t, mockOutbox, entities.DomainEventAccountPurged, "second")
+			},
+			expectedPurged: 2,
+		},
+		{
+			name: "Account is restored concurrently",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetDeletedBefore(ctx, gomock.Any(), 10).Return(deleted, nil)
+				mockRepo.EXPECT().PurgeAccount(ctx, "first", gomock.Any()).Return(repositories.NewErrAccountNotFoundByUUID("first"))
+				mockRepo.EXPECT().PurgeAccount(ctx, "second", gomock.Any()).Return(nil)
+				mockSession.EXPECT().DeleteByAccount(ctx, "second").Return(nil)
+				mockEvents.EXPECT().DeleteByAccount(ctx, "second").Return(nil)
+				mockExports.EXPECT().DeleteByAccount(ctx, "second").Return(nil)
+				mockOutbox.EXPECT().GetByAccount(ctx, "second", entities.DomainEventAccountCreated).Return(nil, nil)
+				expectDomainEvent(t, mockOutbox, entities.DomainEventAccountPurged, "second")
+			},
+			expectedPurged: 1,
+		},
+		{
+			name: "Repo error GetDeletedBefore",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetDeletedBefore(ctx, gomock.Any(), 10).Return(nil, fmt.Errorf("some repo error"))
+			},
+			expectedErr: fmt.Errorf("some repo error"),
+		},
+		{
+			name: "Repo error PurgeAccount",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetDeletedBefore(ctx, gomock.Any(), 10).Return(deleted, nil)
+				mockRepo.EXPECT().PurgeAccount(ctx, "first", gomock.Any()).Return(nil)
+				mockSession.EXPECT().DeleteByAccount(ctx, "first").Return(nil)
+				mockEvents.EXPECT().DeleteByAccount(ctx, "first").Return(nil)
+				mockExports.EXPECT().DeleteByAccount(ctx, "first").Return(nil)
+				mockOutbox.EXPECT().GetByAccount(ctx, "first", entities.DomainEventAccountCreated).Return(nil, nil)
+				expectDomainEvent(t, mockOutbox, entities.DomainEventAccountPurged, "first")
+				mockRepo.EXPECT().PurgeAccount(ctx, "second", gomock.Any()).Return(nil)
+				mockSession.EXPECT().DeleteByAccount(ctx, "second").Return(fmt.Errorf("some repo error"))
+			},
+			expectedPurged: 1,
+			expectedErr:    fmt.Errorf("some repo error"),
+		},
+		{
+			name: "Repo error SetPayloadByEvent",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetDeletedBefore(ctx, gomock.Any(), 10).Return(deleted[:1], nil)
+				mockRepo.EXPECT().PurgeAccount(ctx, "first", gomock.Any()).Return(nil)
+				mockSession.EXPECT().DeleteByAccount(ctx, "first").Return(nil)
+				mockEvents.EXPECT().DeleteByAccount(ctx, "first").Return(nil)
+				mockExports.EXPECT().DeleteByAccount(ctx, "first").Return(nil)
+				mockOutbox.EXPECT().GetByAccount(ctx, "first", entities.DomainEventAccountCreated).Return([]*entities.DomainEvent{created}, nil)
+				mockOutbox.EXPECT().SetPayload(ctx, int64(7), gomock.Any()).Return(nil)
+				mockDeliveries.EXPECT().SetPayloadByEvent(ctx, "created", gomock.Any()).Return(fmt.Errorf("some repo error"))
+			},
+			expectedErr: fmt.Errorf("some repo error"),
+		},
+	}
+
+	txrepos := &TxRepos{
+		Account:         mockRepo,
+		Session:         mockSession,
+		SecurityEvent:   mockEvents,
+		Export:          mockExports,
+		Outbox:          mockOutbox,
+		WebhookDelivery: mockDeliveries,
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			account := &Account{
+				repo:                mockRepo,
+				transactor:          &txStub{repos: txrepos},
+				deletiongraceperiod: time.Hour,
+			}
+
+			purged, err := account.PurgeDeletedAccounts(ctx, 10)
+
+			assert.Equal(t, tc.expectedPurged, purged)
+			if tc.expectedErr != nil {
 				assert.EqualError(t, err, tc.expectedErr.Error())
 			} else {
 				assert.NoError(t, err)
@@ -0,0 +1,98 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+)
+
+//go:generate mockgen -destination mocks/mock_accountimport.go -package usecases_test github.com/alexsibrin/runbot-auth/internal/usecases IAccountImportRepo
+
+const (
+	DefaultAccountExportBatchSize = 1000
+)
+
+var (
+	ErrAccountImportRepoIsNil = errors.New("dependency account import repo is nil")
+)
+
+type IAccountImportRepo interface {
+	// Import inserts the accounts and returns the UUIDs of the skipped ones, their UUID or email exists
+	Import(ctx context.Context, accounts []*entities.Account) ([]string, error)
+}
+
+type AccountImportConfig struct {
+	// ExportBatchSize is how many accounts are read at once, it's DefaultAccountExportBatchSize if it isn't positive
+	ExportBatchSize int
+}
+
+type AccountImportDependencies struct {
+	AccountRepo IAccountRepo
+	ImportRepo  IAccountImportRepo
+	Config      *AccountImportConfig
+}
+
+// AccountImport moves the accounts between installs in bulk, e.g. from a legacy one.
+// The password hashes are copied verbatim and no domain events are emitted, the accounts aren't new to their owners
+type AccountImport struct {
+	accountrepo     IAccountRepo
+	importrepo      IAccountImportRepo
+	exportbatchsize int
+}
+
+func NewAccountImport(d *AccountImportDependencies) (*AccountImport, error) {
+	if d == nil {
+		return nil, ErrDependenciesAreNil
+	}
+	if d.AccountRepo == nil {
+		return nil, ErrAccountRepoIsNil
+	}
+	if d.ImportRepo == nil {
+		return nil, ErrAccountImportRepoIsNil
+	}
+
+	u := &AccountImport{
+		accountrepo:     d.AccountRepo,
+		importrepo:      d.ImportRepo,
+		exportbatchsize: DefaultAccountExportBatchSize,
+	}
+	if d.Config != nil && d.Config.ExportBatchSize > 0 {
+		u.exportbatchsize = d.Config.ExportBatchSize
+	}
+	return u, nil
+}
+
+// Import inserts a batch of the accounts and returns the UUIDs of the skipped ones, their UUID or email exists
+func (u *AccountImport) Import(ctx context.Context, accounts []*entities.Account) ([]string, error) {
+	if len(accounts) == 0 {
+		return nil, nil
+	}
+	return u.importrepo.Import(ctx, accounts)
+}
+
+// Export calls send with every account which isn't deleted in the creation order, it stops once send fails
+func (u *AccountImport) Export(ctx context.Context, send func(account *entities.Account) error) error {
+	filter := &repositories.AccountFilter{
+		Statuses: []uint8{entities.Active, entities.Suspended, entities.Blocked},
+		Sort:     repositories.AccountSortCreatedAt,
+		Limit:    u.exportbatchsize,
+	}
+	for {
+		accounts, err := u.accountrepo.Find(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, account := range accounts {
+			if err = send(account); err != nil {
+				return err
+			}
+		}
+		if len(accounts) < u.exportbatchsize {
+			return nil
+		}
+
+		last := accounts[len(accounts)-1]
+		filter.After = &repositories.AccountKey{CreatedAt: last.CreatedAt, UUID: last.UUID}
+	}
+}
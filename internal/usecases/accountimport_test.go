@@ -0,0 +1,132 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	usecases_test "github.com/alexsibrin/runbot-auth/internal/usecases/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestAccountImportInit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	accountmock := usecases_test.NewMockIAccountRepo(ctrl)
+	importmock := usecases_test.NewMockIAccountImportRepo(ctrl)
+
+	testCases := []struct {
+		name        string
+		in          *AccountImportDependencies
+		out         *AccountImport
+		expectedErr error
+	}{
+		{
+			name: "Regular valid case",
+			in:   &AccountImportDependencies{AccountRepo: accountmock, ImportRepo: importmock},
+			out:  &AccountImport{accountrepo: accountmock, importrepo: importmock, exportbatchsize: DefaultAccountExportBatchSize},
+		},
+		{
+			name: "Export batch size is configured",
+			in:   &AccountImportDependencies{AccountRepo: accountmock, ImportRepo: importmock, Config: &AccountImportConfig{ExportBatchSize: 10}},
+			out:  &AccountImport{accountrepo: accountmock, importrepo: importmock, exportbatchsize: 10},
+		},
+		{
+			name:        "Dependencies are nil",
+			in:          nil,
+			expectedErr: ErrDependenciesAreNil,
+		},
+		{
+			name:        "Account repo is nil",
+			in:          &AccountImportDependencies{ImportRepo: importmock},
+			expectedErr: ErrAccountRepoIsNil,
+		},
+		{
+			name:        "Import repo is nil",
+			in:          &AccountImportDependencies{AccountRepo: accountmock},
+			expectedErr: ErrAccountImportRepoIsNil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			uc, err := NewAccountImport(tc.in)
+			assert.Equal(t, tc.out, uc)
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestAccountImport_Import(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	importmock := usecases_test.NewMockIAccountImportRepo(ctrl)
+	u, err := NewAccountImport(&AccountImportDependencies{AccountRepo: usecases_test.NewMockIAccountRepo(ctrl), ImportRepo: importmock})
+	require.NoError(t, err)
+
+	accounts := []*entities.Account{{UUID: "first"}, {UUID: "second"}}
+	importmock.EXPECT().Import(ctx, accounts).Return([]string{"second"}, nil)
+
+	skipped, err := u.Import(ctx, accounts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"second"}, skipped)
+
+	// the empty batch isn't sent to the repo
+	skipped, err = u.Import(ctx, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, skipped)
+}
+
+func TestAccountImport_Export(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	accountmock := usecases_test.NewMockIAccountRepo(ctrl)
+	u, err := NewAccountImport(&AccountImportDependencies{
+		AccountRepo: accountmock,
+		ImportRepo:  usecases_test.NewMockIAccountImportRepo(ctrl),
+		Config:      &AccountImportConfig{ExportBatchSize: 2},
+	})
+	require.NoError(t, err)
+
+	statuses := []uint8{entities.Active, entities.Suspended, entities.Blocked}
+
+	t.Run("Accounts are read in batches", func(t *testing.T) {
+		gomock.InOrder(
+			accountmock.EXPECT().Find(ctx, &repositories.AccountFilter{
+				Statuses: statuses,
+				Sort:     repositories.AccountSortCreatedAt,
+				Limit:    2,
+			}).Return([]*entities.Account{{UUID: "a", CreatedAt: 1}, {UUID: "b", CreatedAt: 2}}, nil),
+			accountmock.EXPECT().Find(ctx, &repositories.AccountFilter{
+				Statuses: statuses,
+				Sort:     repositories.AccountSortCreatedAt,
+				After:    &repositories.AccountKey{CreatedAt: 2, UUID: "b"},
+				Limit:    2,
+			}).Return([]*entities.Account{{UUID: "c", CreatedAt: 3}}, nil),
+		)
+
+		var sent []string
+		err := u.Export(ctx, func(account *entities.Account) error {
+			sent = append(sent, account.UUID)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c"}, sent)
+	})
+
+	t.Run("Export stops once send fails", func(t *testing.T) {
+		errWrite := errors.New("disk is full")
+		accountmock.EXPECT().Find(ctx, gomock.Any()).Return([]*entities.Account{{UUID: "a"}, {UUID: "b"}}, nil)
+
+		err := u.Export(ctx, func(account *entities.Account) error {
+			return errWrite
+		})
+		assert.ErrorIs(t, err, errWrite)
+	})
+}
@@ -0,0 +1,157 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultAccountWatchPollInterval = 5 * time.Second
+	DefaultAccountWatchBatchSize    = 100
+)
+
+var (
+	ErrCursorIsExpired = errors.New("cursor is expired, the events after it are deleted")
+	ErrWatchIsClosed   = errors.New("account watch is closed")
+)
+
+type AccountWatchConfig struct {
+	// PollInterval is how often the outbox is read without a wakeup, e.g. for the events of the other instances.
+	// It's DefaultAccountWatchPollInterval if it isn't positive
+	PollInterval time.Duration
+	// BatchSize is DefaultAccountWatchBatchSize if it isn't positive
+	BatchSize int
+}
+
+type AccountWatchDependencies struct {
+	Repo   IOutboxRepo
+	Config *AccountWatchConfig
+}
+
+// AccountWatch streams the published account changes from the outbox to the watchers, e.g. the services caching
+// the accounts. The event Seq is the cursor a watcher resumes from after a reconnection. The Seq are committed
+// in their order, so an event committed late can't be skipped, and nothing is lost until the event is deleted
+// after the outbox retention
+type AccountWatch struct {
+	repo         IOutboxRepo
+	pollinterval time.Duration
+	batchsize    int
+
+	mu sync.Mutex
+	// wake is closed and replaced when there are new events
+	wake chan struct{}
+	// closed stops the watchers, e.g. so the server shuts down without waiting for the endless streams
+	closed    chan struct{}
+	closeonce sync.Once
+}
+
+func NewAccountWatch(d *AccountWatchDependencies) (*AccountWatch, error) {
+	if d == nil {
+		return nil, ErrDependenciesAreNil
+	}
+	if d.Repo == nil {
+		return nil, ErrOutboxRepoIsNil
+	}
+
+	u := &AccountWatch{
+		repo:         d.Repo,
+		pollinterval: DefaultAccountWatchPollInterval,
+		batchsize:    DefaultAccountWatchBatchSize,
+		wake:         make(chan struct{}),
+		closed:       make(chan struct{}),
+	}
+	if d.Config != nil && d.Config.PollInterval > 0 {
+		u.pollinterval = d.Config.PollInterval
+	}
+	if d.Config != nil && d.Config.BatchSize > 0 {
+		u.batchsize = d.Config.BatchSize
+	}
+	return u, nil
+}
+
+// Published wakes the watchers up, it's called by the relay once the event is marked published
+func (u *AccountWatch) Published(_ context.Context, _ *entities.DomainEvent) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	close(u.wake)
+	u.wake = make(chan struct{})
+}
+
+// Close stops all the watchers with ErrWatchIsClosed, the later calls of Watch fail at once
+func (u *AccountWatch) Close() {
+	u.closeonce.Do(func() {
+		close(u.closed)
+	})
+}
+
+// Watch calls send with the events after the cursor in the Seq order until ctx is done, send fails or the watch is closed.
+// The zero cursor starts with the next event. ErrCursorIsExpired means the events after the cursor
+// are deleted, so the watcher has to reload the accounts and watch from the zero cursor
+func (u *AccountWatch) Watch(ctx context.Context, cursor int64, send func(event *entities.DomainEvent) error) error {
+	select {
+	case <-u.closed:
+		return ErrWatchIsClosed
+	default:
+	}
+
+	first, last, err := u.repo.GetSeqRange(ctx)
+	if err != nil {
+		return err
+	}
+	switch {
+	case cursor == 0:
+		cursor = last
+	case first > cursor+1, last != 0 && cursor > last:
+		return ErrCursorIsExpired
+	}
+
+	for {
+		// the wakeup is taken before the read, so the events committed after the read aren't missed
+		wake := u.wakeup()
+
+		events, err := u.repo.GetPublishedAfter(ctx, cursor, u.batchsize)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if err = send(event); err != nil {
+				return err
+			}
+			cursor = event.Seq
+		}
+		// the rest of a full batch is read at once
+		if len(events) == u.batchsize {
+			continue
+		}
+
+		if err = u.wait(ctx, wake); err != nil {
+			return err
+		}
+	}
+}
+
+func (u *AccountWatch) wait(ctx context.Context, wake <-chan struct{}) error {
+	timer := time.NewTimer(u.pollinterval)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-u.closed:
+		return ErrWatchIsClosed
+	case <-wake:
+	case <-timer.C:
+	}
+	return nil
+}
+
+func (u *AccountWatch) wakeup() <-chan struct{} {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.wake
+}
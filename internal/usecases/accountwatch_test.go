@@ -0,0 +1,170 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	usecases_test "github.com/alexsibrin/runbot-auth/internal/usecases/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+var errStopWatching = errors.New("stop watching")
+
+func newTestAccountWatch(t *testing.T, config *AccountWatchConfig) (*AccountWatch, *usecases_test.MockIOutboxRepo) {
+	ctrl := gomock.NewController(t)
+	repomock := usecases_test.NewMockIOutboxRepo(ctrl)
+	u, err := NewAccountWatch(&AccountWatchDependencies{Repo: repomock, Config: config})
+	require.NoError(t, err)
+	return u, repomock
+}
+
+// watchEvents returns the published events with the seq, the IDs are unrelated to the seq
+func watchEvents(seqs ...int64) []*entities.DomainEvent {
+	events := make([]*entities.DomainEvent, 0, len(seqs))
+	for _, seq := range seqs {
+		events = append(events, &entities.DomainEvent{ID: 100 - seq, Seq: seq, Type: entities.DomainEventAccountStatusChanged})
+	}
+	return events
+}
+
+// sendUntil collects the sent seq and stops the watching once the last seq is sent
+func sendUntil(sent *[]int64, lastseq int64) func(event *entities.DomainEvent) error {
+	return func(event *entities.DomainEvent) error {
+		*sent = append(*sent, event.Seq)
+		if event.Seq == lastseq {
+			return errStopWatching
+		}
+		return nil
+	}
+}
+
+func TestAccountWatchInit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repomock := usecases_test.NewMockIOutboxRepo(ctrl)
+
+	u, err := NewAccountWatch(&AccountWatchDependencies{Repo: repomock})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultAccountWatchPollInterval, u.pollinterval)
+	assert.Equal(t, DefaultAccountWatchBatchSize, u.batchsize)
+
+	u, err = NewAccountWatch(&AccountWatchDependencies{Repo: repomock, Config: &AccountWatchConfig{PollInterval: time.Second, BatchSize: 10}})
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, u.pollinterval)
+	assert.Equal(t, 10, u.batchsize)
+
+	_, err = NewAccountWatch(nil)
+	assert.ErrorIs(t, err, ErrDependenciesAreNil)
+
+	_, err = NewAccountWatch(&AccountWatchDependencies{})
+	assert.ErrorIs(t, err, ErrOutboxRepoIsNil)
+}
+
+func TestAccountWatch_Watch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Events are sent after the cursor in batches", func(t *testing.T) {
+		u, repomock := newTestAccountWatch(t, &AccountWatchConfig{BatchSize: 2})
+		gomock.InOrder(
+			repomock.EXPECT().GetSeqRange(ctx).Return(int64(1), int64(5), nil),
+			repomock.EXPECT().GetPublishedAfter(ctx, int64(2), 2).Return(watchEvents(3, 4), nil),
+			repomock.EXPECT().GetPublishedAfter(ctx, int64(4), 2).Return(watchEvents(5), nil),
+		)
+
+		var sent []int64
+		err := u.Watch(ctx, 2, sendUntil(&sent, 5))
+		assert.ErrorIs(t, err, errStopWatching)
+		assert.Equal(t, []int64{3, 4, 5}, sent)
+	})
+
+	t.Run("Zero cursor starts with the next event", func(t *testing.T) {
+		u, repomock := newTestAccountWatch(t, nil)
+		gomock.InOrder(
+			repomock.EXPECT().GetSeqRange(ctx).Return(int64(1), int64(5), nil),
+			repomock.EXPECT().GetPublishedAfter(ctx, int64(5), DefaultAccountWatchBatchSize).Return(watchEvents(6), nil),
+		)
+
+		var sent []int64
+		err := u.Watch(ctx, 0, sendUntil(&sent, 6))
+		assert.ErrorIs(t, err, errStopWatching)
+		assert.Equal(t, []int64{6}, sent)
+	})
+
+	t.Run("Cursor is expired", func(t *testing.T) {
+		u, repomock := newTestAccountWatch(t, nil)
+		repomock.EXPECT().GetSeqRange(ctx).Return(int64(10), int64(20), nil).Times(2)
+
+		err := u.Watch(ctx, 8, sendUntil(new([]int64), 0))
+		assert.ErrorIs(t, err, ErrCursorIsExpired)
+
+		err = u.Watch(ctx, 21, sendUntil(new([]int64), 0))
+		assert.ErrorIs(t, err, ErrCursorIsExpired)
+	})
+
+	t.Run("Gap isn't waited for", func(t *testing.T) {
+		u, repomock := newTestAccountWatch(t, &AccountWatchConfig{PollInterval: time.Hour})
+		gomock.InOrder(
+			repomock.EXPECT().GetSeqRange(ctx).Return(int64(1), int64(7), nil),
+			repomock.EXPECT().GetPublishedAfter(ctx, int64(5), DefaultAccountWatchBatchSize).Return(watchEvents(7), nil),
+		)
+
+		var sent []int64
+		err := u.Watch(ctx, 5, sendUntil(&sent, 7))
+		assert.ErrorIs(t, err, errStopWatching)
+		assert.Equal(t, []int64{7}, sent)
+	})
+
+	t.Run("Published event wakes the watcher up", func(t *testing.T) {
+		u, repomock := newTestAccountWatch(t, &AccountWatchConfig{PollInterval: time.Hour})
+		gomock.InOrder(
+			repomock.EXPECT().GetSeqRange(ctx).Return(int64(0), int64(0), nil),
+			repomock.EXPECT().GetPublishedAfter(ctx, int64(0), DefaultAccountWatchBatchSize).DoAndReturn(func(context.Context, int64, int) ([]*entities.DomainEvent, error) {
+				go func() {
+					u.Published(ctx, &entities.DomainEvent{ID: 1})
+				}()
+				return nil, nil
+			}),
+			repomock.EXPECT().GetPublishedAfter(ctx, int64(0), DefaultAccountWatchBatchSize).Return(watchEvents(1), nil),
+		)
+
+		var sent []int64
+		err := u.Watch(ctx, 0, sendUntil(&sent, 1))
+		assert.ErrorIs(t, err, errStopWatching)
+		assert.Equal(t, []int64{1}, sent)
+	})
+
+	t.Run("Watching stops with the context", func(t *testing.T) {
+		u, repomock := newTestAccountWatch(t, &AccountWatchConfig{PollInterval: time.Hour})
+		ctx, cancel := context.WithCancel(ctx)
+		gomock.InOrder(
+			repomock.EXPECT().GetSeqRange(ctx).Return(int64(1), int64(5), nil),
+			repomock.EXPECT().GetPublishedAfter(ctx, int64(5), DefaultAccountWatchBatchSize).DoAndReturn(func(context.Context, int64, int) ([]*entities.DomainEvent, error) {
+				cancel()
+				return nil, nil
+			}),
+		)
+
+		err := u.Watch(ctx, 0, sendUntil(new([]int64), 0))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("Watching stops when the watch is closed", func(t *testing.T) {
+		u, repomock := newTestAccountWatch(t, &AccountWatchConfig{PollInterval: time.Hour})
+		gomock.InOrder(
+			repomock.EXPECT().GetSeqRange(ctx).Return(int64(1), int64(5), nil),
+			repomock.EXPECT().GetPublishedAfter(ctx, int64(5), DefaultAccountWatchBatchSize).DoAndReturn(func(context.Context, int64, int) ([]*entities.DomainEvent, error) {
+				u.Close()
+				return nil, nil
+			}),
+		)
+
+		err := u.Watch(ctx, 0, sendUntil(new([]int64), 0))
+		assert.ErrorIs(t, err, ErrWatchIsClosed)
+
+		err = u.Watch(ctx, 0, sendUntil(new([]int64), 0))
+		assert.ErrorIs(t, err, ErrWatchIsClosed)
+	})
+}
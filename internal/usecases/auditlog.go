@@ -0,0 +1,159 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"time"
+)
+
+//go:generate mockgen -destination mocks/mock_auditlog.go -package usecases_test github.com/alexsibrin/runbot-auth/internal/usecases IAuditLogRepo
+
+const (
+	DefaultAuditLogLimit = 100
+	MaxAuditLogLimit     = 1000
+)
+
+var (
+	ErrAuditLogRepoIsNil  = errors.New("dependency audit log repo is nil")
+	ErrAuditChainIsBroken = errors.New("audit log chain is broken")
+)
+
+type IAuditLogRepo interface {
+	// Add appends the record and returns it with the assigned ID, the records are never changed or deleted
+	Add(ctx context.Context, record *entities.AuditRecord) (*entities.AuditRecord, error)
+	// Last returns the latest record, it's nil if the log is empty
+	Last(ctx context.Context) (*entities.AuditRecord, error)
+	// List returns up to limit records following the record with afterid, the earliest go first
+	List(ctx context.Context, afterid int64, limit int) ([]*entities.AuditRecord, error)
+}
+
+// AuditMetadata describes the admin request which makes a change
+type AuditMetadata struct {
+	Method    string
+	IP        string
+	UserAgent string
+}
+
+// AuditVerification is the result of a successful chain check, Head is the hash of the last record.
+// A removal of the latest records can't be told from the chain itself, compare Head with a previous check
+type AuditVerification struct {
+	Records int
+	Head    string
+}
+
+type AuditLogDependencies struct {
+	Repo IAuditLogRepo
+}
+
+// AuditLog gives access to the admin audit log, the records are added by the Account and Webhook usecases
+// in the transactions of the audited changes
+type AuditLog struct {
+	repo IAuditLogRepo
+}
+
+func NewAuditLog(d *AuditLogDependencies) (*AuditLog, error) {
+	if d == nil {
+		return nil, ErrDependenciesAreNil
+	}
+	if d.Repo == nil {
+		return nil, ErrAuditLogRepoIsNil
+	}
+	return &AuditLog{
+		repo: d.Repo,
+	}, nil
+}
+
+// List exports the log page by page from the earliest record, afterid is the ID of the last record
+// of the previous page. The page size is AuditLogLimit(limit)
+func (u *AuditLog) List(ctx context.Context, afterid int64, limit int) ([]*entities.AuditRecord, error) {
+	return u.repo.List(ctx, afterid, AuditLogLimit(limit))
+}
+
+// Verify walks the whole log and checks every record hash and link to the previous record
+func (u *AuditLog) Verify(ctx context.Context) (*AuditVerification, error) {
+	var (
+		result  AuditVerification
+		afterid int64
+	)
+	for {
+		records, err := u.repo.List(ctx, afterid, MaxAuditLogLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range records {
+			if record.PrevHash != result.Head {
+				return nil, fmt.Errorf("%w: record %d doesn't follow the previous one", ErrAuditChainIsBroken, record.ID)
+			}
+			if record.Hash != record.ComputeHash() {
+				return nil, fmt.Errorf("%w: record %d is changed", ErrAuditChainIsBroken, record.ID)
+			}
+			result.Head = record.Hash
+			result.Records++
+		}
+
+		if len(records) < MaxAuditLogLimit {
+			return &result, nil
+		}
+		afterid = records[len(records)-1].ID
+	}
+}
+
+// AuditLogLimit is the page size for the limit, DefaultAuditLogLimit if limit isn't positive and MaxAuditLogLimit at most
+func AuditLogLimit(limit int) int {
+	switch {
+	case limit <= 0:
+		return DefaultAuditLogLimit
+	case limit > MaxAuditLogLimit:
+		return MaxAuditLogLimit
+	default:
+		return limit
+	}
+}
+
+// appendAuditRecord chains the record to the latest one and adds it, repo must be bound to a transaction,
+// so concurrent appends can't fork the chain
+func appendAuditRecord(ctx context.Context, repo IAuditLogRepo, record *entities.AuditRecord) error {
+	last, err := repo.Last(ctx)
+	if err != nil {
+		return err
+	}
+	record.PrevHash = ""
+	if last != nil {
+		record.PrevHash = last.Hash
+	}
+	record.Hash = record.ComputeHash()
+
+	_, err = repo.Add(ctx, record)
+	return err
+}
+
+func newAuditRecord(meta *AuditMetadata, actor, action, target string, before, after any) *entities.AuditRecord {
+	return &entities.AuditRecord{
+		Actor:      actor,
+		Action:     action,
+		TargetUUID: target,
+		Before:     auditValues(before),
+		After:      auditValues(after),
+		Method:     meta.Method,
+		IP:         meta.IP,
+		UserAgent:  meta.UserAgent,
+		CreatedAt:  time.Now().Unix(),
+	}
+}
+
+// auditValues encodes the changed values of an audit record, nil is no values, e.g. before a creation
+func auditValues(v any) string {
+	if v == nil {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		// the values are plain structs, they're always encoded
+		return ""
+	}
+	return string(data)
+}
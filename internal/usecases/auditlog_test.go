@@ -0,0 +1,187 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	usecases_test "github.com/alexsibrin/runbot-auth/internal/usecases/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+// newTestAuditChain returns n chained records with the IDs from 1
+func newTestAuditChain(n int) []*entities.AuditRecord {
+	records := make([]*entities.AuditRecord, 0, n)
+	var prevhash string
+	for i := 1; i <= n; i++ {
+		record := &entities.AuditRecord{
+			ID:         int64(i),
+			Actor:      "admin",
+			Action:     entities.AuditActionStatusChanged,
+			TargetUUID: "accountuuid",
+			Before:     `{"Status":0}`,
+			After:      `{"Status":1}`,
+			CreatedAt:  int64(100 + i),
+			PrevHash:   prevhash,
+		}
+		record.Hash = record.ComputeHash()
+		prevhash = record.Hash
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestAuditLogInit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	auditmock := usecases_test.NewMockIAuditLogRepo(ctrl)
+
+	testCases := []struct {
+		name        string
+		in          *AuditLogDependencies
+		out         *AuditLog
+		expectedErr error
+	}{
+		{
+			name: "Regular valid case",
+			in:   &AuditLogDependencies{Repo: auditmock},
+			out:  &AuditLog{repo: auditmock},
+		},
+		{
+			name:        "Dependencies are nil",
+			in:          nil,
+			expectedErr: ErrDependenciesAreNil,
+		},
+		{
+			name:        "Repo is nil",
+			in:          &AuditLogDependencies{},
+			expectedErr: ErrAuditLogRepoIsNil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			uc, err := NewAuditLog(tc.in)
+			assert.Equal(t, tc.out, uc)
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestAuditLog_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	auditmock := usecases_test.NewMockIAuditLogRepo(ctrl)
+	ctx := context.TODO()
+
+	testCases := []struct {
+		name          string
+		limit         int
+		expectedLimit int
+	}{
+		{name: "Default limit", limit: 0, expectedLimit: DefaultAuditLogLimit},
+		{name: "Given limit", limit: 10, expectedLimit: 10},
+		{name: "Limit is too big", limit: MaxAuditLogLimit + 1, expectedLimit: MaxAuditLogLimit},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			auditmock.EXPECT().List(ctx, int64(5), tc.expectedLimit).Return(nil, nil)
+
+			uc := &AuditLog{repo: auditmock}
+			_, err := uc.List(ctx, 5, tc.limit)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestAuditLog_Verify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	auditmock := usecases_test.NewMockIAuditLogRepo(ctrl)
+	ctx := context.TODO()
+
+	testCases := []struct {
+		name        string
+		records     func() []*entities.AuditRecord
+		out         *AuditVerification
+		expectedErr error
+	}{
+		{
+			name: "Intact chain",
+			records: func() []*entities.AuditRecord {
+				return newTestAuditChain(3)
+			},
+			out: &AuditVerification{Records: 3, Head: newTestAuditChain(3)[2].Hash},
+		},
+		{
+			name: "Empty log",
+			records: func() []*entities.AuditRecord {
+				return nil
+			},
+			out: &AuditVerification{},
+		},
+		{
+			name: "Record is changed",
+			records: func() []*entities.AuditRecord {
+				records := newTestAuditChain(3)
+				records[1].After = `{"Status":2}`
+				return records
+			},
+			expectedErr: ErrAuditChainIsBroken,
+		},
+		{
+			name: "Record is removed",
+			records: func() []*entities.AuditRecord {
+				records := newTestAuditChain(3)
+				return []*entities.AuditRecord{records[0], records[2]}
+			},
+			expectedErr: ErrAuditChainIsBroken,
+		},
+		{
+			name: "Record is rehashed",
+			records: func() []*entities.AuditRecord {
+				records := newTestAuditChain(3)
+				records[1].Actor = "someone"
+				records[1].Hash = records[1].ComputeHash()
+				return records
+			},
+			expectedErr: ErrAuditChainIsBroken,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			auditmock.EXPECT().List(ctx, int64(0), MaxAuditLogLimit).Return(tc.records(), nil)
+
+			uc := &AuditLog{repo: auditmock}
+			result, err := uc.Verify(ctx)
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.out, result)
+		})
+	}
+
+	t.Run("Repo error", func(t *testing.T) {
+		someErr := errors.New("some repo error")
+		auditmock.EXPECT().List(ctx, int64(0), MaxAuditLogLimit).Return(nil, someErr)
+
+		uc := &AuditLog{repo: auditmock}
+		_, err := uc.Verify(ctx)
+		assert.ErrorIs(t, err, someErr)
+	})
+
+	t.Run("Several pages", func(t *testing.T) {
+		records := newTestAuditChain(MaxAuditLogLimit + 1)
+		auditmock.EXPECT().List(ctx, int64(0), MaxAuditLogLimit).Return(records[:MaxAuditLogLimit], nil)
+		auditmock.EXPECT().List(ctx, int64(MaxAuditLogLimit), MaxAuditLogLimit).Return(records[MaxAuditLogLimit:], nil)
+
+		uc := &AuditLog{repo: auditmock}
+		result, err := uc.Verify(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, &AuditVerification{Records: MaxAuditLogLimit + 1, Head: records[MaxAuditLogLimit].Hash}, result)
+	})
+}
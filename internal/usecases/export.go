@@ -0,0 +1,340 @@
+package usecases
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"github.com/google/uuid"
+	"time"
+)
+
+//go:generate mockgen -destination mocks/mock_export.go -package usecases_test github.com/alexsibrin/runbot-auth/internal/usecases IExportRepo
+
+const (
+	DefaultExportRetention = 7 * 24 * time.Hour
+
+	// exportProcessingTimeout is the time after which an export claimed by a crashed worker is claimed again
+	exportProcessingTimeout = 10 * time.Minute
+)
+
+var (
+	ErrExportRepoIsNil  = errors.New("dependency export repo is nil")
+	ErrExportIsNotFound = errors.New("export is not found")
+	ErrExportIsNotReady = errors.New("export is not ready")
+	ErrExportIsExpired  = errors.New("export is expired")
+)
+
+type IExportRepo interface {
+	Create(ctx context.Context, export *entities.AccountExport) error
+	GetOneByUUID(ctx context.Context, uuid string) (*entities.AccountExport, error)
+	// GetUnfinishedByAccount returns a pending or processing export of the account, nil if there is none
+	GetUnfinishedByAccount(ctx context.Context, accountuuid string) (*entities.AccountExport, error)
+	// ClaimPending marks the oldest pending export, or the processing one which isn't updated since stalebefore,
+	// as processing and returns it, nil is returned if there is none
+	ClaimPending(ctx context.Context, now, stalebefore int64) (*entities.AccountExport, error)
+	SetReady(ctx context.Context, uuid string, archive []byte, readyat, expiresat int64) error
+	// SetFailed keeps the failed export until expiresat, so the owner can see the failure
+	SetFailed(ctx context.Context, uuid string, failedat, expiresat int64) error
+	// DeleteExpired deletes the exports expired at now and returns their number
+	DeleteExpired(ctx context.Context, now int64) (int, error)
+	DeleteByAccount(ctx context.Context, accountuuid string) error
+}
+
+type ExportDependencies struct {
+	Repo              IExportRepo
+	AccountRepo       IAccountRepo
+	SessionRepo       ISessionRepo
+	SecurityEventRepo ISecurityEventRepo
+	// Retention is DefaultExportRetention if it isn't positive
+	Retention time.Duration
+}
+
+// Export assembles the personal data of an account into a ZIP archive of JSON files.
+// Archives are built asynchronously by ProcessNext and deleted after the retention
+type Export struct {
+	repo              IExportRepo
+	accountrepo       IAccountRepo
+	sessionrepo       ISessionRepo
+	securityeventrepo ISecurityEventRepo
+	retention         time.Duration
+}
+
+func NewExport(d *ExportDependencies) (*Export, error) {
+	if d == nil {
+		return nil, ErrDependenciesAreNil
+	}
+	if d.Repo == nil {
+		return nil, ErrExportRepoIsNil
+	}
+	if d.AccountRepo == nil {
+		return nil, ErrAccountRepoIsNil
+	}
+	if d.SessionRepo == nil {
+		return nil, ErrSessionRepoIsNil
+	}
+	if d.SecurityEventRepo == nil {
+		return nil, ErrSecurityEventRepoIsNil
+	}
+	retention := d.Retention
+	if retention <= 0 {
+		retention = DefaultExportRetention
+	}
+	return &Export{
+		repo:              d.Repo,
+		accountrepo:       d.AccountRepo,
+		sessionrepo:       d.SessionRepo,
+		securityeventrepo: d.SecurityEventRepo,
+		retention:         retention,
+	}, nil
+}
+
+// RequestExport schedules an export of the account, the unfinished one is returned if it's already requested
+func (u *Export) RequestExport(ctx context.Context, accountuuid string) (*entities.AccountExport, error) {
+	account, err := u.accountrepo.GetOneByUUID(ctx, accountuuid)
+	if err != nil {
+		if errors.As(err, &repositories.ErrAccountNotFoundByUUID{}) {
+			return nil, ErrAccountIsNotExist
+		}
+		return nil, err
+	}
+	if !account.IsActive() {
+		return nil, ErrAccountIsNotActive
+	}
+
+	unfinished, err := u.repo.GetUnfinishedByAccount(ctx, accountuuid)
+	if err != nil {
+		return nil, err
+	}
+	if unfinished != nil {
+		return unfinished, nil
+	}
+
+	now := time.Now().Unix()
+	export := &entities.AccountExport{
+		UUID:        uuid.NewString(),
+		AccountUUID: accountuuid,
+		Status:      entities.ExportPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err = u.repo.Create(ctx, export); err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// GetExport returns the export if it belongs to the account
+func (u *Export) GetExport(ctx context.Context, accountuuid, exportuuid string) (*entities.AccountExport, error) {
+	export, err := u.getOne(ctx, exportuuid)
+	if err != nil {
+		return nil, err
+	}
+	// the exports of the other accounts are reported as not found to not disclose them
+	if export.AccountUUID != accountuuid {
+		return nil, ErrExportIsNotFound
+	}
+	return export, nil
+}
+
+// GetArchive returns the archive of a ready export, the caller is responsible for the authorization
+func (u *Export) GetArchive(ctx context.Context, exportuuid string) ([]byte, error) {
+	export, err := u.getOne(ctx, exportuuid)
+	if err != nil {
+		return nil, err
+	}
+	if !export.IsReady() {
+		return nil, ErrExportIsNotReady
+	}
+	if export.IsExpired(time.Now().Unix()) {
+		return nil, ErrExportIsExpired
+	}
+	return export.Archive, nil
+}
+
+// ProcessNext builds the archive of the next pending export, false is returned if there is nothing to process
+func (u *Export) ProcessNext(ctx context.Context) (bool, error) {
+	now := time.Now()
+	export, err := u.repo.ClaimPending(ctx, now.Unix(), now.Add(-exportProcessingTimeout).Unix())
+	if err != nil {
+		return false, err
+	}
+	if export == nil {
+		return false, nil
+	}
+
+	archive, err := u.buildArchive(ctx, export)
+	finishedat := time.Now()
+	if err != nil {
+		return true, errors.Join(err, u.repo.SetFailed(ctx, export.UUID, finishedat.Unix(), finishedat.Add(u.retention).Unix()))
+	}
+
+	err = u.repo.SetReady(ctx, export.UUID, archive, finishedat.Unix(), finishedat.Add(u.retention).Unix())
+	return true, err
+}
+
+func (u *Export) DeleteExpired(ctx context.Context) (int, error) {
+	return u.repo.DeleteExpired(ctx, time.Now().Unix())
+}
+
+func (u *Export) getOne(ctx context.Context, exportuuid string) (*entities.AccountExport, error) {
+	export, err := u.repo.GetOneByUUID(ctx, exportuuid)
+	if err != nil {
+		if errors.As(err, &repositories.ErrExportNotFoundByUUID{}) {
+			return nil, ErrExportIsNotFound
+		}
+		return nil, err
+	}
+	return export, nil
+}
+
+// exportFile is a JSON file of the archive
+type exportFile struct {
+	name    string
+	content any
+}
+
+// exportAccount is the account record of the archive, the password hash isn't personal data of the user
+type exportAccount struct {
+	UUID      string
+	Email     string
+	Name      string
+	Status    uint8
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+// exportSession is a device signed in to the account, the revoked and expired sessions are exported as well
+type exportSession struct {
+	UUID        string
+	IP          string
+	UserAgent   string
+	DeviceName  string
+	CreatedAt   int64
+	RefreshedAt int64
+	ExpiresAt   int64
+	RevokedAt   int64
+}
+
+// exportSecurityEvent is a record of the login history and the other security events of the account
+type exportSecurityEvent struct {
+	Type      string
+	IP        string
+	UserAgent string
+	Details   string
+	CreatedAt int64
+}
+
+// buildArchive exports the account, its sessions and its security log, the login history.
+// There are no linked identities to export, the accounts are only signed in by the email and the password
+func (u *Export) buildArchive(ctx context.Context, export *entities.AccountExport) ([]byte, error) {
+	account, err := u.accountrepo.GetOneByUUID(ctx, export.AccountUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := u.sessionrepo.GetByAccount(ctx, export.AccountUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := u.securityEvents(ctx, export.AccountUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []exportFile{
+		{name: "account.json", content: u.account2export(account)},
+		{name: "sessions.json", content: u.sessions2export(sessions)},
+		{name: "security_events.json", content: u.securityEvents2export(events)},
+	}
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, f := range files {
+		fw, err := w.CreateHeader(&zip.FileHeader{
+			Name:     f.name,
+			Method:   zip.Deflate,
+			Modified: time.Unix(export.CreatedAt, 0),
+		})
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(fw)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(f.content); err != nil {
+			return nil, err
+		}
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// securityEvents reads the whole security log of the account page by page, the latest events go first
+func (u *Export) securityEvents(ctx context.Context, accountuuid string) ([]*entities.SecurityEvent, error) {
+	var result []*entities.SecurityEvent
+	filter := &repositories.SecurityEventFilter{
+		AccountUUID: accountuuid,
+		Limit:       MaxSecurityEventsLimit,
+	}
+	for {
+		events, err := u.securityeventrepo.Find(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, events...)
+		if len(events) < filter.Limit {
+			return result, nil
+		}
+		filter.BeforeID = events[len(events)-1].ID
+	}
+}
+
+func (u *Export) account2export(account *entities.Account) *exportAccount {
+	return &exportAccount{
+		UUID:      account.UUID,
+		Email:     account.Email,
+		Name:      account.Name,
+		Status:    account.Status,
+		CreatedAt: account.CreatedAt,
+		UpdatedAt: account.UpdatedAt,
+	}
+}
+
+func (u *Export) sessions2export(sessions []*entities.Session) []*exportSession {
+	result := make([]*exportSession, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, &exportSession{
+			UUID:        session.UUID,
+			IP:          session.IP,
+			UserAgent:   session.UserAgent,
+			DeviceName:  session.DeviceName,
+			CreatedAt:   session.CreatedAt,
+			RefreshedAt: session.RefreshedAt,
+			ExpiresAt:   session.ExpiresAt,
+			RevokedAt:   session.RevokedAt,
+		})
+	}
+	return result
+}
+
+func (u *Export) securityEvents2export(events []*entities.SecurityEvent) []*exportSecurityEvent {
+	result := make([]*exportSecurityEvent, 0, len(events))
+	for _, event := range events {
+		result = append(result, &exportSecurityEvent{
+			Type:      entities.SecurityEventTypeName(event.Type),
+			IP:        event.IP,
+			UserAgent: event.UserAgent,
+			Details:   event.Details,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+	return result
+}
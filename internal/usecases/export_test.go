@@ -0,0 +1,306 @@
+package usecases
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	usecases_test "github.com/alexsibrin/runbot-auth/internal/usecases/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestExportInit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	exportmock := usecases_test.NewMockIExportRepo(ctrl)
+	accountmock := usecases_test.NewMockIAccountRepo(ctrl)
+	sessionmock := usecases_test.NewMockISessionRepo(ctrl)
+	eventmock := usecases_test.NewMockISecurityEventRepo(ctrl)
+
+	testCases := []struct {
+		name        string
+		in          *ExportDependencies
+		out         *Export
+		expectedErr error
+	}{
+		{
+			name: "Regular valid case",
+			in:   &ExportDependencies{Repo: exportmock, AccountRepo: accountmock, SessionRepo: sessionmock, SecurityEventRepo: eventmock},
+			out: &Export{
+				repo:              exportmock,
+				accountrepo:       accountmock,
+				sessionrepo:       sessionmock,
+				securityeventrepo: eventmock,
+				retention:         DefaultExportRetention,
+			},
+		},
+		{
+			name:        "Dependencies are nil",
+			in:          nil,
+			expectedErr: ErrDependenciesAreNil,
+		},
+		{
+			name:        "Repo is nil",
+			in:          &ExportDependencies{AccountRepo: accountmock, SessionRepo: sessionmock, SecurityEventRepo: eventmock},
+			expectedErr: ErrExportRepoIsNil,
+		},
+		{
+			name:        "Account repo is nil",
+			in:          &ExportDependencies{Repo: exportmock, SessionRepo: sessionmock, SecurityEventRepo: eventmock},
+			expectedErr: ErrAccountRepoIsNil,
+		},
+		{
+			name:        "Session repo is nil",
+			in:          &ExportDependencies{Repo: exportmock, AccountRepo: accountmock, SecurityEventRepo: eventmock},
+			expectedErr: ErrSessionRepoIsNil,
+		},
+		{
+			name:        "Security event repo is nil",
+			in:          &ExportDependencies{Repo: exportmock, AccountRepo: accountmock, SessionRepo: sessionmock},
+			expectedErr: ErrSecurityEventRepoIsNil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			uc, err := NewExport(tc.in)
+			assert.Equal(t, tc.out, uc)
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestExport_RequestExport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exportmock := usecases_test.NewMockIExportRepo(ctrl)
+	accountmock := usecases_test.NewMockIAccountRepo(ctrl)
+	ctx := context.TODO()
+
+	active := &entities.Account{UUID: "accountuuid", Status: entities.Active}
+	unfinished := &entities.AccountExport{UUID: "exportuuid", AccountUUID: "accountuuid", Status: entities.ExportProcessing}
+
+	testCases := []struct {
+		name        string
+		setupMocks  func()
+		expectedNew bool
+		expected    *entities.AccountExport
+		expectedErr error
+	}{
+		{
+			name: "New export",
+			setupMocks: func() {
+				accountmock.EXPECT().GetOneByUUID(ctx, "accountuuid").Return(active, nil)
+				exportmock.EXPECT().GetUnfinishedByAccount(ctx, "accountuuid").Return(nil, nil)
+				exportmock.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+			},
+			expectedNew: true,
+		},
+		{
+			name: "Unfinished export is returned",
+			setupMocks: func() {
+				accountmock.EXPECT().GetOneByUUID(ctx, "accountuuid").Return(active, nil)
+				exportmock.EXPECT().GetUnfinishedByAccount(ctx, "accountuuid").Return(unfinished, nil)
+			},
+			expected: unfinished,
+		},
+		{
+			name: "Account is not exist",
+			setupMocks: func() {
+				accountmock.EXPECT().GetOneByUUID(ctx, "accountuuid").Return(nil, repositories.NewErrAccountNotFoundByUUID("accountuuid"))
+			},
+			expectedErr: ErrAccountIsNotExist,
+		},
+		{
+			name: "Account is not active",
+			setupMocks: func() {
+				accountmock.EXPECT().GetOneByUUID(ctx, "accountuuid").Return(&entities.Account{UUID: "accountuuid", Status: entities.Deleted}, nil)
+			},
+			expectedErr: ErrAccountIsNotActive,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			uc := &Export{repo: exportmock, accountrepo: accountmock, retention: time.Hour}
+
+			export, err := uc.RequestExport(ctx, "accountuuid")
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			if tc.expectedNew {
+				assert.NotEmpty(t, export.UUID)
+				assert.Equal(t, "accountuuid", export.AccountUUID)
+				assert.Equal(t, entities.ExportPending, export.Status)
+				return
+			}
+			assert.Equal(t, tc.expected, export)
+		})
+	}
+}
+
+func TestExport_GetExportAndArchive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exportmock := usecases_test.NewMockIExportRepo(ctrl)
+	ctx := context.TODO()
+
+	future := time.Now().Add(time.Hour).Unix()
+	ready := &entities.AccountExport{UUID: "ready", AccountUUID: "owner", Status: entities.ExportReady, Archive: []byte("archive"), ExpiresAt: future}
+	pending := &entities.AccountExport{UUID: "pending", AccountUUID: "owner", Status: entities.ExportPending}
+	expired := &entities.AccountExport{UUID: "expired", AccountUUID: "owner", Status: entities.ExportReady, ExpiresAt: time.Now().Unix() - 1}
+
+	exportmock.EXPECT().GetOneByUUID(ctx, "ready").Return(ready, nil).AnyTimes()
+	exportmock.EXPECT().GetOneByUUID(ctx, "pending").Return(pending, nil).AnyTimes()
+	exportmock.EXPECT().GetOneByUUID(ctx, "expired").Return(expired, nil).AnyTimes()
+	exportmock.EXPECT().GetOneByUUID(ctx, "missing").Return(nil, repositories.NewErrExportNotFoundByUUID("missing")).AnyTimes()
+
+	uc := &Export{repo: exportmock, retention: time.Hour}
+
+	export, err := uc.GetExport(ctx, "owner", "ready")
+	require.NoError(t, err)
+	assert.Equal(t, ready, export)
+
+	_, err = uc.GetExport(ctx, "stranger", "ready")
+	assert.ErrorIs(t, err, ErrExportIsNotFound)
+
+	_, err = uc.GetExport(ctx, "owner", "missing")
+	assert.ErrorIs(t, err, ErrExportIsNotFound)
+
+	archive, err := uc.GetArchive(ctx, "ready")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("archive"), archive)
+
+	_, err = uc.GetArchive(ctx, "pending")
+	assert.ErrorIs(t, err, ErrExportIsNotReady)
+
+	_, err = uc.GetArchive(ctx, "expired")
+	assert.ErrorIs(t, err, ErrExportIsExpired)
+
+	_, err = uc.GetArchive(ctx, "missing")
+	assert.ErrorIs(t, err, ErrExportIsNotFound)
+}
+
+func TestExport_ProcessNext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exportmock := usecases_test.NewMockIExportRepo(ctrl)
+	accountmock := usecases_test.NewMockIAccountRepo(ctrl)
+	sessionmock := usecases_test.NewMockISessionRepo(ctrl)
+	eventmock := usecases_test.NewMockISecurityEventRepo(ctrl)
+	ctx := context.TODO()
+
+	claimed := &entities.AccountExport{UUID: "exportuuid", AccountUUID: "accountuuid", Status: entities.ExportProcessing, CreatedAt: 100}
+	account := &entities.Account{
+		UUID:      "accountuuid",
+		Email:     "some@email.com",
+		Name:      "SomeName",
+		Password:  "$2a$10$hashedpassword",
+		CreatedAt: 100,
+	}
+
+	t.Run("Nothing to process", func(t *testing.T) {
+		exportmock.EXPECT().ClaimPending(ctx, gomock.Any(), gomock.Any()).Return(nil, nil)
+
+		uc := &Export{repo: exportmock, accountrepo: accountmock, sessionrepo: sessionmock, securityeventrepo: eventmock, retention: time.Hour}
+		processed, err := uc.ProcessNext(ctx)
+		assert.NoError(t, err)
+		assert.False(t, processed)
+	})
+
+	t.Run("Archive is built", func(t *testing.T) {
+		var archive []byte
+		exportmock.EXPECT().ClaimPending(ctx, gomock.Any(), gomock.Any()).Return(claimed, nil)
+		accountmock.EXPECT().GetOneByUUID(ctx, "accountuuid").Return(account, nil)
+		sessionmock.EXPECT().GetByAccount(ctx, "accountuuid").Return([]*entities.Session{
+			{UUID: "sessionuuid", AccountUUID: "accountuuid", IP: "10.0.0.1", DeviceName: "Chrome on Windows", RevokedAt: 200},
+		}, nil)
+		// the first page is full, so the next one is read before the older events
+		firstpage := make([]*entities.SecurityEvent, MaxSecurityEventsLimit)
+		for i := range firstpage {
+			firstpage[i] = &entities.SecurityEvent{ID: int64(MaxSecurityEventsLimit + 1 - i), Type: entities.SecurityEventRefresh}
+		}
+		eventmock.EXPECT().Find(ctx, &repositories.SecurityEventFilter{AccountUUID: "accountuuid", Limit: MaxSecurityEventsLimit}).Return(firstpage, nil)
+		eventmock.EXPECT().Find(ctx, &repositories.SecurityEventFilter{AccountUUID: "accountuuid", BeforeID: 2, Limit: MaxSecurityEventsLimit}).Return([]*entities.SecurityEvent{
+			{ID: 1, AccountUUID: "accountuuid", Type: entities.SecurityEventSignInFailed, IP: "10.0.0.2", Details: "wrong password", CreatedAt: 150},
+		}, nil)
+		exportmock.EXPECT().SetReady(ctx, "exportuuid", gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, a []byte, readyat, expiresat int64) error {
+				archive = a
+				assert.Equal(t, int64(time.Hour.Seconds()), expiresat-readyat)
+				return nil
+			})
+
+		uc := &Export{repo: exportmock, accountrepo: accountmock, sessionrepo: sessionmock, securityeventrepo: eventmock, retention: time.Hour}
+		processed, err := uc.ProcessNext(ctx)
+		require.NoError(t, err)
+		assert.True(t, processed)
+
+		r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		require.NoError(t, err)
+		require.Len(t, r.File, 3)
+		assert.Equal(t, "account.json", r.File[0].Name)
+		assert.Equal(t, "sessions.json", r.File[1].Name)
+		assert.Equal(t, "security_events.json", r.File[2].Name)
+
+		var exported map[string]any
+		readArchiveFile(t, r.File[0], &exported)
+		assert.Equal(t, "some@email.com", exported["Email"])
+		assert.NotContains(t, exported, "Password")
+
+		var sessions []map[string]any
+		readArchiveFile(t, r.File[1], &sessions)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, "10.0.0.1", sessions[0]["IP"])
+		assert.Equal(t, float64(200), sessions[0]["RevokedAt"])
+
+		var events []map[string]any
+		readArchiveFile(t, r.File[2], &events)
+		require.Len(t, events, MaxSecurityEventsLimit+1)
+		assert.Equal(t, "refresh", events[0]["Type"])
+		assert.Equal(t, map[string]any{
+			"Type":      "signin_failed",
+			"IP":        "10.0.0.2",
+			"UserAgent": "",
+			"Details":   "wrong password",
+			"CreatedAt": float64(150),
+		}, events[MaxSecurityEventsLimit])
+	})
+
+	t.Run("Failed export", func(t *testing.T) {
+		exportmock.EXPECT().ClaimPending(ctx, gomock.Any(), gomock.Any()).Return(claimed, nil)
+		accountmock.EXPECT().GetOneByUUID(ctx, "accountuuid").Return(nil, errors.New("some repo error"))
+		exportmock.EXPECT().SetFailed(ctx, "exportuuid", gomock.Any(), gomock.Any()).Return(nil)
+
+		uc := &Export{repo: exportmock, accountrepo: accountmock, sessionrepo: sessionmock, securityeventrepo: eventmock, retention: time.Hour}
+		processed, err := uc.ProcessNext(ctx)
+		assert.EqualError(t, err, "some repo error")
+		assert.True(t, processed)
+	})
+}
+
+func readArchiveFile(t *testing.T, file *zip.File, v any) {
+	t.Helper()
+
+	f, err := file.Open()
+	require.NoError(t, err)
+	defer f.Close()
+
+	content, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(content, v))
+}
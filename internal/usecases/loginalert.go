@@ -0,0 +1,433 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"github.com/alexsibrin/runbot-auth/internal/useragent"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:generate mockgen -destination mocks/mock_loginalert.go -package usecases_test github.com/alexsibrin/runbot-auth/internal/usecases IMailer,IGeoIP,ILinkSigner,ILoginAlert
+
+const (
+	// LinkExpiresParam and LinkSignatureParam are the query parameters of the signed links in the emails
+	LinkExpiresParam   = "expires"
+	LinkSignatureParam = "signature"
+
+	// DefaultLinkTTL is the lifetime of the links in the login alerts, a link with a shorter one may expire unread
+	DefaultLinkTTL = 24 * time.Hour
+
+	notMeResource         = "notme:"
+	passwordResetResource = "password-reset:"
+
+	passwordResetRequiredNotMe = "reset required: the sign in wasn't recognized"
+	passwordChangedReset       = "password reset"
+
+	loginAlertSubject    = "New sign in to your account"
+	passwordResetSubject = "Reset the password of your account"
+)
+
+var (
+	ErrMailerIsNil             = errors.New("dependency mailer is nil")
+	ErrLinkSignerIsNil         = errors.New("dependency link signer is nil")
+	ErrLoggerIsNil             = errors.New("dependency logger is nil")
+	ErrNotMeURLIsEmpty         = errors.New("not me URL is empty")
+	ErrPasswordResetURLIsEmpty = errors.New("password reset URL is empty")
+	ErrPasswordResetRequired   = errors.New("password reset is required")
+)
+
+type IMailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+type IGeoIP interface {
+	// Country returns the ISO code of the IP country, the empty string if it's unknown
+	Country(ip string) string
+}
+
+type ILinkSigner interface {
+	Sign(resource string) (string, string)
+	Verify(resource, expires, signature string) error
+}
+
+type ILoginAlert interface {
+	// Check notifies the owner if the account is signed in from a new device or an unusual location
+	Check(ctx context.Context, account *entities.Account, ip, useragent string) error
+}
+
+// PasswordResetToken authorizes setting a new password, it's valid until the password is changed or it expires
+type PasswordResetToken struct {
+	AccountUUID string
+	Expires     string
+	Signature   string
+}
+
+type PasswordResetRequest struct {
+	AccountUUID string
+	Expires     string
+	Signature   string
+	Password    string
+	IP          string
+	UserAgent   string
+}
+
+type LoginAlertDependencies struct {
+	AccountRepo    IAccountRepo
+	SessionRepo    ISessionRepo
+	Transactor     ITransactor
+	PasswordHasher IPasswordHasher
+	Mailer         IMailer
+	// GeoIP is optional, the sign in location isn't compared without it
+	GeoIP IGeoIP
+	Links ILinkSigner
+	// NotMeURL is the format of the "this wasn't me" link, %s is replaced with the account UUID
+	NotMeURL string
+	// PasswordResetURL is the format of the password reset link, %s is replaced with the account UUID
+	PasswordResetURL string
+	Logger           logapp.ILogger
+	// Metrics is optional
+	Metrics IMetrics
+}
+
+// LoginAlert emails the owner about the sign ins from the unknown devices and locations.
+// The "this wasn't me" link of the email signs all the devices out and makes the owner reset the password
+// by the emailed reset link, a new reset link is requested by the email of the account
+type LoginAlert struct {
+	accountrepo    IAccountRepo
+	sessionrepo    ISessionRepo
+	transactor     ITransactor
+	passwordhasher IPasswordHasher
+	mailer         IMailer
+	geoip          IGeoIP
+	links          ILinkSigner
+	notmeurl       string
+	reseturl       string
+	logger         logapp.ILogger
+	metrics        IMetrics
+}
+
+func NewLoginAlert(d *LoginAlertDependencies) (*LoginAlert, error) {
+	if d == nil {
+		return nil, ErrDependenciesAreNil
+	}
+	if d.AccountRepo == nil {
+		return nil, ErrAccountRepoIsNil
+	}
+	if d.SessionRepo == nil {
+		return nil, ErrSessionRepoIsNil
+	}
+	if d.Transactor == nil {
+		return nil, ErrTransactorIsNil
+	}
+	if d.PasswordHasher == nil {
+		return nil, ErrPaswordHasherIsNil
+	}
+	if d.Mailer == nil {
+		return nil, ErrMailerIsNil
+	}
+	if d.Links == nil {
+		return nil, ErrLinkSignerIsNil
+	}
+	if d.NotMeURL == "" {
+		return nil, ErrNotMeURLIsEmpty
+	}
+	if d.PasswordResetURL == "" {
+		return nil, ErrPasswordResetURLIsEmpty
+	}
+	if d.Logger == nil {
+		return nil, ErrLoggerIsNil
+	}
+	return &LoginAlert{
+		accountrepo:    d.AccountRepo,
+		sessionrepo:    d.SessionRepo,
+		transactor:     d.Transactor,
+		passwordhasher: d.PasswordHasher,
+		mailer:         d.Mailer,
+		geoip:          d.GeoIP,
+		links:          d.Links,
+		notmeurl:       d.NotMeURL,
+		reseturl:       d.PasswordResetURL,
+		logger:         d.Logger,
+		metrics:        d.Metrics,
+	}, nil
+}
+
+// Check compares the client with the devices the account has ever signed in from.
+// The email is sent in the background, so a slow or failed delivery doesn't affect the sign in
+func (u *LoginAlert) Check(ctx context.Context, account *entities.Account, ip, ua string) error {
+	sessions, err := u.sessionrepo.GetByAccount(ctx, account.UUID)
+	if err != nil {
+		return err
+	}
+
+	reasons := u.unusual(sessions, ip, ua)
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	u.send(ctx, account, loginAlertSubject, u.alertBody(account, ip, ua, reasons))
+	return nil
+}
+
+// CheckNotMe verifies the "this wasn't me" link without changing anything, the link is opened by GET,
+// so the mail scanners which prefetch it don't sign the owner out
+func (u *LoginAlert) CheckNotMe(ctx context.Context, accountuuid, expires, signature string) error {
+	account, err := u.accountrepo.GetOneByUUID(ctx, accountuuid)
+	if err != nil {
+		return err
+	}
+	if err = u.links.Verify(notMeLinkResource(account), expires, signature); err != nil {
+		return err
+	}
+	if account.IsDeleted() {
+		return ErrAccountIsDeleted
+	}
+	return nil
+}
+
+// NotMe signs all the devices out and clears the password, the returned token resets it and its link
+// is emailed to the owner too. The link is bound to the last account update, so it works once and not after
+// the password is reset
+func (u *LoginAlert) NotMe(ctx context.Context, accountuuid, expires, signature string) (*PasswordResetToken, error) {
+	now := time.Now().Unix()
+	var account *entities.Account
+	err := u.transactor.WithTx(ctx, func(repos *TxRepos) error {
+		var err error
+		account, err = repos.Account.GetOneByUUID(ctx, accountuuid)
+		if err != nil {
+			return err
+		}
+		if err = u.links.Verify(notMeLinkResource(account), expires, signature); err != nil {
+			return err
+		}
+		if account.IsDeleted() {
+			return ErrAccountIsDeleted
+		}
+		// the update time has to change even within the same second, otherwise the link would work again
+		account.UpdatedAt = max(now, account.UpdatedAt+1)
+		if err = repos.Session.RevokeAllByAccount(ctx, accountuuid, now); err != nil {
+			return err
+		}
+		if err = repos.Account.SetPassword(ctx, accountuuid, "", account.UpdatedAt); err != nil {
+			return err
+		}
+		_, err = repos.SecurityEvent.Add(ctx, &entities.SecurityEvent{
+			AccountUUID: accountuuid,
+			Type:        entities.SecurityEventPasswordChanged,
+			Details:     passwordResetRequiredNotMe,
+			CreatedAt:   now,
+		})
+		if err != nil {
+			return err
+		}
+		return addDomainEvent(ctx, repos.Outbox, entities.DomainEventPasswordChanged, accountuuid, &passwordChangedData{ResetRequired: true})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if u.metrics != nil {
+		u.metrics.LockedOut(lockedOutNotMe)
+	}
+
+	token := u.resetToken(account)
+	u.send(ctx, account, passwordResetSubject, u.resetBody(account, token, true))
+	return token, nil
+}
+
+// RequestPasswordReset emails the password reset link to the owner of the account. It doesn't tell
+// whether the account exists, so the unknown and the deleted accounts get nothing without an error
+func (u *LoginAlert) RequestPasswordReset(ctx context.Context, email string) error {
+	account, err := u.accountrepo.GetOneByEmail(ctx, email)
+	if err != nil {
+		if errors.As(err, &repositories.ErrAccountNotFoundByEmail{}) {
+			return nil
+		}
+		return err
+	}
+	if account.IsDeleted() {
+		return nil
+	}
+
+	u.send(ctx, account, passwordResetSubject, u.resetBody(account, u.resetToken(account), false))
+	return nil
+}
+
+// CheckPasswordReset verifies the password reset link without changing anything
+func (u *LoginAlert) CheckPasswordReset(ctx context.Context, accountuuid, expires, signature string) error {
+	account, err := u.accountrepo.GetOneByUUID(ctx, accountuuid)
+	if err != nil {
+		return err
+	}
+	if err = u.links.Verify(passwordResetLinkResource(account), expires, signature); err != nil {
+		return err
+	}
+	if account.IsDeleted() {
+		return ErrAccountIsDeleted
+	}
+	return nil
+}
+
+// ResetPassword sets the new password and signs all the devices out.
+// The token is bound to the last account update, so it can't be used once the password is set
+func (u *LoginAlert) ResetPassword(ctx context.Context, r *PasswordResetRequest) error {
+	pswdhash, err := u.passwordhasher.Hash(r.Password)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	return u.transactor.WithTx(ctx, func(repos *TxRepos) error {
+		account, err := repos.Account.GetOneByUUID(ctx, r.AccountUUID)
+		if err != nil {
+			return err
+		}
+		if err = u.links.Verify(passwordResetLinkResource(account), r.Expires, r.Signature); err != nil {
+			return err
+		}
+		if account.IsDeleted() {
+			return ErrAccountIsDeleted
+		}
+
+		if err = repos.Account.SetPassword(ctx, account.UUID, pswdhash, now); err != nil {
+			return err
+		}
+		if err = repos.Session.RevokeAllByAccount(ctx, account.UUID, now); err != nil {
+			return err
+		}
+		_, err = repos.SecurityEvent.Add(ctx, &entities.SecurityEvent{
+			AccountUUID: account.UUID,
+			Type:        entities.SecurityEventPasswordChanged,
+			IP:          r.IP,
+			UserAgent:   truncate(r.UserAgent, userAgentMaxLength),
+			Details:     passwordChangedReset,
+			CreatedAt:   now,
+		})
+		if err != nil {
+			return err
+		}
+		return addDomainEvent(ctx, repos.Outbox, entities.DomainEventPasswordChanged, account.UUID, &passwordChangedData{})
+	})
+}
+
+// notMeLinkResource is signed by the "this wasn't me" links, any account update, e.g. the password being cleared
+// by the link or reset, invalidates the links issued before
+func notMeLinkResource(account *entities.Account) string {
+	return notMeResource + account.UUID + ":" + strconv.FormatInt(account.UpdatedAt, 10)
+}
+
+// passwordResetLinkResource is signed by the password reset tokens, setting the password updates the account,
+// so a token works once
+func passwordResetLinkResource(account *entities.Account) string {
+	return passwordResetResource + account.UUID + ":" + strconv.FormatInt(account.UpdatedAt, 10)
+}
+
+// resetToken signs the password reset of the account as it is now
+func (u *LoginAlert) resetToken(account *entities.Account) *PasswordResetToken {
+	expires, signature := u.links.Sign(passwordResetLinkResource(account))
+	return &PasswordResetToken{
+		AccountUUID: account.UUID,
+		Expires:     expires,
+		Signature:   signature,
+	}
+}
+
+// send emails the owner in the background, so a slow or failed delivery doesn't affect the request
+func (u *LoginAlert) send(ctx context.Context, account *entities.Account, subject, body string) {
+	go func() {
+		if err := u.mailer.Send(context.WithoutCancel(ctx), account.Email, subject, body); err != nil {
+			u.logger.WithField("account", account.UUID).Errorf("%q email isn't sent: %v", subject, err)
+		}
+	}()
+}
+
+// unusual returns why the sign in is unusual, the first sign in has nothing to compare with
+func (u *LoginAlert) unusual(sessions []*entities.Session, ip, ua string) []string {
+	if len(sessions) == 0 {
+		return nil
+	}
+
+	var reasons []string
+
+	device := useragent.Parse(ua).Name()
+	knowndevice := false
+	for _, session := range sessions {
+		if session.DeviceName == device {
+			knowndevice = true
+			break
+		}
+	}
+	if !knowndevice {
+		reasons = append(reasons, "new device: "+device)
+	}
+
+	if u.geoip == nil {
+		return reasons
+	}
+	country := u.geoip.Country(ip)
+	if country == "" {
+		return reasons
+	}
+	knowncountries := make(map[string]bool)
+	for _, session := range sessions {
+		if c := u.geoip.Country(session.IP); c != "" {
+			knowncountries[c] = true
+		}
+	}
+	// the location is unusual only if some of the previous ones are known
+	if len(knowncountries) > 0 && !knowncountries[country] {
+		reasons = append(reasons, "unusual location: "+country)
+	}
+	return reasons
+}
+
+func (u *LoginAlert) alertBody(account *entities.Account, ip, ua string, reasons []string) string {
+	expires, signature := u.links.Sign(notMeLinkResource(account))
+	query := url.Values{
+		LinkExpiresParam:   []string{expires},
+		LinkSignatureParam: []string{signature},
+	}
+	link := fmt.Sprintf(u.notmeurl, account.UUID) + "?" + query.Encode()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hello %s,\n\n", account.Name)
+	b.WriteString("Your account was just signed in:\n")
+	for _, reason := range reasons {
+		fmt.Fprintf(&b, "  - %s\n", reason)
+	}
+	fmt.Fprintf(&b, "  - IP: %s\n", ip)
+	fmt.Fprintf(&b, "  - browser: %s\n", truncate(ua, userAgentMaxLength))
+	fmt.Fprintf(&b, "  - time: %s\n\n", time.Now().UTC().Format(time.RFC1123))
+	b.WriteString("If it was you, there is nothing to do.\n")
+	b.WriteString("If it wasn't you, follow the link and confirm to sign out all the devices and reset the password:\n")
+	b.WriteString(link + "\n")
+	return b.String()
+}
+
+func (u *LoginAlert) resetBody(account *entities.Account, token *PasswordResetToken, notme bool) string {
+	query := url.Values{
+		LinkExpiresParam:   []string{token.Expires},
+		LinkSignatureParam: []string{token.Signature},
+	}
+	link := fmt.Sprintf(u.reseturl, account.UUID) + "?" + query.Encode()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hello %s,\n\n", account.Name)
+	if notme {
+		b.WriteString("All the devices are signed out of your account and its password is cleared as you asked.\n")
+	} else {
+		b.WriteString("A password reset was requested for your account.\n")
+	}
+	b.WriteString("Follow the link to set a new password, it works once:\n")
+	b.WriteString(link + "\n")
+	if !notme {
+		b.WriteString("If you didn't ask for it, there is nothing to do, the password stays the same.\n")
+	}
+	return b.String()
+}
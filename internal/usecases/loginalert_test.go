@@ -0,0 +1,461 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	usecases_test "github.com/alexsibrin/runbot-auth/internal/usecases/mocks"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestLoginAlertInit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	accountmock := usecases_test.NewMockIAccountRepo(ctrl)
+	sessionmock := usecases_test.NewMockISessionRepo(ctrl)
+	hashmock := usecases_test.NewMockIPasswordHasher(ctrl)
+	mailmock := usecases_test.NewMockIMailer(ctrl)
+	linksmock := usecases_test.NewMockILinkSigner(ctrl)
+	txstub := &txStub{}
+	logger := logrus.New()
+
+	valid := func() *LoginAlertDependencies {
+		return &LoginAlertDependencies{
+			AccountRepo:      accountmock,
+			SessionRepo:      sessionmock,
+			Transactor:       txstub,
+			PasswordHasher:   hashmock,
+			Mailer:           mailmock,
+			Links:            linksmock,
+			NotMeURL:         "https://auth.runbot.io/v1/account/not-me/%s",
+			PasswordResetURL: "https://auth.runbot.io/v1/password-reset/%s",
+			Logger:           logger,
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		modify      func(d *LoginAlertDependencies)
+		expectedErr error
+	}{
+		{name: "Regular valid case", modify: func(d *LoginAlertDependencies) {}},
+		{name: "Account repo is nil", modify: func(d *LoginAlertDependencies) { d.AccountRepo = nil }, expectedErr: ErrAccountRepoIsNil},
+		{name: "Session repo is nil", modify: func(d *LoginAlertDependencies) { d.SessionRepo = nil }, expectedErr: ErrSessionRepoIsNil},
+		{name: "Transactor is nil", modify: func(d *LoginAlertDependencies) { d.Transactor = nil }, expectedErr: ErrTransactorIsNil},
+		{name: "Password hasher is nil", modify: func(d *LoginAlertDependencies) { d.PasswordHasher = nil }, expectedErr: ErrPaswordHasherIsNil},
+		{name: "Mailer is nil", modify: func(d *LoginAlertDependencies) { d.Mailer = nil }, expectedErr: ErrMailerIsNil},
+		{name: "Link signer is nil", modify: func(d *LoginAlertDependencies) { d.Links = nil }, expectedErr: ErrLinkSignerIsNil},
+		{name: "Not me URL is empty", modify: func(d *LoginAlertDependencies) { d.NotMeURL = "" }, expectedErr: ErrNotMeURLIsEmpty},
+		{name: "Password reset URL is empty", modify: func(d *LoginAlertDependencies) { d.PasswordResetURL = "" }, expectedErr: ErrPasswordResetURLIsEmpty},
+		{name: "Logger is nil", modify: func(d *LoginAlertDependencies) { d.Logger = nil }, expectedErr: ErrLoggerIsNil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := valid()
+			tc.modify(d)
+			uc, err := NewLoginAlert(d)
+			assert.ErrorIs(t, err, tc.expectedErr)
+			if tc.expectedErr == nil {
+				assert.NotNil(t, uc)
+			}
+		})
+	}
+
+	_, err := NewLoginAlert(nil)
+	assert.ErrorIs(t, err, ErrDependenciesAreNil)
+}
+
+func TestLoginAlert_Check(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sessionmock := usecases_test.NewMockISessionRepo(ctrl)
+	mailmock := usecases_test.NewMockIMailer(ctrl)
+	linksmock := usecases_test.NewMockILinkSigner(ctrl)
+	geoipmock := usecases_test.NewMockIGeoIP(ctrl)
+	uc := &LoginAlert{
+		sessionrepo: sessionmock,
+		mailer:      mailmock,
+		geoip:       geoipmock,
+		links:       linksmock,
+		notmeurl:    "https://auth.runbot.io/v1/account/not-me/%s",
+		logger:      logrus.New(),
+	}
+	ctx := context.TODO()
+	account := &entities.Account{UUID: "accountuuid", Email: "bob@example.com", Name: "bob", UpdatedAt: 50}
+	known := []*entities.Session{{AccountUUID: "accountuuid", IP: "203.0.113.1", DeviceName: "Chrome on Windows"}}
+
+	// expectAlert expects the email and returns the channel of its body
+	expectAlert := func() chan string {
+		sent := make(chan string, 1)
+		linksmock.EXPECT().Sign("notme:accountuuid:50").Return("100", "somesignature")
+		mailmock.EXPECT().Send(gomock.Any(), "bob@example.com", loginAlertSubject, gomock.Any()).DoAndReturn(func(_ context.Context, _, _, body string) error {
+			sent <- body
+			return nil
+		})
+		return sent
+	}
+	waitAlert := func(t *testing.T, sent chan string) string {
+		select {
+		case body := <-sent:
+			return body
+		case <-time.After(time.Second):
+			t.Fatal("login alert isn't sent")
+			return ""
+		}
+	}
+
+	t.Run("Known device and location", func(t *testing.T) {
+		sessionmock.EXPECT().GetByAccount(ctx, "accountuuid").Return(known, nil)
+		geoipmock.EXPECT().Country("203.0.113.2").Return("AU")
+		geoipmock.EXPECT().Country("203.0.113.1").Return("AU")
+
+		require.NoError(t, uc.Check(ctx, account, "203.0.113.2", chromeOnWindows))
+	})
+
+	t.Run("New device", func(t *testing.T) {
+		sessionmock.EXPECT().GetByAccount(ctx, "accountuuid").Return(known, nil)
+		geoipmock.EXPECT().Country("203.0.113.2").Return("AU")
+		geoipmock.EXPECT().Country("203.0.113.1").Return("AU")
+		sent := expectAlert()
+
+		require.NoError(t, uc.Check(ctx, account, "203.0.113.2", "curl/8.5.0"))
+		body := waitAlert(t, sent)
+		assert.Contains(t, body, "new device: curl")
+		assert.NotContains(t, body, "unusual location")
+		assert.Contains(t, body, "https://auth.runbot.io/v1/account/not-me/accountuuid?expires=100&signature=somesignature")
+	})
+
+	t.Run("Unusual location", func(t *testing.T) {
+		sessionmock.EXPECT().GetByAccount(ctx, "accountuuid").Return(known, nil)
+		geoipmock.EXPECT().Country("198.51.100.1").Return("DE")
+		geoipmock.EXPECT().Country("203.0.113.1").Return("AU")
+		sent := expectAlert()
+
+		require.NoError(t, uc.Check(ctx, account, "198.51.100.1", chromeOnWindows))
+		body := waitAlert(t, sent)
+		assert.Contains(t, body, "unusual location: DE")
+		assert.NotContains(t, body, "new device")
+	})
+
+	t.Run("Unknown location isn't unusual", func(t *testing.T) {
+		sessionmock.EXPECT().GetByAccount(ctx, "accountuuid").Return(known, nil)
+		geoipmock.EXPECT().Country("10.0.0.1").Return("")
+
+		require.NoError(t, uc.Check(ctx, account, "10.0.0.1", chromeOnWindows))
+	})
+
+	t.Run("First sign in", func(t *testing.T) {
+		sessionmock.EXPECT().GetByAccount(ctx, "accountuuid").Return(nil, nil)
+
+		require.NoError(t, uc.Check(ctx, account, "10.0.0.1", "curl/8.5.0"))
+	})
+
+	t.Run("Failed delivery doesn't fail the check", func(t *testing.T) {
+		sent := make(chan struct{})
+		sessionmock.EXPECT().GetByAccount(ctx, "accountuuid").Return(known, nil)
+		geoipmock.EXPECT().Country("203.0.113.2").Return("AU")
+		geoipmock.EXPECT().Country("203.0.113.1").Return("AU")
+		linksmock.EXPECT().Sign("notme:accountuuid:50").Return("100", "somesignature")
+		mailmock.EXPECT().Send(gomock.Any(), "bob@example.com", loginAlertSubject, gomock.Any()).DoAndReturn(func(context.Context, string, string, string) error {
+			close(sent)
+			return errors.New("connection refused")
+		})
+
+		require.NoError(t, uc.Check(ctx, account, "203.0.113.2", "curl/8.5.0"))
+		<-sent
+	})
+
+	t.Run("Repo fails", func(t *testing.T) {
+		someErr := errors.New("some error")
+		sessionmock.EXPECT().GetByAccount(ctx, "accountuuid").Return(nil, someErr)
+
+		assert.ErrorIs(t, uc.Check(ctx, account, "10.0.0.1", "curl/8.5.0"), someErr)
+	})
+}
+
+func TestLoginAlert_CheckNotMe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountmock := usecases_test.NewMockIAccountRepo(ctrl)
+	linksmock := usecases_test.NewMockILinkSigner(ctrl)
+	uc := &LoginAlert{
+		accountrepo: accountmock,
+		links:       linksmock,
+	}
+	ctx := context.TODO()
+	errSignature := errors.New("url signature is not valid")
+
+	t.Run("Link is valid", func(t *testing.T) {
+		accountmock.EXPECT().GetOneByUUID(ctx, "accountuuid").Return(&entities.Account{UUID: "accountuuid", UpdatedAt: 50}, nil)
+		linksmock.EXPECT().Verify("notme:accountuuid:50", "100", "somesignature").Return(nil)
+
+		assert.NoError(t, uc.CheckNotMe(ctx, "accountuuid", "100", "somesignature"))
+	})
+
+	t.Run("Account is updated after the link", func(t *testing.T) {
+		accountmock.EXPECT().GetOneByUUID(ctx, "accountuuid").Return(&entities.Account{UUID: "accountuuid", UpdatedAt: 60}, nil)
+		linksmock.EXPECT().Verify("notme:accountuuid:60", "100", "somesignature").Return(errSignature)
+
+		assert.ErrorIs(t, uc.CheckNotMe(ctx, "accountuuid", "100", "somesignature"), errSignature)
+	})
+
+	t.Run("Account is deleted", func(t *testing.T) {
+		accountmock.EXPECT().GetOneByUUID(ctx, "accountuuid").Return(&entities.Account{UUID: "accountuuid", UpdatedAt: 50, Status: entities.Deleted}, nil)
+		linksmock.EXPECT().Verify("notme:accountuuid:50", "100", "somesignature").Return(nil)
+
+		assert.ErrorIs(t, uc.CheckNotMe(ctx, "accountuuid", "100", "somesignature"), ErrAccountIsDeleted)
+	})
+}
+
+func TestLoginAlert_NotMe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountmock := usecases_test.NewMockIAccountRepo(ctrl)
+	sessionmock := usecases_test.NewMockISessionRepo(ctrl)
+	eventmock := usecases_test.NewMockISecurityEventRepo(ctrl)
+	outboxmock := usecases_test.NewMockIOutboxRepo(ctrl)
+	linksmock := usecases_test.NewMockILinkSigner(ctrl)
+	mailmock := usecases_test.NewMockIMailer(ctrl)
+	uc := &LoginAlert{
+		transactor: &txStub{repos: &TxRepos{Account: accountmock, Session: sessionmock, SecurityEvent: eventmock, Outbox: outboxmock}},
+		links:      linksmock,
+		mailer:     mailmock,
+		reseturl:   "https://auth.runbot.io/v1/password-reset/%s",
+		logger:     logrus.New(),
+	}
+	ctx := context.TODO()
+	errSignature := errors.New("url signature is not valid")
+
+	t.Run("Sessions are revoked and the password is cleared", func(t *testing.T) {
+		accountmock.EXPECT().GetOneByUUID(ctx, "accountuuid").Return(&entities.Account{UUID: "accountuuid", Email: "bob@example.com", UpdatedAt: 50}, nil)
+		linksmock.EXPECT().Verify("notme:accountuuid:50", "100", "somesignature").Return(nil)
+		sessionmock.EXPECT().RevokeAllByAccount(ctx, "accountuuid", gomock.Any()).Return(nil)
+		var clearedat int64
+		accountmock.EXPECT().SetPassword(ctx, "accountuuid", "", gomock.Any()).DoAndReturn(func(_ context.Context, _, _ string, updatedat int64) error {
+			clearedat = updatedat
+			return nil
+		})
+		eventmock.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *entities.SecurityEvent) (*entities.SecurityEvent, error) {
+			assert.Equal(t, entities.SecurityEventPasswordChanged, event.Type)
+			assert.Equal(t, passwordResetRequiredNotMe, event.Details)
+			return event, nil
+		})
+		outboxmock.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *entities.DomainEvent) (*entities.DomainEvent, error) {
+			assert.Equal(t, entities.DomainEventPasswordChanged, event.Type)
+			assert.Equal(t, "accountuuid", event.AccountUUID)
+			assert.JSONEq(t, `{"ResetRequired":true}`, event.Payload)
+			return event, nil
+		})
+		linksmock.EXPECT().Sign(gomock.Any()).DoAndReturn(func(resource string) (string, string) {
+			assert.Equal(t, "password-reset:accountuuid:"+strconv.FormatInt(clearedat, 10), resource)
+			return "200", "resetsignature"
+		})
+		sent := expectEmail(mailmock, "bob@example.com", passwordResetSubject)
+
+		token, err := uc.NotMe(ctx, "accountuuid", "100", "somesignature")
+		require.NoError(t, err)
+		assert.Equal(t, &PasswordResetToken{AccountUUID: "accountuuid", Expires: "200", Signature: "resetsignature"}, token)
+		assert.Contains(t, waitEmail(t, sent), "https://auth.runbot.io/v1/password-reset/accountuuid?expires=200&signature=resetsignature")
+	})
+
+	t.Run("Update time changes within the same second", func(t *testing.T) {
+		future := time.Now().Add(time.Hour).Unix()
+		accountmock.EXPECT().GetOneByUUID(ctx, "accountuuid").Return(&entities.Account{UUID: "accountuuid", UpdatedAt: future}, nil)
+		linksmock.EXPECT().Verify(gomock.Any(), "100", "somesignature").Return(nil)
+		sessionmock.EXPECT().RevokeAllByAccount(ctx, "accountuuid", gomock.Any()).Return(nil)
+		accountmock.EXPECT().SetPassword(ctx, "accountuuid", "", future+1).Return(nil)
+		eventmock.EXPECT().Add(ctx, gomock.Any()).Return(&entities.SecurityEvent{}, nil)
+		outboxmock.EXPECT().Add(ctx, gomock.Any()).Return(&entities.DomainEvent{}, nil)
+		linksmock.EXPECT().Sign("password-reset:accountuuid:"+strconv.FormatInt(future+1, 10)).Return("200", "resetsignature")
+		sent := expectEmail(mailmock, gomock.Any(), passwordResetSubject)
+
+		_, err := uc.NotMe(ctx, "accountuuid", "100", "somesignature")
+		require.NoError(t, err)
+		waitEmail(t, sent)
+	})
+
+	t.Run("Link is used or the password is reset", func(t *testing.T) {
+		accountmock.EXPECT().GetOneByUUID(ctx, "accountuuid").Return(&entities.Account{UUID: "accountuuid", UpdatedAt: 60}, nil)
+		linksmock.EXPECT().Verify("notme:accountuuid:60", "100", "somesignature").Return(errSignature)
+
+		_, err := uc.NotMe(ctx, "accountuuid", "100", "somesignature")
+		assert.ErrorIs(t, err, errSignature)
+	})
+
+	t.Run("Account is deleted", func(t *testing.T) {
+		accountmock.EXPECT().GetOneByUUID(ctx, "accountuuid").Return(&entities.Account{UUID: "accountuuid", UpdatedAt: 50, Status: entities.Deleted}, nil)
+		linksmock.EXPECT().Verify("notme:accountuuid:50", "100", "somesignature").Return(nil)
+
+		_, err := uc.NotMe(ctx, "accountuuid", "100", "somesignature")
+		assert.ErrorIs(t, err, ErrAccountIsDeleted)
+	})
+}
+
+func TestLoginAlert_RequestPasswordReset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountmock := usecases_test.NewMockIAccountRepo(ctrl)
+	linksmock := usecases_test.NewMockILinkSigner(ctrl)
+	mailmock := usecases_test.NewMockIMailer(ctrl)
+	uc := &LoginAlert{
+		accountrepo: accountmock,
+		links:       linksmock,
+		mailer:      mailmock,
+		reseturl:    "https://auth.runbot.io/v1/password-reset/%s",
+		logger:      logrus.New(),
+	}
+	ctx := context.TODO()
+
+	t.Run("Link is emailed", func(t *testing.T) {
+		accountmock.EXPECT().GetOneByEmail(ctx, "bob@example.com").Return(&entities.Account{UUID: "accountuuid", Email: "bob@example.com", UpdatedAt: 50}, nil)
+		linksmock.EXPECT().Sign("password-reset:accountuuid:50").Return("200", "resetsignature")
+		sent := expectEmail(mailmock, "bob@example.com", passwordResetSubject)
+
+		require.NoError(t, uc.RequestPasswordReset(ctx, "bob@example.com"))
+		assert.Contains(t, waitEmail(t, sent), "https://auth.runbot.io/v1/password-reset/accountuuid?expires=200&signature=resetsignature")
+	})
+
+	t.Run("Unknown email isn't disclosed", func(t *testing.T) {
+		accountmock.EXPECT().GetOneByEmail(ctx, "alice@example.com").Return(nil, repositories.NewErrAccountNotFoundByEmail("alice@example.com"))
+
+		assert.NoError(t, uc.RequestPasswordReset(ctx, "alice@example.com"))
+	})
+
+	t.Run("Deleted account gets nothing", func(t *testing.T) {
+		accountmock.EXPECT().GetOneByEmail(ctx, "bob@example.com").Return(&entities.Account{UUID: "accountuuid", Status: entities.Deleted}, nil)
+
+		assert.NoError(t, uc.RequestPasswordReset(ctx, "bob@example.com"))
+	})
+
+	t.Run("Repository fails", func(t *testing.T) {
+		someErr := errors.New("some error")
+		accountmock.EXPECT().GetOneByEmail(ctx, "bob@example.com").Return(nil, someErr)
+
+		assert.ErrorIs(t, uc.RequestPasswordReset(ctx, "bob@example.com"), someErr)
+	})
+}
+
+func TestLoginAlert_CheckPasswordReset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountmock := usecases_test.NewMockIAccountRepo(ctrl)
+	linksmock := usecases_test.NewMockILinkSigner(ctrl)
+	uc := &LoginAlert{
+		accountrepo: accountmock,
+		links:       linksmock,
+	}
+	ctx := context.TODO()
+	errSignature := errors.New("url signature is not valid")
+
+	t.Run("Link is valid", func(t *testing.T) {
+		accountmock.EXPECT().GetOneByUUID(ctx, "accountuuid").Return(&entities.Account{UUID: "accountuuid", UpdatedAt: 150}, nil)
+		linksmock.EXPECT().Verify("password-reset:accountuuid:150", "200", "resetsignature").Return(nil)
+
+		assert.NoError(t, uc.CheckPasswordReset(ctx, "accountuuid", "200", "resetsignature"))
+	})
+
+	t.Run("Password is reset already", func(t *testing.T) {
+		accountmock.EXPECT().GetOneByUUID(ctx, "accountuuid").Return(&entities.Account{UUID: "accountuuid", UpdatedAt: 300}, nil)
+		linksmock.EXPECT().Verify("password-reset:accountuuid:300", "200", "resetsignature").Return(errSignature)
+
+		assert.ErrorIs(t, uc.CheckPasswordReset(ctx, "accountuuid", "200", "resetsignature"), errSignature)
+	})
+
+	t.Run("Account is deleted", func(t *testing.T) {
+		accountmock.EXPECT().GetOneByUUID(ctx, "accountuuid").Return(&entities.Account{UUID: "accountuuid", UpdatedAt: 150, Status: entities.Deleted}, nil)
+		linksmock.EXPECT().Verify("password-reset:accountuuid:150", "200", "resetsignature").Return(nil)
+
+		assert.ErrorIs(t, uc.CheckPasswordReset(ctx, "accountuuid", "200", "resetsignature"), ErrAccountIsDeleted)
+	})
+}
+
+// expectEmail expects the email and returns the channel of its body, the emails are sent in the background
+func expectEmail(mailmock *usecases_test.MockIMailer, to any, subject string) chan string {
+	sent := make(chan string, 1)
+	mailmock.EXPECT().Send(gomock.Any(), to, subject, gomock.Any()).DoAndReturn(func(_ context.Context, _, _, body string) error {
+		sent <- body
+		return nil
+	})
+	return sent
+}
+
+func waitEmail(t *testing.T, sent chan string) string {
+	select {
+	case body := <-sent:
+		return body
+	case <-time.After(time.Second):
+		t.Fatal("email isn't sent")
+		return ""
+	}
+}
+
+func TestLoginAlert_ResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountmock := usecases_test.NewMockIAccountRepo(ctrl)
+	sessionmock := usecases_test.NewMockISessionRepo(ctrl)
+	eventmock := usecases_test.NewMockISecurityEventRepo(ctrl)
+	outboxmock := usecases_test.NewMockIOutboxRepo(ctrl)
+	hashmock := usecases_test.NewMockIPasswordHasher(ctrl)
+	linksmock := usecases_test.NewMockILinkSigner(ctrl)
+	uc := &LoginAlert{
+		transactor:     &txStub{repos: &TxRepos{Account: accountmock, Session: sessionmock, SecurityEvent: eventmock, Outbox: outboxmock}},
+		passwordhasher: hashmock,
+		links:          linksmock,
+	}
+	ctx := context.TODO()
+	request := &PasswordResetRequest{
+		AccountUUID: "accountuuid",
+		Expires:     "200",
+		Signature:   "resetsignature",
+		Password:    "newpassword",
+		IP:          "10.0.0.1",
+		UserAgent:   "someagent",
+	}
+	errSignature := errors.New("url signature is not valid")
+
+	t.Run("Password is set", func(t *testing.T) {
+		hashmock.EXPECT().Hash("newpassword").Return("newhash", nil)
+		accountmock.EXPECT().GetOneByUUID(ctx, "accountuuid").Return(&entities.Account{UUID: "accountuuid", UpdatedAt: 150}, nil)
+		linksmock.EXPECT().Verify("password-reset:accountuuid:150", "200", "resetsignature").Return(nil)
+		accountmock.EXPECT().SetPassword(ctx, "accountuuid", "newhash", gomock.Any()).Return(nil)
+		sessionmock.EXPECT().RevokeAllByAccount(ctx, "accountuuid", gomock.Any()).Return(nil)
+		eventmock.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *entities.SecurityEvent) (*entities.SecurityEvent, error) {
+			assert.Equal(t, entities.SecurityEventPasswordChanged, event.Type)
+			assert.Equal(t, passwordChangedReset, event.Details)
+			assert.Equal(t, "10.0.0.1", event.IP)
+			return event, nil
+		})
+		expectDomainEvent(t, outboxmock, entities.DomainEventPasswordChanged, "accountuuid")
+
+		assert.NoError(t, uc.ResetPassword(ctx, request))
+	})
+
+	t.Run("Token is used already", func(t *testing.T) {
+		hashmock.EXPECT().Hash("newpassword").Return("newhash", nil)
+		accountmock.EXPECT().GetOneByUUID(ctx, "accountuuid").Return(&entities.Account{UUID: "accountuuid", UpdatedAt: 300}, nil)
+		linksmock.EXPECT().Verify("password-reset:accountuuid:300", "200", "resetsignature").Return(errSignature)
+
+		assert.ErrorIs(t, uc.ResetPassword(ctx, request), errSignature)
+	})
+
+	t.Run("Hasher fails", func(t *testing.T) {
+		someErr := errors.New("some error")
+		hashmock.EXPECT().Hash("newpassword").Return("", someErr)
+
+		assert.ErrorIs(t, uc.ResetPassword(ctx, request), someErr)
+	})
+}
@@ -0,0 +1,18 @@
+package usecases
+
+//go:generate mockgen -destination mocks/mock_metrics.go -package usecases_test github.com/alexsibrin/runbot-auth/internal/usecases IMetrics
+
+const (
+	signInFailedWrongEmail = "wrong email"
+
+	lockedOutNotMe = "not me"
+)
+
+// IMetrics counts the outcomes of the auth flows, the reasons are the ones of the security log
+type IMetrics interface {
+	SignedUp()
+	SignInFailed(reason string)
+	Refreshed()
+	// LockedOut counts the accounts which can't sign in anymore, reason is the status name or "not me"
+	LockedOut(reason string)
+}
@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/usecases (interfaces: IAccountImportRepo)
+//
+// Generated by this command:
+//
+//	mockgen -destination mocks/mock_accountimport.go -package usecases_test github.com/alexsibrin/runbot-auth/internal/usecases IAccountImportRepo
+//
+
+// Package usecases_test is a generated GoMock package.
+package usecases_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/alexsibrin/runbot-auth/internal/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIAccountImportRepo is a mock of IAccountImportRepo interface.
+type MockIAccountImportRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockIAccountImportRepoMockRecorder
+}
+
+// MockIAccountImportRepoMockRecorder is the mock recorder for MockIAccountImportRepo.
+type MockIAccountImportRepoMockRecorder struct {
+	mock *MockIAccountImportRepo
+}
+
+// NewMockIAccountImportRepo creates a new mock instance.
+func NewMockIAccountImportRepo(ctrl *gomock.Controller) *MockIAccountImportRepo {
+	mock := &MockIAccountImportRepo{ctrl: ctrl}
+	mock.recorder = &MockIAccountImportRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIAccountImportRepo) EXPECT() *MockIAccountImportRepoMockRecorder {
+	return m.recorder
+}
+
+// Import mocks base method.
+func (m *MockIAccountImportRepo) Import(arg0 context.Context, arg1 []*entities.Account) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockIAccountImportRepoMockRecorder) Import(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockIAccountImportRepo)(nil).Import), arg0, arg1)
+}
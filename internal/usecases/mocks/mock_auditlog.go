@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/usecases (interfaces: IAuditLogRepo)
+//
+// Generated by this command:
+//
+//	mockgen -destination mocks/mock_auditlog.go -package usecases_test github.com/alexsibrin/runbot-auth/internal/usecases IAuditLogRepo
+//
+
+// Package usecases_test is a generated GoMock package.
+package usecases_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/alexsibrin/runbot-auth/internal/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIAuditLogRepo is a mock of IAuditLogRepo interface.
+type MockIAuditLogRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockIAuditLogRepoMockRecorder
+}
+
+// MockIAuditLogRepoMockRecorder is the mock recorder for MockIAuditLogRepo.
+type MockIAuditLogRepoMockRecorder struct {
+	mock *MockIAuditLogRepo
+}
+
+// NewMockIAuditLogRepo creates a new mock instance.
+func NewMockIAuditLogRepo(ctrl *gomock.Controller) *MockIAuditLogRepo {
+	mock := &MockIAuditLogRepo{ctrl: ctrl}
+	mock.recorder = &MockIAuditLogRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIAuditLogRepo) EXPECT() *MockIAuditLogRepoMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockIAuditLogRepo) Add(arg0 context.Context, arg1 *entities.AuditRecord) (*entities.AuditRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", arg0, arg1)
+	ret0, _ := ret[0].(*entities.AuditRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockIAuditLogRepoMockRecorder) Add(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockIAuditLogRepo)(nil).Add), arg0, arg1)
+}
+
+// Last mocks base method.
+func (m *MockIAuditLogRepo) Last(arg0 context.Context) (*entities.AuditRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Last", arg0)
+	ret0, _ := ret[0].(*entities.AuditRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Last indicates an expected call of Last.
+func (mr *MockIAuditLogRepoMockRecorder) Last(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Last", reflect.TypeOf((*MockIAuditLogRepo)(nil).Last), arg0)
+}
+
+// List mocks base method.
+func (m *MockIAuditLogRepo) List(arg0 context.Context, arg1 int64, arg2 int) ([]*entities.AuditRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*entities.AuditRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockIAuditLogRepoMockRecorder) List(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockIAuditLogRepo)(nil).List), arg0, arg1, arg2)
+}
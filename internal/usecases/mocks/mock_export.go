@@ -0,0 +1,157 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/usecases (interfaces: IExportRepo)
+//
+// Generated by this command:
+//
+//	mockgen -destination mocks/mock_export.go -package usecases_test github.com/alexsibrin/runbot-auth/internal/usecases IExportRepo
+//
+
+// Package usecases_test is a generated GoMock package.
+package usecases_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/alexsibrin/runbot-auth/internal/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIExportRepo is a mock of IExportRepo interface.
+type MockIExportRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockIExportRepoMockRecorder
+}
+
+// MockIExportRepoMockRecorder is the mock recorder for MockIExportRepo.
+type MockIExportRepoMockRecorder struct {
+	mock *MockIExportRepo
+}
+
+// NewMockIExportRepo creates a new mock instance.
+func NewMockIExportRepo(ctrl *gomock.Controller) *MockIExportRepo {
+	mock := &MockIExportRepo{ctrl: ctrl}
+	mock.recorder = &MockIExportRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIExportRepo) EXPECT() *MockIExportRepoMockRecorder {
+	return m.recorder
+}
+
+// ClaimPending mocks base method.
+func (m *MockIExportRepo) ClaimPending(arg0 context.Context, arg1, arg2 int64) (*entities.AccountExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimPending", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entities.AccountExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimPending indicates an expected call of ClaimPending.
+func (mr *MockIExportRepoMockRecorder) ClaimPending(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimPending", reflect.TypeOf((*MockIExportRepo)(nil).ClaimPending), arg0, arg1, arg2)
+}
+
+// Create mocks base method.
+func (m *MockIExportRepo) Create(arg0 context.Context, arg1 *entities.AccountExport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIExportRepoMockRecorder) Create(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIExportRepo)(nil).Create), arg0, arg1)
+}
+
+// DeleteByAccount mocks base method.
+func (m *MockIExportRepo) DeleteByAccount(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByAccount indicates an expected call of DeleteByAccount.
+func (mr *MockIExportRepoMockRecorder) DeleteByAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByAccount", reflect.TypeOf((*MockIExportRepo)(nil).DeleteByAccount), arg0, arg1)
+}
+
+// DeleteExpired mocks base method.
+func (m *MockIExportRepo) DeleteExpired(arg0 context.Context, arg1 int64) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockIExportRepoMockRecorder) DeleteExpired(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockIExportRepo)(nil).DeleteExpired), arg0, arg1)
+}
+
+// GetOneByUUID mocks base method.
+func (m *MockIExportRepo) GetOneByUUID(arg0 context.Context, arg1 string) (*entities.AccountExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOneByUUID", arg0, arg1)
+	ret0, _ := ret[0].(*entities.AccountExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOneByUUID indicates an expected call of GetOneByUUID.
+func (mr *MockIExportRepoMockRecorder) GetOneByUUID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOneByUUID", reflect.TypeOf((*MockIExportRepo)(nil).GetOneByUUID), arg0, arg1)
+}
+
+// GetUnfinishedByAccount mocks base method.
+func (m *MockIExportRepo) GetUnfinishedByAccount(arg0 context.Context, arg1 string) (*entities.AccountExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnfinishedByAccount", arg0, arg1)
+	ret0, _ := ret[0].(*entities.AccountExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnfinishedByAccount indicates an expected call of GetUnfinishedByAccount.
+func (mr *MockIExportRepoMockRecorder) GetUnfinishedByAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnfinishedByAccount", reflect.TypeOf((*MockIExportRepo)(nil).GetUnfinishedByAccount), arg0, arg1)
+}
+
+// SetFailed mocks base method.
+func (m *MockIExportRepo) SetFailed(arg0 context.Context, arg1 string, arg2, arg3 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFailed", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetFailed indicates an expected call of SetFailed.
+func (mr *MockIExportRepoMockRecorder) SetFailed(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFailed", reflect.TypeOf((*MockIExportRepo)(nil).SetFailed), arg0, arg1, arg2, arg3)
+}
+
+// SetReady mocks base method.
+func (m *MockIExportRepo) SetReady(arg0 context.Context, arg1 string, arg2 []byte, arg3, arg4 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReady", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetReady indicates an expected call of SetReady.
+func (mr *MockIExportRepoMockRecorder) SetReady(arg0, arg1, arg2, arg3, arg4 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReady", reflect.TypeOf((*MockIExportRepo)(nil).SetReady), arg0, arg1, arg2, arg3, arg4)
+}
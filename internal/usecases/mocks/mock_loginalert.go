@@ -0,0 +1,181 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/usecases (interfaces: IMailer,IGeoIP,ILinkSigner,ILoginAlert)
+//
+// Generated by this command:
+//
+//	mockgen -destination mocks/mock_loginalert.go -package usecases_test github.com/alexsibrin/runbot-auth/internal/usecases IMailer,IGeoIP,ILinkSigner,ILoginAlert
+//
+
+// Package usecases_test is a generated GoMock package.
+package usecases_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/alexsibrin/runbot-auth/internal/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIMailer is a mock of IMailer interface.
+type MockIMailer struct {
+	ctrl     *gomock.Controller
+	recorder *MockIMailerMockRecorder
+}
+
+// MockIMailerMockRecorder is the mock recorder for MockIMailer.
+type MockIMailerMockRecorder struct {
+	mock *MockIMailer
+}
+
+// NewMockIMailer creates a new mock instance.
+func NewMockIMailer(ctrl *gomock.Controller) *MockIMailer {
+	mock := &MockIMailer{ctrl: ctrl}
+	mock.recorder = &MockIMailerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIMailer) EXPECT() *MockIMailerMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockIMailer) Send(arg0 context.Context, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockIMailerMockRecorder) Send(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockIMailer)(nil).Send), arg0, arg1, arg2, arg3)
+}
+
+// MockIGeoIP is a mock of IGeoIP interface.
+type MockIGeoIP struct {
+	ctrl     *gomock.Controller
+	recorder *MockIGeoIPMockRecorder
+}
+
+// MockIGeoIPMockRecorder is the mock recorder for MockIGeoIP.
+type MockIGeoIPMockRecorder struct {
+	mock *MockIGeoIP
+}
+
+// NewMockIGeoIP creates a new mock instance.
+func NewMockIGeoIP(ctrl *gomock.Controller) *MockIGeoIP {
+	mock := &MockIGeoIP{ctrl: ctrl}
+	mock.recorder = &MockIGeoIPMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIGeoIP) EXPECT() *MockIGeoIPMockRecorder {
+	return m.recorder
+}
+
+// Country mocks base method.
+func (m *MockIGeoIP) Country(arg0 string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Country", arg0)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Country indicates an expected call of Country.
+func (mr *MockIGeoIPMockRecorder) Country(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Country", reflect.TypeOf((*MockIGeoIP)(nil).Country), arg0)
+}
+
+// MockILinkSigner is a mock of ILinkSigner interface.
+type MockILinkSigner struct {
+	ctrl     *gomock.Controller
+	recorder *MockILinkSignerMockRecorder
+}
+
+// MockILinkSignerMockRecorder is the mock recorder for MockILinkSigner.
+type MockILinkSignerMockRecorder struct {
+	mock *MockILinkSigner
+}
+
+// NewMockILinkSigner creates a new mock instance.
+func NewMockILinkSigner(ctrl *gomock.Controller) *MockILinkSigner {
+	mock := &MockILinkSigner{ctrl: ctrl}
+	mock.recorder = &MockILinkSignerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockILinkSigner) EXPECT() *MockILinkSignerMockRecorder {
+	return m.recorder
+}
+
+// Sign mocks base method.
+func (m *MockILinkSigner) Sign(arg0 string) (string, string) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sign", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	return ret0, ret1
+}
+
+// Sign indicates an expected call of Sign.
+func (mr *MockILinkSignerMockRecorder) Sign(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sign", reflect.TypeOf((*MockILinkSigner)(nil).Sign), arg0)
+}
+
+// Verify mocks base method.
+func (m *MockILinkSigner) Verify(arg0, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockILinkSignerMockRecorder) Verify(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockILinkSigner)(nil).Verify), arg0, arg1, arg2)
+}
+
+// MockILoginAlert is a mock of ILoginAlert interface.
+type MockILoginAlert struct {
+	ctrl     *gomock.Controller
+	recorder *MockILoginAlertMockRecorder
+}
+
+// MockILoginAlertMockRecorder is the mock recorder for MockILoginAlert.
+type MockILoginAlertMockRecorder struct {
+	mock *MockILoginAlert
+}
+
+// NewMockILoginAlert creates a new mock instance.
+func NewMockILoginAlert(ctrl *gomock.Controller) *MockILoginAlert {
+	mock := &MockILoginAlert{ctrl: ctrl}
+	mock.recorder = &MockILoginAlertMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockILoginAlert) EXPECT() *MockILoginAlertMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockILoginAlert) Check(arg0 context.Context, arg1 *entities.Account, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockILoginAlertMockRecorder) Check(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockILoginAlert)(nil).Check), arg0, arg1, arg2, arg3)
+}
@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/usecases (interfaces: IMetrics)
+//
+// Generated by this command:
+//
+//	mockgen -destination mocks/mock_metrics.go -package usecases_test github.com/alexsibrin/runbot-auth/internal/usecases IMetrics
+//
+
+// Package usecases_test is a generated GoMock package.
+package usecases_test
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIMetrics is a mock of IMetrics interface.
+type MockIMetrics struct {
+	ctrl     *gomock.Controller
+	recorder *MockIMetricsMockRecorder
+}
+
+// MockIMetricsMockRecorder is the mock recorder for MockIMetrics.
+type MockIMetricsMockRecorder struct {
+	mock *MockIMetrics
+}
+
+// NewMockIMetrics creates a new mock instance.
+func NewMockIMetrics(ctrl *gomock.Controller) *MockIMetrics {
+	mock := &MockIMetrics{ctrl: ctrl}
+	mock.recorder = &MockIMetricsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIMetrics) EXPECT() *MockIMetricsMockRecorder {
+	return m.recorder
+}
+
+// LockedOut mocks base method.
+func (m *MockIMetrics) LockedOut(arg0 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "LockedOut", arg0)
+}
+
+// LockedOut indicates an expected call of LockedOut.
+func (mr *MockIMetricsMockRecorder) LockedOut(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockedOut", reflect.TypeOf((*MockIMetrics)(nil).LockedOut), arg0)
+}
+
+// Refreshed mocks base method.
+func (m *MockIMetrics) Refreshed() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Refreshed")
+}
+
+// Refreshed indicates an expected call of Refreshed.
+func (mr *MockIMetricsMockRecorder) Refreshed() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refreshed", reflect.TypeOf((*MockIMetrics)(nil).Refreshed))
+}
+
+// SignInFailed mocks base method.
+func (m *MockIMetrics) SignInFailed(arg0 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SignInFailed", arg0)
+}
+
+// SignInFailed indicates an expected call of SignInFailed.
+func (mr *MockIMetricsMockRecorder) SignInFailed(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignInFailed", reflect.TypeOf((*MockIMetrics)(nil).SignInFailed), arg0)
+}
+
+// SignedUp mocks base method.
+func (m *MockIMetrics) SignedUp() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SignedUp")
+}
+
+// SignedUp indicates an expected call of SignedUp.
+func (mr *MockIMetricsMockRecorder) SignedUp() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignedUp", reflect.TypeOf((*MockIMetrics)(nil).SignedUp))
+}
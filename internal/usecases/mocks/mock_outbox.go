@@ -0,0 +1,246 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/usecases (interfaces: IOutboxRepo,IPublisher,IOutboxListener)
+//
+// Generated by this command:
+//
+//	mockgen -destination mocks/mock_outbox.go -package usecases_test github.com/alexsibrin/runbot-auth/internal/usecases IOutboxRepo,IPublisher,IOutboxListener
+//
+
+// Package usecases_test is a generated GoMock package.
+package usecases_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/alexsibrin/runbot-auth/internal/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIOutboxRepo is a mock of IOutboxRepo interface.
+type MockIOutboxRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockIOutboxRepoMockRecorder
+}
+
+// MockIOutboxRepoMockRecorder is the mock recorder for MockIOutboxRepo.
+type MockIOutboxRepoMockRecorder struct {
+	mock *MockIOutboxRepo
+}
+
+// NewMockIOutboxRepo creates a new mock instance.
+func NewMockIOutboxRepo(ctrl *gomock.Controller) *MockIOutboxRepo {
+	mock := &MockIOutboxRepo{ctrl: ctrl}
+	mock.recorder = &MockIOutboxRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIOutboxRepo) EXPECT() *MockIOutboxRepoMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockIOutboxRepo) Add(arg0 context.Context, arg1 *entities.DomainEvent) (*entities.DomainEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", arg0, arg1)
+	ret0, _ := ret[0].(*entities.DomainEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockIOutboxRepoMockRecorder) Add(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockIOutboxRepo)(nil).Add), arg0, arg1)
+}
+
+// DeletePublishedBefore mocks base method.
+func (m *MockIOutboxRepo) DeletePublishedBefore(arg0 context.Context, arg1 int64) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePublishedBefore", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeletePublishedBefore indicates an expected call of DeletePublishedBefore.
+func (mr *MockIOutboxRepoMockRecorder) DeletePublishedBefore(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePublishedBefore", reflect.TypeOf((*MockIOutboxRepo)(nil).DeletePublishedBefore), arg0, arg1)
+}
+
+// GetByAccount mocks base method.
+func (m *MockIOutboxRepo) GetByAccount(arg0 context.Context, arg1, arg2 string) ([]*entities.DomainEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByAccount", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*entities.DomainEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByAccount indicates an expected call of GetByAccount.
+func (mr *MockIOutboxRepoMockRecorder) GetByAccount(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByAccount", reflect.TypeOf((*MockIOutboxRepo)(nil).GetByAccount), arg0, arg1, arg2)
+}
+
+// GetPublishedAfter mocks base method.
+func (m *MockIOutboxRepo) GetPublishedAfter(arg0 context.Context, arg1 int64, arg2 int) ([]*entities.DomainEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPublishedAfter", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*entities.DomainEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPublishedAfter indicates an expected call of GetPublishedAfter.
+func (mr *MockIOutboxRepoMockRecorder) GetPublishedAfter(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublishedAfter", reflect.TypeOf((*MockIOutboxRepo)(nil).GetPublishedAfter), arg0, arg1, arg2)
+}
+
+// GetSeqRange mocks base method.
+func (m *MockIOutboxRepo) GetSeqRange(arg0 context.Context) (int64, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSeqRange", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetSeqRange indicates an expected call of GetSeqRange.
+func (mr *MockIOutboxRepoMockRecorder) GetSeqRange(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSeqRange", reflect.TypeOf((*MockIOutboxRepo)(nil).GetSeqRange), arg0)
+}
+
+// GetUnpublished mocks base method.
+func (m *MockIOutboxRepo) GetUnpublished(arg0 context.Context, arg1 int) ([]*entities.DomainEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnpublished", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.DomainEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnpublished indicates an expected call of GetUnpublished.
+func (mr *MockIOutboxRepoMockRecorder) GetUnpublished(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnpublished", reflect.TypeOf((*MockIOutboxRepo)(nil).GetUnpublished), arg0, arg1)
+}
+
+// MarkFailed mocks base method.
+func (m *MockIOutboxRepo) MarkFailed(arg0 context.Context, arg1 int64, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkFailed", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkFailed indicates an expected call of MarkFailed.
+func (mr *MockIOutboxRepoMockRecorder) MarkFailed(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkFailed", reflect.TypeOf((*MockIOutboxRepo)(nil).MarkFailed), arg0, arg1, arg2)
+}
+
+// MarkPublished mocks base method.
+func (m *MockIOutboxRepo) MarkPublished(arg0 context.Context, arg1, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkPublished", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkPublished indicates an expected call of MarkPublished.
+func (mr *MockIOutboxRepoMockRecorder) MarkPublished(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkPublished", reflect.TypeOf((*MockIOutboxRepo)(nil).MarkPublished), arg0, arg1, arg2)
+}
+
+// SetPayload mocks base method.
+func (m *MockIOutboxRepo) SetPayload(arg0 context.Context, arg1 int64, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPayload", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPayload indicates an expected call of SetPayload.
+func (mr *MockIOutboxRepoMockRecorder) SetPayload(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPayload", reflect.TypeOf((*MockIOutboxRepo)(nil).SetPayload), arg0, arg1, arg2)
+}
+
+// MockIPublisher is a mock of IPublisher interface.
+type MockIPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockIPublisherMockRecorder
+}
+
+// MockIPublisherMockRecorder is the mock recorder for MockIPublisher.
+type MockIPublisherMockRecorder struct {
+	mock *MockIPublisher
+}
+
+// NewMockIPublisher creates a new mock instance.
+func NewMockIPublisher(ctrl *gomock.Controller) *MockIPublisher {
+	mock := &MockIPublisher{ctrl: ctrl}
+	mock.recorder = &MockIPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIPublisher) EXPECT() *MockIPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockIPublisher) Publish(arg0 context.Context, arg1 *entities.DomainEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockIPublisherMockRecorder) Publish(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockIPublisher)(nil).Publish), arg0, arg1)
+}
+
+// MockIOutboxListener is a mock of IOutboxListener interface.
+type MockIOutboxListener struct {
+	ctrl     *gomock.Controller
+	recorder *MockIOutboxListenerMockRecorder
+}
+
+// MockIOutboxListenerMockRecorder is the mock recorder for MockIOutboxListener.
+type MockIOutboxListenerMockRecorder struct {
+	mock *MockIOutboxListener
+}
+
+// NewMockIOutboxListener creates a new mock instance.
+func NewMockIOutboxListener(ctrl *gomock.Controller) *MockIOutboxListener {
+	mock := &MockIOutboxListener{ctrl: ctrl}
+	mock.recorder = &MockIOutboxListenerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIOutboxListener) EXPECT() *MockIOutboxListenerMockRecorder {
+	return m.recorder
+}
+
+// Published mocks base method.
+func (m *MockIOutboxListener) Published(arg0 context.Context, arg1 *entities.DomainEvent) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Published", arg0, arg1)
+}
+
+// Published indicates an expected call of Published.
+func (mr *MockIOutboxListenerMockRecorder) Published(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Published", reflect.TypeOf((*MockIOutboxListener)(nil).Published), arg0, arg1)
+}
@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/usecases (interfaces: ISecurityEventRepo)
+//
+// Generated by this command:
+//
+//	mockgen -destination mocks/mock_securityevent.go -package usecases_test github.com/alexsibrin/runbot-auth/internal/usecases ISecurityEventRepo
+//
+
+// Package usecases_test is a generated GoMock package.
+package usecases_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/alexsibrin/runbot-auth/internal/entities"
+	repositories "github.com/alexsibrin/runbot-auth/internal/repositories"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockISecurityEventRepo is a mock of ISecurityEventRepo interface.
+type MockISecurityEventRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockISecurityEventRepoMockRecorder
+}
+
+// MockISecurityEventRepoMockRecorder is the mock recorder for MockISecurityEventRepo.
+type MockISecurityEventRepoMockRecorder struct {
+	mock *MockISecurityEventRepo
+}
+
+// NewMockISecurityEventRepo creates a new mock instance.
+func NewMockISecurityEventRepo(ctrl *gomock.Controller) *MockISecurityEventRepo {
+	mock := &MockISecurityEventRepo{ctrl: ctrl}
+	mock.recorder = &MockISecurityEventRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockISecurityEventRepo) EXPECT() *MockISecurityEventRepoMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockISecurityEventRepo) Add(arg0 context.Context, arg1 *entities.SecurityEvent) (*entities.SecurityEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", arg0, arg1)
+	ret0, _ := ret[0].(*entities.SecurityEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockISecurityEventRepoMockRecorder) Add(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockISecurityEventRepo)(nil).Add), arg0, arg1)
+}
+
+// DeleteByAccount mocks base method.
+func (m *MockISecurityEventRepo) DeleteByAccount(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByAccount indicates an expected call of DeleteByAccount.
+func (mr *MockISecurityEventRepoMockRecorder) DeleteByAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByAccount", reflect.TypeOf((*MockISecurityEventRepo)(nil).DeleteByAccount), arg0, arg1)
+}
+
+// Find mocks base method.
+func (m *MockISecurityEventRepo) Find(arg0 context.Context, arg1 *repositories.SecurityEventFilter) ([]*entities.SecurityEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.SecurityEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockISecurityEventRepoMockRecorder) Find(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockISecurityEventRepo)(nil).Find), arg0, arg1)
+}
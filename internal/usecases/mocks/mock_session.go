@@ -0,0 +1,156 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/usecases (interfaces: ISessionRepo)
+//
+// Generated by this command:
+//
+//	mockgen -destination mocks/mock_session.go -package usecases_test github.com/alexsibrin/runbot-auth/internal/usecases ISessionRepo
+//
+
+// Package usecases_test is a generated GoMock package.
+package usecases_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/alexsibrin/runbot-auth/internal/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockISessionRepo is a mock of ISessionRepo interface.
+type MockISessionRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockISessionRepoMockRecorder
+}
+
+// MockISessionRepoMockRecorder is the mock recorder for MockISessionRepo.
+type MockISessionRepoMockRecorder struct {
+	mock *MockISessionRepo
+}
+
+// NewMockISessionRepo creates a new mock instance.
+func NewMockISessionRepo(ctrl *gomock.Controller) *MockISessionRepo {
+	mock := &MockISessionRepo{ctrl: ctrl}
+	mock.recorder = &MockISessionRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockISessionRepo) EXPECT() *MockISessionRepoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockISessionRepo) Create(arg0 context.Context, arg1 *entities.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockISessionRepoMockRecorder) Create(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockISessionRepo)(nil).Create), arg0, arg1)
+}
+
+// DeleteByAccount mocks base method.
+func (m *MockISessionRepo) DeleteByAccount(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByAccount indicates an expected call of DeleteByAccount.
+func (mr *MockISessionRepoMockRecorder) DeleteByAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByAccount", reflect.TypeOf((*MockISessionRepo)(nil).DeleteByAccount), arg0, arg1)
+}
+
+// GetActiveByAccount mocks base method.
+func (m *MockISessionRepo) GetActiveByAccount(arg0 context.Context, arg1 string, arg2 int64) ([]*entities.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveByAccount", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*entities.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveByAccount indicates an expected call of GetActiveByAccount.
+func (mr *MockISessionRepoMockRecorder) GetActiveByAccount(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveByAccount", reflect.TypeOf((*MockISessionRepo)(nil).GetActiveByAccount), arg0, arg1, arg2)
+}
+
+// GetByAccount mocks base method.
+func (m *MockISessionRepo) GetByAccount(arg0 context.Context, arg1 string) ([]*entities.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByAccount", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByAccount indicates an expected call of GetByAccount.
+func (mr *MockISessionRepoMockRecorder) GetByAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByAccount", reflect.TypeOf((*MockISessionRepo)(nil).GetByAccount), arg0, arg1)
+}
+
+// GetOneByUUID mocks base method.
+func (m *MockISessionRepo) GetOneByUUID(arg0 context.Context, arg1 string) (*entities.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOneByUUID", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOneByUUID indicates an expected call of GetOneByUUID.
+func (mr *MockISessionRepoMockRecorder) GetOneByUUID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOneByUUID", reflect.TypeOf((*MockISessionRepo)(nil).GetOneByUUID), arg0, arg1)
+}
+
+// Refresh mocks base method.
+func (m *MockISessionRepo) Refresh(arg0 context.Context, arg1 *entities.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockISessionRepoMockRecorder) Refresh(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockISessionRepo)(nil).Refresh), arg0, arg1)
+}
+
+// Revoke mocks base method.
+func (m *MockISessionRepo) Revoke(arg0 context.Context, arg1 string, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockISessionRepoMockRecorder) Revoke(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockISessionRepo)(nil).Revoke), arg0, arg1, arg2)
+}
+
+// RevokeAllByAccount mocks base method.
+func (m *MockISessionRepo) RevokeAllByAccount(arg0 context.Context, arg1 string, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllByAccount", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAllByAccount indicates an expected call of RevokeAllByAccount.
+func (mr *MockISessionRepoMockRecorder) RevokeAllByAccount(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllByAccount", reflect.TypeOf((*MockISessionRepo)(nil).RevokeAllByAccount), arg0, arg1, arg2)
+}
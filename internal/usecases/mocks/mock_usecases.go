@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/alexsibrin/runbot-auth/internal/usecases (interfaces: IPasswordHasher,IAccountRepo)
+// Source: github.com/alexsibrin/runbot-auth/internal/usecases (interfaces: IPasswordHasher,IAccountRepo,IStatusHistoryRepo)
 //
 // Generated by this command:
 //
-//	mockgen -destination mocks/mock_usecases.go -package usecases_test github.com/alexsibrin/runbot-auth/internal/usecases IPasswordHasher,IAccountRepo
+//	mockgen -destination mocks/mock_usecases.go -package usecases_test github.com/alexsibrin/runbot-auth/internal/usecases IPasswordHasher,IAccountRepo,IStatusHistoryRepo
 //
 
 // Package usecases_test is a generated GoMock package.
@@ -14,6 +14,7 @@ import (
 	reflect "reflect"
 
 	entities "github.com/alexsibrin/runbot-auth/internal/entities"
+	repositories "github.com/alexsibrin/runbot-auth/internal/repositories"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -107,6 +108,51 @@ func (mr *MockIAccountRepoMockRecorder) Create(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIAccountRepo)(nil).Create), arg0, arg1)
 }
 
+// Find mocks base method.
+func (m *MockIAccountRepo) Find(arg0 context.Context, arg1 *repositories.AccountFilter) ([]*entities.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockIAccountRepoMockRecorder) Find(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockIAccountRepo)(nil).Find), arg0, arg1)
+}
+
+// GetDeletedBefore mocks base method.
+func (m *MockIAccountRepo) GetDeletedBefore(arg0 context.Context, arg1 int64, arg2 int) ([]*entities.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedBefore", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*entities.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedBefore indicates an expected call of GetDeletedBefore.
+func (mr *MockIAccountRepoMockRecorder) GetDeletedBefore(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedBefore", reflect.TypeOf((*MockIAccountRepo)(nil).GetDeletedBefore), arg0, arg1, arg2)
+}
+
+// GetManyByUUIDs mocks base method.
+func (m *MockIAccountRepo) GetManyByUUIDs(arg0 context.Context, arg1 []string) ([]*entities.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetManyByUUIDs", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetManyByUUIDs indicates an expected call of GetManyByUUIDs.
+func (mr *MockIAccountRepoMockRecorder) GetManyByUUIDs(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetManyByUUIDs", reflect.TypeOf((*MockIAccountRepo)(nil).GetManyByUUIDs), arg0, arg1)
+}
+
 // GetOneByEmail mocks base method.
 func (m *MockIAccountRepo) GetOneByEmail(arg0 context.Context, arg1 string) (*entities.Account, error) {
 	m.ctrl.T.Helper()
@@ -137,6 +183,21 @@ func (mr *MockIAccountRepoMockRecorder) GetOneByUUID(arg0, arg1 any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOneByUUID", reflect.TypeOf((*MockIAccountRepo)(nil).GetOneByUUID), arg0, arg1)
 }
 
+// GetStatusExpiredBefore mocks base method.
+func (m *MockIAccountRepo) GetStatusExpiredBefore(arg0 context.Context, arg1 int64, arg2 int) ([]*entities.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatusExpiredBefore", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*entities.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatusExpiredBefore indicates an expected call of GetStatusExpiredBefore.
+func (mr *MockIAccountRepoMockRecorder) GetStatusExpiredBefore(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatusExpiredBefore", reflect.TypeOf((*MockIAccountRepo)(nil).GetStatusExpiredBefore), arg0, arg1, arg2)
+}
+
 // IsExist mocks base method.
 func (m *MockIAccountRepo) IsExist(arg0 context.Context, arg1 *entities.Account) (bool, error) {
 	m.ctrl.T.Helper()
@@ -167,16 +228,111 @@ func (mr *MockIAccountRepoMockRecorder) IsExistByUUID(arg0, arg1 any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsExistByUUID", reflect.TypeOf((*MockIAccountRepo)(nil).IsExistByUUID), arg0, arg1)
 }
 
+// MarkAccountDeleted mocks base method.
+func (m *MockIAccountRepo) MarkAccountDeleted(arg0 context.Context, arg1 string, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAccountDeleted", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAccountDeleted indicates an expected call of MarkAccountDeleted.
+func (mr *MockIAccountRepoMockRecorder) MarkAccountDeleted(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAccountDeleted", reflect.TypeOf((*MockIAccountRepo)(nil).MarkAccountDeleted), arg0, arg1, arg2)
+}
+
+// PurgeAccount mocks base method.
+func (m *MockIAccountRepo) PurgeAccount(arg0 context.Context, arg1 string, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeAccount", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeAccount indicates an expected call of PurgeAccount.
+func (mr *MockIAccountRepoMockRecorder) PurgeAccount(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeAccount", reflect.TypeOf((*MockIAccountRepo)(nil).PurgeAccount), arg0, arg1, arg2)
+}
+
 // SetAccountStatus mocks base method.
-func (m *MockIAccountRepo) SetAccountStatus(arg0 context.Context, arg1 string, arg2 byte) error {
+func (m *MockIAccountRepo) SetAccountStatus(arg0 context.Context, arg1 string, arg2 byte, arg3 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetAccountStatus", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "SetAccountStatus", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SetAccountStatus indicates an expected call of SetAccountStatus.
-func (mr *MockIAccountRepoMockRecorder) SetAccountStatus(arg0, arg1, arg2 any) *gomock.Call {
+func (mr *MockIAccountRepoMockRecorder) SetAccountStatus(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAccountStatus", reflect.TypeOf((*MockIAccountRepo)(nil).SetAccountStatus), arg0, arg1, arg2, arg3)
+}
+
+// SetPassword mocks base method.
+func (m *MockIAccountRepo) SetPassword(arg0 context.Context, arg1, arg2 string, arg3 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPassword", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPassword indicates an expected call of SetPassword.
+func (mr *MockIAccountRepoMockRecorder) SetPassword(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPassword", reflect.TypeOf((*MockIAccountRepo)(nil).SetPassword), arg0, arg1, arg2, arg3)
+}
+
+// MockIStatusHistoryRepo is a mock of IStatusHistoryRepo interface.
+type MockIStatusHistoryRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockIStatusHistoryRepoMockRecorder
+}
+
+// MockIStatusHistoryRepoMockRecorder is the mock recorder for MockIStatusHistoryRepo.
+type MockIStatusHistoryRepoMockRecorder struct {
+	mock *MockIStatusHistoryRepo
+}
+
+// NewMockIStatusHistoryRepo creates a new mock instance.
+func NewMockIStatusHistoryRepo(ctrl *gomock.Controller) *MockIStatusHistoryRepo {
+	mock := &MockIStatusHistoryRepo{ctrl: ctrl}
+	mock.recorder = &MockIStatusHistoryRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIStatusHistoryRepo) EXPECT() *MockIStatusHistoryRepoMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockIStatusHistoryRepo) Add(arg0 context.Context, arg1 *entities.StatusChange) (*entities.StatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", arg0, arg1)
+	ret0, _ := ret[0].(*entities.StatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockIStatusHistoryRepoMockRecorder) Add(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockIStatusHistoryRepo)(nil).Add), arg0, arg1)
+}
+
+// GetByAccount mocks base method.
+func (m *MockIStatusHistoryRepo) GetByAccount(arg0 context.Context, arg1 string, arg2 int) ([]*entities.StatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByAccount", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*entities.StatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByAccount indicates an expected call of GetByAccount.
+func (mr *MockIStatusHistoryRepoMockRecorder) GetByAccount(arg0, arg1, arg2 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAccountStatus", reflect.TypeOf((*MockIAccountRepo)(nil).SetAccountStatus), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByAccount", reflect.TypeOf((*MockIStatusHistoryRepo)(nil).GetByAccount), arg0, arg1, arg2)
 }
@@ -0,0 +1,291 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/usecases (interfaces: IWebhookRepo,IWebhookDeliveryRepo,IWebhookSender)
+//
+// Generated by this command:
+//
+//	mockgen -destination mocks/mock_webhook.go -package usecases_test github.com/alexsibrin/runbot-auth/internal/usecases IWebhookRepo,IWebhookDeliveryRepo,IWebhookSender
+//
+
+// Package usecases_test is a generated GoMock package.
+package usecases_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/alexsibrin/runbot-auth/internal/entities"
+	repositories "github.com/alexsibrin/runbot-auth/internal/repositories"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIWebhookRepo is a mock of IWebhookRepo interface.
+type MockIWebhookRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockIWebhookRepoMockRecorder
+}
+
+// MockIWebhookRepoMockRecorder is the mock recorder for MockIWebhookRepo.
+type MockIWebhookRepoMockRecorder struct {
+	mock *MockIWebhookRepo
+}
+
+// NewMockIWebhookRepo creates a new mock instance.
+func NewMockIWebhookRepo(ctrl *gomock.Controller) *MockIWebhookRepo {
+	mock := &MockIWebhookRepo{ctrl: ctrl}
+	mock.recorder = &MockIWebhookRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIWebhookRepo) EXPECT() *MockIWebhookRepoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockIWebhookRepo) Create(arg0 context.Context, arg1 *entities.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIWebhookRepoMockRecorder) Create(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIWebhookRepo)(nil).Create), arg0, arg1)
+}
+
+// Delete mocks base method.
+func (m *MockIWebhookRepo) Delete(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockIWebhookRepoMockRecorder) Delete(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIWebhookRepo)(nil).Delete), arg0, arg1)
+}
+
+// GetAll mocks base method.
+func (m *MockIWebhookRepo) GetAll(arg0 context.Context) ([]*entities.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", arg0)
+	ret0, _ := ret[0].([]*entities.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockIWebhookRepoMockRecorder) GetAll(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockIWebhookRepo)(nil).GetAll), arg0)
+}
+
+// GetOneByUUID mocks base method.
+func (m *MockIWebhookRepo) GetOneByUUID(arg0 context.Context, arg1 string) (*entities.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOneByUUID", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOneByUUID indicates an expected call of GetOneByUUID.
+func (mr *MockIWebhookRepoMockRecorder) GetOneByUUID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOneByUUID", reflect.TypeOf((*MockIWebhookRepo)(nil).GetOneByUUID), arg0, arg1)
+}
+
+// Update mocks base method.
+func (m *MockIWebhookRepo) Update(arg0 context.Context, arg1 *entities.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockIWebhookRepoMockRecorder) Update(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockIWebhookRepo)(nil).Update), arg0, arg1)
+}
+
+// MockIWebhookDeliveryRepo is a mock of IWebhookDeliveryRepo interface.
+type MockIWebhookDeliveryRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockIWebhookDeliveryRepoMockRecorder
+}
+
+// MockIWebhookDeliveryRepoMockRecorder is the mock recorder for MockIWebhookDeliveryRepo.
+type MockIWebhookDeliveryRepoMockRecorder struct {
+	mock *MockIWebhookDeliveryRepo
+}
+
+// NewMockIWebhookDeliveryRepo creates a new mock instance.
+func NewMockIWebhookDeliveryRepo(ctrl *gomock.Controller) *MockIWebhookDeliveryRepo {
+	mock := &MockIWebhookDeliveryRepo{ctrl: ctrl}
+	mock.recorder = &MockIWebhookDeliveryRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIWebhookDeliveryRepo) EXPECT() *MockIWebhookDeliveryRepoMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockIWebhookDeliveryRepo) Add(arg0 context.Context, arg1 *entities.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockIWebhookDeliveryRepoMockRecorder) Add(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockIWebhookDeliveryRepo)(nil).Add), arg0, arg1)
+}
+
+// ClaimDue mocks base method.
+func (m *MockIWebhookDeliveryRepo) ClaimDue(arg0 context.Context, arg1, arg2 int64) (*entities.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDue", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entities.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDue indicates an expected call of ClaimDue.
+func (mr *MockIWebhookDeliveryRepoMockRecorder) ClaimDue(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDue", reflect.TypeOf((*MockIWebhookDeliveryRepo)(nil).ClaimDue), arg0, arg1, arg2)
+}
+
+// DeleteByWebhook mocks base method.
+func (m *MockIWebhookDeliveryRepo) DeleteByWebhook(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByWebhook", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByWebhook indicates an expected call of DeleteByWebhook.
+func (mr *MockIWebhookDeliveryRepoMockRecorder) DeleteByWebhook(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByWebhook", reflect.TypeOf((*MockIWebhookDeliveryRepo)(nil).DeleteByWebhook), arg0, arg1)
+}
+
+// DeleteFinishedBefore mocks base method.
+func (m *MockIWebhookDeliveryRepo) DeleteFinishedBefore(arg0 context.Context, arg1 int64) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFinishedBefore", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFinishedBefore indicates an expected call of DeleteFinishedBefore.
+func (mr *MockIWebhookDeliveryRepoMockRecorder) DeleteFinishedBefore(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFinishedBefore", reflect.TypeOf((*MockIWebhookDeliveryRepo)(nil).DeleteFinishedBefore), arg0, arg1)
+}
+
+// Find mocks base method.
+func (m *MockIWebhookDeliveryRepo) Find(arg0 context.Context, arg1 *repositories.WebhookDeliveryFilter) ([]*entities.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockIWebhookDeliveryRepoMockRecorder) Find(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockIWebhookDeliveryRepo)(nil).Find), arg0, arg1)
+}
+
+// GetOneByID mocks base method.
+func (m *MockIWebhookDeliveryRepo) GetOneByID(arg0 context.Context, arg1 int64) (*entities.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOneByID", arg0, arg1)
+	ret0, _ := ret[0].(*entities.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOneByID indicates an expected call of GetOneByID.
+func (mr *MockIWebhookDeliveryRepoMockRecorder) GetOneByID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOneByID", reflect.TypeOf((*MockIWebhookDeliveryRepo)(nil).GetOneByID), arg0, arg1)
+}
+
+// SetPayloadByEvent mocks base method.
+func (m *MockIWebhookDeliveryRepo) SetPayloadByEvent(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPayloadByEvent", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPayloadByEvent indicates an expected call of SetPayloadByEvent.
+func (mr *MockIWebhookDeliveryRepoMockRecorder) SetPayloadByEvent(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPayloadByEvent", reflect.TypeOf((*MockIWebhookDeliveryRepo)(nil).SetPayloadByEvent), arg0, arg1, arg2)
+}
+
+// Update mocks base method.
+func (m *MockIWebhookDeliveryRepo) Update(arg0 context.Context, arg1 *entities.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockIWebhookDeliveryRepoMockRecorder) Update(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockIWebhookDeliveryRepo)(nil).Update), arg0, arg1)
+}
+
+// MockIWebhookSender is a mock of IWebhookSender interface.
+type MockIWebhookSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockIWebhookSenderMockRecorder
+}
+
+// MockIWebhookSenderMockRecorder is the mock recorder for MockIWebhookSender.
+type MockIWebhookSenderMockRecorder struct {
+	mock *MockIWebhookSender
+}
+
+// NewMockIWebhookSender creates a new mock instance.
+func NewMockIWebhookSender(ctrl *gomock.Controller) *MockIWebhookSender {
+	mock := &MockIWebhookSender{ctrl: ctrl}
+	mock.recorder = &MockIWebhookSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIWebhookSender) EXPECT() *MockIWebhookSenderMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockIWebhookSender) Send(arg0 context.Context, arg1 *entities.Webhook, arg2 *entities.WebhookDelivery) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockIWebhookSenderMockRecorder) Send(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockIWebhookSender)(nil).Send), arg0, arg1, arg2)
+}
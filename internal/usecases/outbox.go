@@ -0,0 +1,225 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/google/uuid"
+	"time"
+)
+
+//go:generate mockgen -destination mocks/mock_outbox.go -package usecases_test github.com/alexsibrin/runbot-auth/internal/usecases IOutboxRepo,IPublisher,IOutboxListener
+
+const (
+	DefaultOutboxBatchSize = 100
+	DefaultOutboxRetention = 7 * 24 * time.Hour
+
+	// publishErrorMaxLength keeps the last error of an event short, the publisher errors may carry whole responses
+	publishErrorMaxLength = 512
+)
+
+var (
+	ErrOutboxRepoIsNil = errors.New("dependency outbox repo is nil")
+	ErrPublisherIsNil  = errors.New("dependency publisher is nil")
+)
+
+type IOutboxRepo interface {
+	// Add stores the event and returns it with the assigned ID
+	Add(ctx context.Context, event *entities.DomainEvent) (*entities.DomainEvent, error)
+	// GetUnpublished returns up to limit unpublished events, the earliest go first
+	GetUnpublished(ctx context.Context, limit int) ([]*entities.DomainEvent, error)
+	// GetPublishedAfter returns up to limit published events with the Seq greater than afterseq in the Seq order
+	GetPublishedAfter(ctx context.Context, afterseq int64, limit int) ([]*entities.DomainEvent, error)
+	// GetSeqRange returns the smallest and the greatest Seq of the stored published events, zeros if there are none
+	GetSeqRange(ctx context.Context) (int64, int64, error)
+	// MarkPublished assigns the next Seq to the event unless it has one. A Seq is committed after all the smaller ones,
+	// so a reader seeing it won't see a smaller one later. The Seq aren't reused after the deletion
+	MarkPublished(ctx context.Context, id int64, publishedat int64) error
+	// MarkFailed counts a failed attempt to publish the event
+	MarkFailed(ctx context.Context, id int64, lasterror string) error
+	// DeletePublishedBefore deletes the events published by before
+	DeletePublishedBefore(ctx context.Context, before int64) (int, error)
+	// GetByAccount returns the events of the type about the account, the earliest go first
+	GetByAccount(ctx context.Context, accountuuid, eventtype string) ([]*entities.DomainEvent, error)
+	// SetPayload replaces the payload of the event, published or not
+	SetPayload(ctx context.Context, id int64, payload string) error
+}
+
+// IPublisher delivers the events to the other services, an event is published once Publish returns nil
+type IPublisher interface {
+	Publish(ctx context.Context, event *entities.DomainEvent) error
+}
+
+// IOutboxListener learns about the events marked published, e.g. to wake the account watchers up
+type IOutboxListener interface {
+	Published(ctx context.Context, event *entities.DomainEvent)
+}
+
+type OutboxConfig struct {
+	// BatchSize is DefaultOutboxBatchSize if it isn't positive
+	BatchSize int
+	// Retention is how long the published events are kept, DefaultOutboxRetention if it isn't positive
+	Retention time.Duration
+}
+
+type OutboxDependencies struct {
+	Repo      IOutboxRepo
+	Publisher IPublisher
+	// Listener is optional
+	Listener IOutboxListener
+	Config   *OutboxConfig
+}
+
+// Outbox relays the domain events from the outbox to the publisher. The events are added by the usecases
+// in the transactions of the changes, so an event is stored if and only if the change is committed
+type Outbox struct {
+	repo      IOutboxRepo
+	publisher IPublisher
+	listener  IOutboxListener
+	batchsize int
+	retention time.Duration
+}
+
+func NewOutbox(d *OutboxDependencies) (*Outbox, error) {
+	if d == nil {
+		return nil, ErrDependenciesAreNil
+	}
+	if d.Repo == nil {
+		return nil, ErrOutboxRepoIsNil
+	}
+	if d.Publisher == nil {
+		return nil, ErrPublisherIsNil
+	}
+
+	u := &Outbox{
+		repo:      d.Repo,
+		publisher: d.Publisher,
+		listener:  d.Listener,
+		batchsize: DefaultOutboxBatchSize,
+		retention: DefaultOutboxRetention,
+	}
+	if d.Config != nil && d.Config.BatchSize > 0 {
+		u.batchsize = d.Config.BatchSize
+	}
+	if d.Config != nil && d.Config.Retention > 0 {
+		u.retention = d.Config.Retention
+	}
+	return u, nil
+}
+
+// PublishNext publishes a batch of the earliest unpublished events in order and returns how many are published.
+// A failed event stops the batch, so the events aren't reordered, it's retried with the next call
+func (u *Outbox) PublishNext(ctx context.Context) (int, error) {
+	events, err := u.repo.GetUnpublished(ctx, u.batchsize)
+	if err != nil {
+		return 0, err
+	}
+
+	var published int
+	for _, event := range events {
+		if err = u.publisher.Publish(ctx, event); err != nil {
+			return published, errors.Join(err, u.repo.MarkFailed(ctx, event.ID, truncate(err.Error(), publishErrorMaxLength)))
+		}
+		if err = u.repo.MarkPublished(ctx, event.ID, time.Now().Unix()); err != nil {
+			return published, err
+		}
+		if u.listener != nil {
+			u.listener.Published(ctx, event)
+		}
+		published++
+	}
+	return published, nil
+}
+
+// DeletePublished deletes the events published longer than the retention ago
+func (u *Outbox) DeletePublished(ctx context.Context) (int, error) {
+	return u.repo.DeletePublishedBefore(ctx, time.Now().Add(-u.retention).Unix())
+}
+
+// accountCreatedData is the payload of entities.DomainEventAccountCreated
+type accountCreatedData struct {
+	Email     string
+	Name      string
+	Status    uint8
+	CreatedAt int64
+}
+
+// accountStatusChangedData is the payload of entities.DomainEventAccountStatusChanged, zero Until means the status is permanent
+type accountStatusChangedData struct {
+	FromStatus uint8
+	ToStatus   uint8
+	Until      int64
+	Reason     string
+	Actor      string
+}
+
+// accountDeletedData is the payload of entities.DomainEventAccountDeleted, the account can be restored until PurgeAt
+type accountDeletedData struct {
+	FromStatus uint8
+	DeletedAt  int64
+	PurgeAt    int64
+}
+
+// accountPurgedData is the payload of entities.DomainEventAccountPurged
+type accountPurgedData struct {
+	PurgedAt int64
+}
+
+// passwordChangedData is the payload of entities.DomainEventPasswordChanged,
+// ResetRequired is set when the password is cleared and the owner has to set a new one
+type passwordChangedData struct {
+	ResetRequired bool
+}
+
+// addDomainEvent stores the event in the outbox, repo must be bound to the transaction of the change
+func addDomainEvent(ctx context.Context, repo IOutboxRepo, eventtype, accountuuid string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = repo.Add(ctx, &entities.DomainEvent{
+		UUID:        uuid.NewString(),
+		Type:        eventtype,
+		AccountUUID: accountuuid,
+		Payload:     string(payload),
+		CreatedAt:   time.Now().Unix(),
+	})
+	return err
+}
+
+// eraseAccountCreated clears the email and the name in the created events of the purged account and in their
+// webhook deliveries. The events are kept, so the watchers don't miss their seq
+func eraseAccountCreated(ctx context.Context, repos *TxRepos, accountuuid string) error {
+	events, err := repos.Outbox.GetByAccount(ctx, accountuuid, entities.DomainEventAccountCreated)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		var data accountCreatedData
+		if err = json.Unmarshal([]byte(event.Payload), &data); err != nil {
+			return err
+		}
+		data.Email, data.Name = "", ""
+
+		payload, err := json.Marshal(&data)
+		if err != nil {
+			return err
+		}
+		if err = repos.Outbox.SetPayload(ctx, event.ID, string(payload)); err != nil {
+			return err
+		}
+
+		erased := *event
+		erased.Payload = string(payload)
+		msg, err := erased.Message()
+		if err != nil {
+			return err
+		}
+		if err = repos.WebhookDelivery.SetPayloadByEvent(ctx, event.UUID, string(msg)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
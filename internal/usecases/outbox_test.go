@@ -0,0 +1,238 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	usecases_test "github.com/alexsibrin/runbot-auth/internal/usecases/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"strings"
+	"testing"
+	"time"
+)
+
+// expectDomainEvent expects an event of eventtype about the account to be added to the outbox
+func expectDomainEvent(t *testing.T, outbox *usecases_test.MockIOutboxRepo, eventtype, accountuuid string) *gomock.Call {
+	return outbox.EXPECT().Add(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, event *entities.DomainEvent) (*entities.DomainEvent, error) {
+		assert.Equal(t, eventtype, event.Type)
+		assert.Equal(t, accountuuid, event.AccountUUID)
+		assert.NotEmpty(t, event.UUID)
+		assert.NotZero(t, event.CreatedAt)
+		assert.True(t, json.Valid([]byte(event.Payload)))
+		return event, nil
+	})
+}
+
+func TestOutboxInit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repomock := usecases_test.NewMockIOutboxRepo(ctrl)
+	publishermock := usecases_test.NewMockIPublisher(ctrl)
+
+	testCases := []struct {
+		name        string
+		in          *OutboxDependencies
+		out         *Outbox
+		expectedErr error
+	}{
+		{
+			name: "Regular valid case",
+			in:   &OutboxDependencies{Repo: repomock, Publisher: publishermock},
+			out: &Outbox{
+				repo:      repomock,
+				publisher: publishermock,
+				batchsize: DefaultOutboxBatchSize,
+				retention: DefaultOutboxRetention,
+			},
+		},
+		{
+			name: "Configured case",
+			in: &OutboxDependencies{Repo: repomock, Publisher: publishermock, Config: &OutboxConfig{
+				BatchSize: 10,
+				Retention: time.Hour,
+			}},
+			out: &Outbox{
+				repo:      repomock,
+				publisher: publishermock,
+				batchsize: 10,
+				retention: time.Hour,
+			},
+		},
+		{
+			name:        "Dependencies are nil",
+			in:          nil,
+			expectedErr: ErrDependenciesAreNil,
+		},
+		{
+			name:        "Repo is nil",
+			in:          &OutboxDependencies{Publisher: publishermock},
+			expectedErr: ErrOutboxRepoIsNil,
+		},
+		{
+			name:        "Publisher is nil",
+			in:          &OutboxDependencies{Repo: repomock},
+			expectedErr: ErrPublisherIsNil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			uc, err := NewOutbox(tc.in)
+			assert.Equal(t, tc.out, uc)
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestOutbox_PublishNext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repomock := usecases_test.NewMockIOutboxRepo(ctrl)
+	publishermock := usecases_test.NewMockIPublisher(ctrl)
+	ctx := context.TODO()
+
+	events := []*entities.DomainEvent{
+		{ID: 1, Type: entities.DomainEventAccountCreated},
+		{ID: 2, Type: entities.DomainEventAccountStatusChanged},
+		{ID: 3, Type: entities.DomainEventAccountDeleted},
+	}
+
+	testCases := []struct {
+		name              string
+		setupMocks        func()
+		expectedPublished int
+		expectedErr       error
+	}{
+		{
+			name: "Valid case",
+			setupMocks: func() {
+				repomock.EXPECT().GetUnpublished(ctx, 10).Return(events, nil)
+				gomock.InOrder(
+					publishermock.EXPECT().Publish(ctx, events[0]).Return(nil),
+					repomock.EXPECT().MarkPublished(ctx, int64(1), gomock.Any()).Return(nil),
+					publishermock.EXPECT().Publish(ctx, events[1]).Return(nil),
+					repomock.EXPECT().MarkPublished(ctx, int64(2), gomock.Any()).Return(nil),
+					publishermock.EXPECT().Publish(ctx, events[2]).Return(nil),
+					repomock.EXPECT().MarkPublished(ctx, int64(3), gomock.Any()).Return(nil),
+				)
+			},
+			expectedPublished: 3,
+		},
+		{
+			name: "Nothing to publish",
+			setupMocks: func() {
+				repomock.EXPECT().GetUnpublished(ctx, 10).Return(nil, nil)
+			},
+			expectedPublished: 0,
+		},
+		{
+			name: "Failed event stops the batch",
+			setupMocks: func() {
+				repomock.EXPECT().GetUnpublished(ctx, 10).Return(events, nil)
+				gomock.InOrder(
+					publishermock.EXPECT().Publish(ctx, events[0]).Return(nil),
+					repomock.EXPECT().MarkPublished(ctx, int64(1), gomock.Any()).Return(nil),
+					publishermock.EXPECT().Publish(ctx, events[1]).Return(errors.New("broker is unavailable")),
+					repomock.EXPECT().MarkFailed(ctx, int64(2), "broker is unavailable").Return(nil),
+				)
+			},
+			expectedPublished: 1,
+			expectedErr:       errors.New("broker is unavailable"),
+		},
+		{
+			name: "Long error is truncated",
+			setupMocks: func() {
+				repomock.EXPECT().GetUnpublished(ctx, 10).Return(events[:1], nil)
+				publishermock.EXPECT().Publish(ctx, events[0]).Return(errors.New(strings.Repeat("e", 1000)))
+				repomock.EXPECT().MarkFailed(ctx, int64(1), strings.Repeat("e", publishErrorMaxLength)).Return(nil)
+			},
+			expectedPublished: 0,
+			expectedErr:       errors.New(strings.Repeat("e", 1000)),
+		},
+		{
+			name: "Repo error GetUnpublished",
+			setupMocks: func() {
+				repomock.EXPECT().GetUnpublished(ctx, 10).Return(nil, errors.New("some repo error"))
+			},
+			expectedErr: errors.New("some repo error"),
+		},
+		{
+			name: "Repo error MarkPublished",
+			setupMocks: func() {
+				repomock.EXPECT().GetUnpublished(ctx, 10).Return(events, nil)
+				publishermock.EXPECT().Publish(ctx, events[0]).Return(nil)
+				repomock.EXPECT().MarkPublished(ctx, int64(1), gomock.Any()).Return(errors.New("some repo error"))
+			},
+			expectedErr: errors.New("some repo error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			u := &Outbox{repo: repomock, publisher: publishermock, batchsize: 10, retention: time.Hour}
+
+			published, err := u.PublishNext(ctx)
+
+			assert.Equal(t, tc.expectedPublished, published)
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestOutbox_PublishNextListener(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repomock := usecases_test.NewMockIOutboxRepo(ctrl)
+	publishermock := usecases_test.NewMockIPublisher(ctrl)
+	listenermock := usecases_test.NewMockIOutboxListener(ctrl)
+	ctx := context.TODO()
+
+	events := []*entities.DomainEvent{
+		{ID: 1, Type: entities.DomainEventAccountCreated},
+		{ID: 2, Type: entities.DomainEventAccountStatusChanged},
+	}
+
+	// the listener learns about an event once it's marked published, the failed one isn't reported
+	repomock.EXPECT().GetUnpublished(ctx, 10).Return(events, nil)
+	gomock.InOrder(
+		publishermock.EXPECT().Publish(ctx, events[0]).Return(nil),
+		repomock.EXPECT().MarkPublished(ctx, int64(1), gomock.Any()).Return(nil),
+		listenermock.EXPECT().Published(ctx, events[0]),
+		publishermock.EXPECT().Publish(ctx, events[1]).Return(errors.New("broker is unavailable")),
+		repomock.EXPECT().MarkFailed(ctx, int64(2), "broker is unavailable").Return(nil),
+	)
+
+	u, err := NewOutbox(&OutboxDependencies{Repo: repomock, Publisher: publishermock, Listener: listenermock, Config: &OutboxConfig{BatchSize: 10}})
+	require.NoError(t, err)
+
+	published, err := u.PublishNext(ctx)
+	assert.Equal(t, 1, published)
+	assert.Error(t, err)
+}
+
+func TestOutbox_DeletePublished(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repomock := usecases_test.NewMockIOutboxRepo(ctrl)
+	ctx := context.TODO()
+
+	repomock.EXPECT().DeletePublishedBefore(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, before int64) (int, error) {
+		assert.InDelta(t, time.Now().Add(-time.Hour).Unix(), before, 1)
+		return 2, nil
+	})
+
+	u := &Outbox{repo: repomock, retention: time.Hour}
+	deleted, err := u.DeletePublished(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+}
@@ -0,0 +1,92 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+)
+
+//go:generate mockgen -destination mocks/mock_securityevent.go -package usecases_test github.com/alexsibrin/runbot-auth/internal/usecases ISecurityEventRepo
+
+const (
+	DefaultSecurityEventsLimit = 50
+	MaxSecurityEventsLimit     = 500
+)
+
+var (
+	ErrSecurityEventRepoIsNil = errors.New("dependency security event repo is nil")
+)
+
+type ISecurityEventRepo interface {
+	// Add stores the event and returns it with the assigned ID
+	Add(ctx context.Context, event *entities.SecurityEvent) (*entities.SecurityEvent, error)
+	// Find returns up to filter.Limit latest events matching the filter, the latest go first
+	Find(ctx context.Context, filter *repositories.SecurityEventFilter) ([]*entities.SecurityEvent, error)
+	DeleteByAccount(ctx context.Context, accountuuid string) error
+}
+
+type SecurityEventDependencies struct {
+	Repo        ISecurityEventRepo
+	AccountRepo IAccountRepo
+}
+
+// SecurityEvent gives access to the security log, the events are recorded by the Account and Session usecases
+type SecurityEvent struct {
+	repo        ISecurityEventRepo
+	accountrepo IAccountRepo
+}
+
+func NewSecurityEvent(d *SecurityEventDependencies) (*SecurityEvent, error) {
+	if d == nil {
+		return nil, ErrDependenciesAreNil
+	}
+	if d.Repo == nil {
+		return nil, ErrSecurityEventRepoIsNil
+	}
+	if d.AccountRepo == nil {
+		return nil, ErrAccountRepoIsNil
+	}
+	return &SecurityEvent{
+		repo:        d.Repo,
+		accountrepo: d.AccountRepo,
+	}, nil
+}
+
+// GetByAccount lists the events of the account page by page, beforeid is the ID of the last event of the previous page
+func (u *SecurityEvent) GetByAccount(ctx context.Context, accountuuid string, beforeid int64, limit int) ([]*entities.SecurityEvent, error) {
+	return u.repo.Find(ctx, &repositories.SecurityEventFilter{
+		AccountUUID: accountuuid,
+		BeforeID:    beforeid,
+		Limit:       SecurityEventsLimit(limit),
+	})
+}
+
+// Search lists the events of all the accounts for admins, a missing account is reported as ErrAccountIsNotExist
+func (u *SecurityEvent) Search(ctx context.Context, filter *repositories.SecurityEventFilter) ([]*entities.SecurityEvent, error) {
+	if filter.AccountUUID != "" {
+		isexist, err := u.accountrepo.IsExistByUUID(ctx, filter.AccountUUID)
+		if err != nil {
+			return nil, err
+		}
+		if !isexist {
+			return nil, ErrAccountIsNotExist
+		}
+	}
+
+	search := *filter
+	search.Limit = SecurityEventsLimit(filter.Limit)
+	return u.repo.Find(ctx, &search)
+}
+
+// SecurityEventsLimit is the page size for the limit, DefaultSecurityEventsLimit if limit isn't positive and MaxSecurityEventsLimit at most
+func SecurityEventsLimit(limit int) int {
+	switch {
+	case limit <= 0:
+		return DefaultSecurityEventsLimit
+	case limit > MaxSecurityEventsLimit:
+		return MaxSecurityEventsLimit
+	default:
+		return limit
+	}
+}
@@ -0,0 +1,152 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	usecases_test "github.com/alexsibrin/runbot-auth/internal/usecases/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestSecurityEventInit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	eventmock := usecases_test.NewMockISecurityEventRepo(ctrl)
+	accountmock := usecases_test.NewMockIAccountRepo(ctrl)
+
+	testCases := []struct {
+		name        string
+		in          *SecurityEventDependencies
+		out         *SecurityEvent
+		expectedErr error
+	}{
+		{
+			name: "Regular valid case",
+			in:   &SecurityEventDependencies{Repo: eventmock, AccountRepo: accountmock},
+			out:  &SecurityEvent{repo: eventmock, accountrepo: accountmock},
+		},
+		{
+			name:        "Dependencies are nil",
+			in:          nil,
+			expectedErr: ErrDependenciesAreNil,
+		},
+		{
+			name:        "Repo is nil",
+			in:          &SecurityEventDependencies{AccountRepo: accountmock},
+			expectedErr: ErrSecurityEventRepoIsNil,
+		},
+		{
+			name:        "Account repo is nil",
+			in:          &SecurityEventDependencies{Repo: eventmock},
+			expectedErr: ErrAccountRepoIsNil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			uc, err := NewSecurityEvent(tc.in)
+			assert.Equal(t, tc.out, uc)
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestSecurityEvent_GetByAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eventmock := usecases_test.NewMockISecurityEventRepo(ctrl)
+	uc := &SecurityEvent{repo: eventmock, accountrepo: usecases_test.NewMockIAccountRepo(ctrl)}
+	ctx := context.TODO()
+
+	testCases := []struct {
+		name          string
+		limit         int
+		expectedLimit int
+	}{
+		{name: "Limit is kept", limit: 10, expectedLimit: 10},
+		{name: "Default limit", limit: 0, expectedLimit: DefaultSecurityEventsLimit},
+		{name: "Negative limit", limit: -1, expectedLimit: DefaultSecurityEventsLimit},
+		{name: "Limit is capped", limit: MaxSecurityEventsLimit + 1, expectedLimit: MaxSecurityEventsLimit},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			events := []*entities.SecurityEvent{{ID: 5, AccountUUID: "accountuuid"}}
+			eventmock.EXPECT().Find(ctx, &repositories.SecurityEventFilter{
+				AccountUUID: "accountuuid",
+				BeforeID:    10,
+				Limit:       tc.expectedLimit,
+			}).Return(events, nil)
+
+			found, err := uc.GetByAccount(ctx, "accountuuid", 10, tc.limit)
+			assert.NoError(t, err)
+			assert.Equal(t, events, found)
+		})
+	}
+}
+
+func TestSecurityEvent_Search(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eventmock := usecases_test.NewMockISecurityEventRepo(ctrl)
+	accountmock := usecases_test.NewMockIAccountRepo(ctrl)
+	uc := &SecurityEvent{repo: eventmock, accountrepo: accountmock}
+	ctx := context.TODO()
+	errSomeRepo := errors.New("some repo error")
+
+	testCases := []struct {
+		name        string
+		filter      *repositories.SecurityEventFilter
+		setupMocks  func()
+		expectedErr error
+	}{
+		{
+			name:   "Search by account",
+			filter: &repositories.SecurityEventFilter{AccountUUID: "accountuuid", Types: []uint8{entities.SecurityEventSignInFailed}},
+			setupMocks: func() {
+				accountmock.EXPECT().IsExistByUUID(ctx, "accountuuid").Return(true, nil)
+				eventmock.EXPECT().Find(ctx, &repositories.SecurityEventFilter{
+					AccountUUID: "accountuuid",
+					Types:       []uint8{entities.SecurityEventSignInFailed},
+					Limit:       DefaultSecurityEventsLimit,
+				}).Return(nil, nil)
+			},
+		},
+		{
+			name:   "Search by IP",
+			filter: &repositories.SecurityEventFilter{IP: "10.0.0.1", Limit: 5},
+			setupMocks: func() {
+				eventmock.EXPECT().Find(ctx, &repositories.SecurityEventFilter{IP: "10.0.0.1", Limit: 5}).Return(nil, nil)
+			},
+		},
+		{
+			name:   "Account is not exist",
+			filter: &repositories.SecurityEventFilter{AccountUUID: "accountuuid"},
+			setupMocks: func() {
+				accountmock.EXPECT().IsExistByUUID(ctx, "accountuuid").Return(false, nil)
+			},
+			expectedErr: ErrAccountIsNotExist,
+		},
+		{
+			name:   "Repo error",
+			filter: &repositories.SecurityEventFilter{AccountUUID: "accountuuid"},
+			setupMocks: func() {
+				accountmock.EXPECT().IsExistByUUID(ctx, "accountuuid").Return(false, errSomeRepo)
+			},
+			expectedErr: errSomeRepo,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			_, err := uc.Search(ctx, tc.filter)
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"github.com/alexsibrin/runbot-auth/internal/useragent"
+	"github.com/google/uuid"
+	"time"
+	"unicode/utf8"
+)
+
+//go:generate mockgen -destination mocks/mock_session.go -package usecases_test github.com/alexsibrin/runbot-auth/internal/usecases ISessionRepo
+
+const (
+	DefaultSessionTTL = 30 * 24 * time.Hour
+
+	// the user agent is truncated, so a client can't bloat the session list
+	userAgentMaxLength = 512
+)
+
+var (
+	ErrSessionRepoIsNil  = errors.New("dependency session repo is nil")
+	ErrSessionIsNotExist = errors.New("session is not exist")
+	ErrSessionIsRevoked  = errors.New("session is revoked")
+	ErrSessionIsExpired  = errors.New("session is expired")
+)
+
+// SessionStartRequest describes the client which signs in
+type SessionStartRequest struct {
+	AccountUUID string
+	IP          string
+	UserAgent   string
+}
+
+// SessionRevokeAllRequest signs all the devices out of the account on behalf of the actor,
+// the revocation is recorded in the audit log if Audit is set
+type SessionRevokeAllRequest struct {
+	AccountUUID string
+	Actor       string
+	Audit       *AuditMetadata
+}
+
+// SessionRefreshRequest describes the client which refreshes the token of the session
+type SessionRefreshRequest struct {
+	UUID        string
+	AccountUUID string
+	IP          string
+	UserAgent   string
+}
+
+type ISessionRepo interface {
+	Create(ctx context.Context, session *entities.Session) error
+	GetOneByUUID(ctx context.Context, uuid string) (*entities.Session, error)
+	// GetActiveByAccount returns the sessions which are neither revoked nor expired at now, the last refreshed go first
+	GetActiveByAccount(ctx context.Context, accountuuid string, now int64) ([]*entities.Session, error)
+	// GetByAccount returns all the sessions of the account, the last refreshed go first
+	GetByAccount(ctx context.Context, accountuuid string) ([]*entities.Session, error)
+	// Refresh stores the client and the times of the session, revoked sessions are reported as not found
+	Refresh(ctx context.Context, session *entities.Session) error
+	// Revoke revokes the session, the revoked sessions are reported as not found
+	Revoke(ctx context.Context, uuid string, revokedat int64) error
+	RevokeAllByAccount(ctx context.Context, accountuuid string, revokedat int64) error
+	DeleteByAccount(ctx context.Context, accountuuid string) error
+}
+
+type SessionDependencies struct {
+	Repo           ISessionRepo
+	SecurityEvents ISecurityEventRepo
+	Transactor     ITransactor
+	// TTL is the lifetime of the refresh token, DefaultSessionTTL is used if it isn't positive.
+	// The session is prolonged by TTL on every refresh
+	TTL time.Duration
+	// Metrics is optional
+	Metrics IMetrics
+}
+
+// Session tracks the devices signed in to the accounts, a revoked session can't refresh its tokens
+type Session struct {
+	repo           ISessionRepo
+	securityevents ISecurityEventRepo
+	transactor     ITransactor
+	metrics        IMetrics
+	ttl            time.Duration
+}
+
+func NewSession(d *SessionDependencies) (*Session, error) {
+	if d == nil {
+		return nil, ErrDependenciesAreNil
+	}
+	if d.Repo == nil {
+		return nil, ErrSessionRepoIsNil
+	}
+	if d.SecurityEvents == nil {
+		return nil, ErrSecurityEventRepoIsNil
+	}
+	if d.Transactor == nil {
+		return nil, ErrTransactorIsNil
+	}
+	ttl := d.TTL
+	if ttl <= 0 {
+		ttl = DefaultSessionTTL
+	}
+	return &Session{
+		repo:           d.Repo,
+		securityevents: d.SecurityEvents,
+		transactor:     d.Transactor,
+		metrics:        d.Metrics,
+		ttl:            ttl,
+	}, nil
+}
+
+func (u *Session) Start(ctx context.Context, r *SessionStartRequest) (*entities.Session, error) {
+	now := time.Now()
+	useragent := truncate(r.UserAgent, userAgentMaxLength)
+
+	session := &entities.Session{
+		UUID:        uuid.NewString(),
+		AccountUUID: r.AccountUUID,
+		IP:          r.IP,
+		UserAgent:   useragent,
+		DeviceName:  deviceName(useragent),
+		CreatedAt:   now.Unix(),
+		RefreshedAt: now.Unix(),
+		ExpiresAt:   now.Add(u.ttl).Unix(),
+	}
+	if err := u.repo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Refresh prolongs the session of the account, the last seen client of the session is updated
+// and the refresh is recorded in the security log
+func (u *Session) Refresh(ctx context.Context, r *SessionRefreshRequest) (*entities.Session, error) {
+	session, err := u.getOwned(ctx, r.AccountUUID, r.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if session.IsRevoked() {
+		return nil, ErrSessionIsRevoked
+	}
+	if session.IsExpired(now.Unix()) {
+		return nil, ErrSessionIsExpired
+	}
+
+	session.IP = r.IP
+	session.UserAgent = truncate(r.UserAgent, userAgentMaxLength)
+	session.DeviceName = deviceName(session.UserAgent)
+	session.RefreshedAt = now.Unix()
+	session.ExpiresAt = now.Add(u.ttl).Unix()
+
+	err = u.repo.Refresh(ctx, session)
+	// the session is revoked concurrently
+	if errors.As(err, &repositories.ErrSessionNotFoundByUUID{}) {
+		return nil, ErrSessionIsRevoked
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = u.securityevents.Add(ctx, &entities.SecurityEvent{
+		AccountUUID: session.AccountUUID,
+		Type:        entities.SecurityEventRefresh,
+		IP:          session.IP,
+		UserAgent:   session.UserAgent,
+		Details:     session.DeviceName,
+		CreatedAt:   session.RefreshedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if u.metrics != nil {
+		u.metrics.Refreshed()
+	}
+	return session, nil
+}
+
+// Check reports whether the session of the account can still be used, i.e. it's neither revoked nor expired
+func (u *Session) Check(ctx context.Context, accountuuid, sessionuuid string) error {
+	session, err := u.getOwned(ctx, accountuuid, sessionuuid)
+	if err != nil {
+		return err
+	}
+	if session.IsRevoked() {
+		return ErrSessionIsRevoked
+	}
+	if session.IsExpired(time.Now().Unix()) {
+		return ErrSessionIsExpired
+	}
+	return nil
+}
+
+// GetActive returns the devices signed in to the account
+func (u *Session) GetActive(ctx context.Context, accountuuid string) ([]*entities.Session, error) {
+	return u.repo.GetActiveByAccount(ctx, accountuuid, time.Now().Unix())
+}
+
+// Revoke signs the device out, the session must belong to the account
+func (u *Session) Revoke(ctx context.Context, accountuuid, sessionuuid string) error {
+	session, err := u.getOwned(ctx, accountuuid, sessionuuid)
+	if err != nil {
+		return err
+	}
+	if session.IsRevoked() {
+		return ErrSessionIsRevoked
+	}
+
+	err = u.repo.Revoke(ctx, sessionuuid, time.Now().Unix())
+	if errors.As(err, &repositories.ErrSessionNotFoundByUUID{}) {
+		return ErrSessionIsRevoked
+	}
+	return err
+}
+
+// RevokeAll signs all the devices out of the account and returns how many sessions were active.
+// The revocation is recorded in the security log, nothing is recorded if there was no active session
+func (u *Session) RevokeAll(ctx context.Context, r *SessionRevokeAllRequest) (int, error) {
+	now := time.Now().Unix()
+	var revoked int
+	err := u.transactor.WithTx(ctx, func(repos *TxRepos) error {
+		sessions, err := repos.Session.GetActiveByAccount(ctx, r.AccountUUID, now)
+		if err != nil {
+			return err
+		}
+		revoked = len(sessions)
+		if revoked == 0 {
+			return nil
+		}
+
+		if err = repos.Session.RevokeAllByAccount(ctx, r.AccountUUID, now); err != nil {
+			return err
+		}
+		// the event doesn't disclose the actor, the security log is shown to the account owner
+		_, err = repos.SecurityEvent.Add(ctx, &entities.SecurityEvent{
+			AccountUUID: r.AccountUUID,
+			Type:        entities.SecurityEventSessionsRevoked,
+			Details:     fmt.Sprintf("%d sessions", revoked),
+			CreatedAt:   now,
+		})
+		if err != nil {
+			return err
+		}
+		if r.Audit == nil {
+			return nil
+		}
+		return appendAuditRecord(ctx, repos.AuditLog, newAuditRecord(r.Audit, r.Actor, entities.AuditActionSessionsRevoked, r.AccountUUID,
+			auditSessions{Active: revoked},
+			auditSessions{Active: 0},
+		))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return revoked, nil
+}
+
+// auditSessions is the audited number of the active sessions of the account
+type auditSessions struct {
+	Active int
+}
+
+// getOwned reports the sessions of the other accounts as not existing to not disclose them
+func (u *Session) getOwned(ctx context.Context, accountuuid, sessionuuid string) (*entities.Session, error) {
+	session, err := u.repo.GetOneByUUID(ctx, sessionuuid)
+	if err != nil {
+		if errors.As(err, &repositories.ErrSessionNotFoundByUUID{}) {
+			return nil, ErrSessionIsNotExist
+		}
+		return nil, err
+	}
+	if session.AccountUUID != accountuuid {
+		return nil, ErrSessionIsNotExist
+	}
+	return session, nil
+}
+
+func deviceName(ua string) string {
+	return useragent.Parse(ua).Name()
+}
+
+// truncate cuts s to max bytes without breaking a UTF-8 sequence
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
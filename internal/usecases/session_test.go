@@ -0,0 +1,427 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	usecases_test "github.com/alexsibrin/runbot-auth/internal/usecases/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"strings"
+	"testing"
+	"time"
+)
+
+const chromeOnWindows = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+func TestSessionInit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sessionmock := usecases_test.NewMockISessionRepo(ctrl)
+	eventmock := usecases_test.NewMockISecurityEventRepo(ctrl)
+	txstub := &txStub{}
+
+	testCases := []struct {
+		name        string
+		in          *SessionDependencies
+		out         *Session
+		expectedErr error
+	}{
+		{
+			name: "Regular valid case",
+			in:   &SessionDependencies{Repo: sessionmock, SecurityEvents: eventmock, Transactor: txstub, TTL: time.Hour},
+			out:  &Session{repo: sessionmock, securityevents: eventmock, transactor: txstub, ttl: time.Hour},
+		},
+		{
+			name: "Default TTL",
+			in:   &SessionDependencies{Repo: sessionmock, SecurityEvents: eventmock, Transactor: txstub},
+			out:  &Session{repo: sessionmock, securityevents: eventmock, transactor: txstub, ttl: DefaultSessionTTL},
+		},
+		{
+			name:        "Dependencies are nil",
+			in:          nil,
+			expectedErr: ErrDependenciesAreNil,
+		},
+		{
+			name:        "Repo is nil",
+			in:          &SessionDependencies{SecurityEvents: eventmock, Transactor: txstub, TTL: time.Hour},
+			expectedErr: ErrSessionRepoIsNil,
+		},
+		{
+			name:        "Security event repo is nil",
+			in:          &SessionDependencies{Repo: sessionmock, Transactor: txstub, TTL: time.Hour},
+			expectedErr: ErrSecurityEventRepoIsNil,
+		},
+		{
+			name:        "Transactor is nil",
+			in:          &SessionDependencies{Repo: sessionmock, SecurityEvents: eventmock, TTL: time.Hour},
+			expectedErr: ErrTransactorIsNil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			uc, err := NewSession(tc.in)
+			assert.Equal(t, tc.out, uc)
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestSession_Start(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sessionmock := usecases_test.NewMockISessionRepo(ctrl)
+	uc := &Session{repo: sessionmock, ttl: time.Hour}
+	ctx := context.TODO()
+
+	t.Run("Session is created", func(t *testing.T) {
+		var created *entities.Session
+		sessionmock.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, s *entities.Session) error {
+			created = s
+			return nil
+		})
+
+		session, err := uc.Start(ctx, &SessionStartRequest{
+			AccountUUID: "accountuuid",
+			IP:          "10.0.0.1",
+			UserAgent:   chromeOnWindows,
+		})
+		require.NoError(t, err)
+		assert.Same(t, created, session)
+		assert.NotEmpty(t, session.UUID)
+		assert.Equal(t, "accountuuid", session.AccountUUID)
+		assert.Equal(t, "10.0.0.1", session.IP)
+		assert.Equal(t, "Chrome on Windows", session.DeviceName)
+		assert.Equal(t, session.CreatedAt, session.RefreshedAt)
+		assert.Equal(t, session.CreatedAt+int64(time.Hour.Seconds()), session.ExpiresAt)
+		assert.Zero(t, session.RevokedAt)
+	})
+
+	t.Run("User agent is truncated", func(t *testing.T) {
+		sessionmock.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+		session, err := uc.Start(ctx, &SessionStartRequest{
+			AccountUUID: "accountuuid",
+			UserAgent:   strings.Repeat("я", userAgentMaxLength),
+		})
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(session.UserAgent), userAgentMaxLength)
+		assert.Equal(t, strings.Repeat("я", userAgentMaxLength/2), session.UserAgent)
+	})
+
+	t.Run("Repo fails", func(t *testing.T) {
+		someErr := errors.New("some error")
+		sessionmock.EXPECT().Create(ctx, gomock.Any()).Return(someErr)
+
+		session, err := uc.Start(ctx, &SessionStartRequest{AccountUUID: "accountuuid"})
+		assert.Nil(t, session)
+		assert.ErrorIs(t, err, someErr)
+	})
+}
+
+func TestSession_Refresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sessionmock := usecases_test.NewMockISessionRepo(ctrl)
+	eventmock := usecases_test.NewMockISecurityEventRepo(ctrl)
+	uc := &Session{repo: sessionmock, securityevents: eventmock, ttl: time.Hour}
+	ctx := context.TODO()
+	now := time.Now().Unix()
+	errSomeRepo := errors.New("some repo error")
+
+	request := &SessionRefreshRequest{
+		UUID:        "sessionuuid",
+		AccountUUID: "accountuuid",
+		IP:          "10.0.0.2",
+		UserAgent:   chromeOnWindows,
+	}
+	active := func() *entities.Session {
+		return &entities.Session{
+			UUID:        "sessionuuid",
+			AccountUUID: "accountuuid",
+			IP:          "10.0.0.1",
+			CreatedAt:   now - 100,
+			RefreshedAt: now - 100,
+			ExpiresAt:   now + 100,
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		setupMocks  func()
+		expectedErr error
+	}{
+		{
+			name: "Session is refreshed",
+			setupMocks: func() {
+				sessionmock.EXPECT().GetOneByUUID(ctx, "sessionuuid").Return(active(), nil)
+				sessionmock.EXPECT().Refresh(ctx, gomock.Any()).Return(nil)
+				eventmock.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *entities.SecurityEvent) (*entities.SecurityEvent, error) {
+					assert.Equal(t, "accountuuid", event.AccountUUID)
+					assert.Equal(t, entities.SecurityEventRefresh, event.Type)
+					assert.Equal(t, "10.0.0.2", event.IP)
+					assert.Equal(t, "Chrome on Windows", event.Details)
+					return event, nil
+				})
+			},
+		},
+		{
+			name: "Security event is not recorded",
+			setupMocks: func() {
+				sessionmock.EXPECT().GetOneByUUID(ctx, "sessionuuid").Return(active(), nil)
+				sessionmock.EXPECT().Refresh(ctx, gomock.Any()).Return(nil)
+				eventmock.EXPECT().Add(ctx, gomock.Any()).Return(nil, errSomeRepo)
+			},
+			expectedErr: errSomeRepo,
+		},
+		{
+			name: "Session is not exist",
+			setupMocks: func() {
+				sessionmock.EXPECT().GetOneByUUID(ctx, "sessionuuid").Return(nil, repositories.NewErrSessionNotFoundByUUID("sessionuuid"))
+			},
+			expectedErr: ErrSessionIsNotExist,
+		},
+		{
+			name: "Session belongs to another account",
+			setupMocks: func() {
+				session := active()
+				session.AccountUUID = "anotheraccountuuid"
+				sessionmock.EXPECT().GetOneByUUID(ctx, "sessionuuid").Return(session, nil)
+			},
+			expectedErr: ErrSessionIsNotExist,
+		},
+		{
+			name: "Session is revoked",
+			setupMocks: func() {
+				session := active()
+				session.RevokedAt = now - 10
+				sessionmock.EXPECT().GetOneByUUID(ctx, "sessionuuid").Return(session, nil)
+			},
+			expectedErr: ErrSessionIsRevoked,
+		},
+		{
+			name: "Session is expired",
+			setupMocks: func() {
+				session := active()
+				session.ExpiresAt = now - 10
+				sessionmock.EXPECT().GetOneByUUID(ctx, "sessionuuid").Return(session, nil)
+			},
+			expectedErr: ErrSessionIsExpired,
+		},
+		{
+			name: "Session is revoked concurrently",
+			setupMocks: func() {
+				sessionmock.EXPECT().GetOneByUUID(ctx, "sessionuuid").Return(active(), nil)
+				sessionmock.EXPECT().Refresh(ctx, gomock.Any()).Return(repositories.NewErrSessionNotFoundByUUID("sessionuuid"))
+			},
+			expectedErr: ErrSessionIsRevoked,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			session, err := uc.Refresh(ctx, request)
+			if tc.expectedErr != nil {
+				assert.Nil(t, session)
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "10.0.0.2", session.IP)
+			assert.Equal(t, "Chrome on Windows", session.DeviceName)
+			assert.Equal(t, now-100, session.CreatedAt)
+			assert.GreaterOrEqual(t, session.RefreshedAt, now)
+			assert.Equal(t, session.RefreshedAt+int64(time.Hour.Seconds()), session.ExpiresAt)
+		})
+	}
+}
+
+func TestSession_Revoke(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sessionmock := usecases_test.NewMockISessionRepo(ctrl)
+	uc := &Session{repo: sessionmock, ttl: time.Hour}
+	ctx := context.TODO()
+
+	testCases := []struct {
+		name        string
+		setupMocks  func()
+		expectedErr error
+	}{
+		{
+			name: "Session is revoked",
+			setupMocks: func() {
+				sessionmock.EXPECT().GetOneByUUID(ctx, "sessionuuid").Return(&entities.Session{UUID: "sessionuuid", AccountUUID: "accountuuid"}, nil)
+				sessionmock.EXPECT().Revoke(ctx, "sessionuuid", gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "Session belongs to another account",
+			setupMocks: func() {
+				sessionmock.EXPECT().GetOneByUUID(ctx, "sessionuuid").Return(&entities.Session{UUID: "sessionuuid", AccountUUID: "anotheraccountuuid"}, nil)
+			},
+			expectedErr: ErrSessionIsNotExist,
+		},
+		{
+			name: "Session is already revoked",
+			setupMocks: func() {
+				sessionmock.EXPECT().GetOneByUUID(ctx, "sessionuuid").Return(&entities.Session{UUID: "sessionuuid", AccountUUID: "accountuuid", RevokedAt: 100}, nil)
+			},
+			expectedErr: ErrSessionIsRevoked,
+		},
+		{
+			name: "Session is revoked concurrently",
+			setupMocks: func() {
+				sessionmock.EXPECT().GetOneByUUID(ctx, "sessionuuid").Return(&entities.Session{UUID: "sessionuuid", AccountUUID: "accountuuid"}, nil)
+				sessionmock.EXPECT().Revoke(ctx, "sessionuuid", gomock.Any()).Return(repositories.NewErrSessionNotFoundByUUID("sessionuuid"))
+			},
+			expectedErr: ErrSessionIsRevoked,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			err := uc.Revoke(ctx, "accountuuid", "sessionuuid")
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestSession_Check(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sessionmock := usecases_test.NewMockISessionRepo(ctrl)
+	uc := &Session{repo: sessionmock, ttl: time.Hour}
+	ctx := context.TODO()
+	future := time.Now().Add(time.Hour).Unix()
+
+	testCases := []struct {
+		name        string
+		setupMocks  func()
+		expectedErr error
+	}{
+		{
+			name: "Session is active",
+			setupMocks: func() {
+				sessionmock.EXPECT().GetOneByUUID(ctx, "sessionuuid").Return(&entities.Session{UUID: "sessionuuid", AccountUUID: "accountuuid", ExpiresAt: future}, nil)
+			},
+		},
+		{
+			name: "Session is revoked",
+			setupMocks: func() {
+				sessionmock.EXPECT().GetOneByUUID(ctx, "sessionuuid").Return(&entities.Session{UUID: "sessionuuid", AccountUUID: "accountuuid", ExpiresAt: future, RevokedAt: 100}, nil)
+			},
+			expectedErr: ErrSessionIsRevoked,
+		},
+		{
+			name: "Session is expired",
+			setupMocks: func() {
+				sessionmock.EXPECT().GetOneByUUID(ctx, "sessionuuid").Return(&entities.Session{UUID: "sessionuuid", AccountUUID: "accountuuid", ExpiresAt: 100}, nil)
+			},
+			expectedErr: ErrSessionIsExpired,
+		},
+		{
+			name: "Session belongs to another account",
+			setupMocks: func() {
+				sessionmock.EXPECT().GetOneByUUID(ctx, "sessionuuid").Return(&entities.Session{UUID: "sessionuuid", AccountUUID: "anotheraccountuuid", ExpiresAt: future}, nil)
+			},
+			expectedErr: ErrSessionIsNotExist,
+		},
+		{
+			name: "Session is deleted",
+			setupMocks: func() {
+				sessionmock.EXPECT().GetOneByUUID(ctx, "sessionuuid").Return(nil, repositories.NewErrSessionNotFoundByUUID("sessionuuid"))
+			},
+			expectedErr: ErrSessionIsNotExist,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			err := uc.Check(ctx, "accountuuid", "sessionuuid")
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestSession_RevokeAll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sessionmock := usecases_test.NewMockISessionRepo(ctrl)
+	eventmock := usecases_test.NewMockISecurityEventRepo(ctrl)
+	auditmock := usecases_test.NewMockIAuditLogRepo(ctrl)
+	uc := &Session{transactor: &txStub{repos: &TxRepos{Session: sessionmock, SecurityEvent: eventmock, AuditLog: auditmock}}, ttl: time.Hour}
+	ctx := context.TODO()
+	repoErr := errors.New("repo error")
+	request := &SessionRevokeAllRequest{AccountUUID: "accountuuid", Actor: "alice", Audit: &AuditMetadata{Method: "cli revoke-sessions"}}
+
+	testCases := []struct {
+		name          string
+		setupMocks    func()
+		expectedCount int
+		expectedErr   error
+	}{
+		{
+			name: "Sessions are revoked",
+			setupMocks: func() {
+				sessionmock.EXPECT().GetActiveByAccount(ctx, "accountuuid", gomock.Any()).Return([]*entities.Session{{UUID: "first"}, {UUID: "second"}}, nil)
+				sessionmock.EXPECT().RevokeAllByAccount(ctx, "accountuuid", gomock.Any()).Return(nil)
+				eventmock.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *entities.SecurityEvent) (*entities.SecurityEvent, error) {
+					assert.Equal(t, "accountuuid", event.AccountUUID)
+					assert.Equal(t, entities.SecurityEventSessionsRevoked, event.Type)
+					assert.Equal(t, "2 sessions", event.Details)
+					return event, nil
+				})
+				auditmock.EXPECT().Last(ctx).Return(nil, nil)
+				auditmock.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, record *entities.AuditRecord) (*entities.AuditRecord, error) {
+					assert.Equal(t, "alice", record.Actor)
+					assert.Equal(t, entities.AuditActionSessionsRevoked, record.Action)
+					assert.Equal(t, "accountuuid", record.TargetUUID)
+					assert.Equal(t, `{"Active":2}`, record.Before)
+					assert.Equal(t, `{"Active":0}`, record.After)
+					assert.Equal(t, "cli revoke-sessions", record.Method)
+					assert.Equal(t, record.ComputeHash(), record.Hash)
+					return record, nil
+				})
+			},
+			expectedCount: 2,
+		},
+		{
+			name: "No active sessions",
+			setupMocks: func() {
+				sessionmock.EXPECT().GetActiveByAccount(ctx, "accountuuid", gomock.Any()).Return(nil, nil)
+			},
+		},
+		{
+			name: "Repo fails",
+			setupMocks: func() {
+				sessionmock.EXPECT().GetActiveByAccount(ctx, "accountuuid", gomock.Any()).Return([]*entities.Session{{UUID: "first"}}, nil)
+				sessionmock.EXPECT().RevokeAllByAccount(ctx, "accountuuid", gomock.Any()).Return(repoErr)
+			},
+			expectedErr: repoErr,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			count, err := uc.RevokeAll(ctx, request)
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expectedCount, count)
+		})
+	}
+}
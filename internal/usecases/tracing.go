@@ -0,0 +1,8 @@
+package usecases
+
+import (
+	"go.opentelemetry.io/otel"
+)
+
+// tracer starts the spans of the usecases, they aren't recorded until the tracer provider is set up
+var tracer = otel.Tracer("github.com/alexsibrin/runbot-auth/internal/usecases")
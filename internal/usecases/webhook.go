@@ -0,0 +1,516 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"github.com/google/uuid"
+	"time"
+)
+
+//go:generate mockgen -destination mocks/mock_webhook.go -package usecases_test github.com/alexsibrin/runbot-auth/internal/usecases IWebhookRepo,IWebhookDeliveryRepo,IWebhookSender
+
+const (
+	DefaultWebhookMaxAttempts = 10
+	DefaultWebhookBackoffBase = 30 * time.Second
+	DefaultWebhookBackoffMax  = 6 * time.Hour
+	DefaultWebhookRetention   = 7 * 24 * time.Hour
+
+	DefaultWebhookDeliveriesLimit = 50
+	MaxWebhookDeliveriesLimit     = 500
+
+	// webhookDeliveryLease is the time after which a delivery claimed by a crashed worker is claimed again
+	webhookDeliveryLease = 5 * time.Minute
+	// webhookSecretLength is the number of the random bytes of a secret
+	webhookSecretLength = 32
+	// deliveryErrorMaxLength keeps the last error of a delivery short, the errors may quote the responses
+	deliveryErrorMaxLength = 512
+)
+
+var (
+	ErrWebhookRepoIsNil          = errors.New("dependency webhook repo is nil")
+	ErrWebhookDeliveryRepoIsNil  = errors.New("dependency webhook delivery repo is nil")
+	ErrWebhookSenderIsNil        = errors.New("dependency webhook sender is nil")
+	ErrWebhookIsNotFound         = errors.New("webhook is not found")
+	ErrWebhookDeliveryIsNotFound = errors.New("webhook delivery is not found")
+	errWebhookIsDeleted          = errors.New("webhook is deleted")
+	errWebhookIsInactive         = errors.New("webhook is inactive")
+)
+
+type IWebhookRepo interface {
+	Create(ctx context.Context, webhook *entities.Webhook) error
+	GetOneByUUID(ctx context.Context, uuid string) (*entities.Webhook, error)
+	// GetAll returns all the webhooks, the earliest go first
+	GetAll(ctx context.Context) ([]*entities.Webhook, error)
+	// Update changes the URL, the secret, the event types, the activity and the update time
+	Update(ctx context.Context, webhook *entities.Webhook) error
+	Delete(ctx context.Context, uuid string) error
+}
+
+type IWebhookDeliveryRepo interface {
+	// Add stores the delivery, a delivery of the same event to the same webhook is stored once
+	Add(ctx context.Context, delivery *entities.WebhookDelivery) error
+	GetOneByID(ctx context.Context, id int64) (*entities.WebhookDelivery, error)
+	// ClaimDue postpones the pending delivery due the earliest at now until leaseuntil and returns it,
+	// so the other workers don't claim it. Nil is returned if there is none
+	ClaimDue(ctx context.Context, now, leaseuntil int64) (*entities.WebhookDelivery, error)
+	// Update changes the status, the attempts, the next attempt time, the last result and the update time
+	Update(ctx context.Context, delivery *entities.WebhookDelivery) error
+	// Find returns up to filter.Limit latest deliveries matching the filter, the latest go first
+	Find(ctx context.Context, filter *repositories.WebhookDeliveryFilter) ([]*entities.WebhookDelivery, error)
+	DeleteByWebhook(ctx context.Context, webhookuuid string) error
+	// SetPayloadByEvent replaces the payload of all the deliveries of the event
+	SetPayloadByEvent(ctx context.Context, eventuuid, payload string) error
+	// DeleteFinishedBefore deletes the delivered and dead deliveries last updated by before
+	DeleteFinishedBefore(ctx context.Context, before int64) (int, error)
+}
+
+// IWebhookSender posts the delivery to the webhook, the status code is zero if there is no response.
+// The delivery is done once Send returns nil
+type IWebhookSender interface {
+	Send(ctx context.Context, webhook *entities.Webhook, delivery *entities.WebhookDelivery) (int, error)
+}
+
+type WebhookConfig struct {
+	// MaxAttempts is the number of attempts after which a delivery is dead, DefaultWebhookMaxAttempts if it isn't positive
+	MaxAttempts int
+	// BackoffBase is the delay after the first failed attempt, it's doubled after every next one up to BackoffMax.
+	// The defaults are DefaultWebhookBackoffBase and DefaultWebhookBackoffMax
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// Retention is how long the finished deliveries are kept, DefaultWebhookRetention if it isn't positive
+	Retention time.Duration
+}
+
+type WebhookDependencies struct {
+	Repo         IWebhookRepo
+	DeliveryRepo IWebhookDeliveryRepo
+	Sender       IWebhookSender
+	Transactor   ITransactor
+	Config       *WebhookConfig
+}
+
+// WebhookCreateRequest subscribes the URL to the event types, the empty list subscribes to all of them.
+// The creation is recorded in the audit log if Audit is set
+type WebhookCreateRequest struct {
+	URL        string
+	EventTypes []string
+	Actor      string
+	Audit      *AuditMetadata
+}
+
+// WebhookUpdateRequest replaces the URL, the event types and the activity of the webhook,
+// RotateSecret replaces the secret with a new one. The change is recorded in the audit log if Audit is set
+type WebhookUpdateRequest struct {
+	UUID         string
+	URL          string
+	EventTypes   []string
+	Active       bool
+	RotateSecret bool
+	Actor        string
+	Audit        *AuditMetadata
+}
+
+// WebhookDeleteRequest deletes the webhook, the deletion is recorded in the audit log if Audit is set
+type WebhookDeleteRequest struct {
+	UUID  string
+	Actor string
+	Audit *AuditMetadata
+}
+
+// WebhookRedeliverRequest sends the delivery again, the redelivery is recorded in the audit log if Audit is set
+type WebhookRedeliverRequest struct {
+	ID    int64
+	Actor string
+	Audit *AuditMetadata
+}
+
+// Webhook manages the webhooks of the partners and delivers the domain events to them. It's a publisher
+// of the outbox: Publish enqueues a delivery per subscribed webhook and DeliverNext sends them with retries
+type Webhook struct {
+	repo         IWebhookRepo
+	deliveryrepo IWebhookDeliveryRepo
+	sender       IWebhookSender
+	transactor   ITransactor
+	maxattempts  int
+	backoffbase  time.Duration
+	backoffmax   time.Duration
+	retention    time.Duration
+}
+
+func NewWebhook(d *WebhookDependencies) (*Webhook, error) {
+	if d == nil {
+		return nil, ErrDependenciesAreNil
+	}
+	if d.Repo == nil {
+		return nil, ErrWebhookRepoIsNil
+	}
+	if d.DeliveryRepo == nil {
+		return nil, ErrWebhookDeliveryRepoIsNil
+	}
+	if d.Sender == nil {
+		return nil, ErrWebhookSenderIsNil
+	}
+	if d.Transactor == nil {
+		return nil, ErrTransactorIsNil
+	}
+
+	u := &Webhook{
+		repo:         d.Repo,
+		deliveryrepo: d.DeliveryRepo,
+		sender:       d.Sender,
+		transactor:   d.Transactor,
+		maxattempts:  DefaultWebhookMaxAttempts,
+		backoffbase:  DefaultWebhookBackoffBase,
+		backoffmax:   DefaultWebhookBackoffMax,
+		retention:    DefaultWebhookRetention,
+	}
+	if d.Config != nil && d.Config.MaxAttempts > 0 {
+		u.maxattempts = d.Config.MaxAttempts
+	}
+	if d.Config != nil && d.Config.BackoffBase > 0 {
+		u.backoffbase = d.Config.BackoffBase
+	}
+	if d.Config != nil && d.Config.BackoffMax > 0 {
+		u.backoffmax = d.Config.BackoffMax
+	}
+	if d.Config != nil && d.Config.Retention > 0 {
+		u.retention = d.Config.Retention
+	}
+	return u, nil
+}
+
+// Create adds an active webhook with a new secret, the secret is shared with the partner to check the signatures
+func (u *Webhook) Create(ctx context.Context, r *WebhookCreateRequest) (*entities.Webhook, error) {
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	webhook := &entities.Webhook{
+		UUID:       uuid.NewString(),
+		URL:        r.URL,
+		Secret:     secret,
+		EventTypes: r.EventTypes,
+		Active:     true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	err = u.transactor.WithTx(ctx, func(repos *TxRepos) error {
+		if err := repos.Webhook.Create(ctx, webhook); err != nil {
+			return err
+		}
+		if r.Audit == nil {
+			return nil
+		}
+		return appendAuditRecord(ctx, repos.AuditLog, newAuditRecord(r.Audit, r.Actor, entities.AuditActionWebhookCreated, webhook.UUID,
+			nil,
+			auditWebhookOf(webhook, false),
+		))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (u *Webhook) GetOne(ctx context.Context, webhookuuid string) (*entities.Webhook, error) {
+	webhook, err := u.repo.GetOneByUUID(ctx, webhookuuid)
+	if err != nil {
+		return nil, u.mapNotFoundError(err)
+	}
+	return webhook, nil
+}
+
+func (u *Webhook) GetAll(ctx context.Context) ([]*entities.Webhook, error) {
+	return u.repo.GetAll(ctx)
+}
+
+func (u *Webhook) Update(ctx context.Context, r *WebhookUpdateRequest) (*entities.Webhook, error) {
+	var (
+		secret  string
+		webhook *entities.Webhook
+		err     error
+	)
+	if r.RotateSecret {
+		if secret, err = newWebhookSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	err = u.transactor.WithTx(ctx, func(repos *TxRepos) error {
+		stored, err := repos.Webhook.GetOneByUUID(ctx, r.UUID)
+		if err != nil {
+			return u.mapNotFoundError(err)
+		}
+
+		updated := *stored
+		if r.RotateSecret {
+			updated.Secret = secret
+		}
+		updated.URL = r.URL
+		updated.EventTypes = r.EventTypes
+		updated.Active = r.Active
+		updated.UpdatedAt = time.Now().Unix()
+
+		if err = repos.Webhook.Update(ctx, &updated); err != nil {
+			return u.mapNotFoundError(err)
+		}
+		webhook = &updated
+		if r.Audit == nil {
+			return nil
+		}
+		return appendAuditRecord(ctx, repos.AuditLog, newAuditRecord(r.Audit, r.Actor, entities.AuditActionWebhookUpdated, r.UUID,
+			auditWebhookOf(stored, false),
+			auditWebhookOf(&updated, r.RotateSecret),
+		))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// Delete deletes the webhook with its deliveries
+func (u *Webhook) Delete(ctx context.Context, r *WebhookDeleteRequest) error {
+	return u.transactor.WithTx(ctx, func(repos *TxRepos) error {
+		webhook, err := repos.Webhook.GetOneByUUID(ctx, r.UUID)
+		if err != nil {
+			return u.mapNotFoundError(err)
+		}
+
+		if err = repos.WebhookDelivery.DeleteByWebhook(ctx, r.UUID); err != nil {
+			return err
+		}
+		if err = repos.Webhook.Delete(ctx, r.UUID); err != nil {
+			return u.mapNotFoundError(err)
+		}
+		if r.Audit == nil {
+			return nil
+		}
+		return appendAuditRecord(ctx, repos.AuditLog, newAuditRecord(r.Audit, r.Actor, entities.AuditActionWebhookDeleted, r.UUID,
+			auditWebhookOf(webhook, false),
+			nil,
+		))
+	})
+}
+
+// Publish enqueues the event for every active webhook subscribed to its type. A failed Publish is retried
+// by the outbox, the deliveries enqueued before the failure aren't duplicated
+func (u *Webhook) Publish(ctx context.Context, event *entities.DomainEvent) error {
+	webhooks, err := u.repo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	var msg []byte
+	now := time.Now().Unix()
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(event.Type) {
+			continue
+		}
+		if msg == nil {
+			if msg, err = event.Message(); err != nil {
+				return err
+			}
+		}
+
+		err = u.deliveryrepo.Add(ctx, &entities.WebhookDelivery{
+			WebhookUUID:   webhook.UUID,
+			EventUUID:     event.UUID,
+			EventType:     event.Type,
+			Payload:       string(msg),
+			Status:        entities.WebhookDeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+		// the webhook deleted since GetAll doesn't receive the event
+		if err != nil && !errors.As(err, &repositories.ErrWebhookNotFoundByUUID{}) {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeliverNext sends the next due delivery, false is returned if there is nothing to send.
+// A failed attempt is retried with the exponential backoff until the delivery runs out of attempts and is dead.
+// The failures of the webhooks aren't errors, they're recorded in the delivery
+func (u *Webhook) DeliverNext(ctx context.Context) (bool, error) {
+	now := time.Now()
+	delivery, err := u.deliveryrepo.ClaimDue(ctx, now.Unix(), now.Add(webhookDeliveryLease).Unix())
+	if err != nil {
+		return false, err
+	}
+	if delivery == nil {
+		return false, nil
+	}
+
+	var statuscode int
+	webhook, err := u.repo.GetOneByUUID(ctx, delivery.WebhookUUID)
+	switch {
+	case errors.As(err, &repositories.ErrWebhookNotFoundByUUID{}):
+		err = errWebhookIsDeleted
+	case err != nil:
+		return true, err
+	case !webhook.Active:
+		// the delivery can be redelivered once the webhook is active again
+		err = errWebhookIsInactive
+	default:
+		statuscode, err = u.sender.Send(ctx, webhook, delivery)
+	}
+
+	finishedat := time.Now()
+	delivery.Attempts++
+	delivery.LastStatusCode = statuscode
+	delivery.UpdatedAt = finishedat.Unix()
+
+	switch {
+	case err == nil:
+		delivery.Status = entities.WebhookDeliveryDelivered
+		delivery.LastError = ""
+	case errors.Is(err, errWebhookIsDeleted), errors.Is(err, errWebhookIsInactive), delivery.Attempts >= u.maxattempts:
+		delivery.Status = entities.WebhookDeliveryDead
+		delivery.LastError = truncate(err.Error(), deliveryErrorMaxLength)
+	default:
+		delivery.NextAttemptAt = finishedat.Add(u.backoff(delivery.Attempts)).Unix()
+		delivery.LastError = truncate(err.Error(), deliveryErrorMaxLength)
+	}
+
+	return true, u.deliveryrepo.Update(ctx, delivery)
+}
+
+// ListDeliveries returns the delivery log, a missing webhook is reported as ErrWebhookIsNotFound
+func (u *Webhook) ListDeliveries(ctx context.Context, filter *repositories.WebhookDeliveryFilter) ([]*entities.WebhookDelivery, error) {
+	if filter.WebhookUUID != "" {
+		if _, err := u.GetOne(ctx, filter.WebhookUUID); err != nil {
+			return nil, err
+		}
+	}
+
+	search := *filter
+	search.Limit = WebhookDeliveriesLimit(filter.Limit)
+	return u.deliveryrepo.Find(ctx, &search)
+}
+
+// Redeliver schedules the delivery to be sent again now with all the attempts, e.g. a dead one
+// after the partner has fixed the endpoint
+func (u *Webhook) Redeliver(ctx context.Context, r *WebhookRedeliverRequest) (*entities.WebhookDelivery, error) {
+	var delivery *entities.WebhookDelivery
+	err := u.transactor.WithTx(ctx, func(repos *TxRepos) error {
+		stored, err := repos.WebhookDelivery.GetOneByID(ctx, r.ID)
+		if err != nil {
+			return u.mapDeliveryNotFoundError(err)
+		}
+
+		now := time.Now().Unix()
+		updated := *stored
+		updated.Status = entities.WebhookDeliveryPending
+		updated.Attempts = 0
+		updated.NextAttemptAt = now
+		updated.UpdatedAt = now
+
+		if err = repos.WebhookDelivery.Update(ctx, &updated); err != nil {
+			return u.mapDeliveryNotFoundError(err)
+		}
+		delivery = &updated
+		if r.Audit == nil {
+			return nil
+		}
+		return appendAuditRecord(ctx, repos.AuditLog, newAuditRecord(r.Audit, r.Actor, entities.AuditActionWebhookRedelivered, stored.WebhookUUID,
+			auditDeliveryOf(stored),
+			auditDeliveryOf(&updated),
+		))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// DeleteFinished deletes the deliveries finished longer than the retention ago
+func (u *Webhook) DeleteFinished(ctx context.Context) (int, error) {
+	return u.deliveryrepo.DeleteFinishedBefore(ctx, time.Now().Add(-u.retention).Unix())
+}
+
+// backoff is the delay after the failed attempt
+func (u *Webhook) backoff(attempt int) time.Duration {
+	delay := u.backoffbase
+	for i := 1; i < attempt && delay < u.backoffmax; i++ {
+		delay *= 2
+	}
+	return min(delay, u.backoffmax)
+}
+
+func (u *Webhook) mapNotFoundError(err error) error {
+	if errors.As(err, &repositories.ErrWebhookNotFoundByUUID{}) {
+		return ErrWebhookIsNotFound
+	}
+	return err
+}
+
+func (u *Webhook) mapDeliveryNotFoundError(err error) error {
+	if errors.As(err, &repositories.ErrWebhookDeliveryNotFoundByID{}) {
+		return ErrWebhookDeliveryIsNotFound
+	}
+	return err
+}
+
+// auditWebhook is the audited state of the webhook, the secret is never recorded but its rotation
+type auditWebhook struct {
+	URL           string
+	EventTypes    []string
+	Active        bool
+	SecretRotated bool `json:",omitempty"`
+}
+
+func auditWebhookOf(webhook *entities.Webhook, secretrotated bool) auditWebhook {
+	return auditWebhook{
+		URL:           webhook.URL,
+		EventTypes:    webhook.EventTypes,
+		Active:        webhook.Active,
+		SecretRotated: secretrotated,
+	}
+}
+
+// auditDelivery is the audited state of the webhook delivery, the payload is the event and isn't recorded
+type auditDelivery struct {
+	ID        int64
+	EventUUID string
+	Status    uint8
+	Attempts  int
+}
+
+func auditDeliveryOf(delivery *entities.WebhookDelivery) auditDelivery {
+	return auditDelivery{
+		ID:        delivery.ID,
+		EventUUID: delivery.EventUUID,
+		Status:    delivery.Status,
+		Attempts:  delivery.Attempts,
+	}
+}
+
+// WebhookDeliveriesLimit is the page size for the limit, DefaultWebhookDeliveriesLimit if limit isn't positive
+// and MaxWebhookDeliveriesLimit at most
+func WebhookDeliveriesLimit(limit int) int {
+	switch {
+	case limit <= 0:
+		return DefaultWebhookDeliveriesLimit
+	case limit > MaxWebhookDeliveriesLimit:
+		return MaxWebhookDeliveriesLimit
+	default:
+		return limit
+	}
+}
+
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, webhookSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
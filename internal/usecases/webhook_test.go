@@ -0,0 +1,565 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	usecases_test "github.com/alexsibrin/runbot-auth/internal/usecases/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestWebhookInit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repomock := usecases_test.NewMockIWebhookRepo(ctrl)
+	deliverymock := usecases_test.NewMockIWebhookDeliveryRepo(ctrl)
+	sendermock := usecases_test.NewMockIWebhookSender(ctrl)
+	txstub := &txStub{}
+
+	testCases := []struct {
+		name        string
+		in          *WebhookDependencies
+		out         *Webhook
+		expectedErr error
+	}{
+		{
+			name: "Regular valid case",
+			in:   &WebhookDependencies{Repo: repomock, DeliveryRepo: deliverymock, Sender: sendermock, Transactor: txstub},
+			out: &Webhook{
+				repo:         repomock,
+				deliveryrepo: deliverymock,
+				sender:       sendermock,
+				transactor:   txstub,
+				maxattempts:  DefaultWebhookMaxAttempts,
+				backoffbase:  DefaultWebhookBackoffBase,
+				backoffmax:   DefaultWebhookBackoffMax,
+				retention:    DefaultWebhookRetention,
+			},
+		},
+		{
+			name: "Configured case",
+			in: &WebhookDependencies{Repo: repomock, DeliveryRepo: deliverymock, Sender: sendermock, Transactor: txstub, Config: &WebhookConfig{
+				MaxAttempts: 3,
+				BackoffBase: time.Second,
+				BackoffMax:  time.Minute,
+				Retention:   time.Hour,
+			}},
+			out: &Webhook{
+				repo:         repomock,
+				deliveryrepo: deliverymock,
+				sender:       sendermock,
+				transactor:   txstub,
+				maxattempts:  3,
+				backoffbase:  time.Second,
+				backoffmax:   time.Minute,
+				retention:    time.Hour,
+			},
+		},
+		{
+			name:        "Dependencies are nil",
+			in:          nil,
+			expectedErr: ErrDependenciesAreNil,
+		},
+		{
+			name:        "Repo is nil",
+			in:          &WebhookDependencies{DeliveryRepo: deliverymock, Sender: sendermock, Transactor: txstub},
+			expectedErr: ErrWebhookRepoIsNil,
+		},
+		{
+			name:        "Delivery repo is nil",
+			in:          &WebhookDependencies{Repo: repomock, Sender: sendermock, Transactor: txstub},
+			expectedErr: ErrWebhookDeliveryRepoIsNil,
+		},
+		{
+			name:        "Sender is nil",
+			in:          &WebhookDependencies{Repo: repomock, DeliveryRepo: deliverymock, Transactor: txstub},
+			expectedErr: ErrWebhookSenderIsNil,
+		},
+		{
+			name:        "Transactor is nil",
+			in:          &WebhookDependencies{Repo: repomock, DeliveryRepo: deliverymock, Sender: sendermock},
+			expectedErr: ErrTransactorIsNil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			uc, err := NewWebhook(tc.in)
+			assert.Equal(t, tc.out, uc)
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestWebhook_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repomock := usecases_test.NewMockIWebhookRepo(ctrl)
+	auditmock := usecases_test.NewMockIAuditLogRepo(ctrl)
+	uc := &Webhook{transactor: &txStub{repos: &TxRepos{Webhook: repomock, AuditLog: auditmock}}}
+	ctx := context.TODO()
+
+	var created *entities.Webhook
+	repomock.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, webhook *entities.Webhook) error {
+		created = webhook
+		return nil
+	})
+	auditmock.EXPECT().Last(ctx).Return(nil, nil)
+	auditmock.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, record *entities.AuditRecord) (*entities.AuditRecord, error) {
+		assert.Equal(t, "alice", record.Actor)
+		assert.Equal(t, entities.AuditActionWebhookCreated, record.Action)
+		assert.Equal(t, created.UUID, record.TargetUUID)
+		assert.Empty(t, record.Before)
+		assert.Equal(t, `{"URL":"https://partner.example.com/hooks","EventTypes":["account.created"],"Active":true}`, record.After)
+		assert.Equal(t, "/runbotauth.Webhooks/Create", record.Method)
+		assert.Empty(t, record.PrevHash)
+		assert.Equal(t, record.ComputeHash(), record.Hash)
+		return record, nil
+	})
+
+	webhook, err := uc.Create(ctx, &WebhookCreateRequest{
+		URL:        "https://partner.example.com/hooks",
+		EventTypes: []string{entities.DomainEventAccountCreated},
+		Actor:      "alice",
+		Audit:      &AuditMetadata{Method: "/runbotauth.Webhooks/Create"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, created, webhook)
+	assert.NotEmpty(t, webhook.UUID)
+	assert.Len(t, webhook.Secret, 2*webhookSecretLength)
+	assert.True(t, webhook.Active)
+	assert.Equal(t, []string{entities.DomainEventAccountCreated}, webhook.EventTypes)
+
+	repomock.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+	another, err := uc.Create(ctx, &WebhookCreateRequest{URL: "https://partner.example.com/hooks"})
+	require.NoError(t, err)
+	assert.NotEqual(t, webhook.Secret, another.Secret)
+}
+
+func TestWebhook_Update(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repomock := usecases_test.NewMockIWebhookRepo(ctrl)
+	auditmock := usecases_test.NewMockIAuditLogRepo(ctrl)
+	uc := &Webhook{transactor: &txStub{repos: &TxRepos{Webhook: repomock, AuditLog: auditmock}}}
+	ctx := context.TODO()
+
+	stored := func() *entities.Webhook {
+		return &entities.Webhook{UUID: "webhook-uuid", URL: "https://old.example.com", Secret: "secret", Active: true, CreatedAt: 1}
+	}
+
+	testCases := []struct {
+		name          string
+		in            *WebhookUpdateRequest
+		setupMocks    func()
+		rotatedSecret bool
+		expectedErr   error
+	}{
+		{
+			name: "Valid case",
+			in: &WebhookUpdateRequest{
+				UUID:       "webhook-uuid",
+				URL:        "https://new.example.com",
+				EventTypes: []string{entities.DomainEventAccountDeleted},
+			},
+			setupMocks: func() {
+				repomock.EXPECT().GetOneByUUID(ctx, "webhook-uuid").Return(stored(), nil)
+				repomock.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "Secret is rotated",
+			in: &WebhookUpdateRequest{
+				UUID:         "webhook-uuid",
+				URL:          "https://new.example.com",
+				Active:       true,
+				RotateSecret: true,
+				Actor:        "alice",
+				Audit:        &AuditMetadata{Method: "/runbotauth.Webhooks/Update"},
+			},
+			setupMocks: func() {
+				repomock.EXPECT().GetOneByUUID(ctx, "webhook-uuid").Return(stored(), nil)
+				repomock.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+				auditmock.EXPECT().Last(ctx).Return(&entities.AuditRecord{ID: 1, Hash: "prevhash"}, nil)
+				auditmock.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, record *entities.AuditRecord) (*entities.AuditRecord, error) {
+					assert.Equal(t, "alice", record.Actor)
+					assert.Equal(t, entities.AuditActionWebhookUpdated, record.Action)
+					assert.Equal(t, "webhook-uuid", record.TargetUUID)
+					assert.Equal(t, `{"URL":"https://old.example.com","EventTypes":null,"Active":true}`, record.Before)
+					assert.Equal(t, `{"URL":"https://new.example.com","EventTypes":null,"Active":true,"SecretRotated":true}`, record.After)
+					assert.NotContains(t, record.Before+record.After, "secret\"")
+					assert.Equal(t, "prevhash", record.PrevHash)
+					return record, nil
+				})
+			},
+			rotatedSecret: true,
+		},
+		{
+			name: "Webhook is not found",
+			in:   &WebhookUpdateRequest{UUID: "webhook-uuid"},
+			setupMocks: func() {
+				repomock.EXPECT().GetOneByUUID(ctx, "webhook-uuid").Return(nil, repositories.NewErrWebhookNotFoundByUUID("webhook-uuid"))
+			},
+			expectedErr: ErrWebhookIsNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			webhook, err := uc.Update(ctx, tc.in)
+			assert.ErrorIs(t, err, tc.expectedErr)
+			if tc.expectedErr != nil {
+				return
+			}
+			assert.Equal(t, tc.in.URL, webhook.URL)
+			assert.Equal(t, tc.in.EventTypes, webhook.EventTypes)
+			assert.Equal(t, tc.in.Active, webhook.Active)
+			assert.Equal(t, int64(1), webhook.CreatedAt)
+			assert.Equal(t, tc.rotatedSecret, webhook.Secret != "secret")
+		})
+	}
+}
+
+func TestWebhook_Delete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repomock := usecases_test.NewMockIWebhookRepo(ctrl)
+	deliverymock := usecases_test.NewMockIWebhookDeliveryRepo(ctrl)
+	auditmock := usecases_test.NewMockIAuditLogRepo(ctrl)
+	uc := &Webhook{transactor: &txStub{repos: &TxRepos{Webhook: repomock, WebhookDelivery: deliverymock, AuditLog: auditmock}}}
+	ctx := context.TODO()
+
+	gomock.InOrder(
+		repomock.EXPECT().GetOneByUUID(ctx, "webhook-uuid").Return(&entities.Webhook{UUID: "webhook-uuid", URL: "https://partner.example.com/hooks", Active: true}, nil),
+		deliverymock.EXPECT().DeleteByWebhook(ctx, "webhook-uuid").Return(nil),
+		repomock.EXPECT().Delete(ctx, "webhook-uuid").Return(nil),
+		auditmock.EXPECT().Last(ctx).Return(nil, nil),
+		auditmock.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, record *entities.AuditRecord) (*entities.AuditRecord, error) {
+			assert.Equal(t, "alice", record.Actor)
+			assert.Equal(t, entities.AuditActionWebhookDeleted, record.Action)
+			assert.Equal(t, "webhook-uuid", record.TargetUUID)
+			assert.Equal(t, `{"URL":"https://partner.example.com/hooks","EventTypes":null,"Active":true}`, record.Before)
+			assert.Empty(t, record.After)
+			return record, nil
+		}),
+	)
+	assert.NoError(t, uc.Delete(ctx, &WebhookDeleteRequest{UUID: "webhook-uuid", Actor: "alice", Audit: &AuditMetadata{}}))
+
+	repomock.EXPECT().GetOneByUUID(ctx, "missing-uuid").Return(nil, repositories.NewErrWebhookNotFoundByUUID("missing-uuid"))
+	assert.ErrorIs(t, uc.Delete(ctx, &WebhookDeleteRequest{UUID: "missing-uuid", Actor: "alice", Audit: &AuditMetadata{}}), ErrWebhookIsNotFound)
+}
+
+func TestWebhook_Publish(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repomock := usecases_test.NewMockIWebhookRepo(ctrl)
+	deliverymock := usecases_test.NewMockIWebhookDeliveryRepo(ctrl)
+	uc := &Webhook{repo: repomock, deliveryrepo: deliverymock}
+	ctx := context.TODO()
+
+	event := &entities.DomainEvent{
+		ID:          1,
+		UUID:        "event-uuid",
+		Type:        entities.DomainEventAccountCreated,
+		AccountUUID: "account-uuid",
+		Payload:     `{"Email":"bob@example.com"}`,
+		CreatedAt:   1700000000,
+	}
+	msg, err := event.Message()
+	require.NoError(t, err)
+
+	webhooks := []*entities.Webhook{
+		{UUID: "all", Active: true},
+		{UUID: "created", Active: true, EventTypes: []string{entities.DomainEventAccountCreated}},
+		{UUID: "deleted", Active: true, EventTypes: []string{entities.DomainEventAccountDeleted}},
+		{UUID: "inactive", Active: false},
+	}
+
+	testCases := []struct {
+		name        string
+		setupMocks  func()
+		expectedErr error
+	}{
+		{
+			name: "Valid case",
+			setupMocks: func() {
+				repomock.EXPECT().GetAll(ctx).Return(webhooks, nil)
+				for _, webhookuuid := range []string{"all", "created"} {
+					webhookuuid := webhookuuid
+					deliverymock.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, delivery *entities.WebhookDelivery) error {
+						assert.Equal(t, webhookuuid, delivery.WebhookUUID)
+						assert.Equal(t, "event-uuid", delivery.EventUUID)
+						assert.Equal(t, entities.DomainEventAccountCreated, delivery.EventType)
+						assert.JSONEq(t, string(msg), delivery.Payload)
+						assert.Equal(t, entities.WebhookDeliveryPending, delivery.Status)
+						assert.NotZero(t, delivery.NextAttemptAt)
+						return nil
+					})
+				}
+			},
+		},
+		{
+			name: "Webhook is deleted meanwhile",
+			setupMocks: func() {
+				repomock.EXPECT().GetAll(ctx).Return(webhooks[:2], nil)
+				deliverymock.EXPECT().Add(ctx, gomock.Any()).Return(repositories.NewErrWebhookNotFoundByUUID("all"))
+				deliverymock.EXPECT().Add(ctx, gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "No webhooks",
+			setupMocks: func() {
+				repomock.EXPECT().GetAll(ctx).Return(nil, nil)
+			},
+		},
+		{
+			name: "Delivery isn't added",
+			setupMocks: func() {
+				repomock.EXPECT().GetAll(ctx).Return(webhooks, nil)
+				deliverymock.EXPECT().Add(ctx, gomock.Any()).Return(errors.New("unexpected"))
+			},
+			expectedErr: errors.New("unexpected"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			err := uc.Publish(ctx, event)
+			assert.Equal(t, tc.expectedErr, err)
+		})
+	}
+}
+
+func TestWebhook_DeliverNext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repomock := usecases_test.NewMockIWebhookRepo(ctrl)
+	deliverymock := usecases_test.NewMockIWebhookDeliveryRepo(ctrl)
+	sendermock := usecases_test.NewMockIWebhookSender(ctrl)
+	uc := &Webhook{
+		repo:         repomock,
+		deliveryrepo: deliverymock,
+		sender:       sendermock,
+		maxattempts:  3,
+		backoffbase:  time.Minute,
+		backoffmax:   time.Hour,
+	}
+	ctx := context.TODO()
+
+	webhook := &entities.Webhook{UUID: "webhook-uuid", URL: "https://partner.example.com/hooks", Secret: "secret", Active: true}
+	claimed := func(attempts int) *entities.WebhookDelivery {
+		return &entities.WebhookDelivery{
+			ID:          7,
+			WebhookUUID: "webhook-uuid",
+			EventUUID:   "event-uuid",
+			Status:      entities.WebhookDeliveryPending,
+			Attempts:    attempts,
+			LastError:   "previous error",
+		}
+	}
+
+	testCases := []struct {
+		name             string
+		setupMocks       func()
+		expectedOk       bool
+		expectedStatus   uint8
+		expectedAttempts int
+		expectedCode     int
+		expectedLastErr  string
+		expectedBackoff  time.Duration
+		expectedErr      error
+	}{
+		{
+			name: "Delivered",
+			setupMocks: func() {
+				deliverymock.EXPECT().ClaimDue(ctx, gomock.Any(), gomock.Any()).Return(claimed(0), nil)
+				repomock.EXPECT().GetOneByUUID(ctx, "webhook-uuid").Return(webhook, nil)
+				sendermock.EXPECT().Send(ctx, webhook, claimed(0)).Return(204, nil)
+			},
+			expectedOk:       true,
+			expectedStatus:   entities.WebhookDeliveryDelivered,
+			expectedAttempts: 1,
+			expectedCode:     204,
+		},
+		{
+			name: "Failed attempt is retried later",
+			setupMocks: func() {
+				deliverymock.EXPECT().ClaimDue(ctx, gomock.Any(), gomock.Any()).Return(claimed(1), nil)
+				repomock.EXPECT().GetOneByUUID(ctx, "webhook-uuid").Return(webhook, nil)
+				sendermock.EXPECT().Send(ctx, webhook, gomock.Any()).Return(503, errors.New("503 Service Unavailable"))
+			},
+			expectedOk:       true,
+			expectedStatus:   entities.WebhookDeliveryPending,
+			expectedAttempts: 2,
+			expectedCode:     503,
+			expectedLastErr:  "503 Service Unavailable",
+			expectedBackoff:  2 * time.Minute,
+		},
+		{
+			name: "Last attempt makes the delivery dead",
+			setupMocks: func() {
+				deliverymock.EXPECT().ClaimDue(ctx, gomock.Any(), gomock.Any()).Return(claimed(2), nil)
+				repomock.EXPECT().GetOneByUUID(ctx, "webhook-uuid").Return(webhook, nil)
+				sendermock.EXPECT().Send(ctx, webhook, gomock.Any()).Return(0, errors.New("connection refused"))
+			},
+			expectedOk:       true,
+			expectedStatus:   entities.WebhookDeliveryDead,
+			expectedAttempts: 3,
+			expectedLastErr:  "connection refused",
+		},
+		{
+			name: "Webhook is inactive",
+			setupMocks: func() {
+				deliverymock.EXPECT().ClaimDue(ctx, gomock.Any(), gomock.Any()).Return(claimed(0), nil)
+				repomock.EXPECT().GetOneByUUID(ctx, "webhook-uuid").Return(&entities.Webhook{UUID: "webhook-uuid"}, nil)
+			},
+			expectedOk:       true,
+			expectedStatus:   entities.WebhookDeliveryDead,
+			expectedAttempts: 1,
+			expectedLastErr:  errWebhookIsInactive.Error(),
+		},
+		{
+			name: "Webhook is deleted",
+			setupMocks: func() {
+				deliverymock.EXPECT().ClaimDue(ctx, gomock.Any(), gomock.Any()).Return(claimed(0), nil)
+				repomock.EXPECT().GetOneByUUID(ctx, "webhook-uuid").Return(nil, repositories.NewErrWebhookNotFoundByUUID("webhook-uuid"))
+			},
+			expectedOk:       true,
+			expectedStatus:   entities.WebhookDeliveryDead,
+			expectedAttempts: 1,
+			expectedLastErr:  errWebhookIsDeleted.Error(),
+		},
+		{
+			name: "Nothing to deliver",
+			setupMocks: func() {
+				deliverymock.EXPECT().ClaimDue(ctx, gomock.Any(), gomock.Any()).Return(nil, nil)
+			},
+		},
+		{
+			name: "Claim failed",
+			setupMocks: func() {
+				deliverymock.EXPECT().ClaimDue(ctx, gomock.Any(), gomock.Any()).Return(nil, errors.New("unexpected"))
+			},
+			expectedErr: errors.New("unexpected"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			now := time.Now().Unix()
+			var updated *entities.WebhookDelivery
+			if tc.expectedOk {
+				deliverymock.EXPECT().Update(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, delivery *entities.WebhookDelivery) error {
+					updated = delivery
+					return nil
+				})
+			}
+
+			ok, err := uc.DeliverNext(ctx)
+			assert.Equal(t, tc.expectedOk, ok)
+			assert.Equal(t, tc.expectedErr, err)
+			if !tc.expectedOk {
+				return
+			}
+			assert.Equal(t, tc.expectedStatus, updated.Status)
+			assert.Equal(t, tc.expectedAttempts, updated.Attempts)
+			assert.Equal(t, tc.expectedCode, updated.LastStatusCode)
+			assert.Equal(t, tc.expectedLastErr, updated.LastError)
+			if tc.expectedBackoff > 0 {
+				assert.InDelta(t, now+int64(tc.expectedBackoff.Seconds()), updated.NextAttemptAt, 1)
+			}
+		})
+	}
+}
+
+func TestWebhook_backoff(t *testing.T) {
+	uc := &Webhook{backoffbase: 30 * time.Second, backoffmax: 10 * time.Minute}
+
+	assert.Equal(t, 30*time.Second, uc.backoff(1))
+	assert.Equal(t, time.Minute, uc.backoff(2))
+	assert.Equal(t, 4*time.Minute, uc.backoff(4))
+	assert.Equal(t, 8*time.Minute, uc.backoff(5))
+	assert.Equal(t, 10*time.Minute, uc.backoff(6))
+	assert.Equal(t, 10*time.Minute, uc.backoff(100))
+}
+
+func TestWebhook_ListDeliveries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repomock := usecases_test.NewMockIWebhookRepo(ctrl)
+	deliverymock := usecases_test.NewMockIWebhookDeliveryRepo(ctrl)
+	uc := &Webhook{repo: repomock, deliveryrepo: deliverymock}
+	ctx := context.TODO()
+
+	deliveries := []*entities.WebhookDelivery{{ID: 2}, {ID: 1}}
+
+	repomock.EXPECT().GetOneByUUID(ctx, "webhook-uuid").Return(&entities.Webhook{UUID: "webhook-uuid"}, nil)
+	deliverymock.EXPECT().Find(ctx, &repositories.WebhookDeliveryFilter{
+		WebhookUUID: "webhook-uuid",
+		Statuses:    []uint8{entities.WebhookDeliveryDead},
+		Limit:       MaxWebhookDeliveriesLimit,
+	}).Return(deliveries, nil)
+
+	result, err := uc.ListDeliveries(ctx, &repositories.WebhookDeliveryFilter{
+		WebhookUUID: "webhook-uuid",
+		Statuses:    []uint8{entities.WebhookDeliveryDead},
+		Limit:       10000,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, deliveries, result)
+
+	repomock.EXPECT().GetOneByUUID(ctx, "missing-uuid").Return(nil, repositories.NewErrWebhookNotFoundByUUID("missing-uuid"))
+	_, err = uc.ListDeliveries(ctx, &repositories.WebhookDeliveryFilter{WebhookUUID: "missing-uuid"})
+	assert.ErrorIs(t, err, ErrWebhookIsNotFound)
+}
+
+func TestWebhook_Redeliver(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	deliverymock := usecases_test.NewMockIWebhookDeliveryRepo(ctrl)
+	auditmock := usecases_test.NewMockIAuditLogRepo(ctrl)
+	uc := &Webhook{transactor: &txStub{repos: &TxRepos{WebhookDelivery: deliverymock, AuditLog: auditmock}}}
+	ctx := context.TODO()
+
+	dead := &entities.WebhookDelivery{ID: 7, WebhookUUID: "webhook-uuid", EventUUID: "event-uuid", Status: entities.WebhookDeliveryDead, Attempts: 10, LastError: "timeout"}
+	deliverymock.EXPECT().GetOneByID(ctx, int64(7)).Return(dead, nil)
+	deliverymock.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+	auditmock.EXPECT().Last(ctx).Return(nil, nil)
+	auditmock.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, record *entities.AuditRecord) (*entities.AuditRecord, error) {
+		assert.Equal(t, "alice", record.Actor)
+		assert.Equal(t, entities.AuditActionWebhookRedelivered, record.Action)
+		assert.Equal(t, "webhook-uuid", record.TargetUUID)
+		assert.Equal(t, fmt.Sprintf(`{"ID":7,"EventUUID":"event-uuid","Status":%d,"Attempts":10}`, entities.WebhookDeliveryDead), record.Before)
+		assert.Equal(t, fmt.Sprintf(`{"ID":7,"EventUUID":"event-uuid","Status":%d,"Attempts":0}`, entities.WebhookDeliveryPending), record.After)
+		return record, nil
+	})
+
+	delivery, err := uc.Redeliver(ctx, &WebhookRedeliverRequest{ID: 7, Actor: "alice", Audit: &AuditMetadata{}})
+	require.NoError(t, err)
+	assert.Equal(t, entities.WebhookDeliveryPending, delivery.Status)
+	assert.Zero(t, delivery.Attempts)
+	assert.NotZero(t, delivery.NextAttemptAt)
+	assert.Equal(t, "timeout", delivery.LastError)
+
+	deliverymock.EXPECT().GetOneByID(ctx, int64(8)).Return(nil, repositories.NewErrWebhookDeliveryNotFoundByID(8))
+	_, err = uc.Redeliver(ctx, &WebhookRedeliverRequest{ID: 8})
+	assert.ErrorIs(t, err, ErrWebhookDeliveryIsNotFound)
+}
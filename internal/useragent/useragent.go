@@ -0,0 +1,75 @@
+// Package useragent recognizes the browser and the operating system of a User-Agent header,
+// it's enough to name a device in the session list and doesn't aim to be exhaustive
+package useragent
+
+import (
+	"strings"
+)
+
+const (
+	Unknown = "Unknown"
+)
+
+// Device is the client recognized by its User-Agent, the unrecognized parts are Unknown
+type Device struct {
+	Browser string
+	OS      string
+}
+
+// the more specific tokens go first, e.g. Edge and Opera user agents contain Chrome and Safari as well
+var (
+	browsers = []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"YaBrowser/", "Yandex Browser"},
+		{"SamsungBrowser/", "Samsung Internet"},
+		{"Firefox/", "Firefox"},
+		{"FxiOS/", "Firefox"},
+		{"CriOS/", "Chrome"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+		{"okhttp/", "OkHttp"},
+		{"grpc-", "gRPC"},
+	}
+	systems = []struct{ token, name string }{
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Android", "Android"},
+		{"CrOS", "ChromeOS"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"Macintosh", "macOS"},
+		{"Linux", "Linux"},
+	}
+)
+
+func Parse(ua string) Device {
+	return Device{
+		Browser: match(ua, browsers),
+		OS:      match(ua, systems),
+	}
+}
+
+// Name is a human readable name of the device, e.g. "Chrome on Windows"
+func (d Device) Name() string {
+	switch {
+	case d.Browser == Unknown && d.OS == Unknown:
+		return Unknown
+	case d.OS == Unknown:
+		return d.Browser
+	case d.Browser == Unknown:
+		return d.OS
+	default:
+		return d.Browser + " on " + d.OS
+	}
+}
+
+func match(ua string, known []struct{ token, name string }) string {
+	for _, k := range known {
+		if strings.Contains(ua, k.token) {
+			return k.name
+		}
+	}
+	return Unknown
+}
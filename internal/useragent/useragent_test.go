@@ -0,0 +1,56 @@
+package useragent
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name         string
+		ua           string
+		expectedName string
+	}{
+		{
+			name:         "Chrome on Windows",
+			ua:           "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			expectedName: "Chrome on Windows",
+		},
+		{
+			name:         "Edge on Windows",
+			ua:           "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0",
+			expectedName: "Edge on Windows",
+		},
+		{
+			name:         "Safari on iOS",
+			ua:           "Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1",
+			expectedName: "Safari on iOS",
+		},
+		{
+			name:         "Firefox on Linux",
+			ua:           "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+			expectedName: "Firefox on Linux",
+		},
+		{
+			name:         "Chrome on Android",
+			ua:           "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36",
+			expectedName: "Chrome on Android",
+		},
+		{
+			name:         "Client without OS",
+			ua:           "curl/8.4.0",
+			expectedName: "curl",
+		},
+		{
+			name:         "Empty",
+			ua:           "",
+			expectedName: Unknown,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedName, Parse(tc.ua).Name())
+		})
+	}
+}
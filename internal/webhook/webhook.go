@@ -0,0 +1,144 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultTimeout = 10 * time.Second
+
+	HeaderEvent     = "X-Runbot-Event"
+	HeaderEventID   = "X-Runbot-Event-Id"
+	HeaderDelivery  = "X-Runbot-Delivery"
+	HeaderTimestamp = "X-Runbot-Timestamp"
+	HeaderSignature = "X-Runbot-Signature"
+
+	signaturePrefix = "sha256="
+	userAgent       = "runbot-auth-webhook/1.0"
+
+	// responseMaxLength bounds the response quoted in an error
+	responseMaxLength = 256
+)
+
+var (
+	ErrConfigIsNil         = errors.New("config is nil")
+	ErrWebhookIsRejected   = errors.New("webhook rejected the delivery")
+	ErrSignatureIsNotValid = errors.New("webhook signature is not valid")
+)
+
+type Config struct {
+	// Timeout bounds a delivery request, DefaultTimeout if it isn't positive
+	Timeout time.Duration
+}
+
+// Sender posts the deliveries to the webhooks as JSON signed with the webhook secret.
+// Only a 2xx response is a delivery, the redirects aren't followed
+type Sender struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewSender(c *Config) (*Sender, error) {
+	if c == nil {
+		return nil, ErrConfigIsNil
+	}
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Sender{
+		client: &http.Client{
+			Timeout: timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		now: time.Now,
+	}, nil
+}
+
+// Send returns the status code of the response, it's zero if there is no response
+func (s *Sender) Send(ctx context.Context, webhook *entities.Webhook, delivery *entities.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := s.now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderEventID, delivery.EventUUID)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// the body is drained, so the connection is reused
+	respbody, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode/100 != 2 {
+		return resp.StatusCode, fmt.Errorf("%w: %s: %s", ErrWebhookIsRejected, resp.Status, truncate(string(respbody), responseMaxLength))
+	}
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the signature header of the body sent at timestamp: sha256= followed by the hex
+// of HMAC-SHA256 of "<timestamp>.<body>" keyed with the secret. The timestamp is signed,
+// so a receiver can reject the replays of the old deliveries
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature of a received delivery and that it's sent within tolerance of now,
+// it's what a receiver does. Zero tolerance doesn't check the time
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration, now time.Time) error {
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: timestamp is not valid", ErrSignatureIsNotValid)
+	}
+	if tolerance > 0 {
+		sentat := time.Unix(timestamp, 0)
+		if sentat.Before(now.Add(-tolerance)) || sentat.After(now.Add(tolerance)) {
+			return fmt.Errorf("%w: timestamp is out of tolerance", ErrSignatureIsNotValid)
+		}
+	}
+
+	signature := header.Get(HeaderSignature)
+	if !strings.HasPrefix(signature, signaturePrefix) || !hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body))) {
+		return ErrSignatureIsNotValid
+	}
+	return nil
+}
+
+// truncate shortens the response for an error
+func truncate(s string, length int) string {
+	if len(s) <= length {
+		return s
+	}
+	return s[:length]
+}
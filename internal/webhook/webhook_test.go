@@ -0,0 +1,231 @@
+package webhook
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/entities"
+	"github.com/alexsibrin/runbot-auth/internal/repositories"
+	"github.com/alexsibrin/runbot-auth/internal/repositories/dbmemory"
+	"github.com/alexsibrin/runbot-auth/internal/usecases"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testReceiver is a partner endpoint which checks the signatures, status is its response status
+type testReceiver struct {
+	*httptest.Server
+	secret string
+
+	mu       sync.Mutex
+	status   int
+	received []*http.Request
+	bodies   []string
+	errs     []error
+}
+
+func newTestReceiver(t *testing.T, secret string) *testReceiver {
+	r := &testReceiver{secret: secret, status: http.StatusNoContent}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.received = append(r.received, req)
+		r.bodies = append(r.bodies, string(body))
+		r.errs = append(r.errs, Verify(r.secret, req.Header, body, 5*time.Minute, time.Now()))
+
+		w.WriteHeader(r.status)
+		_, _ = w.Write([]byte("receiver says hi"))
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *testReceiver) setStatus(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{"ok":true}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t,
+		"sha256=c1afc7c2df3db0690d7d75954610ed1a1d959ce96355ccb8c0a8bc09fd0cfc27",
+		Sign("secret", 1700000000, []byte(`{"ok":true}`)))
+	assert.NotEqual(t, Sign("secret", 1700000000, []byte("{}")), Sign("secret", 1700000001, []byte("{}")))
+	assert.NotEqual(t, Sign("secret", 1700000000, []byte("{}")), Sign("another", 1700000000, []byte("{}")))
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"ok":true}`)
+	header := func(timestamp int64, signature string) http.Header {
+		h := http.Header{}
+		h.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+		h.Set(HeaderSignature, signature)
+		return h
+	}
+
+	assert.NoError(t, Verify("secret", header(now.Unix(), Sign("secret", now.Unix(), body)), body, time.Minute, now))
+	assert.ErrorIs(t, Verify("wrong", header(now.Unix(), Sign("secret", now.Unix(), body)), body, time.Minute, now), ErrSignatureIsNotValid)
+	assert.ErrorIs(t, Verify("secret", header(now.Unix(), Sign("secret", now.Unix(), body)), []byte("{}"), time.Minute, now), ErrSignatureIsNotValid)
+	assert.ErrorIs(t, Verify("secret", header(now.Unix(), Sign("secret", now.Unix()+1, body)), body, time.Minute, now), ErrSignatureIsNotValid)
+
+	old := now.Add(-time.Hour).Unix()
+	assert.ErrorIs(t, Verify("secret", header(old, Sign("secret", old, body)), body, time.Minute, now), ErrSignatureIsNotValid)
+	assert.NoError(t, Verify("secret", header(old, Sign("secret", old, body)), body, 0, now))
+
+	assert.ErrorIs(t, Verify("secret", http.Header{}, body, 0, now), ErrSignatureIsNotValid)
+}
+
+func TestSender_Send(t *testing.T) {
+	ctx := context.Background()
+	sender, err := NewSender(&Config{})
+	require.NoError(t, err)
+
+	delivery := &entities.WebhookDelivery{
+		ID:        7,
+		EventUUID: "event-uuid",
+		EventType: entities.DomainEventAccountCreated,
+		Payload:   `{"ID":"event-uuid","Type":"account.created"}`,
+	}
+
+	t.Run("Delivered", func(t *testing.T) {
+		receiver := newTestReceiver(t, "secret")
+
+		code, err := sender.Send(ctx, &entities.Webhook{URL: receiver.URL, Secret: "secret"}, delivery)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, code)
+
+		require.Len(t, receiver.received, 1)
+		req := receiver.received[0]
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		assert.Equal(t, entities.DomainEventAccountCreated, req.Header.Get(HeaderEvent))
+		assert.Equal(t, "event-uuid", req.Header.Get(HeaderEventID))
+		assert.Equal(t, "7", req.Header.Get(HeaderDelivery))
+		assert.Equal(t, delivery.Payload, receiver.bodies[0])
+		assert.NoError(t, receiver.errs[0])
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		receiver := newTestReceiver(t, "secret")
+		receiver.setStatus(http.StatusServiceUnavailable)
+
+		code, err := sender.Send(ctx, &entities.Webhook{URL: receiver.URL, Secret: "secret"}, delivery)
+		assert.ErrorIs(t, err, ErrWebhookIsRejected)
+		assert.ErrorContains(t, err, "receiver says hi")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+	})
+
+	t.Run("Redirect isn't followed", func(t *testing.T) {
+		target := newTestReceiver(t, "secret")
+		redirect := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+		defer redirect.Close()
+
+		code, err := sender.Send(ctx, &entities.Webhook{URL: redirect.URL, Secret: "secret"}, delivery)
+		assert.ErrorIs(t, err, ErrWebhookIsRejected)
+		assert.Equal(t, http.StatusTemporaryRedirect, code)
+		assert.Empty(t, target.received)
+	})
+
+	t.Run("Receiver is unavailable", func(t *testing.T) {
+		receiver := newTestReceiver(t, "secret")
+		receiver.Close()
+
+		code, err := sender.Send(ctx, &entities.Webhook{URL: receiver.URL, Secret: "secret"}, delivery)
+		assert.Error(t, err)
+		assert.Zero(t, code)
+	})
+}
+
+// TestDelivery runs the webhook usecase with the sender against a receiver: an event is delivered
+// to the subscribed webhook, retried while the receiver fails and dead after the last attempt
+func TestDelivery(t *testing.T) {
+	ctx := context.Background()
+
+	sender, err := NewSender(&Config{Timeout: time.Second})
+	require.NoError(t, err)
+	webhooks, deliveries := dbmemory.NewWebhook(), dbmemory.NewWebhookDelivery()
+	transactor := dbmemory.NewTransactor(dbmemory.NewAccount(), dbmemory.NewStatusHistory(), dbmemory.NewSession(),
+		dbmemory.NewSecurityEvent(), dbmemory.NewAuditLog(), dbmemory.NewExport(), dbmemory.NewOutbox(), webhooks, deliveries)
+	uc, err := usecases.NewWebhook(&usecases.WebhookDependencies{
+		Repo:         webhooks,
+		DeliveryRepo: deliveries,
+		Sender:       sender,
+		Transactor:   transactor,
+		Config:       &usecases.WebhookConfig{MaxAttempts: 2, BackoffBase: time.Nanosecond, BackoffMax: time.Nanosecond},
+	})
+	require.NoError(t, err)
+
+	webhook, err := uc.Create(ctx, &usecases.WebhookCreateRequest{URL: "http://placeholder"})
+	require.NoError(t, err)
+	receiver := newTestReceiver(t, webhook.Secret)
+	webhook, err = uc.Update(ctx, &usecases.WebhookUpdateRequest{
+		UUID:       webhook.UUID,
+		URL:        receiver.URL,
+		EventTypes: []string{entities.DomainEventAccountCreated},
+		Active:     true,
+	})
+	require.NoError(t, err)
+
+	event := &entities.DomainEvent{
+		UUID:        "event-uuid",
+		Type:        entities.DomainEventAccountCreated,
+		AccountUUID: "account-uuid",
+		Payload:     `{"Email":"bob@example.com"}`,
+		CreatedAt:   time.Now().Unix(),
+	}
+	require.NoError(t, uc.Publish(ctx, event))
+	// a republished event isn't delivered twice and an unsubscribed one isn't delivered at all
+	require.NoError(t, uc.Publish(ctx, event))
+	require.NoError(t, uc.Publish(ctx, &entities.DomainEvent{UUID: "another-uuid", Type: entities.DomainEventAccountDeleted}))
+
+	ok, err := uc.DeliverNext(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = uc.DeliverNext(ctx)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.Len(t, receiver.bodies, 1)
+	assert.NoError(t, receiver.errs[0])
+	msg, err := event.Message()
+	require.NoError(t, err)
+	assert.JSONEq(t, string(msg), receiver.bodies[0])
+
+	log, err := uc.ListDeliveries(ctx, &repositories.WebhookDeliveryFilter{WebhookUUID: webhook.UUID})
+	require.NoError(t, err)
+	require.Len(t, log, 1)
+	assert.Equal(t, entities.WebhookDeliveryDelivered, log[0].Status)
+	assert.Equal(t, http.StatusNoContent, log[0].LastStatusCode)
+
+	// the receiver is down: the delivery is retried and dead after the last attempt
+	receiver.setStatus(http.StatusInternalServerError)
+	_, err = uc.Redeliver(ctx, &usecases.WebhookRedeliverRequest{ID: log[0].ID})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		// the backoff is a nanosecond, but the due time is in seconds
+		require.Eventually(t, func() bool {
+			ok, err := uc.DeliverNext(ctx)
+			require.NoError(t, err)
+			return ok
+		}, 3*time.Second, 50*time.Millisecond)
+	}
+
+	dead, err := deliveries.GetOneByID(ctx, log[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.WebhookDeliveryDead, dead.Status)
+	assert.Equal(t, 2, dead.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, dead.LastStatusCode)
+	assert.Contains(t, dead.LastError, "500 Internal Server Error")
+	assert.Len(t, receiver.bodies, 3)
+}
@@ -0,0 +1,101 @@
+package workers
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"time"
+)
+
+const (
+	dispatcherKey = "dispatcher"
+
+	DefaultDispatchInterval = 5 * time.Second
+)
+
+type IWebhookDispatcher interface {
+	DeliverNext(ctx context.Context) (bool, error)
+	DeleteFinished(ctx context.Context) (int, error)
+}
+
+type DispatcherConfig struct {
+	// Interval is DefaultDispatchInterval if it isn't positive
+	Interval time.Duration
+}
+
+type DispatcherDependencies struct {
+	Usecase IWebhookDispatcher
+	Logger  logapp.ILogger
+	Config  *DispatcherConfig
+}
+
+// Dispatcher sends the due webhook deliveries and deletes the finished ones after the retention
+type Dispatcher struct {
+	usecase  IWebhookDispatcher
+	logger   logapp.ILogger
+	interval time.Duration
+}
+
+func NewDispatcher(d *DispatcherDependencies) (*Dispatcher, error) {
+	if d == nil {
+		return nil, ErrDependenciesAreNil
+	}
+	if d.Usecase == nil {
+		return nil, ErrUsecaseIsNil
+	}
+	if d.Logger == nil {
+		return nil, ErrLoggerIsNil
+	}
+
+	w := &Dispatcher{
+		usecase:  d.Usecase,
+		logger:   d.Logger.WithField(workerKey, dispatcherKey),
+		interval: DefaultDispatchInterval,
+	}
+	if d.Config != nil && d.Config.Interval > 0 {
+		w.interval = d.Config.Interval
+	}
+	return w, nil
+}
+
+// Run sends the deliveries every interval until ctx is done, errors are logged
+func (w *Dispatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if sent, err := w.Dispatch(ctx); err != nil {
+			w.logger.Error(err)
+		} else if sent > 0 {
+			w.logger.Debugf("%d webhook deliveries are sent", sent)
+		}
+
+		if deleted, err := w.usecase.DeleteFinished(ctx); err != nil {
+			w.logger.Error(err)
+		} else if deleted > 0 {
+			w.logger.Infof("%d finished webhook deliveries are deleted", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch sends the due deliveries one by one until there are none, the failed deliveries are retried
+// by the usecase later. An error of a delivery doesn't stop the others
+func (w *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	var sent int
+	for ctx.Err() == nil {
+		ok, err := w.usecase.DeliverNext(ctx)
+		if !ok {
+			return sent, err
+		}
+		sent++
+		if err != nil {
+			w.logger.Error(err)
+		}
+	}
+	return sent, nil
+}
@@ -0,0 +1,94 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	workers_test "github.com/alexsibrin/runbot-auth/internal/workers/mocks"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecase := workers_test.NewMockIWebhookDispatcher(ctrl)
+	ctx := context.TODO()
+
+	testCases := []struct {
+		name         string
+		setupMocks   func()
+		expectedSent int
+		expectedErr  error
+	}{
+		{
+			name: "Nothing to send",
+			setupMocks: func() {
+				usecase.EXPECT().DeliverNext(ctx).Return(false, nil)
+			},
+			expectedSent: 0,
+		},
+		{
+			name: "Failed delivery doesn't stop the others",
+			setupMocks: func() {
+				gomock.InOrder(
+					usecase.EXPECT().DeliverNext(ctx).Return(true, nil),
+					usecase.EXPECT().DeliverNext(ctx).Return(true, errors.New("update error")),
+					usecase.EXPECT().DeliverNext(ctx).Return(true, nil),
+					usecase.EXPECT().DeliverNext(ctx).Return(false, nil),
+				)
+			},
+			expectedSent: 3,
+		},
+		{
+			name: "Claim error",
+			setupMocks: func() {
+				gomock.InOrder(
+					usecase.EXPECT().DeliverNext(ctx).Return(true, nil),
+					usecase.EXPECT().DeliverNext(ctx).Return(false, errors.New("claim error")),
+				)
+			},
+			expectedSent: 1,
+			expectedErr:  errors.New("claim error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			w, err := NewDispatcher(&DispatcherDependencies{Usecase: usecase, Logger: logrus.New()})
+			require.NoError(t, err)
+
+			sent, err := w.Dispatch(ctx)
+			assert.Equal(t, tc.expectedSent, sent)
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDispatcher_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecase := workers_test.NewMockIWebhookDispatcher(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	usecase.EXPECT().DeliverNext(gomock.Any()).Return(false, nil)
+	usecase.EXPECT().DeleteFinished(gomock.Any()).DoAndReturn(func(context.Context) (int, error) {
+		cancel()
+		return 1, nil
+	})
+
+	w, err := NewDispatcher(&DispatcherDependencies{Usecase: usecase, Logger: logrus.New()})
+	require.NoError(t, err)
+
+	assert.NoError(t, w.Run(ctx))
+}
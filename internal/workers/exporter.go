@@ -0,0 +1,101 @@
+package workers
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"time"
+)
+
+const (
+	exporterKey = "exporter"
+
+	DefaultExportInterval = 10 * time.Second
+)
+
+type IExportProcessor interface {
+	ProcessNext(ctx context.Context) (bool, error)
+	DeleteExpired(ctx context.Context) (int, error)
+}
+
+type ExporterConfig struct {
+	// Interval is DefaultExportInterval if it isn't positive
+	Interval time.Duration
+}
+
+type ExporterDependencies struct {
+	Usecase IExportProcessor
+	Logger  logapp.ILogger
+	Config  *ExporterConfig
+}
+
+// Exporter builds the requested personal data exports and deletes the expired ones
+type Exporter struct {
+	usecase  IExportProcessor
+	logger   logapp.ILogger
+	interval time.Duration
+}
+
+func NewExporter(d *ExporterDependencies) (*Exporter, error) {
+	if d == nil {
+		return nil, ErrDependenciesAreNil
+	}
+	if d.Usecase == nil {
+		return nil, ErrUsecaseIsNil
+	}
+	if d.Logger == nil {
+		return nil, ErrLoggerIsNil
+	}
+
+	e := &Exporter{
+		usecase:  d.Usecase,
+		logger:   d.Logger.WithField(workerKey, exporterKey),
+		interval: DefaultExportInterval,
+	}
+	if d.Config != nil && d.Config.Interval > 0 {
+		e.interval = d.Config.Interval
+	}
+	return e, nil
+}
+
+// Run processes the exports every interval until ctx is done, errors are logged
+func (e *Exporter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		if processed, err := e.Process(ctx); err != nil {
+			e.logger.Error(err)
+		} else if processed > 0 {
+			e.logger.Infof("%d exports are processed", processed)
+		}
+
+		if deleted, err := e.usecase.DeleteExpired(ctx); err != nil {
+			e.logger.Error(err)
+		} else if deleted > 0 {
+			e.logger.Infof("%d expired exports are deleted", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Process processes the pending exports one by one until there are none.
+// A failed export doesn't stop the others
+func (e *Exporter) Process(ctx context.Context) (int, error) {
+	var processed int
+	for ctx.Err() == nil {
+		ok, err := e.usecase.ProcessNext(ctx)
+		if !ok {
+			return processed, err
+		}
+		processed++
+		if err != nil {
+			e.logger.Error(err)
+		}
+	}
+	return processed, nil
+}
@@ -0,0 +1,94 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	workers_test "github.com/alexsibrin/runbot-auth/internal/workers/mocks"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestExporter_Process(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecase := workers_test.NewMockIExportProcessor(ctrl)
+	ctx := context.TODO()
+
+	testCases := []struct {
+		name              string
+		setupMocks        func()
+		expectedProcessed int
+		expectedErr       error
+	}{
+		{
+			name: "Nothing to process",
+			setupMocks: func() {
+				usecase.EXPECT().ProcessNext(ctx).Return(false, nil)
+			},
+			expectedProcessed: 0,
+		},
+		{
+			name: "Failed export doesn't stop the others",
+			setupMocks: func() {
+				gomock.InOrder(
+					usecase.EXPECT().ProcessNext(ctx).Return(true, nil),
+					usecase.EXPECT().ProcessNext(ctx).Return(true, errors.New("build error")),
+					usecase.EXPECT().ProcessNext(ctx).Return(true, nil),
+					usecase.EXPECT().ProcessNext(ctx).Return(false, nil),
+				)
+			},
+			expectedProcessed: 3,
+		},
+		{
+			name: "Claim error",
+			setupMocks: func() {
+				gomock.InOrder(
+					usecase.EXPECT().ProcessNext(ctx).Return(true, nil),
+					usecase.EXPECT().ProcessNext(ctx).Return(false, errors.New("claim error")),
+				)
+			},
+			expectedProcessed: 1,
+			expectedErr:       errors.New("claim error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			e, err := NewExporter(&ExporterDependencies{Usecase: usecase, Logger: logrus.New()})
+			require.NoError(t, err)
+
+			processed, err := e.Process(ctx)
+			assert.Equal(t, tc.expectedProcessed, processed)
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestExporter_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecase := workers_test.NewMockIExportProcessor(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	usecase.EXPECT().ProcessNext(gomock.Any()).Return(false, nil)
+	usecase.EXPECT().DeleteExpired(gomock.Any()).DoAndReturn(func(context.Context) (int, error) {
+		cancel()
+		return 1, nil
+	})
+
+	e, err := NewExporter(&ExporterDependencies{Usecase: usecase, Logger: logrus.New()})
+	require.NoError(t, err)
+
+	assert.NoError(t, e.Run(ctx))
+}
@@ -0,0 +1,252 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/alexsibrin/runbot-auth/internal/workers (interfaces: IAccountPurger,IExportProcessor,IStatusReactivator,IOutboxRelay,IWebhookDispatcher)
+//
+// Generated by this command:
+//
+//	mockgen -destination mocks/mock_workers.go -package workers_test github.com/alexsibrin/runbot-auth/internal/workers IAccountPurger,IExportProcessor,IStatusReactivator,IOutboxRelay,IWebhookDispatcher
+//
+
+// Package workers_test is a generated GoMock package.
+package workers_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIAccountPurger is a mock of IAccountPurger interface.
+type MockIAccountPurger struct {
+	ctrl     *gomock.Controller
+	recorder *MockIAccountPurgerMockRecorder
+}
+
+// MockIAccountPurgerMockRecorder is the mock recorder for MockIAccountPurger.
+type MockIAccountPurgerMockRecorder struct {
+	mock *MockIAccountPurger
+}
+
+// NewMockIAccountPurger creates a new mock instance.
+func NewMockIAccountPurger(ctrl *gomock.Controller) *MockIAccountPurger {
+	mock := &MockIAccountPurger{ctrl: ctrl}
+	mock.recorder = &MockIAccountPurgerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIAccountPurger) EXPECT() *MockIAccountPurgerMockRecorder {
+	return m.recorder
+}
+
+// PurgeDeletedAccounts mocks base method.
+func (m *MockIAccountPurger) PurgeDeletedAccounts(arg0 context.Context, arg1 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedAccounts", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedAccounts indicates an expected call of PurgeDeletedAccounts.
+func (mr *MockIAccountPurgerMockRecorder) PurgeDeletedAccounts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedAccounts", reflect.TypeOf((*MockIAccountPurger)(nil).PurgeDeletedAccounts), arg0, arg1)
+}
+
+// MockIExportProcessor is a mock of IExportProcessor interface.
+type MockIExportProcessor struct {
+	ctrl     *gomock.Controller
+	recorder *MockIExportProcessorMockRecorder
+}
+
+// MockIExportProcessorMockRecorder is the mock recorder for MockIExportProcessor.
+type MockIExportProcessorMockRecorder struct {
+	mock *MockIExportProcessor
+}
+
+// NewMockIExportProcessor creates a new mock instance.
+func NewMockIExportProcessor(ctrl *gomock.Controller) *MockIExportProcessor {
+	mock := &MockIExportProcessor{ctrl: ctrl}
+	mock.recorder = &MockIExportProcessorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIExportProcessor) EXPECT() *MockIExportProcessorMockRecorder {
+	return m.recorder
+}
+
+// DeleteExpired mocks base method.
+func (m *MockIExportProcessor) DeleteExpired(arg0 context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockIExportProcessorMockRecorder) DeleteExpired(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockIExportProcessor)(nil).DeleteExpired), arg0)
+}
+
+// ProcessNext mocks base method.
+func (m *MockIExportProcessor) ProcessNext(arg0 context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessNext", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProcessNext indicates an expected call of ProcessNext.
+func (mr *MockIExportProcessorMockRecorder) ProcessNext(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessNext", reflect.TypeOf((*MockIExportProcessor)(nil).ProcessNext), arg0)
+}
+
+// MockIStatusReactivator is a mock of IStatusReactivator interface.
+type MockIStatusReactivator struct {
+	ctrl     *gomock.Controller
+	recorder *MockIStatusReactivatorMockRecorder
+}
+
+// MockIStatusReactivatorMockRecorder is the mock recorder for MockIStatusReactivator.
+type MockIStatusReactivatorMockRecorder struct {
+	mock *MockIStatusReactivator
+}
+
+// NewMockIStatusReactivator creates a new mock instance.
+func NewMockIStatusReactivator(ctrl *gomock.Controller) *MockIStatusReactivator {
+	mock := &MockIStatusReactivator{ctrl: ctrl}
+	mock.recorder = &MockIStatusReactivatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIStatusReactivator) EXPECT() *MockIStatusReactivatorMockRecorder {
+	return m.recorder
+}
+
+// ReactivateExpiredStatuses mocks base method.
+func (m *MockIStatusReactivator) ReactivateExpiredStatuses(arg0 context.Context, arg1 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReactivateExpiredStatuses", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReactivateExpiredStatuses indicates an expected call of ReactivateExpiredStatuses.
+func (mr *MockIStatusReactivatorMockRecorder) ReactivateExpiredStatuses(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReactivateExpiredStatuses", reflect.TypeOf((*MockIStatusReactivator)(nil).ReactivateExpiredStatuses), arg0, arg1)
+}
+
+// MockIOutboxRelay is a mock of IOutboxRelay interface.
+type MockIOutboxRelay struct {
+	ctrl     *gomock.Controller
+	recorder *MockIOutboxRelayMockRecorder
+}
+
+// MockIOutboxRelayMockRecorder is the mock recorder for MockIOutboxRelay.
+type MockIOutboxRelayMockRecorder struct {
+	mock *MockIOutboxRelay
+}
+
+// NewMockIOutboxRelay creates a new mock instance.
+func NewMockIOutboxRelay(ctrl *gomock.Controller) *MockIOutboxRelay {
+	mock := &MockIOutboxRelay{ctrl: ctrl}
+	mock.recorder = &MockIOutboxRelayMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIOutboxRelay) EXPECT() *MockIOutboxRelayMockRecorder {
+	return m.recorder
+}
+
+// DeletePublished mocks base method.
+func (m *MockIOutboxRelay) DeletePublished(arg0 context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePublished", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeletePublished indicates an expected call of DeletePublished.
+func (mr *MockIOutboxRelayMockRecorder) DeletePublished(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePublished", reflect.TypeOf((*MockIOutboxRelay)(nil).DeletePublished), arg0)
+}
+
+// PublishNext mocks base method.
+func (m *MockIOutboxRelay) PublishNext(arg0 context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishNext", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishNext indicates an expected call of PublishNext.
+func (mr *MockIOutboxRelayMockRecorder) PublishNext(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishNext", reflect.TypeOf((*MockIOutboxRelay)(nil).PublishNext), arg0)
+}
+
+// MockIWebhookDispatcher is a mock of IWebhookDispatcher interface.
+type MockIWebhookDispatcher struct {
+	ctrl     *gomock.Controller
+	recorder *MockIWebhookDispatcherMockRecorder
+}
+
+// MockIWebhookDispatcherMockRecorder is the mock recorder for MockIWebhookDispatcher.
+type MockIWebhookDispatcherMockRecorder struct {
+	mock *MockIWebhookDispatcher
+}
+
+// NewMockIWebhookDispatcher creates a new mock instance.
+func NewMockIWebhookDispatcher(ctrl *gomock.Controller) *MockIWebhookDispatcher {
+	mock := &MockIWebhookDispatcher{ctrl: ctrl}
+	mock.recorder = &MockIWebhookDispatcherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIWebhookDispatcher) EXPECT() *MockIWebhookDispatcherMockRecorder {
+	return m.recorder
+}
+
+// DeleteFinished mocks base method.
+func (m *MockIWebhookDispatcher) DeleteFinished(arg0 context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFinished", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFinished indicates an expected call of DeleteFinished.
+func (mr *MockIWebhookDispatcherMockRecorder) DeleteFinished(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFinished", reflect.TypeOf((*MockIWebhookDispatcher)(nil).DeleteFinished), arg0)
+}
+
+// DeliverNext mocks base method.
+func (m *MockIWebhookDispatcher) DeliverNext(arg0 context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeliverNext", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeliverNext indicates an expected call of DeliverNext.
+func (mr *MockIWebhookDispatcherMockRecorder) DeliverNext(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeliverNext", reflect.TypeOf((*MockIWebhookDispatcher)(nil).DeliverNext), arg0)
+}
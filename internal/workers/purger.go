@@ -0,0 +1,112 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"time"
+)
+
+//go:generate mockgen -destination mocks/mock_workers.go -package workers_test github.com/alexsibrin/runbot-auth/internal/workers IAccountPurger,IExportProcessor,IStatusReactivator,IOutboxRelay,IWebhookDispatcher
+
+const (
+	workerKey = "worker"
+	purgerKey = "purger"
+
+	DefaultPurgeInterval  = time.Hour
+	DefaultPurgeBatchSize = 100
+)
+
+var (
+	ErrDependenciesAreNil = errors.New("dependencies are nil")
+	ErrUsecaseIsNil       = errors.New("usecase is nil")
+	ErrLoggerIsNil        = errors.New("logger is nil")
+)
+
+type IAccountPurger interface {
+	PurgeDeletedAccounts(ctx context.Context, limit int) (int, error)
+}
+
+type PurgerConfig struct {
+	// Interval is DefaultPurgeInterval if it isn't positive
+	Interval time.Duration
+	// BatchSize is DefaultPurgeBatchSize if it isn't positive
+	BatchSize int
+}
+
+type PurgerDependencies struct {
+	Usecase IAccountPurger
+	Logger  logapp.ILogger
+	Config  *PurgerConfig
+}
+
+// Purger periodically erases the PII of the deleted accounts which grace period is over
+type Purger struct {
+	usecase   IAccountPurger
+	logger    logapp.ILogger
+	interval  time.Duration
+	batchsize int
+}
+
+func NewPurger(d *PurgerDependencies) (*Purger, error) {
+	if d == nil {
+		return nil, ErrDependenciesAreNil
+	}
+	if d.Usecase == nil {
+		return nil, ErrUsecaseIsNil
+	}
+	if d.Logger == nil {
+		return nil, ErrLoggerIsNil
+	}
+
+	p := &Purger{
+		usecase:   d.Usecase,
+		logger:    d.Logger.WithField(workerKey, purgerKey),
+		interval:  DefaultPurgeInterval,
+		batchsize: DefaultPurgeBatchSize,
+	}
+	if d.Config != nil && d.Config.Interval > 0 {
+		p.interval = d.Config.Interval
+	}
+	if d.Config != nil && d.Config.BatchSize > 0 {
+		p.batchsize = d.Config.BatchSize
+	}
+	return p, nil
+}
+
+// Run purges the accounts at the start and then every interval until ctx is done.
+// Purge errors are logged and retried on the next tick
+func (p *Purger) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if purged, err := p.Purge(ctx); err != nil {
+			p.logger.Error(err)
+		} else if purged > 0 {
+			p.logger.Infof("%d deleted accounts are purged", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge purges the accounts batch by batch while there are full batches
+func (p *Purger) Purge(ctx context.Context) (int, error) {
+	var total int
+	for ctx.Err() == nil {
+		purged, err := p.usecase.PurgeDeletedAccounts(ctx, p.batchsize)
+		total += purged
+		if err != nil {
+			return total, err
+		}
+		if purged < p.batchsize {
+			break
+		}
+	}
+	return total, nil
+}
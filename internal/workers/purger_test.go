@@ -0,0 +1,156 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	workers_test "github.com/alexsibrin/runbot-auth/internal/workers/mocks"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestNewPurger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	usecase := workers_test.NewMockIAccountPurger(ctrl)
+	logger := logrus.New()
+
+	testCases := []struct {
+		name              string
+		in                *PurgerDependencies
+		expectedInterval  time.Duration
+		expectedBatchSize int
+		expectedErr       error
+	}{
+		{
+			name:              "Defaults",
+			in:                &PurgerDependencies{Usecase: usecase, Logger: logger},
+			expectedInterval:  DefaultPurgeInterval,
+			expectedBatchSize: DefaultPurgeBatchSize,
+		},
+		{
+			name: "Custom config",
+			in: &PurgerDependencies{Usecase: usecase, Logger: logger, Config: &PurgerConfig{
+				Interval:  time.Minute,
+				BatchSize: 10,
+			}},
+			expectedInterval:  time.Minute,
+			expectedBatchSize: 10,
+		},
+		{
+			name:        "Dependencies are nil",
+			in:          nil,
+			expectedErr: ErrDependenciesAreNil,
+		},
+		{
+			name:        "Usecase is nil",
+			in:          &PurgerDependencies{Logger: logger},
+			expectedErr: ErrUsecaseIsNil,
+		},
+		{
+			name:        "Logger is nil",
+			in:          &PurgerDependencies{Usecase: usecase},
+			expectedErr: ErrLoggerIsNil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := NewPurger(tc.in)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				assert.Nil(t, p)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedInterval, p.interval)
+			assert.Equal(t, tc.expectedBatchSize, p.batchsize)
+		})
+	}
+}
+
+func TestPurger_Purge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecase := workers_test.NewMockIAccountPurger(ctrl)
+	ctx := context.TODO()
+
+	testCases := []struct {
+		name          string
+		setupMocks    func()
+		expectedTotal int
+		expectedErr   error
+	}{
+		{
+			name: "Nothing to purge",
+			setupMocks: func() {
+				usecase.EXPECT().PurgeDeletedAccounts(ctx, 2).Return(0, nil)
+			},
+			expectedTotal: 0,
+		},
+		{
+			name: "Full batches are followed by the next one",
+			setupMocks: func() {
+				gomock.InOrder(
+					usecase.EXPECT().PurgeDeletedAccounts(ctx, 2).Return(2, nil),
+					usecase.EXPECT().PurgeDeletedAccounts(ctx, 2).Return(2, nil),
+					usecase.EXPECT().PurgeDeletedAccounts(ctx, 2).Return(1, nil),
+				)
+			},
+			expectedTotal: 5,
+		},
+		{
+			name: "Usecase error",
+			setupMocks: func() {
+				gomock.InOrder(
+					usecase.EXPECT().PurgeDeletedAccounts(ctx, 2).Return(2, nil),
+					usecase.EXPECT().PurgeDeletedAccounts(ctx, 2).Return(1, errors.New("some error")),
+				)
+			},
+			expectedTotal: 3,
+			expectedErr:   errors.New("some error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			p, err := NewPurger(&PurgerDependencies{
+				Usecase: usecase,
+				Logger:  logrus.New(),
+				Config:  &PurgerConfig{BatchSize: 2},
+			})
+			require.NoError(t, err)
+
+			total, err := p.Purge(ctx)
+			assert.Equal(t, tc.expectedTotal, total)
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPurger_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecase := workers_test.NewMockIAccountPurger(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	usecase.EXPECT().PurgeDeletedAccounts(gomock.Any(), DefaultPurgeBatchSize).DoAndReturn(func(context.Context, int) (int, error) {
+		cancel()
+		return 0, nil
+	})
+
+	p, err := NewPurger(&PurgerDependencies{Usecase: usecase, Logger: logrus.New()})
+	require.NoError(t, err)
+
+	assert.NoError(t, p.Run(ctx))
+}
@@ -0,0 +1,102 @@
+package workers
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"time"
+)
+
+const (
+	reactivatorKey = "reactivator"
+
+	DefaultReactivateInterval  = time.Minute
+	DefaultReactivateBatchSize = 100
+)
+
+type IStatusReactivator interface {
+	ReactivateExpiredStatuses(ctx context.Context, limit int) (int, error)
+}
+
+type ReactivatorConfig struct {
+	// Interval is DefaultReactivateInterval if it isn't positive
+	Interval time.Duration
+	// BatchSize is DefaultReactivateBatchSize if it isn't positive
+	BatchSize int
+}
+
+type ReactivatorDependencies struct {
+	Usecase IStatusReactivator
+	Logger  logapp.ILogger
+	Config  *ReactivatorConfig
+}
+
+// Reactivator periodically activates the accounts which temporary suspension or block has lapsed
+type Reactivator struct {
+	usecase   IStatusReactivator
+	logger    logapp.ILogger
+	interval  time.Duration
+	batchsize int
+}
+
+func NewReactivator(d *ReactivatorDependencies) (*Reactivator, error) {
+	if d == nil {
+		return nil, ErrDependenciesAreNil
+	}
+	if d.Usecase == nil {
+		return nil, ErrUsecaseIsNil
+	}
+	if d.Logger == nil {
+		return nil, ErrLoggerIsNil
+	}
+
+	r := &Reactivator{
+		usecase:   d.Usecase,
+		logger:    d.Logger.WithField(workerKey, reactivatorKey),
+		interval:  DefaultReactivateInterval,
+		batchsize: DefaultReactivateBatchSize,
+	}
+	if d.Config != nil && d.Config.Interval > 0 {
+		r.interval = d.Config.Interval
+	}
+	if d.Config != nil && d.Config.BatchSize > 0 {
+		r.batchsize = d.Config.BatchSize
+	}
+	return r, nil
+}
+
+// Run reactivates the accounts at the start and then every interval until ctx is done.
+// Errors are logged and retried on the next tick
+func (r *Reactivator) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if reactivated, err := r.Reactivate(ctx); err != nil {
+			r.logger.Error(err)
+		} else if reactivated > 0 {
+			r.logger.Infof("%d accounts are reactivated", reactivated)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reactivate reactivates the accounts batch by batch while there are full batches
+func (r *Reactivator) Reactivate(ctx context.Context) (int, error) {
+	var total int
+	for ctx.Err() == nil {
+		reactivated, err := r.usecase.ReactivateExpiredStatuses(ctx, r.batchsize)
+		total += reactivated
+		if err != nil {
+			return total, err
+		}
+		if reactivated < r.batchsize {
+			break
+		}
+	}
+	return total, nil
+}
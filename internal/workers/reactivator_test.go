@@ -0,0 +1,92 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	workers_test "github.com/alexsibrin/runbot-auth/internal/workers/mocks"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestReactivator_Reactivate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecase := workers_test.NewMockIStatusReactivator(ctrl)
+	ctx := context.TODO()
+
+	testCases := []struct {
+		name          string
+		setupMocks    func()
+		expectedTotal int
+		expectedErr   error
+	}{
+		{
+			name: "Nothing to reactivate",
+			setupMocks: func() {
+				usecase.EXPECT().ReactivateExpiredStatuses(ctx, 2).Return(0, nil)
+			},
+			expectedTotal: 0,
+		},
+		{
+			name: "Full batches are followed by the next one",
+			setupMocks: func() {
+				gomock.InOrder(
+					usecase.EXPECT().ReactivateExpiredStatuses(ctx, 2).Return(2, nil),
+					usecase.EXPECT().ReactivateExpiredStatuses(ctx, 2).Return(1, nil),
+				)
+			},
+			expectedTotal: 3,
+		},
+		{
+			name: "Usecase error",
+			setupMocks: func() {
+				usecase.EXPECT().ReactivateExpiredStatuses(ctx, 2).Return(1, errors.New("some error"))
+			},
+			expectedTotal: 1,
+			expectedErr:   errors.New("some error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			r, err := NewReactivator(&ReactivatorDependencies{
+				Usecase: usecase,
+				Logger:  logrus.New(),
+				Config:  &ReactivatorConfig{BatchSize: 2},
+			})
+			require.NoError(t, err)
+
+			total, err := r.Reactivate(ctx)
+			assert.Equal(t, tc.expectedTotal, total)
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestReactivator_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecase := workers_test.NewMockIStatusReactivator(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	usecase.EXPECT().ReactivateExpiredStatuses(gomock.Any(), DefaultReactivateBatchSize).DoAndReturn(func(context.Context, int) (int, error) {
+		cancel()
+		return 0, nil
+	})
+
+	r, err := NewReactivator(&ReactivatorDependencies{Usecase: usecase, Logger: logrus.New()})
+	require.NoError(t, err)
+
+	assert.NoError(t, r.Run(ctx))
+}
@@ -0,0 +1,98 @@
+package workers
+
+import (
+	"context"
+	"github.com/alexsibrin/runbot-auth/internal/logapp"
+	"time"
+)
+
+const (
+	relayKey = "relay"
+
+	DefaultRelayInterval = time.Second
+)
+
+type IOutboxRelay interface {
+	PublishNext(ctx context.Context) (int, error)
+	DeletePublished(ctx context.Context) (int, error)
+}
+
+type RelayConfig struct {
+	// Interval is DefaultRelayInterval if it isn't positive
+	Interval time.Duration
+}
+
+type RelayDependencies struct {
+	Usecase IOutboxRelay
+	Logger  logapp.ILogger
+	Config  *RelayConfig
+}
+
+// Relay publishes the domain events from the outbox and deletes the published ones after the retention
+type Relay struct {
+	usecase  IOutboxRelay
+	logger   logapp.ILogger
+	interval time.Duration
+}
+
+func NewRelay(d *RelayDependencies) (*Relay, error) {
+	if d == nil {
+		return nil, ErrDependenciesAreNil
+	}
+	if d.Usecase == nil {
+		return nil, ErrUsecaseIsNil
+	}
+	if d.Logger == nil {
+		return nil, ErrLoggerIsNil
+	}
+
+	r := &Relay{
+		usecase:  d.Usecase,
+		logger:   d.Logger.WithField(workerKey, relayKey),
+		interval: DefaultRelayInterval,
+	}
+	if d.Config != nil && d.Config.Interval > 0 {
+		r.interval = d.Config.Interval
+	}
+	return r, nil
+}
+
+// Run relays the events every interval until ctx is done, errors are logged
+func (r *Relay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if published, err := r.Publish(ctx); err != nil {
+			r.logger.Error(err)
+		} else if published > 0 {
+			r.logger.Debugf("%d events are published", published)
+		}
+
+		if deleted, err := r.usecase.DeletePublished(ctx); err != nil {
+			r.logger.Error(err)
+		} else if deleted > 0 {
+			r.logger.Infof("%d published events are deleted", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Publish publishes the batches of events until the outbox is drained or a publish fails,
+// the failed event is retried on the next run
+func (r *Relay) Publish(ctx context.Context) (int, error) {
+	var total int
+	for ctx.Err() == nil {
+		published, err := r.usecase.PublishNext(ctx)
+		total += published
+		if err != nil || published == 0 {
+			return total, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,93 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	workers_test "github.com/alexsibrin/runbot-auth/internal/workers/mocks"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestRelay_Publish(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecase := workers_test.NewMockIOutboxRelay(ctrl)
+	ctx := context.TODO()
+
+	testCases := []struct {
+		name              string
+		setupMocks        func()
+		expectedPublished int
+		expectedErr       error
+	}{
+		{
+			name: "Nothing to publish",
+			setupMocks: func() {
+				usecase.EXPECT().PublishNext(ctx).Return(0, nil)
+			},
+			expectedPublished: 0,
+		},
+		{
+			name: "Outbox is drained",
+			setupMocks: func() {
+				gomock.InOrder(
+					usecase.EXPECT().PublishNext(ctx).Return(100, nil),
+					usecase.EXPECT().PublishNext(ctx).Return(20, nil),
+					usecase.EXPECT().PublishNext(ctx).Return(0, nil),
+				)
+			},
+			expectedPublished: 120,
+		},
+		{
+			name: "Failed publish stops the relay",
+			setupMocks: func() {
+				gomock.InOrder(
+					usecase.EXPECT().PublishNext(ctx).Return(100, nil),
+					usecase.EXPECT().PublishNext(ctx).Return(3, errors.New("broker is unavailable")),
+				)
+			},
+			expectedPublished: 103,
+			expectedErr:       errors.New("broker is unavailable"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			r, err := NewRelay(&RelayDependencies{Usecase: usecase, Logger: logrus.New()})
+			require.NoError(t, err)
+
+			published, err := r.Publish(ctx)
+			assert.Equal(t, tc.expectedPublished, published)
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRelay_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecase := workers_test.NewMockIOutboxRelay(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	usecase.EXPECT().PublishNext(gomock.Any()).Return(0, nil)
+	usecase.EXPECT().DeletePublished(gomock.Any()).DoAndReturn(func(context.Context) (int, error) {
+		cancel()
+		return 1, nil
+	})
+
+	r, err := NewRelay(&RelayDependencies{Usecase: usecase, Logger: logrus.New()})
+	require.NoError(t, err)
+
+	assert.NoError(t, r.Run(ctx))
+}
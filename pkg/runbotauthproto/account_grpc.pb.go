@@ -19,9 +19,16 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	Account_Get_FullMethodName       = "/Account/Get"
-	Account_Add_FullMethodName       = "/Account/Add"
-	Account_SetStatus_FullMethodName = "/Account/SetStatus"
+	Account_Get_FullMethodName                  = "/Account/Get"
+	Account_BatchGet_FullMethodName             = "/Account/BatchGet"
+	Account_ListAccounts_FullMethodName         = "/Account/ListAccounts"
+	Account_Add_FullMethodName                  = "/Account/Add"
+	Account_SetStatus_FullMethodName            = "/Account/SetStatus"
+	Account_DeleteAccount_FullMethodName        = "/Account/DeleteAccount"
+	Account_GetStatusHistory_FullMethodName     = "/Account/GetStatusHistory"
+	Account_SearchSecurityEvents_FullMethodName = "/Account/SearchSecurityEvents"
+	Account_ExportAuditLog_FullMethodName       = "/Account/ExportAuditLog"
+	Account_WatchAccounts_FullMethodName        = "/Account/WatchAccounts"
 )
 
 // AccountClient is the client API for Account service.
@@ -29,8 +36,15 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type AccountClient interface {
 	Get(ctx context.Context, in *GetAccount, opts ...grpc.CallOption) (*GetAccountResponse, error)
+	BatchGet(ctx context.Context, in *AccountBatchGet, opts ...grpc.CallOption) (*AccountBatchGetResponse, error)
+	ListAccounts(ctx context.Context, in *AccountsList, opts ...grpc.CallOption) (*AccountsListResponse, error)
 	Add(ctx context.Context, in *AccountCreate, opts ...grpc.CallOption) (*AccountCreateResponse, error)
 	SetStatus(ctx context.Context, in *ChangeAccountStatus, opts ...grpc.CallOption) (*ChangeAccountStatusResponse, error)
+	DeleteAccount(ctx context.Context, in *AccountDelete, opts ...grpc.CallOption) (*AccountDeleteResponse, error)
+	GetStatusHistory(ctx context.Context, in *AccountStatusHistory, opts ...grpc.CallOption) (*AccountStatusHistoryResponse, error)
+	SearchSecurityEvents(ctx context.Context, in *SecurityEventsSearch, opts ...grpc.CallOption) (*SecurityEventsResponse, error)
+	ExportAuditLog(ctx context.Context, in *AuditLogExport, opts ...grpc.CallOption) (*AuditLogResponse, error)
+	WatchAccounts(ctx context.Context, in *AccountWatch, opts ...grpc.CallOption) (Account_WatchAccountsClient, error)
 }
 
 type accountClient struct {
@@ -50,6 +64,24 @@ func (c *accountClient) Get(ctx context.Context, in *GetAccount, opts ...grpc.Ca
 	return out, nil
 }
 
+func (c *accountClient) BatchGet(ctx context.Context, in *AccountBatchGet, opts ...grpc.CallOption) (*AccountBatchGetResponse, error) {
+	out := new(AccountBatchGetResponse)
+	err := c.cc.Invoke(ctx, Account_BatchGet_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountClient) ListAccounts(ctx context.Context, in *AccountsList, opts ...grpc.CallOption) (*AccountsListResponse, error) {
+	out := new(AccountsListResponse)
+	err := c.cc.Invoke(ctx, Account_ListAccounts_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *accountClient) Add(ctx context.Context, in *AccountCreate, opts ...grpc.CallOption) (*AccountCreateResponse, error) {
 	out := new(AccountCreateResponse)
 	err := c.cc.Invoke(ctx, Account_Add_FullMethodName, in, out, opts...)
@@ -68,13 +100,88 @@ func (c *accountClient) SetStatus(ctx context.Context, in *ChangeAccountStatus,
 	return out, nil
 }
 
+func (c *accountClient) DeleteAccount(ctx context.Context, in *AccountDelete, opts ...grpc.CallOption) (*AccountDeleteResponse, error) {
+	out := new(AccountDeleteResponse)
+	err := c.cc.Invoke(ctx, Account_DeleteAccount_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountClient) GetStatusHistory(ctx context.Context, in *AccountStatusHistory, opts ...grpc.CallOption) (*AccountStatusHistoryResponse, error) {
+	out := new(AccountStatusHistoryResponse)
+	err := c.cc.Invoke(ctx, Account_GetStatusHistory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountClient) SearchSecurityEvents(ctx context.Context, in *SecurityEventsSearch, opts ...grpc.CallOption) (*SecurityEventsResponse, error) {
+	out := new(SecurityEventsResponse)
+	err := c.cc.Invoke(ctx, Account_SearchSecurityEvents_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountClient) ExportAuditLog(ctx context.Context, in *AuditLogExport, opts ...grpc.CallOption) (*AuditLogResponse, error) {
+	out := new(AuditLogResponse)
+	err := c.cc.Invoke(ctx, Account_ExportAuditLog_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountClient) WatchAccounts(ctx context.Context, in *AccountWatch, opts ...grpc.CallOption) (Account_WatchAccountsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Account_ServiceDesc.Streams[0], Account_WatchAccounts_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &accountWatchAccountsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Account_WatchAccountsClient interface {
+	Recv() (*AccountChange, error)
+	grpc.ClientStream
+}
+
+type accountWatchAccountsClient struct {
+	grpc.ClientStream
+}
+
+func (x *accountWatchAccountsClient) Recv() (*AccountChange, error) {
+	m := new(AccountChange)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // AccountServer is the server API for Account service.
 // All implementations must embed UnimplementedAccountServer
 // for forward compatibility
 type AccountServer interface {
 	Get(context.Context, *GetAccount) (*GetAccountResponse, error)
+	BatchGet(context.Context, *AccountBatchGet) (*AccountBatchGetResponse, error)
+	ListAccounts(context.Context, *AccountsList) (*AccountsListResponse, error)
 	Add(context.Context, *AccountCreate) (*AccountCreateResponse, error)
 	SetStatus(context.Context, *ChangeAccountStatus) (*ChangeAccountStatusResponse, error)
+	DeleteAccount(context.Context, *AccountDelete) (*AccountDeleteResponse, error)
+	GetStatusHistory(context.Context, *AccountStatusHistory) (*AccountStatusHistoryResponse, error)
+	SearchSecurityEvents(context.Context, *SecurityEventsSearch) (*SecurityEventsResponse, error)
+	ExportAuditLog(context.Context, *AuditLogExport) (*AuditLogResponse, error)
+	WatchAccounts(*AccountWatch, Account_WatchAccountsServer) error
 	mustEmbedUnimplementedAccountServer()
 }
 
@@ -85,12 +192,33 @@ type UnimplementedAccountServer struct {
 func (UnimplementedAccountServer) Get(context.Context, *GetAccount) (*GetAccountResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
 }
+func (UnimplementedAccountServer) BatchGet(context.Context, *AccountBatchGet) (*AccountBatchGetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGet not implemented")
+}
+func (UnimplementedAccountServer) ListAccounts(context.Context, *AccountsList) (*AccountsListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAccounts not implemented")
+}
 func (UnimplementedAccountServer) Add(context.Context, *AccountCreate) (*AccountCreateResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Add not implemented")
 }
 func (UnimplementedAccountServer) SetStatus(context.Context, *ChangeAccountStatus) (*ChangeAccountStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SetStatus not implemented")
 }
+func (UnimplementedAccountServer) DeleteAccount(context.Context, *AccountDelete) (*AccountDeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAccount not implemented")
+}
+func (UnimplementedAccountServer) GetStatusHistory(context.Context, *AccountStatusHistory) (*AccountStatusHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatusHistory not implemented")
+}
+func (UnimplementedAccountServer) SearchSecurityEvents(context.Context, *SecurityEventsSearch) (*SecurityEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchSecurityEvents not implemented")
+}
+func (UnimplementedAccountServer) ExportAuditLog(context.Context, *AuditLogExport) (*AuditLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportAuditLog not implemented")
+}
+func (UnimplementedAccountServer) WatchAccounts(*AccountWatch, Account_WatchAccountsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchAccounts not implemented")
+}
 func (UnimplementedAccountServer) mustEmbedUnimplementedAccountServer() {}
 
 // UnsafeAccountServer may be embedded to opt out of forward compatibility for this service.
@@ -122,6 +250,42 @@ func _Account_Get_Handler(srv interface{}, ctx context.Context, dec func(interfa
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Account_BatchGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountBatchGet)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServer).BatchGet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Account_BatchGet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServer).BatchGet(ctx, req.(*AccountBatchGet))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Account_ListAccounts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountsList)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServer).ListAccounts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Account_ListAccounts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServer).ListAccounts(ctx, req.(*AccountsList))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Account_Add_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(AccountCreate)
 	if err := dec(in); err != nil {
@@ -158,6 +322,99 @@ func _Account_SetStatus_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Account_DeleteAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountDelete)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServer).DeleteAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Account_DeleteAccount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServer).DeleteAccount(ctx, req.(*AccountDelete))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Account_GetStatusHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountStatusHistory)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServer).GetStatusHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Account_GetStatusHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServer).GetStatusHistory(ctx, req.(*AccountStatusHistory))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Account_SearchSecurityEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SecurityEventsSearch)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServer).SearchSecurityEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Account_SearchSecurityEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServer).SearchSecurityEvents(ctx, req.(*SecurityEventsSearch))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Account_ExportAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuditLogExport)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServer).ExportAuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Account_ExportAuditLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServer).ExportAuditLog(ctx, req.(*AuditLogExport))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Account_WatchAccounts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AccountWatch)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AccountServer).WatchAccounts(m, &accountWatchAccountsServer{stream})
+}
+
+type Account_WatchAccountsServer interface {
+	Send(*AccountChange) error
+	grpc.ServerStream
+}
+
+type accountWatchAccountsServer struct {
+	grpc.ServerStream
+}
+
+func (x *accountWatchAccountsServer) Send(m *AccountChange) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // Account_ServiceDesc is the grpc.ServiceDesc for Account service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -169,6 +426,14 @@ var Account_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Get",
 			Handler:    _Account_Get_Handler,
 		},
+		{
+			MethodName: "BatchGet",
+			Handler:    _Account_BatchGet_Handler,
+		},
+		{
+			MethodName: "ListAccounts",
+			Handler:    _Account_ListAccounts_Handler,
+		},
 		{
 			MethodName: "Add",
 			Handler:    _Account_Add_Handler,
@@ -177,7 +442,29 @@ var Account_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SetStatus",
 			Handler:    _Account_SetStatus_Handler,
 		},
+		{
+			MethodName: "DeleteAccount",
+			Handler:    _Account_DeleteAccount_Handler,
+		},
+		{
+			MethodName: "GetStatusHistory",
+			Handler:    _Account_GetStatusHistory_Handler,
+		},
+		{
+			MethodName: "SearchSecurityEvents",
+			Handler:    _Account_SearchSecurityEvents_Handler,
+		},
+		{
+			MethodName: "ExportAuditLog",
+			Handler:    _Account_ExportAuditLog_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchAccounts",
+			Handler:       _Account_WatchAccounts_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "account.proto",
 }
@@ -0,0 +1,331 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.25.3
+// source: webhook.proto
+
+package runbotauthproto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Webhooks_Create_FullMethodName         = "/Webhooks/Create"
+	Webhooks_Get_FullMethodName            = "/Webhooks/Get"
+	Webhooks_List_FullMethodName           = "/Webhooks/List"
+	Webhooks_Update_FullMethodName         = "/Webhooks/Update"
+	Webhooks_Delete_FullMethodName         = "/Webhooks/Delete"
+	Webhooks_ListDeliveries_FullMethodName = "/Webhooks/ListDeliveries"
+	Webhooks_Redeliver_FullMethodName      = "/Webhooks/Redeliver"
+)
+
+// WebhooksClient is the client API for Webhooks service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type WebhooksClient interface {
+	Create(ctx context.Context, in *WebhookCreate, opts ...grpc.CallOption) (*WebhookResponse, error)
+	Get(ctx context.Context, in *WebhookGet, opts ...grpc.CallOption) (*WebhookResponse, error)
+	List(ctx context.Context, in *WebhookList, opts ...grpc.CallOption) (*WebhookListResponse, error)
+	Update(ctx context.Context, in *WebhookUpdate, opts ...grpc.CallOption) (*WebhookResponse, error)
+	Delete(ctx context.Context, in *WebhookDelete, opts ...grpc.CallOption) (*WebhookDeleteResponse, error)
+	ListDeliveries(ctx context.Context, in *WebhookDeliveriesSearch, opts ...grpc.CallOption) (*WebhookDeliveriesResponse, error)
+	Redeliver(ctx context.Context, in *WebhookRedeliver, opts ...grpc.CallOption) (*WebhookDelivery, error)
+}
+
+type webhooksClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWebhooksClient(cc grpc.ClientConnInterface) WebhooksClient {
+	return &webhooksClient{cc}
+}
+
+func (c *webhooksClient) Create(ctx context.Context, in *WebhookCreate, opts ...grpc.CallOption) (*WebhookResponse, error) {
+	out := new(WebhookResponse)
+	err := c.cc.Invoke(ctx, Webhooks_Create_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhooksClient) Get(ctx context.Context, in *WebhookGet, opts ...grpc.CallOption) (*WebhookResponse, error) {
+	out := new(WebhookResponse)
+	err := c.cc.Invoke(ctx, Webhooks_Get_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhooksClient) List(ctx context.Context, in *WebhookList, opts ...grpc.CallOption) (*WebhookListResponse, error) {
+	out := new(WebhookListResponse)
+	err := c.cc.Invoke(ctx, Webhooks_List_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhooksClient) Update(ctx context.Context, in *WebhookUpdate, opts ...grpc.CallOption) (*WebhookResponse, error) {
+	out := new(WebhookResponse)
+	err := c.cc.Invoke(ctx, Webhooks_Update_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhooksClient) Delete(ctx context.Context, in *WebhookDelete, opts ...grpc.CallOption) (*WebhookDeleteResponse, error) {
+	out := new(WebhookDeleteResponse)
+	err := c.cc.Invoke(ctx, Webhooks_Delete_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhooksClient) ListDeliveries(ctx context.Context, in *WebhookDeliveriesSearch, opts ...grpc.CallOption) (*WebhookDeliveriesResponse, error) {
+	out := new(WebhookDeliveriesResponse)
+	err := c.cc.Invoke(ctx, Webhooks_ListDeliveries_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhooksClient) Redeliver(ctx context.Context, in *WebhookRedeliver, opts ...grpc.CallOption) (*WebhookDelivery, error) {
+	out := new(WebhookDelivery)
+	err := c.cc.Invoke(ctx, Webhooks_Redeliver_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WebhooksServer is the server API for Webhooks service.
+// All implementations must embed UnimplementedWebhooksServer
+// for forward compatibility
+type WebhooksServer interface {
+	Create(context.Context, *WebhookCreate) (*WebhookResponse, error)
+	Get(context.Context, *WebhookGet) (*WebhookResponse, error)
+	List(context.Context, *WebhookList) (*WebhookListResponse, error)
+	Update(context.Context, *WebhookUpdate) (*WebhookResponse, error)
+	Delete(context.Context, *WebhookDelete) (*WebhookDeleteResponse, error)
+	ListDeliveries(context.Context, *WebhookDeliveriesSearch) (*WebhookDeliveriesResponse, error)
+	Redeliver(context.Context, *WebhookRedeliver) (*WebhookDelivery, error)
+	mustEmbedUnimplementedWebhooksServer()
+}
+
+// UnimplementedWebhooksServer must be embedded to have forward compatible implementations.
+type UnimplementedWebhooksServer struct {
+}
+
+func (UnimplementedWebhooksServer) Create(context.Context, *WebhookCreate) (*WebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedWebhooksServer) Get(context.Context, *WebhookGet) (*WebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedWebhooksServer) List(context.Context, *WebhookList) (*WebhookListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedWebhooksServer) Update(context.Context, *WebhookUpdate) (*WebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedWebhooksServer) Delete(context.Context, *WebhookDelete) (*WebhookDeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedWebhooksServer) ListDeliveries(context.Context, *WebhookDeliveriesSearch) (*WebhookDeliveriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeliveries not implemented")
+}
+func (UnimplementedWebhooksServer) Redeliver(context.Context, *WebhookRedeliver) (*WebhookDelivery, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Redeliver not implemented")
+}
+func (UnimplementedWebhooksServer) mustEmbedUnimplementedWebhooksServer() {}
+
+// UnsafeWebhooksServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WebhooksServer will
+// result in compilation errors.
+type UnsafeWebhooksServer interface {
+	mustEmbedUnimplementedWebhooksServer()
+}
+
+func RegisterWebhooksServer(s grpc.ServiceRegistrar, srv WebhooksServer) {
+	s.RegisterService(&Webhooks_ServiceDesc, srv)
+}
+
+func _Webhooks_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookCreate)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhooksServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Webhooks_Create_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhooksServer).Create(ctx, req.(*WebhookCreate))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Webhooks_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookGet)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhooksServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Webhooks_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhooksServer).Get(ctx, req.(*WebhookGet))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Webhooks_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookList)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhooksServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Webhooks_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhooksServer).List(ctx, req.(*WebhookList))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Webhooks_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookUpdate)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhooksServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Webhooks_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhooksServer).Update(ctx, req.(*WebhookUpdate))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Webhooks_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookDelete)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhooksServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Webhooks_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhooksServer).Delete(ctx, req.(*WebhookDelete))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Webhooks_ListDeliveries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookDeliveriesSearch)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhooksServer).ListDeliveries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Webhooks_ListDeliveries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhooksServer).ListDeliveries(ctx, req.(*WebhookDeliveriesSearch))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Webhooks_Redeliver_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookRedeliver)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhooksServer).Redeliver(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Webhooks_Redeliver_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhooksServer).Redeliver(ctx, req.(*WebhookRedeliver))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Webhooks_ServiceDesc is the grpc.ServiceDesc for Webhooks service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Webhooks_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "Webhooks",
+	HandlerType: (*WebhooksServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _Webhooks_Create_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _Webhooks_Get_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _Webhooks_List_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _Webhooks_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _Webhooks_Delete_Handler,
+		},
+		{
+			MethodName: "ListDeliveries",
+			Handler:    _Webhooks_ListDeliveries_Handler,
+		},
+		{
+			MethodName: "Redeliver",
+			Handler:    _Webhooks_Redeliver_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "webhook.proto",
+}